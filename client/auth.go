@@ -62,6 +62,29 @@ func (c AuthCredentials) Copy() *AuthCredentials {
 	return &c
 }
 
+// Class names of the server-side authenticators sent in AUTHENTICATE responses.
+const (
+	PasswordAuthenticatorClass = "org.apache.cassandra.auth.PasswordAuthenticator"
+	DseAuthenticatorClass      = "com.datastax.bdp.cassandra.auth.DseAuthenticator"
+)
+
+// Authenticator performs the client side of an authentication exchange; see PlainTextAuthenticator.
+type Authenticator interface {
+	// InitialResponse returns the token of the first AUTH_RESPONSE, given the class name of the server-side
+	// authenticator, as sent in the AUTHENTICATE response.
+	InitialResponse(authenticator string) ([]byte, error)
+	// EvaluateChallenge returns the token of the AUTH_RESPONSE answering the given AUTH_CHALLENGE token.
+	EvaluateChallenge(challenge []byte) ([]byte, error)
+}
+
+// AuthenticatorProvider is a callback function that gets invoked whenever a CqlClientConnection receives an
+// AUTHENTICATE response during a handshake, with the class name of the server-side authenticator, e.g.
+// DseAuthenticatorClass. It returns the Authenticator to use, so that a single client can connect to servers using
+// different authenticators. Returning a nil Authenticator makes the client authenticate anonymously, with empty
+// plain-text credentials, which is what DSE servers expect from unauthenticated clients when their authenticator is in
+// transitional mode. Returning an error fails the handshake. See CqlClient.AuthenticatorProvider.
+type AuthenticatorProvider func(authenticator string) (Authenticator, error)
+
 // A simple authenticator to perform plain-text authentications for CQL clients.
 type PlainTextAuthenticator struct {
 	Credentials *AuthCredentials
@@ -74,9 +97,9 @@ var (
 
 func (a *PlainTextAuthenticator) InitialResponse(authenticator string) ([]byte, error) {
 	switch authenticator {
-	case "com.datastax.bdp.cassandra.auth.DseAuthenticator":
+	case DseAuthenticatorClass:
 		return mechanism, nil
-	case "org.apache.cassandra.auth.PasswordAuthenticator":
+	case PasswordAuthenticatorClass:
 		return a.Credentials.Marshal(), nil
 	}
 	return nil, fmt.Errorf("unknown authenticator: %v", authenticator)
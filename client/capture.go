@@ -0,0 +1,126 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+)
+
+// CapturedFrame is a frame captured by a WireCapture, along with its exact encoded bytes.
+type CapturedFrame struct {
+	Frame *frame.Frame
+	// Bytes are the bytes of the frame, header included, as written to or read from the network. With protocol
+	// version 5 and higher, once the connection uses the modern framing layout, these are the bytes of the frame
+	// inside its segment: segment headers and compression are not included.
+	Bytes []byte
+}
+
+// WireCapture captures the exact bytes of a request and of its responses, alongside the corresponding decoded frames,
+// so that byte-level assertions can be written against the real client path, e.g. to compare them with golden files.
+// WireCapture instances are obtained through CqlClientConnection.SendWithCapture or
+// CqlClientConnection.SendAndReceiveWithCapture. A WireCapture is safe for concurrent use.
+type WireCapture struct {
+	request   *CapturedFrame
+	responses []*CapturedFrame
+	lock      sync.Mutex
+}
+
+// Request returns the captured request, or nil if it was not written yet.
+func (w *WireCapture) Request() *CapturedFrame {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.request
+}
+
+// Responses returns the responses captured so far. Typically there is only one response, except when using
+// continuous paging (DataStax Enterprise only). Responses are captured before they are delivered to the in-flight
+// request.
+func (w *WireCapture) Responses() []*CapturedFrame {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]*CapturedFrame(nil), w.responses...)
+}
+
+func (w *WireCapture) onRequest(f *frame.Frame, encoded []byte) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.request = &CapturedFrame{Frame: f, Bytes: append([]byte(nil), encoded...)}
+}
+
+func (w *WireCapture) onResponse(f *frame.Frame, encoded []byte) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.responses = append(w.responses, &CapturedFrame{Frame: f, Bytes: encoded})
+}
+
+// wireCaptures keeps track of the active captures of a connection, per stream id. A capture is active from the moment
+// its request is enqueued until its last response is received.
+type wireCaptures struct {
+	captures map[int16]*WireCapture
+	// active is the number of active captures, so that frames are not copied when there is none.
+	active int32
+	lock   sync.Mutex
+}
+
+func newWireCaptures() *wireCaptures {
+	return &wireCaptures{captures: make(map[int16]*WireCapture)}
+}
+
+func (w *wireCaptures) isActive() bool {
+	return atomic.LoadInt32(&w.active) > 0
+}
+
+// add activates the given capture for the given stream id, replacing any capture left by a request that timed out.
+func (w *wireCaptures) add(streamId int16, capture *WireCapture) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.captures[streamId] = capture
+	atomic.StoreInt32(&w.active, int32(len(w.captures)))
+}
+
+func (w *wireCaptures) remove(streamId int16) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	delete(w.captures, streamId)
+	atomic.StoreInt32(&w.active, int32(len(w.captures)))
+}
+
+func (w *wireCaptures) onRequest(f *frame.Frame, encoded []byte) {
+	if !w.isActive() {
+		return
+	}
+	w.lock.Lock()
+	capture := w.captures[f.Header.StreamId]
+	w.lock.Unlock()
+	if capture != nil {
+		capture.onRequest(f, encoded)
+	}
+}
+
+func (w *wireCaptures) onResponse(f *frame.Frame, encoded []byte) {
+	w.lock.Lock()
+	capture := w.captures[f.Header.StreamId]
+	if capture != nil && isLastFrame(f) {
+		delete(w.captures, f.Header.StreamId)
+		atomic.StoreInt32(&w.active, int32(len(w.captures)))
+	}
+	w.lock.Unlock()
+	if capture != nil {
+		capture.onResponse(f, encoded)
+	}
+}
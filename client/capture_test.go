@@ -0,0 +1,58 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCqlClientConnection_SendAndReceiveWithCapture(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{client.HeartbeatHandler}, nil)
+	defer cancelFn()
+
+	// a request without capture, so that captures are matched by stream id
+	_, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, 2, &message.Options{}))
+	require.NoError(t, err)
+
+	request := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Options{})
+	response, capture, err := clientConn.SendAndReceiveWithCapture(request)
+	require.NoError(t, err)
+	require.NotNil(t, capture.Request())
+	assert.Same(t, request, capture.Request().Frame)
+	assert.Equal(t, []byte{
+		0x04,       // version
+		0x00,       // flags
+		0x00, 0x01, // stream id
+		0x05,                   // OPTIONS
+		0x00, 0x00, 0x00, 0x00, // body length
+	}, capture.Request().Bytes)
+	responses := capture.Responses()
+	require.Len(t, responses, 1)
+	assert.Same(t, response, responses[0].Frame)
+	expected, err := frame.EncodeToBytes(frame.NewCodec(), response)
+	require.NoError(t, err)
+	assert.Equal(t, expected, responses[0].Bytes)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
@@ -59,22 +59,82 @@ type CqlClient struct {
 	RemoteAddress string
 	// The AuthCredentials for authenticated servers. If nil, no authentication will be used.
 	Credentials *AuthCredentials
+	// AuthenticatorProvider is an optional callback returning the Authenticator to use, given the authenticator class
+	// name sent by the server in the AUTHENTICATE response of a handshake. When set, it takes precedence over
+	// Credentials, and handshakes accept both READY and AUTHENTICATE responses to STARTUP requests.
+	AuthenticatorProvider AuthenticatorProvider
 	// The compression to use; if unspecified, no compression will be used.
 	Compression primitive.Compression
-	// The maximum number of in-flight requests to apply for each connection created with Connect. Must be strictly
-	// positive.
+	// The maximum number of in-flight requests to apply for each connection created with Connect. Must be between 1 and
+	// 32768. Note that protocol version 2 only allows 128 in-flight requests per connection, regardless of this setting.
 	MaxInFlight int
 	// The maximum number of pending responses awaiting delivery to store per request. Must be strictly positive.
 	// This is only useful when using continuous paging, a feature specific to DataStax Enterprise.
 	MaxPending int
 	// The timeout to apply when establishing new connections.
 	ConnectTimeout time.Duration
-	// The timeout to apply when waiting for incoming responses.
+	// The timeout to apply when waiting for incoming responses. Use CqlClientConnection.SendWithTimeout to apply a
+	// different timeout to a given request.
 	ReadTimeout time.Duration
+	// StreamIdWaitTimeout is how long requests using ManagedStreamId wait for a stream id to be released, when all the
+	// stream ids of a connection are in use. If zero or negative, such requests fail immediately with a
+	// StreamIdsExhaustedError, which is also returned when the timeout expires.
+	StreamIdWaitTimeout time.Duration
 	// An optional list of handlers to handle incoming events.
 	EventHandlers []EventHandler
 	// TLSConfig is the TLS configuration to use.
 	TLSConfig *tls.Config
+	// AllowBetaVersions enables beta protocol versions, such as primitive.ProtocolVersion6, for connections created
+	// by this client: handshakes can then be initiated with a beta version, in which case all frames will have the
+	// USE_BETA flag set. The server must be configured to accept beta versions as well.
+	AllowBetaVersions bool
+	// Recorder is an optional FrameRecorder that records all the frames exchanged by connections created by this
+	// client. See NewHexDumpRecorder and NewJSONRecorder.
+	Recorder FrameRecorder
+	// Middlewares is an optional list of middlewares invoked with all the frames exchanged by connections created by
+	// this client. See NewLoggingMiddleware.
+	Middlewares []Middleware
+	// RateLimiter is an optional RateLimiter shared by all the connections created by this client, thus limiting the
+	// global rate of requests sent by them. Requests exceeding the rate are delayed until allowed.
+	RateLimiter *RateLimiter
+	// ConnectionRateLimit is the maximum number of requests per second that each connection created by this client
+	// can send; requests exceeding the rate are delayed until allowed. If zero or negative, requests are not limited.
+	ConnectionRateLimit float64
+	// ConnectionRateBurst is the maximum number of requests that each connection can send at once, when
+	// ConnectionRateLimit is set. If zero or negative, a burst of 1 is used, meaning that requests are evenly spaced.
+	ConnectionRateBurst int
+	// An optional list of listeners to notify when requests are delayed by RateLimiter or ConnectionRateLimit.
+	ThrottleListeners []ThrottleListener
+	// Metrics is an optional Metrics notified of the frames exchanged by connections created by this client, e.g. an
+	// OpCodeMetrics.
+	Metrics Metrics
+	// Scheduler is an optional Scheduler serializing the internal hand-offs of connections created by this client,
+	// for deterministic tests; it is usually shared with a CqlServer. If nil, hand-offs are not serialized.
+	Scheduler *Scheduler
+	// StartupOptions are additional options to include in the STARTUP requests of handshakes, e.g.
+	// message.StartupOptionThrowOnOverload, or any custom key. They take precedence over the default options
+	// CQL_VERSION and DRIVER_NAME; the COMPRESSION option however is always set according to Compression.
+	StartupOptions map[string]string
+	// SendOptions makes handshakes send an OPTIONS request before the STARTUP request. The server's SUPPORTED
+	// response can then be obtained with CqlClientConnection.Supported.
+	SendOptions bool
+	// AutoReprepare makes connections keep track of the statements they prepared, keyed by prepared query id; when an
+	// EXECUTE request sent with CqlClientConnection.Send, SendWithTimeout or SendContext is rejected with an
+	// Unprepared error, its PREPARE request is then sent again, and the EXECUTE request is retried once, as drivers
+	// do. The Unprepared error is only delivered if the statement was not prepared on the same connection, or if it
+	// cannot be prepared again.
+	AutoReprepare bool
+	// HeartbeatInterval enables heartbeats: connections created by this client send a heartbeat request whenever no
+	// frame was sent or received for this long, and are closed if the heartbeat fails, e.g. if the server does not
+	// reply within ReadTimeout, thus detecting broken connections. Heartbeats use the protocol version of the last frame
+	// sent, and managed stream ids, see ManagedStreamId. If zero or negative, no heartbeats are sent.
+	HeartbeatInterval time.Duration
+	// HeartbeatRequest is the message sent as heartbeat; it should be a no-op request. If nil, an OPTIONS request is
+	// sent, as drivers do.
+	HeartbeatRequest message.Message
+	// An optional list of handlers to notify when a heartbeat fails, just before the connection is closed. Use
+	// CqlClientConnection.Done to be notified when a connection is closed for any reason.
+	HeartbeatFailureHandlers []HeartbeatFailureHandler
 }
 
 // NewCqlClient Creates a new CqlClient with default options. Leave credentials nil to opt out from authentication.
@@ -94,13 +154,18 @@ func (client *CqlClient) String() string {
 }
 
 // Connect establishes a new TCP connection to the client's remote address.
-// Set ctx to context.Background if no parent context exists.
+// Set ctx to context.Background if no parent context exists. The connection attempt is interrupted if ctx is done
+// before it completes or before ConnectTimeout elapses; ctx also becomes the parent context of the returned connection,
+// which is closed when ctx is done.
 // The returned CqlClientConnection is ready to use, but one must initialize it manually, for example by calling
 // CqlClientConnection.InitiateHandshake. Alternatively, use ConnectAndInit to get a fully-initialized connection.
 func (client *CqlClient) Connect(ctx context.Context) (*CqlClientConnection, error) {
 	log.Debug().Msgf("%v: connecting", client)
+	rateLimiters, err := client.newRateLimiters()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", client, err)
+	}
 	var conn net.Conn
-	var err error
 	connectCtx, connectCancel := context.WithTimeout(ctx, client.ConnectTimeout)
 	defer connectCancel()
 	if client.TLSConfig != nil {
@@ -118,11 +183,24 @@ func (client *CqlClient) Connect(ctx context.Context) (*CqlClientConnection, err
 			conn,
 			ctx,
 			client.Credentials,
+			client.AuthenticatorProvider,
 			client.Compression,
 			client.MaxInFlight,
 			client.MaxPending,
 			client.ReadTimeout,
+			client.StreamIdWaitTimeout,
 			client.EventHandlers,
+			client.AllowBetaVersions,
+			client.Recorder,
+			client.Middlewares,
+			rateLimiters,
+			client.ThrottleListeners,
+			client.Metrics,
+			client.Scheduler,
+			client.StartupOptions,
+			client.SendOptions,
+			client.AutoReprepare,
+			newHeartbeat(client.HeartbeatInterval, client.HeartbeatRequest, client.HeartbeatFailureHandlers),
 		); err != nil {
 			log.Err(err).Msgf("%v: cannot establish CQL connection", client)
 			_ = conn.Close()
@@ -134,10 +212,34 @@ func (client *CqlClient) Connect(ctx context.Context) (*CqlClientConnection, err
 	}
 }
 
+// newRateLimiters returns the rate limiters to apply to a new connection: a new limiter if ConnectionRateLimit is set,
+// then the shared RateLimiter, if any.
+func (client *CqlClient) newRateLimiters() ([]*RateLimiter, error) {
+	var rateLimiters []*RateLimiter
+	if client.ConnectionRateLimit > 0 {
+		burst := client.ConnectionRateBurst
+		if burst < 1 {
+			burst = 1
+		}
+		if limiter, err := NewRateLimiter(client.ConnectionRateLimit, burst); err != nil {
+			return nil, fmt.Errorf("connection rate limit: %w", err)
+		} else {
+			rateLimiters = append(rateLimiters, limiter)
+		}
+	}
+	if client.RateLimiter != nil {
+		rateLimiters = append(rateLimiters, client.RateLimiter)
+	}
+	return rateLimiters, nil
+}
+
 // ConnectAndInit establishes a new TCP connection to the server, then initiates a handshake procedure using the
 // specified protocol version. The CqlClientConnection connection will be fully initialized when this method returns.
 // Use stream id zero to activate automatic stream id management.
-// Set ctx to context.Background if no parent context exists.
+// Set ctx to context.Background if no parent context exists. Like with Connect, ctx becomes the parent context of the
+// returned connection, which is closed when ctx is done; it also interrupts the handshake, see
+// CqlClientConnection.InitiateHandshakeContext. To only bound the connection attempt and the handshake, call Connect
+// with a long-lived context, then InitiateHandshakeContext with a shorter one.
 func (client *CqlClient) ConnectAndInit(
 	ctx context.Context,
 	version primitive.ProtocolVersion,
@@ -146,7 +248,7 @@ func (client *CqlClient) ConnectAndInit(
 	if connection, err := client.Connect(ctx); err != nil {
 		return nil, err
 	} else {
-		return connection, connection.InitiateHandshake(version, streamId)
+		return connection, connection.InitiateHandshakeContext(ctx, version, streamId)
 	}
 }
 
@@ -160,10 +262,28 @@ type CqlClientConnection struct {
 	modernLayout       bool
 	readTimeout        time.Duration
 	credentials        *AuthCredentials
+	authenticators     AuthenticatorProvider
 	handlers           []EventHandler
+	allowBeta          bool
+	recorder           FrameRecorder
+	middlewares        []Middleware
+	rateLimiters       []*RateLimiter
+	throttleListeners  []ThrottleListener
+	metrics            Metrics
+	requestTracker     *requestTracker
+	captures           *wireCaptures
+	scheduler          *Scheduler
+	startupOptions     map[string]string
+	sendOptions        bool
+	supported          atomic.Value
+	preparedStatements *preparedStatementCache
+	heartbeat          *heartbeat
+	schedulerLabel     string
 	inFlightHandler    *inFlightRequestsHandler
 	outgoing           chan *frame.Frame
 	events             chan *frame.Frame
+	subscribers        []*EventSubscriber
+	subscribersLock    sync.Mutex
 	waitGroup          *sync.WaitGroup
 	closed             int32
 	ctx                context.Context
@@ -175,11 +295,24 @@ func newCqlClientConnection(
 	conn net.Conn,
 	ctx context.Context,
 	credentials *AuthCredentials,
+	authenticators AuthenticatorProvider,
 	compression primitive.Compression,
 	maxInFlight int,
 	maxPending int,
 	readTimeout time.Duration,
+	streamIdWaitTimeout time.Duration,
 	handlers []EventHandler,
+	allowBeta bool,
+	recorder FrameRecorder,
+	middlewares []Middleware,
+	rateLimiters []*RateLimiter,
+	throttleListeners []ThrottleListener,
+	metrics Metrics,
+	scheduler *Scheduler,
+	startupOptions map[string]string,
+	sendOptions bool,
+	autoReprepare bool,
+	heartbeat *heartbeat,
 ) (*CqlClientConnection, error) {
 	if conn == nil {
 		return nil, fmt.Errorf("TCP connection cannot be nil")
@@ -189,36 +322,67 @@ func newCqlClientConnection(
 	}
 	if maxInFlight < 1 {
 		return nil, fmt.Errorf("max in-flight: expecting positive, got: %v", maxInFlight)
-	} else if maxInFlight > math.MaxInt16 {
-		return nil, fmt.Errorf("max in-flight: expecting <= %v, got: %v", math.MaxInt16, maxInFlight)
+	} else if maxInFlight > math.MaxInt16+1 {
+		return nil, fmt.Errorf("max in-flight: expecting <= %v, got: %v", math.MaxInt16+1, maxInFlight)
 	}
 	if maxPending < 1 {
 		return nil, fmt.Errorf("max pending: expecting positive, got: %v", maxInFlight)
 	}
-	frameCodec := frame.NewCodecWithCompression(NewBodyCompressor(compression))
+	frameCodec := newFrameCodec(compression, allowBeta)
 	segmentCodec := segment.NewCodecWithCompression(NewPayloadCompressor(compression))
 	if compression == "" {
 		compression = primitive.CompressionNone
 	}
 	connection := &CqlClientConnection{
-		conn:         conn,
-		frameCodec:   frameCodec,
-		segmentCodec: segmentCodec,
-		compression:  compression,
-		readTimeout:  readTimeout,
-		credentials:  credentials,
-		handlers:     handlers,
-		outgoing:     make(chan *frame.Frame, maxInFlight),
-		events:       make(chan *frame.Frame, maxInFlight),
-		waitGroup:    &sync.WaitGroup{},
+		conn:              conn,
+		frameCodec:        frameCodec,
+		segmentCodec:      segmentCodec,
+		compression:       compression,
+		readTimeout:       readTimeout,
+		credentials:       credentials,
+		authenticators:    authenticators,
+		handlers:          handlers,
+		allowBeta:         allowBeta,
+		recorder:          recorder,
+		middlewares:       middlewares,
+		rateLimiters:      rateLimiters,
+		throttleListeners: throttleListeners,
+		metrics:           metrics,
+		captures:          newWireCaptures(),
+		scheduler:         scheduler,
+		schedulerLabel:    scheduler.newConnectionLabel("client"),
+		startupOptions:    startupOptions,
+		sendOptions:       sendOptions,
+		heartbeat:         heartbeat,
+		outgoing:          make(chan *frame.Frame, maxInFlight),
+		events:            make(chan *frame.Frame, maxInFlight),
+		waitGroup:         &sync.WaitGroup{},
 		payloadAccumulator: &payloadAccumulator{
-			frameCodec: frame.NewRawCodec(), // without compression
+			frameCodec: newFrameCodec(primitive.CompressionNone, allowBeta),
 		},
 	}
+	if metrics != nil {
+		connection.requestTracker = newRequestTracker()
+	}
+	if autoReprepare {
+		connection.preparedStatements = newPreparedStatementCache()
+	}
 	connection.ctx, connection.cancel = context.WithCancel(ctx)
-	connection.inFlightHandler = newInFlightRequestsHandler(connection.String(), connection.ctx, maxInFlight, maxPending, readTimeout)
+	var err error
+	if connection.inFlightHandler, err = newInFlightRequestsHandler(
+		connection.String(),
+		connection.ctx,
+		maxInFlight,
+		maxPending,
+		readTimeout,
+		streamIdWaitTimeout,
+	); err != nil {
+		connection.cancel()
+		return nil, err
+	}
 	connection.incomingLoop()
 	connection.outgoingLoop()
+	connection.heartbeatLoop()
 	connection.awaitDone()
 	return connection, nil
 }
@@ -291,12 +455,15 @@ func (c *CqlClientConnection) outgoingLoop() {
 				break
 			} else {
 				log.Debug().Msgf("%v: sending outgoing frame: %v", c, outgoing)
+				done := c.scheduler.await(c.ctx, StepClientSend, c.schedulerLabel, outgoing.Header)
+				c.heartbeat.onFrameSent(outgoing)
 				if c.modernLayout {
 					// TODO write coalescer
 					abort = c.writeSegment(outgoing, c.conn)
 				} else {
 					abort = c.writeFrame(outgoing, c.conn)
 				}
+				done()
 			}
 		}
 		c.waitGroup.Done()
@@ -362,10 +529,10 @@ func (c *CqlClientConnection) addMultiSegmentPayload(payload *segment.Payload) (
 func (c *CqlClientConnection) writeSegment(outgoing *frame.Frame, dest io.Writer) (abort bool) {
 	// never compress frames individually when included in a segment
 	outgoing.Header.Flags = outgoing.Header.Flags.Remove(primitive.HeaderFlagCompressed)
-	encodedFrame := &bytes.Buffer{}
-	if abort = c.writeFrame(outgoing, encodedFrame); abort {
-		abort = true
-	} else {
+	// the frame is encoded straight into the segment payload
+	encodedFrame := primitive.AcquireWriteBuffer()
+	defer primitive.ReleaseWriteBuffer(encodedFrame)
+	if abort = c.encodeFrame(outgoing, encodedFrame); !abort {
 		seg := &segment.Segment{
 			Header:  &segment.Header{IsSelfContained: true},
 			Payload: &segment.Payload{UncompressedData: encodedFrame.Bytes()},
@@ -374,15 +541,28 @@ func (c *CqlClientConnection) writeSegment(outgoing *frame.Frame, dest io.Writer
 			abort = c.reportConnectionFailure(err, false)
 		} else {
 			log.Debug().Msgf("%v: outgoing segment successfully written: %v (frame: %v)", c, seg, outgoing)
+			c.onFrameWritten(outgoing)
 		}
 	}
 	return abort
 }
 
 func (c *CqlClientConnection) readFrame(source io.Reader) (abort bool) {
+	var encoded *bytes.Buffer
+	if c.captures.isActive() {
+		encoded = &bytes.Buffer{}
+		source = io.TeeReader(source, encoded)
+	}
+	start := time.Now()
 	if incoming, err := c.frameCodec.DecodeFrame(source); err != nil {
 		abort = c.reportConnectionFailure(err, true)
 	} else {
+		if c.metrics != nil {
+			c.metrics.OnFrameDecoded(incoming, encodedFrameLength(incoming), time.Since(start))
+		}
+		if encoded != nil {
+			c.captures.onResponse(incoming, encoded.Bytes())
+		}
 		c.maybeSwitchToModernLayout(incoming)
 		abort = c.processIncomingFrame(incoming)
 	}
@@ -401,14 +581,44 @@ func (c *CqlClientConnection) maybeSwitchToModernLayout(incoming *frame.Frame) {
 }
 
 func (c *CqlClientConnection) writeFrame(outgoing *frame.Frame, dest io.Writer) (abort bool) {
-	if err := c.frameCodec.EncodeFrame(outgoing, dest); err != nil {
-		abort = c.reportConnectionFailure(err, false)
-	} else {
-		log.Debug().Msgf("%v: outgoing frame successfully written: %v", c, outgoing)
+	// encode the whole frame before writing it, to avoid issuing many small writes to the connection
+	encodedFrame := primitive.AcquireWriteBuffer()
+	defer primitive.ReleaseWriteBuffer(encodedFrame)
+	if abort = c.encodeFrame(outgoing, encodedFrame); !abort {
+		if _, err := encodedFrame.WriteTo(dest); err != nil {
+			abort = c.reportConnectionFailure(err, false)
+		} else {
+			log.Debug().Msgf("%v: outgoing frame successfully written: %v", c, outgoing)
+			c.onFrameWritten(outgoing)
+		}
 	}
 	return abort
 }
 
+func (c *CqlClientConnection) encodeFrame(outgoing *frame.Frame, dest *primitive.WriteBuffer) (abort bool) {
+	start := time.Now()
+	if err := c.frameCodec.EncodeFrame(outgoing, dest); err != nil {
+		return c.reportConnectionFailure(err, false)
+	}
+	if c.metrics != nil {
+		c.metrics.OnFrameEncoded(outgoing, dest.Len(), time.Since(start))
+		// track the request before writing it, since its response could be processed before the write returns
+		c.requestTracker.onRequest(outgoing)
+	}
+	c.captures.onRequest(outgoing, dest.Bytes())
+	return false
+}
+
+func (c *CqlClientConnection) onFrameWritten(outgoing *frame.Frame) {
+	if c.recorder != nil {
+		c.recorder.RecordFrame(c, FrameSent, outgoing)
+	}
+	invokeMiddlewares(c.middlewares, c, FrameSent, outgoing)
+	if c.metrics != nil {
+		c.metrics.OnRequestSent(outgoing)
+	}
+}
+
 func (c *CqlClientConnection) reportConnectionFailure(err error, read bool) (abort bool) {
 	if !c.IsClosed() {
 		if errors.Is(err, io.EOF) {
@@ -427,10 +637,17 @@ func (c *CqlClientConnection) reportConnectionFailure(err error, read bool) (abo
 
 func (c *CqlClientConnection) processIncomingFrame(incoming *frame.Frame) (abort bool) {
 	log.Debug().Msgf("%v: received incoming frame: %v", c, incoming)
+	defer c.scheduler.await(c.ctx, StepClientReceive, c.schedulerLabel, incoming.Header)()
+	c.heartbeat.onFrameReceived()
+	if c.recorder != nil {
+		c.recorder.RecordFrame(c, FrameReceived, incoming)
+	}
+	invokeMiddlewares(c.middlewares, c, FrameReceived, incoming)
 	if incoming.Header.OpCode == primitive.OpCodeEvent {
 		for _, handler := range c.handlers {
 			handler(incoming, c)
 		}
+		c.notifySubscribers(incoming)
 		select {
 		case c.events <- incoming:
 			log.Debug().Msgf("%v: incoming event frame successfully delivered: %v", c, incoming)
@@ -438,6 +655,14 @@ func (c *CqlClientConnection) processIncomingFrame(incoming *frame.Frame) (abort
 			log.Error().Msgf("%v: events queue is full, discarding event frame: %v", c, incoming)
 		}
 	} else {
+		if c.metrics != nil {
+			if request, latency := c.requestTracker.onResponse(incoming); request != nil {
+				c.metrics.OnResponseReceived(request, incoming, latency)
+			}
+		}
+		if c.preparedStatements != nil {
+			c.preparedStatements.onResponse(incoming)
+		}
 		if err := c.inFlightHandler.onIncomingFrameReceived(incoming); err != nil {
 			log.Error().Err(err).Msgf("%v: incoming frame delivery failed: %v", c, incoming)
 		} else {
@@ -466,20 +691,77 @@ func (c *CqlClientConnection) awaitDone() {
 
 // NewStartupRequest is a convenience method to create a new STARTUP request frame. The compression option will be
 // automatically set to the appropriate compression algorithm, depending on whether the connection was configured to
-// use a compressor. Use stream id zero to activate automatic stream id management.
+// use a compressor; other options are set according to CqlClient.StartupOptions. Use stream id zero to activate
+// automatic stream id management. Beta protocol versions can only be used if the connection was created by a client
+// with CqlClient.AllowBetaVersions set.
 func (c *CqlClientConnection) NewStartupRequest(version primitive.ProtocolVersion, streamId int16) (*frame.Frame, error) {
-	startup := message.NewStartup()
-	if c.compression != primitive.CompressionNone {
-		if version.SupportsCompression(c.compression) {
-			startup.SetCompression(c.compression)
-		} else {
-			return nil, fmt.Errorf("%v does not support compression %v", version, c.compression)
-		}
+	if err := c.checkBeta(version); err != nil {
+		return nil, err
 	}
+	startup := message.NewStartup()
 	startup.SetDriverName("DataStax Go client")
+	for key, value := range c.startupOptions {
+		startup.Options[key] = value
+	}
+	startup.SetCompression(c.compression)
+	if c.compression != primitive.CompressionNone && !version.SupportsCompression(c.compression) {
+		return nil, fmt.Errorf("%v does not support compression %v", version, c.compression)
+	}
 	return frame.NewFrame(version, streamId, startup), nil
 }
 
+// NewOptionsRequest is a convenience method to create a new OPTIONS request frame. Use stream id zero to activate
+// automatic stream id management. Beta protocol versions can only be used if the connection was created by a client
+// with CqlClient.AllowBetaVersions set.
+func (c *CqlClientConnection) NewOptionsRequest(version primitive.ProtocolVersion, streamId int16) (*frame.Frame, error) {
+	if err := c.checkBeta(version); err != nil {
+		return nil, err
+	}
+	return frame.NewFrame(version, streamId, &message.Options{}), nil
+}
+
+func (c *CqlClientConnection) checkBeta(version primitive.ProtocolVersion) error {
+	if version.IsBeta() && !c.allowBeta {
+		return fmt.Errorf("%v is a beta protocol version, set CqlClient.AllowBetaVersions to use it", version)
+	}
+	return nil
+}
+
+// Supported returns the SUPPORTED response received by the last handshake that sent an OPTIONS request, see
+// CqlClient.SendOptions, or by the last call to RequestOptions; it returns nil if no such response was received.
+func (c *CqlClientConnection) Supported() *message.Supported {
+	supported, _ := c.supported.Load().(*message.Supported)
+	return supported
+}
+
+// RequestOptions sends an OPTIONS request and returns the server's SUPPORTED response, which can also be obtained
+// later with Supported. It can be used before or after the handshake. Use stream id zero to activate automatic stream
+// id management.
+func (c *CqlClientConnection) RequestOptions(version primitive.ProtocolVersion, streamId int16) (*message.Supported, error) {
+	return c.requestOptions(context.Background(), version, streamId)
+}
+
+func (c *CqlClientConnection) requestOptions(
+	ctx context.Context,
+	version primitive.ProtocolVersion,
+	streamId int16,
+) (*message.Supported, error) {
+	request, err := c.NewOptionsRequest(version, streamId)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.SendAndReceiveContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("could not send OPTIONS: %w", err)
+	}
+	supported, ok := response.Body.Message.(*message.Supported)
+	if !ok {
+		return nil, fmt.Errorf("expected SUPPORTED, got %v", response.Body.Message)
+	}
+	c.supported.Store(supported)
+	return supported, nil
+}
+
 // InFlightRequest is an in-flight request sent through CqlClientConnection.Send.
 type InFlightRequest interface {
 
@@ -510,24 +792,92 @@ type InFlightRequest interface {
 // Stream id management: if the frame's stream id is ManagedStreamId (0), it is assumed that the frame's stream id is
 // to be automatically assigned by the connection upon write. Users are free to choose between managed stream ids or
 // manually assigned ones, but it is not recommended mixing managed stream ids with non-managed ones on the same
-// connection.
+// connection. When all stream ids are in use, managed stream ids are subject to CqlClient.StreamIdWaitTimeout.
 func (c *CqlClientConnection) Send(f *frame.Frame) (InFlightRequest, error) {
+	return c.SendWithTimeout(f, c.readTimeout)
+}
+
+// SendWithTimeout is like Send, but applies the given read timeout to the request instead of the connection's read
+// timeout.
+func (c *CqlClientConnection) SendWithTimeout(f *frame.Frame, timeout time.Duration) (InFlightRequest, error) {
+	return c.sendRequest(context.Background(), f, timeout)
+}
+
+// SendContext is like Send, but the given context controls the request: it interrupts the wait for rate limiters and
+// for a free stream id, and when it is done before the last response frame is received, the in-flight request is
+// closed with an error wrapping the context's error. The request's stream id is then released once a late response is
+// received and discarded, or at the latest after the connection's read timeout, so that it is never reused while a
+// response to the abandoned request may still arrive. The connection's read timeout still applies; use
+// context.WithTimeout or context.WithDeadline for shorter per-request timeouts.
+func (c *CqlClientConnection) SendContext(ctx context.Context, f *frame.Frame) (InFlightRequest, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("%v: context cannot be nil", c)
+	}
+	return c.sendRequest(ctx, f, c.readTimeout)
+}
+
+// SendWithCapture is like Send, but also returns a WireCapture holding the exact bytes of the request and of its
+// responses, once they are written and received.
+func (c *CqlClientConnection) SendWithCapture(f *frame.Frame) (InFlightRequest, *WireCapture, error) {
+	capture := &WireCapture{}
+	if inFlight, err := c.send(context.Background(), f, c.readTimeout, capture); err != nil {
+		return nil, nil, err
+	} else {
+		return inFlight, capture, nil
+	}
+}
+
+// SendAndReceiveWithCapture is like SendAndReceive, but also returns a WireCapture holding the exact bytes of the
+// request and of its response.
+func (c *CqlClientConnection) SendAndReceiveWithCapture(f *frame.Frame) (*frame.Frame, *WireCapture, error) {
+	if ch, capture, err := c.SendWithCapture(f); err != nil {
+		return nil, nil, err
+	} else if incoming, err := c.Receive(ch); err != nil {
+		return nil, nil, err
+	} else {
+		return incoming, capture, nil
+	}
+}
+
+func (c *CqlClientConnection) send(
+	ctx context.Context,
+	f *frame.Frame,
+	timeout time.Duration,
+	capture *WireCapture,
+) (InFlightRequest, error) {
 	if f == nil {
 		return nil, fmt.Errorf("%v: frame cannot be nil", c)
 	}
 	if c.IsClosed() {
 		return nil, fmt.Errorf("%v: connection closed", c)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%v: cannot send frame: %v: %w", c, f, err)
+	}
+	if err := c.throttle(ctx, f); err != nil {
+		return nil, err
+	}
 	log.Debug().Msgf("%v: enqueuing outgoing frame: %v", c, f)
-	if inFlight, err := c.inFlightHandler.onOutgoingFrameEnqueued(f); err != nil {
+	if inFlight, err := c.inFlightHandler.onOutgoingFrameEnqueued(ctx, f, timeout); err != nil {
 		return nil, fmt.Errorf("%v: failed to register in-flight handler for frame: %v: %w", c, f, err)
 	} else {
+		if c.preparedStatements != nil {
+			c.preparedStatements.onRequest(f)
+		}
+		if capture != nil {
+			c.captures.add(f.Header.StreamId, capture)
+		}
 		select {
 		case c.outgoing <- f:
 			log.Debug().Msgf("%v: outgoing frame successfully enqueued: %v", c, f)
 			return inFlight, nil
 		default:
-			return nil, fmt.Errorf("%v: failed to enqueue outgoing frame: %v", c, f)
+			if capture != nil {
+				c.captures.remove(f.Header.StreamId)
+			}
+			err := fmt.Errorf("%v: failed to enqueue outgoing frame: %v", c, f)
+			c.inFlightHandler.onOutgoingFrameDiscarded(f, inFlight, err)
+			return nil, err
 		}
 	}
 }
@@ -554,6 +904,37 @@ func (c *CqlClientConnection) Receive(ch InFlightRequest) (*frame.Frame, error)
 	}
 }
 
+// ReceiveContext is like Receive, but stops waiting when the given context is done, in which case an error wrapping
+// the context's error is returned. Unless the request was sent with SendContext using the same context, the in-flight
+// request itself is left untouched and can still be received from.
+func (c *CqlClientConnection) ReceiveContext(ctx context.Context, ch InFlightRequest) (*frame.Frame, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("%v: context cannot be nil", c)
+	}
+	if ch == nil {
+		return nil, fmt.Errorf("%v: response channel cannot be nil", c)
+	}
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%v: stopped waiting for incoming frame: %w", c, ctx.Err())
+	default:
+	}
+	select {
+	case incoming, ok := <-ch.Incoming():
+		if !ok {
+			if ch.Err() == nil {
+				log.Debug().Msgf("%v: in-flight request closed for stream id: %d", c, ch.StreamId())
+				return nil, nil
+			}
+			return nil, fmt.Errorf("%v: failed to retrieve incoming frame: %w", c, ch.Err())
+		}
+		log.Debug().Msgf("%v: incoming frame successfully received: %v", c, incoming)
+		return incoming, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%v: stopped waiting for incoming frame: %w", c, ctx.Err())
+	}
+}
+
 // SendAndReceive is a convenience method chaining a call to Send to a call to Receive.
 func (c *CqlClientConnection) SendAndReceive(f *frame.Frame) (*frame.Frame, error) {
 	if ch, err := c.Send(f); err != nil {
@@ -563,6 +944,16 @@ func (c *CqlClientConnection) SendAndReceive(f *frame.Frame) (*frame.Frame, erro
 	}
 }
 
+// SendAndReceiveContext is a convenience method chaining a call to SendContext to a call to ReceiveContext, with the
+// same context.
+func (c *CqlClientConnection) SendAndReceiveContext(ctx context.Context, f *frame.Frame) (*frame.Frame, error) {
+	if ch, err := c.SendContext(ctx, f); err != nil {
+		return nil, err
+	} else {
+		return c.ReceiveContext(ctx, ch)
+	}
+}
+
 // EventChannel is a receive-only channel for incoming events. A receive channel can be obtained through
 // CqlClientConnection.EventChannel.
 type EventChannel <-chan *frame.Frame
@@ -609,6 +1000,7 @@ func (c *CqlClientConnection) Close() (err error) {
 		c.events = nil
 		close(outgoing)
 		close(events)
+		c.closeSubscribers()
 		c.inFlightHandler.close()
 		c.waitGroup.Wait()
 		if err != nil {
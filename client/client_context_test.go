@@ -0,0 +1,141 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCqlClientConnection_SendContext(t *testing.T) {
+	// "hang" requests never get a response; "slow" requests get one after a short delay
+	handler := func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		if query, ok := request.Body.Message.(*message.Query); ok {
+			if query.Query == "slow" {
+				time.Sleep(100 * time.Millisecond)
+			} else if query.Query == "hang" {
+				return nil
+			}
+		}
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+	}
+	newQuery := func(query string) *frame.Frame {
+		return frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: query})
+	}
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{handler}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.MaxInFlight = 1
+	clt.ReadTimeout = 500 * time.Millisecond
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+
+	t.Run("context already done", func(t *testing.T) {
+		requestCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := clientConn.SendContext(requestCtx, newQuery("fast"))
+		require.True(t, errors.Is(err, context.Canceled))
+		err = clientConn.InitiateHandshakeContext(requestCtx, primitive.ProtocolVersion4, client.ManagedStreamId)
+		require.True(t, errors.Is(err, context.Canceled))
+		// the stream id was not borrowed
+		response, err := clientConn.SendAndReceive(newQuery("fast"))
+		require.NoError(t, err)
+		assert.Equal(t, &message.VoidResult{}, response.Body.Message)
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		requestCtx, cancel := context.WithCancel(context.Background())
+		inFlight, err := clientConn.SendContext(requestCtx, newQuery("hang"))
+		require.NoError(t, err)
+		cancel()
+		_, err = clientConn.Receive(inFlight)
+		require.True(t, errors.Is(err, context.Canceled))
+		assert.True(t, inFlight.IsDone())
+		// the stream id of the abandoned request is only released after the read timeout
+		_, err = clientConn.Send(newQuery("fast"))
+		var exhausted *client.StreamIdsExhaustedError
+		require.True(t, errors.As(err, &exhausted))
+		assert.Eventually(t, func() bool {
+			_, err := clientConn.SendAndReceive(newQuery("fast"))
+			return err == nil
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("deadline with late response", func(t *testing.T) {
+		requestCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := clientConn.SendAndReceiveContext(requestCtx, newQuery("slow"))
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		// the late response releases the stream id, before the read timeout elapses
+		assert.Eventually(t, func() bool {
+			_, err := clientConn.SendAndReceive(newQuery("fast"))
+			return err == nil
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Less(t, int64(time.Since(start)), int64(clt.ReadTimeout))
+	})
+
+	t.Run("receive context", func(t *testing.T) {
+		inFlight, err := clientConn.Send(newQuery("slow"))
+		require.NoError(t, err)
+		receiveCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = clientConn.ReceiveContext(receiveCtx, inFlight)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		// the in-flight request itself is left untouched
+		response, err := clientConn.ReceiveContext(context.Background(), inFlight)
+		require.NoError(t, err)
+		assert.Equal(t, &message.VoidResult{}, response.Body.Message)
+	})
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestCqlClient_ConnectAndInit_Context(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+	handshakeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, clientConn.InitiateHandshakeContext(handshakeCtx, primitive.ProtocolVersion4, client.ManagedStreamId))
+	require.NoError(t, clientConn.Close())
+
+	clientConn, err = clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
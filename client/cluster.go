@@ -0,0 +1,521 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Cluster simulates a multi-node cluster in-process, e.g. to test the failover logic of drivers. Each node is a
+// CqlServer listening to its own address; all nodes share a common cluster state: the cluster name, the schema
+// version, and the token ring. Nodes handle handshakes, heartbeats, USE and REGISTER requests, as well as the queries
+// that drivers issue to discover the cluster topology: system.local returns the node itself, and system.peers returns
+// all the other nodes, whether they are up or down.
+//
+// Nodes can be taken down and up, added and removed; the corresponding STATUS_CHANGE and TOPOLOGY_CHANGE events are
+// sent to the client connections registered for them on the other nodes that are up, as Apache Cassandra does. Tokens
+// are evenly distributed among nodes, and redistributed when nodes are added or removed.
+//
+// Nodes share the same port if their listen addresses only differ by their IP, e.g. 127.0.0.1:9042, 127.0.0.2:9042;
+// this is what drivers usually expect, but requires the loopback interface to accept these addresses.
+type Cluster struct {
+	// Name is the cluster name, as returned in system.local.
+	Name string
+	// Datacenter is the datacenter of all the nodes.
+	Datacenter string
+	// Credentials are the AuthCredentials of all the nodes. If nil, no authentication will be used.
+	Credentials *AuthCredentials
+	// RequestHandlers is an optional list of handlers to handle the requests that the nodes do not handle themselves.
+	// It must be set before the cluster is started.
+	RequestHandlers []RequestHandler
+
+	nodes         []*ClusterNode
+	schemaVersion primitive.UUID
+	ctx           context.Context
+	lock          sync.RWMutex
+}
+
+// ClusterNode is a node of a Cluster. ClusterNode instances should be created by calling NewCluster or
+// Cluster.AddNode.
+type ClusterNode struct {
+	cluster       *Cluster
+	listenAddress string
+	address       *net.TCPAddr
+	hostId        primitive.UUID
+	// tokens are guarded by the cluster lock.
+	tokens        []string
+	server        *CqlServer
+	maxVersion    primitive.ProtocolVersion
+	registrations map[*CqlServerConnection]*eventRegistration
+	lock          sync.Mutex
+}
+
+// eventRegistration is a REGISTER request received by a node.
+type eventRegistration struct {
+	version    primitive.ProtocolVersion
+	eventTypes []primitive.EventType
+}
+
+var systemPeersColumns = []*message.ColumnMetadata{
+	{Keyspace: "system", Table: "peers", Name: "peer", Type: datatype.Inet},
+	{Keyspace: "system", Table: "peers", Name: "data_center", Type: datatype.Varchar},
+	{Keyspace: "system", Table: "peers", Name: "host_id", Type: datatype.Uuid},
+	{Keyspace: "system", Table: "peers", Name: "rack", Type: datatype.Varchar},
+	{Keyspace: "system", Table: "peers", Name: "release_version", Type: datatype.Varchar},
+	{Keyspace: "system", Table: "peers", Name: "rpc_address", Type: datatype.Inet},
+	{Keyspace: "system", Table: "peers", Name: "schema_version", Type: datatype.Uuid},
+	{Keyspace: "system", Table: "peers", Name: "tokens", Type: datatype.NewSet(datatype.Varchar)},
+}
+
+// NewCluster creates a new Cluster with one node per listen address. The cluster must be started with Start.
+func NewCluster(name string, datacenter string, listenAddresses ...string) (*Cluster, error) {
+	if len(listenAddresses) == 0 {
+		return nil, fmt.Errorf("cannot create cluster %v: at least one listen address must be provided", name)
+	}
+	cluster := &Cluster{
+		Name:          name,
+		Datacenter:    datacenter,
+		schemaVersion: *primitive.NewTimeUuid(),
+	}
+	for _, listenAddress := range listenAddresses {
+		if node, err := cluster.newNode(listenAddress); err != nil {
+			return nil, fmt.Errorf("cannot create cluster %v: %w", name, err)
+		} else {
+			cluster.nodes = append(cluster.nodes, node)
+		}
+	}
+	cluster.assignTokens()
+	return cluster, nil
+}
+
+func (c *Cluster) String() string {
+	return fmt.Sprintf("CQL cluster [%v]", c.Name)
+}
+
+// Start starts all the nodes of the cluster. Set ctx to context.Background if no parent context exists.
+func (c *Cluster) Start(ctx context.Context) error {
+	if ctx == nil {
+		return fmt.Errorf("%v: context cannot be nil", c)
+	}
+	c.lock.Lock()
+	if c.ctx != nil {
+		c.lock.Unlock()
+		return fmt.Errorf("%v: already started", c)
+	}
+	c.ctx = ctx
+	nodes := c.copyNodes()
+	c.lock.Unlock()
+	for _, node := range nodes {
+		if err := node.start(); err != nil {
+			_ = c.Close()
+			return fmt.Errorf("%v: start failed: %w", c, err)
+		}
+	}
+	log.Info().Msgf("%v: successfully started %d nodes", c, len(nodes))
+	return nil
+}
+
+// Close stops all the nodes of the cluster that are up.
+func (c *Cluster) Close() (err error) {
+	for _, node := range c.Nodes() {
+		if closeErr := node.stop(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// Nodes returns the nodes of the cluster, in the order they were added.
+func (c *Cluster) Nodes() []*ClusterNode {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.copyNodes()
+}
+
+func (c *Cluster) copyNodes() []*ClusterNode {
+	return append([]*ClusterNode(nil), c.nodes...)
+}
+
+// SchemaVersion returns the schema version shared by all the nodes.
+func (c *Cluster) SchemaVersion() primitive.UUID {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.schemaVersion
+}
+
+// SetSchemaVersion changes the schema version shared by all the nodes.
+func (c *Cluster) SetSchemaVersion(schemaVersion primitive.UUID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.schemaVersion = schemaVersion
+}
+
+// AddNode adds a new node listening to the given address, and starts it if the cluster was started, in which case a
+// TOPOLOGY_CHANGE event of type NEW_NODE is sent to the registered client connections.
+func (c *Cluster) AddNode(listenAddress string) (*ClusterNode, error) {
+	node, err := c.newNode(listenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("%v: cannot add node: %w", c, err)
+	}
+	c.lock.Lock()
+	c.nodes = append(c.nodes, node)
+	c.assignTokens()
+	started := c.ctx != nil
+	c.lock.Unlock()
+	if started {
+		if err = node.start(); err != nil {
+			return nil, fmt.Errorf("%v: cannot start node %v: %w", c, node, err)
+		}
+		c.sendEvent(primitive.EventTypeTopologyChange, &message.TopologyChangeEvent{
+			ChangeType: primitive.TopologyChangeTypeNewNode,
+			Address:    node.Address(),
+		}, node)
+	}
+	log.Info().Msgf("%v: node added: %v", c, node)
+	return node, nil
+}
+
+// RemoveNode stops the given node if it is up, and removes it from the cluster; a TOPOLOGY_CHANGE event of type
+// REMOVED_NODE is sent to the registered client connections.
+func (c *Cluster) RemoveNode(node *ClusterNode) error {
+	c.lock.Lock()
+	found := false
+	for i, n := range c.nodes {
+		if n == node {
+			c.nodes = append(c.nodes[:i], c.nodes[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if found {
+		c.assignTokens()
+	}
+	c.lock.Unlock()
+	if !found {
+		return fmt.Errorf("%v: cannot remove node %v: not found", c, node)
+	}
+	if err := node.stop(); err != nil {
+		return fmt.Errorf("%v: cannot remove node %v: %w", c, node, err)
+	}
+	c.sendEvent(primitive.EventTypeTopologyChange, &message.TopologyChangeEvent{
+		ChangeType: primitive.TopologyChangeTypeRemovedNode,
+		Address:    node.Address(),
+	}, node)
+	log.Info().Msgf("%v: node removed: %v", c, node)
+	return nil
+}
+
+func (c *Cluster) newNode(listenAddress string) (*ClusterNode, error) {
+	address, err := net.ResolveTCPAddr("tcp", listenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid listen address %v: %w", listenAddress, err)
+	}
+	return &ClusterNode{
+		cluster:       c,
+		listenAddress: listenAddress,
+		address:       address,
+		hostId:        *primitive.NewTimeUuid(),
+		registrations: make(map[*CqlServerConnection]*eventRegistration),
+	}, nil
+}
+
+// assignTokens distributes tokens evenly among nodes, in the Murmur3 token range; the caller must hold the lock.
+func (c *Cluster) assignTokens() {
+	if len(c.nodes) == 0 {
+		return
+	}
+	step := math.MaxUint64 / uint64(len(c.nodes))
+	for i, node := range c.nodes {
+		var token int64 = math.MinInt64
+		token += int64(uint64(i) * step)
+		node.tokens = []string{strconv.FormatInt(token, 10)}
+	}
+}
+
+// sendEvent sends the given event to the client connections registered for its type, on all the nodes that are up
+// except the given one.
+func (c *Cluster) sendEvent(eventType primitive.EventType, event message.Event, except *ClusterNode) {
+	for _, node := range c.Nodes() {
+		if node != except {
+			node.sendEvent(eventType, event)
+		}
+	}
+}
+
+func (n *ClusterNode) String() string {
+	return fmt.Sprintf("CQL cluster node [%v]", n.listenAddress)
+}
+
+// ListenAddress returns the address the node listens to.
+func (n *ClusterNode) ListenAddress() string {
+	return n.listenAddress
+}
+
+// Address returns the address of the node, as found in events and system tables.
+func (n *ClusterNode) Address() *primitive.Inet {
+	return &primitive.Inet{Addr: n.address.IP, Port: int32(n.address.Port)}
+}
+
+// HostId returns the host id of the node.
+func (n *ClusterNode) HostId() primitive.UUID {
+	return n.hostId
+}
+
+// Tokens returns the tokens currently owned by the node.
+func (n *ClusterNode) Tokens() []string {
+	n.cluster.lock.RLock()
+	defer n.cluster.lock.RUnlock()
+	return append([]string(nil), n.tokens...)
+}
+
+// Server returns the CqlServer of the node, or nil if the node is down. A new CqlServer is created each time the node
+// is brought up.
+func (n *ClusterNode) Server() *CqlServer {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.server
+}
+
+// IsUp returns true if the node is up.
+func (n *ClusterNode) IsUp() bool {
+	return n.Server() != nil
+}
+
+// Down stops the node, thus closing all its client connections, and sends a STATUS_CHANGE event of type DOWN to the
+// client connections registered on the other nodes. The node remains part of the cluster.
+func (n *ClusterNode) Down() error {
+	if !n.IsUp() {
+		return fmt.Errorf("%v: already down", n)
+	} else if err := n.stop(); err != nil {
+		return err
+	}
+	n.cluster.sendEvent(primitive.EventTypeStatusChange, &message.StatusChangeEvent{
+		ChangeType: primitive.StatusChangeTypeDown,
+		Address:    n.Address(),
+	}, n)
+	log.Info().Msgf("%v: node is down", n)
+	return nil
+}
+
+// Up starts the node again after Down, and sends a STATUS_CHANGE event of type UP to the client connections
+// registered on the other nodes.
+func (n *ClusterNode) Up() error {
+	if n.IsUp() {
+		return fmt.Errorf("%v: already up", n)
+	} else if err := n.start(); err != nil {
+		return err
+	}
+	n.cluster.sendEvent(primitive.EventTypeStatusChange, &message.StatusChangeEvent{
+		ChangeType: primitive.StatusChangeTypeUp,
+		Address:    n.Address(),
+	}, n)
+	log.Info().Msgf("%v: node is up", n)
+	return nil
+}
+
+// GetMaxProtocolVersion returns the highest protocol version accepted by the node, or zero if all versions are
+// accepted, which is the default.
+func (n *ClusterNode) GetMaxProtocolVersion() primitive.ProtocolVersion {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.maxVersion
+}
+
+// SetMaxProtocolVersion changes the highest protocol version accepted by the node, see
+// CqlServer.SetMaxProtocolVersion; the setting survives Down and Up. Calling it for one node after another simulates
+// a rolling upgrade or downgrade of the cluster.
+func (n *ClusterNode) SetMaxProtocolVersion(version primitive.ProtocolVersion) error {
+	if version != 0 {
+		if err := primitive.CheckSupportedProtocolVersion(version); err != nil {
+			return fmt.Errorf("%v: cannot set max protocol version: %w", n, err)
+		}
+	}
+	n.lock.Lock()
+	n.maxVersion = version
+	server := n.server
+	n.lock.Unlock()
+	if server != nil {
+		// the lock must not be held here, since connections are reset
+		return server.SetMaxProtocolVersion(version)
+	}
+	return nil
+}
+
+func (n *ClusterNode) start() error {
+	n.cluster.lock.RLock()
+	ctx := n.cluster.ctx
+	n.cluster.lock.RUnlock()
+	if ctx == nil {
+		return fmt.Errorf("%v: cluster not started", n)
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	server := NewCqlServer(n.listenAddress, n.cluster.Credentials)
+	server.RequestHandlers = append([]RequestHandler{n.handleRequest}, n.cluster.RequestHandlers...)
+	if err := server.SetMaxProtocolVersion(n.maxVersion); err != nil {
+		return err
+	} else if err = server.Start(ctx); err != nil {
+		return err
+	}
+	n.server = server
+	return nil
+}
+
+func (n *ClusterNode) stop() error {
+	n.lock.Lock()
+	server := n.server
+	n.server = nil
+	n.registrations = make(map[*CqlServerConnection]*eventRegistration)
+	n.lock.Unlock()
+	if server == nil {
+		return nil
+	}
+	// the lock must not be held here, since closing the server waits for request handlers to complete
+	return server.Close()
+}
+
+func (n *ClusterNode) sendEvent(eventType primitive.EventType, event message.Event) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for conn, registration := range n.registrations {
+		if conn.IsClosed() {
+			delete(n.registrations, conn)
+			continue
+		}
+		for _, registeredType := range registration.eventTypes {
+			if registeredType == eventType {
+				if err := conn.Send(frame.NewFrame(registration.version, EventStreamId, event)); err != nil {
+					log.Error().Err(err).Msgf("%v: cannot send event %v to %v", n, event, conn)
+				}
+				break
+			}
+		}
+	}
+}
+
+func (n *ClusterNode) handleRequest(request *frame.Frame, conn *CqlServerConnection, ctx RequestHandlerContext) *frame.Frame {
+	switch msg := request.Body.Message.(type) {
+	case *message.Register:
+		n.lock.Lock()
+		n.registrations[conn] = &eventRegistration{version: request.Header.Version, eventTypes: msg.EventTypes}
+		n.lock.Unlock()
+		log.Debug().Msgf("%v: [cluster node]: received REGISTER: %v", conn, msg.EventTypes)
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Ready{})
+	case *message.Query:
+		if response := n.handleSystemQuery(msg, request); response != nil {
+			return response
+		}
+	}
+	return NewCompositeRequestHandler(HeartbeatHandler, HandshakeHandler, NewSetKeyspaceHandler(func(string) {}))(
+		request,
+		conn,
+		ctx,
+	)
+}
+
+func (n *ClusterNode) handleSystemQuery(query *message.Query, request *frame.Frame) *frame.Frame {
+	q := strings.Join(strings.Fields(strings.ToLower(query.Query)), " ")
+	var columns []*message.ColumnMetadata
+	var rows [][]interface{}
+	n.cluster.lock.RLock()
+	switch {
+	case strings.HasPrefix(q, "select * from system.local"):
+		columns = systemLocalColumns
+		rows = [][]interface{}{n.systemLocalValues()}
+	case strings.HasPrefix(q, "select schema_version from system.local"):
+		columns = []*message.ColumnMetadata{schemaVersionColumn}
+		rows = [][]interface{}{{n.cluster.schemaVersion}}
+	case strings.HasPrefix(q, "select cluster_name from system.local"):
+		columns = []*message.ColumnMetadata{clusterNameColumn}
+		rows = [][]interface{}{{n.cluster.Name}}
+	case strings.Contains(q, "from system.peers_v2"):
+		n.cluster.lock.RUnlock()
+		// like Apache Cassandra 3, so that drivers fall back to system.peers
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Invalid{
+			ErrorMessage: "unconfigured table peers_v2",
+		})
+	case strings.Contains(q, "from system.peers"):
+		columns = systemPeersColumns
+		rows = [][]interface{}{}
+		for _, peer := range n.cluster.nodes {
+			if peer != n {
+				rows = append(rows, peer.systemPeersValues())
+			}
+		}
+	}
+	n.cluster.lock.RUnlock()
+	if columns == nil {
+		return nil
+	}
+	data := make(message.RowSet, len(rows))
+	for i, values := range rows {
+		var err error
+		if data[i], err = encodeRow(columns, values, request.Header.Version); err != nil {
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.ServerError{
+				ErrorMessage: fmt.Sprintf("cannot encode system table row: %v", err),
+			})
+		}
+	}
+	return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.RowsResult{
+		Metadata: &message.RowsMetadata{ColumnCount: int32(len(columns)), Columns: columns},
+		Data:     data,
+	})
+}
+
+// systemLocalValues returns the values of the systemLocalColumns for this node; the caller must hold the cluster lock.
+func (n *ClusterNode) systemLocalValues() []interface{} {
+	return []interface{}{
+		string(keyValue),
+		n.address.IP,
+		n.cluster.Name,
+		string(cqlVersionValue),
+		n.cluster.Datacenter,
+		n.hostId,
+		n.address.IP,
+		string(partitionerValue),
+		string(rackValue),
+		string(releaseVersionValue),
+		n.address.IP,
+		n.cluster.schemaVersion,
+		n.tokens,
+	}
+}
+
+// systemPeersValues returns the values of the systemPeersColumns for this node; the caller must hold the cluster lock.
+func (n *ClusterNode) systemPeersValues() []interface{} {
+	return []interface{}{
+		n.address.IP,
+		n.cluster.Datacenter,
+		n.hostId,
+		string(rackValue),
+		string(releaseVersionValue),
+		n.address.IP,
+		n.cluster.schemaVersion,
+		n.tokens,
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCluster(t *testing.T) {
+	cluster, err := client.NewCluster("cluster1", "dc1", "127.0.0.1:9043", "127.0.0.1:9044", "127.0.0.1:9045")
+	require.NoError(t, err)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, cluster.Start(ctx))
+	defer func() { _ = cluster.Close() }()
+	nodes := cluster.Nodes()
+	require.Len(t, nodes, 3)
+	assert.Equal(t, []string{"-9223372036854775808"}, nodes[0].Tokens())
+	assert.Equal(t, []string{"-3074457345618258603"}, nodes[1].Tokens())
+	assert.Equal(t, []string{"3074457345618258602"}, nodes[2].Tokens())
+
+	clientConn, err := client.NewCqlClient("127.0.0.1:9043", nil).ConnectAndInit(ctx, primitive.ProtocolVersion4, 1)
+	require.NoError(t, err)
+	subscriber, err := clientConn.RegisterForEvents(
+		ctx,
+		primitive.ProtocolVersion4,
+		primitive.EventTypeStatusChange,
+		primitive.EventTypeTopologyChange,
+	)
+	require.NoError(t, err)
+
+	// system tables
+	local := querySystemTable(t, clientConn, "SELECT * FROM system.local")
+	require.Len(t, local.Data, 1)
+	hostId := nodes[0].HostId()
+	assert.Equal(t, message.Column(hostId[:]), local.Data[0][5])
+	peers := querySystemTable(t, clientConn, "SELECT * FROM system.peers")
+	require.Len(t, peers.Data, 2)
+	assert.Equal(t, message.Column(nodes[1].Address().Addr.To4()), peers.Data[0][0])
+	assert.Equal(t, message.Column(nodes[2].Address().Addr.To4()), peers.Data[1][0])
+
+	// status changes
+	require.NoError(t, nodes[1].Down())
+	assert.False(t, nodes[1].IsUp())
+	assert.Error(t, nodes[1].Down())
+	assert.Equal(t, &message.StatusChangeEvent{
+		ChangeType: primitive.StatusChangeTypeDown,
+		Address:    nodes[1].Address(),
+	}, receiveEvent(t, subscriber))
+	require.NoError(t, nodes[1].Up())
+	assert.True(t, nodes[1].IsUp())
+	assert.Equal(t, &message.StatusChangeEvent{
+		ChangeType: primitive.StatusChangeTypeUp,
+		Address:    nodes[1].Address(),
+	}, receiveEvent(t, subscriber))
+
+	// topology changes
+	node3, err := cluster.AddNode("127.0.0.1:9046")
+	require.NoError(t, err)
+	assert.Equal(t, &message.TopologyChangeEvent{
+		ChangeType: primitive.TopologyChangeTypeNewNode,
+		Address:    node3.Address(),
+	}, receiveEvent(t, subscriber))
+	assert.Equal(t, []string{"4611686018427387901"}, node3.Tokens())
+	assert.Len(t, querySystemTable(t, clientConn, "SELECT * FROM system.peers").Data, 3)
+	require.NoError(t, cluster.RemoveNode(node3))
+	assert.Equal(t, &message.TopologyChangeEvent{
+		ChangeType: primitive.TopologyChangeTypeRemovedNode,
+		Address:    node3.Address(),
+	}, receiveEvent(t, subscriber))
+	assert.Error(t, cluster.RemoveNode(node3))
+	assert.Len(t, querySystemTable(t, clientConn, "SELECT * FROM system.peers").Data, 2)
+
+	// protocol version downgrade, surviving restarts
+	require.NoError(t, nodes[2].SetMaxProtocolVersion(primitive.ProtocolVersion3))
+	require.NoError(t, nodes[2].Down())
+	require.NoError(t, nodes[2].Up())
+	assert.Equal(t, primitive.ProtocolVersion3, nodes[2].Server().GetMaxProtocolVersion())
+	_, err = client.NewCqlClient("127.0.0.1:9045", nil).ConnectAndInit(ctx, primitive.ProtocolVersion4, 1)
+	assert.Error(t, err)
+	conn3, err := client.NewCqlClient("127.0.0.1:9045", nil).ConnectAndInit(ctx, primitive.ProtocolVersion3, 1)
+	require.NoError(t, err)
+	require.NoError(t, conn3.Close())
+
+	require.NoError(t, cluster.Close())
+	for _, node := range nodes {
+		assert.False(t, node.IsUp())
+	}
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func querySystemTable(t *testing.T, clientConn *client.CqlClientConnection, query string) *message.RowsResult {
+	request := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: query})
+	response, err := clientConn.SendAndReceive(request)
+	require.NoError(t, err)
+	require.IsType(t, &message.RowsResult{}, response.Body.Message)
+	return response.Body.Message.(*message.RowsResult)
+}
+
+func TestCluster_RemoveLastNode(t *testing.T) {
+	cluster, err := client.NewCluster("cluster1", "dc1", "127.0.0.1:9043")
+	require.NoError(t, err)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, cluster.Start(ctx))
+
+	require.NoError(t, cluster.RemoveNode(cluster.Nodes()[0]))
+	assert.Empty(t, cluster.Nodes())
+	node, err := cluster.AddNode("127.0.0.1:9044")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-9223372036854775808"}, node.Tokens())
+	require.NoError(t, cluster.Close())
+}
@@ -15,9 +15,12 @@
 package client
 
 import (
+	"fmt"
+
 	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
 	"github.com/datastax/go-cassandra-native-protocol/compression/snappy"
 	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 	"github.com/datastax/go-cassandra-native-protocol/segment"
 )
@@ -35,6 +38,15 @@ func NewBodyCompressor(c primitive.Compression) frame.BodyCompressor {
 	}
 }
 
+// newFrameCodec creates a frame codec using the given compression, and optionally allowing beta protocol versions.
+func newFrameCodec(c primitive.Compression, allowBeta bool) frame.RawCodec {
+	builder := frame.NewCodecBuilder().WithCompressor(NewBodyCompressor(c))
+	if allowBeta {
+		builder.WithBetaVersions()
+	}
+	return builder.Build()
+}
+
 func NewPayloadCompressor(c primitive.Compression) segment.PayloadCompressor {
 	switch c {
 	case primitive.CompressionNone:
@@ -48,3 +60,75 @@ func NewPayloadCompressor(c primitive.Compression) segment.PayloadCompressor {
 		return nil
 	}
 }
+
+// CompressionAlgorithm is implemented by compressors that know the compression algorithm they implement, such as
+// lz4.Compressor and snappy.Compressor. See NegotiateCompression.
+type CompressionAlgorithm interface {
+	Algorithm() primitive.Compression
+}
+
+// NegotiatedCompression is the outcome of a compression negotiation, see NegotiateCompression.
+type NegotiatedCompression struct {
+	// Compression is the chosen algorithm, or primitive.CompressionNone if compression should not be used.
+	Compression primitive.Compression
+	// BodyCompressor is the compressor implementing the chosen algorithm, or nil if compression should not be used.
+	BodyCompressor frame.BodyCompressor
+	// PayloadCompressor is the compressor implementing the chosen algorithm, if it can also compress segment payloads,
+	// or nil.
+	PayloadCompressor segment.PayloadCompressor
+}
+
+// NegotiateCompression picks the first of the given locally available compressors, in order of preference, whose
+// algorithm is advertised by the given SUPPORTED response and supported by the given protocol version; see
+// message.SupportedOptions.NegotiateCompression. With protocol v5 and higher, only compressors that also implement
+// segment.PayloadCompressor are considered, since compression then applies to segments. Use the returned
+// NegotiatedCompression to create the STARTUP request and the codecs to use once it is accepted. It returns an error
+// if a compressor does not implement CompressionAlgorithm.
+func NegotiateCompression(
+	supported *message.Supported,
+	version primitive.ProtocolVersion,
+	compressors ...frame.BodyCompressor,
+) (*NegotiatedCompression, error) {
+	var algorithms []primitive.Compression
+	candidates := map[primitive.Compression]frame.BodyCompressor{}
+	for _, compressor := range compressors {
+		named, ok := compressor.(CompressionAlgorithm)
+		if !ok {
+			return nil, fmt.Errorf(
+				"cannot negotiate compression: compressor %T does not implement CompressionAlgorithm", compressor)
+		}
+		if _, payload := compressor.(segment.PayloadCompressor); payload || !version.SupportsModernFramingLayout() {
+			if _, found := candidates[named.Algorithm()]; !found {
+				algorithms = append(algorithms, named.Algorithm())
+				candidates[named.Algorithm()] = compressor
+			}
+		}
+	}
+	options := &message.SupportedOptions{CompressionAlgorithms: supported.Compression()}
+	negotiated := &NegotiatedCompression{Compression: options.NegotiateCompression(version, algorithms...)}
+	if negotiated.Compression != primitive.CompressionNone {
+		negotiated.BodyCompressor = candidates[negotiated.Compression]
+		negotiated.PayloadCompressor, _ = negotiated.BodyCompressor.(segment.PayloadCompressor)
+	}
+	return negotiated, nil
+}
+
+// NewStartup creates a STARTUP request with the given options, see message.NewStartup, requesting the negotiated
+// compression, if any.
+func (n *NegotiatedCompression) NewStartup(keysAndValues ...string) *message.Startup {
+	startup := message.NewStartup(keysAndValues...)
+	startup.SetCompression(n.Compression)
+	return startup
+}
+
+// NewFrameCodec creates a frame codec compressing frame bodies with the negotiated compression, if any, as required
+// by the legacy framing layout, or by the frames exchanged before the modern framing layout is in use.
+func (n *NegotiatedCompression) NewFrameCodec(messageCodecs ...message.Codec) frame.RawCodec {
+	return frame.NewRawCodecWithCompression(n.BodyCompressor, messageCodecs...)
+}
+
+// NewSegmentCodec creates a segment codec compressing segment payloads with the negotiated compression, if any, as
+// required by the modern framing layout.
+func (n *NegotiatedCompression) NewSegmentCodec() segment.Codec {
+	return segment.NewCodecWithCompression(n.PayloadCompressor)
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+	"github.com/datastax/go-cassandra-native-protocol/compression/snappy"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+type unnamedCompressor struct{}
+
+func (unnamedCompressor) CompressWithLength(io.Reader, io.Writer) error   { return nil }
+func (unnamedCompressor) DecompressWithLength(io.Reader, io.Writer) error { return nil }
+
+func TestNegotiateCompression(t *testing.T) {
+	supported := &message.Supported{Options: map[string][]string{message.SupportedCompression: {"snappy", "lz4"}}}
+
+	negotiated, err := NegotiateCompression(supported, primitive.ProtocolVersion4, &snappy.Compressor{}, &lz4.Compressor{})
+	require.NoError(t, err)
+	assert.Equal(t, &NegotiatedCompression{
+		Compression:    primitive.CompressionSnappy,
+		BodyCompressor: &snappy.Compressor{},
+	}, negotiated)
+	assert.Equal(t, "SNAPPY", negotiated.NewStartup().Options[message.StartupOptionCompression])
+
+	// snappy cannot compress segments
+	negotiated, err = NegotiateCompression(supported, primitive.ProtocolVersion5, &snappy.Compressor{}, &lz4.Compressor{})
+	require.NoError(t, err)
+	assert.Equal(t, &NegotiatedCompression{
+		Compression:       primitive.CompressionLz4,
+		BodyCompressor:    &lz4.Compressor{},
+		PayloadCompressor: &lz4.Compressor{},
+	}, negotiated)
+	startup := negotiated.NewStartup(message.StartupOptionDriverName, "test")
+	assert.Equal(t, map[string]string{
+		message.StartupOptionCqlVersion:  "3.0.0",
+		message.StartupOptionDriverName:  "test",
+		message.StartupOptionCompression: "LZ4",
+	}, startup.Options)
+
+	// the codecs compress with the negotiated algorithm
+	request := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Query{
+		Query:   "SELECT * FROM system.local",
+		Options: &message.QueryOptions{},
+	})
+	request.SetCompress(true)
+	encoded := &bytes.Buffer{}
+	require.NoError(t, negotiated.NewFrameCodec().EncodeFrame(request, encoded))
+	decoded, err := frame.NewCodecWithCompression(&lz4.Compressor{}).DecodeFrame(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, request.Body, decoded.Body)
+	assert.NotNil(t, negotiated.NewSegmentCodec())
+
+	negotiated, err = NegotiateCompression(&message.Supported{}, primitive.ProtocolVersion4, &lz4.Compressor{})
+	require.NoError(t, err)
+	assert.Equal(t, &NegotiatedCompression{Compression: primitive.CompressionNone}, negotiated)
+	_, found := negotiated.NewStartup().Options[message.StartupOptionCompression]
+	assert.False(t, found)
+
+	_, err = NegotiateCompression(supported, primitive.ProtocolVersion4, unnamedCompressor{})
+	assert.EqualError(t, err,
+		"cannot negotiate compression: compressor client.unnamedCompressor does not implement CompressionAlgorithm")
+}
@@ -0,0 +1,212 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ContinuousPagingSession is a continuous paging query in progress, started with
+// CqlClientConnection.StartContinuousPaging. Continuous paging is a feature specific to DataStax Enterprise: the
+// server pushes successive pages of results on the stream id of the query, until the last page is sent, without the
+// client having to request each page.
+//
+// The stream id of the query remains in use for the whole session: it is only released once the last page, or an
+// error, is received, even if the session was cancelled, so that late pages pushed by the server are never mistaken
+// for responses to other requests.
+type ContinuousPagingSession struct {
+	conn     *CqlClientConnection
+	version  primitive.ProtocolVersion
+	inFlight InFlightRequest
+	pages    chan *frame.Frame
+	err      error
+	done     chan struct{}
+	// cancelled is closed when Cancel is called; pages received afterwards are discarded.
+	cancelled chan struct{}
+	lock      sync.Mutex
+}
+
+// StartContinuousPaging sends the given QUERY or EXECUTE request, which must have continuous paging options, and
+// returns a ContinuousPagingSession to receive the successive pages of results. Only DSE protocol versions support
+// continuous paging. Use ManagedStreamId to let the connection assign a stream id to the query.
+func (c *CqlClientConnection) StartContinuousPaging(query *frame.Frame) (*ContinuousPagingSession, error) {
+	if query == nil {
+		return nil, fmt.Errorf("%v: frame cannot be nil", c)
+	} else if !query.Header.Version.IsDse() {
+		return nil, fmt.Errorf("%v: continuous paging requires a DSE protocol version, got: %v", c, query.Header.Version)
+	}
+	var options *message.QueryOptions
+	switch msg := query.Body.Message.(type) {
+	case *message.Query:
+		options = msg.Options
+	case *message.Execute:
+		options = msg.Options
+	default:
+		return nil, fmt.Errorf("%v: continuous paging requires a QUERY or EXECUTE request, got: %v", c, query.Body.Message)
+	}
+	if options == nil || options.ContinuousPagingOptions == nil {
+		return nil, fmt.Errorf("%v: continuous paging requires continuous paging options", c)
+	}
+	inFlight, err := c.Send(query)
+	if err != nil {
+		return nil, err
+	}
+	session := &ContinuousPagingSession{
+		conn:      c,
+		version:   query.Header.Version,
+		inFlight:  inFlight,
+		pages:     make(chan *frame.Frame),
+		done:      make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+	go session.receivePages()
+	return session, nil
+}
+
+func (s *ContinuousPagingSession) String() string {
+	return fmt.Sprintf("%v: [continuous paging stream id %d]", s.conn, s.inFlight.StreamId())
+}
+
+// StreamId returns the stream id of the query.
+func (s *ContinuousPagingSession) StreamId() int16 {
+	return s.inFlight.StreamId()
+}
+
+// Pages returns a channel to receive the pages of results, as frames containing a RowsResult message. The channel is
+// closed after the last page is received, or if an error occurs, or after the session is cancelled, whichever
+// happens first; Err then tells whether the session completed normally.
+func (s *ContinuousPagingSession) Pages() <-chan *frame.Frame {
+	return s.pages
+}
+
+// Err returns the error that terminated the session, if any. It returns nil if the session completed normally, was
+// cancelled, or is still in progress. An error response from the server is returned as a *message.ResponseError.
+func (s *ContinuousPagingSession) Err() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+// Done returns a channel that is closed when the session is over, i.e. when the last page or an error was received,
+// and the stream id of the query was released.
+func (s *ContinuousPagingSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// RequestMore tells the server that the client is ready to receive the given number of additional pages. This is
+// only useful when the query's ContinuousPagingOptions.NextPages is positive, and requires DSE protocol version 2.
+func (s *ContinuousPagingSession) RequestMore(nextPages int32) error {
+	if s.version < primitive.ProtocolVersionDse2 {
+		return fmt.Errorf("%v: requesting more pages requires DSE protocol version 2, got: %v", s, s.version)
+	}
+	return s.revise(&message.Revise{
+		RevisionType:   primitive.DseRevisionTypeMoreContinuousPages,
+		TargetStreamId: int32(s.StreamId()),
+		NextPages:      nextPages,
+	})
+}
+
+// Cancel asks the server to stop sending pages, and closes the Pages channel. Pages still in transit are discarded
+// when received; the stream id of the query is released when the server sends its last page or an error.
+func (s *ContinuousPagingSession) Cancel() error {
+	s.lock.Lock()
+	if s.isCancelled() || s.inFlight.IsDone() {
+		s.lock.Unlock()
+		return nil
+	}
+	close(s.cancelled)
+	s.lock.Unlock()
+	return s.revise(&message.Revise{
+		RevisionType:   primitive.DseRevisionTypeCancelContinuousPaging,
+		TargetStreamId: int32(s.StreamId()),
+	})
+}
+
+// revise sends the given REVISE_REQUEST on a new stream id and waits for the response.
+func (s *ContinuousPagingSession) revise(revise *message.Revise) error {
+	request := frame.NewFrame(s.version, ManagedStreamId, revise)
+	if response, err := s.conn.SendAndReceive(request); err != nil {
+		return fmt.Errorf("%v: cannot send revise request: %w", s, err)
+	} else if errorResponse, ok := response.Body.Message.(message.Error); ok {
+		return fmt.Errorf("%v: revise request failed: %w", s, message.ToGoError(errorResponse))
+	}
+	return nil
+}
+
+func (s *ContinuousPagingSession) receivePages() {
+	defer close(s.done)
+	cancelled := s.cancelled
+	closePages := func() {
+		if cancelled != nil {
+			close(s.pages)
+			cancelled = nil
+		}
+	}
+	defer closePages()
+	for {
+		select {
+		case page, ok := <-s.inFlight.Incoming():
+			if !ok {
+				s.setErr(s.inFlight.Err())
+				return
+			} else if errorResponse, isError := page.Body.Message.(message.Error); isError {
+				s.setErr(message.ToGoError(errorResponse))
+				return
+			} else if cancelled == nil {
+				log.Debug().Msgf("%v: discarding page received after cancellation: %v", s, page)
+				continue
+			}
+			select {
+			case s.pages <- page:
+			case <-cancelled:
+				log.Debug().Msgf("%v: discarding page received after cancellation: %v", s, page)
+				closePages()
+			case <-s.conn.ctx.Done():
+				s.setErr(fmt.Errorf("%v: connection closed", s))
+				return
+			}
+		case <-cancelled:
+			closePages()
+		case <-s.conn.ctx.Done():
+			s.setErr(fmt.Errorf("%v: connection closed", s))
+			return
+		}
+	}
+}
+
+// isCancelled tells whether Cancel was called.
+func (s *ContinuousPagingSession) isCancelled() bool {
+	select {
+	case <-s.cancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *ContinuousPagingSession) setErr(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.err == nil && !s.isCancelled() {
+		s.err = err
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+const continuousPagingTotalPages = 5
+
+// continuousPagingHandler simulates a DSE server pushing continuousPagingTotalPages pages. When the query's NextPages
+// is positive, only that many pages are sent, and more pages are sent when requested with a REVISE_REQUEST.
+type continuousPagingHandler struct {
+	sent map[int16]int32
+	lock sync.Mutex
+}
+
+func newContinuousPagingHandler() *continuousPagingHandler {
+	return &continuousPagingHandler{sent: make(map[int16]int32)}
+}
+
+func (h *continuousPagingHandler) handle(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+	switch msg := request.Body.Message.(type) {
+	case *message.Query:
+		if msg.Query == "fail" {
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Overloaded{ErrorMessage: "overloaded"})
+		}
+		nextPages := msg.Options.ContinuousPagingOptions.NextPages
+		if nextPages <= 0 {
+			nextPages = continuousPagingTotalPages
+		}
+		return h.sendPages(conn, request.Header.Version, request.Header.StreamId, nextPages, false)
+	case *message.Revise:
+		streamId := int16(msg.TargetStreamId)
+		if msg.RevisionType == primitive.DseRevisionTypeCancelContinuousPaging {
+			if last := h.sendPages(conn, request.Header.Version, streamId, 1, true); last != nil {
+				_ = conn.Send(last)
+			}
+		} else if last := h.sendPages(conn, request.Header.Version, streamId, msg.NextPages, false); last != nil {
+			_ = conn.Send(last)
+		}
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+	}
+	return nil
+}
+
+// sendPages sends the given number of pages but the last one, which is returned.
+func (h *continuousPagingHandler) sendPages(
+	conn *client.CqlServerConnection,
+	version primitive.ProtocolVersion,
+	streamId int16,
+	count int32,
+	cancel bool,
+) *frame.Frame {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	var pages []*frame.Frame
+	for i := int32(0); i < count && h.sent[streamId] < continuousPagingTotalPages; i++ {
+		h.sent[streamId]++
+		pageNumber := h.sent[streamId]
+		last := cancel || pageNumber == continuousPagingTotalPages
+		if last {
+			h.sent[streamId] = continuousPagingTotalPages
+		}
+		pages = append(pages, frame.NewFrame(version, streamId, &message.RowsResult{
+			Metadata: &message.RowsMetadata{
+				ColumnCount: 1,
+				Columns: []*message.ColumnMetadata{
+					{Keyspace: "ks", Table: "t1", Name: "v", Type: datatype.Int},
+				},
+				ContinuousPageNumber: pageNumber,
+				LastContinuousPage:   last,
+			},
+			Data: message.RowSet{{{0, 0, 0, byte(pageNumber)}}},
+		}))
+	}
+	if len(pages) == 0 {
+		return nil
+	}
+	for _, page := range pages[:len(pages)-1] {
+		_ = conn.Send(page)
+	}
+	return pages[len(pages)-1]
+}
+
+func newContinuousPagingQuery(query string, nextPages int32) *frame.Frame {
+	return frame.NewFrame(primitive.ProtocolVersionDse2, client.ManagedStreamId, &message.Query{
+		Query: query,
+		Options: &message.QueryOptions{
+			ContinuousPagingOptions: &message.ContinuousPagingOptions{NextPages: nextPages},
+		},
+	})
+}
+
+func receivePages(t *testing.T, session *client.ContinuousPagingSession, count int) []int32 {
+	var pageNumbers []int32
+	for i := 0; i < count; i++ {
+		select {
+		case page, ok := <-session.Pages():
+			require.True(t, ok)
+			pageNumbers = append(pageNumbers, page.Body.Message.(*message.RowsResult).Metadata.ContinuousPageNumber)
+		case <-time.After(time.Second * 5):
+			require.Fail(t, "timed out waiting for page")
+		}
+	}
+	return pageNumbers
+}
+
+func assertSessionDone(t *testing.T, session *client.ContinuousPagingSession) {
+	select {
+	case <-session.Done():
+	case <-time.After(time.Second * 5):
+		require.Fail(t, "timed out waiting for session to complete")
+	}
+	_, ok := <-session.Pages()
+	assert.False(t, ok)
+}
+
+func TestCqlClientConnection_StartContinuousPaging(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{client.HeartbeatHandler, newContinuousPagingHandler().handle}, nil)
+	defer cancelFn()
+
+	session, err := clientConn.StartContinuousPaging(newContinuousPagingQuery("SELECT v FROM ks.t1", 0))
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, 2, 3, 4, 5}, receivePages(t, session, continuousPagingTotalPages))
+	assertSessionDone(t, session)
+	assert.NoError(t, session.Err())
+
+	// the stream id was released and can be reused
+	testHeartbeat(t, clientConn)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestContinuousPagingSession_RequestMore(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{client.HeartbeatHandler, newContinuousPagingHandler().handle}, nil)
+	defer cancelFn()
+
+	session, err := clientConn.StartContinuousPaging(newContinuousPagingQuery("SELECT v FROM ks.t1", 2))
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, 2}, receivePages(t, session, 2))
+	require.NoError(t, session.RequestMore(2))
+	assert.Equal(t, []int32{3, 4}, receivePages(t, session, 2))
+	require.NoError(t, session.RequestMore(2))
+	assert.Equal(t, []int32{5}, receivePages(t, session, 1))
+	assertSessionDone(t, session)
+	assert.NoError(t, session.Err())
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestContinuousPagingSession_Cancel(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{client.HeartbeatHandler, newContinuousPagingHandler().handle}, nil)
+	defer cancelFn()
+
+	session, err := clientConn.StartContinuousPaging(newContinuousPagingQuery("SELECT v FROM ks.t1", 2))
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1}, receivePages(t, session, 1))
+	require.NoError(t, session.Cancel())
+	// page 2 and the final page sent by the server in response to the cancellation are discarded
+	assertSessionDone(t, session)
+	assert.NoError(t, session.Err())
+	// cancelling again is a no-op
+	assert.NoError(t, session.Cancel())
+
+	testHeartbeat(t, clientConn)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestContinuousPagingSession_Error(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{client.HeartbeatHandler, newContinuousPagingHandler().handle}, nil)
+	defer cancelFn()
+
+	session, err := clientConn.StartContinuousPaging(newContinuousPagingQuery("fail", 0))
+	require.NoError(t, err)
+	assertSessionDone(t, session)
+	var responseErr *message.ResponseError
+	require.True(t, errors.As(session.Err(), &responseErr))
+	assert.Equal(t, primitive.ErrorCodeOverloaded, responseErr.Response.GetErrorCode())
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestCqlClientConnection_StartContinuousPaging_Invalid(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, nil, nil)
+	defer cancelFn()
+
+	_, err := clientConn.StartContinuousPaging(nil)
+	assert.EqualError(t, err, clientConn.String()+": frame cannot be nil")
+
+	query := newContinuousPagingQuery("SELECT v FROM ks.t1", 0)
+	query.Header.Version = primitive.ProtocolVersion4
+	_, err = clientConn.StartContinuousPaging(query)
+	assert.Contains(t, err.Error(), "continuous paging requires a DSE protocol version")
+
+	_, err = clientConn.StartContinuousPaging(frame.NewFrame(primitive.ProtocolVersionDse2, client.ManagedStreamId, &message.Options{}))
+	assert.Contains(t, err.Error(), "continuous paging requires a QUERY or EXECUTE request")
+
+	_, err = clientConn.StartContinuousPaging(frame.NewFrame(primitive.ProtocolVersionDse2, client.ManagedStreamId, &message.Query{Query: "SELECT v FROM ks.t1"}))
+	assert.Contains(t, err.Error(), "continuous paging requires continuous paging options")
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestContinuousPagingSession_RequestMoreUnsupported(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{client.HeartbeatHandler, newContinuousPagingHandler().handle}, nil)
+	defer cancelFn()
+
+	query := newContinuousPagingQuery("SELECT v FROM ks.t1", 0)
+	query.Header.Version = primitive.ProtocolVersionDse1
+	session, err := clientConn.StartContinuousPaging(query)
+	require.NoError(t, err)
+	err = session.RequestMore(1)
+	assert.Contains(t, err.Error(), "requesting more pages requires DSE protocol version 2")
+	assert.Len(t, receivePages(t, session, continuousPagingTotalPages), continuousPagingTotalPages)
+	assertSessionDone(t, session)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// EventStreamId is the stream id of the frames initiated by servers, i.e. EVENT responses. See primitive.StreamId.
+const EventStreamId = int16(primitive.EventStreamId)
+
+// Demultiplexer dispatches incoming frames to the pending requests they answer, matching them by stream id, so that
+// responses can be received in any order, each on the channel of its request. Frames on EventStreamId, i.e.
+// server-initiated events, are delivered to the channel returned by Events instead.
+//
+// CqlClientConnection uses its own demultiplexing logic, which also handles timeouts and managed stream ids;
+// Demultiplexer is a standalone component for frames received by other means, e.g. by proxies reading frames from a
+// backend connection. It does not allocate stream ids; see StreamIdAllocator.
+//
+// A Demultiplexer is safe for concurrent use.
+type Demultiplexer struct {
+	maxPending int
+	requests   map[int16]*inFlightRequest
+	events     chan *frame.Frame
+	ctx        context.Context
+	cancel     context.CancelFunc
+	closed     bool
+	lock       sync.Mutex
+}
+
+// NewDemultiplexer creates a new Demultiplexer. The parameter maxPending is the maximum number of frames awaiting
+// delivery to store per request; it is only useful to be greater than 1 when using continuous paging, a feature
+// specific to DataStax Enterprise. The parameter maxPendingEvents is the maximum number of events awaiting delivery.
+// Both must be strictly positive.
+func NewDemultiplexer(maxPending int, maxPendingEvents int) (*Demultiplexer, error) {
+	if maxPending < 1 {
+		return nil, fmt.Errorf("max pending: expecting positive, got: %v", maxPending)
+	} else if maxPendingEvents < 1 {
+		return nil, fmt.Errorf("max pending events: expecting positive, got: %v", maxPendingEvents)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Demultiplexer{
+		maxPending: maxPending,
+		requests:   map[int16]*inFlightRequest{},
+		events:     make(chan *frame.Frame, maxPendingEvents),
+		ctx:        ctx,
+		cancel:     cancel,
+	}, nil
+}
+
+func (d *Demultiplexer) String() string {
+	return "[demultiplexer]"
+}
+
+// Register registers a pending request with the given stream id, which must be positive or zero and not already in
+// use. The returned InFlightRequest receives the frames dispatched with that stream id; it is done, and unregistered,
+// after its last frame is dispatched, or when it is canceled with Cancel, or when the Demultiplexer is closed.
+func (d *Demultiplexer) Register(streamId int16) (InFlightRequest, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.closed {
+		return nil, fmt.Errorf("%v: closed", d)
+	} else if streamId < 0 {
+		return nil, fmt.Errorf("%v: invalid stream id for request: %d", d, streamId)
+	} else if _, found := d.requests[streamId]; found {
+		return nil, fmt.Errorf("%v: stream id already in use: %d", d, streamId)
+	}
+	request := newInFlightRequest(d.String(), streamId, false, d.ctx, d.ctx, d.maxPending, 0)
+	d.requests[streamId] = request
+	return request, nil
+}
+
+// Cancel unregisters the pending request with the given stream id, which then fails with an error, and returns true;
+// it returns false if no request is pending for that stream id. Its stream id can be registered again immediately,
+// since late frames for the canceled request cannot be told apart from frames answering the next request.
+func (d *Demultiplexer) Cancel(streamId int16) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	request, found := d.requests[streamId]
+	if found {
+		delete(d.requests, streamId)
+		request.close(fmt.Errorf("%v: request canceled", request))
+	}
+	return found
+}
+
+// Dispatch delivers the given incoming frame to the pending request with the same stream id, or to the Events channel
+// if its stream id is EventStreamId. It never blocks: it returns an error if no request is pending for the frame's
+// stream id, or if the frame cannot be delivered because too many frames are awaiting delivery, in which case the
+// request fails, or the event is dropped.
+func (d *Demultiplexer) Dispatch(f *frame.Frame) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.closed {
+		return fmt.Errorf("%v: closed", d)
+	}
+	streamId := f.Header.StreamId
+	if streamId == EventStreamId {
+		select {
+		case d.events <- f:
+			return nil
+		default:
+			return fmt.Errorf("%v: too many pending events, event dropped: %v", d, f)
+		}
+	}
+	request, found := d.requests[streamId]
+	if !found {
+		return fmt.Errorf("%v: unknown stream id: %d", d, streamId)
+	}
+	// requests are only closed while holding the lock, so their channel cannot be closed concurrently
+	select {
+	case request._incoming <- f:
+		if isLastFrame(f) {
+			delete(d.requests, streamId)
+			request.close(nil)
+		}
+		return nil
+	default:
+		err := fmt.Errorf("%v: too many pending incoming frames: %d", request, d.maxPending)
+		delete(d.requests, streamId)
+		request.close(err)
+		return err
+	}
+}
+
+// Events returns the channel receiving the frames dispatched with EventStreamId. It is closed when the Demultiplexer
+// is closed.
+func (d *Demultiplexer) Events() <-chan *frame.Frame {
+	return d.events
+}
+
+// Pending returns the number of pending requests.
+func (d *Demultiplexer) Pending() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return len(d.requests)
+}
+
+// Close closes this Demultiplexer: all pending requests fail with an error, and the Events channel is closed.
+// Subsequent calls to Register and Dispatch will fail.
+func (d *Demultiplexer) Close() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.closed {
+		return
+	}
+	d.closed = true
+	for streamId, request := range d.requests {
+		delete(d.requests, streamId)
+		request.close(fmt.Errorf("%v: closed", d))
+	}
+	close(d.events)
+	d.cancel()
+}
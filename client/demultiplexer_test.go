@@ -0,0 +1,116 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestDemultiplexer(t *testing.T) {
+	demux, err := client.NewDemultiplexer(2, 1)
+	require.NoError(t, err)
+	request1, err := demux.Register(1)
+	require.NoError(t, err)
+	request2, err := demux.Register(2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, demux.Pending())
+
+	// out-of-order delivery
+	response2 := frame.NewFrame(primitive.ProtocolVersion4, 2, &message.VoidResult{})
+	require.NoError(t, demux.Dispatch(response2))
+	assert.Equal(t, response2, <-request2.Incoming())
+	assert.True(t, request2.IsDone())
+	assert.NoError(t, request2.Err())
+	assert.False(t, request1.IsDone())
+
+	// continuous paging: many frames per request
+	page := func(number int32, last bool) *frame.Frame {
+		return frame.NewFrame(primitive.ProtocolVersion4, 1, &message.RowsResult{
+			Metadata: &message.RowsMetadata{ContinuousPageNumber: number, LastContinuousPage: last},
+		})
+	}
+	require.NoError(t, demux.Dispatch(page(1, false)))
+	require.NoError(t, demux.Dispatch(page(2, true)))
+	assert.Equal(t, page(1, false), <-request1.Incoming())
+	assert.Equal(t, page(2, true), <-request1.Incoming())
+	_, open := <-request1.Incoming()
+	assert.False(t, open)
+	assert.Zero(t, demux.Pending())
+
+	// events
+	event := frame.NewFrame(primitive.ProtocolVersion4, client.EventStreamId, &message.StatusChangeEvent{
+		ChangeType: primitive.StatusChangeTypeUp,
+		Address:    &primitive.Inet{},
+	})
+	require.NoError(t, demux.Dispatch(event))
+	assert.EqualError(t, demux.Dispatch(event),
+		"[demultiplexer]: too many pending events, event dropped: "+event.String())
+	assert.Equal(t, event, <-demux.Events())
+
+	// stream id reuse
+	request1, err = demux.Register(1)
+	require.NoError(t, err)
+	_, err = demux.Register(1)
+	assert.EqualError(t, err, "[demultiplexer]: stream id already in use: 1")
+	_, err = demux.Register(-1)
+	assert.EqualError(t, err, "[demultiplexer]: invalid stream id for request: -1")
+	assert.EqualError(t, demux.Dispatch(response2), "[demultiplexer]: unknown stream id: 2")
+
+	demux.Close()
+	_, open = <-request1.Incoming()
+	assert.False(t, open)
+	assert.EqualError(t, request1.Err(), "[demultiplexer]: closed")
+	_, open = <-demux.Events()
+	assert.False(t, open)
+	_, err = demux.Register(3)
+	assert.EqualError(t, err, "[demultiplexer]: closed")
+	assert.EqualError(t, demux.Dispatch(response2), "[demultiplexer]: closed")
+}
+
+func TestDemultiplexer_Cancel(t *testing.T) {
+	demux, err := client.NewDemultiplexer(1, 1)
+	require.NoError(t, err)
+	defer demux.Close()
+	request, err := demux.Register(1)
+	require.NoError(t, err)
+	assert.True(t, demux.Cancel(1))
+	assert.False(t, demux.Cancel(1))
+	assert.True(t, request.IsDone())
+	assert.EqualError(t, request.Err(), "[demultiplexer] [stream id 1]: request canceled")
+
+	// too many pending frames
+	request, err = demux.Register(1)
+	require.NoError(t, err)
+	page := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.RowsResult{
+		Metadata: &message.RowsMetadata{ContinuousPageNumber: 1},
+	})
+	require.NoError(t, demux.Dispatch(page))
+	assert.EqualError(t, demux.Dispatch(page), "[demultiplexer] [stream id 1]: too many pending incoming frames: 1")
+	assert.True(t, request.IsDone())
+	assert.Zero(t, demux.Pending())
+
+	_, err = client.NewDemultiplexer(0, 1)
+	assert.EqualError(t, err, "max pending: expecting positive, got: 0")
+	_, err = client.NewDemultiplexer(1, 0)
+	assert.EqualError(t, err, "max pending events: expecting positive, got: 0")
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// DefaultEventSubscriberBufferSize is the default capacity of the channel returned by EventSubscriber.Events.
+const DefaultEventSubscriberBufferSize = 128
+
+// EventSubscriber delivers the decoded events received by a CqlClientConnection. EventSubscriber instances should be
+// created by calling CqlClientConnection.RegisterForEvents or CqlClientConnection.Subscribe.
+//
+// Events are delivered in the order they were received. If the subscriber's channel is full, incoming events are
+// discarded. Subscribers do not compete with each other, nor with CqlClientConnection.EventChannel and
+// CqlClientConnection.ReceiveEvent: every event is delivered to all of them.
+type EventSubscriber struct {
+	conn   *CqlClientConnection
+	events chan message.Event
+	done   chan struct{}
+	closed bool
+	lock   sync.Mutex
+}
+
+// Events returns a channel for receiving incoming events. The channel is closed when the subscriber is closed, either
+// explicitly, or because its context was canceled, or because the connection was closed.
+func (s *EventSubscriber) Events() <-chan message.Event {
+	return s.events
+}
+
+// Close stops the delivery of events to this subscriber and closes its channel. Note that the protocol does not allow
+// to unregister from events: the server will keep sending them on the connection. It is safe to call this method
+// many times.
+func (s *EventSubscriber) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+		close(s.events)
+		s.conn.removeSubscriber(s)
+	}
+}
+
+func (s *EventSubscriber) deliver(event message.Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.closed {
+		select {
+		case s.events <- event:
+		default:
+			log.Error().Msgf("%v: event subscriber queue is full, discarding event: %v", s.conn, event)
+		}
+	}
+}
+
+// Subscribe creates a new EventSubscriber for the events received by this connection, with a channel of the given
+// capacity. It does not send any request to the server; use RegisterForEvents to do so. The subscriber is closed when
+// the given context is canceled, or when the connection is closed, whichever happens first.
+func (c *CqlClientConnection) Subscribe(ctx context.Context, bufferSize int) (*EventSubscriber, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("%v: context cannot be nil", c)
+	} else if bufferSize < 1 {
+		return nil, fmt.Errorf("%v: buffer size: expecting positive, got: %v", c, bufferSize)
+	}
+	subscriber := &EventSubscriber{
+		conn:   c,
+		events: make(chan message.Event, bufferSize),
+		done:   make(chan struct{}),
+	}
+	c.subscribersLock.Lock()
+	if c.IsClosed() {
+		c.subscribersLock.Unlock()
+		return nil, fmt.Errorf("%v: connection closed", c)
+	}
+	c.subscribers = append(c.subscribers, subscriber)
+	c.subscribersLock.Unlock()
+	go func() {
+		select {
+		case <-ctx.Done():
+			subscriber.Close()
+		case <-c.ctx.Done():
+			subscriber.Close()
+		case <-subscriber.done:
+		}
+	}()
+	return subscriber, nil
+}
+
+// RegisterForEvents sends a REGISTER request for the given event types, waits for the server to reply with READY,
+// then returns an EventSubscriber for the events received by this connection. The subscriber is closed when the given
+// context is canceled, or when the connection is closed, whichever happens first.
+func (c *CqlClientConnection) RegisterForEvents(
+	ctx context.Context,
+	version primitive.ProtocolVersion,
+	eventTypes ...primitive.EventType,
+) (*EventSubscriber, error) {
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("%v: at least one event type must be provided", c)
+	}
+	// subscribe first, to avoid missing events sent right after the READY response
+	subscriber, err := c.Subscribe(ctx, DefaultEventSubscriberBufferSize)
+	if err != nil {
+		return nil, err
+	}
+	request := frame.NewFrame(version, ManagedStreamId, &message.Register{EventTypes: eventTypes})
+	if response, err := c.SendAndReceive(request); err != nil {
+		subscriber.Close()
+		return nil, fmt.Errorf("%v: cannot register for events: %w", c, err)
+	} else if _, ok := response.Body.Message.(*message.Ready); !ok {
+		subscriber.Close()
+		return nil, fmt.Errorf("%v: cannot register for events: expected READY, got: %v", c, response.Body.Message)
+	}
+	return subscriber, nil
+}
+
+func (c *CqlClientConnection) notifySubscribers(incoming *frame.Frame) {
+	event, ok := incoming.Body.Message.(message.Event)
+	if !ok {
+		return
+	}
+	c.subscribersLock.Lock()
+	subscribers := make([]*EventSubscriber, len(c.subscribers))
+	copy(subscribers, c.subscribers)
+	c.subscribersLock.Unlock()
+	for _, subscriber := range subscribers {
+		subscriber.deliver(event)
+	}
+}
+
+func (c *CqlClientConnection) removeSubscriber(subscriber *EventSubscriber) {
+	c.subscribersLock.Lock()
+	defer c.subscribersLock.Unlock()
+	for i, s := range c.subscribers {
+		if s == subscriber {
+			c.subscribers = append(c.subscribers[:i], c.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *CqlClientConnection) closeSubscribers() {
+	c.subscribersLock.Lock()
+	subscribers := c.subscribers
+	c.subscribers = nil
+	c.subscribersLock.Unlock()
+	for _, subscriber := range subscribers {
+		subscriber.Close()
+	}
+}
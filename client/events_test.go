@@ -0,0 +1,114 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCqlClientConnection_RegisterForEvents(t *testing.T) {
+
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{client.RegisterHandler}, nil)
+	defer cancelFn()
+	serverConn, err := server.Accept(clientConn)
+	require.NoError(t, err)
+
+	subscriberCtx, cancelSubscriber := context.WithCancel(context.Background())
+	subscriber1, err := clientConn.RegisterForEvents(
+		subscriberCtx,
+		primitive.ProtocolVersion4,
+		primitive.EventTypeSchemaChange,
+		primitive.EventTypeTopologyChange,
+	)
+	require.NoError(t, err)
+	subscriber2, err := clientConn.Subscribe(context.Background(), 10)
+	require.NoError(t, err)
+
+	schemaChange := &message.SchemaChangeEvent{
+		ChangeType: primitive.SchemaChangeTypeCreated,
+		Target:     primitive.SchemaChangeTargetKeyspace,
+		Keyspace:   "ks1",
+	}
+	topologyChange := &message.TopologyChangeEvent{
+		ChangeType: primitive.TopologyChangeTypeNewNode,
+		Address:    &primitive.Inet{Addr: net.IPv4(192, 168, 1, 1), Port: 9042},
+	}
+	require.NoError(t, serverConn.Send(frame.NewFrame(primitive.ProtocolVersion4, -1, schemaChange)))
+	require.NoError(t, serverConn.Send(frame.NewFrame(primitive.ProtocolVersion4, -1, topologyChange)))
+
+	for _, subscriber := range []*client.EventSubscriber{subscriber1, subscriber2} {
+		assert.Equal(t, schemaChange, receiveEvent(t, subscriber))
+		assert.Equal(t, topologyChange, receiveEvent(t, subscriber))
+	}
+	// events are still delivered to the connection's event channel
+	event, err := clientConn.ReceiveEvent()
+	require.NoError(t, err)
+	assert.Equal(t, schemaChange, event.Body.Message)
+
+	// canceling the context closes the subscriber
+	cancelSubscriber()
+	assert.Eventually(t, func() bool {
+		_, ok := <-subscriber1.Events()
+		return !ok
+	}, time.Second, time.Millisecond)
+
+	// closing the connection closes the remaining subscribers
+	require.NoError(t, clientConn.Close())
+	_, ok := <-subscriber2.Events()
+	assert.False(t, ok)
+	_, err = clientConn.Subscribe(context.Background(), 10)
+	assert.Error(t, err)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestCqlClientConnection_RegisterForEvents_Error(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{
+		func(request *frame.Frame, conn *client.CqlServerConnection, ctx client.RequestHandlerContext) *frame.Frame {
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Invalid{ErrorMessage: "nope"})
+		},
+	}, nil)
+	defer cancelFn()
+	_, err := clientConn.RegisterForEvents(context.Background(), primitive.ProtocolVersion4, primitive.EventTypeStatusChange)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot register for events: expected READY, got: ERROR INVALID")
+	_, err = clientConn.RegisterForEvents(context.Background(), primitive.ProtocolVersion4)
+	assert.Error(t, err)
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func receiveEvent(t *testing.T, subscriber *client.EventSubscriber) message.Event {
+	select {
+	case event, ok := <-subscriber.Events():
+		require.True(t, ok)
+		return event
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for event")
+	}
+	return nil
+}
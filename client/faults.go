@@ -0,0 +1,187 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+)
+
+// Fault alters the response produced for a request, e.g. to simulate a slow or failing server. It is invoked with the
+// request and with the response produced by the request handlers wrapped by a FaultInjector; it returns the response
+// to send, or nil to send no response at all. Faults are allowed to block; they should however return promptly when
+// the connection is closed.
+type Fault func(request *frame.Frame, response *frame.Frame, conn *CqlServerConnection) *frame.Frame
+
+// DelayFault returns a Fault that delays the response by the given duration.
+func DelayFault(delay time.Duration) Fault {
+	return RandomDelayFault(delay, delay)
+}
+
+// RandomDelayFault returns a Fault that delays the response by a pseudo-random duration between min and max.
+func RandomDelayFault(min time.Duration, max time.Duration) Fault {
+	var lock sync.Mutex
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return func(request *frame.Frame, response *frame.Frame, conn *CqlServerConnection) *frame.Frame {
+		delay := min
+		if max > min {
+			lock.Lock()
+			delay += time.Duration(random.Int63n(int64(max - min)))
+			lock.Unlock()
+		}
+		select {
+		case <-time.After(delay):
+			return response
+		case <-conn.Done():
+			return nil
+		}
+	}
+}
+
+// DropResponseFault is a Fault that drops the response: the request remains unanswered, which simulates a server-side
+// timeout.
+func DropResponseFault(request *frame.Frame, _ *frame.Frame, conn *CqlServerConnection) *frame.Frame {
+	log.Debug().Msgf("%v: [fault injector]: dropping response for request: %v", conn, request)
+	return nil
+}
+
+// CloseConnectionFault is a Fault that abruptly closes the connection instead of responding, which simulates a server
+// crash or a network failure.
+func CloseConnectionFault(request *frame.Frame, _ *frame.Frame, conn *CqlServerConnection) *frame.Frame {
+	log.Debug().Msgf("%v: [fault injector]: closing connection instead of responding to request: %v", conn, request)
+	// closing the underlying connection makes the connection abort; Close cannot be invoked from a request handler
+	if err := conn.GetConn().Close(); err != nil {
+		log.Error().Err(err).Msgf("%v: [fault injector]: error closing connection", conn)
+	}
+	return nil
+}
+
+// ErrorFault returns a Fault that replaces the response with the given message, typically an error such as
+// message.Overloaded, using the request's protocol version and stream id.
+func ErrorFault(msg message.Message) Fault {
+	return func(request *frame.Frame, _ *frame.Frame, _ *CqlServerConnection) *frame.Frame {
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, msg)
+	}
+}
+
+// OverloadedFault is a Fault that replaces the response with an OVERLOADED error.
+var OverloadedFault = ErrorFault(&message.Overloaded{ErrorMessage: "injected fault: overloaded"})
+
+// FaultInjector injects faults into the responses produced by request handlers. Faults are triggered either with a
+// given probability, or according to a sequence, for requests satisfying a set of matchers; the triggers are evaluated
+// in the order they were added, and the first one that triggers a fault wins. Use Wrap to plug the injector into a
+// CqlServer:
+//
+//  injector := NewFaultInjector(seed).
+//      WithProbability(0.1, OverloadedFault, MatchOpCode(primitive.OpCodeQuery)).
+//      WithSequence([]Fault{nil, DropResponseFault}, MatchQuery("^INSERT"))
+//  server.RequestHandlers = []RequestHandler{injector.Wrap(handler), NewDriverConnectionInitializationHandler(...)}
+//
+// Matchers should exclude handshake requests, unless the handshake itself is meant to fail.
+type FaultInjector struct {
+	triggers []*faultTrigger
+	random   *rand.Rand
+	lock     sync.Mutex
+}
+
+type faultTrigger struct {
+	matchers    []RequestMatcher
+	probability float64
+	fault       Fault
+	sequence    []Fault
+	matches     int
+}
+
+// NewFaultInjector creates a new FaultInjector. Probabilities are evaluated with a pseudo-random generator initialized
+// with the given seed, which makes a test scenario reproducible.
+func NewFaultInjector(seed int64) *FaultInjector {
+	return &FaultInjector{random: rand.New(rand.NewSource(seed))}
+}
+
+// WithProbability injects the given fault, with the given probability between 0 and 1, into the responses to requests
+// satisfying all the given matchers.
+func (i *FaultInjector) WithProbability(probability float64, fault Fault, matchers ...RequestMatcher) *FaultInjector {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.triggers = append(i.triggers, &faultTrigger{matchers: matchers, probability: probability, fault: fault})
+	return i
+}
+
+// WithSequence injects the given faults, in order, into the responses to requests satisfying all the given matchers:
+// the first matched request gets the first fault, the second matched request the second fault, and so on. A nil fault
+// leaves the response untouched; once the sequence is exhausted, no more faults are injected.
+func (i *FaultInjector) WithSequence(faults []Fault, matchers ...RequestMatcher) *FaultInjector {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.triggers = append(i.triggers, &faultTrigger{matchers: matchers, sequence: faults})
+	return i
+}
+
+// Wrap returns a RequestHandler that invokes the given handlers, like NewCompositeRequestHandler, then injects faults
+// into their response, if any. Requests that the given handlers do not handle are passed on to the next handlers
+// without faults.
+func (i *FaultInjector) Wrap(handlers ...RequestHandler) RequestHandler {
+	composite := NewCompositeRequestHandler(handlers...)
+	return func(request *frame.Frame, conn *CqlServerConnection, ctx RequestHandlerContext) *frame.Frame {
+		response := composite(request, conn, ctx)
+		if response == nil {
+			return nil
+		}
+		if fault := i.fault(request); fault != nil {
+			log.Debug().Msgf("%v: [fault injector]: injecting fault for request: %v", conn, request)
+			if response = fault(request, response, conn); response == nil {
+				// the request must not reach the next handlers: wait until the connection is closed, like NoResponse
+				<-conn.Done()
+			}
+		}
+		return response
+	}
+}
+
+// fault returns the fault to inject into the response to the given request, if any.
+func (i *FaultInjector) fault(request *frame.Frame) Fault {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for _, trigger := range i.triggers {
+		if !trigger.match(request) {
+			continue
+		}
+		if trigger.sequence != nil {
+			index := trigger.matches
+			trigger.matches++
+			if index < len(trigger.sequence) && trigger.sequence[index] != nil {
+				return trigger.sequence[index]
+			}
+		} else if i.random.Float64() < trigger.probability {
+			return trigger.fault
+		}
+	}
+	return nil
+}
+
+func (t *faultTrigger) match(request *frame.Frame) bool {
+	for _, matcher := range t.matchers {
+		if !matcher(request) {
+			return false
+		}
+	}
+	return true
+}
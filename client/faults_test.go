@@ -0,0 +1,123 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func voidResultHandler(request *frame.Frame, _ *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+	if _, ok := request.Body.Message.(*message.Query); ok {
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+	}
+	return nil
+}
+
+func TestFaultInjector_Sequence(t *testing.T) {
+	injector := client.NewFaultInjector(0).WithSequence([]client.Fault{
+		nil,
+		client.OverloadedFault,
+		client.DelayFault(100 * time.Millisecond),
+		client.DropResponseFault,
+		client.CloseConnectionFault,
+	}, client.MatchOpCode(primitive.OpCodeQuery))
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{injector.Wrap(voidResultHandler), client.HandshakeHandler}
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.ReadTimeout = 500 * time.Millisecond
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	query := func() (*frame.Frame, error) {
+		return clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{
+			Query: "SELECT * FROM ks.t1",
+		}))
+	}
+
+	response, err := query()
+	require.NoError(t, err)
+	assert.Equal(t, &message.VoidResult{}, response.Body.Message)
+
+	response, err = query()
+	require.NoError(t, err)
+	assert.Equal(t, &message.Overloaded{ErrorMessage: "injected fault: overloaded"}, response.Body.Message)
+
+	start := time.Now()
+	response, err = query()
+	require.NoError(t, err)
+	assert.Equal(t, &message.VoidResult{}, response.Body.Message)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	_, err = query()
+	assert.Error(t, err)
+	assert.False(t, clientConn.IsClosed())
+
+	_, err = query()
+	assert.Error(t, err)
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+
+	// the sequence is exhausted
+	clientConn, err = clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	response, err = query()
+	require.NoError(t, err)
+	assert.Equal(t, &message.VoidResult{}, response.Body.Message)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestFaultInjector_Probability(t *testing.T) {
+	injector := client.NewFaultInjector(42).
+		WithProbability(1, client.OverloadedFault, client.MatchQuery("^always")).
+		WithProbability(0, client.OverloadedFault, client.MatchQuery("^never")).
+		WithProbability(0.5, client.OverloadedFault, client.MatchQuery("^sometimes"))
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{injector.Wrap(voidResultHandler), client.HandshakeHandler}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := client.NewCqlClient("127.0.0.1:9043", nil).ConnectAndInit(ctx, primitive.ProtocolVersion4, 1)
+	require.NoError(t, err)
+	overloaded := map[string]int{}
+	for _, query := range []string{"always", "never", "sometimes"} {
+		for i := 0; i < 100; i++ {
+			response, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: query}))
+			require.NoError(t, err)
+			if _, ok := response.Body.Message.(*message.Overloaded); ok {
+				overloaded[query]++
+			}
+		}
+	}
+	assert.Equal(t, 100, overloaded["always"])
+	assert.Equal(t, 0, overloaded["never"])
+	assert.Greater(t, overloaded["sometimes"], 0)
+	assert.Less(t, overloaded["sometimes"], 100)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
@@ -15,6 +15,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/rs/zerolog/log"
@@ -53,16 +54,38 @@ func PerformHandshake(clientConn *CqlClientConnection, serverConn *CqlServerConn
 }
 
 // InitiateHandshake initiates the handshake procedure to initialize the client connection, using the given protocol
-// version. The handshake will use authentication if the connection was created with auth credentials; otherwise it will
-// proceed without authentication. Use stream id zero to activate automatic stream id management.
+// version. The handshake will use authentication if the connection was created with auth credentials or with an
+// AuthenticatorProvider; otherwise it will proceed without authentication. If the connection was created by a client
+// with CqlClient.SendOptions set, an OPTIONS request is sent first. Use stream id zero to activate automatic stream id
+// management.
 func (c *CqlClientConnection) InitiateHandshake(version primitive.ProtocolVersion, streamId int16) (err error) {
+	return c.InitiateHandshakeContext(context.Background(), version, streamId)
+}
+
+// InitiateHandshakeContext is like InitiateHandshake, but the given context controls the whole handshake: if it is
+// done before the handshake completes, the handshake fails with an error wrapping the context's error. See
+// CqlClientConnection.SendContext.
+func (c *CqlClientConnection) InitiateHandshakeContext(
+	ctx context.Context,
+	version primitive.ProtocolVersion,
+	streamId int16,
+) (err error) {
+	if ctx == nil {
+		return fmt.Errorf("%v: context cannot be nil", c)
+	}
 	log.Debug().Msgf("%v: performing handshake", c)
+	if c.sendOptions {
+		if _, err := c.requestOptions(ctx, version, streamId); err != nil {
+			log.Error().Err(err).Msgf("%v: handshake failed", c)
+			return err
+		}
+	}
 	if startup, err := c.NewStartupRequest(version, streamId); err != nil {
 		return err
 	} else {
 		var response *frame.Frame
-		if response, err = c.SendAndReceive(startup); err == nil {
-			if c.credentials == nil {
+		if response, err = c.SendAndReceiveContext(ctx, startup); err == nil {
+			if c.credentials == nil && c.authenticators == nil {
 				if _, authSuccess := response.Body.Message.(*message.Ready); !authSuccess {
 					err = fmt.Errorf("expected READY, got %v", response.Body.Message)
 				}
@@ -72,11 +95,13 @@ func (c *CqlClientConnection) InitiateHandshake(version primitive.ProtocolVersio
 					log.Warn().Msgf("%v: expected AUTHENTICATE, got READY – is authentication required?", c)
 					break
 				case *message.Authenticate:
-					authenticator := &PlainTextAuthenticator{c.credentials}
+					var authenticator Authenticator
 					var initialResponse []byte
-					if initialResponse, err = authenticator.InitialResponse(msg.Authenticator); err == nil {
+					if authenticator, err = c.newAuthenticator(msg.Authenticator); err != nil {
+						break
+					} else if initialResponse, err = authenticator.InitialResponse(msg.Authenticator); err == nil {
 						authResponse := frame.NewFrame(version, streamId, &message.AuthResponse{Token: initialResponse})
-						if response, err = c.SendAndReceive(authResponse); err != nil {
+						if response, err = c.SendAndReceiveContext(ctx, authResponse); err != nil {
 							err = fmt.Errorf("could not send AUTH RESPONSE: %w", err)
 						} else {
 							switch msg := response.Body.Message.(type) {
@@ -86,7 +111,7 @@ func (c *CqlClientConnection) InitiateHandshake(version primitive.ProtocolVersio
 								var challenge []byte
 								if challenge, err = authenticator.EvaluateChallenge(msg.Token); err == nil {
 									authResponse := frame.NewFrame(version, streamId, &message.AuthResponse{Token: challenge})
-									if response, err = c.SendAndReceive(authResponse); err != nil {
+									if response, err = c.SendAndReceiveContext(ctx, authResponse); err != nil {
 										err = fmt.Errorf("could not send AUTH RESPONSE: %w", err)
 									} else if _, authSuccess := response.Body.Message.(*message.AuthSuccess); !authSuccess {
 										err = fmt.Errorf("expected AUTH_SUCCESS, got %v", response.Body.Message)
@@ -111,6 +136,21 @@ func (c *CqlClientConnection) InitiateHandshake(version primitive.ProtocolVersio
 	}
 }
 
+// newAuthenticator returns the Authenticator to use with the given server-side authenticator class.
+func (c *CqlClientConnection) newAuthenticator(authenticator string) (Authenticator, error) {
+	if c.authenticators == nil {
+		return &PlainTextAuthenticator{c.credentials}, nil
+	}
+	if auth, err := c.authenticators(authenticator); err != nil {
+		return nil, fmt.Errorf("cannot create authenticator for %v: %w", authenticator, err)
+	} else if auth == nil {
+		log.Debug().Msgf("%v: authenticating anonymously with %v", c, authenticator)
+		return &PlainTextAuthenticator{&AuthCredentials{}}, nil
+	} else {
+		return auth, nil
+	}
+}
+
 // AcceptHandshake Listens for a client STARTUP request and proceeds with the server-side handshake procedure.
 // Authentication will be required if the connection was created with auth credentials; otherwise the handshake will
 // proceed without authentication.
@@ -123,13 +163,19 @@ func (c *CqlServerConnection) AcceptHandshake() (err error) {
 	done := false
 	for !done && err == nil {
 		if request, err = c.Receive(); err == nil {
-			switch request.Body.Message.(type) {
+			switch startup := request.Body.Message.(type) {
 			case *message.Options:
-				supported := frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Supported{})
+				supported := frame.NewFrame(request.Header.Version, request.Header.StreamId, c.supported())
 				err = c.Send(supported)
 				continue
 			case *message.Startup:
-				if c.credentials == nil {
+				if compression := startup.GetCompression(); !c.acceptsCompression(request.Header.Version, compression) {
+					err = fmt.Errorf("unsupported compression: %v", compression)
+					protocolError := frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.ProtocolError{ErrorMessage: err.Error()})
+					if sendErr := c.Send(protocolError); sendErr != nil {
+						err = sendErr
+					}
+				} else if c.credentials == nil {
 					authSuccess = true
 					ready := frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Ready{})
 					err = c.Send(ready)
@@ -192,9 +238,13 @@ var HandshakeHandler RequestHandler = func(request *frame.Frame, conn *CqlServer
 	switch msg := request.Body.Message.(type) {
 	case *message.Options:
 		log.Debug().Msgf("%v: [handshake handler]: intercepted OPTIONS before STARTUP", conn)
-		response = frame.NewFrame(version, id, &message.Supported{})
+		response = frame.NewFrame(version, id, conn.supported())
 	case *message.Startup:
-		if conn.Credentials() == nil {
+		if compression := msg.GetCompression(); !conn.acceptsCompression(version, compression) {
+			ctx.PutAttribute(handshakeStateKey, handshakeStateDone)
+			log.Error().Msgf("%v: [handshake handler]: unsupported compression: %v", conn, compression)
+			response = frame.NewFrame(version, id, &message.ProtocolError{ErrorMessage: fmt.Sprintf("unsupported compression: %v", compression)})
+		} else if conn.Credentials() == nil {
 			ctx.PutAttribute(handshakeStateKey, handshakeStateDone)
 			log.Info().Msgf("%v: [handshake handler]: handshake successful", conn)
 			response = frame.NewFrame(version, id, &message.Ready{})
@@ -16,7 +16,10 @@ package client_test
 
 import (
 	"context"
+	"errors"
 	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -81,3 +84,184 @@ func TestHandshakeHandler_Auth(t *testing.T) {
 	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
 
 }
+
+func TestHandshakeHandler_BetaVersion(t *testing.T) {
+
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, client.HeartbeatHandler}
+	server.AllowBetaVersions = true
+
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	err := server.Start(ctx)
+	require.NoError(t, err)
+
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+
+	// beta versions must be enabled on the client
+	err = clientConn.InitiateHandshake(primitive.ProtocolVersion6, client.ManagedStreamId)
+	assert.EqualError(t, err, "ProtocolVersion OSS 6 (beta) is a beta protocol version, set CqlClient.AllowBetaVersions to use it")
+
+	clt.AllowBetaVersions = true
+	clientConn, err = clt.ConnectAndInit(ctx, primitive.ProtocolVersion6, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	response, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion6, client.ManagedStreamId, &message.Options{}))
+	require.NoError(t, err)
+	assert.True(t, response.Header.Flags.Contains(primitive.HeaderFlagUseBeta))
+	assert.Equal(t, primitive.ProtocolVersion6, response.Header.Version)
+
+	cancelFn()
+
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+
+}
+
+func TestHandshake_OptionsAndStartupOptions(t *testing.T) {
+
+	supported := &message.Supported{Options: map[string][]string{
+		message.StartupOptionCompression: {"lz4", "snappy"},
+		message.StartupOptionCqlVersion:  {"3.4.5"},
+		"PROTOCOL_VERSIONS":              {"3/v3", "4/v4", "5/v5"},
+	}}
+	startupOptions := make(chan map[string]string, 1)
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{
+		func(request *frame.Frame, conn *client.CqlServerConnection, ctx client.RequestHandlerContext) *frame.Frame {
+			switch msg := request.Body.Message.(type) {
+			case *message.Options:
+				return frame.NewFrame(request.Header.Version, request.Header.StreamId, supported)
+			case *message.Startup:
+				startupOptions <- msg.Options
+			}
+			return nil
+		},
+		client.HandshakeHandler,
+	}
+
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.Compression = primitive.CompressionLz4
+	clt.SendOptions = true
+	clt.StartupOptions = map[string]string{
+		message.StartupOptionThrowOnOverload: "1",
+		message.StartupOptionDriverName:      "Test driver",
+		message.StartupOptionDriverVersion:   "1.2.3",
+		message.StartupOptionCompression:     "snappy",
+		"CUSTOM_KEY":                         "custom value",
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	err := server.Start(ctx)
+	require.NoError(t, err)
+
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, clientConn.Supported())
+
+	err = clientConn.InitiateHandshake(primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	assert.Equal(t, supported.Options, clientConn.Supported().Options)
+	assert.Equal(t, map[string]string{
+		message.StartupOptionCqlVersion:      "3.0.0",
+		message.StartupOptionCompression:     "LZ4",
+		message.StartupOptionThrowOnOverload: "1",
+		message.StartupOptionDriverName:      "Test driver",
+		message.StartupOptionDriverVersion:   "1.2.3",
+		"CUSTOM_KEY":                         "custom value",
+	}, <-startupOptions)
+
+	cancelFn()
+
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+
+}
+
+// dseAuthHandler emulates a DSE server using DseAuthenticator with the PLAIN SASL mechanism; in transitional mode,
+// anonymous clients, that is, clients sending empty credentials, are accepted.
+func dseAuthHandler(transitional bool) client.RequestHandler {
+	return func(request *frame.Frame, _ *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		version := request.Header.Version
+		id := request.Header.StreamId
+		switch msg := request.Body.Message.(type) {
+		case *message.Startup:
+			return frame.NewFrame(version, id, &message.Authenticate{Authenticator: client.DseAuthenticatorClass})
+		case *message.AuthResponse:
+			if string(msg.Token) == "PLAIN" {
+				return frame.NewFrame(version, id, &message.AuthChallenge{Token: []byte("PLAIN-START")})
+			}
+			credentials := &client.AuthCredentials{}
+			if err := credentials.Unmarshal(msg.Token); err == nil &&
+				(*credentials == client.AuthCredentials{Username: "user1", Password: "pass1"} ||
+					transitional && *credentials == client.AuthCredentials{}) {
+				return frame.NewFrame(version, id, &message.AuthSuccess{})
+			}
+			return frame.NewFrame(version, id, &message.AuthenticationError{ErrorMessage: "invalid credentials"})
+		}
+		return nil
+	}
+}
+
+func TestHandshake_AuthenticatorProvider(t *testing.T) {
+	credentials := &client.AuthCredentials{Username: "user1", Password: "pass1"}
+	multiAuthenticators := func(authenticator string) (client.Authenticator, error) {
+		switch authenticator {
+		case client.DseAuthenticatorClass, client.PasswordAuthenticatorClass:
+			return &client.PlainTextAuthenticator{Credentials: credentials}, nil
+		}
+		return nil, errors.New("unexpected authenticator")
+	}
+	anonymous := func(string) (client.Authenticator, error) { return nil, nil }
+	tests := []struct {
+		name           string
+		credentials    *client.AuthCredentials
+		handler        client.RequestHandler
+		authenticators client.AuthenticatorProvider
+		expectedClass  string
+		expectedErr    string
+	}{
+		{"password", credentials, client.HandshakeHandler, multiAuthenticators, client.PasswordAuthenticatorClass, ""},
+		{"dse", nil, dseAuthHandler(false), multiAuthenticators, client.DseAuthenticatorClass, ""},
+		{"no auth", nil, client.HandshakeHandler, multiAuthenticators, "", ""},
+		{"transitional", nil, dseAuthHandler(true), anonymous, client.DseAuthenticatorClass, ""},
+		{"anonymous rejected", nil, dseAuthHandler(false), anonymous, client.DseAuthenticatorClass, "expected AUTH_SUCCESS, got ERROR AUTHENTICATION ERROR (code=ErrorCode AuthenticationError [0x00000100], msg=invalid credentials)"},
+		{"provider error", nil, dseAuthHandler(false), func(string) (client.Authenticator, error) {
+			return nil, errors.New("boom")
+		}, client.DseAuthenticatorClass, "cannot create authenticator for com.datastax.bdp.cassandra.auth.DseAuthenticator: boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := client.NewCqlServer("127.0.0.1:9043", tt.credentials)
+			server.RequestHandlers = []client.RequestHandler{tt.handler}
+			ctx, cancelFn := context.WithCancel(context.Background())
+			defer cancelFn()
+			require.NoError(t, server.Start(ctx))
+
+			clt := client.NewCqlClient("127.0.0.1:9043", nil)
+			var class string
+			clt.AuthenticatorProvider = func(authenticator string) (client.Authenticator, error) {
+				class = authenticator
+				return tt.authenticators(authenticator)
+			}
+			clientConn, err := clt.Connect(ctx)
+			require.NoError(t, err)
+
+			err = clientConn.InitiateHandshake(primitive.ProtocolVersion4, client.ManagedStreamId)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr)
+			}
+			assert.Equal(t, tt.expectedClass, class)
+
+			cancelFn()
+			checkClosed(t, clientConn, server)
+		})
+	}
+}
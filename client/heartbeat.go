@@ -0,0 +1,138 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// HeartbeatFailureHandler is a callback function that gets invoked whenever a heartbeat sent by a CqlClientConnection
+// fails, typically because the server did not reply in time, or because the connection is broken. The connection is
+// closed right after the callback returns. See CqlClient.HeartbeatInterval.
+type HeartbeatFailureHandler func(conn *CqlClientConnection, err error)
+
+// heartbeat keeps track of the activity of a connection, and of the protocol version to use for heartbeats.
+type heartbeat struct {
+	interval time.Duration
+	request  message.Message
+	handlers []HeartbeatFailureHandler
+	// lastActivity is the time, in Unix nanoseconds, at which the last frame was sent or received.
+	lastActivity int64
+	// version is the protocol version of the last frame sent, or zero if no frame was sent yet.
+	version int32
+}
+
+// newHeartbeat returns nil if interval is zero or negative, meaning that heartbeats are disabled. If request is nil,
+// OPTIONS requests are sent.
+func newHeartbeat(interval time.Duration, request message.Message, handlers []HeartbeatFailureHandler) *heartbeat {
+	if interval <= 0 {
+		return nil
+	}
+	if request == nil {
+		request = &message.Options{}
+	}
+	return &heartbeat{
+		interval:     interval,
+		request:      request,
+		handlers:     handlers,
+		lastActivity: time.Now().UnixNano(),
+	}
+}
+
+func (h *heartbeat) onFrameSent(f *frame.Frame) {
+	if h != nil {
+		atomic.StoreInt32(&h.version, int32(f.Header.Version))
+		atomic.StoreInt64(&h.lastActivity, time.Now().UnixNano())
+	}
+}
+
+func (h *heartbeat) onFrameReceived() {
+	if h != nil {
+		atomic.StoreInt64(&h.lastActivity, time.Now().UnixNano())
+	}
+}
+
+// idleTime returns how long the connection has been idle.
+func (h *heartbeat) idleTime() time.Duration {
+	return time.Duration(time.Now().UnixNano() - atomic.LoadInt64(&h.lastActivity))
+}
+
+func (h *heartbeat) protocolVersion() primitive.ProtocolVersion {
+	return primitive.ProtocolVersion(atomic.LoadInt32(&h.version))
+}
+
+// Done returns a channel that is closed when this connection is closed, be it explicitly, because its parent context
+// is done, or because a failure was detected, e.g. a heartbeat failure.
+func (c *CqlClientConnection) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+func (c *CqlClientConnection) heartbeatLoop() {
+	if c.heartbeat == nil {
+		return
+	}
+	log.Debug().Msgf("%v: sending heartbeats after %v of inactivity", c, c.heartbeat.interval)
+	c.waitGroup.Add(1)
+	go func() {
+		err := c.sendHeartbeats()
+		c.waitGroup.Done()
+		if err != nil && !c.IsClosed() {
+			log.Error().Err(err).Msgf("%v: heartbeat failed, closing connection", c)
+			for _, handler := range c.heartbeat.handlers {
+				handler(c, err)
+			}
+			c.abort()
+		}
+	}()
+}
+
+// sendHeartbeats sends a heartbeat whenever the connection was idle for the heartbeat interval, until the connection is
+// closed or a heartbeat fails. Heartbeats are only sent once the protocol version is known, that is, once a first
+// frame was sent.
+func (c *CqlClientConnection) sendHeartbeats() error {
+	timer := time.NewTimer(c.heartbeat.interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+		if idle := c.heartbeat.idleTime(); idle < c.heartbeat.interval {
+			timer.Reset(c.heartbeat.interval - idle)
+			continue
+		}
+		if version := c.heartbeat.protocolVersion(); version != 0 {
+			request := frame.NewFrame(version, ManagedStreamId, c.heartbeat.request)
+			log.Debug().Msgf("%v: sending heartbeat: %v", c, request)
+			// any response, including an error, shows that the server is alive
+			if _, err := c.SendAndReceiveContext(c.ctx, request); err != nil {
+				if c.IsClosed() {
+					return nil
+				}
+				return fmt.Errorf("heartbeat failed: %w", err)
+			}
+		}
+		timer.Reset(c.heartbeat.interval)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCqlClient_Heartbeat(t *testing.T) {
+	var heartbeats int32
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{
+		func(request *frame.Frame, conn *client.CqlServerConnection, ctx client.RequestHandlerContext) *frame.Frame {
+			if _, ok := request.Body.Message.(*message.Options); ok {
+				atomic.AddInt32(&heartbeats, 1)
+			}
+			return client.HeartbeatHandler(request, conn, ctx)
+		},
+		client.HandshakeHandler,
+	}
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.HeartbeatInterval = 20 * time.Millisecond
+	clt.HeartbeatFailureHandlers = []client.HeartbeatFailureHandler{
+		func(conn *client.CqlClientConnection, err error) {
+			t.Errorf("unexpected heartbeat failure: %v", err)
+		},
+	}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	// the connection is idle: heartbeats are sent periodically
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&heartbeats) >= 3 }, time.Second*10, time.Millisecond*10)
+	assert.False(t, clientConn.IsClosed())
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+	select {
+	case <-clientConn.Done():
+	default:
+		t.Error("expected Done channel to be closed")
+	}
+}
+
+func TestCqlClient_HeartbeatFailure(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	// the heartbeat request below is never answered
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler}
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.ReadTimeout = 100 * time.Millisecond
+	clt.HeartbeatInterval = 20 * time.Millisecond
+	clt.HeartbeatRequest = &message.Query{Query: "SELECT now() FROM system.local"}
+	failures := make(chan error, 1)
+	clt.HeartbeatFailureHandlers = []client.HeartbeatFailureHandler{
+		func(conn *client.CqlClientConnection, err error) {
+			failures <- err
+		},
+	}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	select {
+	case <-clientConn.Done():
+	case <-time.After(time.Second * 10):
+		require.Fail(t, "expected connection to be closed after heartbeat failure")
+	}
+	err = <-failures
+	assert.Contains(t, err.Error(), "heartbeat failed")
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
@@ -29,15 +29,16 @@ import (
 )
 
 type inFlightRequestsHandler struct {
-	connectionId string
-	ctx          context.Context
-	maxInFlight  int
-	maxPending   int
-	timeout      time.Duration
-	streamIds    chan int16
-	inFlight     map[int16]*inFlightRequest
-	inFlightLock *sync.RWMutex
-	closed       int32
+	connectionId        string
+	ctx                 context.Context
+	maxInFlight         int
+	maxPending          int
+	timeout             time.Duration
+	streamIdWaitTimeout time.Duration
+	streamIds           *StreamIdAllocator
+	inFlight            map[int16]*inFlightRequest
+	inFlightLock        *sync.RWMutex
+	closed              int32
 }
 
 func (h *inFlightRequestsHandler) String() string {
@@ -50,24 +51,33 @@ func newInFlightRequestsHandler(
 	maxInFlight int,
 	maxPending int,
 	timeout time.Duration,
-) *inFlightRequestsHandler {
-	handler := &inFlightRequestsHandler{
-		connectionId: connectionId,
-		ctx:          ctx,
-		maxInFlight:  maxInFlight,
-		maxPending:   maxPending,
-		timeout:      timeout,
-		streamIds:    make(chan int16, maxInFlight),
-		inFlight:     make(map[int16]*inFlightRequest, maxInFlight),
-		inFlightLock: &sync.RWMutex{},
+	streamIdWaitTimeout time.Duration,
+) (*inFlightRequestsHandler, error) {
+	streamIds, err := NewStreamIdAllocator(maxInFlight)
+	if err != nil {
+		return nil, err
 	}
-	for i := 1; i <= maxInFlight; i++ {
-		handler.streamIds <- int16(i)
-	}
-	return handler
+	return &inFlightRequestsHandler{
+		connectionId:        connectionId,
+		ctx:                 ctx,
+		maxInFlight:         maxInFlight,
+		maxPending:          maxPending,
+		timeout:             timeout,
+		streamIdWaitTimeout: streamIdWaitTimeout,
+		streamIds:           streamIds,
+		inFlight:            make(map[int16]*inFlightRequest, maxInFlight),
+		inFlightLock:        &sync.RWMutex{},
+	}, nil
 }
 
-func (h *inFlightRequestsHandler) onOutgoingFrameEnqueued(f *frame.Frame) (InFlightRequest, error) {
+// onOutgoingFrameEnqueued registers a new in-flight request for the given frame, borrowing a stream id for it if its
+// stream id is ManagedStreamId. The request times out if no response is received within the given timeout, and is
+// abandoned if the given context is done first.
+func (h *inFlightRequestsHandler) onOutgoingFrameEnqueued(
+	ctx context.Context,
+	f *frame.Frame,
+	timeout time.Duration,
+) (*inFlightRequest, error) {
 	if h.isClosed() {
 		return nil, fmt.Errorf("%v: handler closed", h)
 	}
@@ -75,7 +85,7 @@ func (h *inFlightRequestsHandler) onOutgoingFrameEnqueued(f *frame.Frame) (InFli
 	streamId := f.Header.StreamId
 	managedStreamId := streamId == ManagedStreamId
 	if managedStreamId {
-		if streamId, err = h.borrowStreamId(); err != nil {
+		if streamId, err = h.borrowStreamId(ctx, f.Header.Version); err != nil {
 			return nil, err
 		} else {
 			f.Header.StreamId = streamId
@@ -90,12 +100,16 @@ func (h *inFlightRequestsHandler) onOutgoingFrameEnqueued(f *frame.Frame) (InFli
 	h.inFlightLock.RUnlock()
 	if err == nil {
 		var inFlight *inFlightRequest
-		inFlight, err = h.addInFlight(streamId, managedStreamId)
+		inFlight, err = h.addInFlight(ctx, streamId, managedStreamId, timeout)
 		if err == nil {
 			inFlight.startTimeout()
 			return inFlight, nil
 		}
 	}
+	if managedStreamId {
+		f.Header.StreamId = ManagedStreamId
+		_ = h.releaseStreamId(streamId)
+	}
 	return nil, err
 }
 
@@ -113,21 +127,51 @@ func (h *inFlightRequestsHandler) onIncomingFrameReceived(f *frame.Frame) error
 	}
 	h.inFlightLock.RUnlock()
 	if err == nil {
-		if isLastFrame(f) {
-			h.removeInFlight(streamId)
-			if inFlight.managedStreamId {
-				if err := h.releaseStreamId(streamId); err != nil {
-					return err
-				}
+		if isLastFrame(f) && h.removeInFlight(inFlight) && inFlight.managedStreamId {
+			if err := h.releaseStreamId(streamId); err != nil {
+				return err
 			}
 		}
+		if inFlight.IsDone() {
+			log.Debug().Msgf("%v: discarding late incoming frame for abandoned request: %v", h, f)
+			return nil
+		}
 		err = inFlight.onFrameReceived(f)
 	}
 	return err
 }
 
-func (h *inFlightRequestsHandler) addInFlight(streamId int16, managedStreamId bool) (*inFlightRequest, error) {
-	inFlight := newInFlightRequest(h.String(), streamId, managedStreamId, h.ctx, h.maxPending, h.timeout)
+// onOutgoingFrameDiscarded unregisters the given in-flight request, whose frame could not be written, and releases its
+// stream id immediately, since no response can be received for it.
+func (h *inFlightRequestsHandler) onOutgoingFrameDiscarded(f *frame.Frame, inFlight *inFlightRequest, err error) {
+	inFlight.close(err)
+	if h.removeInFlight(inFlight) && inFlight.managedStreamId {
+		f.Header.StreamId = ManagedStreamId
+		_ = h.releaseStreamId(inFlight.streamId)
+	}
+}
+
+// onInFlightRequestAbandoned is invoked when the given in-flight request was closed before receiving its last frame,
+// e.g. because it timed out or because its context is done. Since a late response may still be received, its stream
+// id cannot be reused right away: the request is kept registered until either its last frame is received and
+// discarded, or the handler's timeout elapses, whichever happens first, then its stream id is released.
+func (h *inFlightRequestsHandler) onInFlightRequestAbandoned(inFlight *inFlightRequest) {
+	log.Debug().Msgf("%v: in-flight request abandoned, stream id %d will be released later", h, inFlight.streamId)
+	time.AfterFunc(h.timeout, func() {
+		if h.removeInFlight(inFlight) && inFlight.managedStreamId {
+			_ = h.releaseStreamId(inFlight.streamId)
+		}
+	})
+}
+
+func (h *inFlightRequestsHandler) addInFlight(
+	ctx context.Context,
+	streamId int16,
+	managedStreamId bool,
+	timeout time.Duration,
+) (*inFlightRequest, error) {
+	inFlight := newInFlightRequest(h.String(), streamId, managedStreamId, h.ctx, ctx, h.maxPending, timeout)
+	inFlight.onAbandoned = h.onInFlightRequestAbandoned
 	h.inFlightLock.Lock()
 	defer h.inFlightLock.Unlock()
 	if h.isClosed() {
@@ -137,41 +181,47 @@ func (h *inFlightRequestsHandler) addInFlight(streamId int16, managedStreamId bo
 	return inFlight, nil
 }
 
-func (h *inFlightRequestsHandler) removeInFlight(streamId int16) {
+// removeInFlight unregisters the given in-flight request, and returns true if it was still registered; it returns false
+// if it was already unregistered, in which case its stream id must not be released again.
+func (h *inFlightRequestsHandler) removeInFlight(inFlight *inFlightRequest) bool {
 	h.inFlightLock.Lock()
 	defer h.inFlightLock.Unlock()
-	if _, found := h.inFlight[streamId]; found {
-		delete(h.inFlight, streamId)
+	if h.inFlight[inFlight.streamId] == inFlight {
+		delete(h.inFlight, inFlight.streamId)
+		return true
 	}
+	return false
 }
 
-func (h *inFlightRequestsHandler) borrowStreamId() (int16, error) {
+// borrowStreamId borrows a stream id valid for the given protocol version. If all stream ids are in use, and the stream
+// id wait timeout is positive, waits until a stream id is released, the timeout expires or ctx is done.
+func (h *inFlightRequestsHandler) borrowStreamId(ctx context.Context, version primitive.ProtocolVersion) (id int16, err error) {
 	if h.isClosed() {
 		return -1, fmt.Errorf("%v: handler closed", h)
 	}
-	select {
-	case id, ok := <-h.streamIds:
-		if !ok {
-			return -1, fmt.Errorf("%v: handler closed", h)
-		}
-		log.Debug().Msgf("%v: borrowed stream id: %v", h, id)
-		return id, nil
-	default:
-		return -1, fmt.Errorf("%v: no stream id available", h)
+	if h.streamIdWaitTimeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, h.streamIdWaitTimeout)
+		id, err = h.streamIds.Acquire(ctx, version)
+		cancel()
+	} else {
+		id, err = h.streamIds.TryAcquire(version)
+	}
+	if err != nil {
+		return -1, fmt.Errorf("%v: %w", h, err)
 	}
+	log.Debug().Msgf("%v: borrowed stream id: %v", h, id)
+	return id, nil
 }
 
 func (h *inFlightRequestsHandler) releaseStreamId(id int16) error {
 	if h.isClosed() {
 		return fmt.Errorf("%v: handler closed", h)
 	}
-	select {
-	case h.streamIds <- id:
-		log.Debug().Msgf("%v: released stream id: %v", h, id)
-		return nil
-	default:
-		return fmt.Errorf("%v: stream id %d: release failed", h, id)
+	if err := h.streamIds.Release(id); err != nil {
+		return fmt.Errorf("%v: %w", h, err)
 	}
+	log.Debug().Msgf("%v: released stream id: %v", h, id)
+	return nil
 }
 
 func (h *inFlightRequestsHandler) isClosed() bool {
@@ -191,9 +241,7 @@ func (h *inFlightRequestsHandler) close() {
 			inFlight.close(fmt.Errorf("%v: handler closed", h))
 		}
 		h.inFlightLock.Unlock()
-		streamIds := h.streamIds
-		h.streamIds = nil
-		close(streamIds)
+		h.streamIds.Close()
 		log.Trace().Msgf("%v: successfully closed", h)
 	}
 }
@@ -211,6 +259,11 @@ type inFlightRequest struct {
 	cancel          context.CancelFunc
 	timeoutCtx      context.Context
 	timeoutCancel   context.CancelFunc
+	// requestCtx is the context the request was sent with; when it is done, the request is abandoned.
+	requestCtx context.Context
+	// onAbandoned, if not nil, is invoked when the request is closed because of a timeout or because requestCtx is
+	// done.
+	onAbandoned func(*inFlightRequest)
 
 	// lock guards the closing of incoming chan and the assignment of done and err;
 	// required to fulfill the interface contract:
@@ -246,6 +299,7 @@ func newInFlightRequest(
 	streamId int16,
 	managedStreamId bool,
 	ctx context.Context,
+	requestCtx context.Context,
 	maxPending int,
 	timeout time.Duration,
 ) *inFlightRequest {
@@ -260,6 +314,7 @@ func newInFlightRequest(
 		timeout:         timeout,
 		ctx:             ctx,
 		cancel:          cancel,
+		requestCtx:      requestCtx,
 		lock:            &sync.RWMutex{},
 	}
 }
@@ -282,24 +337,26 @@ func (r *inFlightRequest) onFrameReceived(f *frame.Frame) error {
 		return fmt.Errorf("%v: request closed", r)
 	default:
 		err := fmt.Errorf("%v: too many pending incoming frames: %d", r, len(r.incoming))
-		r.close(err)
+		r.abandon(err)
 		return err
 	}
 }
 
 func (r *inFlightRequest) startTimeout() {
-	r.timeoutCtx, r.timeoutCancel = context.WithTimeout(r.ctx, r.timeout)
+	timeoutCtx, timeoutCancel := context.WithTimeout(r.ctx, r.timeout)
+	r.timeoutCtx, r.timeoutCancel = timeoutCtx, timeoutCancel
 	log.Trace().Msgf("%v: timeout started", r)
 	go func() {
 		select {
-		case <-r.timeoutCtx.Done():
-			switch r.timeoutCtx.Err() {
+		case <-timeoutCtx.Done():
+			switch timeoutCtx.Err() {
 			case context.DeadlineExceeded:
-				err := fmt.Errorf("%v: timed out waiting for incoming frames", r)
-				r.close(err)
+				r.abandon(fmt.Errorf("%v: timed out waiting for incoming frames", r))
 			case context.Canceled:
 				log.Trace().Msgf("%v: timeout canceled", r)
 			}
+		case <-r.requestCtx.Done():
+			r.abandon(fmt.Errorf("%v: %w", r, r.requestCtx.Err()))
 		}
 	}()
 }
@@ -310,15 +367,24 @@ func (r *inFlightRequest) stopTimeout() {
 	}
 }
 
-func (r inFlightRequest) resetTimeout() {
+func (r *inFlightRequest) resetTimeout() {
 	r.stopTimeout()
 	r.startTimeout()
 }
 
-func (r *inFlightRequest) close(err error) {
+// abandon closes the request with the given error, then notifies onAbandoned, unless the request was already closed.
+func (r *inFlightRequest) abandon(err error) {
+	if r.close(err) && r.onAbandoned != nil {
+		r.onAbandoned(r)
+	}
+}
+
+// close closes the request with the given error, and returns true if it was not already closed.
+func (r *inFlightRequest) close(err error) (closed bool) {
 	// need to hold the lock to keep the 3 states in sync: done, incoming and err
 	r.lock.Lock()
 	if !r.done {
+		closed = true
 		log.Trace().Msgf("%v: closing", r)
 		r.cancel()
 		// set _incoming to nil first to avoid potential panic in onFrameReceived
@@ -329,6 +395,7 @@ func (r *inFlightRequest) close(err error) {
 	}
 	r.lock.Unlock()
 	log.Trace().Msgf("%v: successfully closed", r)
+	return closed
 }
 
 func isLastFrame(f *frame.Frame) bool {
@@ -336,7 +403,7 @@ func isLastFrame(f *frame.Frame) bool {
 		result := f.Body.Message.(message.Result)
 		if result.GetResultType() == primitive.ResultTypeRows {
 			rows := result.(*message.RowsResult)
-			if rows.Metadata.Flags()&primitive.RowsFlagDseContinuousPaging != 0 {
+			if rows.Metadata.Flags().Contains(primitive.RowsFlagDseContinuousPaging) {
 				return rows.Metadata.LastContinuousPage
 			}
 		}
@@ -0,0 +1,97 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// The functions in this file deliberately violate the protocol, so that a driver's defensive handling of misbehaving
+// servers can be validated: a well-behaved server never responds twice to the same request, and never sends responses
+// on stream ids that the client did not use.
+
+// RespondWithDuplicates returns a RuleAction that sends the response produced by the given action, plus the given
+// number of extra copies of it, all on the request's stream id. The copies are enqueued before the original response,
+// but since they are identical, the client cannot tell them apart: it sees one response followed by duplicates for a
+// stream id that is, from its point of view, not in use anymore. If the given action returns nil, no copies are sent.
+func RespondWithDuplicates(copies int, action RuleAction) RuleAction {
+	return func(request *frame.Frame, conn *CqlServerConnection) *frame.Frame {
+		response := action(request, conn)
+		if response == nil {
+			return nil
+		}
+		for i := 0; i < copies; i++ {
+			log.Debug().Msgf("%v: sending duplicate response %d/%d: %v", conn, i+1, copies, response)
+			if err := conn.Send(response.DeepCopy()); err != nil {
+				log.Error().Err(err).Msgf("%v: send failed for duplicate response: %v", conn, response)
+			}
+		}
+		return response
+	}
+}
+
+// InjectUnsolicited returns a RuleAction that first sends an unsolicited response with the given message, using
+// CqlServerConnection.SendUnsolicited and the request's protocol version, then delegates to the given action.
+func InjectUnsolicited(msg message.Message, action RuleAction) RuleAction {
+	return func(request *frame.Frame, conn *CqlServerConnection) *frame.Frame {
+		if _, err := conn.SendUnsolicited(request.Header.Version, msg); err != nil {
+			log.Error().Err(err).Msgf("%v: send failed for unsolicited response: %v", conn, msg)
+		}
+		return action(request, conn)
+	}
+}
+
+// SendUnsolicited sends a response frame with the given message on a stream id that was never used so far on this
+// connection, neither by the client nor by a previous unsolicited response. Stream ids are chosen starting from the
+// highest stream id allowed by the protocol version, since clients usually allocate them starting from the lowest
+// ones. The stream id that was used is returned.
+func (c *CqlServerConnection) SendUnsolicited(version primitive.ProtocolVersion, msg message.Message) (int16, error) {
+	streamId, err := c.nextUnusedStreamId(version)
+	if err != nil {
+		return 0, err
+	}
+	log.Debug().Msgf("%v: sending unsolicited response on stream id %d: %v", c, streamId, msg)
+	return streamId, c.Send(frame.NewFrame(version, streamId, msg))
+}
+
+func (c *CqlServerConnection) markStreamIdUsed(streamId int16) {
+	c.usedStreamIdsLock.Lock()
+	defer c.usedStreamIdsLock.Unlock()
+	c.usedStreamIds[streamId] = true
+}
+
+func (c *CqlServerConnection) nextUnusedStreamId(version primitive.ProtocolVersion) (int16, error) {
+	// protocol version 2 uses 1-byte stream ids
+	maxStreamId := int16(math.MaxInt16)
+	if version < primitive.ProtocolVersion3 {
+		maxStreamId = math.MaxInt8
+	}
+	c.usedStreamIdsLock.Lock()
+	defer c.usedStreamIdsLock.Unlock()
+	for streamId := maxStreamId; streamId > 0; streamId-- {
+		if !c.usedStreamIds[streamId] {
+			c.usedStreamIds[streamId] = true
+			return streamId, nil
+		}
+	}
+	return 0, fmt.Errorf("%v: no unused stream id left for protocol version %v", c, version)
+}
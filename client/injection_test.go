@@ -0,0 +1,129 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// startServerAndDial starts a server with the given handlers, and opens a raw TCP connection to it, so that the test
+// can observe exactly which frames the server sends.
+func startServerAndDial(t *testing.T, handlers ...client.RequestHandler) (*client.CqlServer, net.Conn, context.CancelFunc) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = handlers
+	ctx, cancelFn := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+	conn, err := net.Dial("tcp", "127.0.0.1:9043")
+	require.NoError(t, err)
+	return server, conn, cancelFn
+}
+
+func sendAndReceiveRaw(t *testing.T, conn net.Conn, request *frame.Frame, responses int) []*frame.Frame {
+	codec := frame.NewCodec()
+	require.NoError(t, codec.EncodeFrame(request, conn))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var received []*frame.Frame
+	for i := 0; i < responses; i++ {
+		response, err := codec.DecodeFrame(conn)
+		require.NoError(t, err)
+		received = append(received, response)
+	}
+	return received
+}
+
+func TestRespondWithDuplicates(t *testing.T) {
+	engine := client.NewRuleEngine(client.NewRule(client.MatchOpCode(primitive.OpCodeOptions)).
+		Then(client.RespondWithDuplicates(2, client.RespondWith(&message.Ready{}))))
+	server, conn, cancelFn := startServerAndDial(t, engine.Handler())
+
+	request := frame.NewFrame(primitive.ProtocolVersion4, 7, &message.Options{})
+	responses := sendAndReceiveRaw(t, conn, request, 3)
+	for _, response := range responses {
+		assert.Equal(t, int16(7), response.Header.StreamId)
+		assert.Equal(t, &message.Ready{}, response.Body.Message)
+	}
+
+	_ = conn.Close()
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestInjectUnsolicited(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  primitive.ProtocolVersion
+		expected []int16
+	}{
+		{"v2", primitive.ProtocolVersion2, []int16{math.MaxInt8, math.MaxInt8 - 2}},
+		{"v4", primitive.ProtocolVersion4, []int16{math.MaxInt16, math.MaxInt16 - 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := client.NewRuleEngine(client.NewRule(client.MatchOpCode(primitive.OpCodeOptions)).
+				Then(client.InjectUnsolicited(&message.VoidResult{}, client.RespondWith(&message.Ready{}))))
+			server, conn, cancelFn := startServerAndDial(t, engine.Handler())
+
+			// the first unsolicited response uses the highest stream id
+			responses := sendAndReceiveRaw(t, conn, frame.NewFrame(tt.version, 1, &message.Options{}), 2)
+			assert.Equal(t, tt.expected[0], responses[0].Header.StreamId)
+			assert.Equal(t, &message.VoidResult{}, responses[0].Body.Message)
+			assert.Equal(t, int16(1), responses[1].Header.StreamId)
+			assert.Equal(t, &message.Ready{}, responses[1].Body.Message)
+
+			// stream ids used by the client or by previous unsolicited responses are skipped
+			responses = sendAndReceiveRaw(t, conn, frame.NewFrame(tt.version, tt.expected[0]-1, &message.Options{}), 2)
+			assert.Equal(t, tt.expected[1], responses[0].Header.StreamId)
+			assert.Equal(t, tt.expected[0]-1, responses[1].Header.StreamId)
+
+			_ = conn.Close()
+			cancelFn()
+			assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+		})
+	}
+}
+
+func TestCqlClientConnection_ProtocolViolations(t *testing.T) {
+	engine := client.NewRuleEngine(
+		client.NewRule(client.MatchQuery("duplicate")).
+			Then(client.RespondWithDuplicates(3, client.RespondWith(&message.VoidResult{}))),
+		client.NewRule(client.MatchQuery("unsolicited")).
+			Then(client.InjectUnsolicited(&message.VoidResult{}, client.RespondWith(&message.VoidResult{}))),
+	)
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{engine.Handler(), client.HeartbeatHandler}, nil)
+
+	// extra responses are discarded by the client, which remains usable
+	for i, query := range []string{"duplicate", "unsolicited"} {
+		request := frame.NewFrame(primitive.ProtocolVersion4, int16(i+1), &message.Query{Query: query})
+		response, err := clientConn.SendAndReceive(request)
+		require.NoError(t, err)
+		assert.Equal(t, &message.VoidResult{}, response.Body.Message)
+	}
+	testHeartbeat(t, clientConn)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
@@ -0,0 +1,421 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// DefaultLoadQuery is the default query of a LoadGenerator.
+const DefaultLoadQuery = "INSERT INTO ks1.table1 (pk, v) VALUES (now(), ?)"
+
+// RequestMix gives the relative weights of the kinds of requests sent by a LoadGenerator; e.g. {Query: 3, Batch: 1}
+// sends three QUERY requests for each BATCH request on average. Weights must be positive or zero, and at least one
+// must be strictly positive.
+type RequestMix struct {
+	// Query is the weight of QUERY requests executing LoadGenerator.Query.
+	Query int
+	// Execute is the weight of EXECUTE requests executing LoadGenerator.Query, which is prepared beforehand on each
+	// connection.
+	Execute int
+	// Batch is the weight of unlogged BATCH requests containing LoadGenerator.BatchSize simple statements executing
+	// LoadGenerator.Query.
+	Batch int
+}
+
+func (m RequestMix) validate() error {
+	if m.Query < 0 || m.Execute < 0 || m.Batch < 0 {
+		return fmt.Errorf("request mix: expecting positive weights, got: %+v", m)
+	} else if m.Query+m.Execute+m.Batch == 0 {
+		return errors.New("request mix: expecting at least one strictly positive weight")
+	}
+	return nil
+}
+
+// pick returns the opcode of a request chosen randomly according to the weights.
+func (m RequestMix) pick(random *rand.Rand) primitive.OpCode {
+	n := random.Intn(m.Query + m.Execute + m.Batch)
+	if n < m.Query {
+		return primitive.OpCodeQuery
+	} else if n < m.Query+m.Execute {
+		return primitive.OpCodeExecute
+	}
+	return primitive.OpCodeBatch
+}
+
+// LoadGenerator drives wire-level load against a server, e.g. to benchmark a server, a proxy, or this library itself.
+// It opens a number of connections with its CqlClient, spread over the given protocol versions, then runs a number of
+// concurrent workers on each connection; each worker sends requests chosen according to the request mix, one at a
+// time, and waits for their responses. Connections pipeline the requests of their workers, so the number of in-flight
+// requests per connection is the concurrency. Run stops after the given number of requests, the given duration, or
+// when its context is done, whichever happens first, and returns a LoadReport with throughput and latency
+// statistics.
+//
+// All requests execute the same query, which must have a single bound variable, to which a blob of PayloadSize
+// random bytes is bound. The client options apply, e.g. to use compression or to limit the request rate. LoadGenerator
+// instances should be created by calling NewLoadGenerator.
+type LoadGenerator struct {
+	// Client is the CqlClient used to open connections.
+	Client *CqlClient
+	// Versions are the protocol versions of the connections, which are assigned to connections in turn. Defaults to
+	// primitive.ProtocolVersion4.
+	Versions []primitive.ProtocolVersion
+	// Connections is the number of connections to open. Defaults to 1.
+	Connections int
+	// Concurrency is the number of concurrent workers per connection. Defaults to 1.
+	Concurrency int
+	// Requests is the total number of requests to send. If zero, requests are sent until Duration elapses, or until the
+	// context passed to Run is done.
+	Requests int64
+	// Duration is how long to send requests. If zero, requests are sent until Requests requests were sent, or until
+	// the context passed to Run is done.
+	Duration time.Duration
+	// Mix is the mix of requests to send. Defaults to QUERY requests only.
+	Mix RequestMix
+	// Query is the query to execute. Defaults to DefaultLoadQuery.
+	Query string
+	// PayloadSize is the size, in bytes, of the payload bound to each statement. Defaults to 100.
+	PayloadSize int
+	// BatchSize is the number of statements in BATCH requests. Defaults to 10.
+	BatchSize int
+	// Consistency is the consistency level of all requests. Defaults to LOCAL_ONE.
+	Consistency primitive.ConsistencyLevel
+}
+
+// NewLoadGenerator creates a new LoadGenerator with default options, opening connections with the given client.
+func NewLoadGenerator(client *CqlClient) *LoadGenerator {
+	return &LoadGenerator{
+		Client:      client,
+		Versions:    []primitive.ProtocolVersion{primitive.ProtocolVersion4},
+		Connections: 1,
+		Concurrency: 1,
+		Mix:         RequestMix{Query: 1},
+		Query:       DefaultLoadQuery,
+		PayloadSize: 100,
+		BatchSize:   10,
+		Consistency: primitive.ConsistencyLevelLocalOne,
+	}
+}
+
+func (g *LoadGenerator) validate() error {
+	if g.Client == nil {
+		return errors.New("client: expecting non-nil")
+	} else if len(g.Versions) == 0 {
+		return errors.New("versions: expecting at least one protocol version")
+	} else if g.Connections < 1 {
+		return fmt.Errorf("connections: expecting positive, got: %v", g.Connections)
+	} else if g.Concurrency < 1 {
+		return fmt.Errorf("concurrency: expecting positive, got: %v", g.Concurrency)
+	} else if g.Requests < 0 {
+		return fmt.Errorf("requests: expecting positive or zero, got: %v", g.Requests)
+	} else if g.Duration < 0 {
+		return fmt.Errorf("duration: expecting positive or zero, got: %v", g.Duration)
+	} else if g.PayloadSize < 0 {
+		return fmt.Errorf("payload size: expecting positive or zero, got: %v", g.PayloadSize)
+	} else if g.Mix.Batch > 0 && g.BatchSize < 1 {
+		return fmt.Errorf("batch size: expecting positive, got: %v", g.BatchSize)
+	}
+	return g.Mix.validate()
+}
+
+// Run opens the connections, sends requests until one of the stop conditions is met, then closes the connections and
+// returns the statistics of the completed requests. It returns an error if the options are invalid, or if a
+// connection cannot be opened, or the query cannot be prepared when the mix contains EXECUTE requests. Failed
+// requests, including requests that received an ERROR response, are reported as errors in the report. A worker stops
+// if its connection gets closed.
+func (g *LoadGenerator) Run(ctx context.Context) (*LoadReport, error) {
+	if err := g.validate(); err != nil {
+		return nil, fmt.Errorf("cannot run load generator: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	connections := make([]*loadConnection, 0, g.Connections)
+	defer func() {
+		for _, connection := range connections {
+			_ = connection.conn.Close()
+		}
+	}()
+	for i := 0; i < g.Connections; i++ {
+		connection, err := g.connect(ctx, g.Versions[i%len(g.Versions)])
+		if err != nil {
+			return nil, fmt.Errorf("cannot run load generator: connection %d: %w", i, err)
+		}
+		connections = append(connections, connection)
+	}
+	if g.Duration > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, g.Duration)
+		defer cancelTimeout()
+	}
+	var sent int64
+	workers := make([]*loadWorker, 0, g.Connections*g.Concurrency)
+	wg := &sync.WaitGroup{}
+	start := time.Now()
+	for _, connection := range connections {
+		for i := 0; i < g.Concurrency; i++ {
+			worker := &loadWorker{
+				generator:  g,
+				connection: connection,
+				random:     rand.New(rand.NewSource(start.UnixNano() + int64(len(workers)))),
+				stats:      make(map[primitive.OpCode]*LoadStats),
+			}
+			workers = append(workers, worker)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				worker.run(ctx, &sent)
+			}()
+		}
+	}
+	wg.Wait()
+	return newLoadReport(time.Since(start), workers), nil
+}
+
+type loadConnection struct {
+	conn       *CqlClientConnection
+	version    primitive.ProtocolVersion
+	preparedId []byte
+}
+
+func (g *LoadGenerator) connect(ctx context.Context, version primitive.ProtocolVersion) (*loadConnection, error) {
+	conn, err := g.Client.ConnectAndInit(ctx, version, ManagedStreamId)
+	if err != nil {
+		if conn != nil {
+			_ = conn.Close()
+		}
+		return nil, err
+	}
+	connection := &loadConnection{conn: conn, version: version}
+	if g.Mix.Execute > 0 {
+		prepare := frame.NewFrame(version, ManagedStreamId, &message.Prepare{Query: g.Query})
+		if response, err := conn.SendAndReceiveContext(ctx, prepare); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("cannot prepare query: %w", err)
+		} else if prepared, ok := response.Body.Message.(*message.PreparedResult); !ok {
+			_ = conn.Close()
+			return nil, fmt.Errorf("cannot prepare query: expected PREPARED RESULT, got: %v", response.Body.Message)
+		} else {
+			connection.preparedId = prepared.PreparedQueryId
+		}
+	}
+	return connection, nil
+}
+
+type loadWorker struct {
+	generator  *LoadGenerator
+	connection *loadConnection
+	random     *rand.Rand
+	stats      map[primitive.OpCode]*LoadStats
+}
+
+func (w *loadWorker) run(ctx context.Context, sent *int64) {
+	g := w.generator
+	for ctx.Err() == nil && !w.connection.conn.IsClosed() {
+		if g.Requests > 0 && atomic.AddInt64(sent, 1) > g.Requests {
+			return
+		}
+		opCode := g.Mix.pick(w.random)
+		request := frame.NewFrame(w.connection.version, ManagedStreamId, w.newRequest(opCode))
+		start := time.Now()
+		response, err := w.connection.conn.SendAndReceiveContext(ctx, request)
+		latency := time.Since(start)
+		if err != nil && ctx.Err() != nil {
+			// the request was interrupted because the run is over
+			return
+		}
+		stats, found := w.stats[opCode]
+		if !found {
+			stats = &LoadStats{}
+			w.stats[opCode] = stats
+		}
+		stats.Requests++
+		if err != nil {
+			stats.Errors++
+		} else {
+			if _, isError := response.Body.Message.(message.Error); isError {
+				stats.Errors++
+			}
+			stats.latencies = append(stats.latencies, latency)
+		}
+	}
+}
+
+func (w *loadWorker) newRequest(opCode primitive.OpCode) message.Message {
+	g := w.generator
+	switch opCode {
+	case primitive.OpCodeExecute:
+		return &message.Execute{
+			QueryId: w.connection.preparedId,
+			Options: &message.QueryOptions{Consistency: g.Consistency, PositionalValues: w.newValues()},
+		}
+	case primitive.OpCodeBatch:
+		batch := &message.Batch{
+			Type:        primitive.BatchTypeUnlogged,
+			Consistency: g.Consistency,
+			Children:    make([]*message.BatchChild, g.BatchSize),
+		}
+		for i := range batch.Children {
+			batch.Children[i] = &message.BatchChild{Query: g.Query, Values: w.newValues()}
+		}
+		return batch
+	default:
+		return &message.Query{
+			Query:   g.Query,
+			Options: &message.QueryOptions{Consistency: g.Consistency, PositionalValues: w.newValues()},
+		}
+	}
+}
+
+func (w *loadWorker) newValues() []*primitive.Value {
+	payload := make([]byte, w.generator.PayloadSize)
+	_, _ = w.random.Read(payload)
+	return []*primitive.Value{primitive.NewValue(payload)}
+}
+
+// LoadStats are the statistics collected by a LoadGenerator for a kind of request, or for all requests.
+type LoadStats struct {
+	// Requests is the number of completed requests, whether they succeeded or not.
+	Requests int64
+	// Errors is the number of failed requests, including requests that received an ERROR response.
+	Errors int64
+	// latencies are the sorted latencies of the requests that received a response.
+	latencies []time.Duration
+}
+
+func (s *LoadStats) merge(other *LoadStats) {
+	s.Requests += other.Requests
+	s.Errors += other.Errors
+	s.latencies = append(s.latencies, other.latencies...)
+}
+
+// MeanLatency returns the mean latency of the requests that received a response, or zero if there is none.
+func (s *LoadStats) MeanLatency() time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, latency := range s.latencies {
+		total += latency
+	}
+	return total / time.Duration(len(s.latencies))
+}
+
+// MaxLatency returns the highest latency of the requests that received a response, or zero if there is none.
+func (s *LoadStats) MaxLatency() time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	return s.latencies[len(s.latencies)-1]
+}
+
+// Percentile returns the latency below which the given percentage of the requests that received a response fall,
+// e.g. 99 for the 99th percentile, or zero if there is none. The percentage must be between 0 and 100.
+func (s *LoadStats) Percentile(percentage float64) time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	index := int(percentage / 100 * float64(len(s.latencies)))
+	if index >= len(s.latencies) {
+		index = len(s.latencies) - 1
+	} else if index < 0 {
+		index = 0
+	}
+	return s.latencies[index]
+}
+
+func (s *LoadStats) String() string {
+	return fmt.Sprintf("%d requests, %d errors, latency mean %v, p50 %v, p99 %v, max %v",
+		s.Requests,
+		s.Errors,
+		s.MeanLatency(),
+		s.Percentile(50),
+		s.Percentile(99),
+		s.MaxLatency())
+}
+
+// LoadReport is the result of LoadGenerator.Run.
+type LoadReport struct {
+	// Elapsed is the duration of the run, excluding the time spent opening connections.
+	Elapsed time.Duration
+	// Total are the statistics of all requests.
+	Total *LoadStats
+	// ByOpCode are the statistics of each kind of request sent, indexed by request opcode.
+	ByOpCode map[primitive.OpCode]*LoadStats
+}
+
+func newLoadReport(elapsed time.Duration, workers []*loadWorker) *LoadReport {
+	report := &LoadReport{
+		Elapsed:  elapsed,
+		Total:    &LoadStats{},
+		ByOpCode: make(map[primitive.OpCode]*LoadStats),
+	}
+	for _, worker := range workers {
+		for opCode, stats := range worker.stats {
+			total, found := report.ByOpCode[opCode]
+			if !found {
+				total = &LoadStats{}
+				report.ByOpCode[opCode] = total
+			}
+			total.merge(stats)
+			report.Total.merge(stats)
+		}
+	}
+	sortLatencies(report.Total.latencies)
+	for _, stats := range report.ByOpCode {
+		sortLatencies(stats.latencies)
+	}
+	return report
+}
+
+func sortLatencies(latencies []time.Duration) {
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+}
+
+// Throughput returns the number of completed requests per second.
+func (r *LoadReport) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Total.Requests) / r.Elapsed.Seconds()
+}
+
+// String returns a multi-line summary of this report: the throughput, then the statistics of all requests, and of
+// each kind of request.
+func (r *LoadReport) String() string {
+	sb := &strings.Builder{}
+	_, _ = fmt.Fprintf(sb, "%.1f requests/s in %v\n", r.Throughput(), r.Elapsed)
+	_, _ = fmt.Fprintf(sb, "total: %v\n", r.Total)
+	opCodes := make([]primitive.OpCode, 0, len(r.ByOpCode))
+	for opCode := range r.ByOpCode {
+		opCodes = append(opCodes, opCode)
+	}
+	sort.Slice(opCodes, func(i, j int) bool { return opCodes[i] < opCodes[j] })
+	for _, opCode := range opCodes {
+		_, _ = fmt.Fprintf(sb, "%v: %v\n", opCode, r.ByOpCode[opCode])
+	}
+	return sb.String()
+}
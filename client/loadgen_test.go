@@ -0,0 +1,102 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// loadHandler answers PREPARE requests with a prepared result, and QUERY, EXECUTE and BATCH requests with a VOID
+// result, except for queries bound to a 1-byte payload, which fail.
+func loadHandler(request *frame.Frame, _ *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+	switch msg := request.Body.Message.(type) {
+	case *message.Prepare:
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.PreparedResult{
+			PreparedQueryId:   []byte{0xca, 0xfe},
+			VariablesMetadata: &message.VariablesMetadata{},
+			ResultMetadata:    &message.RowsMetadata{},
+		})
+	case *message.Query:
+		if len(msg.Options.PositionalValues[0].Contents) == 1 {
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Overloaded{ErrorMessage: "overloaded"})
+		}
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+	case *message.Execute, *message.Batch:
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+	}
+	return nil
+}
+
+func TestLoadGenerator(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, loadHandler}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	t.Run("requests", func(t *testing.T) {
+		generator := client.NewLoadGenerator(client.NewCqlClient("127.0.0.1:9043", nil))
+		generator.Versions = []primitive.ProtocolVersion{primitive.ProtocolVersion3, primitive.ProtocolVersion4}
+		generator.Connections = 2
+		generator.Concurrency = 4
+		generator.Requests = 300
+		generator.Mix = client.RequestMix{Query: 1, Execute: 1, Batch: 1}
+		report, err := generator.Run(ctx)
+		require.NoError(t, err)
+		assert.EqualValues(t, 300, report.Total.Requests)
+		assert.Zero(t, report.Total.Errors)
+		assert.Len(t, report.ByOpCode, 3)
+		for _, opCode := range []primitive.OpCode{primitive.OpCodeQuery, primitive.OpCodeExecute, primitive.OpCodeBatch} {
+			assert.Greater(t, report.ByOpCode[opCode].Requests, int64(0))
+		}
+		assert.Greater(t, report.Throughput(), 0.0)
+		assert.Greater(t, report.Total.MeanLatency(), time.Duration(0))
+		assert.LessOrEqual(t, report.Total.Percentile(50), report.Total.Percentile(99))
+		assert.LessOrEqual(t, report.Total.Percentile(99), report.Total.MaxLatency())
+		assert.Contains(t, report.String(), "OpCode QUERY [0x07]: ")
+	})
+
+	t.Run("duration and errors", func(t *testing.T) {
+		generator := client.NewLoadGenerator(client.NewCqlClient("127.0.0.1:9043", nil))
+		generator.Duration = 100 * time.Millisecond
+		generator.PayloadSize = 1
+		start := time.Now()
+		report, err := generator.Run(ctx)
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Greater(t, report.Total.Requests, int64(0))
+		assert.Equal(t, report.Total.Requests, report.Total.Errors)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		generator := client.NewLoadGenerator(client.NewCqlClient("127.0.0.1:9043", nil))
+		generator.Mix = client.RequestMix{}
+		_, err := generator.Run(ctx)
+		assert.EqualError(t, err, "cannot run load generator: request mix: expecting at least one strictly positive weight")
+	})
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Metrics receives notifications about the frames exchanged by client and server connections, e.g. to collect
+// per-opcode latency histograms in load-testing harnesses. To enable it, set CqlClient.Metrics or CqlServer.Metrics
+// before creating connections. Notifications are sent by the connections' read and write loops, so implementations
+// must be safe for concurrent use, and should return quickly. Embed NoopMetrics to implement only some methods.
+type Metrics interface {
+
+	// OnFrameEncoded is invoked after a frame was encoded, with its encoded size in bytes, and the time spent encoding
+	// it.
+	OnFrameEncoded(f *frame.Frame, size int, duration time.Duration)
+
+	// OnFrameDecoded is invoked after a frame was decoded, with its encoded size in bytes, and the time spent decoding
+	// it. When reading directly from the network, the duration includes the time spent waiting for the frame bytes
+	// after the first one.
+	OnFrameDecoded(f *frame.Frame, size int, duration time.Duration)
+
+	// OnRequestSent is invoked by client connections after a request was written.
+	OnRequestSent(request *frame.Frame)
+
+	// OnResponseReceived is invoked by client connections when a response to a request was received; the latency is
+	// the time elapsed since the request was written. It is not invoked for events.
+	OnResponseReceived(request *frame.Frame, response *frame.Frame, latency time.Duration)
+
+	// OnRequestReceived is invoked by server connections when a request was received.
+	OnRequestReceived(request *frame.Frame)
+
+	// OnResponseSent is invoked by server connections after a response to a request was written; the latency is the
+	// time elapsed since the request was received. It is not invoked for events and raw responses.
+	OnResponseSent(request *frame.Frame, response *frame.Frame, latency time.Duration)
+}
+
+// NoopMetrics is a Metrics implementation that does nothing.
+type NoopMetrics struct{}
+
+func (NoopMetrics) OnFrameEncoded(*frame.Frame, int, time.Duration)              {}
+func (NoopMetrics) OnFrameDecoded(*frame.Frame, int, time.Duration)              {}
+func (NoopMetrics) OnRequestSent(*frame.Frame)                                   {}
+func (NoopMetrics) OnResponseReceived(*frame.Frame, *frame.Frame, time.Duration) {}
+func (NoopMetrics) OnRequestReceived(*frame.Frame)                               {}
+func (NoopMetrics) OnResponseSent(*frame.Frame, *frame.Frame, time.Duration)     {}
+
+// OpCodeStats are the statistics collected by OpCodeMetrics for a given request opcode.
+type OpCodeStats struct {
+	// Requests is the number of completed requests, i.e. requests for which the last response was sent or received.
+	Requests int64
+	// TotalLatency is the sum of the latencies of completed requests.
+	TotalLatency time.Duration
+	// MaxLatency is the highest latency of completed requests.
+	MaxLatency time.Duration
+	// BytesEncoded is the total size of the encoded frames with this opcode.
+	BytesEncoded int64
+	// BytesDecoded is the total size of the decoded frames with this opcode.
+	BytesDecoded int64
+}
+
+// MeanLatency returns the mean latency of completed requests, or zero if there is none.
+func (s OpCodeStats) MeanLatency() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Requests)
+}
+
+// OpCodeMetrics is a Metrics implementation that collects basic statistics per opcode. Latencies are attributed to
+// the opcode of the request; sizes are attributed to the opcode of the encoded or decoded frame. For continuous
+// paging requests, the latency is measured until the last page. It can be shared by clients and servers.
+type OpCodeMetrics struct {
+	stats map[primitive.OpCode]*OpCodeStats
+	lock  sync.Mutex
+}
+
+// NewOpCodeMetrics creates a new, empty OpCodeMetrics.
+func NewOpCodeMetrics() *OpCodeMetrics {
+	return &OpCodeMetrics{stats: make(map[primitive.OpCode]*OpCodeStats)}
+}
+
+// Snapshot returns a copy of the statistics collected so far, indexed by opcode.
+func (m *OpCodeMetrics) Snapshot() map[primitive.OpCode]OpCodeStats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	snapshot := make(map[primitive.OpCode]OpCodeStats, len(m.stats))
+	for opCode, stats := range m.stats {
+		snapshot[opCode] = *stats
+	}
+	return snapshot
+}
+
+func (m *OpCodeMetrics) update(opCode primitive.OpCode, updateFn func(stats *OpCodeStats)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	stats, found := m.stats[opCode]
+	if !found {
+		stats = &OpCodeStats{}
+		m.stats[opCode] = stats
+	}
+	updateFn(stats)
+}
+
+func (m *OpCodeMetrics) OnFrameEncoded(f *frame.Frame, size int, _ time.Duration) {
+	m.update(f.Header.OpCode, func(stats *OpCodeStats) { stats.BytesEncoded += int64(size) })
+}
+
+func (m *OpCodeMetrics) OnFrameDecoded(f *frame.Frame, size int, _ time.Duration) {
+	m.update(f.Header.OpCode, func(stats *OpCodeStats) { stats.BytesDecoded += int64(size) })
+}
+
+func (m *OpCodeMetrics) OnRequestSent(*frame.Frame) {}
+
+func (m *OpCodeMetrics) OnResponseReceived(request *frame.Frame, response *frame.Frame, latency time.Duration) {
+	m.onResponse(request, response, latency)
+}
+
+func (m *OpCodeMetrics) OnRequestReceived(*frame.Frame) {}
+
+func (m *OpCodeMetrics) OnResponseSent(request *frame.Frame, response *frame.Frame, latency time.Duration) {
+	m.onResponse(request, response, latency)
+}
+
+func (m *OpCodeMetrics) onResponse(request *frame.Frame, response *frame.Frame, latency time.Duration) {
+	if isLastFrame(response) {
+		m.update(request.Header.OpCode, func(stats *OpCodeStats) {
+			stats.Requests++
+			stats.TotalLatency += latency
+			if latency > stats.MaxLatency {
+				stats.MaxLatency = latency
+			}
+		})
+	}
+}
+
+// requestTracker keeps track of the requests awaiting responses on a connection, to measure latencies.
+type requestTracker struct {
+	requests map[int16]*trackedRequest
+	lock     sync.Mutex
+}
+
+type trackedRequest struct {
+	request *frame.Frame
+	start   time.Time
+}
+
+func newRequestTracker() *requestTracker {
+	return &requestTracker{requests: make(map[int16]*trackedRequest)}
+}
+
+func (t *requestTracker) onRequest(request *frame.Frame) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.requests[request.Header.StreamId] = &trackedRequest{request: request, start: time.Now()}
+}
+
+// onResponse returns the request matching the given response, along with the time elapsed since it was tracked, or
+// nil if none. The request stops being tracked when its last response is received.
+func (t *requestTracker) onResponse(response *frame.Frame) (*frame.Frame, time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	tracked, found := t.requests[response.Header.StreamId]
+	if !found {
+		return nil, 0
+	}
+	if isLastFrame(response) {
+		delete(t.requests, response.Header.StreamId)
+	}
+	return tracked.request, time.Since(tracked.start)
+}
+
+// encodedFrameLength returns the length of the given frame as it was last encoded or decoded.
+func encodedFrameLength(f *frame.Frame) int {
+	return f.Header.Version.FrameHeaderLengthInBytes() + int(f.Header.BodyLength)
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// requestCounter counts requests sent and received, and implements the other Metrics methods with NoopMetrics.
+type requestCounter struct {
+	client.NoopMetrics
+	sent     int32
+	received int32
+}
+
+func (c *requestCounter) OnRequestSent(*frame.Frame) {
+	atomic.AddInt32(&c.sent, 1)
+}
+
+func (c *requestCounter) OnRequestReceived(*frame.Frame) {
+	atomic.AddInt32(&c.received, 1)
+}
+
+func TestMetrics(t *testing.T) {
+	serverMetrics := client.NewOpCodeMetrics()
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HeartbeatHandler, client.NewSetKeyspaceHandler(func(string) {})}
+	server.Metrics = serverMetrics
+	clientMetrics := client.NewOpCodeMetrics()
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.Metrics = clientMetrics
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+	testHeartbeat(t, clientConn)
+	testUseQuery(t, clientConn)
+	cancelFn()
+	checkClosed(t, clientConn, server)
+
+	for _, metrics := range []*client.OpCodeMetrics{clientMetrics, serverMetrics} {
+		snapshot := metrics.Snapshot()
+		options := snapshot[primitive.OpCodeOptions]
+		assert.EqualValues(t, 100, options.Requests)
+		assert.Greater(t, int64(options.TotalLatency), int64(0))
+		assert.GreaterOrEqual(t, options.TotalLatency, options.MaxLatency)
+		assert.LessOrEqual(t, options.MeanLatency(), options.MaxLatency)
+		assert.EqualValues(t, 1, snapshot[primitive.OpCodeQuery].Requests)
+		assert.Zero(t, snapshot[primitive.OpCodeSupported].Requests)
+		assert.Zero(t, snapshot[primitive.OpCodeResult].Requests)
+	}
+	// each OPTIONS request has an empty body
+	assert.EqualValues(t, 900, clientMetrics.Snapshot()[primitive.OpCodeOptions].BytesEncoded)
+	assert.EqualValues(t, 900, serverMetrics.Snapshot()[primitive.OpCodeOptions].BytesDecoded)
+	supportedBytes := serverMetrics.Snapshot()[primitive.OpCodeSupported].BytesEncoded
+	assert.Greater(t, supportedBytes, int64(900))
+	assert.Equal(t, supportedBytes, clientMetrics.Snapshot()[primitive.OpCodeSupported].BytesDecoded)
+}
+
+func TestMetrics_Custom(t *testing.T) {
+	serverCounter := &requestCounter{}
+	clientCounter := &requestCounter{}
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HeartbeatHandler}
+	server.Metrics = serverCounter
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.Metrics = clientCounter
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+	testHeartbeat(t, clientConn)
+	cancelFn()
+	checkClosed(t, clientConn, server)
+	assert.EqualValues(t, 100, atomic.LoadInt32(&clientCounter.sent))
+	assert.EqualValues(t, 0, atomic.LoadInt32(&clientCounter.received))
+	assert.EqualValues(t, 100, atomic.LoadInt32(&serverCounter.received))
+	assert.EqualValues(t, 0, atomic.LoadInt32(&serverCounter.sent))
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Middleware is a function invoked with each frame sent or received by a client or server connection, e.g. to log or
+// inspect the frames. To enable middlewares, set CqlClient.Middlewares or CqlServer.Middlewares before creating
+// connections; they are invoked in order. The conn parameter is the *CqlClientConnection or *CqlServerConnection that
+// exchanged the frame. Sent frames are passed after they were successfully written, and received frames after they
+// were decoded, before they are processed; raw server responses are not passed to middlewares.
+//
+// Middlewares are invoked by the connections' read and write loops, so they must be safe for concurrent use, should
+// return quickly, and must not modify the frames. See NewLoggingMiddleware.
+type Middleware func(conn fmt.Stringer, direction FrameDirection, f *frame.Frame)
+
+func invokeMiddlewares(middlewares []Middleware, conn fmt.Stringer, direction FrameDirection, f *frame.Frame) {
+	for _, middleware := range middlewares {
+		middleware(conn, direction, f)
+	}
+}
+
+// Redaction is a set of flags telling which sensitive contents FormatFrame and logging middlewares must hide.
+type Redaction int
+
+const (
+	// RedactQueryLiterals replaces string literals in query strings with '***'.
+	RedactQueryLiterals = Redaction(1 << iota)
+	// RedactValues hides the contents of bound values.
+	RedactValues
+	// RedactAuthTokens hides the tokens of AUTH_RESPONSE, AUTH_CHALLENGE and AUTH_SUCCESS messages.
+	RedactAuthTokens
+)
+
+// RedactNothing disables redaction; RedactAll enables all redactions.
+const (
+	RedactNothing = Redaction(0)
+	RedactAll     = RedactQueryLiterals | RedactValues | RedactAuthTokens
+)
+
+// Contains returns true if this redaction contains the other one.
+func (r Redaction) Contains(other Redaction) bool {
+	return r&other != 0
+}
+
+const redacted = "***"
+
+// NewLoggingMiddleware returns a Middleware logging each frame with the given logger and level, pretty-printed with
+// FormatFrame using the given redaction.
+func NewLoggingMiddleware(logger zerolog.Logger, level zerolog.Level, redaction Redaction) Middleware {
+	return func(conn fmt.Stringer, direction FrameDirection, f *frame.Frame) {
+		if event := logger.WithLevel(level); event != nil {
+			event.Msgf("%v: %v %v", conn, direction, FormatFrame(f, redaction))
+		}
+	}
+}
+
+// FormatFrame returns a human-readable, single-line description of the given frame, with its header and the relevant
+// contents of its message, hiding the sensitive contents selected by the given redaction. For example:
+//
+//	[ProtocolVersion OSS 4, stream 1] QUERY "SELECT * FROM ks.t WHERE k = '***'" (consistency LOCAL_ONE, values [***])
+func FormatFrame(f *frame.Frame, redaction Redaction) string {
+	sb := &strings.Builder{}
+	_, _ = fmt.Fprintf(sb, "[%v, stream %d] ", f.Header.Version, f.Header.StreamId)
+	formatMessage(sb, f.Body.Message, redaction)
+	if f.Body.TracingId != nil {
+		_, _ = fmt.Fprintf(sb, " (tracing id %v)", f.Body.TracingId)
+	}
+	if len(f.Body.Warnings) > 0 {
+		_, _ = fmt.Fprintf(sb, " (warnings %q)", f.Body.Warnings)
+	}
+	return sb.String()
+}
+
+func formatMessage(sb *strings.Builder, msg message.Message, redaction Redaction) {
+	switch msg := msg.(type) {
+	case *message.Query:
+		_, _ = fmt.Fprintf(sb, "QUERY %q", formatQuery(msg.Query, redaction))
+		formatQueryOptions(sb, msg.Options, redaction)
+	case *message.Prepare:
+		_, _ = fmt.Fprintf(sb, "PREPARE %q", formatQuery(msg.Query, redaction))
+		if msg.Keyspace != "" {
+			_, _ = fmt.Fprintf(sb, " (keyspace %v)", msg.Keyspace)
+		}
+	case *message.Execute:
+		_, _ = fmt.Fprintf(sb, "EXECUTE %v", msg.QueryId)
+		formatQueryOptions(sb, msg.Options, redaction)
+	case *message.Batch:
+		_, _ = fmt.Fprintf(sb, "BATCH %v (consistency %v) [", msg.Type, msg.Consistency)
+		for i, child := range msg.Children {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			if child.Query != "" {
+				_, _ = fmt.Fprintf(sb, "%q", formatQuery(child.Query, redaction))
+			} else {
+				sb.WriteString(child.Id.String())
+			}
+			if len(child.Values) > 0 {
+				_, _ = fmt.Fprintf(sb, " values %v", formatValues(child.Values, redaction))
+			} else if len(child.NamedValues) > 0 {
+				_, _ = fmt.Fprintf(sb, " values %v", formatNamedValues(child.NamedValues, redaction))
+			}
+		}
+		sb.WriteString("]")
+	case *message.AuthResponse:
+		_, _ = fmt.Fprintf(sb, "AUTH_RESPONSE (token %v)", formatToken(msg.Token, redaction))
+	case *message.AuthChallenge:
+		_, _ = fmt.Fprintf(sb, "AUTH_CHALLENGE (token %v)", formatToken(msg.Token, redaction))
+	case *message.AuthSuccess:
+		_, _ = fmt.Fprintf(sb, "AUTH_SUCCESS (token %v)", formatToken(msg.Token, redaction))
+	default:
+		_, _ = fmt.Fprintf(sb, "%v", msg)
+	}
+}
+
+func formatQueryOptions(sb *strings.Builder, options *message.QueryOptions, redaction Redaction) {
+	if options == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(sb, " (consistency %v", options.Consistency)
+	if len(options.PositionalValues) > 0 {
+		_, _ = fmt.Fprintf(sb, ", values %v", formatValues(options.PositionalValues, redaction))
+	} else if len(options.NamedValues) > 0 {
+		_, _ = fmt.Fprintf(sb, ", values %v", formatNamedValues(options.NamedValues, redaction))
+	}
+	if options.PageSize > 0 {
+		_, _ = fmt.Fprintf(sb, ", page size %d", options.PageSize)
+	}
+	sb.WriteString(")")
+}
+
+// formatQuery returns the given query string, with its string literals replaced if required.
+func formatQuery(query string, redaction Redaction) string {
+	if !redaction.Contains(RedactQueryLiterals) {
+		return query
+	}
+	sb := &strings.Builder{}
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '\'':
+			sb.WriteString("'" + redacted + "'")
+			i = endOfQuoted(runes, i) - 1
+		case '"':
+			// quoted identifiers are not literals
+			end := endOfQuoted(runes, i)
+			sb.WriteString(string(runes[i:end]))
+			i = end - 1
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+	return sb.String()
+}
+
+func formatValues(values []*primitive.Value, redaction Redaction) string {
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = formatValue(value, redaction)
+	}
+	return "[" + strings.Join(formatted, ", ") + "]"
+}
+
+func formatNamedValues(values map[string]*primitive.Value, redaction Redaction) string {
+	formatted := make([]string, 0, len(values))
+	for name, value := range values {
+		formatted = append(formatted, name+": "+formatValue(value, redaction))
+	}
+	sort.Strings(formatted)
+	return "{" + strings.Join(formatted, ", ") + "}"
+}
+
+func formatValue(value *primitive.Value, redaction Redaction) string {
+	if value == nil || value.Type == primitive.ValueTypeNull {
+		return "NULL"
+	} else if value.Type == primitive.ValueTypeUnset {
+		return "UNSET"
+	} else if redaction.Contains(RedactValues) {
+		return redacted
+	}
+	return "0x" + hex.EncodeToString(value.Contents)
+}
+
+func formatToken(token []byte, redaction Redaction) string {
+	if token == nil {
+		return "NULL"
+	} else if redaction.Contains(RedactAuthTokens) {
+		return redacted
+	}
+	return "0x" + hex.EncodeToString(token)
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestFormatFrame(t *testing.T) {
+	query := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Query{
+		Query: `SELECT * FROM ks."T" WHERE k = 'it''s' AND c = ?`,
+		Options: &message.QueryOptions{
+			Consistency:      primitive.ConsistencyLevelLocalOne,
+			PositionalValues: []*primitive.Value{primitive.NewValue([]byte{1, 2}), primitive.NewNullValue()},
+		},
+	})
+	batch := frame.NewFrame(primitive.ProtocolVersion4, 2, &message.Batch{
+		Type:        primitive.BatchTypeUnlogged,
+		Consistency: primitive.ConsistencyLevelQuorum,
+		Children: []*message.BatchChild{
+			{Query: "INSERT INTO t (k) VALUES ('a')"},
+			{Id: message.PreparedId{0xca, 0xfe}, Values: []*primitive.Value{primitive.NewValue([]byte{3})}},
+		},
+	})
+	authResponse := frame.NewFrame(primitive.ProtocolVersion4, 3, &message.AuthResponse{Token: []byte{0xba, 0xbe}})
+	ready := frame.NewFrame(primitive.ProtocolVersion4, 4, &message.Ready{})
+	tests := []struct {
+		name      string
+		f         *frame.Frame
+		redaction client.Redaction
+		expected  string
+	}{
+		{
+			"query not redacted",
+			query,
+			client.RedactNothing,
+			`[ProtocolVersion OSS 4, stream 1] QUERY "SELECT * FROM ks.\"T\" WHERE k = 'it''s' AND c = ?" (consistency ConsistencyLevel LOCAL_ONE [0x000A], values [0x0102, NULL])`,
+		},
+		{
+			"query redacted",
+			query,
+			client.RedactAll,
+			`[ProtocolVersion OSS 4, stream 1] QUERY "SELECT * FROM ks.\"T\" WHERE k = '***' AND c = ?" (consistency ConsistencyLevel LOCAL_ONE [0x000A], values [***, NULL])`,
+		},
+		{
+			"batch literals redacted",
+			batch,
+			client.RedactQueryLiterals,
+			`[ProtocolVersion OSS 4, stream 2] BATCH BatchType UNLOGGED [0x01] (consistency ConsistencyLevel QUORUM [0x0004]) ["INSERT INTO t (k) VALUES ('***')", cafe values [0x03]]`,
+		},
+		{
+			"auth token not redacted",
+			authResponse,
+			client.RedactQueryLiterals | client.RedactValues,
+			`[ProtocolVersion OSS 4, stream 3] AUTH_RESPONSE (token 0xbabe)`,
+		},
+		{
+			"auth token redacted",
+			authResponse,
+			client.RedactAuthTokens,
+			`[ProtocolVersion OSS 4, stream 3] AUTH_RESPONSE (token ***)`,
+		},
+		{
+			"other message",
+			ready,
+			client.RedactAll,
+			`[ProtocolVersion OSS 4, stream 4] READY`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, client.FormatFrame(tt.f, tt.redaction))
+		})
+	}
+}
+
+func TestMiddlewares(t *testing.T) {
+	clientLog := &lockedBuffer{}
+	serverLog := &lockedBuffer{}
+	server := client.NewCqlServer("127.0.0.1:9043", &client.AuthCredentials{Username: "cassandra", Password: "cassandra"})
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, client.HeartbeatHandler}
+	server.Middlewares = []client.Middleware{
+		client.NewLoggingMiddleware(zerolog.New(serverLog), zerolog.ErrorLevel, client.RedactAll),
+	}
+	var directions []client.FrameDirection
+	var lock sync.Mutex
+	clt := client.NewCqlClient("127.0.0.1:9043", &client.AuthCredentials{Username: "cassandra", Password: "cassandra"})
+	clt.Middlewares = []client.Middleware{
+		client.NewLoggingMiddleware(zerolog.New(clientLog), zerolog.ErrorLevel, client.RedactNothing),
+		func(_ fmt.Stringer, direction client.FrameDirection, _ *frame.Frame) {
+			lock.Lock()
+			defer lock.Unlock()
+			directions = append(directions, direction)
+		},
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	cancelFn()
+	checkClosed(t, clientConn, server)
+
+	// STARTUP, AUTHENTICATE, AUTH_RESPONSE, AUTH_SUCCESS
+	assert.Equal(t, []client.FrameDirection{client.FrameSent, client.FrameReceived, client.FrameSent, client.FrameReceived}, directions)
+	clientLines := strings.Split(strings.TrimSpace(clientLog.String()), "\n")
+	require.Len(t, clientLines, 4)
+	assert.Contains(t, clientLines[0], "sent [ProtocolVersion OSS 4, stream 1] STARTUP")
+	assert.Contains(t, clientLines[2], "sent [ProtocolVersion OSS 4, stream 2] AUTH_RESPONSE (token 0x")
+	serverLines := strings.Split(strings.TrimSpace(serverLog.String()), "\n")
+	require.Len(t, serverLines, 4)
+	assert.Contains(t, serverLines[0], "received [ProtocolVersion OSS 4, stream 1] STARTUP")
+	assert.Contains(t, serverLines[2], "received [ProtocolVersion OSS 4, stream 2] AUTH_RESPONSE (token ***)")
+	assert.Contains(t, serverLines[3], `"level":"error"`)
+}
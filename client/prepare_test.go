@@ -98,7 +98,7 @@ func testUnprepared(
 	require.Equal(t, primitive.OpCodeError, response.Header.OpCode)
 	require.IsType(t, &message.Unprepared{}, response.Body.Message)
 	result := response.Body.Message.(*message.Unprepared)
-	require.Equal(t, []byte(query), result.Id)
+	require.Equal(t, message.PreparedId(query), result.Id)
 }
 
 func testPrepare(
@@ -119,7 +119,7 @@ func testPrepare(
 	require.Equal(t, primitive.OpCodeResult, response.Header.OpCode)
 	require.IsType(t, &message.PreparedResult{}, response.Body.Message)
 	result := response.Body.Message.(*message.PreparedResult)
-	require.Equal(t, []byte(query), result.PreparedQueryId)
+	require.Equal(t, message.PreparedId(query), result.PreparedQueryId)
 	require.Equal(t, variables, result.VariablesMetadata)
 	require.Equal(t, columns, result.ResultMetadata)
 
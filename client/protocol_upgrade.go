@@ -0,0 +1,106 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// GetMaxProtocolVersion returns the highest protocol version accepted by this server, or zero if all versions are
+// accepted, which is the default.
+func (server *CqlServer) GetMaxProtocolVersion() primitive.ProtocolVersion {
+	return primitive.ProtocolVersion(atomic.LoadInt32(&server.maxVersion))
+}
+
+// SetMaxProtocolVersion changes the highest protocol version accepted by this server; use zero to accept all versions
+// again. Requests using a higher version are answered with a PROTOCOL_ERROR, like Apache Cassandra does, so that
+// clients can negotiate a lower version. The limit is applied as follows:
+//
+//   - an OSS limit accepts OSS versions up to and including the limit, and no DSE versions;
+//   - a DSE limit accepts DSE versions up to and including the limit, and OSS versions up to v4, like DataStax
+//     Enterprise does.
+//
+// This method can be called before or after the server is started. When the server is running, all the client
+// connections are reset, simulating a node being restarted with a different version, e.g. during a rolling upgrade or
+// downgrade; clients must then reconnect and negotiate the protocol version again.
+func (server *CqlServer) SetMaxProtocolVersion(version primitive.ProtocolVersion) error {
+	if version != 0 {
+		if err := primitive.CheckSupportedProtocolVersion(version); err != nil {
+			return fmt.Errorf("%v: cannot set max protocol version: %w", server, err)
+		}
+	}
+	atomic.StoreInt32(&server.maxVersion, int32(version))
+	log.Info().Msgf("%v: max protocol version set to %v", server, version)
+	if server.IsRunning() {
+		server.resetConnections()
+	}
+	return nil
+}
+
+// resetConnections closes all the client connections currently accepted by this server.
+func (server *CqlServer) resetConnections() {
+	for _, connection := range server.connectionsHandler.allAcceptedClients() {
+		log.Debug().Msgf("%v: resetting client connection: %v", server, connection)
+		if err := connection.Close(); err != nil {
+			log.Error().Err(err).Msgf("%v: error resetting client connection: %v", server, connection)
+		}
+	}
+}
+
+// isProtocolVersionAccepted tells whether the given protocol version is accepted by a server whose highest accepted
+// version is maxVersion.
+func isProtocolVersionAccepted(version primitive.ProtocolVersion, maxVersion primitive.ProtocolVersion) bool {
+	switch {
+	case maxVersion == 0:
+		return true
+	case version.IsDse():
+		return maxVersion.IsDse() && version <= maxVersion
+	case maxVersion.IsDse():
+		return version <= primitive.ProtocolVersion4
+	default:
+		return version <= maxVersion
+	}
+}
+
+// rejectUnsupportedVersion answers the given request with a PROTOCOL_ERROR if its version is not accepted by the
+// server, and returns true in that case; the request is then not processed any further.
+func (c *CqlServerConnection) rejectUnsupportedVersion(request *frame.Frame) bool {
+	if c.maxVersion == nil {
+		return false
+	}
+	maxVersion := c.maxVersion()
+	if isProtocolVersionAccepted(request.Header.Version, maxVersion) {
+		return false
+	}
+	log.Debug().Msgf("%v: rejecting request with unsupported protocol version: %v", c, request)
+	response := frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.ProtocolError{
+		ErrorMessage: fmt.Sprintf(
+			"Invalid or unsupported protocol version (%d); highest supported version is %d",
+			request.Header.Version,
+			maxVersion,
+		),
+	})
+	if err := c.Send(response); err != nil {
+		log.Error().Err(err).Msgf("%v: send failed for frame: %v", c, response)
+	}
+	return true
+}
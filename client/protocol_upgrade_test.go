@@ -0,0 +1,99 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func sendOptions(t *testing.T, clientConn *client.CqlClientConnection, version primitive.ProtocolVersion) message.Message {
+	request := frame.NewFrame(version, client.ManagedStreamId, &message.Options{})
+	response, err := clientConn.SendAndReceive(request)
+	require.NoError(t, err)
+	return response.Body.Message
+}
+
+func TestCqlServer_SetMaxProtocolVersion(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HeartbeatHandler}
+	require.NoError(t, server.SetMaxProtocolVersion(primitive.ProtocolVersion4))
+	assert.Equal(t, primitive.ProtocolVersion4, server.GetMaxProtocolVersion())
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	// before upgrade: v5 is rejected
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+	assert.IsType(t, &message.Supported{}, sendOptions(t, clientConn, primitive.ProtocolVersion4))
+	protocolError, ok := sendOptions(t, clientConn, primitive.ProtocolVersion5).(*message.ProtocolError)
+	require.True(t, ok)
+	assert.Equal(t, "Invalid or unsupported protocol version (5); highest supported version is 4", protocolError.ErrorMessage)
+	// protocol errors are fatal: the client closes the connection, and must reconnect with a supported version
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	clientConn, err = clt.Connect(ctx)
+	require.NoError(t, err)
+	assert.IsType(t, &message.Supported{}, sendOptions(t, clientConn, primitive.ProtocolVersion3))
+
+	// upgrade: existing connections are reset, new connections can use v5
+	require.NoError(t, server.SetMaxProtocolVersion(primitive.ProtocolVersion5))
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	clientConn, err = clt.Connect(ctx)
+	require.NoError(t, err)
+	assert.IsType(t, &message.Supported{}, sendOptions(t, clientConn, primitive.ProtocolVersion5))
+
+	// downgrade to DSE: OSS v5 is rejected, but OSS v4 and DSE versions are accepted
+	require.NoError(t, server.SetMaxProtocolVersion(primitive.ProtocolVersionDse1))
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	for _, version := range []primitive.ProtocolVersion{primitive.ProtocolVersion5, primitive.ProtocolVersionDse2} {
+		clientConn, err = clt.Connect(ctx)
+		require.NoError(t, err)
+		assert.IsType(t, &message.ProtocolError{}, sendOptions(t, clientConn, version))
+		assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	}
+	clientConn, err = clt.Connect(ctx)
+	require.NoError(t, err)
+	assert.IsType(t, &message.Supported{}, sendOptions(t, clientConn, primitive.ProtocolVersionDse1))
+	assert.IsType(t, &message.Supported{}, sendOptions(t, clientConn, primitive.ProtocolVersion4))
+
+	// no limit
+	require.NoError(t, server.SetMaxProtocolVersion(0))
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	clientConn, err = clt.Connect(ctx)
+	require.NoError(t, err)
+	assert.IsType(t, &message.Supported{}, sendOptions(t, clientConn, primitive.ProtocolVersion5))
+	assert.IsType(t, &message.Supported{}, sendOptions(t, clientConn, primitive.ProtocolVersionDse2))
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestCqlServer_SetMaxProtocolVersion_Invalid(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	err := server.SetMaxProtocolVersion(primitive.ProtocolVersion(1))
+	assert.EqualError(t, err, "CQL server [127.0.0.1:9043]: cannot set max protocol version: invalid protocol version: ProtocolVersion ? [0X01]")
+	assert.Equal(t, primitive.ProtocolVersion(0), server.GetMaxProtocolVersion())
+}
@@ -0,0 +1,276 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ProxyRequestHandler is a callback function that gets invoked whenever a Proxy receives a request from a client,
+// before the request is forwarded to the server. Handlers may modify the request in place. If a handler returns a
+// non-nil response, the request is not forwarded, and the response is sent to the client instead; no further
+// handlers are tried in that case.
+type ProxyRequestHandler func(request *frame.Frame, conn *ProxyConnection) (response *frame.Frame)
+
+// ProxyResponseHandler is a callback function that gets invoked whenever a Proxy receives a response or an event from
+// the server, before it is forwarded to the client; request is the request that was forwarded, or nil for events.
+// Handlers return the frame to forward, which can be the given response, possibly modified in place, or another
+// frame; if a handler returns nil, the response is dropped and no further handlers are tried. Responses bear the stream
+// id of the client's request.
+type ProxyResponseHandler func(request *frame.Frame, response *frame.Frame, conn *ProxyConnection) *frame.Frame
+
+// Proxy is a transparent proxy between CQL clients and a Cassandra-compatible backend: it accepts client connections
+// with Server, opens one backend connection with Client for each client connection, and forwards the frames exchanged
+// by both sides, invoking RequestHandlers and ResponseHandlers with the decoded frames. It is preferable to create
+// Proxy instances using the constructor function NewProxy. Once the proxy is created and properly configured, use
+// Start to start accepting client connections.
+//
+// The handshake is forwarded as well, including authentication. Compression is negotiated independently on each side:
+// client connections use the compression requested by clients, subject to Server.Compressions, while backend
+// connections use Client.Compression. Backend requests use managed stream ids, see ManagedStreamId.
+type Proxy struct {
+	// Server is the CqlServer accepting client connections. Its RequestHandlers and RequestRawHandlers are replaced when
+	// the proxy is started, and its Credentials are ignored, since authentication is performed by the backend.
+	Server *CqlServer
+	// Client is the CqlClient opening backend connections. Its Credentials and StartupOptions are ignored, since the
+	// handshake requests are those of the clients.
+	Client *CqlClient
+	// An optional list of handlers to invoke with the requests received from clients.
+	RequestHandlers []ProxyRequestHandler
+	// An optional list of handlers to invoke with the responses and events received from the backend.
+	ResponseHandlers []ProxyResponseHandler
+
+	ctx         context.Context
+	cancel      context.CancelFunc
+	connections map[*CqlServerConnection]*ProxyConnection
+	lock        sync.Mutex
+}
+
+// NewProxy creates a new Proxy with default options, listening to listenAddress and forwarding frames to
+// backendAddress.
+func NewProxy(listenAddress string, backendAddress string) *Proxy {
+	return &Proxy{
+		Server: NewCqlServer(listenAddress, nil),
+		Client: NewCqlClient(backendAddress, nil),
+	}
+}
+
+func (p *Proxy) String() string {
+	return fmt.Sprintf("CQL proxy [%v -> %v]", p.Server.ListenAddress, p.Client.RemoteAddress)
+}
+
+// Start starts accepting client connections. Set ctx to context.Background if no parent context exists; the proxy, and
+// all its connections, are closed when ctx is done.
+func (p *Proxy) Start(ctx context.Context) error {
+	if ctx == nil {
+		return fmt.Errorf("context cannot be nil")
+	}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	p.connections = make(map[*CqlServerConnection]*ProxyConnection)
+	p.Server.RequestHandlers = []RequestHandler{p.forwardRequest}
+	p.Server.RequestRawHandlers = nil
+	if err := p.Server.Start(p.ctx); err != nil {
+		p.cancel()
+		return fmt.Errorf("%v: start failed: %w", p, err)
+	}
+	log.Info().Msgf("%v: successfully started", p)
+	return nil
+}
+
+// Close closes the proxy and all its client and backend connections.
+func (p *Proxy) Close() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return p.Server.Close()
+}
+
+// ProxyConnection is a pair of connections bridged by a Proxy: a connection accepted from a client, and the
+// corresponding backend connection.
+type ProxyConnection struct {
+	frontend *CqlServerConnection
+	backend  *CqlClientConnection
+	// ready is closed once the backend connection is established, or failed to be established, in which case err is
+	// set.
+	ready chan struct{}
+	err   error
+}
+
+// Frontend returns the connection accepted from the client.
+func (c *ProxyConnection) Frontend() *CqlServerConnection {
+	return c.frontend
+}
+
+// Backend returns the connection to the backend.
+func (c *ProxyConnection) Backend() *CqlClientConnection {
+	return c.backend
+}
+
+func (c *ProxyConnection) String() string {
+	return fmt.Sprintf("CQL proxy conn [%v <-> %v]", c.frontend.RemoteAddr(), c.backend.RemoteAddr())
+}
+
+// connection returns the ProxyConnection for the given client connection, establishing its backend connection on the
+// first call.
+func (p *Proxy) connection(frontend *CqlServerConnection) (*ProxyConnection, error) {
+	p.lock.Lock()
+	conn, found := p.connections[frontend]
+	if !found {
+		conn = &ProxyConnection{frontend: frontend, ready: make(chan struct{})}
+		p.connections[frontend] = conn
+	}
+	p.lock.Unlock()
+	if !found {
+		if conn.backend, conn.err = p.Client.Connect(p.ctx); conn.err == nil {
+			log.Info().Msgf("%v: new proxy connection established: %v", p, conn)
+			p.bridge(conn)
+		} else {
+			conn.err = fmt.Errorf("%v: cannot connect to backend: %w", p, conn.err)
+			_ = frontend.Close()
+		}
+		close(conn.ready)
+	}
+	<-conn.ready
+	return conn, conn.err
+}
+
+// bridge forwards the events received from the backend, and closes each side of the given connection when the other
+// one is closed.
+func (p *Proxy) bridge(conn *ProxyConnection) {
+	events := conn.backend.EventChannel()
+	go func() {
+		for event := range events {
+			event.Header.Flags = event.Header.Flags.Remove(primitive.HeaderFlagCompressed)
+			if event = p.handleResponse(nil, event, conn); event != nil {
+				if err := conn.frontend.Send(event); err != nil {
+					log.Error().Err(err).Msgf("%v: cannot forward event: %v", conn, event)
+				}
+			}
+		}
+	}()
+	go func() {
+		select {
+		case <-conn.frontend.Done():
+		case <-conn.backend.Done():
+		}
+		log.Debug().Msgf("%v: closing proxy connection: %v", p, conn)
+		_ = conn.frontend.Close()
+		_ = conn.backend.Close()
+		p.lock.Lock()
+		delete(p.connections, conn.frontend)
+		p.lock.Unlock()
+	}()
+}
+
+// forwardRequest is the RequestHandler installed on Server; it forwards the given request to the backend, forwards all
+// the responses but the last one to the client, and returns the last one.
+func (p *Proxy) forwardRequest(request *frame.Frame, frontend *CqlServerConnection, _ RequestHandlerContext) *frame.Frame {
+	conn, err := p.connection(frontend)
+	if err != nil {
+		log.Error().Err(err).Msgf("%v: cannot forward request: %v", p, request)
+		return nil
+	}
+	for _, handler := range p.RequestHandlers {
+		if response := handler(request, conn); response != nil {
+			log.Debug().Msgf("%v: request handler produced response: %v", conn, response)
+			return response
+		}
+	}
+	forwarded := &frame.Frame{Header: &frame.Header{}, Body: request.Body}
+	*forwarded.Header = *request.Header
+	forwarded.Header.StreamId = ManagedStreamId
+	// each side compresses frames according to its own compression
+	forwarded.Header.Flags = forwarded.Header.Flags.Remove(primitive.HeaderFlagCompressed)
+	if startup, ok := request.Body.Message.(*message.Startup); ok {
+		compression := startup.GetCompression()
+		if !frontend.acceptsCompression(request.Header.Version, compression) {
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.ProtocolError{
+				ErrorMessage: fmt.Sprintf("unsupported compression: %v", compression),
+			})
+		}
+		// the backend connection has its own compression
+		backendStartup := &message.Startup{Options: make(map[string]string, len(startup.Options))}
+		for key, value := range startup.Options {
+			backendStartup.Options[key] = value
+		}
+		backendStartup.SetCompression(conn.backend.compression)
+		forwarded.Body = &frame.Body{Message: backendStartup}
+	}
+	inFlight, err := conn.backend.Send(forwarded)
+	if err != nil {
+		return p.forwardError(request, conn, err)
+	}
+	var last *frame.Frame
+	for {
+		response, err := conn.backend.Receive(inFlight)
+		if err != nil {
+			return p.forwardError(request, conn, err)
+		} else if response == nil {
+			return last
+		}
+		response.Header.StreamId = request.Header.StreamId
+		response.Header.Flags = response.Header.Flags.Remove(primitive.HeaderFlagCompressed)
+		if supported, ok := response.Body.Message.(*message.Supported); ok {
+			response.Body.Message = p.frontendSupported(supported, frontend)
+		}
+		if response = p.handleResponse(request, response, conn); response == nil {
+			continue
+		}
+		if last != nil {
+			if err := frontend.Send(last); err != nil {
+				log.Error().Err(err).Msgf("%v: cannot forward response: %v", conn, last)
+			}
+		}
+		last = response
+	}
+}
+
+func (p *Proxy) handleResponse(request *frame.Frame, response *frame.Frame, conn *ProxyConnection) *frame.Frame {
+	for _, handler := range p.ResponseHandlers {
+		if response = handler(request, response, conn); response == nil {
+			log.Debug().Msgf("%v: response handler dropped response", conn)
+			return nil
+		}
+	}
+	return response
+}
+
+// forwardError returns an error response for the given request, that could not be forwarded, or whose response could
+// not be received.
+func (p *Proxy) forwardError(request *frame.Frame, conn *ProxyConnection, err error) *frame.Frame {
+	log.Error().Err(err).Msgf("%v: cannot forward request: %v", conn, request)
+	return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.ServerError{
+		ErrorMessage: fmt.Sprintf("proxy error: %v", err),
+	})
+}
+
+// frontendSupported returns a copy of the given SUPPORTED response from the backend, advertising the compression
+// algorithms accepted by the client connection rather than those of the backend.
+func (p *Proxy) frontendSupported(supported *message.Supported, frontend *CqlServerConnection) *message.Supported {
+	result := supported.DeepCopy()
+	if result.Options == nil {
+		result.Options = map[string][]string{}
+	}
+	result.Options[message.SupportedCompression] = frontend.supported().Options[message.SupportedCompression]
+	return result
+}
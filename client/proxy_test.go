@@ -0,0 +1,166 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestProxy(t *testing.T) {
+	tests := []struct {
+		version     primitive.ProtocolVersion
+		compression primitive.Compression
+	}{
+		{primitive.ProtocolVersion4, primitive.CompressionNone},
+		{primitive.ProtocolVersion4, primitive.CompressionSnappy},
+		{primitive.ProtocolVersion5, primitive.CompressionNone},
+		{primitive.ProtocolVersion5, primitive.CompressionLz4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version.String()+" "+string(tt.compression), func(t *testing.T) {
+			testProxy(t, tt.version, tt.compression)
+		})
+	}
+}
+
+func testProxy(t *testing.T, version primitive.ProtocolVersion, compression primitive.Compression) {
+	backendConns := make(chan *client.CqlServerConnection, 1)
+	backend := client.NewCqlServer("127.0.0.1:9043", nil)
+	backend.RequestHandlers = []client.RequestHandler{
+		client.HandshakeHandler,
+		client.RegisterHandler,
+		func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+			if query, ok := request.Body.Message.(*message.Query); ok {
+				backendConns <- conn
+				return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.SetKeyspaceResult{
+					Keyspace: query.Query,
+				})
+			}
+			return nil
+		},
+	}
+	proxy := client.NewProxy("127.0.0.1:9047", "127.0.0.1:9043")
+	// the backend connection does not necessarily use the same compression as the client connection
+	proxy.Client.Compression = primitive.CompressionLz4
+	proxy.RequestHandlers = []client.ProxyRequestHandler{
+		func(request *frame.Frame, conn *client.ProxyConnection) *frame.Frame {
+			if query, ok := request.Body.Message.(*message.Query); ok && query.Query == "intercepted" {
+				return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+			}
+			return nil
+		},
+	}
+	proxy.ResponseHandlers = []client.ProxyResponseHandler{
+		func(request *frame.Frame, response *frame.Frame, conn *client.ProxyConnection) *frame.Frame {
+			if result, ok := response.Body.Message.(*message.SetKeyspaceResult); ok && result.Keyspace == "dropped" {
+				return nil
+			} else if ok {
+				result.Keyspace = "proxied_" + result.Keyspace
+			} else if event, ok := response.Body.Message.(*message.StatusChangeEvent); ok {
+				assert.Nil(t, request)
+				event.ChangeType = primitive.StatusChangeTypeDown
+			}
+			return response
+		},
+	}
+	clt := client.NewCqlClient("127.0.0.1:9047", nil)
+	clt.Compression = compression
+	clt.ReadTimeout = 500 * time.Millisecond
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, backend.Start(ctx))
+	require.NoError(t, proxy.Start(ctx))
+	clientConn, err := clt.ConnectAndInit(ctx, version, 1)
+	require.NoError(t, err)
+
+	// forwarded request and modified response
+	response, err := clientConn.SendAndReceive(frame.NewFrame(version, 2, &message.Query{Query: "ks1"}))
+	require.NoError(t, err)
+	assert.Equal(t, int16(2), response.Header.StreamId)
+	assert.Equal(t, &message.SetKeyspaceResult{Keyspace: "proxied_ks1"}, response.Body.Message)
+	backendConn := <-backendConns
+
+	// intercepted request
+	response, err = clientConn.SendAndReceive(frame.NewFrame(version, 3, &message.Query{Query: "intercepted"}))
+	require.NoError(t, err)
+	assert.Equal(t, &message.VoidResult{}, response.Body.Message)
+
+	// dropped response
+	_, err = clientConn.SendAndReceive(frame.NewFrame(version, 4, &message.Query{Query: "dropped"}))
+	assert.Error(t, err)
+	<-backendConns
+
+	// forwarded and modified event
+	response, err = clientConn.SendAndReceive(frame.NewFrame(version, 5, &message.Register{
+		EventTypes: []primitive.EventType{primitive.EventTypeStatusChange},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, &message.Ready{}, response.Body.Message)
+	err = backendConn.Send(frame.NewFrame(version, -1, &message.StatusChangeEvent{
+		ChangeType: primitive.StatusChangeTypeUp,
+		Address:    &primitive.Inet{Addr: []byte{127, 0, 0, 1}, Port: 9042},
+	}))
+	require.NoError(t, err)
+	select {
+	case event := <-clientConn.EventChannel():
+		assert.Equal(t, int16(-1), event.Header.StreamId)
+		assert.Equal(t, primitive.StatusChangeTypeDown, event.Body.Message.(*message.StatusChangeEvent).ChangeType)
+	case <-time.After(time.Second * 10):
+		t.Fatal("expected event to be forwarded")
+	}
+
+	// closing the backend connection closes the client connection
+	require.NoError(t, backendConn.Close())
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+
+	cancelFn()
+	assert.Eventually(t, proxy.Server.IsClosed, time.Second*10, time.Millisecond*10)
+	assert.Eventually(t, backend.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestProxy_UnsupportedCompression(t *testing.T) {
+	backend := client.NewCqlServer("127.0.0.1:9043", nil)
+	backend.RequestHandlers = []client.RequestHandler{client.HandshakeHandler}
+	proxy := client.NewProxy("127.0.0.1:9047", "127.0.0.1:9043")
+	proxy.Server.Compressions = []primitive.Compression{primitive.CompressionLz4}
+	clt := client.NewCqlClient("127.0.0.1:9047", nil)
+	clt.Compression = primitive.CompressionSnappy
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, backend.Start(ctx))
+	require.NoError(t, proxy.Start(ctx))
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+	response, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Options{}))
+	require.NoError(t, err)
+	assert.Equal(t, []primitive.Compression{primitive.CompressionLz4}, response.Body.Message.(*message.Supported).Compression())
+	startup, err := clientConn.NewStartupRequest(primitive.ProtocolVersion4, 1)
+	require.NoError(t, err)
+	response, err = clientConn.SendAndReceive(startup)
+	require.NoError(t, err)
+	assert.Equal(t, &message.ProtocolError{ErrorMessage: "unsupported compression: SNAPPY"}, response.Body.Message)
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+)
+
+// ThrottleListener is a callback function that gets invoked whenever a request sent by a CqlClientConnection was
+// delayed by a RateLimiter. The delay is the time the request waited before being sent; the callback is invoked after
+// the wait, just before the request is enqueued.
+type ThrottleListener func(conn *CqlClientConnection, request *frame.Frame, delay time.Duration)
+
+// RateLimiter is a token bucket limiting the rate at which requests are sent, and is safe for concurrent use. The
+// bucket holds at most burst tokens, and is refilled at the given rate; each request consumes one token. The bucket is
+// initially full, so up to burst requests can be sent at once, after which requests are spaced out evenly to match the
+// rate.
+//
+// When the bucket is empty, TryAcquire fails immediately, while Acquire reserves the next token and waits until it is
+// available, or its context is done, whichever happens first. Since tokens are reserved in order, concurrent callers of
+// Acquire are served in order as well, and the resulting request rate is precise even under contention.
+//
+// A RateLimiter can be shared by all the connections of a CqlClient, by setting CqlClient.RateLimiter, in which case
+// it limits the global request rate; set CqlClient.ConnectionRateLimit instead to limit the request rate of each
+// connection. Both can be combined.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	// tokens is negative when tokens were reserved by waiting callers.
+	tokens float64
+	last   time.Time
+	lock   sync.Mutex
+}
+
+// NewRateLimiter creates a new RateLimiter allowing rate requests per second on average, and bursts of at most burst
+// requests. rate must be strictly positive, and burst must be at least 1.
+func NewRateLimiter(rate float64, burst int) (*RateLimiter, error) {
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return nil, fmt.Errorf("rate: expecting positive, got: %v", rate)
+	}
+	if burst < 1 {
+		return nil, fmt.Errorf("burst: expecting positive, got: %v", burst)
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}, nil
+}
+
+// Rate returns the number of requests per second allowed by this limiter.
+func (l *RateLimiter) Rate() float64 {
+	return l.rate
+}
+
+// Burst returns the maximum number of requests this limiter allows at once.
+func (l *RateLimiter) Burst() int {
+	return int(l.burst)
+}
+
+// TryAcquire consumes a token if one is available, and returns true; otherwise it returns false immediately.
+func (l *RateLimiter) TryAcquire() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.refill(time.Now())
+	if l.tokens >= 1 {
+		l.tokens--
+		return true
+	}
+	return false
+}
+
+// Acquire consumes a token, waiting until one is available, or the given context is done, whichever happens first.
+// It returns how long it waited. If the context is done before a token is available, the reserved token is given back
+// and the context error is returned.
+func (l *RateLimiter) Acquire(ctx context.Context) (time.Duration, error) {
+	delay := l.reserve(time.Now())
+	if delay <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		l.lock.Lock()
+		l.tokens++
+		l.lock.Unlock()
+		return 0, ctx.Err()
+	}
+}
+
+// reserve consumes a token, possibly making the bucket negative, and returns how long the caller must wait until the
+// token becomes available.
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.refill(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+		l.last = now
+	}
+}
+
+// throttle waits until the given request is allowed by all the rate limiters of this connection, or until either ctx or
+// the connection's context is done, then notifies the throttle listeners if the request was delayed.
+func (c *CqlClientConnection) throttle(ctx context.Context, f *frame.Frame) error {
+	if len(c.rateLimiters) == 0 {
+		return nil
+	}
+	ctx, cancel := mergeContexts(c.ctx, ctx)
+	defer cancel()
+	var delay time.Duration
+	for _, limiter := range c.rateLimiters {
+		if waited, err := limiter.Acquire(ctx); err != nil {
+			return fmt.Errorf("%v: rate limiter wait interrupted: %w", c, err)
+		} else {
+			delay += waited
+		}
+	}
+	if delay > 0 {
+		log.Debug().Msgf("%v: outgoing frame throttled for %v: %v", c, delay, f)
+		for _, listener := range c.throttleListeners {
+			listener(c, f, delay)
+		}
+	}
+	return nil
+}
+
+// mergeContexts returns a context derived from parent that is also done when other is done.
+func mergeContexts(parent context.Context, other context.Context) (context.Context, context.CancelFunc) {
+	merged, cancel := context.WithCancel(parent)
+	if other.Done() != nil {
+		go func() {
+			select {
+			case <-other.Done():
+				cancel()
+			case <-merged.Done():
+			}
+		}()
+	}
+	return merged, cancel
+}
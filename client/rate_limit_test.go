@@ -0,0 +1,176 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		rate   float64
+		burst  int
+		errMsg string
+	}{
+		{"valid", 100, 10, ""},
+		{"zero rate", 0, 1, "rate: expecting positive, got: 0"},
+		{"negative rate", -1, 1, "rate: expecting positive, got: -1"},
+		{"zero burst", 100, 0, "burst: expecting positive, got: 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := client.NewRateLimiter(tt.rate, tt.burst)
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.rate, limiter.Rate())
+				assert.Equal(t, tt.burst, limiter.Burst())
+			} else {
+				assert.EqualError(t, err, tt.errMsg)
+				assert.Nil(t, limiter)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_TryAcquire(t *testing.T) {
+	limiter, err := client.NewRateLimiter(1, 3)
+	require.NoError(t, err)
+	// the bucket is initially full
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.TryAcquire())
+	}
+	assert.False(t, limiter.TryAcquire())
+}
+
+func TestRateLimiter_Acquire(t *testing.T) {
+	limiter, err := client.NewRateLimiter(100, 2)
+	require.NoError(t, err)
+	start := time.Now()
+	var total time.Duration
+	for i := 0; i < 12; i++ {
+		delay, err := limiter.Acquire(context.Background())
+		require.NoError(t, err)
+		total += delay
+	}
+	// 2 requests are allowed immediately, then the remaining 10 at a rate of one every 10 ms
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, int64(elapsed), int64(90*time.Millisecond))
+	assert.Less(t, int64(elapsed), int64(time.Second))
+	assert.Greater(t, int64(total), int64(0))
+}
+
+func TestRateLimiter_Acquire_ContextDone(t *testing.T) {
+	limiter, err := client.NewRateLimiter(1, 1)
+	require.NoError(t, err)
+	assert.True(t, limiter.TryAcquire())
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelFn()
+	delay, err := limiter.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, delay)
+}
+
+func TestCqlClientConnection_RateLimit(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HeartbeatHandler}
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.ConnectionRateLimit = 100
+	var throttled []time.Duration
+	var lock sync.Mutex
+	clt.ThrottleListeners = []client.ThrottleListener{
+		func(conn *client.CqlClientConnection, request *frame.Frame, delay time.Duration) {
+			lock.Lock()
+			defer lock.Unlock()
+			assert.IsType(t, &message.Options{}, request.Body.Message)
+			throttled = append(throttled, delay)
+		},
+	}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+
+	start := time.Now()
+	heartbeat := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{})
+	for i := 0; i < 11; i++ {
+		_, err := clientConn.SendAndReceive(heartbeat)
+		require.NoError(t, err)
+	}
+	// the first request is allowed immediately, then the remaining 10 at a rate of one every 10 ms
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(90*time.Millisecond))
+	lock.Lock()
+	assert.NotEmpty(t, throttled)
+	for _, delay := range throttled {
+		assert.Greater(t, int64(delay), int64(0))
+		assert.LessOrEqual(t, int64(delay), int64(10*time.Millisecond))
+	}
+	lock.Unlock()
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestCqlClient_RateLimiter(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HeartbeatHandler}
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	limiter, err := client.NewRateLimiter(100, 1)
+	require.NoError(t, err)
+	clt.RateLimiter = limiter
+	ctx, cancelFn := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+	clientConn1, err := clt.Connect(ctx)
+	require.NoError(t, err)
+	clientConn2, err := clt.Connect(ctx)
+	require.NoError(t, err)
+
+	// the limiter is shared: 11 requests over 2 connections take at least 100 ms
+	start := time.Now()
+	heartbeat := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{})
+	for i := 0; i < 11; i++ {
+		clientConn := clientConn1
+		if i%2 == 1 {
+			clientConn = clientConn2
+		}
+		_, err := clientConn.SendAndReceive(heartbeat)
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(90*time.Millisecond))
+
+	cancelFn()
+	checkClosed(t, clientConn1, server)
+	checkClosed(t, clientConn2, server)
+}
+
+func TestCqlClient_InvalidConnectionRateLimit(t *testing.T) {
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.ConnectionRateLimit = math.Inf(1)
+	clientConn, err := clt.Connect(context.Background())
+	assert.Nil(t, clientConn)
+	assert.EqualError(t, err, "CQL client [127.0.0.1:9043]: connection rate limit: rate: expecting positive, got: +Inf")
+}
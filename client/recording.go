@@ -0,0 +1,259 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// FrameDirection tells whether a frame was sent or received by a connection; recorded frames are always exchanged by
+// client connections.
+type FrameDirection string
+
+const (
+	FrameSent     = FrameDirection("sent")
+	FrameReceived = FrameDirection("received")
+)
+
+// FrameRecorder records the frames exchanged by client connections. To enable it, set CqlClient.Recorder before
+// creating connections. Frames are recorded by the connection's read and write loops, after a frame is successfully
+// written or read, so implementations must be safe for concurrent use, and should return quickly.
+type FrameRecorder interface {
+	RecordFrame(conn *CqlClientConnection, direction FrameDirection, f *frame.Frame)
+}
+
+// RecordedFrame is a frame recorded by a recorder created with NewJSONRecorder.
+type RecordedFrame struct {
+	// Timestamp is the time at which the frame was recorded.
+	Timestamp time.Time `json:"timestamp"`
+	// Connection identifies the client connection that exchanged the frame.
+	Connection string `json:"connection"`
+	// Direction tells whether the frame was sent or received by the client.
+	Direction FrameDirection `json:"direction"`
+	// Summary is a human-readable description of the frame, for information only.
+	Summary string `json:"summary"`
+	// Data is the hex-encoded frame, never compressed, as encoded by a frame.Codec.
+	Data string `json:"data"`
+}
+
+// Frame decodes the recorded frame.
+func (r *RecordedFrame) Frame() (*frame.Frame, error) {
+	if data, err := hex.DecodeString(r.Data); err != nil {
+		return nil, fmt.Errorf("cannot decode recorded frame data: %w", err)
+	} else if f, err := recordingCodec.DecodeFrame(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("cannot decode recorded frame: %w", err)
+	} else {
+		return f, nil
+	}
+}
+
+var recordingCodec = frame.NewCodec()
+
+// encodeRecordedFrame encodes the given frame without compression, since a recording does not carry compression
+// settings.
+func encodeRecordedFrame(f *frame.Frame) ([]byte, error) {
+	if f.Header.Flags.Contains(primitive.HeaderFlagCompressed) {
+		f = f.DeepCopy()
+		f.Header.Flags = f.Header.Flags.Remove(primitive.HeaderFlagCompressed)
+	}
+	return frame.EncodeToBytes(recordingCodec, f)
+}
+
+type writerRecorder struct {
+	dest   io.Writer
+	format func(conn *CqlClientConnection, direction FrameDirection, f *frame.Frame, encoded []byte) ([]byte, error)
+	lock   sync.Mutex
+}
+
+func (r *writerRecorder) RecordFrame(conn *CqlClientConnection, direction FrameDirection, f *frame.Frame) {
+	encoded, err := encodeRecordedFrame(f)
+	if err == nil {
+		var record []byte
+		if record, err = r.format(conn, direction, f, encoded); err == nil {
+			r.lock.Lock()
+			_, err = r.dest.Write(record)
+			r.lock.Unlock()
+		}
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("%v: cannot record %v frame: %v", conn, direction, f)
+	}
+}
+
+// NewHexDumpRecorder returns a FrameRecorder that writes a human-readable description of each frame, followed by a
+// hex dump of its encoded form, to the given destination.
+func NewHexDumpRecorder(dest io.Writer) FrameRecorder {
+	return &writerRecorder{
+		dest: dest,
+		format: func(conn *CqlClientConnection, direction FrameDirection, f *frame.Frame, encoded []byte) ([]byte, error) {
+			return []byte(fmt.Sprintf(
+				"%v %v %v: %v\n%v",
+				time.Now().Format(time.RFC3339Nano),
+				conn,
+				direction,
+				f,
+				hex.Dump(encoded),
+			)), nil
+		},
+	}
+}
+
+// NewJSONRecorder returns a FrameRecorder that writes each frame to the given destination as a JSON-encoded
+// RecordedFrame, one per line. Recordings can be read back with ReadRecording, and replayed with a Replayer.
+func NewJSONRecorder(dest io.Writer) FrameRecorder {
+	return &writerRecorder{
+		dest: dest,
+		format: func(conn *CqlClientConnection, direction FrameDirection, f *frame.Frame, encoded []byte) ([]byte, error) {
+			record, err := json.Marshal(&RecordedFrame{
+				Timestamp:  time.Now(),
+				Connection: conn.String(),
+				Direction:  direction,
+				Summary:    f.String(),
+				Data:       hex.EncodeToString(encoded),
+			})
+			return append(record, '\n'), err
+		},
+	}
+}
+
+// ReadRecording reads a recording written by a recorder created with NewJSONRecorder.
+func ReadRecording(source io.Reader) ([]*RecordedFrame, error) {
+	var recording []*RecordedFrame
+	decoder := json.NewDecoder(source)
+	for decoder.More() {
+		record := &RecordedFrame{}
+		if err := decoder.Decode(record); err != nil {
+			return nil, fmt.Errorf("cannot read recorded frame %d: %w", len(recording), err)
+		}
+		recording = append(recording, record)
+	}
+	return recording, nil
+}
+
+// Replayer answers requests received by a CqlServer with the responses of a recording, turning the server into a
+// stand-in for the server that was recorded. Use Replayer.Handle as one of the server's request handlers.
+//
+// Each request sent in the recording forms an exchange with the responses received on the same connection and with
+// the same stream id, until the stream id is reused. When a request is received, the first exchange not yet replayed
+// whose recorded request matches it is replayed: its responses are sent with the stream id of the received request.
+// Recorded events are not replayed.
+type Replayer struct {
+	// Match tells whether a received request matches a recorded one. By default, requests match if they have the same
+	// protocol version and op code, and equal messages.
+	Match     func(recorded *frame.Frame, received *frame.Frame) bool
+	exchanges []*replayExchange
+	lock      sync.Mutex
+}
+
+type replayExchange struct {
+	request   *frame.Frame
+	responses []*frame.Frame
+	replayed  bool
+}
+
+// NewReplayer creates a new Replayer for the given recording.
+func NewReplayer(recording []*RecordedFrame) (*Replayer, error) {
+	replayer := &Replayer{Match: matchRecordedRequest}
+	type streamKey struct {
+		connection string
+		streamId   int16
+	}
+	pending := make(map[streamKey]*replayExchange)
+	for i, record := range recording {
+		f, err := record.Frame()
+		if err != nil {
+			return nil, fmt.Errorf("recorded frame %d: %w", i, err)
+		}
+		key := streamKey{record.Connection, f.Header.StreamId}
+		switch record.Direction {
+		case FrameSent:
+			exchange := &replayExchange{request: f}
+			replayer.exchanges = append(replayer.exchanges, exchange)
+			pending[key] = exchange
+		case FrameReceived:
+			if exchange, found := pending[key]; found {
+				exchange.responses = append(exchange.responses, f)
+			}
+		default:
+			return nil, fmt.Errorf("recorded frame %d: unknown direction: %v", i, record.Direction)
+		}
+	}
+	return replayer, nil
+}
+
+// Handle is a RequestHandler that replays the responses of the first exchange matching the request. If no exchange
+// matches, it returns nil, so that other handlers can handle the request.
+func (r *Replayer) Handle(request *frame.Frame, conn *CqlServerConnection, _ RequestHandlerContext) *frame.Frame {
+	responses := r.nextResponses(request)
+	if len(responses) == 0 {
+		log.Debug().Msgf("%v: no recorded response for request: %v", conn, request)
+		return nil
+	}
+	for _, response := range responses[:len(responses)-1] {
+		if err := conn.Send(replayedResponse(response, request)); err != nil {
+			log.Error().Err(err).Msgf("%v: cannot send replayed response: %v", conn, response)
+		}
+	}
+	return replayedResponse(responses[len(responses)-1], request)
+}
+
+// Remaining returns the number of recorded exchanges that were not replayed yet.
+func (r *Replayer) Remaining() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	remaining := 0
+	for _, exchange := range r.exchanges {
+		if !exchange.replayed {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+func (r *Replayer) nextResponses(request *frame.Frame) []*frame.Frame {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, exchange := range r.exchanges {
+		if !exchange.replayed && r.Match(exchange.request, request) {
+			exchange.replayed = true
+			return exchange.responses
+		}
+	}
+	return nil
+}
+
+func replayedResponse(recorded *frame.Frame, request *frame.Frame) *frame.Frame {
+	response := recorded.DeepCopy()
+	response.Header.StreamId = request.Header.StreamId
+	return response
+}
+
+func matchRecordedRequest(recorded *frame.Frame, received *frame.Frame) bool {
+	return recorded.Header.Version == received.Header.Version &&
+		recorded.Header.OpCode == received.Header.OpCode &&
+		reflect.DeepEqual(recorded.Body.Message, received.Body.Message)
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use, since recorders are invoked from the connections' loops.
+type lockedBuffer struct {
+	buf  bytes.Buffer
+	lock sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func recordSession(t *testing.T, handlers []client.RequestHandler, recorder client.FrameRecorder) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = handlers
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.Recorder = recorder
+	ctx, cancelFn := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+	testHeartbeat(t, clientConn)
+	testUseQuery(t, clientConn)
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestJSONRecorderAndReplayer(t *testing.T) {
+	dest := &lockedBuffer{}
+	recordSession(t, []client.RequestHandler{client.HeartbeatHandler, client.NewSetKeyspaceHandler(func(string) {})}, client.NewJSONRecorder(dest))
+
+	recording, err := client.ReadRecording(strings.NewReader(dest.String()))
+	require.NoError(t, err)
+	require.Len(t, recording, 202)
+	assert.Equal(t, client.FrameSent, recording[0].Direction)
+	assert.Equal(t, client.FrameReceived, recording[1].Direction)
+	request, err := recording[200].Frame()
+	require.NoError(t, err)
+	require.IsType(t, &message.Query{}, request.Body.Message)
+	assert.Equal(t, " USE \n ks1 ", request.Body.Message.(*message.Query).Query)
+	response, err := recording[201].Frame()
+	require.NoError(t, err)
+	assert.Equal(t, &message.SetKeyspaceResult{Keyspace: "ks1"}, response.Body.Message)
+	assert.Equal(t, request.Header.StreamId, response.Header.StreamId)
+
+	// replay the recording; requests that cannot be replayed are handled by the next handlers
+	replayer, err := client.NewReplayer(recording)
+	require.NoError(t, err)
+	assert.Equal(t, 101, replayer.Remaining())
+	var fallbackInvoked int32
+	fallback := func(request *frame.Frame, conn *client.CqlServerConnection, ctx client.RequestHandlerContext) *frame.Frame {
+		atomic.StoreInt32(&fallbackInvoked, 1)
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Overloaded{ErrorMessage: "not recorded"})
+	}
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{replayer.Handle, fallback}, nil)
+	testHeartbeat(t, clientConn)
+	testUseQuery(t, clientConn)
+	assert.Equal(t, 0, replayer.Remaining())
+	assert.Zero(t, atomic.LoadInt32(&fallbackInvoked))
+
+	// all exchanges were replayed: further requests are not handled by the replayer
+	unrecorded, err := clientConn.SendAndReceive(frame.NewFrame(
+		primitive.ProtocolVersion4,
+		client.ManagedStreamId,
+		&message.Options{},
+	))
+	require.NoError(t, err)
+	assert.IsType(t, &message.Overloaded{}, unrecorded.Body.Message)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&fallbackInvoked))
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestHexDumpRecorder(t *testing.T) {
+	dest := &lockedBuffer{}
+	recordSession(t, []client.RequestHandler{client.HeartbeatHandler, client.NewSetKeyspaceHandler(func(string) {})}, client.NewHexDumpRecorder(dest))
+	dump := dest.String()
+	assert.Equal(t, 101, strings.Count(dump, " sent: "))
+	assert.Equal(t, 101, strings.Count(dump, " received: "))
+	assert.Contains(t, dump, "OPTIONS")
+	// the USE query response
+	assert.Regexp(t, "received: \\{header: \\{response: true, .*opcode: OpCode RESULT \\[0x08\\]", dump)
+	assert.Regexp(t, "00000000  84 00 00 .. 08 00 00 00  09 00 00 00 03 00 03 6b  \\|\\.+k\\|", dump)
+}
@@ -0,0 +1,236 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+)
+
+// preparedStatementCache keeps track of the PREPARE requests that were successfully prepared on a connection, keyed by
+// the prepared query id returned by the server, so that they can be sent again when an EXECUTE request is rejected
+// with an Unprepared error. See CqlClient.AutoReprepare.
+type preparedStatementCache struct {
+	// pending are the PREPARE requests awaiting a response, keyed by stream id.
+	pending map[int16]*message.Prepare
+	// prepared are the PREPARE requests that were successfully prepared, keyed by prepared query id.
+	prepared map[string]*message.Prepare
+	lock     sync.Mutex
+}
+
+func newPreparedStatementCache() *preparedStatementCache {
+	return &preparedStatementCache{
+		pending:  make(map[int16]*message.Prepare),
+		prepared: make(map[string]*message.Prepare),
+	}
+}
+
+// onRequest must be invoked once the stream id of the outgoing request is known.
+func (c *preparedStatementCache) onRequest(f *frame.Frame) {
+	if prepare, ok := f.Body.Message.(*message.Prepare); ok {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		c.pending[f.Header.StreamId] = prepare.DeepCopy()
+	}
+}
+
+// onResponse must be invoked before the incoming response is delivered to its in-flight request.
+func (c *preparedStatementCache) onResponse(f *frame.Frame) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if prepare, found := c.pending[f.Header.StreamId]; found {
+		delete(c.pending, f.Header.StreamId)
+		if result, ok := f.Body.Message.(*message.PreparedResult); ok {
+			c.prepared[string(result.PreparedQueryId)] = prepare
+		}
+	}
+}
+
+func (c *preparedStatementCache) get(queryId []byte) *message.Prepare {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if prepare, found := c.prepared[string(queryId)]; found {
+		return prepare.DeepCopy()
+	}
+	return nil
+}
+
+// sendRequest sends the given frame like send, and if CqlClient.AutoReprepare is set and the frame is an EXECUTE
+// request, wraps the returned in-flight request so that an Unprepared error is handled transparently.
+func (c *CqlClientConnection) sendRequest(ctx context.Context, f *frame.Frame, timeout time.Duration) (InFlightRequest, error) {
+	if c.preparedStatements == nil || f == nil {
+		return c.send(ctx, f, timeout, nil)
+	}
+	if _, ok := f.Body.Message.(*message.Execute); !ok {
+		return c.send(ctx, f, timeout, nil)
+	}
+	managedStreamId := f.Header.StreamId == ManagedStreamId
+	// the original frame is copied before it is sent, since its stream id may be modified
+	retry := f.DeepCopy()
+	if inFlight, err := c.send(ctx, f, timeout, nil); err != nil {
+		return nil, err
+	} else {
+		if !managedStreamId {
+			retry.Header.StreamId = inFlight.StreamId()
+		}
+		return c.newRepreparingRequest(ctx, inFlight, retry, timeout), nil
+	}
+}
+
+// repreparingRequest is an in-flight EXECUTE request that, upon receiving an Unprepared error for a query id found in
+// the connection's prepared statement cache, sends the original PREPARE request again, then retries the EXECUTE request
+// once; the responses to the retried request are then delivered in place of the Unprepared error. If the statement
+// cannot be prepared again, the Unprepared error is delivered.
+type repreparingRequest struct {
+	streamId int16
+	incoming chan *frame.Frame
+	err      error
+	done     bool
+	lock     sync.RWMutex
+	// connectionDone is closed when the connection is closed, to avoid blocking forever on undelivered frames.
+	connectionDone <-chan struct{}
+}
+
+func (c *CqlClientConnection) newRepreparingRequest(
+	ctx context.Context,
+	inFlight InFlightRequest,
+	retry *frame.Frame,
+	timeout time.Duration,
+) *repreparingRequest {
+	r := &repreparingRequest{
+		streamId:       inFlight.StreamId(),
+		incoming:       make(chan *frame.Frame, c.inFlightHandler.maxPending),
+		connectionDone: c.ctx.Done(),
+	}
+	go func() {
+		first := true
+		for incoming := range inFlight.Incoming() {
+			if first {
+				first = false
+				if unprepared, ok := incoming.Body.Message.(*message.Unprepared); ok {
+					if retried := c.reprepareAndRetry(ctx, retry, unprepared, timeout); retried != nil {
+						r.forward(retried)
+						return
+					}
+				}
+			}
+			if !r.deliver(incoming) {
+				return
+			}
+		}
+		r.close(inFlight.Err())
+	}()
+	return r
+}
+
+// reprepareAndRetry prepares the statement again, then retries the given EXECUTE request; it returns nil if the
+// statement was never prepared on this connection or could not be prepared again.
+func (c *CqlClientConnection) reprepareAndRetry(
+	ctx context.Context,
+	retry *frame.Frame,
+	unprepared *message.Unprepared,
+	timeout time.Duration,
+) InFlightRequest {
+	prepare := c.preparedStatements.get(unprepared.Id)
+	if prepare == nil {
+		log.Debug().Msgf("%v: cannot reprepare unknown query id: %x", c, unprepared.Id)
+		return nil
+	}
+	log.Debug().Msgf("%v: reprepare: re-sending PREPARE for query id: %x", c, unprepared.Id)
+	response, err := c.SendAndReceiveContext(ctx, frame.NewFrame(retry.Header.Version, retry.Header.StreamId, prepare))
+	if err != nil {
+		log.Error().Err(err).Msgf("%v: reprepare failed for query id: %x", c, unprepared.Id)
+		return nil
+	}
+	result, ok := response.Body.Message.(*message.PreparedResult)
+	if !ok {
+		log.Error().Msgf("%v: reprepare failed for query id: %x, expected PREPARED, got %v", c, unprepared.Id, response.Body.Message)
+		return nil
+	}
+	execute := retry.Body.Message.(*message.Execute)
+	execute.QueryId = result.PreparedQueryId
+	if len(result.ResultMetadataId) > 0 {
+		execute.ResultMetadataId = result.ResultMetadataId
+	}
+	log.Debug().Msgf("%v: reprepare: retrying EXECUTE for query id: %x", c, unprepared.Id)
+	inFlight, err := c.send(ctx, retry, timeout, nil)
+	if err != nil {
+		log.Error().Err(err).Msgf("%v: reprepare: retry failed for query id: %x", c, unprepared.Id)
+		return nil
+	}
+	return inFlight
+}
+
+func (r *repreparingRequest) forward(inFlight InFlightRequest) {
+	r.lock.Lock()
+	r.streamId = inFlight.StreamId()
+	r.lock.Unlock()
+	for incoming := range inFlight.Incoming() {
+		if !r.deliver(incoming) {
+			return
+		}
+	}
+	r.close(inFlight.Err())
+}
+
+// deliver delivers the given frame, and returns false if the connection was closed before it could be delivered.
+func (r *repreparingRequest) deliver(incoming *frame.Frame) bool {
+	select {
+	case r.incoming <- incoming:
+		return true
+	case <-r.connectionDone:
+		r.close(fmt.Errorf("[stream id %d]: connection closed", r.StreamId()))
+		return false
+	}
+}
+
+func (r *repreparingRequest) close(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.done {
+		r.done = true
+		r.err = err
+		close(r.incoming)
+	}
+}
+
+func (r *repreparingRequest) StreamId() int16 {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.streamId
+}
+
+func (r *repreparingRequest) Incoming() <-chan *frame.Frame {
+	return r.incoming
+}
+
+func (r *repreparingRequest) IsDone() bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.done
+}
+
+func (r *repreparingRequest) Err() error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.err
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCqlClient_AutoReprepare(t *testing.T) {
+	query := "SELECT v FROM ks.t1"
+	columns := func(name string) *message.RowsMetadata {
+		return &message.RowsMetadata{
+			ColumnCount: 1,
+			Columns:     []*message.ColumnMetadata{{Keyspace: "ks", Table: "t1", Name: name, Type: datatype.Varchar}},
+		}
+	}
+	rows := func(*message.QueryOptions) message.RowSet {
+		return message.RowSet{message.Row{message.Column("v1")}}
+	}
+	for _, version := range []primitive.ProtocolVersion{primitive.ProtocolVersion4, primitive.ProtocolVersion5} {
+		for _, streamId := range []int16{client.ManagedStreamId, 42} {
+			for _, autoReprepare := range []bool{false, true} {
+				name := fmt.Sprintf("%v stream id %d auto reprepare %v", version, streamId, autoReprepare)
+				t.Run(name, func(t *testing.T) {
+					simulator := client.NewPreparedStatementSimulator(query, &message.VariablesMetadata{}, columns("v"), rows)
+					server := client.NewCqlServer("127.0.0.1:9043", nil)
+					server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, simulator.Handler()}
+					ctx, cancelFn := context.WithCancel(context.Background())
+					defer cancelFn()
+					require.NoError(t, server.Start(ctx))
+					clt := client.NewCqlClient("127.0.0.1:9043", nil)
+					clt.AutoReprepare = autoReprepare
+					clientConn, err := clt.ConnectAndInit(ctx, version, streamId)
+					require.NoError(t, err)
+
+					// statements that were not prepared on this connection are never reprepared
+					execute := &message.Execute{QueryId: []byte(query), Options: &message.QueryOptions{}}
+					if version.SupportsResultMetadataId() {
+						execute.ResultMetadataId = []byte{0xca, 0xfe}
+					}
+					response, err := clientConn.SendAndReceive(frame.NewFrame(version, streamId, execute))
+					require.NoError(t, err)
+					require.IsType(t, &message.Unprepared{}, response.Body.Message)
+
+					response, err = clientConn.SendAndReceive(frame.NewFrame(version, streamId, &message.Prepare{Query: query}))
+					require.NoError(t, err)
+					prepared := response.Body.Message.(*message.PreparedResult)
+					execute.ResultMetadataId = prepared.ResultMetadataId
+					response, err = clientConn.SendAndReceive(frame.NewFrame(version, streamId, execute))
+					require.NoError(t, err)
+					require.IsType(t, &message.RowsResult{}, response.Body.Message)
+
+					// the statement is evicted from the server
+					newResultMetadataId := simulator.ChangeResultMetadata(columns("v2"), true)
+					response, err = clientConn.SendAndReceive(frame.NewFrame(version, streamId, execute))
+					require.NoError(t, err)
+					if !autoReprepare {
+						require.IsType(t, &message.Unprepared{}, response.Body.Message)
+					} else {
+						require.IsType(t, &message.RowsResult{}, response.Body.Message)
+						result := response.Body.Message.(*message.RowsResult)
+						assert.Equal(t, message.RowSet{message.Row{message.Column("v1")}}, result.Data)
+						if version.SupportsResultMetadataId() {
+							// the retried EXECUTE carried the new result metadata id
+							assert.Nil(t, result.Metadata.NewResultMetadataId)
+							assert.Equal(t, newResultMetadataId, simulator.ResultMetadataId())
+						}
+					}
+
+					cancelFn()
+					checkClosed(t, clientConn, server)
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+)
+
+// RequestLogEntry is an entry in a RequestLog.
+type RequestLogEntry struct {
+	// Timestamp is the time at which the request was received.
+	Timestamp time.Time
+	// Connection is the server connection that received the request.
+	Connection *CqlServerConnection
+	// Request is the received request frame.
+	Request *frame.Frame
+	// Queries contains the normalized query strings found in the request: one for QUERY and PREPARE requests, one
+	// per child statement with a query string for BATCH requests, and none for other requests. See NormalizeQuery.
+	Queries []string
+}
+
+// RequestLog is an in-memory log of the requests received by a CqlServer. To enable it, set CqlServer.RequestLog
+// before starting the server. A RequestLog is safe for concurrent use.
+type RequestLog struct {
+	entries []*RequestLogEntry
+	// notify is closed and replaced every time a new entry is added.
+	notify chan struct{}
+	lock   sync.Mutex
+}
+
+// NewRequestLog creates a new, empty RequestLog.
+func NewRequestLog() *RequestLog {
+	return &RequestLog{notify: make(chan struct{})}
+}
+
+func (l *RequestLog) add(request *frame.Frame, conn *CqlServerConnection) {
+	entry := &RequestLogEntry{
+		Timestamp:  time.Now(),
+		Connection: conn,
+		Request:    request,
+		Queries:    requestQueries(request),
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.entries = append(l.entries, entry)
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
+
+// Entries returns a snapshot of all the entries currently in the log, in the order they were received.
+func (l *RequestLog) Entries() []*RequestLogEntry {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	entries := make([]*RequestLogEntry, len(l.entries))
+	copy(entries, l.entries)
+	return entries
+}
+
+// Queries returns all the normalized query strings currently in the log, in the order they were received.
+func (l *RequestLog) Queries() []string {
+	var queries []string
+	for _, entry := range l.Entries() {
+		queries = append(queries, entry.Queries...)
+	}
+	return queries
+}
+
+// Clear removes all entries from the log.
+func (l *RequestLog) Clear() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.entries = nil
+}
+
+// Await waits until an entry satisfying the given predicate is present in the log, and returns it. Entries already
+// in the log are considered. If no such entry is found within the given timeout, an error is returned.
+func (l *RequestLog) Await(predicate func(entry *RequestLogEntry) bool, timeout time.Duration) (*RequestLogEntry, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	scanned := 0
+	for {
+		l.lock.Lock()
+		if scanned > len(l.entries) {
+			// log was cleared
+			scanned = 0
+		}
+		for _, entry := range l.entries[scanned:] {
+			if predicate(entry) {
+				l.lock.Unlock()
+				return entry, nil
+			}
+		}
+		scanned = len(l.entries)
+		notify := l.notify
+		l.lock.Unlock()
+		select {
+		case <-notify:
+		case <-timer.C:
+			return nil, fmt.Errorf("no matching request received within %v", timeout)
+		}
+	}
+}
+
+// AwaitQuery waits until a request containing a query that matches the given regular expression is present in the
+// log, and returns it. The expression is matched against normalized query strings, see NormalizeQuery. This function
+// panics if the expression cannot be compiled.
+func (l *RequestLog) AwaitQuery(expr string, timeout time.Duration) (*RequestLogEntry, error) {
+	pattern := regexp.MustCompile(expr)
+	return l.Await(func(entry *RequestLogEntry) bool {
+		for _, query := range entry.Queries {
+			if pattern.MatchString(query) {
+				return true
+			}
+		}
+		return false
+	}, timeout)
+}
+
+func requestQueries(request *frame.Frame) []string {
+	switch msg := request.Body.Message.(type) {
+	case *message.Query:
+		return []string{NormalizeQuery(msg.Query)}
+	case *message.Prepare:
+		return []string{NormalizeQuery(msg.Query)}
+	case *message.Batch:
+		var queries []string
+		for _, child := range msg.Children {
+			if child.Query != "" {
+				queries = append(queries, NormalizeQuery(child.Query))
+			}
+		}
+		return queries
+	}
+	return nil
+}
+
+// NormalizeQuery returns a canonical form of the given CQL query string, suitable for comparisons. Leading and
+// trailing whitespace and trailing semicolons are removed, and consecutive whitespace characters are replaced with a
+// single space. Whitespace is also removed after opening parentheses and before closing parentheses and commas, and a
+// single space is inserted after commas. Finally, named bind markers such as ":name" are replaced with positional bind
+// markers "?". String literals and quoted identifiers are left untouched.
+func NormalizeQuery(query string) string {
+	sb := &strings.Builder{}
+	pendingSpace := false
+	runes := []rune(strings.TrimSpace(query))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'' || r == '"':
+			flushSpace(sb, &pendingSpace)
+			end := endOfQuoted(runes, i)
+			sb.WriteString(string(runes[i:end]))
+			i = end - 1
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			pendingSpace = sb.Len() > 0
+		case r == ',':
+			sb.WriteRune(',')
+			pendingSpace = true
+		case r == ')':
+			pendingSpace = false
+			sb.WriteRune(')')
+		case r == '(':
+			flushSpace(sb, &pendingSpace)
+			sb.WriteRune('(')
+			pendingSpace = false
+			i = skipSpaces(runes, i)
+		case r == ':' && i+1 < len(runes) && isIdentifierStart(runes[i+1]):
+			flushSpace(sb, &pendingSpace)
+			sb.WriteRune('?')
+			for i+1 < len(runes) && isIdentifierPart(runes[i+1]) {
+				i++
+			}
+		default:
+			flushSpace(sb, &pendingSpace)
+			sb.WriteRune(r)
+		}
+	}
+	return strings.TrimRight(strings.TrimSpace(sb.String()), "; ")
+}
+
+func flushSpace(sb *strings.Builder, pendingSpace *bool) {
+	if *pendingSpace {
+		sb.WriteRune(' ')
+		*pendingSpace = false
+	}
+}
+
+// endOfQuoted returns the index following the closing quote of the quoted string starting at index start. Doubled
+// quotes are treated as escaped quotes.
+func endOfQuoted(runes []rune, start int) int {
+	quote := runes[start]
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == quote {
+			if i+1 < len(runes) && runes[i+1] == quote {
+				i++
+			} else {
+				return i + 1
+			}
+		}
+	}
+	return len(runes)
+}
+
+func skipSpaces(runes []rune, i int) int {
+	for i+1 < len(runes) && (runes[i+1] == ' ' || runes[i+1] == '\t' || runes[i+1] == '\n' || runes[i+1] == '\r') {
+		i++
+	}
+	return i
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestRequestLog(t *testing.T) {
+
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HeartbeatHandler}
+	server.RequestLog = client.NewRequestLog()
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+
+	testHeartbeat(t, clientConn)
+	entries := server.RequestLog.Entries()
+	require.Len(t, entries, 100)
+	assert.Equal(t, primitive.OpCodeOptions, entries[0].Request.Header.OpCode)
+	assert.Empty(t, entries[0].Queries)
+	assert.NotNil(t, entries[0].Connection)
+	server.RequestLog.Clear()
+	assert.Empty(t, server.RequestLog.Entries())
+
+	// query sent later while awaiting
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{
+			Query: "SELECT *\n  FROM ks1.table1\tWHERE pk = :pk ;",
+		}))
+	}()
+	entry, err := server.RequestLog.AwaitQuery("^SELECT \\* FROM ks1\\.table1 WHERE pk = \\?$", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SELECT * FROM ks1.table1 WHERE pk = ?"}, entry.Queries)
+
+	// batch already received before awaiting
+	_, err = clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Batch{
+		Children: []*message.BatchChild{
+			{Query: "INSERT INTO ks1.table1 (pk,v) VALUES (?,?)"},
+			{Id: []byte{1, 2, 3}},
+		},
+	}))
+	require.NoError(t, err)
+	entry, err = server.RequestLog.AwaitQuery("INSERT", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, primitive.OpCodeBatch, entry.Request.Header.OpCode)
+	assert.Equal(t, []string{
+		"SELECT * FROM ks1.table1 WHERE pk = ?",
+		"INSERT INTO ks1.table1 (pk, v) VALUES (?, ?)",
+	}, server.RequestLog.Queries())
+
+	// no match
+	_, err = server.RequestLog.AwaitQuery("DELETE", 50*time.Millisecond)
+	assert.EqualError(t, err, "no matching request received within 50ms")
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"already normalized", "SELECT * FROM t WHERE k = ?", "SELECT * FROM t WHERE k = ?"},
+		{"whitespace", "  SELECT *\n\tFROM   t  ", "SELECT * FROM t"},
+		{"trailing semicolons", "SELECT * FROM t ; ;", "SELECT * FROM t"},
+		{"parentheses and commas", "INSERT INTO t ( a ,b,  c ) VALUES ( ?,? , ? )", "INSERT INTO t (a, b, c) VALUES (?, ?, ?)"},
+		{"named bind markers", "UPDATE t SET v = :v1 WHERE k = :k_1", "UPDATE t SET v = ? WHERE k = ?"},
+		{"string literals", "SELECT * FROM t WHERE v = '  a  :b ,c '' d'", "SELECT * FROM t WHERE v = '  a  :b ,c '' d'"},
+		{"quoted identifiers", "SELECT \"My  Col\" FROM t", "SELECT \"My  Col\" FROM t"},
+		{"map literal", "INSERT INTO t (k, m) VALUES (1, {'a' : 1})", "INSERT INTO t (k, m) VALUES (1, {'a' : 1})"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, client.NormalizeQuery(tt.input))
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/md5"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+)
+
+// PreparedStatementSimulator emulates a prepared statement whose result set metadata can change over time, for
+// example because the underlying table was altered. It is meant to test how drivers refresh their cached result
+// metadata, and behaves like NewPreparedStatementHandler, with a few additions.
+//
+// When the protocol version supports result metadata ids (protocol version 5 and DSE protocol version 2), PREPARE
+// responses include the current result metadata id. If an EXECUTE request carries a stale result metadata id, the Rows
+// RESULT response includes the full, current metadata and its new id (that is, the METADATA_CHANGED flag is set), even
+// if the request asked to skip metadata.
+//
+// With older protocol versions, when the EXECUTE request asks to skip metadata, the Rows RESULT response never
+// includes metadata, even if it changed since the statement was prepared, as a real server would do.
+//
+// When the metadata change also evicts the statement, subsequent EXECUTE requests are rejected with an Unprepared ERROR
+// response until the statement is prepared again.
+//
+// Use Handler to obtain a RequestHandler for this simulator, and ChangeResultMetadata to simulate schema changes. It
+// is safe to use a PreparedStatementSimulator from many goroutines.
+type PreparedStatementSimulator struct {
+	query            string
+	variables        *message.VariablesMetadata
+	rows             func(options *message.QueryOptions) message.RowSet
+	lock             sync.Mutex
+	prepared         bool
+	columns          *message.RowsMetadata
+	resultMetadataId message.ResultMetadataId
+}
+
+// NewPreparedStatementSimulator creates a new PreparedStatementSimulator for the given query string. The prepared id
+// is simply the query string bytes; the result metadata id is computed from the given columns. The rows factory
+// function is invoked for each EXECUTE request, as in NewPreparedStatementHandler.
+func NewPreparedStatementSimulator(
+	query string,
+	variables *message.VariablesMetadata,
+	columns *message.RowsMetadata,
+	rows func(options *message.QueryOptions) message.RowSet,
+) *PreparedStatementSimulator {
+	return &PreparedStatementSimulator{
+		query:            query,
+		variables:        variables,
+		rows:             rows,
+		columns:          columns,
+		resultMetadataId: computeResultMetadataId(columns),
+	}
+}
+
+// ResultMetadataId returns the current result metadata id.
+func (s *PreparedStatementSimulator) ResultMetadataId() message.ResultMetadataId {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append(message.ResultMetadataId(nil), s.resultMetadataId...)
+}
+
+// ChangeResultMetadata replaces the result set metadata with the given columns, and returns the new result metadata
+// id. If evict is true, the statement is also evicted from the simulated server cache, and subsequent EXECUTE requests
+// will be rejected until the statement is prepared again; otherwise, subsequent EXECUTE requests carrying a stale
+// result metadata id will receive the new metadata and its id.
+func (s *PreparedStatementSimulator) ChangeResultMetadata(columns *message.RowsMetadata, evict bool) message.ResultMetadataId {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.columns = columns
+	s.resultMetadataId = computeResultMetadataId(columns)
+	if evict {
+		s.prepared = false
+	}
+	return append(message.ResultMetadataId(nil), s.resultMetadataId...)
+}
+
+// Handler returns a RequestHandler that intercepts PREPARE and EXECUTE requests targeting this simulator's query
+// string.
+func (s *PreparedStatementSimulator) Handler() RequestHandler {
+	return func(request *frame.Frame, conn *CqlServerConnection, ctx RequestHandlerContext) (response *frame.Frame) {
+		version := request.Header.Version
+		id := request.Header.StreamId
+		switch msg := request.Body.Message.(type) {
+		case *message.Prepare:
+			if msg.Query == s.query {
+				log.Debug().Msgf("%v: [prepared statement simulator]: intercepted PREPARE", conn)
+				response = frame.NewFrame(version, id, s.prepare(version.SupportsResultMetadataId()))
+				log.Debug().Msgf("%v: [prepared statement simulator]: returning %v", conn, response)
+			}
+		case *message.Execute:
+			if string(msg.QueryId) == s.query {
+				log.Debug().Msgf("%v: [prepared statement simulator]: intercepted EXECUTE", conn)
+				response = frame.NewFrame(version, id, s.execute(msg, version.SupportsResultMetadataId()))
+				log.Debug().Msgf("%v: [prepared statement simulator]: returning %v", conn, response)
+			}
+		}
+		return
+	}
+}
+
+func (s *PreparedStatementSimulator) prepare(supportsResultMetadataId bool) message.Message {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.prepared = true
+	result := &message.PreparedResult{
+		PreparedQueryId:   []byte(s.query),
+		VariablesMetadata: s.variables,
+		ResultMetadata:    s.columns,
+	}
+	if supportsResultMetadataId {
+		result.ResultMetadataId = s.resultMetadataId
+	}
+	return result
+}
+
+func (s *PreparedStatementSimulator) execute(msg *message.Execute, supportsResultMetadataId bool) message.Message {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.prepared {
+		return &message.Unprepared{
+			ErrorMessage: "Unprepared query: " + s.query,
+			Id:           []byte(s.query),
+		}
+	}
+	skipMetadata := msg.Options != nil && msg.Options.SkipMetadata
+	metadata := s.columns.DeepCopy()
+	if metadata == nil {
+		metadata = &message.RowsMetadata{}
+	}
+	if supportsResultMetadataId && string(msg.ResultMetadataId) != string(s.resultMetadataId) {
+		metadata.NewResultMetadataId = s.resultMetadataId
+		skipMetadata = false
+	}
+	if skipMetadata {
+		metadata.Columns = nil
+	}
+	return &message.RowsResult{
+		Metadata: metadata,
+		Data:     s.rows(msg.Options),
+	}
+}
+
+// computeResultMetadataId computes a digest of the given columns, similar to what Cassandra does.
+func computeResultMetadataId(columns *message.RowsMetadata) message.ResultMetadataId {
+	digest := md5.New()
+	if columns != nil {
+		for _, column := range columns.Columns {
+			_, _ = fmt.Fprintf(digest, "%s.%s.%s:%s;", column.Keyspace, column.Table, column.Name, column.Type.AsCql())
+		}
+	}
+	return digest.Sum(nil)
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+const simulatedQuery = "SELECT * FROM ks.t1"
+
+var simulatedColumnsV1 = &message.RowsMetadata{
+	ColumnCount: 1,
+	Columns: []*message.ColumnMetadata{
+		{Keyspace: "ks", Table: "t1", Name: "v1", Index: 0, Type: datatype.Varchar},
+	},
+}
+
+var simulatedColumnsV2 = &message.RowsMetadata{
+	ColumnCount: 2,
+	Columns: []*message.ColumnMetadata{
+		{Keyspace: "ks", Table: "t1", Name: "v1", Index: 0, Type: datatype.Varchar},
+		{Keyspace: "ks", Table: "t1", Name: "v2", Index: 0, Type: datatype.Int},
+	},
+}
+
+func newSimulator() *client.PreparedStatementSimulator {
+	return client.NewPreparedStatementSimulator(
+		simulatedQuery,
+		&message.VariablesMetadata{},
+		simulatedColumnsV1,
+		func(options *message.QueryOptions) message.RowSet { return message.RowSet{} },
+	)
+}
+
+func TestPreparedStatementSimulator_MetadataChanged(t *testing.T) {
+	for _, version := range []primitive.ProtocolVersion{primitive.ProtocolVersion5, primitive.ProtocolVersionDse2} {
+		t.Run(version.String(), func(t *testing.T) {
+			simulator := newSimulator()
+			server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{simulator.Handler()}, nil)
+			defer cancelFn()
+
+			prepared := simulatePrepare(t, clientConn, version)
+			oldId := prepared.ResultMetadataId
+			require.NotEmpty(t, oldId)
+			assert.Equal(t, simulator.ResultMetadataId(), oldId)
+			assert.Equal(t, simulatedColumnsV1, prepared.ResultMetadata)
+
+			// up-to-date id, metadata skipped
+			rows := simulateExecute(t, clientConn, version, oldId, true).(*message.RowsResult)
+			assert.Nil(t, rows.Metadata.NewResultMetadataId)
+			assert.Nil(t, rows.Metadata.Columns)
+
+			// schema change: stale id gets the new metadata and its id
+			newId := simulator.ChangeResultMetadata(simulatedColumnsV2, false)
+			assert.NotEqual(t, oldId, newId)
+			rows = simulateExecute(t, clientConn, version, oldId, true).(*message.RowsResult)
+			assert.Equal(t, newId, rows.Metadata.NewResultMetadataId)
+			assert.Equal(t, simulatedColumnsV2.Columns, rows.Metadata.Columns)
+
+			// up-to-date id again
+			rows = simulateExecute(t, clientConn, version, newId, true).(*message.RowsResult)
+			assert.Nil(t, rows.Metadata.NewResultMetadataId)
+			assert.Nil(t, rows.Metadata.Columns)
+
+			// schema change with eviction: statement must be prepared again
+			newerId := simulator.ChangeResultMetadata(simulatedColumnsV1, true)
+			assert.Equal(t, oldId, newerId)
+			unprepared := simulateExecute(t, clientConn, version, newId, true).(*message.Unprepared)
+			assert.Equal(t, message.PreparedId(simulatedQuery), unprepared.Id)
+			prepared = simulatePrepare(t, clientConn, version)
+			assert.Equal(t, newerId, prepared.ResultMetadataId)
+			rows = simulateExecute(t, clientConn, version, newerId, false).(*message.RowsResult)
+			assert.Nil(t, rows.Metadata.NewResultMetadataId)
+			assert.Equal(t, simulatedColumnsV1.Columns, rows.Metadata.Columns)
+
+			cancelFn()
+			checkClosed(t, clientConn, server)
+		})
+	}
+}
+
+func TestPreparedStatementSimulator_LegacyVersion(t *testing.T) {
+	simulator := newSimulator()
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{simulator.Handler()}, nil)
+	defer cancelFn()
+
+	prepared := simulatePrepare(t, clientConn, primitive.ProtocolVersion4)
+	assert.Nil(t, prepared.ResultMetadataId)
+	simulator.ChangeResultMetadata(simulatedColumnsV2, false)
+
+	// metadata changes are not detected when skipping metadata
+	rows := simulateExecute(t, clientConn, primitive.ProtocolVersion4, nil, true).(*message.RowsResult)
+	assert.Nil(t, rows.Metadata.NewResultMetadataId)
+	assert.Nil(t, rows.Metadata.Columns)
+	assert.Equal(t, int32(2), rows.Metadata.ColumnCount)
+
+	rows = simulateExecute(t, clientConn, primitive.ProtocolVersion4, nil, false).(*message.RowsResult)
+	assert.Equal(t, simulatedColumnsV2.Columns, rows.Metadata.Columns)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func simulatePrepare(
+	t *testing.T,
+	clientConn *client.CqlClientConnection,
+	version primitive.ProtocolVersion,
+) *message.PreparedResult {
+	prepare := frame.NewFrame(version, client.ManagedStreamId, &message.Prepare{Query: simulatedQuery})
+	response, err := clientConn.SendAndReceive(prepare)
+	require.NoError(t, err)
+	require.IsType(t, &message.PreparedResult{}, response.Body.Message)
+	return response.Body.Message.(*message.PreparedResult)
+}
+
+func simulateExecute(
+	t *testing.T,
+	clientConn *client.CqlClientConnection,
+	version primitive.ProtocolVersion,
+	resultMetadataId []byte,
+	skipMetadata bool,
+) message.Message {
+	execute := frame.NewFrame(version, client.ManagedStreamId, &message.Execute{
+		QueryId:          []byte(simulatedQuery),
+		ResultMetadataId: resultMetadataId,
+		Options:          &message.QueryOptions{SkipMetadata: skipMetadata},
+	})
+	response, err := clientConn.SendAndReceive(execute)
+	require.NoError(t, err)
+	require.NotNil(t, response)
+	return response.Body.Message
+}
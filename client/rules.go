@@ -0,0 +1,289 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// RequestMatcher is a predicate on incoming request frames. Matchers are used to determine whether a Rule applies to a
+// given request.
+type RequestMatcher func(request *frame.Frame) bool
+
+// MatchOpCode returns a RequestMatcher that matches requests having any of the given opcodes.
+func MatchOpCode(opCodes ...primitive.OpCode) RequestMatcher {
+	return func(request *frame.Frame) bool {
+		for _, opCode := range opCodes {
+			if request.Header.OpCode == opCode {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchVersion returns a RequestMatcher that matches requests encoded with any of the given protocol versions.
+func MatchVersion(versions ...primitive.ProtocolVersion) RequestMatcher {
+	return func(request *frame.Frame) bool {
+		for _, version := range versions {
+			if request.Header.Version == version {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchQuery returns a RequestMatcher that matches QUERY and PREPARE requests whose query string matches the given
+// regular expression, as well as BATCH requests containing at least one such query string. This function panics if
+// the expression cannot be compiled.
+func MatchQuery(expr string) RequestMatcher {
+	pattern := regexp.MustCompile(expr)
+	return func(request *frame.Frame) bool {
+		switch msg := request.Body.Message.(type) {
+		case *message.Query:
+			return pattern.MatchString(msg.Query)
+		case *message.Prepare:
+			return pattern.MatchString(msg.Query)
+		case *message.Batch:
+			for _, child := range msg.Children {
+				if child.Query != "" && pattern.MatchString(child.Query) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// MatchPositionalValues returns a RequestMatcher that matches QUERY and EXECUTE requests whose positional values are
+// exactly the given ones.
+func MatchPositionalValues(values ...*primitive.Value) RequestMatcher {
+	return func(request *frame.Frame) bool {
+		options := requestOptions(request)
+		if options == nil || len(options.PositionalValues) != len(values) {
+			return false
+		}
+		for i, value := range values {
+			if !valuesEqual(value, options.PositionalValues[i]) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchNamedValue returns a RequestMatcher that matches QUERY and EXECUTE requests having a named value with the
+// given name and contents.
+func MatchNamedValue(name string, value *primitive.Value) RequestMatcher {
+	return func(request *frame.Frame) bool {
+		options := requestOptions(request)
+		if options == nil {
+			return false
+		}
+		actual, found := options.NamedValues[name]
+		return found && valuesEqual(value, actual)
+	}
+}
+
+func requestOptions(request *frame.Frame) *message.QueryOptions {
+	switch msg := request.Body.Message.(type) {
+	case *message.Query:
+		return msg.Options
+	case *message.Execute:
+		return msg.Options
+	}
+	return nil
+}
+
+func valuesEqual(v1 *primitive.Value, v2 *primitive.Value) bool {
+	if v1 == nil || v2 == nil {
+		return v1 == v2
+	}
+	return v1.Type == v2.Type && bytes.Equal(v1.Contents, v2.Contents)
+}
+
+// RuleAction produces the response to a request matched by a Rule. Actions are allowed to block, e.g. to simulate a
+// slow server; they should however return nil promptly when the connection is closed.
+// If an action returns nil, no response is sent back for the request.
+type RuleAction func(request *frame.Frame, conn *CqlServerConnection) *frame.Frame
+
+// RespondWith returns a RuleAction that responds with the given message, using the request's protocol version and
+// stream id. Error messages such as message.WriteTimeout or message.Overloaded can be used to inject errors.
+func RespondWith(msg message.Message) RuleAction {
+	return func(request *frame.Frame, _ *CqlServerConnection) *frame.Frame {
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, msg)
+	}
+}
+
+// RespondAfter returns a RuleAction that responds with the given message after the given delay.
+func RespondAfter(delay time.Duration, msg message.Message) RuleAction {
+	respond := RespondWith(msg)
+	return func(request *frame.Frame, conn *CqlServerConnection) *frame.Frame {
+		select {
+		case <-time.After(delay):
+			return respond(request, conn)
+		case <-conn.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NoResponse is a RuleAction that never responds to the request, effectively simulating a server-side timeout. The
+// request remains unanswered until the connection is closed.
+func NoResponse(_ *frame.Frame, conn *CqlServerConnection) *frame.Frame {
+	<-conn.ctx.Done()
+	return nil
+}
+
+// Rule is a primed response for requests satisfying a set of matchers. A Rule holds a sequence of actions: the first
+// matched request triggers the first action, the second matched request the second action, and so on; once the
+// sequence is exhausted, the last action is repeated for all subsequent matches. For example, the following rule
+// simulates a timeout for the first matching query, then succeeds:
+//
+//  rule := NewRule(MatchOpCode(primitive.OpCodeQuery), MatchQuery("^SELECT .* FROM ks\\.t1")).
+//      ThenNoResponse().
+//      ThenRespond(&message.VoidResult{})
+//
+// A Rule without matchers matches all requests. A Rule without actions never matches.
+type Rule struct {
+	matchers []RequestMatcher
+	actions  []RuleAction
+	matches  int
+	lock     sync.Mutex
+}
+
+// NewRule creates a new Rule for requests satisfying all the given matchers.
+func NewRule(matchers ...RequestMatcher) *Rule {
+	return &Rule{matchers: matchers}
+}
+
+// Then appends the given actions to this rule's sequence of actions.
+func (r *Rule) Then(actions ...RuleAction) *Rule {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.actions = append(r.actions, actions...)
+	return r
+}
+
+// ThenRespond appends an action responding with the given message to this rule's sequence of actions.
+func (r *Rule) ThenRespond(msg message.Message) *Rule {
+	return r.Then(RespondWith(msg))
+}
+
+// ThenRespondAfter appends an action responding with the given message after the given delay to this rule's sequence
+// of actions.
+func (r *Rule) ThenRespondAfter(delay time.Duration, msg message.Message) *Rule {
+	return r.Then(RespondAfter(delay, msg))
+}
+
+// ThenNoResponse appends an action that never responds to this rule's sequence of actions.
+func (r *Rule) ThenNoResponse() *Rule {
+	return r.Then(NoResponse)
+}
+
+// MatchCount returns the number of requests matched by this rule so far.
+func (r *Rule) MatchCount() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.matches
+}
+
+// match checks whether the request satisfies this rule and if so, returns the action to apply.
+func (r *Rule) match(request *frame.Frame) RuleAction {
+	for _, matcher := range r.matchers {
+		if !matcher(request) {
+			return nil
+		}
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.actions) == 0 {
+		return nil
+	}
+	action := r.actions[len(r.actions)-1]
+	if r.matches < len(r.actions) {
+		action = r.actions[r.matches]
+	}
+	r.matches++
+	return action
+}
+
+// RuleEngine holds a list of primed rules and evaluates them against incoming requests. Rules are evaluated in the
+// order they were primed, and the first matching rule wins. Use Handler to plug the engine into a CqlServer:
+//
+//  engine := NewRuleEngine(rule1, rule2)
+//  server.RequestHandlers = []RequestHandler{engine.Handler(), NewDriverConnectionInitializationHandler(...)}
+//
+// Requests not matched by any rule are passed on to the next handlers.
+type RuleEngine struct {
+	rules []*Rule
+	lock  sync.RWMutex
+}
+
+// NewRuleEngine creates a new RuleEngine primed with the given rules.
+func NewRuleEngine(rules ...*Rule) *RuleEngine {
+	return &RuleEngine{rules: rules}
+}
+
+// Prime adds the given rules to this engine. Rules can be primed while the server is running.
+func (e *RuleEngine) Prime(rules ...*Rule) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.rules = append(e.rules, rules...)
+}
+
+// Clear removes all rules from this engine.
+func (e *RuleEngine) Clear() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.rules = nil
+}
+
+// Handler returns a RequestHandler that applies this engine's rules to incoming requests.
+func (e *RuleEngine) Handler() RequestHandler {
+	return func(request *frame.Frame, conn *CqlServerConnection, _ RequestHandlerContext) (response *frame.Frame) {
+		if action := e.match(request); action != nil {
+			log.Debug().Msgf("%v: [rule engine]: request matched: %v", conn, request)
+			response = action(request, conn)
+			if response == nil {
+				log.Debug().Msgf("%v: [rule engine]: no response for request: %v", conn, request)
+			} else {
+				log.Debug().Msgf("%v: [rule engine]: returning %v", conn, response)
+			}
+		}
+		return
+	}
+}
+
+func (e *RuleEngine) match(request *frame.Frame) RuleAction {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	for _, rule := range e.rules {
+		if action := rule.match(request); action != nil {
+			return action
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestRuleEngine(t *testing.T) {
+
+	timeoutThenSuccess := client.NewRule(
+		client.MatchOpCode(primitive.OpCodeQuery),
+		client.MatchQuery("(?i)^SELECT .* FROM ks1\\.table1"),
+	).ThenNoResponse().ThenRespond(&message.VoidResult{})
+
+	overloaded := client.NewRule(
+		client.MatchVersion(primitive.ProtocolVersion4),
+		client.MatchQuery("INSERT"),
+		client.MatchPositionalValues(primitive.NewValue([]byte{1})),
+	).ThenRespondAfter(100*time.Millisecond, &message.Overloaded{ErrorMessage: "overloaded"})
+
+	engine := client.NewRuleEngine(timeoutThenSuccess, overloaded)
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{engine.Handler(), client.HeartbeatHandler}, nil)
+	defer cancelFn()
+
+	// first SELECT: no response
+	selectQuery := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "select * from ks1.table1"})
+	inFlight, err := clientConn.Send(selectQuery)
+	require.NoError(t, err)
+	select {
+	case response := <-inFlight.Incoming():
+		t.Fatalf("expected no response, got: %v", response)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// second SELECT: success
+	response, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "SELECT * FROM ks1.table1"}))
+	require.NoError(t, err)
+	assert.IsType(t, &message.VoidResult{}, response.Body.Message)
+	assert.Equal(t, 2, timeoutThenSuccess.MatchCount())
+
+	// INSERT with matching values: delayed error
+	insertQuery := &message.Query{
+		Query:   "INSERT INTO ks1.table1 (pk) VALUES (?)",
+		Options: &message.QueryOptions{PositionalValues: []*primitive.Value{primitive.NewValue([]byte{1})}},
+	}
+	start := time.Now()
+	response, err = clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, insertQuery))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(100*time.Millisecond))
+	assert.Equal(t, &message.Overloaded{ErrorMessage: "overloaded"}, response.Body.Message)
+
+	// INSERT with other values: not matched, falls through to the next handlers, which won't handle it either
+	insertQuery.Options.PositionalValues = []*primitive.Value{primitive.NewValue([]byte{2})}
+	inFlight, err = clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, insertQuery))
+	require.NoError(t, err)
+	select {
+	case response := <-inFlight.Incoming():
+		t.Fatalf("expected no response, got: %v", response)
+	case <-time.After(200 * time.Millisecond):
+	}
+	assert.Equal(t, 1, overloaded.MatchCount())
+
+	// unmatched requests are handled by the next handlers
+	testHeartbeat(t, clientConn)
+
+	// rules can be primed at runtime
+	engine.Clear()
+	engine.Prime(client.NewRule(client.MatchOpCode(primitive.OpCodeOptions)).ThenRespond(&message.Ready{}))
+	response, err = clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.NoError(t, err)
+	assert.IsType(t, &message.Ready{}, response.Body.Message)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestMatchNamedValue(t *testing.T) {
+	matcher := client.MatchNamedValue("pk", primitive.NewValue([]byte{1}))
+	request := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Execute{
+		QueryId: []byte{1, 2, 3},
+		Options: &message.QueryOptions{NamedValues: map[string]*primitive.Value{"pk": primitive.NewValue([]byte{1})}},
+	})
+	assert.True(t, matcher(request))
+	request.Body.Message.(*message.Execute).Options.NamedValues["pk"] = primitive.NewValue([]byte{2})
+	assert.False(t, matcher(request))
+	assert.False(t, matcher(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{})))
+}
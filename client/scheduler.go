@@ -0,0 +1,280 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// StepKind is the kind of a ScheduledStep.
+type StepKind string
+
+const (
+	// StepClientSend is a client connection writing a request to the network.
+	StepClientSend = StepKind("client send")
+	// StepClientReceive is a client connection delivering a response to its in-flight request, or an event to its
+	// handlers and subscribers.
+	StepClientReceive = StepKind("client receive")
+	// StepServerReceive is a server connection delivering a request to CqlServerConnection.Receive and to the request
+	// log.
+	StepServerReceive = StepKind("server receive")
+	// StepServerHandle is a server connection invoking its request handlers for a request.
+	StepServerHandle = StepKind("server handle")
+	// StepServerSend is a server connection writing a response to the network.
+	StepServerSend = StepKind("server send")
+)
+
+// ScheduledStep describes an internal hand-off of a connection, waiting to be released by a Scheduler.
+type ScheduledStep struct {
+	Kind StepKind
+	// Connection is a label identifying the connection, e.g. "client-1" or "server-2"; connections are numbered in the
+	// order they were created, independently of their network addresses, so that labels are stable across runs.
+	Connection string
+	StreamId   int16
+	OpCode     primitive.OpCode
+}
+
+func (s ScheduledStep) String() string {
+	return fmt.Sprintf("%v: %v (stream id %d, %v)", s.Connection, s.Kind, s.StreamId, s.OpCode)
+}
+
+func (s ScheduledStep) less(other ScheduledStep) bool {
+	if s.Connection != other.Connection {
+		return s.Connection < other.Connection
+	} else if s.Kind != other.Kind {
+		return s.Kind < other.Kind
+	} else if s.StreamId != other.StreamId {
+		return s.StreamId < other.StreamId
+	}
+	return s.OpCode < other.OpCode
+}
+
+// SchedulingPolicy chooses the next step to release among the pending ones, which are given in a deterministic order,
+// regardless of the order in which they became pending. It returns the index of the chosen step, or -1 to wait until
+// more steps are pending.
+type SchedulingPolicy func(pending []ScheduledStep) int
+
+// FirstPendingPolicy is a SchedulingPolicy that always releases the first pending step.
+func FirstPendingPolicy([]ScheduledStep) int {
+	return 0
+}
+
+// NewRandomSchedulingPolicy returns a SchedulingPolicy releasing pending steps in a pseudo-random order determined by
+// the given seed, which is useful to explore different interleavings of a test scenario.
+func NewRandomSchedulingPolicy(seed int64) SchedulingPolicy {
+	random := rand.New(rand.NewSource(seed))
+	return func(pending []ScheduledStep) int {
+		return random.Intn(len(pending))
+	}
+}
+
+// NewReplaySchedulingPolicy returns a SchedulingPolicy releasing steps in the order of the given history, typically
+// obtained with Scheduler.History: it waits until the next step of the history is pending, regardless of the other
+// pending steps. Once the history is exhausted, it releases the first pending step. Note that steps are only replayed
+// accurately if their stream ids are the same in both runs, e.g. if they are assigned manually.
+func NewReplaySchedulingPolicy(history []ScheduledStep) SchedulingPolicy {
+	next := 0
+	return func(pending []ScheduledStep) int {
+		if next == len(history) {
+			return 0
+		}
+		for i, step := range pending {
+			if step == history[next] {
+				next++
+				return i
+			}
+		}
+		return -1
+	}
+}
+
+// Scheduler serializes the internal hand-offs of client and server connections, such as writing a request or invoking
+// request handlers, so that test scenarios involving many connections can be replayed deterministically, e.g. when
+// debugging race-dependent driver failures. To enable it, set CqlClient.Scheduler and CqlServer.Scheduler to the same
+// Scheduler before creating connections.
+//
+// Connections then wait before each hand-off until the Scheduler releases it: each call to Step releases exactly one
+// pending step, chosen by the SchedulingPolicy, and waits for it to complete, so that no two steps ever run
+// concurrently. Steps must be released for connections to make progress, either by calling Step, or by running Run in
+// a separate goroutine. Network reads and writes themselves are not serialized, only the processing of frames is.
+// Pending steps are abandoned when their connection is closed.
+type Scheduler struct {
+	policy      SchedulingPolicy
+	pending     []*pendingStep
+	history     []ScheduledStep
+	connections map[string]int
+	lock        sync.Mutex
+	stepLock    sync.Mutex
+	arrived     chan struct{}
+}
+
+type pendingStep struct {
+	ScheduledStep
+	ctx      context.Context
+	released chan struct{}
+	done     chan struct{}
+}
+
+// NewScheduler creates a new Scheduler with the given policy; if nil, FirstPendingPolicy is used.
+func NewScheduler(policy SchedulingPolicy) *Scheduler {
+	if policy == nil {
+		policy = FirstPendingPolicy
+	}
+	return &Scheduler{
+		policy:      policy,
+		connections: make(map[string]int),
+		arrived:     make(chan struct{}, 1),
+	}
+}
+
+// Pending returns the steps currently waiting to be released, in the order they are presented to the policy.
+func (s *Scheduler) Pending() []ScheduledStep {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.sortedPending()
+}
+
+// History returns the steps released so far, in order. It can be given to NewReplaySchedulingPolicy to replay them.
+func (s *Scheduler) History() []ScheduledStep {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]ScheduledStep(nil), s.history...)
+}
+
+// Step waits until the policy chooses one of the pending steps, releases it, and waits until it completes or its
+// connection is closed. It returns the released step, or an error if the given context is done before. Concurrent
+// calls to Step are serialized.
+func (s *Scheduler) Step(ctx context.Context) (ScheduledStep, error) {
+	s.stepLock.Lock()
+	defer s.stepLock.Unlock()
+	for {
+		if step := s.choose(); step != nil {
+			close(step.released)
+			select {
+			case <-step.done:
+			case <-step.ctx.Done():
+			}
+			return step.ScheduledStep, nil
+		}
+		select {
+		case <-s.arrived:
+		case <-ctx.Done():
+			return ScheduledStep{}, ctx.Err()
+		}
+	}
+}
+
+// Run releases steps until the given context is done, and returns the context error.
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		if _, err := s.Step(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// choose returns the pending step chosen by the policy, after removing it from the pending steps and adding it to the
+// history, or nil if none.
+func (s *Scheduler) choose() *pendingStep {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.pending) == 0 {
+		return nil
+	}
+	sorted := s.sortedPending()
+	i := s.policy(sorted)
+	if i < 0 || i >= len(sorted) {
+		return nil
+	}
+	for j, step := range s.pending {
+		if step.ScheduledStep == sorted[i] {
+			s.pending = append(s.pending[:j], s.pending[j+1:]...)
+			s.history = append(s.history, step.ScheduledStep)
+			return step
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) sortedPending() []ScheduledStep {
+	sorted := make([]ScheduledStep, len(s.pending))
+	for i, step := range s.pending {
+		sorted[i] = step.ScheduledStep
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].less(sorted[j]) })
+	return sorted
+}
+
+// newConnectionLabel returns a new label for a connection of the given side, "client" or "server". It can be invoked
+// on a nil Scheduler.
+func (s *Scheduler) newConnectionLabel(side string) string {
+	if s == nil {
+		return ""
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.connections[side]++
+	return fmt.Sprintf("%v-%d", side, s.connections[side])
+}
+
+// await waits until the step of the given kind for the frame with the given header is released, or the given context is done, and
+// returns a function to invoke once the step is complete. It can be invoked on a nil Scheduler, in which case it
+// returns immediately.
+func (s *Scheduler) await(ctx context.Context, kind StepKind, connection string, header *frame.Header) (done func()) {
+	if s == nil {
+		return func() {}
+	}
+	step := &pendingStep{
+		ScheduledStep: ScheduledStep{Kind: kind, Connection: connection},
+		ctx:           ctx,
+		released:      make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if header != nil {
+		step.StreamId = header.StreamId
+		step.OpCode = header.OpCode
+	}
+	s.lock.Lock()
+	s.pending = append(s.pending, step)
+	s.lock.Unlock()
+	select {
+	case s.arrived <- struct{}{}:
+	default:
+	}
+	select {
+	case <-step.released:
+	case <-ctx.Done():
+		s.abandon(step)
+	}
+	return func() { close(step.done) }
+}
+
+func (s *Scheduler) abandon(step *pendingStep) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i, pending := range s.pending {
+		if pending == step {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			return
+		}
+	}
+}
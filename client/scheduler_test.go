@@ -0,0 +1,128 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func startScheduledServer(t *testing.T, ctx context.Context, scheduler *client.Scheduler) (*client.CqlServer, *client.CqlClient) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HeartbeatHandler}
+	server.Scheduler = scheduler
+	require.NoError(t, server.Start(ctx))
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.Scheduler = scheduler
+	return server, clt
+}
+
+func TestScheduler_Step(t *testing.T) {
+	scheduler := client.NewScheduler(nil)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	server, clt := startScheduledServer(t, ctx, scheduler)
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+
+	inFlight, err := clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Options{}))
+	require.NoError(t, err)
+	for _, kind := range []client.StepKind{
+		client.StepClientSend,
+		client.StepServerReceive,
+		client.StepServerHandle,
+		client.StepServerSend,
+		client.StepClientReceive,
+	} {
+		stepCtx, stepCancelFn := context.WithTimeout(ctx, 5*time.Second)
+		step, err := scheduler.Step(stepCtx)
+		stepCancelFn()
+		require.NoError(t, err)
+		expectedConnection, expectedOpCode := "server-1", primitive.OpCodeOptions
+		if kind == client.StepClientSend || kind == client.StepClientReceive {
+			expectedConnection = "client-1"
+		}
+		if kind == client.StepServerSend || kind == client.StepClientReceive {
+			expectedOpCode = primitive.OpCodeSupported
+		}
+		assert.Equal(t, client.ScheduledStep{
+			Kind:       kind,
+			Connection: expectedConnection,
+			StreamId:   1,
+			OpCode:     expectedOpCode,
+		}, step)
+	}
+	response, err := clientConn.Receive(inFlight)
+	require.NoError(t, err)
+	assert.IsType(t, &message.Supported{}, response.Body.Message)
+	assert.Empty(t, scheduler.Pending())
+	assert.Len(t, scheduler.History(), 5)
+
+	// nothing is pending
+	stepCtx, stepCancelFn := context.WithTimeout(ctx, 50*time.Millisecond)
+	_, err = scheduler.Step(stepCtx)
+	stepCancelFn()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestScheduler_Replay(t *testing.T) {
+	// runs a scenario with two connections sending concurrent requests, and returns the steps released
+	runScenario := func(policy client.SchedulingPolicy) []client.ScheduledStep {
+		scheduler := client.NewScheduler(policy)
+		ctx, cancelFn := context.WithCancel(context.Background())
+		server, clt := startScheduledServer(t, ctx, scheduler)
+		go func() { _ = scheduler.Run(ctx) }()
+		var clientConns []*client.CqlClientConnection
+		var inFlights []client.InFlightRequest
+		for i := 0; i < 2; i++ {
+			clientConn, err := clt.Connect(ctx)
+			require.NoError(t, err)
+			_, err = server.Accept(clientConn)
+			require.NoError(t, err)
+			clientConns = append(clientConns, clientConn)
+		}
+		for _, clientConn := range clientConns {
+			for streamId := int16(1); streamId <= 3; streamId++ {
+				inFlight, err := clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, streamId, &message.Options{}))
+				require.NoError(t, err)
+				inFlights = append(inFlights, inFlight)
+			}
+		}
+		for i, inFlight := range inFlights {
+			response, err := clientConns[i/3].Receive(inFlight)
+			require.NoError(t, err)
+			assert.IsType(t, &message.Supported{}, response.Body.Message)
+		}
+		cancelFn()
+		for _, clientConn := range clientConns {
+			checkClosed(t, clientConn, server)
+		}
+		return scheduler.History()
+	}
+	history := runScenario(client.NewRandomSchedulingPolicy(42))
+	require.Len(t, history, 2*3*5)
+	assert.Equal(t, history, runScenario(client.NewReplaySchedulingPolicy(history)))
+}
@@ -105,15 +105,44 @@ type CqlServer struct {
 	RequestHandlers []RequestHandler
 	// RequestRawHandlers is an optional list of handlers to handle incoming requests and return a response in a byte slice format.
 	RequestRawHandlers []RawRequestHandler
-	// TLSConfig is the TLS configuration to use.
+	// TLSConfig is the TLS configuration to use. If TLSListenAddress is empty, connections to ListenAddress must use
+	// TLS; otherwise, this configuration only applies to TLSListenAddress.
 	TLSConfig *tls.Config
+	// TLSListenAddress is an optional, additional address to listen to with TLS. When set, TLSConfig is required and
+	// ListenAddress accepts plain TCP connections, so that the server exposes both a cleartext and a TLS endpoint.
+	// Connections accepted on either address share the same handlers, credentials, request log and limits.
+	TLSListenAddress string
+	// RequestLog is an optional log where all incoming requests will be recorded. If nil, requests are not recorded.
+	RequestLog *RequestLog
+	// AllowBetaVersions enables beta protocol versions, such as primitive.ProtocolVersion6, for incoming connections.
+	// Frames using a beta version must have the USE_BETA flag set.
+	AllowBetaVersions bool
+	// Metrics is an optional Metrics notified of the frames exchanged by connections accepted by this server, e.g. an
+	// OpCodeMetrics.
+	Metrics Metrics
+	// Scheduler is an optional Scheduler serializing the internal hand-offs of connections accepted by this server,
+	// for deterministic tests; it is usually shared with a CqlClient. If nil, hand-offs are not serialized.
+	Scheduler *Scheduler
+	// MaxSegmentPayloadLength is the maximum payload length of the segments written by connections that switched to the
+	// modern framing layout (protocol v5 and higher). Responses are coalesced into one segment until this length is
+	// reached, and larger responses are split across many segments of at most this length. If zero, or greater than
+	// segment.MaxPayloadLength, segment.MaxPayloadLength is used.
+	MaxSegmentPayloadLength int
+	// Compressions is an optional list of the compression algorithms accepted in STARTUP requests, and advertised in
+	// SUPPORTED responses. If empty, all compression algorithms are accepted. Regardless of this setting, compression
+	// algorithms not supported by the protocol version in use are rejected, e.g. Snappy with protocol v5.
+	Compressions []primitive.Compression
+	// Middlewares is an optional list of middlewares invoked with all the frames exchanged by connections accepted by
+	// this server. See NewLoggingMiddleware.
+	Middlewares []Middleware
 
 	ctx                context.Context
 	cancel             context.CancelFunc
-	listener           net.Listener
+	listeners          []net.Listener
 	connectionsHandler *clientConnectionHandler
 	waitGroup          *sync.WaitGroup
 	state              int32
+	maxVersion         int32
 }
 
 // NewCqlServer creates a new CqlServer with default options. Leave credentials nil to opt out from authentication.
@@ -129,6 +158,9 @@ func NewCqlServer(listenAddress string, credentials *AuthCredentials) *CqlServer
 }
 
 func (server *CqlServer) String() string {
+	if server.TLSListenAddress != "" {
+		return fmt.Sprintf("CQL server [%v, TLS %v]", server.ListenAddress, server.TLSListenAddress)
+	}
 	return fmt.Sprintf("CQL server [%v]", server.ListenAddress)
 }
 
@@ -157,6 +189,8 @@ func (server *CqlServer) transitionState(old int32, new int32) bool {
 func (server *CqlServer) Start(ctx context.Context) (err error) {
 	if ctx == nil {
 		return fmt.Errorf("context cannot be nil")
+	} else if server.TLSListenAddress != "" && server.TLSConfig == nil {
+		return fmt.Errorf("%v: TLSConfig is required when TLSListenAddress is set", server)
 	}
 	if server.transitionState(ServerStateNotStarted, ServerStateRunning) {
 		log.Debug().Msgf("%v: server is starting", server)
@@ -164,17 +198,14 @@ func (server *CqlServer) Start(ctx context.Context) (err error) {
 		if err != nil {
 			return fmt.Errorf("%v: start failed: %w", server, err)
 		}
-		if server.TLSConfig != nil {
-			server.listener, err = tls.Listen("tcp", server.ListenAddress, server.TLSConfig)
-		} else {
-			server.listener, err = net.Listen("tcp", server.ListenAddress)
-		}
-		if err != nil {
+		if server.listeners, err = server.listen(); err != nil {
 			return fmt.Errorf("%v: start failed: %w", server, err)
 		}
 		server.ctx, server.cancel = context.WithCancel(ctx)
 		server.waitGroup = &sync.WaitGroup{}
-		server.acceptLoop()
+		for _, listener := range server.listeners {
+			server.acceptLoop(listener)
+		}
 		server.awaitDone()
 		log.Info().Msgf("%v: successfully started", server)
 	} else {
@@ -183,10 +214,38 @@ func (server *CqlServer) Start(ctx context.Context) (err error) {
 	return err
 }
 
+// listen binds to ListenAddress and, if set, to TLSListenAddress. If any of the listeners cannot be created, the ones
+// already created are closed.
+func (server *CqlServer) listen() ([]net.Listener, error) {
+	var listener net.Listener
+	var err error
+	if server.TLSConfig != nil && server.TLSListenAddress == "" {
+		listener, err = tls.Listen("tcp", server.ListenAddress, server.TLSConfig)
+	} else {
+		listener, err = net.Listen("tcp", server.ListenAddress)
+	}
+	if err != nil {
+		return nil, err
+	}
+	listeners := []net.Listener{listener}
+	if server.TLSListenAddress != "" {
+		if listener, err = tls.Listen("tcp", server.TLSListenAddress, server.TLSConfig); err != nil {
+			_ = listeners[0].Close()
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
 func (server *CqlServer) Close() (err error) {
 	if server.transitionState(ServerStateRunning, ServerStateClosed) {
 		log.Debug().Msgf("%v: closing", server)
-		err = server.listener.Close()
+		for _, listener := range server.listeners {
+			if closeErr := listener.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
 		server.connectionsHandler.close()
 		server.cancel()
 		server.waitGroup.Wait()
@@ -209,19 +268,19 @@ func (server *CqlServer) abort() {
 	}
 }
 
-func (server *CqlServer) acceptLoop() {
+func (server *CqlServer) acceptLoop(listener net.Listener) {
 	server.waitGroup.Add(1)
 	go func() {
 		abort := false
 		for server.IsRunning() {
-			if conn, err := server.listener.Accept(); err != nil {
+			if conn, err := listener.Accept(); err != nil {
 				if !server.IsClosed() {
 					log.Error().Err(err).Msgf("%v: error accepting client connections, closing server", server)
 					abort = true
 				}
 				break
 			} else {
-				log.Debug().Msgf("%v: new TCP connection accepted", server)
+				log.Debug().Msgf("%v: new TCP connection accepted on %v", server, listener.Addr())
 				if connection, err := newCqlServerConnection(
 					conn,
 					server.ctx,
@@ -230,6 +289,14 @@ func (server *CqlServer) acceptLoop() {
 					server.IdleTimeout,
 					server.RequestHandlers,
 					server.RequestRawHandlers,
+					server.RequestLog,
+					server.AllowBetaVersions,
+					server.GetMaxProtocolVersion,
+					server.Metrics,
+					server.Scheduler,
+					server.MaxSegmentPayloadLength,
+					server.Compressions,
+					server.Middlewares,
 					server.connectionsHandler.onConnectionClosed,
 				); err != nil {
 					log.Error().Msgf("%v: failed to accept incoming CQL client connection: %v", server, connection)
@@ -360,6 +427,16 @@ func newRawResponse(rawResponse []byte) *response {
 	}
 }
 
+// header returns the header of the response, or nil if it cannot be decoded.
+func (r *response) header() *frame.Header {
+	if r.responseFrame != nil {
+		return r.responseFrame.Header
+	} else if header, err := frame.NewRawCodec().DecodeHeader(bytes.NewReader(r.rawResponse)); err == nil {
+		return header
+	}
+	return nil
+}
+
 // CqlServerConnection encapsulates a TCP server connection to a remote CQL client.
 // CqlServerConnection instances should be created by calling CqlServer.Accept or CqlServer.Bind.
 type CqlServerConnection struct {
@@ -367,11 +444,22 @@ type CqlServerConnection struct {
 	credentials        *AuthCredentials
 	frameCodec         frame.Codec
 	segmentCodec       segment.Codec
+	multiplexer        *segment.Multiplexer
+	maxSegmentPayload  int
 	compression        primitive.Compression
+	compressions       []primitive.Compression
+	middlewares        []Middleware
 	modernLayout       bool
 	idleTimeout        time.Duration
 	handlers           []RequestHandler
 	rawHandlers        []RawRequestHandler
+	requestLog         *RequestLog
+	allowBeta          bool
+	maxVersion         func() primitive.ProtocolVersion
+	metrics            Metrics
+	requestTracker     *requestTracker
+	scheduler          *Scheduler
+	schedulerLabel     string
 	handlerCtx         []RequestHandlerContext
 	incoming           chan *frame.Frame
 	outgoing           chan *response
@@ -381,6 +469,8 @@ type CqlServerConnection struct {
 	ctx                context.Context
 	cancel             context.CancelFunc
 	payloadAccumulator *payloadAccumulator
+	usedStreamIds      map[int16]bool
+	usedStreamIdsLock  sync.Mutex
 }
 
 func newCqlServerConnection(
@@ -391,6 +481,14 @@ func newCqlServerConnection(
 	idleTimeout time.Duration,
 	handlers []RequestHandler,
 	rawHandlers []RawRequestHandler,
+	requestLog *RequestLog,
+	allowBeta bool,
+	maxVersion func() primitive.ProtocolVersion,
+	metrics Metrics,
+	scheduler *Scheduler,
+	maxSegmentPayload int,
+	compressions []primitive.Compression,
+	middlewares []Middleware,
 	onClose func(*CqlServerConnection),
 ) (*CqlServerConnection, error) {
 	if conn == nil {
@@ -401,26 +499,42 @@ func newCqlServerConnection(
 	} else if maxInFlight > math.MaxInt16 {
 		return nil, fmt.Errorf("max in-flight: expecting <= %v, got: %v", math.MaxInt16, maxInFlight)
 	}
-	frameCodec := frame.NewCodec()
+	frameCodec := newFrameCodec(primitive.CompressionNone, allowBeta)
 	segmentCodec := segment.NewCodec()
 	connection := &CqlServerConnection{
-		conn:         conn,
-		frameCodec:   frameCodec,
-		segmentCodec: segmentCodec,
-		compression:  primitive.CompressionNone,
-		credentials:  credentials,
-		idleTimeout:  idleTimeout,
-		handlers:     handlers,
-		rawHandlers:  rawHandlers,
-		handlerCtx:   make([]RequestHandlerContext, len(handlers)),
-		incoming:     make(chan *frame.Frame, maxInFlight),
-		outgoing:     make(chan *response, maxInFlight),
-		waitGroup:    &sync.WaitGroup{},
-		onClose:      onClose,
+		conn:              conn,
+		frameCodec:        frameCodec,
+		segmentCodec:      segmentCodec,
+		maxSegmentPayload: maxSegmentPayload,
+		compression:       primitive.CompressionNone,
+		compressions:      compressions,
+		middlewares:       middlewares,
+		credentials:       credentials,
+		idleTimeout:       idleTimeout,
+		handlers:          handlers,
+		rawHandlers:       rawHandlers,
+		requestLog:        requestLog,
+		allowBeta:         allowBeta,
+		maxVersion:        maxVersion,
+		metrics:           metrics,
+		scheduler:         scheduler,
+		schedulerLabel:    scheduler.newConnectionLabel("server"),
+		handlerCtx:        make([]RequestHandlerContext, len(handlers)),
+		incoming:          make(chan *frame.Frame, maxInFlight),
+		outgoing:          make(chan *response, maxInFlight),
+		waitGroup:         &sync.WaitGroup{},
+		onClose:           onClose,
+		usedStreamIds:     make(map[int16]bool),
+		payloadAccumulator: &payloadAccumulator{
+			frameCodec: newFrameCodec(primitive.CompressionNone, allowBeta),
+		},
 	}
 	for i := range handlers {
 		connection.handlerCtx[i] = requestHandlerContext{}
 	}
+	if metrics != nil {
+		connection.requestTracker = newRequestTracker()
+	}
 	connection.ctx, connection.cancel = context.WithCancel(ctx)
 	connection.incomingLoop()
 	connection.outgoingLoop()
@@ -454,6 +568,12 @@ func (c *CqlServerConnection) GetConn() net.Conn {
 	return c.conn
 }
 
+// Done returns a channel that is closed when this connection is closed, be it explicitly, or because its parent context
+// is done.
+func (c *CqlServerConnection) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
 func (c *CqlServerConnection) incomingLoop() {
 	log.Debug().Msgf("%v: listening for incoming frames...", c)
 	c.waitGroup.Add(1)
@@ -490,21 +610,37 @@ func (c *CqlServerConnection) outgoingLoop() {
 				}
 				break
 			} else {
+				done := func() {}
+				if c.scheduler != nil {
+					// decoding the header of raw responses is only worth it when a scheduler is configured
+					done = c.scheduler.await(c.ctx, StepServerSend, c.schedulerLabel, outgoing.header())
+				}
 				if outgoing.rawResponse != nil {
-					abort = c.writeRawResponse(outgoing.rawResponse, c.conn)
 					log.Debug().Msgf("%v: sending outgoing raw response: %v", c, outgoing.rawResponse)
+					if c.modernLayout {
+						abort = c.writeRawSegment(outgoing.rawResponse)
+					} else {
+						abort = c.writeRawResponse(outgoing.rawResponse, c.conn)
+					}
 				} else {
-					if c.compression != primitive.CompressionNone {
+					// with the modern framing layout, compression is applied to segments, never to frames; handshake
+					// frames exchanged before the switch to the modern layout are never compressed either.
+					if c.compression != primitive.CompressionNone &&
+						!outgoing.responseFrame.Header.Version.SupportsModernFramingLayout() {
 						outgoing.responseFrame.Header.Flags = outgoing.responseFrame.Header.Flags.Add(primitive.HeaderFlagCompressed)
 					}
 					log.Debug().Msgf("%v: sending outgoing frame: %v", c, outgoing.responseFrame)
 					if c.modernLayout {
-						// TODO write coalescer
-						abort = c.writeSegment(outgoing.responseFrame, c.conn)
+						abort = c.writeSegment(outgoing.responseFrame)
 					} else {
 						abort = c.writeFrame(outgoing.responseFrame, c.conn)
 					}
 				}
+				if !abort && c.modernLayout && len(c.outgoing) == 0 {
+					// no more pending responses: flush the segment containing the responses coalesced so far
+					abort = c.flushSegments()
+				}
+				done()
 			}
 		}
 		c.waitGroup.Done()
@@ -577,33 +713,50 @@ func (c *CqlServerConnection) addMultiSegmentPayload(payload *segment.Payload) (
 	return false
 }
 
-func (c *CqlServerConnection) writeSegment(outgoing *frame.Frame, dest io.Writer) (abort bool) {
+func (c *CqlServerConnection) writeSegment(outgoing *frame.Frame) (abort bool) {
 	// never compress frames individually when included in a segment
-	outgoing.Header.Flags.Remove(primitive.HeaderFlagCompressed)
-	encodedFrame := &bytes.Buffer{}
-	if abort = c.writeFrame(outgoing, encodedFrame); abort {
-		abort = true
-	} else {
-		seg := &segment.Segment{
-			Header:  &segment.Header{IsSelfContained: true},
-			Payload: &segment.Payload{UncompressedData: encodedFrame.Bytes()},
-		}
-		if err := c.segmentCodec.EncodeSegment(seg, dest); err != nil {
+	outgoing.Header.Flags = outgoing.Header.Flags.Remove(primitive.HeaderFlagCompressed)
+	// the frame is encoded straight into the buffer handed to the multiplexer
+	encodedFrame := primitive.AcquireWriteBuffer()
+	defer primitive.ReleaseWriteBuffer(encodedFrame)
+	if abort = c.encodeFrame(outgoing, encodedFrame); !abort {
+		if err := c.multiplexer.WriteFrame(encodedFrame.Bytes()); err != nil {
 			abort = c.reportConnectionFailure(err, false)
 		} else {
-			log.Debug().Msgf("%v: outgoing segment successfully written: %v (frame: %v)", c, seg, outgoing)
+			log.Debug().Msgf("%v: outgoing frame successfully added to segment: %v", c, outgoing)
+			c.onFrameWritten(outgoing)
 		}
 	}
 	return abort
 }
 
+func (c *CqlServerConnection) writeRawSegment(outgoing []byte) (abort bool) {
+	if err := c.multiplexer.WriteFrame(outgoing); err != nil {
+		abort = c.reportConnectionFailure(err, false)
+	} else {
+		log.Debug().Msgf("%v: outgoing raw response successfully added to segment: %v", c, outgoing)
+	}
+	return abort
+}
+
+func (c *CqlServerConnection) flushSegments() (abort bool) {
+	if err := c.multiplexer.Flush(); err != nil {
+		abort = c.reportConnectionFailure(err, false)
+	}
+	return abort
+}
+
 func (c *CqlServerConnection) readFrame(source io.Reader) (abort bool) {
+	start := time.Now()
 	if incoming, err := c.frameCodec.DecodeFrame(source); err != nil {
 		abort = c.reportConnectionFailure(err, true)
 	} else {
-		if startup, ok := incoming.Body.Message.(*message.Startup); ok {
+		if c.metrics != nil {
+			c.metrics.OnFrameDecoded(incoming, encodedFrameLength(incoming), time.Since(start))
+		}
+		if startup, ok := incoming.Body.Message.(*message.Startup); ok && c.acceptsCompression(incoming.Header.Version, startup.GetCompression()) {
 			c.compression = startup.GetCompression()
-			c.frameCodec = frame.NewCodecWithCompression(NewBodyCompressor(c.compression))
+			c.frameCodec = newFrameCodec(c.compression, c.allowBeta)
 			c.segmentCodec = segment.NewCodecWithCompression(NewPayloadCompressor(c.compression))
 		}
 		c.processIncomingFrame(incoming)
@@ -612,13 +765,39 @@ func (c *CqlServerConnection) readFrame(source io.Reader) (abort bool) {
 }
 
 func (c *CqlServerConnection) writeFrame(outgoing *frame.Frame, dest io.Writer) (abort bool) {
+	// encode the whole frame before writing it, to avoid issuing many small writes to the connection
+	encodedFrame := primitive.AcquireWriteBuffer()
+	defer primitive.ReleaseWriteBuffer(encodedFrame)
+	if abort = c.encodeFrame(outgoing, encodedFrame); !abort {
+		if _, err := encodedFrame.WriteTo(dest); err != nil {
+			abort = c.reportConnectionFailure(err, false)
+		} else {
+			log.Debug().Msgf("%v: outgoing frame successfully written: %v", c, outgoing)
+			c.onFrameWritten(outgoing)
+		}
+	}
+	return abort
+}
+
+func (c *CqlServerConnection) encodeFrame(outgoing *frame.Frame, dest *primitive.WriteBuffer) (abort bool) {
 	c.maybeSwitchToModernLayout(outgoing)
+	start := time.Now()
 	if err := c.frameCodec.EncodeFrame(outgoing, dest); err != nil {
-		abort = c.reportConnectionFailure(err, false)
-	} else {
-		log.Debug().Msgf("%v: outgoing frame successfully written: %v", c, outgoing)
+		return c.reportConnectionFailure(err, false)
+	}
+	if c.metrics != nil {
+		c.metrics.OnFrameEncoded(outgoing, dest.Len(), time.Since(start))
+	}
+	return false
+}
+
+func (c *CqlServerConnection) onFrameWritten(outgoing *frame.Frame) {
+	invokeMiddlewares(c.middlewares, c, FrameSent, outgoing)
+	if c.metrics != nil {
+		if request, latency := c.requestTracker.onResponse(outgoing); request != nil {
+			c.metrics.OnResponseSent(request, outgoing, latency)
+		}
 	}
-	return abort
 }
 
 func (c *CqlServerConnection) writeRawResponse(outgoing []byte, dest io.Writer) (abort bool) {
@@ -637,10 +816,38 @@ func (c *CqlServerConnection) maybeSwitchToModernLayout(outgoing *frame.Frame) {
 		// Changing this value could be racy if some incoming frame is being processed;
 		// but in theory, this should never happen during handshake.
 		log.Debug().Msgf("%v: switching to modern framing layout", c)
+		c.multiplexer = segment.NewMultiplexer(c.segmentCodec, c.conn, c.maxSegmentPayload, 0)
 		c.modernLayout = true
 	}
 }
 
+// acceptsCompression returns true if the given compression, requested in a STARTUP request, is accepted by this
+// connection for the given protocol version.
+func (c *CqlServerConnection) acceptsCompression(version primitive.ProtocolVersion, compression primitive.Compression) bool {
+	if compression == primitive.CompressionNone {
+		return true
+	} else if !version.SupportsCompression(compression) {
+		return false
+	} else if len(c.compressions) == 0 {
+		return true
+	}
+	for _, accepted := range c.compressions {
+		if accepted == compression {
+			return true
+		}
+	}
+	return false
+}
+
+// supported returns the SUPPORTED response to send to clients, advertising the accepted compression algorithms.
+func (c *CqlServerConnection) supported() *message.Supported {
+	compressions := c.compressions
+	if len(compressions) == 0 {
+		compressions = []primitive.Compression{primitive.CompressionLz4, primitive.CompressionSnappy}
+	}
+	return message.NewSupported(&message.SupportedOptions{CompressionAlgorithms: compressions})
+}
+
 func (c *CqlServerConnection) reportConnectionFailure(err error, read bool) (abort bool) {
 	if !c.IsClosed() {
 		if errors.Is(err, io.EOF) {
@@ -659,6 +866,19 @@ func (c *CqlServerConnection) reportConnectionFailure(err error, read bool) (abo
 
 func (c *CqlServerConnection) processIncomingFrame(incoming *frame.Frame) {
 	log.Debug().Msgf("%v: received incoming frame: %v", c, incoming)
+	defer c.scheduler.await(c.ctx, StepServerReceive, c.schedulerLabel, incoming.Header)()
+	invokeMiddlewares(c.middlewares, c, FrameReceived, incoming)
+	if c.rejectUnsupportedVersion(incoming) || c.rejectReservedStreamId(incoming) {
+		return
+	}
+	c.markStreamIdUsed(incoming.Header.StreamId)
+	if c.metrics != nil {
+		c.requestTracker.onRequest(incoming)
+		c.metrics.OnRequestReceived(incoming)
+	}
+	if c.requestLog != nil {
+		c.requestLog.add(incoming, c)
+	}
 	select {
 	case c.incoming <- incoming:
 		log.Debug().Msgf("%v: incoming frame successfully delivered: %v", c, incoming)
@@ -670,6 +890,23 @@ func (c *CqlServerConnection) processIncomingFrame(incoming *frame.Frame) {
 	}
 }
 
+// rejectReservedStreamId replies with a PROTOCOL ERROR to requests using a stream id reserved for server-initiated
+// frames, and returns true if the request was rejected.
+func (c *CqlServerConnection) rejectReservedStreamId(request *frame.Frame) bool {
+	err := primitive.StreamId(request.Header.StreamId).ValidateRequest(request.Header.Version)
+	if err == nil {
+		return false
+	}
+	log.Debug().Err(err).Msgf("%v: rejecting request with invalid stream id: %v", c, request)
+	response := frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.ProtocolError{
+		ErrorMessage: fmt.Sprintf("Invalid stream id: %v", err),
+	})
+	if err = c.Send(response); err != nil {
+		log.Error().Err(err).Msgf("%v: send failed for frame: %v", c, response)
+	}
+	return true
+}
+
 func (c *CqlServerConnection) awaitDone() {
 	c.waitGroup.Add(1)
 	go func() {
@@ -683,6 +920,7 @@ func (c *CqlServerConnection) awaitDone() {
 func (c *CqlServerConnection) invokeRequestHandlers(request *frame.Frame) {
 	c.waitGroup.Add(1)
 	go func() {
+		defer c.scheduler.await(c.ctx, StepServerHandle, c.schedulerLabel, request.Header)()
 		log.Debug().Msgf("%v: invoking request handlers for incoming request: %v", c, request)
 		var err error
 		var rawResponse []byte
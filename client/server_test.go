@@ -15,11 +15,25 @@
 package client_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
+	"github.com/datastax/go-cassandra-native-protocol/segment"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"io"
+	"math/big"
+	"net"
 	"testing"
 	"time"
 )
@@ -221,3 +235,243 @@ func TestCqlServer_BindAndInit(t *testing.T) {
 	assert.Eventually(t, serverConn2.IsClosed, time.Second*10, time.Millisecond*10)
 	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
 }
+
+func TestCqlServer_PlainAndTLSListeners(t *testing.T) {
+
+	serverTLSConfig, clientTLSConfig := newSelfSignedTLSConfigs(t)
+
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.TLSConfig = serverTLSConfig
+	server.TLSListenAddress = "127.0.0.1:9045"
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, client.HeartbeatHandler}
+
+	plainClt := client.NewCqlClient("127.0.0.1:9043", nil)
+	tlsClt := client.NewCqlClient("127.0.0.1:9045", nil)
+	tlsClt.TLSConfig = clientTLSConfig
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	err := server.Start(ctx)
+	require.NoError(t, err)
+
+	// both endpoints share the same handlers and connection state
+	plainConn, err := plainClt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	testHeartbeat(t, plainConn)
+
+	tlsConn, err := tlsClt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	testHeartbeat(t, tlsConn)
+
+	clients, err := server.AllAcceptedClients()
+	require.NoError(t, err)
+	require.Len(t, clients, 2)
+
+	// the cleartext endpoint does not accept TLS connections
+	conn, err := net.Dial("tcp", "127.0.0.1:9043")
+	require.NoError(t, err)
+	err = tls.Client(conn, clientTLSConfig).Handshake()
+	require.Error(t, err)
+	_ = conn.Close()
+
+	cancelFn()
+
+	assert.Eventually(t, plainConn.IsClosed, time.Second*10, time.Millisecond*10)
+	assert.Eventually(t, tlsConn.IsClosed, time.Second*10, time.Millisecond*10)
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestCqlServer_TLSListenAddressWithoutTLSConfig(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.TLSListenAddress = "127.0.0.1:9045"
+	err := server.Start(context.Background())
+	assert.EqualError(t, err, "CQL server [127.0.0.1:9043, TLS 127.0.0.1:9045]: TLSConfig is required when TLSListenAddress is set")
+	assert.True(t, server.IsNotStarted())
+}
+
+// largeRowsHandler replies to QUERY requests with a result large enough to be split across many segments.
+var largeRowsHandler client.RequestHandler = func(request *frame.Frame, _ *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+	if _, ok := request.Body.Message.(*message.Query); ok {
+		rows := message.RowSet{}
+		for i := 0; i < 50; i++ {
+			rows = append(rows, message.Row{message.Column{0, 0, 0, 4, 1, 2, 3, byte(i)}})
+		}
+		result := &message.RowsResult{Metadata: &message.RowsMetadata{ColumnCount: 1}, Data: rows}
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, result)
+	}
+	return nil
+}
+
+func TestCqlServer_ModernFraming(t *testing.T) {
+	for _, compression := range []primitive.Compression{primitive.CompressionNone, primitive.CompressionLz4} {
+		t.Run(string(compression), func(t *testing.T) {
+			server := client.NewCqlServer("127.0.0.1:9043", nil)
+			server.MaxSegmentPayloadLength = 100
+			server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, largeRowsHandler}
+			clt := client.NewCqlClient("127.0.0.1:9043", nil)
+			clt.Compression = compression
+
+			ctx, cancelFn := context.WithCancel(context.Background())
+			defer cancelFn()
+			require.NoError(t, server.Start(ctx))
+
+			clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion5, client.ManagedStreamId)
+			require.NoError(t, err)
+			for i := 0; i < 10; i++ {
+				query := frame.NewFrame(primitive.ProtocolVersion5, client.ManagedStreamId, &message.Query{Query: "SELECT"})
+				response, err := clientConn.SendAndReceive(query)
+				require.NoError(t, err)
+				require.IsType(t, &message.RowsResult{}, response.Body.Message)
+				assert.Len(t, response.Body.Message.(*message.RowsResult).Data, 50)
+				assert.False(t, response.Header.Flags.Contains(primitive.HeaderFlagCompressed))
+			}
+
+			cancelFn()
+			assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+			assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+		})
+	}
+}
+
+// recordingSegmentCodec is a segment.Codec recording the payload length of each decoded segment.
+type recordingSegmentCodec struct {
+	segment.Codec
+	payloadLengths []int
+}
+
+func (c *recordingSegmentCodec) DecodeSegment(source io.Reader) (*segment.Segment, error) {
+	seg, err := c.Codec.DecodeSegment(source)
+	if err == nil {
+		c.payloadLengths = append(c.payloadLengths, len(seg.Payload.UncompressedData))
+	}
+	return seg, err
+}
+
+func TestCqlServer_MaxSegmentPayloadLength(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.MaxSegmentPayloadLength = 100
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, largeRowsHandler}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	conn, err := net.Dial("tcp", "127.0.0.1:9043")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// handshake frames use the legacy framing layout
+	frameCodec := frame.NewCodec()
+	startup := message.NewStartup()
+	startup.SetCompression(primitive.CompressionLz4)
+	require.NoError(t, frameCodec.EncodeFrame(frame.NewFrame(primitive.ProtocolVersion5, 1, startup), conn))
+	ready, err := frameCodec.DecodeFrame(conn)
+	require.NoError(t, err)
+	require.IsType(t, &message.Ready{}, ready.Body.Message)
+	assert.False(t, ready.Header.Flags.Contains(primitive.HeaderFlagCompressed))
+
+	// then frames are exchanged in compressed segments
+	segmentCodec := &recordingSegmentCodec{Codec: segment.NewCodecWithCompression(&lz4.Compressor{})}
+	mux := segment.NewMultiplexer(segmentCodec, conn, 0, 0)
+	encodedQuery := &bytes.Buffer{}
+	require.NoError(t, frameCodec.EncodeFrame(frame.NewFrame(primitive.ProtocolVersion5, 2, &message.Query{Query: "SELECT"}), encodedQuery))
+	require.NoError(t, mux.WriteFrame(encodedQuery.Bytes()))
+	require.NoError(t, mux.Flush())
+
+	encodedResult, err := segment.NewDemultiplexer(segmentCodec, conn).ReadFrame()
+	require.NoError(t, err)
+	result, err := frameCodec.DecodeFrame(bytes.NewReader(encodedResult))
+	require.NoError(t, err)
+	require.IsType(t, &message.RowsResult{}, result.Body.Message)
+	assert.Len(t, result.Body.Message.(*message.RowsResult).Data, 50)
+	assert.Greater(t, len(segmentCodec.payloadLengths), 1)
+	for _, length := range segmentCodec.payloadLengths {
+		assert.LessOrEqual(t, length, 100)
+	}
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestCqlServer_Compressions(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.Compressions = []primitive.Compression{primitive.CompressionLz4}
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.SendOptions = true
+	clt.Compression = primitive.CompressionSnappy
+	_, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	assert.Error(t, err)
+
+	clt.Compression = primitive.CompressionLz4
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"LZ4"}, clientConn.Supported().Options[message.StartupOptionCompression])
+
+	cancelFn()
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+// newSelfSignedTLSConfigs creates a server TLS configuration with a self-signed certificate for 127.0.0.1, and a client
+// TLS configuration that trusts it.
+func newSelfSignedTLSConfigs(t *testing.T) (*tls.Config, *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	serverConfig := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}}}
+	clientConfig := &tls.Config{RootCAs: pool}
+	return serverConfig, clientConfig
+}
+
+func TestCqlServer_ReservedStreamId(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HeartbeatHandler}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	conn, err := net.Dial("tcp", "127.0.0.1:9043")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// negative stream ids are reserved for server-initiated frames
+	codec := frame.NewCodec()
+	require.NoError(t, codec.EncodeFrame(frame.NewFrame(primitive.ProtocolVersion4, -2, &message.Options{}), conn))
+	response, err := codec.DecodeFrame(conn)
+	require.NoError(t, err)
+	assert.Equal(t, int16(-2), response.Header.StreamId)
+	require.IsType(t, &message.ProtocolError{}, response.Body.Message)
+	assert.Equal(t, "Invalid stream id: stream id reserved for server-initiated frames: -2",
+		response.Body.Message.(*message.ProtocolError).ErrorMessage)
+
+	require.NoError(t, codec.EncodeFrame(frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Options{}), conn))
+	response, err = codec.DecodeFrame(conn)
+	require.NoError(t, err)
+	assert.IsType(t, &message.Supported{}, response.Body.Message)
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
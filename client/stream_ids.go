@@ -0,0 +1,180 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// MaxStreamIds returns the number of distinct stream ids that requests can use in the given protocol version: 128 in
+// protocol version 2, where stream ids are 1-byte signed integers, and 32768 in protocol version 3 and higher. Negative
+// stream ids are reserved for server-initiated events.
+func MaxStreamIds(version primitive.ProtocolVersion) int {
+	return int(primitive.MaxStreamId(version)) + 1
+}
+
+// StreamIdsExhaustedError is returned when a request cannot be sent because all the stream ids available to it are in
+// use. Use errors.As to detect it.
+type StreamIdsExhaustedError struct {
+	// InFlight is the number of stream ids in use when the error occurred.
+	InFlight int
+	// Available is the number of stream ids that were available to the request, taking into account both the
+	// allocator's maximum in-flight requests and the request's protocol version.
+	Available int
+}
+
+func (e *StreamIdsExhaustedError) Error() string {
+	return fmt.Sprintf("no stream id available: %d of %d stream ids in use", e.InFlight, e.Available)
+}
+
+var errStreamIdAllocatorClosed = errors.New("stream id allocator closed")
+
+// StreamIdAllocator hands out stream ids to outgoing requests, and is safe for concurrent use. Stream ids are allocated
+// in a round-robin fashion, starting from 1, so that a recently released stream id is not immediately reused; stream id
+// 0, which otherwise designates ManagedStreamId, comes last in each round, and only if all the stream ids of the
+// protocol version can be in use concurrently. This allows up to 32768 concurrent requests in protocol version 3 and
+// higher, and up to 128 in protocol version 2.
+//
+// When all stream ids are in use, TryAcquire fails immediately with a StreamIdsExhaustedError, while Acquire waits
+// until a stream id is released, or its context is done, whichever happens first.
+//
+// CqlClientConnection uses a StreamIdAllocator to manage stream ids of frames sent with ManagedStreamId; the allocator
+// can also be used on its own, e.g. by tools managing stream ids themselves.
+type StreamIdAllocator struct {
+	maxInFlight int
+	// inUse is indexed by stream id
+	inUse    []bool
+	inFlight int
+	cursor   int
+	closed   bool
+	// released is closed and replaced every time a stream id is released, to wake up waiting goroutines.
+	released chan struct{}
+	lock     sync.Mutex
+}
+
+// NewStreamIdAllocator creates a new StreamIdAllocator allowing at most maxInFlight stream ids to be in use
+// concurrently. maxInFlight must be between 1 and 32768.
+func NewStreamIdAllocator(maxInFlight int) (*StreamIdAllocator, error) {
+	if maxInFlight < 1 {
+		return nil, fmt.Errorf("max in-flight: expecting positive, got: %v", maxInFlight)
+	} else if maxInFlight > math.MaxInt16+1 {
+		return nil, fmt.Errorf("max in-flight: expecting <= %v, got: %v", math.MaxInt16+1, maxInFlight)
+	}
+	return &StreamIdAllocator{
+		maxInFlight: maxInFlight,
+		inUse:       make([]bool, math.MaxInt16+1),
+		released:    make(chan struct{}),
+	}, nil
+}
+
+// MaxInFlight returns the maximum number of stream ids that can be in use concurrently.
+func (a *StreamIdAllocator) MaxInFlight() int {
+	return a.maxInFlight
+}
+
+// InFlight returns the number of stream ids currently in use.
+func (a *StreamIdAllocator) InFlight() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.inFlight
+}
+
+// TryAcquire returns an unused stream id valid for the given protocol version, or a StreamIdsExhaustedError if none is
+// available.
+func (a *StreamIdAllocator) TryAcquire(version primitive.ProtocolVersion) (int16, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	id, _, err := a.tryAcquire(version)
+	return id, err
+}
+
+// Acquire returns an unused stream id valid for the given protocol version, waiting for one to be released if none is
+// available. If ctx is done before a stream id is released, a StreamIdsExhaustedError is returned.
+func (a *StreamIdAllocator) Acquire(ctx context.Context, version primitive.ProtocolVersion) (int16, error) {
+	for {
+		a.lock.Lock()
+		id, released, err := a.tryAcquire(version)
+		a.lock.Unlock()
+		if released == nil {
+			return id, err
+		}
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return -1, err
+		}
+	}
+}
+
+// tryAcquire must be called with the lock held. If no stream id is available, it also returns the channel to wait on.
+func (a *StreamIdAllocator) tryAcquire(version primitive.ProtocolVersion) (int16, chan struct{}, error) {
+	if a.closed {
+		return -1, nil, errStreamIdAllocatorClosed
+	}
+	maxStreamIds := MaxStreamIds(version)
+	available := a.maxInFlight
+	if available > maxStreamIds {
+		available = maxStreamIds
+	}
+	for i := 0; i < available && a.inFlight < a.maxInFlight; i++ {
+		index := (a.cursor + i) % available
+		if id := streamIdAt(index, maxStreamIds); !a.inUse[id] {
+			a.inUse[id] = true
+			a.inFlight++
+			a.cursor = (index + 1) % available
+			return id, nil, nil
+		}
+	}
+	return -1, a.released, &StreamIdsExhaustedError{InFlight: a.inFlight, Available: available}
+}
+
+// Release returns the given stream id to the allocator. An error is returned if the stream id was not in use.
+func (a *StreamIdAllocator) Release(id int16) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.closed {
+		return errStreamIdAllocatorClosed
+	}
+	if id < 0 || !a.inUse[id] {
+		return fmt.Errorf("stream id %d: release failed, stream id not in use", id)
+	}
+	a.inUse[id] = false
+	a.inFlight--
+	close(a.released)
+	a.released = make(chan struct{})
+	return nil
+}
+
+// Close closes the allocator: waiting goroutines are woken up, and all subsequent operations fail.
+func (a *StreamIdAllocator) Close() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if !a.closed {
+		a.closed = true
+		close(a.released)
+	}
+}
+
+// streamIdAt returns the stream id at the given index: index i designates stream id i+1, except for the last stream id
+// of the protocol version, which designates stream id 0.
+func streamIdAt(index int, maxStreamIds int) int16 {
+	return int16((index + 1) % maxStreamIds)
+}
@@ -0,0 +1,203 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestNewStreamIdAllocator(t *testing.T) {
+	_, err := client.NewStreamIdAllocator(0)
+	assert.EqualError(t, err, "max in-flight: expecting positive, got: 0")
+	_, err = client.NewStreamIdAllocator(32769)
+	assert.EqualError(t, err, "max in-flight: expecting <= 32768, got: 32769")
+	allocator, err := client.NewStreamIdAllocator(32768)
+	require.NoError(t, err)
+	assert.Equal(t, 32768, allocator.MaxInFlight())
+}
+
+func TestStreamIdAllocator_RoundRobin(t *testing.T) {
+	allocator, _ := client.NewStreamIdAllocator(3)
+	for _, expected := range []int16{1, 2, 3} {
+		id, err := allocator.TryAcquire(primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, expected, id)
+	}
+	assert.Equal(t, 3, allocator.InFlight())
+	// released stream ids are not reused immediately
+	require.NoError(t, allocator.Release(1))
+	require.NoError(t, allocator.Release(3))
+	id, _ := allocator.TryAcquire(primitive.ProtocolVersion4)
+	assert.Equal(t, int16(1), id)
+	id, _ = allocator.TryAcquire(primitive.ProtocolVersion4)
+	assert.Equal(t, int16(3), id)
+	// released stream ids must be in use
+	assert.EqualError(t, allocator.Release(4), "stream id 4: release failed, stream id not in use")
+	assert.EqualError(t, allocator.Release(-1), "stream id -1: release failed, stream id not in use")
+}
+
+func TestStreamIdAllocator_Exhaustion(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxInFlight int
+		version     primitive.ProtocolVersion
+		available   int
+		last        int16
+	}{
+		{"v2", 1024, primitive.ProtocolVersion2, 128, 0},
+		{"v4", 32768, primitive.ProtocolVersion4, 32768, 0},
+		{"v4 limited", 1024, primitive.ProtocolVersion4, 1024, 1024},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocator, _ := client.NewStreamIdAllocator(tt.maxInFlight)
+			seen := make(map[int16]bool)
+			var id int16
+			var err error
+			for i := 0; i < tt.available; i++ {
+				id, err = allocator.TryAcquire(tt.version)
+				require.NoError(t, err)
+				require.False(t, seen[id])
+				require.True(t, id >= 0 && int(id) < client.MaxStreamIds(tt.version))
+				seen[id] = true
+			}
+			// stream id 0 comes last, and only if all the stream ids of the protocol version are usable
+			assert.Equal(t, tt.last, id)
+			_, err = allocator.TryAcquire(tt.version)
+			var exhausted *client.StreamIdsExhaustedError
+			require.True(t, errors.As(err, &exhausted))
+			assert.Equal(t, tt.available, exhausted.InFlight)
+			assert.Equal(t, tt.available, exhausted.Available)
+		})
+	}
+}
+
+func TestStreamIdAllocator_Acquire(t *testing.T) {
+	allocator, _ := client.NewStreamIdAllocator(1)
+	id, err := allocator.Acquire(context.Background(), primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, int16(1), id)
+
+	// times out when no stream id is released
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	_, err = allocator.Acquire(ctx, primitive.ProtocolVersion4)
+	cancel()
+	assert.EqualError(t, err, "no stream id available: 1 of 1 stream ids in use")
+
+	// waits until a stream id is released
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = allocator.Release(1)
+	}()
+	id, err = allocator.Acquire(context.Background(), primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, int16(1), id)
+
+	// closing the allocator wakes up waiting goroutines
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		allocator.Close()
+	}()
+	_, err = allocator.Acquire(context.Background(), primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "stream id allocator closed")
+	assert.EqualError(t, allocator.Release(1), "stream id allocator closed")
+}
+
+func TestStreamIdAllocator_Concurrent(t *testing.T) {
+	allocator, _ := client.NewStreamIdAllocator(16)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				id, err := allocator.Acquire(context.Background(), primitive.ProtocolVersion4)
+				assert.NoError(t, err)
+				assert.NoError(t, allocator.Release(id))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 0, allocator.InFlight())
+}
+
+func TestCqlClientConnection_StreamIdExhaustion(t *testing.T) {
+	// "hang" requests never get a response; "slow" requests get one after a short delay
+	handler := func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		if query, ok := request.Body.Message.(*message.Query); ok {
+			if query.Query == "slow" {
+				time.Sleep(100 * time.Millisecond)
+			} else {
+				return nil
+			}
+		}
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+	}
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{handler}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.MaxInFlight = 1
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+
+	// the only stream id is in use: requests fail immediately
+	hang, err := clientConn.SendWithTimeout(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "hang"}), 100*time.Millisecond)
+	require.NoError(t, err)
+	_, err = clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "hang"}))
+	var exhausted *client.StreamIdsExhaustedError
+	require.True(t, errors.As(err, &exhausted))
+	assert.Equal(t, 1, exhausted.InFlight)
+
+	// the per-request timeout applies
+	_, err = clientConn.Receive(hang)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out waiting for incoming frames")
+	cancelFn()
+	checkClosed(t, clientConn, server)
+
+	// with a stream id wait timeout, requests wait for the stream id to be released
+	server = client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{handler}
+	ctx, cancelFn = context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+	clt.StreamIdWaitTimeout = 5 * time.Second
+	clientConn, err = clt.Connect(ctx)
+	require.NoError(t, err)
+	slow, err := clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "slow"}))
+	require.NoError(t, err)
+	response, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "slow"}))
+	require.NoError(t, err)
+	assert.Equal(t, &message.VoidResult{}, response.Body.Message)
+	response, err = clientConn.Receive(slow)
+	require.NoError(t, err)
+	assert.Equal(t, &message.VoidResult{}, response.Body.Message)
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
@@ -16,11 +16,13 @@ package client
 
 import (
 	"bytes"
+	"fmt"
 	"net"
 	"strings"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
 	"github.com/datastax/go-cassandra-native-protocol/datatype"
 	"github.com/datastax/go-cassandra-native-protocol/frame"
 	"github.com/datastax/go-cassandra-native-protocol/message"
@@ -172,3 +174,17 @@ func emptySystemPeers(request *frame.Frame) *frame.Frame {
 	}
 	return frame.NewFrame(request.Header.Version, request.Header.StreamId, msg)
 }
+
+// encodeRow encodes the given values of the given columns with the default codecs.
+func encodeRow(columns []*message.ColumnMetadata, values []interface{}, version primitive.ProtocolVersion) (message.Row, error) {
+	row := make(message.Row, len(columns))
+	for i, column := range columns {
+		codec, err := datacodec.NewCodec(column.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %v: %w", column.Name, err)
+		} else if row[i], err = codec.Encode(values[i], version); err != nil {
+			return nil, fmt.Errorf("column %v: %w", column.Name, err)
+		}
+	}
+	return row, nil
+}
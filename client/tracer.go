@@ -0,0 +1,275 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Tracer completes the tracing loop on the server side, so that drivers can be tested against a CqlServer with
+// tracing enabled: when a request has the tracing flag set, the response produced by the wrapped handlers carries a
+// new tracing id, and a synthetic trace is stored for it; the trace can then be retrieved by querying the
+// system_traces.sessions and system_traces.events tables, e.g. with CqlClientConnection.FetchTraceReport. Use
+// Tracer.Wrap to create the request handler to register with the server.
+//
+// Only system_traces queries sent as QUERY requests, with the session id as the first positional value, are served,
+// which is how drivers typically fetch traces. A Tracer is safe for concurrent use.
+type Tracer struct {
+	// EventsFunc is an optional function generating the events of the trace of a request; by default, a few generic
+	// events are generated. The elapsed time of events should not exceed the given duration of the request.
+	EventsFunc func(request *frame.Frame, response *frame.Frame, duration time.Duration) []*TraceEvent
+
+	traces map[primitive.UUID]*TraceReport
+	lock   sync.RWMutex
+}
+
+// NewTracer creates a new Tracer, with no traces.
+func NewTracer() *Tracer {
+	return &Tracer{traces: make(map[primitive.UUID]*TraceReport)}
+}
+
+var (
+	tracerSessionsColumns = []*message.ColumnMetadata{
+		{Keyspace: "system_traces", Table: "sessions", Name: "session_id", Index: 0, Type: datatype.Uuid},
+		{Keyspace: "system_traces", Table: "sessions", Name: "client", Index: 1, Type: datatype.Inet},
+		{Keyspace: "system_traces", Table: "sessions", Name: "coordinator", Index: 2, Type: datatype.Inet},
+		{Keyspace: "system_traces", Table: "sessions", Name: "duration", Index: 3, Type: datatype.Int},
+		{Keyspace: "system_traces", Table: "sessions", Name: "parameters", Index: 4, Type: datatype.NewMap(datatype.Varchar, datatype.Varchar)},
+		{Keyspace: "system_traces", Table: "sessions", Name: "request", Index: 5, Type: datatype.Varchar},
+		{Keyspace: "system_traces", Table: "sessions", Name: "started_at", Index: 6, Type: datatype.Timestamp},
+	}
+	tracerEventsColumns = []*message.ColumnMetadata{
+		{Keyspace: "system_traces", Table: "events", Name: "session_id", Index: 0, Type: datatype.Uuid},
+		{Keyspace: "system_traces", Table: "events", Name: "event_id", Index: 1, Type: datatype.Timeuuid},
+		{Keyspace: "system_traces", Table: "events", Name: "activity", Index: 2, Type: datatype.Varchar},
+		{Keyspace: "system_traces", Table: "events", Name: "source", Index: 3, Type: datatype.Inet},
+		{Keyspace: "system_traces", Table: "events", Name: "source_elapsed", Index: 4, Type: datatype.Int},
+		{Keyspace: "system_traces", Table: "events", Name: "thread", Index: 5, Type: datatype.Varchar},
+	}
+)
+
+// Wrap returns a RequestHandler that serves system_traces queries for the traces stored by this tracer, and invokes
+// the given handlers in order for other requests, like NewCompositeRequestHandler; if the request has the tracing
+// flag set, a trace is stored for it, and its tracing id is set on the response.
+func (t *Tracer) Wrap(handlers ...RequestHandler) RequestHandler {
+	handler := NewCompositeRequestHandler(handlers...)
+	return func(request *frame.Frame, conn *CqlServerConnection, ctx RequestHandlerContext) *frame.Frame {
+		if response := t.handleTraceQuery(request); response != nil {
+			return response
+		}
+		startedAt := time.Now()
+		response := handler(request, conn, ctx)
+		if response != nil && request.Header.Flags.Contains(primitive.HeaderFlagTracing) {
+			tracingId := primitive.NewTimeUuid()
+			t.addTrace(tracingId, request, response, conn, startedAt, time.Since(startedAt))
+			response.SetTracingId(tracingId)
+		}
+		return response
+	}
+}
+
+// Trace returns the trace stored for the given tracing id, or nil if none.
+func (t *Tracer) Trace(tracingId *primitive.UUID) *TraceReport {
+	if tracingId == nil {
+		return nil
+	}
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.traces[*tracingId]
+}
+
+func (t *Tracer) addTrace(
+	tracingId *primitive.UUID,
+	request *frame.Frame,
+	response *frame.Frame,
+	conn *CqlServerConnection,
+	startedAt time.Time,
+	duration time.Duration,
+) {
+	// durations are stored in microseconds; the trace is only complete once its duration is set, so make sure it is
+	// not zero
+	if duration = duration.Truncate(time.Microsecond); duration < time.Microsecond {
+		duration = time.Microsecond
+	}
+	report := &TraceReport{
+		TracingId:   tracingId,
+		Request:     traceRequestDescription(request),
+		Coordinator: addrIP(conn.LocalAddr()),
+		Client:      addrIP(conn.RemoteAddr()),
+		Duration:    duration,
+		StartedAt:   startedAt.Truncate(time.Millisecond),
+		Parameters:  traceRequestParameters(request),
+	}
+	if t.EventsFunc != nil {
+		report.Events = t.EventsFunc(request, response, duration)
+	} else {
+		report.Events = defaultTraceEvents(request, report.Coordinator, duration)
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.traces[*tracingId] = report
+}
+
+// handleTraceQuery returns the rows of the system_traces table targeted by the given request, or nil if it is not a
+// query for such a table.
+func (t *Tracer) handleTraceQuery(request *frame.Frame) *frame.Frame {
+	query, ok := request.Body.Message.(*message.Query)
+	if !ok || query.Options == nil || len(query.Options.PositionalValues) == 0 {
+		return nil
+	}
+	queryString := strings.ToLower(query.Query)
+	var sessions bool
+	if strings.Contains(queryString, "system_traces.sessions") {
+		sessions = true
+	} else if !strings.Contains(queryString, "system_traces.events") {
+		return nil
+	}
+	version := request.Header.Version
+	tracingId := &primitive.UUID{}
+	if contents := query.Options.PositionalValues[0].Contents; len(contents) == len(tracingId) {
+		copy(tracingId[:], contents)
+	}
+	var metadata *message.RowsMetadata
+	var rows message.RowSet
+	var err error
+	if sessions {
+		metadata = &message.RowsMetadata{ColumnCount: int32(len(tracerSessionsColumns)), Columns: tracerSessionsColumns}
+		if report := t.Trace(tracingId); report != nil {
+			var row message.Row
+			if row, err = encodeTraceSessionRow(report, version); err == nil {
+				rows = message.RowSet{row}
+			}
+		}
+	} else {
+		metadata = &message.RowsMetadata{ColumnCount: int32(len(tracerEventsColumns)), Columns: tracerEventsColumns}
+		if report := t.Trace(tracingId); report != nil {
+			for _, event := range report.Events {
+				var row message.Row
+				if row, err = encodeTraceEventRow(report.TracingId, event, version); err != nil {
+					break
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	if err != nil {
+		return frame.NewFrame(version, request.Header.StreamId, &message.ServerError{
+			ErrorMessage: fmt.Sprintf("cannot encode trace %v: %v", tracingId, err),
+		})
+	}
+	return frame.NewFrame(version, request.Header.StreamId, &message.RowsResult{Metadata: metadata, Data: rows})
+}
+
+func encodeTraceSessionRow(report *TraceReport, version primitive.ProtocolVersion) (message.Row, error) {
+	return encodeRow(tracerSessionsColumns, []interface{}{
+		report.TracingId,
+		report.Client,
+		report.Coordinator,
+		int32(report.Duration / time.Microsecond),
+		report.Parameters,
+		report.Request,
+		report.StartedAt,
+	}, version)
+}
+
+func encodeTraceEventRow(tracingId *primitive.UUID, event *TraceEvent, version primitive.ProtocolVersion) (message.Row, error) {
+	return encodeRow(tracerEventsColumns, []interface{}{
+		tracingId,
+		event.EventId,
+		event.Activity,
+		event.Source,
+		int32(event.SourceElapsed / time.Microsecond),
+		event.Thread,
+	}, version)
+}
+
+// traceRequestDescription returns a short description of the given request, as found in the request column of the
+// system_traces.sessions table.
+func traceRequestDescription(request *frame.Frame) string {
+	switch request.Header.OpCode {
+	case primitive.OpCodeQuery:
+		return "Execute CQL3 query"
+	case primitive.OpCodeExecute:
+		return "Execute CQL3 prepared query"
+	case primitive.OpCodeBatch:
+		return "Execute batch of CQL3 queries"
+	case primitive.OpCodePrepare:
+		return "Preparing CQL3 query"
+	}
+	return fmt.Sprintf("Execute %v request", request.Header.OpCode)
+}
+
+// traceRequestParameters returns the parameters of the given request, as found in the parameters column of the
+// system_traces.sessions table.
+func traceRequestParameters(request *frame.Frame) map[string]string {
+	parameters := make(map[string]string)
+	var options *message.QueryOptions
+	switch msg := request.Body.Message.(type) {
+	case *message.Query:
+		parameters["query"] = msg.Query
+		options = msg.Options
+	case *message.Execute:
+		options = msg.Options
+	case *message.Prepare:
+		parameters["query"] = msg.Query
+	case *message.Batch:
+		parameters["consistency_level"] = msg.Consistency.String()
+	}
+	if options != nil {
+		parameters["consistency_level"] = options.Consistency.String()
+		if options.SerialConsistency != nil {
+			parameters["serial_consistency_level"] = options.SerialConsistency.String()
+		}
+		if options.PageSize > 0 {
+			parameters["page_size"] = fmt.Sprint(options.PageSize)
+		}
+	}
+	return parameters
+}
+
+func defaultTraceEvents(request *frame.Frame, source net.IP, duration time.Duration) []*TraceEvent {
+	activities := []string{fmt.Sprintf("Processing %v request", request.Header.OpCode)}
+	if query, ok := request.Body.Message.(*message.Query); ok {
+		activities = []string{"Parsing " + query.Query, "Preparing statement"}
+	}
+	activities = append(activities, "Request complete")
+	events := make([]*TraceEvent, len(activities))
+	for i, activity := range activities {
+		events[i] = &TraceEvent{
+			EventId:       *primitive.NewTimeUuid(),
+			Activity:      activity,
+			Source:        source,
+			SourceElapsed: (duration * time.Duration(i) / time.Duration(len(activities)-1)).Truncate(time.Microsecond),
+			Thread:        "Native-Transport-Requests-1",
+		}
+	}
+	return events
+}
+
+func addrIP(addr net.Addr) net.IP {
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		return tcpAddr.IP
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestTracer(t *testing.T) {
+	tracer := client.NewTracer()
+	handler := func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		if _, ok := request.Body.Message.(*message.Query); ok {
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+		}
+		return nil
+	}
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{tracer.Wrap(handler)}, nil)
+
+	request := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{
+		Query:   "INSERT INTO ks.t1 (pk) VALUES (1)",
+		Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevelQuorum},
+	})
+	response, report, err := clientConn.SendAndReceiveTraced(request)
+	require.NoError(t, err)
+	assert.Equal(t, &message.VoidResult{}, response.Body.Message)
+	require.NotNil(t, report)
+	assert.Equal(t, response.Body.TracingId, report.TracingId)
+	assert.Equal(t, "Execute CQL3 query", report.Request)
+	assert.True(t, net.IPv4(127, 0, 0, 1).Equal(report.Coordinator))
+	assert.True(t, net.IPv4(127, 0, 0, 1).Equal(report.Client))
+	assert.Greater(t, int64(report.Duration), int64(0))
+	assert.WithinDuration(t, time.Now(), report.StartedAt, time.Minute)
+	assert.Equal(t, map[string]string{
+		"query":             "INSERT INTO ks.t1 (pk) VALUES (1)",
+		"consistency_level": primitive.ConsistencyLevelQuorum.String(),
+	}, report.Parameters)
+	require.Len(t, report.Events, 3)
+	assert.Equal(t, "Parsing INSERT INTO ks.t1 (pk) VALUES (1)", report.Events[0].Activity)
+	assert.Equal(t, "Request complete", report.Events[2].Activity)
+	assert.Equal(t, report.Duration, report.Events[2].SourceElapsed)
+	assert.Equal(t, tracer.Trace(report.TracingId).Events, report.Events)
+
+	// requests without the tracing flag are not traced
+	request = frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "SELECT"})
+	response, err = clientConn.SendAndReceive(request)
+	require.NoError(t, err)
+	assert.Nil(t, response.Body.TracingId)
+
+	// unknown traces are not found
+	unknown := primitive.UUID{1, 2, 3}
+	report, err = clientConn.FetchTraceReport(primitive.ProtocolVersion4, client.ManagedStreamId, &unknown, 1, time.Millisecond)
+	assert.Nil(t, report)
+	assert.Error(t, err)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
@@ -0,0 +1,259 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+const (
+	// DefaultTraceFetchAttempts is the number of times FetchTraceReport queries the system_traces.sessions table
+	// before giving up, if the trace is not complete yet.
+	DefaultTraceFetchAttempts = 5
+	// DefaultTraceFetchInterval is the delay between two attempts of FetchTraceReport.
+	DefaultTraceFetchInterval = 100 * time.Millisecond
+)
+
+const (
+	traceSessionQuery = "SELECT * FROM system_traces.sessions WHERE session_id = ?"
+	traceEventsQuery  = "SELECT * FROM system_traces.events WHERE session_id = ?"
+)
+
+// TraceReport is the decoded content of a query trace, as stored by the server in the system_traces keyspace.
+type TraceReport struct {
+	TracingId *primitive.UUID
+	// Request is a short description of the traced request, e.g. "Execute CQL3 query".
+	Request string
+	// Coordinator is the address of the node that coordinated the request.
+	Coordinator net.IP
+	// Client is the address of the client that issued the request; it is only available with Cassandra 3.0+.
+	Client net.IP
+	// Duration is the total duration of the request, as measured by the coordinator.
+	Duration time.Duration
+	// StartedAt is the time at which the coordinator started processing the request.
+	StartedAt time.Time
+	// Parameters contains request parameters such as the query string and the consistency level.
+	Parameters map[string]string
+	// Events contains the trace events, in the order they were returned by the server.
+	Events []*TraceEvent
+}
+
+// TraceEvent is a single event of a query trace.
+type TraceEvent struct {
+	EventId  primitive.UUID
+	Activity string
+	// Source is the address of the node where the event occurred.
+	Source net.IP
+	// SourceElapsed is the time elapsed on the source node since it started processing the request.
+	SourceElapsed time.Duration
+	Thread        string
+}
+
+func (r *TraceReport) String() string {
+	return fmt.Sprintf("{tracing id: %v, request: %v, coordinator: %v, duration: %v, events: %d}",
+		r.TracingId, r.Request, r.Coordinator, r.Duration, len(r.Events))
+}
+
+// SendAndReceiveTraced sends the given request with the tracing flag set, waits for its response, then retrieves the
+// corresponding trace with FetchTraceReport, using default attempts and interval. The trace is fetched with the same
+// stream id as the request, using the request's protocol version. If the response does not contain a tracing id, an
+// error is returned along with the response.
+func (c *CqlClientConnection) SendAndReceiveTraced(f *frame.Frame) (*frame.Frame, *TraceReport, error) {
+	if f == nil {
+		return nil, nil, fmt.Errorf("%v: frame cannot be nil", c)
+	}
+	// capture the stream id before it is possibly assigned by the connection
+	streamId := f.Header.StreamId
+	f.RequestTracingId(true)
+	response, err := c.SendAndReceive(f)
+	if err != nil {
+		return nil, nil, err
+	} else if response.Body.TracingId == nil {
+		return response, nil, fmt.Errorf("%v: response does not contain a tracing id: %v", c, response)
+	}
+	report, err := c.FetchTraceReport(
+		f.Header.Version,
+		streamId,
+		response.Body.TracingId,
+		DefaultTraceFetchAttempts,
+		DefaultTraceFetchInterval,
+	)
+	return response, report, err
+}
+
+// FetchTraceReport queries the system_traces.sessions and system_traces.events tables and decodes the trace with the
+// given tracing id. Traces are written asynchronously by the server, so the sessions table is queried up to the given
+// number of attempts, waiting for the given interval in between, until the trace is complete, that is, until its
+// duration is known.
+func (c *CqlClientConnection) FetchTraceReport(
+	version primitive.ProtocolVersion,
+	streamId int16,
+	tracingId *primitive.UUID,
+	attempts int,
+	interval time.Duration,
+) (*TraceReport, error) {
+	if tracingId == nil {
+		return nil, fmt.Errorf("%v: tracing id cannot be nil", c)
+	}
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(interval):
+			case <-c.ctx.Done():
+				return nil, fmt.Errorf("%v: cannot fetch trace %v: connection closed", c, tracingId)
+			}
+		}
+		rows, err := c.queryTrace(version, streamId, traceSessionQuery, tracingId)
+		if err != nil {
+			return nil, err
+		} else if len(rows.Data) == 0 {
+			continue
+		}
+		report := &TraceReport{TracingId: tracingId}
+		var duration int32
+		decoder := newTraceRowDecoder(rows.Metadata)
+		// a trace is complete once its duration is set
+		if complete, err := decoder.decodeColumn(rows.Data[0], "duration", &duration, version); err != nil {
+			return nil, fmt.Errorf("%v: cannot decode trace %v: %w", c, tracingId, err)
+		} else if !complete {
+			continue
+		} else if err := decoder.decode(rows.Data[0], map[string]interface{}{
+			"request":     &report.Request,
+			"coordinator": &report.Coordinator,
+			"client":      &report.Client,
+			"started_at":  &report.StartedAt,
+			"parameters":  &report.Parameters,
+		}, version); err != nil {
+			return nil, fmt.Errorf("%v: cannot decode trace %v: %w", c, tracingId, err)
+		}
+		report.Duration = time.Duration(duration) * time.Microsecond
+		if report.Events, err = c.fetchTraceEvents(version, streamId, tracingId); err != nil {
+			return nil, err
+		}
+		return report, nil
+	}
+	return nil, fmt.Errorf("%v: trace %v not found or incomplete after %d attempts", c, tracingId, attempts)
+}
+
+func (c *CqlClientConnection) fetchTraceEvents(
+	version primitive.ProtocolVersion,
+	streamId int16,
+	tracingId *primitive.UUID,
+) ([]*TraceEvent, error) {
+	rows, err := c.queryTrace(version, streamId, traceEventsQuery, tracingId)
+	if err != nil {
+		return nil, err
+	}
+	decoder := newTraceRowDecoder(rows.Metadata)
+	events := make([]*TraceEvent, 0, len(rows.Data))
+	for i, row := range rows.Data {
+		event := &TraceEvent{}
+		var sourceElapsed int32
+		if err := decoder.decode(row, map[string]interface{}{
+			"event_id":       &event.EventId,
+			"activity":       &event.Activity,
+			"source":         &event.Source,
+			"source_elapsed": &sourceElapsed,
+			"thread":         &event.Thread,
+		}, version); err != nil {
+			return nil, fmt.Errorf("%v: cannot decode event %d of trace %v: %w", c, i, tracingId, err)
+		}
+		event.SourceElapsed = time.Duration(sourceElapsed) * time.Microsecond
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (c *CqlClientConnection) queryTrace(
+	version primitive.ProtocolVersion,
+	streamId int16,
+	query string,
+	tracingId *primitive.UUID,
+) (*message.RowsResult, error) {
+	request := frame.NewFrame(version, streamId, &message.Query{
+		Query: query,
+		Options: &message.QueryOptions{
+			Consistency:      primitive.ConsistencyLevelOne,
+			PositionalValues: []*primitive.Value{primitive.NewValue(tracingId[:])},
+		},
+	})
+	response, err := c.SendAndReceive(request)
+	if err != nil {
+		return nil, fmt.Errorf("%v: cannot fetch trace %v: %w", c, tracingId, err)
+	}
+	rows, ok := response.Body.Message.(*message.RowsResult)
+	if !ok {
+		return nil, fmt.Errorf("%v: cannot fetch trace %v: expected ROWS, got: %v", c, tracingId, response.Body.Message)
+	}
+	return rows, nil
+}
+
+// traceRowDecoder decodes trace columns by name; columns absent from the result set are ignored, since the
+// system_traces tables vary slightly between server versions.
+type traceRowDecoder struct {
+	columns map[string]*message.ColumnMetadata
+	indices map[string]int
+}
+
+func newTraceRowDecoder(metadata *message.RowsMetadata) *traceRowDecoder {
+	decoder := &traceRowDecoder{
+		columns: make(map[string]*message.ColumnMetadata),
+		indices: make(map[string]int),
+	}
+	if metadata != nil {
+		for i, column := range metadata.Columns {
+			decoder.columns[column.Name] = column
+			decoder.indices[column.Name] = i
+		}
+	}
+	return decoder
+}
+
+func (d *traceRowDecoder) decode(row message.Row, destinations map[string]interface{}, version primitive.ProtocolVersion) error {
+	for name, dest := range destinations {
+		if _, err := d.decodeColumn(row, name, dest, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeColumn decodes the given column into dest, and reports whether the column was found and non-null.
+func (d *traceRowDecoder) decodeColumn(row message.Row, name string, dest interface{}, version primitive.ProtocolVersion) (bool, error) {
+	column, found := d.columns[name]
+	if !found {
+		return false, nil
+	}
+	index := d.indices[name]
+	if index >= len(row) {
+		return false, fmt.Errorf("column %v: row has only %d values", name, len(row))
+	}
+	codec, err := datacodec.NewCodec(column.Type)
+	if err != nil {
+		return false, fmt.Errorf("column %v: %w", name, err)
+	}
+	wasNull, err := codec.Decode(row[index], dest, version)
+	if err != nil {
+		return false, fmt.Errorf("column %v: %w", name, err)
+	}
+	return !wasNull, nil
+}
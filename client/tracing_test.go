@@ -0,0 +1,169 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+var (
+	traceId       = primitive.UUID{0xc0, 0xd1, 0xd2, 0x1e, 0xbb, 0x01, 0x41, 0x96, 0x86, 0xdb, 0xbc, 0x31, 0x7b, 0xc1, 0x79, 0x6a}
+	traceEventId  = primitive.UUID{0x0d, 0x9e, 0x8f, 0x50, 0x6b, 0xb4, 0x11, 0xeb, 0x94, 0x39, 0x02, 0x42, 0xac, 0x13, 0x00, 0x02}
+	traceStart    = time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	traceSessions = &message.RowsMetadata{
+		ColumnCount: 6,
+		Columns: []*message.ColumnMetadata{
+			{Keyspace: "system_traces", Table: "sessions", Name: "session_id", Type: datatype.Uuid},
+			{Keyspace: "system_traces", Table: "sessions", Name: "coordinator", Type: datatype.Inet},
+			{Keyspace: "system_traces", Table: "sessions", Name: "duration", Type: datatype.Int},
+			{Keyspace: "system_traces", Table: "sessions", Name: "parameters", Type: datatype.NewMap(datatype.Varchar, datatype.Varchar)},
+			{Keyspace: "system_traces", Table: "sessions", Name: "request", Type: datatype.Varchar},
+			{Keyspace: "system_traces", Table: "sessions", Name: "started_at", Type: datatype.Timestamp},
+		},
+	}
+	traceEvents = &message.RowsMetadata{
+		ColumnCount: 5,
+		Columns: []*message.ColumnMetadata{
+			{Keyspace: "system_traces", Table: "events", Name: "event_id", Type: datatype.Timeuuid},
+			{Keyspace: "system_traces", Table: "events", Name: "activity", Type: datatype.Varchar},
+			{Keyspace: "system_traces", Table: "events", Name: "source", Type: datatype.Inet},
+			{Keyspace: "system_traces", Table: "events", Name: "source_elapsed", Type: datatype.Int},
+			{Keyspace: "system_traces", Table: "events", Name: "thread", Type: datatype.Varchar},
+		},
+	}
+)
+
+// newTracingHandler returns a handler that traces all queries except those to system_traces; the trace becomes
+// complete after the given number of lookups of system_traces.sessions.
+func newTracingHandler(t *testing.T, incompleteLookups int32) client.RequestHandler {
+	lookups := int32(0)
+	encode := func(dt datatype.DataType, value interface{}) message.Column {
+		codec, err := datacodec.NewCodec(dt)
+		require.NoError(t, err)
+		encoded, err := codec.Encode(value, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		return encoded
+	}
+	return func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		query, ok := request.Body.Message.(*message.Query)
+		if !ok {
+			return nil
+		}
+		switch {
+		case strings.Contains(query.Query, "system_traces.sessions"):
+			assert.Equal(t, message.Column(traceId[:]), message.Column(query.Options.PositionalValues[0].Contents))
+			var duration interface{}
+			if atomic.AddInt32(&lookups, 1) > incompleteLookups {
+				duration = int32(1500)
+			}
+			row := message.Row{
+				encode(datatype.Uuid, traceId),
+				encode(datatype.Inet, net.IPv4(127, 0, 0, 1)),
+				encode(datatype.Int, duration),
+				encode(datatype.NewMap(datatype.Varchar, datatype.Varchar), map[string]string{"consistency_level": "ONE"}),
+				encode(datatype.Varchar, "Execute CQL3 query"),
+				encode(datatype.Timestamp, traceStart),
+			}
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.RowsResult{Metadata: traceSessions, Data: message.RowSet{row}})
+		case strings.Contains(query.Query, "system_traces.events"):
+			row := message.Row{
+				encode(datatype.Timeuuid, traceEventId),
+				encode(datatype.Varchar, "Parsing query"),
+				encode(datatype.Inet, net.IPv4(127, 0, 0, 2)),
+				encode(datatype.Int, int32(250)),
+				encode(datatype.Varchar, "Native-Transport-Requests-1"),
+			}
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.RowsResult{Metadata: traceEvents, Data: message.RowSet{row}})
+		default:
+			response := frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+			if request.Header.Flags.Contains(primitive.HeaderFlagTracing) {
+				id := traceId
+				response.SetTracingId(&id)
+			}
+			return response
+		}
+	}
+}
+
+func TestCqlClientConnection_SendAndReceiveTraced(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{newTracingHandler(t, 1)}, nil)
+
+	request := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "INSERT INTO ks.t1 (pk) VALUES (1)"})
+	response, report, err := clientConn.SendAndReceiveTraced(request)
+	require.NoError(t, err)
+	assert.Equal(t, &message.VoidResult{}, response.Body.Message)
+	assert.True(t, request.Header.Flags.Contains(primitive.HeaderFlagTracing))
+	require.NotNil(t, report)
+	assert.Equal(t, &traceId, report.TracingId)
+	assert.Equal(t, "Execute CQL3 query", report.Request)
+	assert.True(t, net.IPv4(127, 0, 0, 1).Equal(report.Coordinator))
+	assert.Nil(t, report.Client)
+	assert.Equal(t, 1500*time.Microsecond, report.Duration)
+	assert.True(t, traceStart.Equal(report.StartedAt))
+	assert.Equal(t, map[string]string{"consistency_level": "ONE"}, report.Parameters)
+	require.Len(t, report.Events, 1)
+	assert.Equal(t, traceEventId, report.Events[0].EventId)
+	assert.Equal(t, "Parsing query", report.Events[0].Activity)
+	assert.True(t, net.IPv4(127, 0, 0, 2).Equal(report.Events[0].Source))
+	assert.Equal(t, 250*time.Microsecond, report.Events[0].SourceElapsed)
+	assert.Equal(t, "Native-Transport-Requests-1", report.Events[0].Thread)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestCqlClientConnection_FetchTraceReport_Incomplete(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{newTracingHandler(t, 10)}, nil)
+
+	id := traceId
+	report, err := clientConn.FetchTraceReport(primitive.ProtocolVersion4, client.ManagedStreamId, &id, 2, time.Millisecond)
+	assert.Nil(t, report)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found or incomplete after 2 attempts")
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestCqlClientConnection_SendAndReceiveTraced_NoTracingId(t *testing.T) {
+	handler := func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+	}
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{handler}, nil)
+
+	request := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "SELECT"})
+	response, report, err := clientConn.SendAndReceiveTraced(request)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "response does not contain a tracing id")
+	assert.NotNil(t, response)
+	assert.Nil(t, report)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
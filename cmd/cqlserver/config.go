@@ -0,0 +1,383 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// config is the root of a simulator configuration file. Since JSON is a subset of YAML, configuration files can be
+// written in either format.
+type config struct {
+	Cluster     string            `yaml:"cluster"`
+	Datacenter  string            `yaml:"datacenter"`
+	Credentials *credentials      `yaml:"credentials"`
+	Nodes       []*node           `yaml:"nodes"`
+	Schema      []*table          `yaml:"schema"`
+	Responses   []*cannedResponse `yaml:"responses"`
+	Chaos       *chaos            `yaml:"chaos"`
+}
+
+type credentials struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// node is a simulated node; each node listens on its own address.
+type node struct {
+	Address        string `yaml:"address"`
+	MaxConnections int    `yaml:"max_connections"`
+	MaxInFlight    int    `yaml:"max_in_flight"`
+}
+
+// table declares the columns of a table, so that canned responses can refer to it instead of declaring their own
+// columns.
+type table struct {
+	Keyspace string    `yaml:"keyspace"`
+	Name     string    `yaml:"name"`
+	Columns  []*column `yaml:"columns"`
+}
+
+type column struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+// cannedResponse is a primed response for QUERY requests whose query string matches a regular expression. Exactly one
+// of Rows (with either Table or Columns), Error, Message or NoResponse should be provided; if none is provided, a VOID
+// result is returned. Message is any response message, in the representation of message.UnmarshalMessageJSON.
+type cannedResponse struct {
+	Query      string          `yaml:"query"`
+	Delay      time.Duration   `yaml:"delay"`
+	NoResponse bool            `yaml:"no_response"`
+	Table      string          `yaml:"table"`
+	Columns    []*column       `yaml:"columns"`
+	Rows       [][]interface{} `yaml:"rows"`
+	Error      *cannedError    `yaml:"error"`
+	Message    interface{}     `yaml:"message"`
+}
+
+// cannedError describes an error response. Consistency, Received, BlockFor, Required, Alive and WriteType are only
+// relevant for unavailable, read_timeout and write_timeout errors.
+type cannedError struct {
+	Type        string                      `yaml:"type"`
+	Message     string                      `yaml:"message"`
+	Consistency *primitive.ConsistencyLevel `yaml:"consistency"`
+	Received    int32                       `yaml:"received"`
+	BlockFor    int32                       `yaml:"block_for"`
+	Required    int32                       `yaml:"required"`
+	Alive       int32                       `yaml:"alive"`
+	WriteType   primitive.WriteType         `yaml:"write_type"`
+}
+
+// chaos holds settings to degrade the simulated nodes: Latency is added to every response, and DropRate is the
+// probability, between 0 and 1, of never responding to a request. Only QUERY, PREPARE, EXECUTE and BATCH requests are
+// affected, so that drivers can still establish connections.
+type chaos struct {
+	Latency  time.Duration `yaml:"latency"`
+	DropRate float64       `yaml:"drop_rate"`
+}
+
+// loadConfig reads and validates the configuration file at the given path.
+func loadConfig(path string) (*config, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file: %w", err)
+	}
+	return parseConfig(contents)
+}
+
+// parseConfig parses and validates the given YAML or JSON configuration, and applies default values.
+func parseConfig(contents []byte) (*config, error) {
+	cfg := &config{}
+	if err := yaml.Unmarshal(contents, cfg); err != nil {
+		return nil, fmt.Errorf("cannot parse config: %w", err)
+	}
+	if cfg.Cluster == "" {
+		cfg.Cluster = "cluster1"
+	}
+	if cfg.Datacenter == "" {
+		cfg.Datacenter = "dc1"
+	}
+	if len(cfg.Nodes) == 0 {
+		cfg.Nodes = []*node{{Address: "127.0.0.1:9042"}}
+	}
+	for i, n := range cfg.Nodes {
+		if n == nil || n.Address == "" {
+			return nil, fmt.Errorf("node %d: address is required", i)
+		} else if n.MaxConnections < 0 || n.MaxInFlight < 0 {
+			return nil, fmt.Errorf("node %s: max connections and max in-flight cannot be negative", n.Address)
+		}
+	}
+	if cfg.Chaos != nil && (cfg.Chaos.DropRate < 0 || cfg.Chaos.DropRate > 1) {
+		return nil, fmt.Errorf("chaos: drop rate must be between 0 and 1, got: %v", cfg.Chaos.DropRate)
+	}
+	return cfg, nil
+}
+
+// newServers creates one CqlServer per configured node, each one with the same request handlers.
+func (cfg *config) newServers() ([]*client.CqlServer, error) {
+	handlers, err := cfg.requestHandlers()
+	if err != nil {
+		return nil, err
+	}
+	var creds *client.AuthCredentials
+	if cfg.Credentials != nil {
+		creds = &client.AuthCredentials{Username: cfg.Credentials.Username, Password: cfg.Credentials.Password}
+	}
+	servers := make([]*client.CqlServer, len(cfg.Nodes))
+	for i, n := range cfg.Nodes {
+		server := client.NewCqlServer(n.Address, creds)
+		if n.MaxConnections > 0 {
+			server.MaxConnections = n.MaxConnections
+		}
+		if n.MaxInFlight > 0 {
+			server.MaxInFlight = n.MaxInFlight
+		}
+		server.RequestHandlers = handlers
+		servers[i] = server
+	}
+	return servers, nil
+}
+
+// requestHandlers returns the handlers for the configured chaos settings and canned responses, followed by the
+// handlers required to initialize driver connections.
+func (cfg *config) requestHandlers() ([]client.RequestHandler, error) {
+	var handlers []client.RequestHandler
+	if cfg.Chaos != nil && (cfg.Chaos.Latency > 0 || cfg.Chaos.DropRate > 0) {
+		handlers = append(handlers, cfg.Chaos.handler())
+	}
+	tables := make(map[string]*table, len(cfg.Schema))
+	for _, t := range cfg.Schema {
+		tables[t.Keyspace+"."+t.Name] = t
+	}
+	engine := client.NewRuleEngine()
+	for i, response := range cfg.Responses {
+		if rule, err := response.rule(tables); err != nil {
+			return nil, fmt.Errorf("response %d: %w", i, err)
+		} else {
+			engine.Prime(rule)
+		}
+	}
+	handlers = append(handlers, engine.Handler())
+	handlers = append(handlers, client.NewDriverConnectionInitializationHandler(cfg.Cluster, cfg.Datacenter, func(string) {}))
+	handlers = append(handlers, voidResultHandler)
+	return handlers, nil
+}
+
+// voidResultHandler replies with a VOID result to all QUERY requests; it is meant to be the last handler invoked.
+var voidResultHandler client.RequestHandler = func(request *frame.Frame, _ *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+	if _, ok := request.Body.Message.(*message.Query); ok {
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+	}
+	return nil
+}
+
+// handler returns a RequestHandler that delays and drops requests, then lets the next handlers process them.
+func (c *chaos) handler() client.RequestHandler {
+	return func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		switch request.Header.OpCode {
+		case primitive.OpCodeQuery, primitive.OpCodePrepare, primitive.OpCodeExecute, primitive.OpCodeBatch:
+		default:
+			return nil
+		}
+		if c.DropRate > 0 && rand.Float64() < c.DropRate {
+			return client.NoResponse(request, conn)
+		}
+		if c.Latency > 0 {
+			time.Sleep(c.Latency)
+		}
+		return nil
+	}
+}
+
+func (r *cannedResponse) rule(tables map[string]*table) (*client.Rule, error) {
+	if r.Query == "" {
+		return nil, fmt.Errorf("query is required")
+	} else if _, err := regexp.Compile(r.Query); err != nil {
+		return nil, fmt.Errorf("invalid query expression: %w", err)
+	}
+	rule := client.NewRule(client.MatchOpCode(primitive.OpCodeQuery), client.MatchQuery(r.Query))
+	if r.NoResponse {
+		return rule.ThenNoResponse(), nil
+	}
+	var action client.RuleAction
+	if r.Error != nil {
+		msg, err := r.Error.message()
+		if err != nil {
+			return nil, err
+		}
+		action = client.RespondWith(msg)
+	} else if r.Message != nil {
+		msg, err := parseMessage(r.Message)
+		if err != nil {
+			return nil, err
+		}
+		action = client.RespondWith(msg)
+	} else if r.Table != "" || len(r.Columns) > 0 {
+		var err error
+		if action, err = r.rowsAction(tables); err != nil {
+			return nil, err
+		}
+	} else {
+		action = client.RespondWith(&message.VoidResult{})
+	}
+	if r.Delay > 0 {
+		action = delayed(r.Delay, action)
+	}
+	return rule.Then(action), nil
+}
+
+// parseMessage decodes a response message from its YAML representation, which is the same as its JSON one.
+func parseMessage(decoded interface{}) (message.Message, error) {
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	msg, err := message.UnmarshalMessageJSON(data)
+	if err != nil {
+		return nil, err
+	} else if msg == nil || !msg.IsResponse() {
+		return nil, fmt.Errorf("invalid message: expecting response, got: %v", msg)
+	}
+	return msg, nil
+}
+
+// rowsAction returns a RuleAction responding with the canned rows, encoded with the request's protocol version.
+func (r *cannedResponse) rowsAction(tables map[string]*table) (client.RuleAction, error) {
+	keyspace, tableName, columns := "", "", r.Columns
+	if r.Table != "" {
+		t, found := tables[r.Table]
+		if !found {
+			return nil, fmt.Errorf("unknown table: %s", r.Table)
+		}
+		keyspace, tableName, columns = t.Keyspace, t.Name, t.Columns
+	}
+	metadata := &message.RowsMetadata{ColumnCount: int32(len(columns))}
+	codecs := make([]datacodec.Codec, len(columns))
+	for i, col := range columns {
+		dt, err := parseDataType(col.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", col.Name, err)
+		}
+		metadata.Columns = append(metadata.Columns, &message.ColumnMetadata{
+			Keyspace: keyspace,
+			Table:    tableName,
+			Name:     col.Name,
+			Index:    int32(i),
+			Type:     dt,
+		})
+		if codecs[i], err = datacodec.NewCodec(dt); err != nil {
+			return nil, fmt.Errorf("column %s: %w", col.Name, err)
+		}
+	}
+	for i, row := range r.Rows {
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("row %d: expected %d values, got: %d", i, len(columns), len(row))
+		}
+	}
+	return func(request *frame.Frame, _ *client.CqlServerConnection) *frame.Frame {
+		version := request.Header.Version
+		data := make(message.RowSet, 0, len(r.Rows))
+		for _, row := range r.Rows {
+			encoded := make(message.Row, len(row))
+			for i, value := range row {
+				var err error
+				if encoded[i], err = codecs[i].Encode(value, version); err != nil {
+					msg := message.NewServerError(fmt.Sprintf("cannot encode column %s: %v", columns[i].Name, err))
+					return frame.NewFrame(version, request.Header.StreamId, msg)
+				}
+			}
+			data = append(data, encoded)
+		}
+		return frame.NewFrame(version, request.Header.StreamId, &message.RowsResult{Metadata: metadata, Data: data})
+	}, nil
+}
+
+func (e *cannedError) message() (message.Message, error) {
+	var msg message.Message
+	var err error
+	switch strings.ToLower(e.Type) {
+	case "server_error":
+		msg = message.NewServerError(e.Message)
+	case "overloaded":
+		msg = message.NewOverloaded(e.Message)
+	case "is_bootstrapping":
+		msg = message.NewIsBootstrapping(e.Message)
+	case "truncate_error":
+		msg = message.NewTruncateError(e.Message)
+	case "syntax_error":
+		msg = message.NewSyntaxError(e.Message)
+	case "unauthorized":
+		msg = message.NewUnauthorized(e.Message)
+	case "invalid":
+		msg = message.NewInvalid(e.Message)
+	case "config_error":
+		msg = message.NewConfigError(e.Message)
+	case "unavailable":
+		msg, err = message.NewUnavailable(e.consistency(), e.Required, e.Alive)
+	case "read_timeout":
+		msg, err = message.NewReadTimeout(e.consistency(), e.Received, e.BlockFor, false)
+	case "write_timeout":
+		writeType := e.WriteType
+		if writeType == "" {
+			writeType = primitive.WriteTypeSimple
+		}
+		msg, err = message.NewWriteTimeout(e.consistency(), e.Received, e.BlockFor, writeType)
+	default:
+		return nil, fmt.Errorf("unknown error type: %v", e.Type)
+	}
+	return msg, err
+}
+
+// consistency returns the configured consistency level, or LOCAL_QUORUM if none is configured.
+func (e *cannedError) consistency() primitive.ConsistencyLevel {
+	if e.Consistency == nil {
+		return primitive.ConsistencyLevelLocalQuorum
+	}
+	return *e.Consistency
+}
+
+// delayed returns a RuleAction that applies the given action after the given delay.
+func delayed(delay time.Duration, action client.RuleAction) client.RuleAction {
+	return func(request *frame.Frame, conn *client.CqlServerConnection) *frame.Frame {
+		time.Sleep(delay)
+		return action(request, conn)
+	}
+}
+
+// parseDataType parses CQL type strings, see datatype.Parse. User-defined types must be declared with their fields,
+// e.g. ks1.address<street:text,zip:int>, since there is no schema to resolve them from.
+func parseDataType(s string) (datatype.DataType, error) {
+	return datatype.ParseWithResolver(s, func(string, string) (*datatype.UserDefined, error) {
+		return nil, errors.New("user-defined types must be declared with their fields")
+	})
+}
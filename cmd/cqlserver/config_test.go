@@ -0,0 +1,231 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+const testConfig = `
+cluster: test
+credentials: {username: cassandra, password: secret}
+nodes:
+  - address: 127.0.0.1:9044
+schema:
+  - keyspace: ks
+    name: users
+    columns: [{name: id, type: int}, {name: name, type: text}, {name: tags, type: "set<varchar>"}]
+responses:
+  - query: "(?i)^SELECT .* FROM ks\\.users"
+    table: ks.users
+    rows: [[1, alice, [a, b]], [2, bob, []]]
+  - query: "(?i)^INSERT"
+    error: {type: write_timeout, consistency: QUORUM, received: 1, block_for: 2}
+  - query: "(?i)^UPDATE"
+    delay: 50ms
+    columns: [{name: "[applied]", type: boolean}]
+    rows: [[true]]
+  - query: "(?i)^TRUNCATE"
+    message: {messageType: Overloaded, ErrorMessage: too busy}
+`
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	os.Exit(m.Run())
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := parseConfig([]byte("{}"))
+		require.NoError(t, err)
+		assert.Equal(t, "cluster1", cfg.Cluster)
+		assert.Equal(t, "dc1", cfg.Datacenter)
+		assert.Equal(t, []*node{{Address: "127.0.0.1:9042"}}, cfg.Nodes)
+	})
+	t.Run("json", func(t *testing.T) {
+		cfg, err := parseConfig([]byte(`{"nodes": [{"address": "127.0.0.1:9999"}], "chaos": {"latency": "10ms", "drop_rate": 0.5}}`))
+		require.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:9999", cfg.Nodes[0].Address)
+		assert.Equal(t, &chaos{Latency: 10 * time.Millisecond, DropRate: 0.5}, cfg.Chaos)
+	})
+	t.Run("yaml", func(t *testing.T) {
+		cfg, err := parseConfig([]byte(testConfig))
+		require.NoError(t, err)
+		require.Len(t, cfg.Responses, 4)
+		require.NotNil(t, cfg.Responses[1].Error.Consistency)
+		assert.Equal(t, primitive.ConsistencyLevelQuorum, *cfg.Responses[1].Error.Consistency)
+		assert.Equal(t, 50*time.Millisecond, cfg.Responses[2].Delay)
+	})
+	errorTests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{"malformed", "nodes: [", "cannot parse config"},
+		{"missing address", "nodes: [{max_connections: 1}]", "node 0: address is required"},
+		{"invalid drop rate", "chaos: {drop_rate: 2}", "chaos: drop rate must be between 0 and 1, got: 2"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfig([]byte(tt.config))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestConfig_RequestHandlers_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{"missing query", "responses: [{rows: []}]", "response 0: query is required"},
+		{"invalid query", "responses: [{query: '('}]", "response 0: invalid query expression"},
+		{"unknown table", "responses: [{query: '.*', table: ks.t1}]", "response 0: unknown table: ks.t1"},
+		{"unknown type", "responses: [{query: '.*', columns: [{name: c, type: foo}]}]", "cannot resolve user-defined type foo"},
+		{"wrong row length", "responses: [{query: '.*', columns: [{name: c, type: int}], rows: [[1, 2]]}]", "row 0: expected 1 values, got: 2"},
+		{"unknown error", "responses: [{query: '.*', error: {type: foo}}]", "unknown error type: foo"},
+		{"unknown message", "responses: [{query: '.*', message: {messageType: Foo}}]", `unknown message type: "Foo"`},
+		{"request message", "responses: [{query: '.*', message: {messageType: Options}}]", "expecting response, got: OPTIONS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseConfig([]byte(tt.config))
+			require.NoError(t, err)
+			_, err = cfg.requestHandlers()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestCannedError_Consistency(t *testing.T) {
+	tests := []struct {
+		config   string
+		expected primitive.ConsistencyLevel
+	}{
+		{"{type: unavailable, required: 2, alive: 1}", primitive.ConsistencyLevelLocalQuorum},
+		{"{type: unavailable, consistency: ANY, required: 2, alive: 1}", primitive.ConsistencyLevelAny},
+		{"{type: unavailable, consistency: ONE, required: 2, alive: 1}", primitive.ConsistencyLevelOne},
+	}
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			cfg, err := parseConfig([]byte("responses: [{query: '.*', error: " + tt.config + "}]"))
+			require.NoError(t, err)
+			msg, err := cfg.Responses[0].Error.message()
+			require.NoError(t, err)
+			require.IsType(t, &message.Unavailable{}, msg)
+			assert.Equal(t, tt.expected, msg.(*message.Unavailable).Consistency)
+		})
+	}
+}
+
+func TestParseDataType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected datatype.DataType
+		err      string
+	}{
+		{"int", datatype.Int, ""},
+		{"TEXT", datatype.Varchar, ""},
+		{"list<int>", datatype.NewList(datatype.Int), ""},
+		{"map<text, frozen>", nil, `cannot parse data type "map<text, frozen>": at position 16: expected '<'`},
+		{"map<varchar, set<uuid>>", datatype.NewMap(datatype.Varchar, datatype.NewSet(datatype.Uuid)), ""},
+		{"map<int>", nil, `cannot parse data type "map<int>": at position 0: wrong number of type arguments for map: 1`},
+		{"tuple<int>", datatype.NewTuple(datatype.Int), ""},
+		{"frozen<ks1.udt1<f1:int>>", &datatype.UserDefined{
+			Keyspace: "ks1", Name: "udt1", FieldNames: []string{"f1"}, FieldTypes: []datatype.DataType{datatype.Int},
+		}, ""},
+		{"ks1.udt1", nil, `cannot parse data type "ks1.udt1": cannot resolve user-defined type ks1.udt1: user-defined types must be declared with their fields`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual, err := parseDataType(tt.input)
+			assert.Equal(t, tt.expected, actual)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestConfig_NewServers(t *testing.T) {
+	cfg, err := parseConfig([]byte(testConfig))
+	require.NoError(t, err)
+	servers, err := cfg.newServers()
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	server := servers[0]
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	go drainAcceptedConnections(server)
+
+	clt := client.NewCqlClient("127.0.0.1:9044", &client.AuthCredentials{Username: "cassandra", Password: "secret"})
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	response := sendQuery(t, clientConn, "SELECT * FROM ks.users")
+	require.IsType(t, &message.RowsResult{}, response)
+	rows := response.(*message.RowsResult)
+	require.Len(t, rows.Metadata.Columns, 3)
+	assert.Equal(t, "users", rows.Metadata.Columns[0].Table)
+	assert.Equal(t, datatype.NewSet(datatype.Varchar), rows.Metadata.Columns[2].Type)
+	require.Len(t, rows.Data, 2)
+	assert.Equal(t, message.Column{0, 0, 0, 1}, rows.Data[0][0])
+	assert.Equal(t, message.Column("alice"), rows.Data[0][1])
+
+	response = sendQuery(t, clientConn, "INSERT INTO ks.users (id) VALUES (3)")
+	require.IsType(t, &message.WriteTimeout{}, response)
+	assert.Equal(t, primitive.ConsistencyLevelQuorum, response.(*message.WriteTimeout).Consistency)
+
+	start := time.Now()
+	response = sendQuery(t, clientConn, "UPDATE ks.users SET name = 'x' WHERE id = 1 IF EXISTS")
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	require.IsType(t, &message.RowsResult{}, response)
+	assert.Equal(t, message.RowSet{{message.Column{1}}}, response.(*message.RowsResult).Data)
+
+	response = sendQuery(t, clientConn, "TRUNCATE ks.users")
+	assert.Equal(t, &message.Overloaded{ErrorMessage: "too busy"}, response)
+
+	response = sendQuery(t, clientConn, "DELETE FROM ks.users WHERE id = 1")
+	assert.Equal(t, &message.VoidResult{}, response)
+
+	require.NoError(t, clientConn.Close())
+	require.NoError(t, server.Close())
+}
+
+func sendQuery(t *testing.T, clientConn *client.CqlClientConnection, query string) message.Message {
+	request := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: query})
+	response, err := clientConn.SendAndReceive(request)
+	require.NoError(t, err)
+	return response.Body.Message
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command cqlserver starts one or more simulated CQL nodes, as configured by a YAML or JSON file, and runs until
+// interrupted. It allows drivers written in any language to be tested against the CqlServer simulator:
+//
+//  cqlserver -config simulator.yaml
+//
+// A configuration file looks like this:
+//
+//  cluster: cluster1
+//  datacenter: dc1
+//  credentials: {username: cassandra, password: cassandra}
+//  nodes:
+//    - address: 127.0.0.1:9042
+//  schema:
+//    - keyspace: ks
+//      name: users
+//      columns: [{name: id, type: int}, {name: name, type: text}]
+//  responses:
+//    - query: "(?i)^SELECT .* FROM ks\\.users"
+//      table: ks.users
+//      rows: [[1, alice], [2, bob]]
+//    - query: "(?i)^INSERT INTO ks\\.users"
+//      error: {type: write_timeout, consistency: LOCAL_QUORUM, received: 1, block_for: 2}
+//    - query: "(?i)^TRUNCATE"
+//      message: {messageType: Overloaded, ErrorMessage: too busy}
+//    - query: "(?i)^DELETE"
+//      delay: 500ms
+//  chaos:
+//    latency: 10ms
+//    drop_rate: 0.01
+//
+// All nodes share the same configuration. Connections are initialized as expected by DataStax drivers: handshake,
+// heartbeats, USE and REGISTER requests, and queries to system.local and system.peers are handled automatically.
+// Canned responses are evaluated in order, and the first one whose query expression matches wins; QUERY requests not
+// matching any canned response get a VOID result.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+)
+
+func main() {
+	configPath := flag.String("config", "", "path to the YAML or JSON configuration file (required)")
+	logLevel := flag.String("log-level", "info", "log level: trace, debug, info, warn or error")
+	flag.Parse()
+	if err := run(*configPath, *logLevel); err != nil {
+		fmt.Fprintf(os.Stderr, "cqlserver: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath string, logLevel string) error {
+	if configPath == "" {
+		return fmt.Errorf("missing -config flag")
+	}
+	level, err := zerolog.ParseLevel(logLevel)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	zerolog.SetGlobalLevel(level)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	servers, err := cfg.newServers()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	for _, server := range servers {
+		if err := server.Start(ctx); err != nil {
+			return err
+		}
+		go drainAcceptedConnections(server)
+	}
+	<-ctx.Done()
+	for _, server := range servers {
+		if err := server.Close(); err != nil {
+			log.Warn().Err(err).Msgf("%v: error closing server", server)
+		}
+	}
+	return nil
+}
+
+// drainAcceptedConnections consumes the connections accepted by the given server, so that its queue of accepted
+// connections never fills up; the connections themselves are handled by the server's request handlers.
+func drainAcceptedConnections(server *client.CqlServer) {
+	for !server.IsClosed() {
+		_, _ = server.AcceptAny()
+	}
+}
@@ -22,6 +22,8 @@ import (
 	"io/ioutil"
 
 	"github.com/pierrec/lz4/v4"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
 
 // Compressor satisfies frame.BodyCompressor and segment.PayloadCompressor for the LZ4 algorithm.
@@ -30,6 +32,11 @@ import (
 // decoding.
 type Compressor struct{}
 
+// Algorithm returns primitive.CompressionLz4.
+func (c Compressor) Algorithm() primitive.Compression {
+	return primitive.CompressionLz4
+}
+
 func (c Compressor) Compress(source io.Reader, dest io.Writer) error {
 	if uncompressedMessage, err := bufferFromReader(source); err != nil {
 		return fmt.Errorf("cannot read uncompressed message: %w", err)
@@ -20,11 +20,18 @@ import (
 	"io"
 
 	"github.com/golang/snappy"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
 
 // Compressor satisfies frame.BodyCompressor for the SNAPPY algorithm.
 type Compressor struct{}
 
+// Algorithm returns primitive.CompressionSnappy.
+func (l Compressor) Algorithm() primitive.Compression {
+	return primitive.CompressionSnappy
+}
+
 func (l Compressor) CompressWithLength(source io.Reader, dest io.Writer) error {
 	if uncompressedMessage, err := bufferFromReader(source); err != nil {
 		return fmt.Errorf("cannot read uncompressed message: %w", err)
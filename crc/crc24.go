@@ -30,6 +30,9 @@ const crc24Poly uint32 = 0x1974F0B
 // Koopman's own notation to represent the polynomial has been changed.
 // This polynomial provides hamming distance of 8 for messages up to length 105 bits;
 // we only support 8-64 bits at present, with an expected range of 40-48.
+// ChecksumKoopman computes the CRC-24 checksum of the len least significant bytes of data, using the Koopman
+// polynomial 0x1974F0B and the initial value 0x875060, as done by Cassandra for protocol v5 segment headers. The bytes
+// are read in little-endian order, i.e. starting with the least significant byte of data. len must be between 0 and 8.
 func ChecksumKoopman(data uint64, len int) uint32 {
 	crc := crc24Init
 	for i := 0; i < len; i++ {
@@ -44,3 +47,13 @@ func ChecksumKoopman(data uint64, len int) uint32 {
 	}
 	return crc
 }
+
+// ChecksumKoopmanBytes is like ChecksumKoopman, but computes the CRC-24 checksum of the given bytes, which cannot be
+// more than 8. This is convenient to check the header of a segment read from a raw byte stream.
+func ChecksumKoopmanBytes(data []byte) uint32 {
+	var packed uint64
+	for i, b := range data {
+		packed |= uint64(b) << (8 * i)
+	}
+	return ChecksumKoopman(packed, len(data))
+}
@@ -50,3 +50,22 @@ func TestChecksumKoopman(t *testing.T) {
 		})
 	}
 }
+
+func TestChecksumKoopmanBytes(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want uint32
+	}{
+		{[]byte{}, ChecksumKoopman(0, 0)},
+		{[]byte{0, 0, 0}, ChecksumKoopman(0, 3)},
+		{[]byte{5, 0, 2}, ChecksumKoopman(131077, 3)},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}, ChecksumKoopman(9223372036854775807, 8)},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("data %v", tt.data), func(t *testing.T) {
+			if got := ChecksumKoopmanBytes(tt.data); got != tt.want {
+				t.Errorf("ChecksumKoopmanBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -22,6 +22,8 @@ var initialChecksum = crc32.Update(0, table, initialBytes)
 
 // ChecksumIEEE returns the CRC-32 checksum of the given data. The algorithm is the one expected by Cassandra:
 // the actual checksum is computed over the combination of 4 fixed initial bytes and the given byte slice.
+// ChecksumIEEE computes the CRC-32 checksum of the given data, using the IEEE polynomial and an initial state primed
+// with the bytes 0xFA, 0x2D, 0x55, 0xCA, as done by Cassandra for protocol v5 segment payloads.
 func ChecksumIEEE(data []byte) uint32 {
 	return crc32.Update(initialChecksum, table, data)
 }
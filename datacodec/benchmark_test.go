@@ -0,0 +1,153 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// benchmarkCase is a codec exercised by the benchmarks in this file, together with the maximum number of allocations
+// that encoding the source, and decoding it back into a new destination, are allowed to perform. The allocation
+// budgets are enforced by TestAllocationBudgets; a change that increases the number of allocations of a hot path must
+// update the corresponding budget deliberately.
+type benchmarkCase struct {
+	name         string
+	codec        func() (Codec, error)
+	source       interface{}
+	dest         func() interface{}
+	encodeAllocs float64
+	decodeAllocs float64
+}
+
+func staticCodec(codec Codec) func() (Codec, error) {
+	return func() (Codec, error) { return codec, nil }
+}
+
+func benchmarkCases() []benchmarkCase {
+	largeString := strings.Repeat("x", 64*1024)
+	largeBlob := []byte(largeString)
+	largeInt := new(big.Int).Lsh(big.NewInt(1), 1024)
+	largeNegInt := new(big.Int).Sub(new(big.Int).Neg(largeInt), big.NewInt(1))
+	smallList := []int32{1, 2, 3}
+	largeList := make([]int32, 1000)
+	smallMap := map[string]int32{"a": 1, "b": 2, "c": 3}
+	largeMap := make(map[string]int32, 1000)
+	for i := range largeList {
+		largeList[i] = int32(i)
+		largeMap["key"+strconv.Itoa(i)] = int32(i)
+	}
+	udtType, _ := datatype.NewUserDefined("ks", "udt", []string{"f1", "f2"}, []datatype.DataType{datatype.Int, datatype.Varchar})
+	return []benchmarkCase{
+		{"int", staticCodec(Int), int32(42), func() interface{} { return new(int32) }, 1, 0},
+		{"bigint", staticCodec(Bigint), int64(42), func() interface{} { return new(int64) }, 1, 0},
+		{"smallint", staticCodec(Smallint), int16(42), func() interface{} { return new(int16) }, 1, 0},
+		{"tinyint", staticCodec(Tinyint), int8(42), func() interface{} { return new(int8) }, 1, 0},
+		{"boolean", staticCodec(Boolean), true, func() interface{} { return new(bool) }, 1, 0},
+		{"float", staticCodec(Float), float32(42.42), func() interface{} { return new(float32) }, 1, 0},
+		{"double", staticCodec(Double), 42.42, func() interface{} { return new(float64) }, 1, 0},
+		{"varchar small", staticCodec(Varchar), "hello", func() interface{} { return new(string) }, 1, 1},
+		{"varchar large", staticCodec(Varchar), largeString, func() interface{} { return new(string) }, 1, 1},
+		{"blob small", staticCodec(Blob), []byte{1, 2, 3}, func() interface{} { return new([]byte) }, 0, 0},
+		{"blob large", staticCodec(Blob), largeBlob, func() interface{} { return new([]byte) }, 0, 0},
+		{"uuid", staticCodec(Uuid), primitive.UUID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, func() interface{} { return new(primitive.UUID) }, 1, 0},
+		{"inet v4", staticCodec(Inet), net.IPv4(192, 168, 1, 1), func() interface{} { return new(net.IP) }, 0, 1},
+		{"inet v6", staticCodec(Inet), net.ParseIP("2001:db8::1"), func() interface{} { return new(net.IP) }, 0, 0},
+		{"timestamp", staticCodec(Timestamp), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), func() interface{} { return new(time.Time) }, 1, 0},
+		{"date", staticCodec(Date), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), func() interface{} { return new(time.Time) }, 1, 0},
+		{"time", staticCodec(Time), 12 * time.Hour, func() interface{} { return new(time.Duration) }, 1, 0},
+		{"duration", staticCodec(Duration), CqlDuration{Months: 1, Days: 2, Nanos: 3}, func() interface{} { return new(CqlDuration) }, 5, 4},
+		{"varint small", staticCodec(Varint), big.NewInt(42), func() interface{} { return new(big.Int) }, 1, 0},
+		{"varint large", staticCodec(Varint), largeInt, func() interface{} { return new(big.Int) }, 1, 0},
+		{"varint large negative", staticCodec(Varint), largeNegInt, func() interface{} { return new(big.Int) }, 1, 0},
+		{"varint int64", staticCodec(Varint), int64(-4242), func() interface{} { return new(int64) }, 1, 0},
+		{"decimal small", staticCodec(Decimal), CqlDecimal{Unscaled: big.NewInt(4242), Scale: 2}, func() interface{} { return new(CqlDecimal) }, 1, 2},
+		{"decimal large", staticCodec(Decimal), CqlDecimal{Unscaled: largeInt, Scale: 2}, func() interface{} { return new(CqlDecimal) }, 1, 2},
+		{"decimal large negative", staticCodec(Decimal), CqlDecimal{Unscaled: largeNegInt, Scale: 2}, func() interface{} { return new(CqlDecimal) }, 1, 2},
+		{"list small", func() (Codec, error) { return NewList(datatype.NewList(datatype.Int)) }, smallList, func() interface{} { return new([]int32) }, 8, 13},
+		{"list large", func() (Codec, error) { return NewList(datatype.NewList(datatype.Int)) }, largeList, func() interface{} { return new([]int32) }, 2746, 4492},
+		{"set small", func() (Codec, error) { return NewSet(datatype.NewSet(datatype.Varchar)) }, []string{"a", "b", "c"}, func() interface{} { return new([]string) }, 8, 13},
+		{"map small", func() (Codec, error) { return NewMap(datatype.NewMap(datatype.Varchar, datatype.Int)) }, smallMap, func() interface{} { return new(map[string]int32) }, 15, 22},
+		{"map large", func() (Codec, error) { return NewMap(datatype.NewMap(datatype.Varchar, datatype.Int)) }, largeMap, func() interface{} { return new(map[string]int32) }, 4003, 7004},
+		{"tuple", func() (Codec, error) { return NewTuple(datatype.NewTuple(datatype.Int, datatype.Varchar)) }, []interface{}{int32(1), "a"}, func() interface{} { return new([]interface{}) }, 4, 9},
+		{"udt", func() (Codec, error) { return NewUserDefined(udtType) }, map[string]interface{}{"f1": int32(1), "f2": "a"}, func() interface{} { return new(map[string]interface{}) }, 11, 13},
+	}
+}
+
+func BenchmarkCodec_Encode(b *testing.B) {
+	for _, bc := range benchmarkCases() {
+		codec, err := bc.codec()
+		require.NoError(b, err)
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := codec.Encode(bc.source, primitive.ProtocolVersion5); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCodec_Decode(b *testing.B) {
+	for _, bc := range benchmarkCases() {
+		codec, err := bc.codec()
+		require.NoError(b, err)
+		encoded, err := codec.Encode(bc.source, primitive.ProtocolVersion5)
+		require.NoError(b, err)
+		b.Run(bc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(encoded)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := codec.Decode(encoded, bc.dest(), primitive.ProtocolVersion5); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestAllocationBudgets(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are not reliable with the race detector")
+	}
+	for _, bc := range benchmarkCases() {
+		t.Run(bc.name, func(t *testing.T) {
+			codec, err := bc.codec()
+			require.NoError(t, err)
+			encoded, err := codec.Encode(bc.source, primitive.ProtocolVersion5)
+			require.NoError(t, err)
+			encodeAllocs := testing.AllocsPerRun(100, func() {
+				_, _ = codec.Encode(bc.source, primitive.ProtocolVersion5)
+			})
+			assert.LessOrEqual(t, encodeAllocs, bc.encodeAllocs, "encode allocations")
+			dest := bc.dest()
+			decodeAllocs := testing.AllocsPerRun(100, func() {
+				_, _ = codec.Decode(encoded, dest, primitive.ProtocolVersion5)
+			})
+			assert.LessOrEqual(t, decodeAllocs, bc.decodeAllocs, "decode allocations")
+		})
+	}
+}
@@ -28,11 +28,6 @@ import (
 // handle all possible big.Int values; the best CQL type for handling big.Int is varint, not bigint.
 var Bigint Codec = &bigintCodec{dataType: datatype.Bigint}
 
-// Counter is a codec for the CQL counter type. Its preferred Go type is int64, but it can encode from and
-// decode to most numeric types, including big.Int. Note: contrary to what the name similarity suggests, bigint codecs
-// cannot handle all possible big.Int values; the best CQL type for handling big.Int is varint, not bigint.
-var Counter Codec = &bigintCodec{dataType: datatype.Counter}
-
 type bigintCodec struct {
 	dataType *datatype.PrimitiveType
 }
@@ -42,6 +37,7 @@ func (c *bigintCodec) DataType() datatype.DataType {
 }
 
 func (c *bigintCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	var val int64
 	var wasNil bool
 	if val, wasNil, err = convertToInt64(source); err == nil && !wasNil {
@@ -54,6 +50,9 @@ func (c *bigintCodec) Encode(source interface{}, version primitive.ProtocolVersi
 }
 
 func (c *bigintCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	var val int64
 	if val, wasNull, err = readInt64(source); err == nil {
 		err = convertFromInt64(val, wasNull, dest)
@@ -42,6 +42,7 @@ func (c *blobCodec) DataType() datatype.DataType {
 }
 
 func (c *blobCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	if dest, err = convertToBytes(source); err != nil {
 		err = errCannotEncode(source, c.DataType(), version, err)
 	}
@@ -49,6 +50,9 @@ func (c *blobCodec) Encode(source interface{}, version primitive.ProtocolVersion
 }
 
 func (c *blobCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	if wasNull, err = convertFromBytes(source, dest); err != nil {
 		err = errCannotDecode(dest, c.DataType(), version, err)
 	}
@@ -0,0 +1,147 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// IntBlobOptions controls how integers are converted to and from big-endian blob encodings, see IntToBlob, UintToBlob,
+// BlobToInt and BlobToUint.
+type IntBlobOptions struct {
+
+	// Width is the width of the blob encoding, in bytes; valid values are 1, 2, 4 and 8. A value of zero means that
+	// blobs are variable-length: when encoding, the shortest encoding able to represent the value is used; when
+	// decoding, blobs of any length between 1 and 8 bytes are accepted.
+	Width int
+
+	// Unsigned indicates whether the blob encoding is an unsigned integer; if false, the blob encoding is a two's
+	// complement signed integer.
+	Unsigned bool
+}
+
+// IntToBlob converts the given integer to a big-endian blob encoding, according to the given options. An error is
+// returned if the value cannot be represented with the requested width and signedness.
+func IntToBlob(val int64, options IntBlobOptions) ([]byte, error) {
+	if options.Unsigned {
+		if val < 0 {
+			return nil, errValueOutOfRange(val)
+		}
+		return UintToBlob(uint64(val), options)
+	}
+	width, err := options.width()
+	if err != nil {
+		return nil, err
+	} else if width == 0 {
+		width = 1
+		for width < 8 && (val < -(1<<(width*8-1)) || val > 1<<(width*8-1)-1) {
+			width *= 2
+		}
+	} else if width < 8 && (val < -(1<<(width*8-1)) || val > 1<<(width*8-1)-1) {
+		return nil, errValueOutOfRange(val)
+	}
+	return putUint(uint64(val), width), nil
+}
+
+// UintToBlob converts the given unsigned integer to a big-endian blob encoding, according to the given options. An
+// error is returned if the value cannot be represented with the requested width and signedness.
+func UintToBlob(val uint64, options IntBlobOptions) ([]byte, error) {
+	if !options.Unsigned {
+		if val > math.MaxInt64 {
+			return nil, errValueOutOfRange(val)
+		}
+		return IntToBlob(int64(val), options)
+	}
+	width, err := options.width()
+	if err != nil {
+		return nil, err
+	} else if width == 0 {
+		width = 1
+		for width < 8 && val > 1<<(width*8)-1 {
+			width *= 2
+		}
+	} else if width < 8 && val > 1<<(width*8)-1 {
+		return nil, errValueOutOfRange(val)
+	}
+	return putUint(val, width), nil
+}
+
+// BlobToInt converts the given big-endian blob encoding to an integer, according to the given options. An error is
+// returned if the blob length does not match the requested width, or if the decoded value overflows int64.
+func BlobToInt(blob []byte, options IntBlobOptions) (int64, error) {
+	val, err := readUint(blob, options)
+	if err != nil {
+		return 0, err
+	} else if options.Unsigned {
+		if val > math.MaxInt64 {
+			return 0, errValueOutOfRange(val)
+		}
+		return int64(val), nil
+	}
+	// sign-extend
+	shift := 64 - uint(len(blob))*8
+	return int64(val<<shift) >> shift, nil
+}
+
+// BlobToUint converts the given big-endian blob encoding to an unsigned integer, according to the given options. An
+// error is returned if the blob length does not match the requested width, or if the decoded value is negative.
+func BlobToUint(blob []byte, options IntBlobOptions) (uint64, error) {
+	if !options.Unsigned {
+		val, err := BlobToInt(blob, options)
+		if err != nil {
+			return 0, err
+		} else if val < 0 {
+			return 0, errValueOutOfRange(val)
+		}
+		return uint64(val), nil
+	}
+	return readUint(blob, options)
+}
+
+func (o IntBlobOptions) width() (int, error) {
+	switch o.Width {
+	case 0, 1, 2, 4, 8:
+		return o.Width, nil
+	}
+	return 0, fmt.Errorf("invalid blob width: %v", o.Width)
+}
+
+func putUint(val uint64, width int) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, val)
+	return buf[8-width:]
+}
+
+func readUint(blob []byte, options IntBlobOptions) (uint64, error) {
+	width, err := options.width()
+	if err != nil {
+		return 0, err
+	} else if width == 0 {
+		if len(blob) == 0 {
+			return 0, errWrongMinimumLength(1, 0)
+		} else if len(blob) > 8 {
+			return 0, fmt.Errorf("expected at most 8 bytes but got: %v", len(blob))
+		}
+	} else if len(blob) != width {
+		return 0, errWrongFixedLength(width, len(blob))
+	}
+	var val uint64
+	for _, b := range blob {
+		val = val<<8 | uint64(b)
+	}
+	return val, nil
+}
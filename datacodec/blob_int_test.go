@@ -0,0 +1,125 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntToBlob(t *testing.T) {
+	tests := []struct {
+		name     string
+		val      int64
+		options  IntBlobOptions
+		expected []byte
+		err      string
+	}{
+		{"signed 1 byte", -1, IntBlobOptions{Width: 1}, []byte{0xff}, ""},
+		{"signed 2 bytes", -2, IntBlobOptions{Width: 2}, []byte{0xff, 0xfe}, ""},
+		{"signed 4 bytes", 256, IntBlobOptions{Width: 4}, []byte{0, 0, 1, 0}, ""},
+		{"signed 8 bytes", math.MinInt64, IntBlobOptions{Width: 8}, []byte{0x80, 0, 0, 0, 0, 0, 0, 0}, ""},
+		{"signed out of range", 128, IntBlobOptions{Width: 1}, nil, "value out of range: 128"},
+		{"signed variable small", 127, IntBlobOptions{}, []byte{0x7f}, ""},
+		{"signed variable negative", -129, IntBlobOptions{}, []byte{0xff, 0x7f}, ""},
+		{"signed variable large", math.MaxInt32 + 1, IntBlobOptions{}, []byte{0, 0, 0, 0, 0x80, 0, 0, 0}, ""},
+		{"unsigned 1 byte", 255, IntBlobOptions{Width: 1, Unsigned: true}, []byte{0xff}, ""},
+		{"unsigned variable", 256, IntBlobOptions{Unsigned: true}, []byte{1, 0}, ""},
+		{"unsigned out of range", 256, IntBlobOptions{Width: 1, Unsigned: true}, nil, "value out of range: 256"},
+		{"unsigned negative", -1, IntBlobOptions{Width: 8, Unsigned: true}, nil, "value out of range: -1"},
+		{"invalid width", 1, IntBlobOptions{Width: 3}, nil, "invalid blob width: 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := IntToBlob(tt.val, tt.options)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
+
+func TestUintToBlob(t *testing.T) {
+	tests := []struct {
+		name     string
+		val      uint64
+		options  IntBlobOptions
+		expected []byte
+		err      string
+	}{
+		{"unsigned 8 bytes", math.MaxUint64, IntBlobOptions{Width: 8, Unsigned: true}, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, ""},
+		{"unsigned 2 bytes", math.MaxUint16, IntBlobOptions{Width: 2, Unsigned: true}, []byte{0xff, 0xff}, ""},
+		{"signed 2 bytes", math.MaxInt16, IntBlobOptions{Width: 2}, []byte{0x7f, 0xff}, ""},
+		{"signed out of range", math.MaxUint16, IntBlobOptions{Width: 2}, nil, "value out of range: 65535"},
+		{"signed overflow", math.MaxUint64, IntBlobOptions{Width: 8}, nil, "value out of range: 18446744073709551615"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := UintToBlob(tt.val, tt.options)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
+
+func TestBlobToInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		blob     []byte
+		options  IntBlobOptions
+		expected int64
+		err      string
+	}{
+		{"signed 1 byte", []byte{0xff}, IntBlobOptions{Width: 1}, -1, ""},
+		{"signed 2 bytes", []byte{0xff, 0x7f}, IntBlobOptions{Width: 2}, -129, ""},
+		{"signed 8 bytes", []byte{0x80, 0, 0, 0, 0, 0, 0, 0}, IntBlobOptions{Width: 8}, math.MinInt64, ""},
+		{"signed variable", []byte{0xff, 0xff, 0xfe}, IntBlobOptions{}, -2, ""},
+		{"unsigned 1 byte", []byte{0xff}, IntBlobOptions{Width: 1, Unsigned: true}, 255, ""},
+		{"unsigned overflow", []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, IntBlobOptions{Unsigned: true}, 0, "value out of range: 18446744073709551615"},
+		{"wrong length", []byte{1, 2, 3}, IntBlobOptions{Width: 4}, 0, "expected 4 bytes but got: 3"},
+		{"empty variable", []byte{}, IntBlobOptions{}, 0, "expected at least 1 bytes but got: 0"},
+		{"too long variable", make([]byte, 9), IntBlobOptions{}, 0, "expected at most 8 bytes but got: 9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := BlobToInt(tt.blob, tt.options)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
+
+func TestBlobToUint(t *testing.T) {
+	tests := []struct {
+		name     string
+		blob     []byte
+		options  IntBlobOptions
+		expected uint64
+		err      string
+	}{
+		{"unsigned 8 bytes", []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, IntBlobOptions{Width: 8, Unsigned: true}, math.MaxUint64, ""},
+		{"unsigned variable", []byte{1, 0}, IntBlobOptions{Unsigned: true}, 256, ""},
+		{"signed positive", []byte{0x7f}, IntBlobOptions{Width: 1}, 127, ""},
+		{"signed negative", []byte{0xff}, IntBlobOptions{Width: 1}, 0, "value out of range: -1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := BlobToUint(tt.blob, tt.options)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
@@ -30,6 +30,7 @@ func (c *booleanCodec) DataType() datatype.DataType {
 }
 
 func (c *booleanCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	var val bool
 	var wasNil bool
 	if val, wasNil, err = convertToBoolean(source); err == nil && !wasNil {
@@ -42,6 +43,9 @@ func (c *booleanCodec) Encode(source interface{}, version primitive.ProtocolVers
 }
 
 func (c *booleanCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	var val bool
 	if val, wasNull, err = readBool(source); err == nil {
 		err = convertFromBoolean(val, wasNull, dest)
@@ -44,10 +44,16 @@ type Codec interface {
 	DataType() datatype.DataType
 }
 
-// NewCodec creates a new codec for the given data type. For simple CQL types, this function actually returns one of
-// the existing singletons. For complex CQL types, it delegates to one of the constructor functions available:
-// NewList, NewSet, NewMap, NewTuple, NewUserDefined and NewCustom.
+// NewCodec creates a new codec for the given data type. Codecs registered in DefaultCodecRegistry take precedence over
+// built-in ones. For simple CQL types, this function otherwise returns one of the existing singletons. For complex CQL
+// types, it delegates to one of the constructor functions available: NewList, NewSet, NewMap, NewTuple,
+// NewUserDefined and NewCustom, except for the DSE geospatial and date range types, for which it returns Point,
+// LineString, Polygon or DateRange.
 func NewCodec(dt datatype.DataType) (Codec, error) {
+	return DefaultCodecRegistry.NewCodec(dt)
+}
+
+func newBuiltinCodec(dt datatype.DataType, registry *CodecRegistry) (Codec, error) {
 	switch dt.Code() {
 	case primitive.DataTypeCodeAscii:
 		return Ascii, nil
@@ -90,17 +96,23 @@ func NewCodec(dt datatype.DataType) (Codec, error) {
 	case primitive.DataTypeCodeVarint:
 		return Varint, nil
 	case primitive.DataTypeCodeCustom:
+		if geoCodec := newGeoCodec(dt); geoCodec != nil {
+			return geoCodec, nil
+		}
+		if isDateRange(dt) {
+			return DateRange, nil
+		}
 		return NewCustom(dt.(*datatype.Custom)), nil
 	case primitive.DataTypeCodeList:
-		return NewList(dt.(*datatype.List))
+		return newList(dt.(*datatype.List), registry)
 	case primitive.DataTypeCodeSet:
-		return NewSet(dt.(*datatype.Set))
+		return newSet(dt.(*datatype.Set), registry)
 	case primitive.DataTypeCodeMap:
-		return NewMap(dt.(*datatype.Map))
+		return newMap(dt.(*datatype.Map), registry)
 	case primitive.DataTypeCodeTuple:
-		return NewTuple(dt.(*datatype.Tuple))
+		return newTuple(dt.(*datatype.Tuple), registry)
 	case primitive.DataTypeCodeUdt:
-		return NewUserDefined(dt.(*datatype.UserDefined))
+		return newUserDefined(dt.(*datatype.UserDefined), registry)
 	}
 	return nil, errCannotCreateCodec(dt)
 }
@@ -122,6 +134,16 @@ func PreferredGoType(dt datatype.DataType) (reflect.Type, error) {
 	case primitive.DataTypeCodeCounter:
 		return typeOfInt64, nil
 	case primitive.DataTypeCodeCustom:
+		switch dt.(*datatype.Custom).ClassName {
+		case datatype.DsePointClassName:
+			return typeOfCqlPoint, nil
+		case datatype.DseLineStringClassName:
+			return typeOfCqlLineString, nil
+		case datatype.DsePolygonClassName:
+			return typeOfCqlPolygon, nil
+		case datatype.DseDateRangeClassName:
+			return typeOfCqlDateRange, nil
+		}
 		return typeOfByteSlice, nil
 	case primitive.DataTypeCodeDate:
 		return typeOfTime, nil
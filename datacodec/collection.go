@@ -26,10 +26,14 @@ import (
 )
 
 func NewList(dataType *datatype.List) (Codec, error) {
+	return newList(dataType, DefaultCodecRegistry)
+}
+
+func newList(dataType *datatype.List, registry *CodecRegistry) (Codec, error) {
 	if dataType == nil {
 		return nil, ErrNilDataType
 	}
-	codec, err := NewCodec(dataType.ElementType)
+	codec, err := registry.NewCodec(dataType.ElementType)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create codec for list elements: %w", err)
 	}
@@ -37,10 +41,14 @@ func NewList(dataType *datatype.List) (Codec, error) {
 }
 
 func NewSet(dataType *datatype.Set) (Codec, error) {
+	return newSet(dataType, DefaultCodecRegistry)
+}
+
+func newSet(dataType *datatype.Set, registry *CodecRegistry) (Codec, error) {
 	if dataType == nil {
 		return nil, ErrNilDataType
 	}
-	codec, err := NewCodec(dataType.ElementType)
+	codec, err := registry.NewCodec(dataType.ElementType)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create codec for set elements: %w", err)
 	}
@@ -57,6 +65,7 @@ func (c *collectionCodec) DataType() datatype.DataType {
 }
 
 func (c *collectionCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	ext, size, err := c.createExtractor(source)
 	if err == nil && ext != nil {
 		dest, err = writeCollection(ext, c.elementCodec, size, version)
@@ -68,6 +77,9 @@ func (c *collectionCodec) Encode(source interface{}, version primitive.ProtocolV
 }
 
 func (c *collectionCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	wasNull = len(source) == 0
 	var injectorFactory func(int) (injector, error)
 	if injectorFactory, err = c.createInjector(dest, wasNull); err == nil && injectorFactory != nil {
@@ -115,7 +127,7 @@ func (c *collectionCodec) createInjector(dest interface{}, wasNull bool) (inject
 		case reflect.Interface:
 			if !wasNull {
 				var targetType reflect.Type
-				if targetType, err = PreferredGoType(c.DataType()); err == nil {
+				if targetType, err = preferredGoTypeOf(c); err == nil {
 					injectorFactory = func(size int) (injector, error) {
 						destValue.Set(reflect.MakeSlice(targetType, size, size))
 						return newSliceInjector(destValue.Elem())
@@ -130,7 +142,8 @@ func (c *collectionCodec) createInjector(dest interface{}, wasNull bool) (inject
 }
 
 func writeCollection(ext extractor, elementCodec Codec, size int, version primitive.ProtocolVersion) ([]byte, error) {
-	buf := &bytes.Buffer{}
+	buf := primitive.AcquireWriteBuffer()
+	defer primitive.ReleaseWriteBuffer(buf)
 	if err := writeCollectionSize(size, buf, version); err != nil {
 		return nil, err
 	}
@@ -153,7 +166,7 @@ func writeCollection(ext extractor, elementCodec Codec, size int, version primit
 			}
 		}
 	}
-	return buf.Bytes(), nil
+	return buf.CopyBytes(), nil
 }
 
 func readCollection(source []byte, injectorFactory func(int) (injector, error), elementCodec Codec, version primitive.ProtocolVersion) error {
@@ -0,0 +1,127 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Counter is a codec for the CQL counter type. Its preferred Go type is int64, but it can encode from and decode to
+// most numeric types, including big.Int, like Bigint; it can also encode from and decode to CounterDelta. Note that
+// counter values can only be bound as deltas, e.g. in UPDATE statements such as "UPDATE t SET c = c + ? WHERE ...";
+// use StrictCounter to enforce that.
+var Counter Codec = &counterCodec{}
+
+// StrictCounter is a codec for the CQL counter type that only encodes CounterDelta values, and fails with
+// ErrCounterDeltaExpected for all other values: since counter columns cannot be inserted nor set to a given value,
+// only incremented or decremented, binding a plain number to a counter is often a mistake, e.g. when binding values
+// driven by RowsMetadata. Decoding is the same as with Counter.
+var StrictCounter Codec = &counterCodec{strict: true}
+
+// ErrCounterDeltaExpected is returned by StrictCounter when encoding a value that is not a CounterDelta.
+var ErrCounterDeltaExpected = errors.New("counter values can only be encoded as deltas, expecting CounterDelta")
+
+// CounterDelta is an increment, or a decrement if negative, to apply to a counter column, e.g. the value bound to the
+// marker of "UPDATE t SET c = c + ? WHERE ...".
+type CounterDelta int64
+
+// CounterDeltaBetween returns the delta that turns the counter value before into the counter value after.
+func CounterDeltaBetween(before int64, after int64) CounterDelta {
+	return CounterDelta(after - before)
+}
+
+// Apply returns the value of a counter of the given value once this delta is applied. Like in Cassandra, the result
+// wraps around on overflow.
+func (d CounterDelta) Apply(value int64) int64 {
+	return value + int64(d)
+}
+
+// IsIncrement returns true if this delta increments counters, and false if it decrements them or is zero.
+func (d CounterDelta) IsIncrement() bool {
+	return d > 0
+}
+
+// String returns this delta with an explicit sign, e.g. "+1" or "-1".
+func (d CounterDelta) String() string {
+	if d >= 0 {
+		return "+" + strconv.FormatInt(int64(d), 10)
+	}
+	return strconv.FormatInt(int64(d), 10)
+}
+
+type counterCodec struct {
+	strict bool
+}
+
+func (c *counterCodec) DataType() datatype.DataType {
+	return datatype.Counter
+}
+
+func (c *counterCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
+	var val int64
+	var wasNil bool
+	switch s := source.(type) {
+	case CounterDelta:
+		val = int64(s)
+	case *CounterDelta:
+		if wasNil = s == nil; !wasNil {
+			val = int64(*s)
+		}
+	case nil:
+		wasNil = true
+	default:
+		if c.strict {
+			err = ErrCounterDeltaExpected
+		} else {
+			val, wasNil, err = convertToInt64(source)
+		}
+	}
+	if err == nil && !wasNil {
+		dest = writeInt64(val)
+	}
+	if err != nil {
+		err = errCannotEncode(source, c.DataType(), version, err)
+	}
+	return
+}
+
+func (c *counterCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
+	var val int64
+	if val, wasNull, err = readInt64(source); err == nil {
+		if d, ok := dest.(*CounterDelta); ok {
+			if d == nil {
+				err = ErrNilDestination
+			} else if wasNull {
+				*d = 0
+			} else {
+				*d = CounterDelta(val)
+			}
+		} else {
+			err = convertFromInt64(val, wasNull, dest)
+		}
+	}
+	if err != nil {
+		err = errCannotDecode(dest, c.DataType(), version, err)
+	}
+	return
+}
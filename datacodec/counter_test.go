@@ -0,0 +1,99 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func Test_counterCodec_DataType(t *testing.T) {
+	assert.Equal(t, datatype.Counter, Counter.DataType())
+	assert.Equal(t, datatype.Counter, StrictCounter.DataType())
+}
+
+func Test_counterCodec_Encode(t *testing.T) {
+	delta := CounterDelta(1)
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			tests := []struct {
+				name     string
+				codec    Codec
+				source   interface{}
+				expected []byte
+				err      string
+			}{
+				{"delta", Counter, CounterDelta(1), bigIntOneBytes, ""},
+				{"delta pointer", Counter, &delta, bigIntOneBytes, ""},
+				{"nil delta pointer", Counter, (*CounterDelta)(nil), nil, ""},
+				{"int", Counter, 1, bigIntOneBytes, ""},
+				{"strict delta", StrictCounter, CounterDelta(-1), bigIntMinusOneBytes, ""},
+				{"strict delta pointer", StrictCounter, &delta, bigIntOneBytes, ""},
+				{"strict nil", StrictCounter, nil, nil, ""},
+				{"strict int", StrictCounter, 1, nil, fmt.Sprintf("cannot encode int as CQL counter with %v: counter values can only be encoded as deltas, expecting CounterDelta", version)},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					actual, err := tt.codec.Encode(tt.source, version)
+					assert.Equal(t, tt.expected, actual)
+					assertErrorMessage(t, tt.err, err)
+				})
+			}
+		})
+	}
+}
+
+func Test_counterCodec_Decode(t *testing.T) {
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			for _, codec := range []Codec{Counter, StrictCounter} {
+				var delta CounterDelta
+				wasNull, err := codec.Decode(bigIntMinusOneBytes, &delta, version)
+				assert.NoError(t, err)
+				assert.False(t, wasNull)
+				assert.Equal(t, CounterDelta(-1), delta)
+				wasNull, err = codec.Decode(nil, &delta, version)
+				assert.NoError(t, err)
+				assert.True(t, wasNull)
+				assert.Equal(t, CounterDelta(0), delta)
+				var value int64
+				_, err = codec.Decode(bigIntOneBytes, &value, version)
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), value)
+				_, err = codec.Decode(bigIntOneBytes, (*CounterDelta)(nil), version)
+				assertErrorMessage(t, fmt.Sprintf("cannot decode CQL counter as *datacodec.CounterDelta with %v: destination is nil", version), err)
+			}
+		})
+	}
+}
+
+func TestCounterDelta(t *testing.T) {
+	assert.Equal(t, CounterDelta(3), CounterDeltaBetween(2, 5))
+	assert.Equal(t, CounterDelta(-3), CounterDeltaBetween(5, 2))
+	assert.Equal(t, int64(5), CounterDelta(3).Apply(2))
+	assert.Equal(t, int64(math.MinInt64), CounterDelta(1).Apply(math.MaxInt64))
+	assert.True(t, CounterDelta(1).IsIncrement())
+	assert.False(t, CounterDelta(0).IsIncrement())
+	assert.False(t, CounterDelta(-1).IsIncrement())
+	assert.Equal(t, "+3", CounterDelta(3).String())
+	assert.Equal(t, "+0", CounterDelta(0).String())
+	assert.Equal(t, "-3", CounterDelta(-3).String())
+}
@@ -85,6 +85,10 @@ func (c *dateCodec) DataType() datatype.DataType {
 // Note that this relies on the fact that some additions will overflow: this is expected.
 
 func (c *dateCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return nil, errCannotEncode(source, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	var val int32
 	var wasNil bool
 	if val, wasNil, err = convertToInt32Date(source, c.layout); err == nil && !wasNil {
@@ -97,6 +101,12 @@ func (c *dateCodec) Encode(source interface{}, version primitive.ProtocolVersion
 }
 
 func (c *dateCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return false, errCannotDecode(dest, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	var val int32
 	if val, wasNull, err = readInt32(source); err == nil {
 		err = convertFromInt32Date(val+math.MinInt32, wasNull, c.layout, dest)
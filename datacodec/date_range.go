@@ -0,0 +1,431 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// DateRangePrecision is the precision of a CqlDateRangeBound, i.e. the smallest date or time unit it specifies.
+type DateRangePrecision uint8
+
+const (
+	DateRangePrecisionYear        = DateRangePrecision(0x00)
+	DateRangePrecisionMonth       = DateRangePrecision(0x01)
+	DateRangePrecisionDay         = DateRangePrecision(0x02)
+	DateRangePrecisionHour        = DateRangePrecision(0x03)
+	DateRangePrecisionMinute      = DateRangePrecision(0x04)
+	DateRangePrecisionSecond      = DateRangePrecision(0x05)
+	DateRangePrecisionMillisecond = DateRangePrecision(0x06)
+)
+
+func (p DateRangePrecision) IsValid() bool {
+	return p <= DateRangePrecisionMillisecond
+}
+
+func (p DateRangePrecision) String() string {
+	switch p {
+	case DateRangePrecisionYear:
+		return "DateRangePrecision YEAR [0x00]"
+	case DateRangePrecisionMonth:
+		return "DateRangePrecision MONTH [0x01]"
+	case DateRangePrecisionDay:
+		return "DateRangePrecision DAY [0x02]"
+	case DateRangePrecisionHour:
+		return "DateRangePrecision HOUR [0x03]"
+	case DateRangePrecisionMinute:
+		return "DateRangePrecision MINUTE [0x04]"
+	case DateRangePrecisionSecond:
+		return "DateRangePrecision SECOND [0x05]"
+	case DateRangePrecisionMillisecond:
+		return "DateRangePrecision MILLISECOND [0x06]"
+	}
+	return fmt.Sprintf("DateRangePrecision ? [%#.2X]", uint8(p))
+}
+
+// CqlDateRangeBound is a bound of a CqlDateRange, or the date of a single-date CqlDateRange.
+type CqlDateRangeBound struct {
+	// Timestamp is the bound's timestamp, with millisecond precision. When parsed, lower bounds and single dates are
+	// rounded down to the beginning of their precision unit, e.g. 2017-02 becomes 2017-02-01T00:00:00.000Z, and upper
+	// bounds are rounded up to its end, e.g. 2017-02 becomes 2017-02-28T23:59:59.999Z.
+	Timestamp time.Time
+	Precision DateRangePrecision
+}
+
+// CqlDateRange is a value of the DSE date range type, see datatype.DseDateRange. It is either a single date, e.g.
+// 2017-02-03, or a range of dates, e.g. [2017-01 TO 2017-02-15]; single dates and range bounds can be unbounded,
+// which is written *.
+type CqlDateRange struct {
+	// LowerBound is the lower bound of the range, or the date of a single date; nil means unbounded.
+	LowerBound *CqlDateRangeBound
+
+	// UpperBound is the upper bound of the range; nil means unbounded. It must be nil for single dates.
+	UpperBound *CqlDateRangeBound
+
+	// SingleDate is true if this value is a single date rather than a range.
+	SingleDate bool
+}
+
+// DateRange is a codec for the DSE date range type, datatype.DseDateRange. It encodes from and decodes to
+// CqlDateRange, and also accepts strings using the DSE Search syntax, e.g. "[2017-01 TO 2017-02-15]", which are parsed
+// with ParseCqlDateRange on encode, and formatted with CqlDateRange.String on decode.
+var DateRange Codec = &dateRangeCodec{}
+
+type dateRangeCodec struct{}
+
+func isDateRange(dt datatype.DataType) bool {
+	customType, ok := dt.(*datatype.Custom)
+	return ok && customType.ClassName == datatype.DseDateRangeClassName
+}
+
+func (c *dateRangeCodec) DataType() datatype.DataType {
+	return datatype.DseDateRange
+}
+
+func (c *dateRangeCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
+	var val CqlDateRange
+	var wasNil bool
+	if val, wasNil, err = convertToDateRange(source); err == nil && !wasNil {
+		dest, err = writeDateRange(val)
+	}
+	if err != nil {
+		err = errCannotEncode(source, c.DataType(), version, err)
+	}
+	return
+}
+
+func (c *dateRangeCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
+	var val CqlDateRange
+	if val, wasNull, err = readDateRange(source); err == nil {
+		err = convertFromDateRange(val, wasNull, dest)
+	}
+	if err != nil {
+		err = errCannotDecode(dest, c.DataType(), version, err)
+	}
+	return
+}
+
+func convertToDateRange(source interface{}) (val CqlDateRange, wasNil bool, err error) {
+	switch s := source.(type) {
+	case CqlDateRange:
+		val = s
+	case *CqlDateRange:
+		if wasNil = s == nil; !wasNil {
+			val = *s
+		}
+	case string:
+		val, err = ParseCqlDateRange(s)
+	case *string:
+		if wasNil = s == nil; !wasNil {
+			val, err = ParseCqlDateRange(*s)
+		}
+	case nil:
+		wasNil = true
+	default:
+		err = ErrConversionNotSupported
+	}
+	if err != nil {
+		err = errSourceConversionFailed(source, val, err)
+	}
+	return
+}
+
+func convertFromDateRange(val CqlDateRange, wasNull bool, dest interface{}) (err error) {
+	switch d := dest.(type) {
+	case *interface{}:
+		if d == nil {
+			err = ErrNilDestination
+		} else if wasNull {
+			*d = nil
+		} else {
+			*d = val
+		}
+	case *CqlDateRange:
+		if d == nil {
+			err = ErrNilDestination
+		} else if wasNull {
+			*d = CqlDateRange{}
+		} else {
+			*d = val
+		}
+	case *string:
+		if d == nil {
+			err = ErrNilDestination
+		} else if wasNull {
+			*d = ""
+		} else {
+			*d = val.String()
+		}
+	default:
+		err = errDestinationInvalid(dest)
+	}
+	if err != nil {
+		err = errDestinationConversionFailed(val, dest, err)
+	}
+	return
+}
+
+// String returns the DSE Search representation of this date range, e.g. "[2017-01 TO 2017-02-15]", "2017-02-03" or
+// "[2017 TO *]". Bounds are formatted in UTC according to their precision.
+func (r CqlDateRange) String() string {
+	if r.SingleDate {
+		return formatDateRangeBound(r.LowerBound)
+	}
+	return "[" + formatDateRangeBound(r.LowerBound) + " TO " + formatDateRangeBound(r.UpperBound) + "]"
+}
+
+func formatDateRangeBound(b *CqlDateRangeBound) string {
+	if b == nil {
+		return "*"
+	}
+	t := b.Timestamp.UTC()
+	var s string
+	if year := t.Year(); year < 0 {
+		s = fmt.Sprintf("-%04d", -year)
+	} else {
+		s = fmt.Sprintf("%04d", year)
+	}
+	if b.Precision >= DateRangePrecisionMonth {
+		s += fmt.Sprintf("-%02d", t.Month())
+	}
+	if b.Precision >= DateRangePrecisionDay {
+		s += fmt.Sprintf("-%02d", t.Day())
+	}
+	if b.Precision >= DateRangePrecisionHour {
+		s += fmt.Sprintf("T%02d", t.Hour())
+	}
+	if b.Precision >= DateRangePrecisionMinute {
+		s += fmt.Sprintf(":%02d", t.Minute())
+	}
+	if b.Precision >= DateRangePrecisionSecond {
+		s += fmt.Sprintf(":%02d", t.Second())
+	}
+	if b.Precision >= DateRangePrecisionMillisecond {
+		s += fmt.Sprintf(".%03d", t.Nanosecond()/int(time.Millisecond))
+	}
+	if b.Precision >= DateRangePrecisionHour {
+		s += "Z"
+	}
+	return s
+}
+
+var (
+	dateRangeBoundPattern    = regexp.MustCompile(`^([+-]?\d{4,})(?:-(\d{2})(?:-(\d{2})(?:T(\d{2})(?::(\d{2})(?::(\d{2})(?:\.(\d{1,3}))?)?)?)?Z?)?)?$`)
+	errInvalidDateRange      = errors.New("invalid date range format")
+	errInvalidDateRangeBound = errors.New("invalid date range bound")
+)
+
+// ParseCqlDateRange parses a date range using the DSE Search syntax: either a single date, e.g. "2017-02-03", or a
+// range, e.g. "[2017-01 TO 2017-02-15T10:30Z]", where "*" denotes an unbounded date or bound. Dates are written
+// YYYY[-MM[-DD[Thh[:mm[:ss[.SSS]]]]]], optionally followed by Z, and are interpreted in UTC; their precision is
+// inferred from the units present. Lower bounds and single dates are rounded down, and upper bounds rounded up, to
+// their precision, see CqlDateRangeBound.Timestamp.
+func ParseCqlDateRange(s string) (CqlDateRange, error) {
+	r, err := parseDateRange(strings.TrimSpace(s))
+	if err != nil {
+		return CqlDateRange{}, fmt.Errorf("cannot parse date range %q: %w", s, err)
+	}
+	return r, nil
+}
+
+func parseDateRange(s string) (r CqlDateRange, err error) {
+	if !strings.HasPrefix(s, "[") {
+		r.SingleDate = true
+		r.LowerBound, err = parseDateRangeBound(s, false)
+		return
+	} else if !strings.HasSuffix(s, "]") {
+		return CqlDateRange{}, errInvalidDateRange
+	}
+	bounds := strings.Split(s[1:len(s)-1], " TO ")
+	if len(bounds) != 2 {
+		return CqlDateRange{}, errInvalidDateRange
+	}
+	if r.LowerBound, err = parseDateRangeBound(strings.TrimSpace(bounds[0]), false); err == nil {
+		r.UpperBound, err = parseDateRangeBound(strings.TrimSpace(bounds[1]), true)
+	}
+	return
+}
+
+func parseDateRangeBound(s string, upper bool) (*CqlDateRangeBound, error) {
+	if s == "*" {
+		return nil, nil
+	}
+	matches := dateRangeBoundPattern.FindStringSubmatch(s)
+	if matches == nil {
+		return nil, fmt.Errorf("%w: %q", errInvalidDateRangeBound, s)
+	}
+	// values holds the year, month, day, hour, minute, second and millisecond; precision is the index of the last
+	// unit present
+	values := []int{0, 1, 1, 0, 0, 0, 0}
+	precision := DateRangePrecisionYear
+	for i, match := range matches[1:] {
+		if match == "" {
+			break
+		}
+		if i == int(DateRangePrecisionMillisecond) {
+			match = (match + "00")[:3]
+		}
+		value, err := strconv.Atoi(match)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", errInvalidDateRangeBound, s)
+		}
+		values[i] = value
+		precision = DateRangePrecision(i)
+	}
+	if values[1] < 1 || values[1] > 12 || values[2] < 1 || values[2] > 31 || values[3] > 23 || values[4] > 59 ||
+		values[5] > 59 {
+		return nil, fmt.Errorf("%w: %q", errInvalidDateRangeBound, s)
+	}
+	t := time.Date(
+		values[0], time.Month(values[1]), values[2], values[3], values[4], values[5],
+		values[6]*int(time.Millisecond), time.UTC,
+	)
+	if t.Day() != values[2] {
+		// e.g. February 30th
+		return nil, fmt.Errorf("%w: %q", errInvalidDateRangeBound, s)
+	}
+	if upper {
+		t = roundUpDateRangeBound(t, precision)
+	}
+	return &CqlDateRangeBound{Timestamp: t, Precision: precision}, nil
+}
+
+// roundUpDateRangeBound returns the last millisecond of the precision unit starting at t.
+func roundUpDateRangeBound(t time.Time, precision DateRangePrecision) time.Time {
+	switch precision {
+	case DateRangePrecisionYear:
+		t = t.AddDate(1, 0, 0)
+	case DateRangePrecisionMonth:
+		t = t.AddDate(0, 1, 0)
+	case DateRangePrecisionDay:
+		t = t.AddDate(0, 0, 1)
+	case DateRangePrecisionHour:
+		t = t.Add(time.Hour)
+	case DateRangePrecisionMinute:
+		t = t.Add(time.Minute)
+	case DateRangePrecisionSecond:
+		t = t.Add(time.Second)
+	default:
+		return t
+	}
+	return t.Add(-time.Millisecond)
+}
+
+// Implementation notes: date ranges are encoded as a type byte followed by the bounds present, each encoded as a
+// timestamp (milliseconds since the Epoch, as a [long]) followed by a precision byte.
+
+const (
+	dateRangeTypeSingleDate     = byte(0x00)
+	dateRangeTypeClosedRange    = byte(0x01)
+	dateRangeTypeOpenRangeHigh  = byte(0x02)
+	dateRangeTypeOpenRangeLow   = byte(0x03)
+	dateRangeTypeBothOpenRange  = byte(0x04)
+	dateRangeTypeSingleDateOpen = byte(0x05)
+)
+
+const lengthOfDateRangeBound = primitive.LengthOfLong + primitive.LengthOfByte
+
+func writeDateRange(val CqlDateRange) ([]byte, error) {
+	var rangeType byte
+	var bounds []*CqlDateRangeBound
+	switch {
+	case val.SingleDate && val.UpperBound != nil:
+		return nil, errors.New("single date cannot have an upper bound")
+	case val.SingleDate && val.LowerBound == nil:
+		rangeType = dateRangeTypeSingleDateOpen
+	case val.SingleDate:
+		rangeType, bounds = dateRangeTypeSingleDate, []*CqlDateRangeBound{val.LowerBound}
+	case val.LowerBound != nil && val.UpperBound != nil:
+		rangeType, bounds = dateRangeTypeClosedRange, []*CqlDateRangeBound{val.LowerBound, val.UpperBound}
+	case val.LowerBound != nil:
+		rangeType, bounds = dateRangeTypeOpenRangeHigh, []*CqlDateRangeBound{val.LowerBound}
+	case val.UpperBound != nil:
+		rangeType, bounds = dateRangeTypeOpenRangeLow, []*CqlDateRangeBound{val.UpperBound}
+	default:
+		rangeType = dateRangeTypeBothOpenRange
+	}
+	dest := make([]byte, 1, 1+len(bounds)*lengthOfDateRangeBound)
+	dest[0] = rangeType
+	for _, bound := range bounds {
+		if !bound.Precision.IsValid() {
+			return nil, fmt.Errorf("invalid precision: %v", bound.Precision)
+		}
+		millis, err := ConvertTimeToEpochMillis(bound.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		dest = append(dest, writeInt64(millis)...)
+		dest = append(dest, byte(bound.Precision))
+	}
+	return dest, nil
+}
+
+func readDateRange(source []byte) (val CqlDateRange, wasNull bool, err error) {
+	length := len(source)
+	if wasNull = length == 0; wasNull {
+		return
+	}
+	var expected int
+	switch source[0] {
+	case dateRangeTypeSingleDate, dateRangeTypeOpenRangeHigh, dateRangeTypeOpenRangeLow:
+		expected = 1 + lengthOfDateRangeBound
+	case dateRangeTypeClosedRange:
+		expected = 1 + 2*lengthOfDateRangeBound
+	case dateRangeTypeBothOpenRange, dateRangeTypeSingleDateOpen:
+		expected = 1
+	default:
+		return CqlDateRange{}, false, errCannotRead(val, fmt.Errorf("unknown date range type: %d", source[0]))
+	}
+	if length != expected {
+		return CqlDateRange{}, false, errCannotRead(val, errWrongFixedLength(expected, length))
+	}
+	var bounds []*CqlDateRangeBound
+	for offset := 1; offset < length; offset += lengthOfDateRangeBound {
+		bound := &CqlDateRangeBound{
+			Timestamp: ConvertEpochMillisToTime(int64(binary.BigEndian.Uint64(source[offset:]))),
+			Precision: DateRangePrecision(source[offset+primitive.LengthOfLong]),
+		}
+		if !bound.Precision.IsValid() {
+			return CqlDateRange{}, false, errCannotRead(val, fmt.Errorf("invalid precision: %v", bound.Precision))
+		}
+		bounds = append(bounds, bound)
+	}
+	switch source[0] {
+	case dateRangeTypeSingleDate:
+		val = CqlDateRange{LowerBound: bounds[0], SingleDate: true}
+	case dateRangeTypeClosedRange:
+		val = CqlDateRange{LowerBound: bounds[0], UpperBound: bounds[1]}
+	case dateRangeTypeOpenRangeHigh:
+		val = CqlDateRange{LowerBound: bounds[0]}
+	case dateRangeTypeOpenRangeLow:
+		val = CqlDateRange{UpperBound: bounds[0]}
+	case dateRangeTypeSingleDateOpen:
+		val = CqlDateRange{SingleDate: true}
+	}
+	return val, false, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+var (
+	cqlDateRangeClosed = CqlDateRange{
+		LowerBound: &CqlDateRangeBound{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), DateRangePrecisionMonth},
+		UpperBound: &CqlDateRangeBound{time.Date(2017, 2, 15, 23, 59, 59, 999000000, time.UTC), DateRangePrecisionDay},
+	}
+	cqlDateRangeSingle = CqlDateRange{
+		LowerBound: &CqlDateRangeBound{time.Date(2017, 2, 3, 10, 15, 30, 7000000, time.UTC), DateRangePrecisionMillisecond},
+		SingleDate: true,
+	}
+)
+
+var (
+	cqlDateRangeClosedBytes = []byte{
+		1,
+		0, 0, 1, 0x59, 0x57, 0x53, 0x64, 0x00, 1, // 2017-01-01T00:00:00.000Z, month
+		0, 0, 1, 0x5a, 0x44, 0x37, 0xeb, 0xff, 2, // 2017-02-15T23:59:59.999Z, day
+	}
+	cqlDateRangeSingleBytes = []byte{0, 0, 0, 1, 0x5a, 0x03, 0x78, 0xc1, 0xd7, 6}
+)
+
+func Test_dateRangeCodec_DataType(t *testing.T) {
+	assert.Equal(t, datatype.DseDateRange, DateRange.DataType())
+	codec, err := NewCodec(datatype.NewCustom(datatype.DseDateRangeClassName))
+	require.NoError(t, err)
+	assert.Same(t, DateRange, codec)
+	goType, err := PreferredGoType(datatype.DseDateRange)
+	require.NoError(t, err)
+	assert.Equal(t, typeOfCqlDateRange, goType)
+}
+
+func Test_dateRangeCodec_Encode(t *testing.T) {
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			tests := []struct {
+				name     string
+				source   interface{}
+				expected []byte
+				err      string
+			}{
+				{"nil", nil, nil, ""},
+				{"nil pointer", (*CqlDateRange)(nil), nil, ""},
+				{"closed range", cqlDateRangeClosed, cqlDateRangeClosedBytes, ""},
+				{"closed range pointer", &cqlDateRangeClosed, cqlDateRangeClosedBytes, ""},
+				{"closed range string", "[2017-01 TO 2017-02-15]", cqlDateRangeClosedBytes, ""},
+				{"single date", cqlDateRangeSingle, cqlDateRangeSingleBytes, ""},
+				{"open range high", "[2017-01 TO *]", append([]byte{2}, cqlDateRangeClosedBytes[1:10]...), ""},
+				{"open range low", "[* TO 2017-02-15]", append([]byte{3}, cqlDateRangeClosedBytes[10:]...), ""},
+				{"both open range", "[* TO *]", []byte{4}, ""},
+				{"single date open", "*", []byte{5}, ""},
+				{"string invalid", "[2017 TO]", nil, fmt.Sprintf("cannot encode string as CQL 'org.apache.cassandra.db.marshal.DateRangeType' with %v: cannot convert from string to datacodec.CqlDateRange: cannot parse date range \"[2017 TO]\": invalid date range format", version)},
+				{"single date with upper bound", CqlDateRange{SingleDate: true, UpperBound: &CqlDateRangeBound{}}, nil, fmt.Sprintf("cannot encode datacodec.CqlDateRange as CQL 'org.apache.cassandra.db.marshal.DateRangeType' with %v: single date cannot have an upper bound", version)},
+				{"invalid precision", CqlDateRange{LowerBound: &CqlDateRangeBound{Precision: 7}}, nil, fmt.Sprintf("cannot encode datacodec.CqlDateRange as CQL 'org.apache.cassandra.db.marshal.DateRangeType' with %v: invalid precision: DateRangePrecision ? [0X07]", version)},
+				{"conversion failed", 123, nil, fmt.Sprintf("cannot encode int as CQL 'org.apache.cassandra.db.marshal.DateRangeType' with %v: cannot convert from int to datacodec.CqlDateRange: conversion not supported", version)},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					actual, err := DateRange.Encode(tt.source, version)
+					assert.Equal(t, tt.expected, actual)
+					assertErrorMessage(t, tt.err, err)
+				})
+			}
+		})
+	}
+}
+
+func Test_dateRangeCodec_Decode(t *testing.T) {
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			tests := []struct {
+				name     string
+				source   []byte
+				dest     interface{}
+				expected interface{}
+				wasNull  bool
+				err      string
+			}{
+				{"null", nil, new(CqlDateRange), new(CqlDateRange), true, ""},
+				{"null interface", nil, new(interface{}), new(interface{}), true, ""},
+				{"closed range", cqlDateRangeClosedBytes, new(CqlDateRange), &cqlDateRangeClosed, false, ""},
+				{"closed range interface", cqlDateRangeClosedBytes, new(interface{}), interfacePtr(cqlDateRangeClosed), false, ""},
+				{"closed range string", cqlDateRangeClosedBytes, new(string), stringPtr("[2017-01 TO 2017-02-15]"), false, ""},
+				{"single date string", cqlDateRangeSingleBytes, new(string), stringPtr("2017-02-03T10:15:30.007Z"), false, ""},
+				{"both open range string", []byte{4}, new(string), stringPtr("[* TO *]"), false, ""},
+				{"single date open string", []byte{5}, new(string), stringPtr("*"), false, ""},
+				{"unknown type", []byte{6}, new(CqlDateRange), new(CqlDateRange), false, fmt.Sprintf("cannot decode CQL 'org.apache.cassandra.db.marshal.DateRangeType' as *datacodec.CqlDateRange with %v: cannot read datacodec.CqlDateRange: unknown date range type: 6", version)},
+				{"wrong length", cqlDateRangeClosedBytes[:10], new(CqlDateRange), new(CqlDateRange), false, fmt.Sprintf("cannot decode CQL 'org.apache.cassandra.db.marshal.DateRangeType' as *datacodec.CqlDateRange with %v: cannot read datacodec.CqlDateRange: expected 19 bytes but got: 10", version)},
+				{"invalid precision", []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 9}, new(CqlDateRange), new(CqlDateRange), false, fmt.Sprintf("cannot decode CQL 'org.apache.cassandra.db.marshal.DateRangeType' as *datacodec.CqlDateRange with %v: cannot read datacodec.CqlDateRange: invalid precision: DateRangePrecision ? [0X09]", version)},
+				{"conversion failed", []byte{5}, new(float64), new(float64), false, fmt.Sprintf("cannot decode CQL 'org.apache.cassandra.db.marshal.DateRangeType' as *float64 with %v: cannot convert from datacodec.CqlDateRange to *float64: conversion not supported", version)},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					wasNull, err := DateRange.Decode(tt.source, tt.dest, version)
+					assert.Equal(t, tt.expected, tt.dest)
+					assert.Equal(t, tt.wasNull, wasNull)
+					assertErrorMessage(t, tt.err, err)
+				})
+			}
+		})
+	}
+}
+
+func TestParseCqlDateRange(t *testing.T) {
+	bound := func(t time.Time, p DateRangePrecision) *CqlDateRangeBound {
+		return &CqlDateRangeBound{Timestamp: t, Precision: p}
+	}
+	tests := []struct {
+		input     string
+		expected  CqlDateRange
+		formatted string
+		err       string
+	}{
+		{"2017", CqlDateRange{LowerBound: bound(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), DateRangePrecisionYear), SingleDate: true}, "2017", ""},
+		{" * ", CqlDateRange{SingleDate: true}, "*", ""},
+		{"[2016 TO 2017]", CqlDateRange{
+			LowerBound: bound(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), DateRangePrecisionYear),
+			UpperBound: bound(time.Date(2017, 12, 31, 23, 59, 59, 999000000, time.UTC), DateRangePrecisionYear),
+		}, "[2016 TO 2017]", ""},
+		{"[2016-02 TO 2016-02]", CqlDateRange{
+			LowerBound: bound(time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC), DateRangePrecisionMonth),
+			UpperBound: bound(time.Date(2016, 2, 29, 23, 59, 59, 999000000, time.UTC), DateRangePrecisionMonth),
+		}, "[2016-02 TO 2016-02]", ""},
+		{"[2017-02-03T10 TO 2017-02-03T10:15:30Z]", CqlDateRange{
+			LowerBound: bound(time.Date(2017, 2, 3, 10, 0, 0, 0, time.UTC), DateRangePrecisionHour),
+			UpperBound: bound(time.Date(2017, 2, 3, 10, 15, 30, 999000000, time.UTC), DateRangePrecisionSecond),
+		}, "[2017-02-03T10Z TO 2017-02-03T10:15:30Z]", ""},
+		{"[* TO 2017-02-03T10:15]", CqlDateRange{
+			UpperBound: bound(time.Date(2017, 2, 3, 10, 15, 59, 999000000, time.UTC), DateRangePrecisionMinute),
+		}, "[* TO 2017-02-03T10:15Z]", ""},
+		{"2017-02-03T10:15:30.5", CqlDateRange{LowerBound: bound(time.Date(2017, 2, 3, 10, 15, 30, 500000000, time.UTC), DateRangePrecisionMillisecond), SingleDate: true}, "2017-02-03T10:15:30.500Z", ""},
+		{"-0010-01", CqlDateRange{LowerBound: bound(time.Date(-10, 1, 1, 0, 0, 0, 0, time.UTC), DateRangePrecisionMonth), SingleDate: true}, "-0010-01", ""},
+		{"2017-02-30", CqlDateRange{}, "", `cannot parse date range "2017-02-30": invalid date range bound: "2017-02-30"`},
+		{"2017-13", CqlDateRange{}, "", `cannot parse date range "2017-13": invalid date range bound: "2017-13"`},
+		{"17-01", CqlDateRange{}, "", `cannot parse date range "17-01": invalid date range bound: "17-01"`},
+		{"[2017 TO 2018", CqlDateRange{}, "", `cannot parse date range "[2017 TO 2018": invalid date range format`},
+		{"[2017 2018]", CqlDateRange{}, "", `cannot parse date range "[2017 2018]": invalid date range format`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual, err := ParseCqlDateRange(tt.input)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+			if err == nil {
+				assert.Equal(t, tt.formatted, actual.String())
+				json, err := ToJSONValue(actual, datatype.DseDateRange, nil)
+				require.NoError(t, err)
+				assert.Equal(t, tt.formatted, json)
+				fromJson, err := FromJSONValue(json, datatype.DseDateRange, nil)
+				require.NoError(t, err)
+				assert.Equal(t, tt.formatted, fromJson.(CqlDateRange).String())
+			}
+		})
+	}
+}
@@ -15,8 +15,15 @@
 package datacodec
 
 import (
+	"bytes"
+	"database/sql/driver"
 	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"math/big"
+	"strconv"
 
 	"github.com/datastax/go-cassandra-native-protocol/datatype"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
@@ -24,8 +31,10 @@ import (
 
 // CqlDecimal is the poor man's representation in Go of a CQL decimal value, since there is no built-in representation
 // of arbitrary-precision decimal values in Go's standard library.
-// Note that this value is pretty useless as is. It's highly recommended converting this value to some other type using
-// a dedicated library. The most popular one is: https://pkg.go.dev/github.com/ericlagergren/decimal/v3.
+// CqlDecimal offers no arithmetic; for that, convert it to a big.Rat with Rat, or to some other type using a dedicated
+// library, e.g. https://pkg.go.dev/github.com/ericlagergren/decimal/v3, through its string representation. CqlDecimal
+// implements fmt.Stringer, json.Marshaler, json.Unmarshaler, driver.Valuer and sql.Scanner, all of which use the plain
+// decimal notation, e.g. "123.45" for unscaled 12345 and scale 2.
 // The zero value of a CqlDecimal is encoded as zero, with zero scale.
 type CqlDecimal struct {
 
@@ -47,6 +56,7 @@ func (c *decimalCodec) DataType() datatype.DataType {
 }
 
 func (c *decimalCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	var val CqlDecimal
 	var wasNil bool
 	if val, wasNil, err = convertToDecimal(source); err == nil && !wasNil {
@@ -59,6 +69,9 @@ func (c *decimalCodec) Encode(source interface{}, version primitive.ProtocolVers
 }
 
 func (c *decimalCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	var val CqlDecimal
 	if val, wasNull, err = readDecimal(source); err == nil {
 		err = convertFromDecimal(val, wasNull, dest)
@@ -120,10 +133,10 @@ func writeDecimal(val CqlDecimal) []byte {
 	if n == nil {
 		n = zeroBigInt
 	}
-	unscaled := writeBigInt(n)
-	dest := make([]byte, primitive.LengthOfInt, primitive.LengthOfInt+len(unscaled))
+	dest := make([]byte, primitive.LengthOfInt+lengthOfBigInt(n))
 	binary.BigEndian.PutUint32(dest, uint32(val.Scale))
-	return append(dest, unscaled...)
+	putBigInt(dest[primitive.LengthOfInt:], n)
+	return dest
 }
 
 func readDecimal(source []byte) (val CqlDecimal, wasNull bool, err error) {
@@ -141,3 +154,126 @@ func readDecimal(source []byte) (val CqlDecimal, wasNull bool, err error) {
 	}
 	return
 }
+
+// ParseDecimal parses a decimal number in plain or scientific notation, preserving its scale: "1.50" is parsed as
+// unscaled 150 with scale 2, and "1.5E-3" as unscaled 15 with scale 4.
+func ParseDecimal(s string) (CqlDecimal, error) {
+	return parseDecimal(s)
+}
+
+// DecimalFromRat converts the given rational number to a CqlDecimal with the given scale. An error is returned if the
+// number cannot be represented exactly with that scale, e.g. 1/3 with any scale, or 1/8 with a scale lesser than 3.
+func DecimalFromRat(r *big.Rat, scale int32) (CqlDecimal, error) {
+	if r == nil {
+		return CqlDecimal{}, errors.New("cannot convert nil big.Rat to decimal")
+	}
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetFrac(pow10(scale), pow10(-scale)))
+	if !scaled.IsInt() {
+		return CqlDecimal{}, fmt.Errorf("cannot convert %v to decimal with scale %d without losing precision", r, scale)
+	}
+	return CqlDecimal{Unscaled: new(big.Int).Set(scaled.Num()), Scale: scale}, nil
+}
+
+// DecimalFromFloat64 converts the given float to a CqlDecimal, using the shortest decimal representation that
+// converts back to the same float, e.g. 0.1 is converted to unscaled 1 with scale 1. NaN and infinite values cannot be
+// converted.
+func DecimalFromFloat64(f float64) (CqlDecimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return CqlDecimal{}, fmt.Errorf("cannot convert %v to decimal", f)
+	}
+	return parseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// Rat returns this decimal as a rational number, which can be used for arithmetic operations.
+func (d CqlDecimal) Rat() *big.Rat {
+	unscaled := d.Unscaled
+	if unscaled == nil {
+		unscaled = zeroBigInt
+	}
+	r := new(big.Rat).SetInt(unscaled)
+	return r.Mul(r, new(big.Rat).SetFrac(pow10(-d.Scale), pow10(d.Scale)))
+}
+
+// Float64 returns the float nearest to this decimal, and whether that float represents it exactly.
+func (d CqlDecimal) Float64() (f float64, exact bool) {
+	return d.Rat().Float64()
+}
+
+// Cmp compares this decimal to the other one, regardless of their scales, and returns -1, 0 or +1 if this decimal is,
+// respectively, lesser than, equal to, or greater than the other one. 1.5 and 1.50 are equal.
+func (d CqlDecimal) Cmp(other CqlDecimal) int {
+	return d.Rat().Cmp(other.Rat())
+}
+
+// String returns this decimal in plain notation, e.g. 123.45 for unscaled 12345 and scale 2.
+func (d CqlDecimal) String() string {
+	return formatDecimal(d)
+}
+
+// MarshalJSON marshals this decimal as a JSON number in plain notation, preserving its scale.
+func (d CqlDecimal) MarshalJSON() ([]byte, error) {
+	return []byte(formatDecimal(d)), nil
+}
+
+// UnmarshalJSON unmarshals this decimal from a JSON number or string, preserving its scale. Null values are ignored.
+func (d *CqlDecimal) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Errorf("cannot unmarshal decimal: %w", err)
+	}
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("cannot unmarshal decimal: %w", errJSONWrongType(v, "number or string"))
+	}
+	decoded, err := parseDecimal(s)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal decimal: %w", err)
+	}
+	*d = decoded
+	return nil
+}
+
+// Value implements driver.Valuer; decimals are converted to strings in plain notation.
+func (d CqlDecimal) Value() (driver.Value, error) {
+	return formatDecimal(d), nil
+}
+
+// Scan implements sql.Scanner; it accepts strings and byte slices in plain or scientific notation, integers and
+// floats. Null values are scanned as the zero decimal.
+func (d *CqlDecimal) Scan(src interface{}) (err error) {
+	var decoded CqlDecimal
+	switch s := src.(type) {
+	case nil:
+	case string:
+		decoded, err = parseDecimal(s)
+	case []byte:
+		decoded, err = parseDecimal(string(s))
+	case int64:
+		decoded = CqlDecimal{Unscaled: big.NewInt(s)}
+	case float64:
+		decoded, err = DecimalFromFloat64(s)
+	default:
+		err = fmt.Errorf("cannot scan %T into decimal", src)
+	}
+	if err == nil {
+		*d = decoded
+	}
+	return err
+}
+
+// pow10 returns 10^n if n is positive, or 1 otherwise.
+func pow10(n int32) *big.Int {
+	if n <= 0 {
+		return big.NewInt(1)
+	}
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -15,12 +15,16 @@
 package datacodec
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/datastax/go-cassandra-native-protocol/datatype"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
@@ -209,3 +213,167 @@ func Test_readDecimal(t *testing.T) {
 		})
 	}
 }
+
+var (
+	_ fmt.Stringer     = CqlDecimal{}
+	_ json.Marshaler   = CqlDecimal{}
+	_ json.Unmarshaler = &CqlDecimal{}
+	_ driver.Valuer    = CqlDecimal{}
+	_ sql.Scanner      = &CqlDecimal{}
+)
+
+func TestCqlDecimal_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    CqlDecimal
+		expected string
+	}{
+		{"zero", decimalZero, "0"},
+		{"one", decimalOne, "1"},
+		{"negative scale", decimalSimple, "1230"},
+		{"positive scale", CqlDecimal{big.NewInt(-12345), 2}, "-123.45"},
+		{"leading zeros", CqlDecimal{big.NewInt(15), 4}, "0.0015"},
+		{"trailing zeros", CqlDecimal{big.NewInt(150), 2}, "1.50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.input.String())
+			assert.Equal(t, tt.expected, fmt.Sprint(tt.input))
+			parsed, err := ParseDecimal(tt.expected)
+			require.NoError(t, err)
+			assert.Zero(t, parsed.Cmp(tt.input))
+		})
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected CqlDecimal
+		err      string
+	}{
+		{"1.50", CqlDecimal{big.NewInt(150), 2}, ""},
+		{"-0.001", CqlDecimal{big.NewInt(-1), 3}, ""},
+		{"1.5E-3", CqlDecimal{big.NewInt(15), 4}, ""},
+		{"123e2", CqlDecimal{big.NewInt(123), -2}, ""},
+		{"abc", CqlDecimal{}, "cannot parse decimal: abc"},
+		{"1e", CqlDecimal{}, "cannot parse decimal: 1e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual, err := ParseDecimal(tt.input)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
+
+func TestCqlDecimal_Rat(t *testing.T) {
+	assert.Equal(t, big.NewRat(0, 1), decimalZero.Rat())
+	assert.Equal(t, big.NewRat(1230, 1), decimalSimple.Rat())
+	assert.Equal(t, big.NewRat(-12345, 100), CqlDecimal{big.NewInt(-12345), 2}.Rat())
+	// arithmetic through big.Rat
+	sum := new(big.Rat).Add(CqlDecimal{big.NewInt(15), 1}.Rat(), CqlDecimal{big.NewInt(25), 2}.Rat())
+	actual, err := DecimalFromRat(sum, 2)
+	require.NoError(t, err)
+	assert.Equal(t, CqlDecimal{big.NewInt(175), 2}, actual)
+	assert.Equal(t, "1.75", actual.String())
+}
+
+func TestDecimalFromRat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    *big.Rat
+		scale    int32
+		expected CqlDecimal
+		err      string
+	}{
+		{"exact", big.NewRat(1, 8), 3, CqlDecimal{big.NewInt(125), 3}, ""},
+		{"larger scale", big.NewRat(1, 8), 5, CqlDecimal{big.NewInt(12500), 5}, ""},
+		{"negative scale", big.NewRat(1200, 1), -2, CqlDecimal{big.NewInt(12), -2}, ""},
+		{"inexact", big.NewRat(1, 8), 2, CqlDecimal{}, "cannot convert 1/8 to decimal with scale 2 without losing precision"},
+		{"repeating", big.NewRat(1, 3), 10, CqlDecimal{}, "cannot convert 1/3 to decimal with scale 10 without losing precision"},
+		{"nil", nil, 0, CqlDecimal{}, "cannot convert nil big.Rat to decimal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := DecimalFromRat(tt.input, tt.scale)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
+
+func TestCqlDecimal_Float64(t *testing.T) {
+	f, exact := CqlDecimal{big.NewInt(125), 3}.Float64()
+	assert.Equal(t, 0.125, f)
+	assert.True(t, exact)
+	f, exact = CqlDecimal{big.NewInt(1), 1}.Float64()
+	assert.Equal(t, 0.1, f)
+	assert.False(t, exact)
+	d, err := DecimalFromFloat64(0.1)
+	require.NoError(t, err)
+	assert.Equal(t, CqlDecimal{big.NewInt(1), 1}, d)
+	d, err = DecimalFromFloat64(-1.5e20)
+	require.NoError(t, err)
+	assert.Equal(t, "-150000000000000000000", d.String())
+	_, err = DecimalFromFloat64(math.NaN())
+	assertErrorMessage(t, "cannot convert NaN to decimal", err)
+	_, err = DecimalFromFloat64(math.Inf(1))
+	assertErrorMessage(t, "cannot convert +Inf to decimal", err)
+}
+
+func TestCqlDecimal_Cmp(t *testing.T) {
+	assert.Equal(t, 0, CqlDecimal{big.NewInt(15), 1}.Cmp(CqlDecimal{big.NewInt(150), 2}))
+	assert.Equal(t, -1, decimalOne.Cmp(decimalSimple))
+	assert.Equal(t, 1, decimalOne.Cmp(decimalZero))
+}
+
+func TestCqlDecimal_JSON(t *testing.T) {
+	type row struct {
+		Price CqlDecimal  `json:"price"`
+		Tax   *CqlDecimal `json:"tax"`
+	}
+	data, err := json.Marshal(row{Price: CqlDecimal{big.NewInt(1050), 2}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"price":10.50,"tax":null}`, string(data))
+	var decoded row
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, row{Price: CqlDecimal{big.NewInt(1050), 2}}, decoded)
+	// strings are accepted too, and large values don't lose precision
+	require.NoError(t, json.Unmarshal([]byte(`{"price":"18446744073709551615.5","tax":1e-2}`), &decoded))
+	assert.Equal(t, "18446744073709551615.5", decoded.Price.String())
+	assert.Equal(t, CqlDecimal{big.NewInt(1), 2}, *decoded.Tax)
+	err = json.Unmarshal([]byte(`{"price":true}`), &decoded)
+	assertErrorMessage(t, "cannot unmarshal decimal: expected JSON number or string, got: bool", err)
+	err = json.Unmarshal([]byte(`{"price":"abc"}`), &decoded)
+	assertErrorMessage(t, "cannot unmarshal decimal: cannot parse decimal: abc", err)
+}
+
+func TestCqlDecimal_SQL(t *testing.T) {
+	value, err := CqlDecimal{big.NewInt(-1050), 2}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "-10.50", value)
+	tests := []struct {
+		name     string
+		src      interface{}
+		expected CqlDecimal
+		err      string
+	}{
+		{"nil", nil, CqlDecimal{}, ""},
+		{"string", "10.50", CqlDecimal{big.NewInt(1050), 2}, ""},
+		{"bytes", []byte("1e3"), CqlDecimal{big.NewInt(1), -3}, ""},
+		{"int64", int64(42), CqlDecimal{Unscaled: big.NewInt(42)}, ""},
+		{"float64", 0.25, CqlDecimal{big.NewInt(25), 2}, ""},
+		{"wrong string", "abc", decimalOne, "cannot parse decimal: abc"},
+		{"wrong type", true, decimalOne, "cannot scan bool into decimal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := decimalOne
+			err := d.Scan(tt.src)
+			assert.Equal(t, tt.expected, d)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
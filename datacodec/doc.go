@@ -35,7 +35,8 @@
 // maps, tuples and user-defined types).
 //
 // Simple CQL types also have a global codec available; for example the datacodec.Varchar codec can be used to decode
-// and encode to CQL varchar.
+// and encode to CQL varchar. The datacodec.StrictVarchar codec behaves like datacodec.Varchar, but additionally rejects
+// strings that are not valid UTF-8, reporting the byte offset of the first invalid sequence.
 //
 // Codecs for complex types can be obtained through constructor functions:
 //
@@ -55,6 +56,7 @@
 //                        | int[64-8], *int[64-8], uint[64-8], *uint[64-8]  |
 //                        | *big.Int                                        |
 //                        | string, *string                                 | formatted and parsed as base 10 number
+//                        | CounterDelta, *CounterDelta                     | counter only; StrictCounter only accepts these
 //  blob                  | []byte, *[]byte                                 |
 //                        | string, *string                                 |
 //  boolean               | bool, *bool                                     |
@@ -63,10 +65,20 @@
 //                        | int[64-8], *int[64-8], uint[64-8], *uint[64-8]  | days since Unix epoch
 //                        | string, *string                                 | parsed according to layout, default is "2006-01-02"
 //  decimal               | CqlDecimal, *CqlDecimal                         |
+//  DSE DateRangeType     | CqlDateRange, *CqlDateRange                     |
+//                        | string, *string                                 | DSE Search syntax, e.g. "[2017-01 TO 2017-02-15]"
 //  double                | float64, *float64                               |
 //                        | float32, *float32                               |
 //                        | *big.Float                                      |
 //  duration              | CqlDuration, *CqlDuration                       |
+//                        | time.Duration, *time.Duration                   | months and days must be zero when decoding
+//                        | string, *string                                 | ISO-8601 or Cassandra format, e.g. "P1DT2H" or "1d2h"
+//  DSE PointType         | CqlPoint, *CqlPoint                             |
+//                        | string, *string                                 | WKT format, e.g. "POINT (1 2)"
+//  DSE LineStringType    | CqlLineString, *CqlLineString                   |
+//                        | string, *string                                 | WKT format, e.g. "LINESTRING (1 2, 3 4)"
+//  DSE PolygonType       | CqlPolygon, *CqlPolygon                         |
+//                        | string, *string                                 | WKT format, e.g. "POLYGON ((0 0, 1 0, 1 1, 0 0))"
 //  float                 | float32, *float32                               |
 //                        | float64, *float64                               |
 //  inet                  | net.IP, *net.IP                                 |
@@ -88,6 +100,7 @@
 //  timestamp             | time.Time, *time.Time                           |
 //                        | int[64-8], *int[64-8], uint[64-8], *uint[64-8]  | milliseconds since Unix epoch
 //                        | string, *string                                 | parsed according to layout and location, defaults are "2006-01-02T15:04:05.999999999-07:00" and UTC
+//                        | CivilDateTime, *CivilDateTime                   | date and time of day in the codec's location, default is UTC
 //  tinyint               | int8, *int8                                     |
 //                        | int[64-8], *int[64-8], uint[64-8], *uint[64-8]  |
 //                        | string, *string                                 | formatted and parsed as base 10 number
@@ -117,11 +130,50 @@
 // accepted type. When decoding to *interface{}, the codec will use the preferred type to decode, then store its value
 // in the target variable; if the decoded value was NULL, the target will be set to nil.
 //
+// Nullable values
+//
+// All the built-in codecs also accept nullable wrappers, modeled after database/sql's NullInt64 and the like, as an
+// alternative to pointers: NullInt64, NullInt32, NullInt16, NullInt8, NullBool, NullFloat64, NullFloat32, NullString,
+// NullBytes, NullTime, NullTimeOfDay, NullDuration, NullDecimal, NullVarint, NullUUID and NullIP; with Go 1.21+, the
+// generic Nullable type can wrap any accepted Go type. A wrapper whose Valid field is false is encoded as a CQL NULL;
+// when decoding into a pointer to a wrapper, its Valid field tells whether the decoded value was NULL. This is
+// especially convenient for struct fields mapped to user-defined types:
+//
+//  type address struct {
+// 	  Street datacodec.NullString
+// 	  Number datacodec.NullInt32
+//  }
+//
+// Protocol versions
+//
+// Some CQL types were introduced in later protocol versions; their codecs return an error wrapping
+// ErrDataTypeNotSupported when used with an earlier version, rather than producing bytes that the server would not
+// understand:
+//
+//  CQL type                       | Minimum protocol version
+//  tuple, user-defined type       | v3
+//  date, time, smallint, tinyint  | v4
+//  duration                       | v5
+//
+// All the other types, as well as all the types above with DSE protocol versions, are supported. Collection codecs
+// do not check the protocol version themselves, but their element codecs do.
+//
 // Encoding data
 //
 // Sources can be passed by value or by reference, unless specified otherwise in the table above. Nils are encoded as
 // CQL NULLs; encoding such a value is generally a no-op and returns a nil []byte.
 //
+// Empty values
+//
+// CQL distinguishes NULL values, encoded as a nil []byte, from empty values, encoded as a non-nil, zero-length []byte.
+// Codecs for varchar, ascii and blob guarantee that empty values round-trip as empty, non-NULL values: the empty string
+// and empty (non-nil) slices are encoded as empty values, and empty values are decoded as such, with wasNull set to
+// false. This is also true of the elements of collections, tuples and user-defined types.
+//
+// For all the other CQL types, empty values have no Go representation: codecs never produce them, and decode them as
+// NULLs, with wasNull set to true. Empty values can still be written intentionally with EmptyValue, and distinguished
+// from NULLs with IsEmpty and IsNull.
+//
 // Decoding data
 //
 // Destination values must be passed by reference, see examples below. This is also valid for slices and maps, and is
@@ -161,4 +213,78 @@
 //  } else {
 // 	  fmt.Println("CQL value was:", value)
 //  }
+//
+// Streaming collections
+//
+// Decoding a very large list, set or map with a codec requires the whole Go slice or map to be built in memory. The
+// functions NewListIterator, NewSetIterator and NewMapIterator can be used instead to process the serialized
+// collection one element at a time:
+//
+//  it, err := datacodec.NewListIterator(datatype.NewList(datatype.Int), source, primitive.ProtocolVersion5)
+//  if err != nil {
+// 	  return err
+//  }
+//  for it.Next() {
+// 	  var elem int32
+// 	  if _, err := it.Decode(&elem); err != nil {
+// 	    return err
+// 	  }
+// 	  fmt.Println("element:", elem)
+//  }
+//  if err := it.Err(); err != nil {
+// 	  fmt.Println("Decoding failed: ", err)
+//  }
+//
+// With Go 1.23+, the generic functions Elements and Entries turn these iterators into range-over-func sequences:
+//
+//  for elem := range datacodec.Elements[int32](it) {
+// 	  fmt.Println("element:", elem)
+//  }
+//  if err := it.Err(); err != nil {
+// 	  fmt.Println("Decoding failed: ", err)
+//  }
+//
+// Custom codecs
+//
+// User-provided codecs can be registered in a CodecRegistry, and take precedence over the built-in codecs. This can be
+// used for example to map a custom CQL type to a Go struct. Codecs registered in DefaultCodecRegistry are consulted by
+// NewCodec, including when creating codecs for the elements of complex types:
+//
+//  if err := datacodec.DefaultCodecRegistry.Register(myPointCodec); err != nil {
+// 	  return err
+//  }
+//  // returns a list codec that uses myPointCodec to encode and decode its elements
+//  codec, err := datacodec.NewCodec(datatype.NewList(datatype.NewCustom("com.example.Point")))
+//
+// Codecs can also be registered for a specific keyspace, table and column with CodecRegistry.RegisterColumn; such
+// codecs are only consulted by CodecRegistry.NewColumnCodec.
+//
+// The Go types used when decoding into an *interface{}, as returned by PreferredGoType, can be overridden per CQL type
+// with CodecRegistry.SetPreferredGoType, e.g. to decode timestamps into int64 milliseconds instead of time.Time:
+//
+//  if err := datacodec.DefaultCodecRegistry.SetPreferredGoType(datatype.Timestamp, reflect.TypeOf(int64(0))); err != nil {
+// 	  return err
+//  }
+//
+// JSON
+//
+// ToJSONValue and FromJSONValue convert CQL values to and from values that encoding/json can marshal, e.g. to expose
+// query results through a REST API. Formatting of temporal types and big numbers can be configured with JSONOptions:
+//
+//  codec, _ := datacodec.NewCodec(datatype.NewMap(datatype.Varchar, datatype.Decimal))
+//  doc, err := datacodec.EncodedToJSON(codec, source, primitive.ProtocolVersion5, &datacodec.JSONOptions{
+// 	  BigNumbersAsStrings: true,
+//  })
+//  // doc is now e.g. {"price":"12.50"}
+//
+// Whole rows
+//
+// RowCodec decodes whole rows of a message.RowsResult into maps of column names to values, and encodes such maps back
+// into rows; NULL values are represented by the Null sentinel:
+//
+//  codec, _ := datacodec.NewRowCodec(result.Metadata)
+//  for _, row := range result.Data {
+// 	  values, err := codec.Decode(row, primitive.ProtocolVersion5)
+// 	  ...
+//  }
 package datacodec
@@ -34,6 +34,7 @@ func (c *doubleCodec) DataType() datatype.DataType {
 }
 
 func (c *doubleCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	var val float64
 	var wasNil bool
 	if val, wasNil, err = convertToFloat64(source); err == nil && !wasNil {
@@ -46,6 +47,9 @@ func (c *doubleCodec) Encode(source interface{}, version primitive.ProtocolVersi
 }
 
 func (c *doubleCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	var val float64
 	if val, wasNull, err = readFloat64(source); err == nil {
 		err = convertFromFloat64(val, wasNull, dest)
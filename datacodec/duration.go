@@ -16,6 +16,7 @@ package datacodec
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"time"
@@ -34,8 +35,10 @@ type CqlDuration struct {
 }
 
 // Duration is a codec for the CQL duration type, introduced in protocol v5. There is no built-in representation of
-// arbitrary-precision duration values in Go's standard library. This is why this codec can only encode from and decode
-// to CqlDuration.
+// arbitrary-precision duration values in Go's standard library. This is why this codec encodes from and decodes to
+// CqlDuration. It also accepts time.Duration, which can only be decoded if the duration's months and days are zero, and
+// strings, which are parsed with ParseCqlDuration on encode, and formatted with CqlDuration.String on decode.
+// Durations whose components do not all have the same sign are rejected on encode, and cannot be decoded to strings.
 var Duration Codec = &durationCodec{}
 
 type durationCodec struct {
@@ -46,6 +49,10 @@ func (c *durationCodec) DataType() datatype.DataType {
 }
 
 func (c *durationCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return nil, errCannotEncode(source, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	var val CqlDuration
 	var wasNil bool
 	if val, wasNil, err = convertToDuration(source); err == nil && !wasNil {
@@ -58,6 +65,12 @@ func (c *durationCodec) Encode(source interface{}, version primitive.ProtocolVer
 }
 
 func (c *durationCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return false, errCannotDecode(dest, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	var val CqlDuration
 	if val, wasNull, err = readDuration(source); err == nil {
 		err = convertFromDuration(val, wasNull, dest)
@@ -76,11 +89,26 @@ func convertToDuration(source interface{}) (val CqlDuration, wasNil bool, err er
 		if wasNil = s == nil; !wasNil {
 			val = *s
 		}
+	case time.Duration:
+		val = NewCqlDuration(s)
+	case *time.Duration:
+		if wasNil = s == nil; !wasNil {
+			val = NewCqlDuration(*s)
+		}
+	case string:
+		val, err = ParseCqlDuration(s)
+	case *string:
+		if wasNil = s == nil; !wasNil {
+			val, err = ParseCqlDuration(*s)
+		}
 	case nil:
 		wasNil = true
 	default:
 		err = ErrConversionNotSupported
 	}
+	if err == nil && !wasNil {
+		err = val.checkSigns()
+	}
 	if err != nil {
 		err = errSourceConversionFailed(source, val, err)
 	}
@@ -105,6 +133,22 @@ func convertFromDuration(val CqlDuration, wasNull bool, dest interface{}) (err e
 		} else {
 			*d = val
 		}
+	case *time.Duration:
+		if d == nil {
+			err = ErrNilDestination
+		} else if wasNull {
+			*d = 0
+		} else {
+			*d, err = val.ToDuration()
+		}
+	case *string:
+		if d == nil {
+			err = ErrNilDestination
+		} else if wasNull {
+			*d = ""
+		} else if err = val.checkSigns(); err == nil {
+			*d = val.String()
+		}
 	default:
 		err = errDestinationInvalid(dest)
 	}
@@ -114,6 +158,16 @@ func convertFromDuration(val CqlDuration, wasNull bool, dest interface{}) (err e
 	return
 }
 
+var errDurationMixedSigns = errors.New("months, days and nanoseconds must all be positive or zero, or all be negative or zero")
+
+// checkSigns returns an error if the components of this duration do not all have the same sign, see CqlDuration.
+func (d CqlDuration) checkSigns() error {
+	if (d.Months < 0 || d.Days < 0 || d.Nanos < 0) && (d.Months > 0 || d.Days > 0 || d.Nanos > 0) {
+		return errDurationMixedSigns
+	}
+	return nil
+}
+
 // Implementation notes from the protocol specs:
 // A duration is composed of 3 signed variable length integers ([vint]s).
 // The first [vint] represents a number of months, the second [vint] represents
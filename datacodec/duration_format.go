@@ -0,0 +1,281 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewCqlDuration returns a CqlDuration representing the given time.Duration; its months and days are zero.
+func NewCqlDuration(d time.Duration) CqlDuration {
+	return CqlDuration{Nanos: d}
+}
+
+// ToDuration converts this CqlDuration to a time.Duration. This is only possible if its months and days are zero,
+// since the length of a month or a day is not fixed, e.g. because of daylight saving time.
+func (d CqlDuration) ToDuration() (time.Duration, error) {
+	if d.Months != 0 || d.Days != 0 {
+		return 0, fmt.Errorf("cannot convert %v to time.Duration: months and days must be zero", d)
+	}
+	return d.Nanos, nil
+}
+
+// String returns this CqlDuration in Cassandra's standard format, e.g. "1y2mo3d4h5m6s7ms8us9ns", as CQL shells
+// display durations. A zero duration is formatted as "0s". Durations whose components do not all have the same sign
+// are invalid, see CqlDuration, and cannot be represented in this format: the Duration codec refuses to encode them,
+// or to decode them to strings.
+func (d CqlDuration) String() string {
+	if d.Months == 0 && d.Days == 0 && d.Nanos == 0 {
+		return "0s"
+	}
+	sb := &strings.Builder{}
+	months, days, nanos := d.magnitudes(sb)
+	appendUnit(sb, months/12, "y")
+	appendUnit(sb, months%12, "mo")
+	appendUnit(sb, days, "d")
+	for _, unit := range durationUnits {
+		appendUnit(sb, nanos/uint64(unit.nanos), unit.symbol)
+		nanos %= uint64(unit.nanos)
+	}
+	return sb.String()
+}
+
+// ISO8601 returns this CqlDuration in ISO-8601 format, e.g. "P1Y2M3DT4H5M6.007008009S"; a negative duration is
+// prefixed with a minus sign, and a zero duration is formatted as "PT0S". Like with String, the components of the
+// duration must all have the same sign.
+func (d CqlDuration) ISO8601() string {
+	if d.Months == 0 && d.Days == 0 && d.Nanos == 0 {
+		return "PT0S"
+	}
+	sb := &strings.Builder{}
+	months, days, nanos := d.magnitudes(sb)
+	sb.WriteString("P")
+	appendUnit(sb, months/12, "Y")
+	appendUnit(sb, months%12, "M")
+	appendUnit(sb, days, "D")
+	if nanos > 0 {
+		sb.WriteString("T")
+		appendUnit(sb, nanos/uint64(time.Hour), "H")
+		nanos %= uint64(time.Hour)
+		appendUnit(sb, nanos/uint64(time.Minute), "M")
+		nanos %= uint64(time.Minute)
+		if nanos > 0 {
+			sb.WriteString(strconv.FormatUint(nanos/uint64(time.Second), 10))
+			if fraction := nanos % uint64(time.Second); fraction > 0 {
+				sb.WriteString(strings.TrimRight(fmt.Sprintf(".%09d", fraction), "0"))
+			}
+			sb.WriteString("S")
+		}
+	}
+	return sb.String()
+}
+
+// magnitudes writes a minus sign to the given builder if this duration is negative, then returns the absolute values
+// of its components.
+func (d CqlDuration) magnitudes(sb *strings.Builder) (months, days, nanos uint64) {
+	if d.Months < 0 || d.Days < 0 || d.Nanos < 0 {
+		sb.WriteString("-")
+	}
+	return abs64(int64(d.Months)), abs64(int64(d.Days)), abs64(int64(d.Nanos))
+}
+
+func abs64(i int64) uint64 {
+	if i < 0 {
+		return uint64(-(i + 1)) + 1
+	}
+	return uint64(i)
+}
+
+func appendUnit(sb *strings.Builder, value uint64, symbol string) {
+	if value > 0 {
+		sb.WriteString(strconv.FormatUint(value, 10))
+		sb.WriteString(symbol)
+	}
+}
+
+// durationUnits are the units of the nanos component in Cassandra's standard format, from largest to smallest.
+var durationUnits = []struct {
+	symbol string
+	nanos  time.Duration
+}{
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+	{"ns", time.Nanosecond},
+}
+
+var (
+	standardDurationPattern = regexp.MustCompile(`(?i)(\d+)(y|mo|w|d|h|s|ms|us|µs|ns|m)`)
+	isoDurationPattern      = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)(?:\.(\d{1,9}))?S)?)?$`)
+	isoWeekDurationPattern  = regexp.MustCompile(`^P(\d+)W$`)
+	isoAlternativePattern   = regexp.MustCompile(`^P(\d{4})-(\d{2})-(\d{2})T(\d{2}):(\d{2}):(\d{2})$`)
+)
+
+var errInvalidDurationFormat = errors.New("invalid duration format")
+
+// ParseCqlDuration parses a duration in any of the formats accepted by Cassandra, optionally prefixed with a minus
+// sign:
+//   - Cassandra's standard format, e.g. "1y2mo3w4d5h6m7s8ms9us10ns"; units are case-insensitive, "µs" is accepted
+//     for microseconds, and units can appear in any order, but only once;
+//   - the ISO-8601 format, e.g. "P3Y6M4DT12H30M5S"; unlike Cassandra, fractional seconds are accepted, e.g.
+//     "PT1.5S", so that the output of CqlDuration.ISO8601 can always be parsed back;
+//   - the ISO-8601 week format, e.g. "P2W";
+//   - the ISO-8601 alternative format, e.g. "P0003-06-04T12:30:05".
+func ParseCqlDuration(s string) (CqlDuration, error) {
+	input := s
+	negative := strings.HasPrefix(s, "-")
+	if negative {
+		s = s[1:]
+	}
+	b := &durationBuilder{negative: negative}
+	var err error
+	if strings.HasPrefix(s, "P") {
+		err = b.parseISO8601(s)
+	} else {
+		err = b.parseStandard(s)
+	}
+	if err != nil {
+		return CqlDuration{}, fmt.Errorf("cannot parse duration %q: %w", input, err)
+	}
+	return b.build(), nil
+}
+
+// durationBuilder accumulates the magnitudes of a duration's components, checking for overflows. The magnitudes of
+// negative durations can be one more than the maximum positive values, e.g. math.MinInt64 nanoseconds.
+type durationBuilder struct {
+	negative bool
+	months   uint64
+	days     uint64
+	nanos    uint64
+	err      error
+}
+
+func (b *durationBuilder) parseStandard(s string) error {
+	if s == "" {
+		return errInvalidDurationFormat
+	}
+	matches := standardDurationPattern.FindAllStringSubmatchIndex(s, -1)
+	end := 0
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		if match[0] != end {
+			return errInvalidDurationFormat
+		}
+		end = match[1]
+		unit := strings.ToLower(s[match[4]:match[5]])
+		if unit == "µs" {
+			unit = "us"
+		}
+		if seen[unit] {
+			return fmt.Errorf("unit %v specified more than once", unit)
+		}
+		seen[unit] = true
+		value := s[match[2]:match[3]]
+		switch unit {
+		case "y":
+			b.addMonths(value, 12)
+		case "mo":
+			b.addMonths(value, 1)
+		case "w":
+			b.addDays(value, 7)
+		case "d":
+			b.addDays(value, 1)
+		default:
+			for _, u := range durationUnits {
+				if u.symbol == unit {
+					b.addNanos(value, uint64(u.nanos))
+				}
+			}
+		}
+	}
+	if end != len(s) {
+		return errInvalidDurationFormat
+	}
+	return b.err
+}
+
+func (b *durationBuilder) parseISO8601(s string) error {
+	if match := isoDurationPattern.FindStringSubmatch(s); match != nil && s != "P" && !strings.HasSuffix(s, "T") {
+		b.addMonths(match[1], 12)
+		b.addMonths(match[2], 1)
+		b.addDays(match[3], 1)
+		b.addNanos(match[4], uint64(time.Hour))
+		b.addNanos(match[5], uint64(time.Minute))
+		b.addNanos(match[6], uint64(time.Second))
+		if fraction := match[7]; fraction != "" {
+			b.addNanos(fraction+strings.Repeat("0", 9-len(fraction)), 1)
+		}
+	} else if match := isoWeekDurationPattern.FindStringSubmatch(s); match != nil {
+		b.addDays(match[1], 7)
+	} else if match := isoAlternativePattern.FindStringSubmatch(s); match != nil {
+		b.addMonths(match[1], 12)
+		b.addMonths(match[2], 1)
+		b.addDays(match[3], 1)
+		b.addNanos(match[4], uint64(time.Hour))
+		b.addNanos(match[5], uint64(time.Minute))
+		b.addNanos(match[6], uint64(time.Second))
+	} else {
+		return errInvalidDurationFormat
+	}
+	return b.err
+}
+
+func (b *durationBuilder) addMonths(value string, factor uint64) {
+	b.months = b.add(b.months, value, factor, b.maxMagnitude(math.MaxInt32))
+}
+
+func (b *durationBuilder) addDays(value string, factor uint64) {
+	b.days = b.add(b.days, value, factor, b.maxMagnitude(math.MaxInt32))
+}
+
+func (b *durationBuilder) addNanos(value string, factor uint64) {
+	b.nanos = b.add(b.nanos, value, factor, b.maxMagnitude(math.MaxInt64))
+}
+
+func (b *durationBuilder) maxMagnitude(maxPositive uint64) uint64 {
+	if b.negative {
+		return maxPositive + 1
+	}
+	return maxPositive
+}
+
+// add returns total + value * factor, or records an error if the result exceeds max; empty values are ignored.
+func (b *durationBuilder) add(total uint64, value string, factor uint64, max uint64) uint64 {
+	if b.err != nil || value == "" {
+		return total
+	}
+	v, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || v > (max-total)/factor {
+		b.err = errValueOutOfRange(value)
+		return total
+	}
+	return total + v*factor
+}
+
+func (b *durationBuilder) build() CqlDuration {
+	if b.negative {
+		// negating in unsigned arithmetic, the magnitudes of the minimum values do not overflow
+		return CqlDuration{Months: int32(-b.months), Days: int32(-b.days), Nanos: time.Duration(-b.nanos)}
+	}
+	return CqlDuration{Months: int32(b.months), Days: int32(b.days), Nanos: time.Duration(b.nanos)}
+}
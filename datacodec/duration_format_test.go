@@ -0,0 +1,103 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCqlDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected CqlDuration
+		err      string
+	}{
+		{"standard", "1y2mo3w4d5h6m7s8ms9us10ns", CqlDuration{14, 25, 5*time.Hour + 6*time.Minute + 7*time.Second + 8*time.Millisecond + 9*time.Microsecond + 10}, ""},
+		{"standard negative", "-2d3h", CqlDuration{0, -2, -3 * time.Hour}, ""},
+		{"standard case insensitive", "1Y2MO3D", CqlDuration{14, 3, 0}, ""},
+		{"standard any order", "3m1h", CqlDuration{0, 0, time.Hour + 3*time.Minute}, ""},
+		{"standard micro sign", "5µs", CqlDuration{0, 0, 5 * time.Microsecond}, ""},
+		{"standard zero", "0s", CqlDuration{}, ""},
+		{"ISO-8601", "P3Y6M4DT12H", CqlDuration{42, 4, 12 * time.Hour}, ""},
+		{"ISO-8601 time only", "PT1H2M3.5S", CqlDuration{0, 0, time.Hour + 2*time.Minute + 3500*time.Millisecond}, ""},
+		{"ISO-8601 negative", "-P1D", CqlDuration{0, -1, 0}, ""},
+		{"ISO-8601 week", "P2W", CqlDuration{0, 14, 0}, ""},
+		{"ISO-8601 alternative", "P0003-06-04T12:30:05", CqlDuration{42, 4, 12*time.Hour + 30*time.Minute + 5*time.Second}, ""},
+		{"max", "178956970y7mo2147483647d9223372036854775807ns", CqlDuration{math.MaxInt32, math.MaxInt32, math.MaxInt64}, ""},
+		{"min", "-178956970y8mo2147483648d9223372036854775808ns", CqlDuration{math.MinInt32, math.MinInt32, math.MinInt64}, ""},
+		{"empty", "", CqlDuration{}, `cannot parse duration "": invalid duration format`},
+		{"no unit", "12", CqlDuration{}, `cannot parse duration "12": invalid duration format`},
+		{"unknown unit", "1d2x", CqlDuration{}, `cannot parse duration "1d2x": invalid duration format`},
+		{"leading garbage", "x1d", CqlDuration{}, `cannot parse duration "x1d": invalid duration format`},
+		{"repeated unit", "1h2H", CqlDuration{}, `cannot parse duration "1h2H": unit h specified more than once`},
+		{"ISO-8601 empty", "P", CqlDuration{}, `cannot parse duration "P": invalid duration format`},
+		{"ISO-8601 empty time", "P1DT", CqlDuration{}, `cannot parse duration "P1DT": invalid duration format`},
+		{"ISO-8601 wrong order", "P1D2Y", CqlDuration{}, `cannot parse duration "P1D2Y": invalid duration format`},
+		{"months out of range", "2147483648mo", CqlDuration{}, `cannot parse duration "2147483648mo": value out of range: 2147483648`},
+		{"months out of range cumulated", "178956970y8mo", CqlDuration{}, `cannot parse duration "178956970y8mo": value out of range: 8`},
+		{"nanos out of range", "2562048h", CqlDuration{}, `cannot parse duration "2562048h": value out of range: 2562048`},
+		{"nanos out of range positive", "9223372036854775808ns", CqlDuration{}, `cannot parse duration "9223372036854775808ns": value out of range: 9223372036854775808`},
+		{"nanos out of range negative", "-9223372036854775809ns", CqlDuration{}, `cannot parse duration "-9223372036854775809ns": value out of range: 9223372036854775809`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ParseCqlDuration(tt.input)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
+
+func TestCqlDuration_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    CqlDuration
+		standard string
+		iso      string
+	}{
+		{"zero", CqlDuration{}, "0s", "PT0S"},
+		{"pos", cqlDurationPos, "1mo2d3ns", "P1M2DT0.000000003S"},
+		{"neg", cqlDurationNeg, "-1mo2d3ns", "-P1M2DT0.000000003S"},
+		{"date only", CqlDuration{42, 4, 0}, "3y6mo4d", "P3Y6M4D"},
+		{"time only", CqlDuration{0, 0, time.Hour + 2*time.Minute + 3500*time.Millisecond}, "1h2m3s500ms", "PT1H2M3.5S"},
+		{"max", cqlDurationMax, "178956970y7mo2147483647d2562047h47m16s854ms775us807ns", "P178956970Y7M2147483647DT2562047H47M16.854775807S"},
+		{"min", cqlDurationMin, "-178956970y8mo2147483648d2562047h47m16s854ms775us808ns", "-P178956970Y8M2147483648DT2562047H47M16.854775808S"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.standard, tt.input.String())
+			assert.Equal(t, tt.iso, tt.input.ISO8601())
+			parsed, err := ParseCqlDuration(tt.standard)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.input, parsed)
+			parsed, err = ParseCqlDuration(tt.iso)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.input, parsed)
+		})
+	}
+}
+
+func TestCqlDuration_ToDuration(t *testing.T) {
+	d, err := NewCqlDuration(-time.Minute).ToDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, -time.Minute, d)
+	_, err = CqlDuration{Days: 1}.ToDuration()
+	assertErrorMessage(t, "cannot convert 1d to time.Duration: months and days must be zero", err)
+}
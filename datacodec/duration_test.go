@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -56,7 +57,12 @@ func Test_durationCodec_Encode(t *testing.T) {
 				{"nil pointer", cqlDurationNilPtr(), nil, ""},
 				{"non nil", cqlDurationPos, cqlDurationPosBytes, ""},
 				{"non nil pointer", &cqlDurationPos, cqlDurationPosBytes, ""},
+				{"time.Duration", time.Duration(3), []byte{0, 0, 6}, ""},
+				{"string", "1mo2d3ns", cqlDurationPosBytes, ""},
+				{"string ISO-8601", "-P1M2DT0.000000003S", cqlDurationNegBytes, ""},
+				{"string invalid", "1x", nil, fmt.Sprintf("cannot encode string as CQL duration with %v: cannot convert from string to datacodec.CqlDuration: cannot parse duration \"1x\": invalid duration format", version)},
 				{"conversion failed", 123, nil, fmt.Sprintf("cannot encode int as CQL duration with %v: cannot convert from int to datacodec.CqlDuration: conversion not supported", version)},
+				{"mixed signs", CqlDuration{Months: 1, Nanos: -1}, nil, fmt.Sprintf("cannot encode datacodec.CqlDuration as CQL duration with %v: cannot convert from datacodec.CqlDuration to datacodec.CqlDuration: months, days and nanoseconds must all be positive or zero, or all be negative or zero", version)},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -83,6 +89,9 @@ func Test_durationCodec_Decode(t *testing.T) {
 				{"null", nil, new(CqlDuration), new(CqlDuration), true, ""},
 				{"non null", cqlDurationPosBytes, new(CqlDuration), &cqlDurationPos, false, ""},
 				{"non null interface", cqlDurationPosBytes, new(interface{}), interfacePtr(cqlDurationPos), false, ""},
+				{"non null time.Duration", []byte{0, 0, 6}, new(time.Duration), durationPtr(3), false, ""},
+				{"non null string", cqlDurationPosBytes, new(string), stringPtr("1mo2d3ns"), false, ""},
+				{"time.Duration with days", cqlDurationPosBytes, new(time.Duration), new(time.Duration), false, fmt.Sprintf("cannot decode CQL duration as *time.Duration with %v: cannot convert from datacodec.CqlDuration to *time.Duration: cannot convert 1mo2d3ns to time.Duration: months and days must be zero", version)},
 				{"read failed", []byte{1}, new(CqlDuration), new(CqlDuration), false, fmt.Sprintf("cannot decode CQL duration as *datacodec.CqlDuration with %v: cannot read datacodec.CqlDuration: cannot read duration days: cannot read [vint]: cannot read [unsigned vint]: EOF", version)},
 				{"conversion failed", cqlDurationPosBytes, new(float64), new(float64), false, fmt.Sprintf("cannot decode CQL duration as *float64 with %v: cannot convert from datacodec.CqlDuration to *float64: conversion not supported", version)},
 			}
@@ -109,6 +118,14 @@ func Test_convertToDuration(t *testing.T) {
 		{"from CqlDuration", cqlDurationPos, cqlDurationPos, false, ""},
 		{"from *CqlDuration", &cqlDurationPos, cqlDurationPos, false, ""},
 		{"from *CqlDuration nil", cqlDurationNilPtr(), cqlDurationZero, true, ""},
+		{"from time.Duration", time.Hour, CqlDuration{Nanos: time.Hour}, false, ""},
+		{"from *time.Duration", durationPtr(-time.Hour), CqlDuration{Nanos: -time.Hour}, false, ""},
+		{"from *time.Duration nil", durationNilPtr(), cqlDurationZero, true, ""},
+		{"from string", "1y2mo3w4d5h", CqlDuration{14, 25, 5 * time.Hour}, false, ""},
+		{"from string ISO-8601", "P1Y2M25DT5H", CqlDuration{14, 25, 5 * time.Hour}, false, ""},
+		{"from *string", stringPtr("-1d"), CqlDuration{Days: -1}, false, ""},
+		{"from *string nil", stringNilPtr(), cqlDurationZero, true, ""},
+		{"from string invalid", "1d1d", cqlDurationZero, false, "cannot convert from string to datacodec.CqlDuration: cannot parse duration \"1d1d\": unit d specified more than once"},
 		{"from untyped nil", nil, cqlDurationZero, true, ""},
 		{"from unsupported value type", 123, cqlDurationZero, false, "cannot convert from int to datacodec.CqlDuration: conversion not supported"},
 		{"from unsupported pointer type", intPtr(123), cqlDurationZero, false, "cannot convert from *int to datacodec.CqlDuration: conversion not supported"},
@@ -139,6 +156,14 @@ func Test_convertFromDuration(t *testing.T) {
 		{"to *CqlDuration nil source", cqlDurationZero, true, new(CqlDuration), new(CqlDuration), ""},
 		{"to *CqlDuration empty source", cqlDurationZero, false, new(CqlDuration), new(CqlDuration), ""},
 		{"to *CqlDuration non nil", cqlDurationPos, false, new(CqlDuration), &cqlDurationPos, ""},
+		{"to *time.Duration nil dest", cqlDurationZero, false, durationNilPtr(), durationNilPtr(), "cannot convert from datacodec.CqlDuration to *time.Duration: destination is nil"},
+		{"to *time.Duration nil source", cqlDurationZero, true, durationPtr(1), new(time.Duration), ""},
+		{"to *time.Duration non nil", CqlDuration{Nanos: time.Second}, false, new(time.Duration), durationPtr(time.Second), ""},
+		{"to *time.Duration months", CqlDuration{Months: 1}, false, new(time.Duration), new(time.Duration), "cannot convert from datacodec.CqlDuration to *time.Duration: cannot convert 1mo to time.Duration: months and days must be zero"},
+		{"to *string nil dest", cqlDurationZero, false, stringNilPtr(), stringNilPtr(), "cannot convert from datacodec.CqlDuration to *string: destination is nil"},
+		{"to *string nil source", cqlDurationZero, true, stringPtr("x"), new(string), ""},
+		{"to *string non nil", cqlDurationNeg, false, new(string), stringPtr("-1mo2d3ns"), ""},
+		{"to *string mixed signs", CqlDuration{Months: 1, Nanos: -1}, false, new(string), new(string), "cannot convert from datacodec.CqlDuration to *string: months, days and nanoseconds must all be positive or zero, or all be negative or zero"},
 		{"to untyped nil", cqlDurationPos, false, nil, nil, "cannot convert from datacodec.CqlDuration to <nil>: destination is nil"},
 		{"to non pointer", cqlDurationPos, false, CqlDuration{}, CqlDuration{}, "cannot convert from datacodec.CqlDuration to datacodec.CqlDuration: destination is not pointer"},
 		{"to unsupported pointer type", cqlDurationPos, false, new(float64), new(float64), "cannot convert from datacodec.CqlDuration to *float64: conversion not supported"},
@@ -199,3 +224,5 @@ func Test_readDuration(t *testing.T) {
 		})
 	}
 }
+
+func durationPtr(v time.Duration) *time.Duration { return &v }
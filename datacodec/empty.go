@@ -0,0 +1,36 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+// CQL distinguishes NULL values from empty values: a NULL is encoded as a nil []byte, and written with a negative
+// length, whereas an empty value is encoded as a non-nil, zero-length []byte, and written with a zero length. See the
+// "Empty values" section of the package documentation for how codecs handle them.
+
+// IsNull returns true if the given encoded value is a CQL NULL, that is, if it is nil.
+func IsNull(encoded []byte) bool {
+	return encoded == nil
+}
+
+// IsEmpty returns true if the given encoded value is a CQL empty value, that is, if it is zero-length but not nil.
+func IsEmpty(encoded []byte) bool {
+	return encoded != nil && len(encoded) == 0
+}
+
+// EmptyValue returns a new CQL empty value. Only the codecs for varchar, ascii and blob produce empty values; this
+// function can be used to intentionally write an empty value for any other CQL type, e.g. in a message.Row or in a
+// primitive.Value, see also primitive.NewEmptyValue.
+func EmptyValue() []byte {
+	return []byte{}
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestIsNullIsEmpty(t *testing.T) {
+	assert.True(t, IsNull(nil))
+	assert.False(t, IsNull([]byte{}))
+	assert.False(t, IsNull(EmptyValue()))
+	assert.False(t, IsEmpty(nil))
+	assert.True(t, IsEmpty([]byte{}))
+	assert.True(t, IsEmpty(EmptyValue()))
+	assert.False(t, IsEmpty([]byte{0}))
+	assert.False(t, IsNull([]byte{0}))
+}
+
+func TestEmptyVsNull_Strings(t *testing.T) {
+	for _, codec := range []Codec{Varchar, StrictVarchar, Ascii, Blob} {
+		t.Run(codec.DataType().AsCql(), func(t *testing.T) {
+			for _, version := range primitive.SupportedProtocolVersions() {
+				t.Run(version.String(), func(t *testing.T) {
+					for _, source := range []interface{}{"", stringPtr(""), []byte{}, &[]byte{}} {
+						encoded, err := codec.Encode(source, version)
+						require.NoError(t, err)
+						assert.True(t, IsEmpty(encoded))
+						var decoded interface{}
+						wasNull, err := codec.Decode(encoded, &decoded, version)
+						require.NoError(t, err)
+						assert.False(t, wasNull)
+						assert.NotNil(t, decoded)
+						assert.Empty(t, decoded)
+					}
+					for _, source := range []interface{}{nil, stringNilPtr(), []byte(nil)} {
+						encoded, err := codec.Encode(source, version)
+						require.NoError(t, err)
+						assert.True(t, IsNull(encoded))
+						var decoded interface{}
+						wasNull, err := codec.Decode(encoded, &decoded, version)
+						require.NoError(t, err)
+						assert.True(t, wasNull)
+						assert.Nil(t, decoded)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestEmptyVsNull_OtherTypes(t *testing.T) {
+	for _, codec := range []Codec{Bigint, Boolean, Date, Decimal, Double, Float, Inet, Int, Time, Timestamp, Uuid, Varint} {
+		t.Run(codec.DataType().AsCql(), func(t *testing.T) {
+			var decoded interface{}
+			wasNull, err := codec.Decode(EmptyValue(), &decoded, primitive.ProtocolVersion5)
+			require.NoError(t, err)
+			assert.True(t, wasNull)
+			assert.Nil(t, decoded)
+		})
+	}
+}
+
+func TestEmptyVsNull_Elements(t *testing.T) {
+	udtType, err := datatype.NewUserDefined("ks1", "type1", []string{"f1", "f2"}, []datatype.DataType{datatype.Varchar, datatype.Varchar})
+	require.NoError(t, err)
+	tests := []struct {
+		name     string
+		dataType datatype.DataType
+		source   interface{}
+		dest     interface{}
+	}{
+		{"list", datatype.NewList(datatype.Varchar), []string{"", "abc"}, new([]string)},
+		{"set", datatype.NewSet(datatype.Blob), [][]byte{{}, {1}}, new([][]byte)},
+		{"map", datatype.NewMap(datatype.Varchar, datatype.Varchar), map[string]string{"": "", "abc": ""}, new(map[string]string)},
+		{"tuple", datatype.NewTuple(datatype.Varchar, datatype.Varchar), []interface{}{"", nil}, new([]interface{})},
+		{"udt", udtType, map[string]interface{}{"f1": "", "f2": nil}, new(map[string]interface{})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec, err := NewCodec(tt.dataType)
+			require.NoError(t, err)
+			encoded, err := codec.Encode(tt.source, primitive.ProtocolVersion5)
+			require.NoError(t, err)
+			wasNull, err := codec.Decode(encoded, tt.dest, primitive.ProtocolVersion5)
+			require.NoError(t, err)
+			assert.False(t, wasNull)
+			assert.Equal(t, tt.source, reflect.ValueOf(tt.dest).Elem().Interface())
+		})
+	}
+}
+
+func TestEmptyVsNull_Rows(t *testing.T) {
+	codec, err := NewRowCodec(&message.RowsMetadata{
+		ColumnCount: 2,
+		Columns: []*message.ColumnMetadata{
+			{Keyspace: "ks1", Table: "t1", Name: "c1", Index: 0, Type: datatype.Varchar},
+			{Keyspace: "ks1", Table: "t1", Name: "c2", Index: 1, Type: datatype.Varchar},
+		},
+	})
+	require.NoError(t, err)
+	row, err := codec.Encode(map[string]interface{}{"c1": "", "c2": Null}, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	assert.True(t, IsEmpty(row[0]))
+	assert.True(t, IsNull(row[1]))
+	values, err := codec.Decode(row, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"c1": "", "c2": Null}, values)
+}
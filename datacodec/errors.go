@@ -32,16 +32,25 @@ var ErrDestinationTypeNotSupported = errors.New("destination type not supported"
 
 var ErrPointerTypeExpected = errors.New("destination is not pointer")
 
+// ErrDataTypeNotSupported is returned when encoding or decoding a data type that the protocol version in use does
+// not support, e.g. smallint with protocol version 3. See primitive.ProtocolVersion.SupportsDataType.
+var ErrDataTypeNotSupported = errors.New("data type not supported in this protocol version")
+
 func errCannotEncode(source interface{}, dataType datatype.DataType, version primitive.ProtocolVersion, err error) error {
-	return fmt.Errorf("cannot encode %T as CQL %s with %v: %w", source, dataType, version, err)
+	return fmt.Errorf("cannot encode %T as CQL %s with %v: %w", source, asCql(dataType), version, err)
 }
 
 func errCannotDecode(dest interface{}, dataType datatype.DataType, version primitive.ProtocolVersion, err error) error {
-	return fmt.Errorf("cannot decode CQL %s as %T with %v: %w", dataType, dest, version, err)
+	return fmt.Errorf("cannot decode CQL %s as %T with %v: %w", asCql(dataType), dest, version, err)
 }
 
-func errDataTypeNotSupported(dataType datatype.DataType, version primitive.ProtocolVersion) error {
-	return fmt.Errorf("data type %s not supported in %v", dataType, version)
+// asCql formats the given data type for error messages; unlike String, AsCql includes the fields of user-defined
+// types.
+func asCql(dataType datatype.DataType) string {
+	if dataType == nil {
+		return "<nil>"
+	}
+	return dataType.AsCql()
 }
 
 func errSourceConversionFailed(from interface{}, to interface{}, err error) error {
@@ -103,6 +112,10 @@ func errWrongDataType(desc string, expected1, expected2, actual datatype.DataTyp
 	return fmt.Errorf("wrong %s, expected %s or %s, got: %v", desc, expected1, expected2, actual)
 }
 
+func errInvalidUtf8(offset int) error {
+	return fmt.Errorf("invalid UTF-8 sequence at byte offset %d", offset)
+}
+
 func errBytesRemaining(total int, remaining int) error {
 	return fmt.Errorf("source was not fully read: bytes total: %d, read: %d, remaining: %d", total, total-remaining, remaining)
 }
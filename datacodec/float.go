@@ -33,6 +33,7 @@ func (c *floatCodec) DataType() datatype.DataType {
 }
 
 func (c *floatCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	var val float32
 	var wasNil bool
 	if val, wasNil, err = convertToFloat32(source); err == nil && !wasNil {
@@ -45,6 +46,9 @@ func (c *floatCodec) Encode(source interface{}, version primitive.ProtocolVersio
 }
 
 func (c *floatCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	var val float32
 	if val, wasNull, err = readFloat32(source); err == nil {
 		err = convertFromFloat32(val, wasNull, dest)
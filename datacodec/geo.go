@@ -0,0 +1,541 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// CqlPoint is a DSE geospatial point, see datatype.DsePoint.
+type CqlPoint struct {
+	X float64
+	Y float64
+}
+
+// CqlLineString is a DSE geospatial line string, see datatype.DseLineString.
+type CqlLineString struct {
+	Points []CqlPoint
+}
+
+// CqlPolygon is a DSE geospatial polygon, see datatype.DsePolygon. The first ring is the exterior ring, and the
+// others, if any, are interior rings, i.e. holes.
+type CqlPolygon struct {
+	Rings [][]CqlPoint
+}
+
+// Point is a codec for the DSE geospatial type datatype.DsePoint. It encodes from and decodes to CqlPoint, and also
+// accepts strings in the Well-Known Text (WKT) format, e.g. "POINT (1 2)", which are parsed with ParseCqlPoint on
+// encode, and formatted with CqlPoint.String on decode.
+var Point Codec = &geoCodec{dataType: datatype.DsePoint, wkbType: wkbPoint}
+
+// LineString is a codec for the DSE geospatial type datatype.DseLineString. It encodes from and decodes to
+// CqlLineString, and also accepts strings in the Well-Known Text (WKT) format, e.g. "LINESTRING (1 2, 3 4)", which
+// are parsed with ParseCqlLineString on encode, and formatted with CqlLineString.String on decode.
+var LineString Codec = &geoCodec{dataType: datatype.DseLineString, wkbType: wkbLineString}
+
+// Polygon is a codec for the DSE geospatial type datatype.DsePolygon. It encodes from and decodes to CqlPolygon, and
+// also accepts strings in the Well-Known Text (WKT) format, e.g. "POLYGON ((0 0, 1 0, 1 1, 0 0))", which are parsed
+// with ParseCqlPolygon on encode, and formatted with CqlPolygon.String on decode.
+var Polygon Codec = &geoCodec{dataType: datatype.DsePolygon, wkbType: wkbPolygon}
+
+// newGeoCodec returns the geospatial codec for the given data type, or nil if it is not a DSE geospatial type.
+func newGeoCodec(dt datatype.DataType) *geoCodec {
+	if customType, ok := dt.(*datatype.Custom); ok {
+		switch customType.ClassName {
+		case datatype.DsePointClassName:
+			return Point.(*geoCodec)
+		case datatype.DseLineStringClassName:
+			return LineString.(*geoCodec)
+		case datatype.DsePolygonClassName:
+			return Polygon.(*geoCodec)
+		}
+	}
+	return nil
+}
+
+// WKB geometry types.
+const (
+	wkbPoint      = uint32(1)
+	wkbLineString = uint32(2)
+	wkbPolygon    = uint32(3)
+)
+
+type geoCodec struct {
+	dataType *datatype.Custom
+	wkbType  uint32
+}
+
+func (c *geoCodec) DataType() datatype.DataType {
+	return c.dataType
+}
+
+func (c *geoCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
+	var val geometry
+	var wasNil bool
+	if val, wasNil, err = c.convertTo(source); err == nil && !wasNil {
+		dest = writeGeometry(val)
+	}
+	if err != nil {
+		err = errCannotEncode(source, c.DataType(), version, err)
+	}
+	return
+}
+
+func (c *geoCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
+	var val geometry
+	if val, wasNull, err = readGeometry(source, c.wkbType); err == nil {
+		err = c.convertFrom(val, wasNull, dest)
+	}
+	if err != nil {
+		err = errCannotDecode(dest, c.DataType(), version, err)
+	}
+	return
+}
+
+// geometry is implemented by CqlPoint, CqlLineString and CqlPolygon.
+type geometry interface {
+	wkbType() uint32
+	String() string
+}
+
+func (c *geoCodec) convertTo(source interface{}) (val geometry, wasNil bool, err error) {
+	switch s := source.(type) {
+	case CqlPoint:
+		val = s
+	case *CqlPoint:
+		if wasNil = s == nil; !wasNil {
+			val = *s
+		}
+	case CqlLineString:
+		val = s
+	case *CqlLineString:
+		if wasNil = s == nil; !wasNil {
+			val = *s
+		}
+	case CqlPolygon:
+		val = s
+	case *CqlPolygon:
+		if wasNil = s == nil; !wasNil {
+			val = *s
+		}
+	case string:
+		val, err = c.parse(s)
+	case *string:
+		if wasNil = s == nil; !wasNil {
+			val, err = c.parse(*s)
+		}
+	case nil:
+		wasNil = true
+	default:
+		err = ErrConversionNotSupported
+	}
+	if err == nil && !wasNil && val.wkbType() != c.wkbType {
+		err = ErrConversionNotSupported
+	}
+	if err != nil {
+		err = errSourceConversionFailed(source, c.zero(), err)
+	}
+	return
+}
+
+func (c *geoCodec) convertFrom(val geometry, wasNull bool, dest interface{}) (err error) {
+	if wasNull {
+		val = c.zero()
+	}
+	switch d := dest.(type) {
+	case *interface{}:
+		if d == nil {
+			err = ErrNilDestination
+		} else if wasNull {
+			*d = nil
+		} else {
+			*d = val
+		}
+	case *CqlPoint:
+		if d == nil {
+			err = ErrNilDestination
+		} else if p, ok := val.(CqlPoint); !ok {
+			err = errDestinationInvalid(dest)
+		} else {
+			*d = p
+		}
+	case *CqlLineString:
+		if d == nil {
+			err = ErrNilDestination
+		} else if l, ok := val.(CqlLineString); !ok {
+			err = errDestinationInvalid(dest)
+		} else {
+			*d = l
+		}
+	case *CqlPolygon:
+		if d == nil {
+			err = ErrNilDestination
+		} else if p, ok := val.(CqlPolygon); !ok {
+			err = errDestinationInvalid(dest)
+		} else {
+			*d = p
+		}
+	case *string:
+		if d == nil {
+			err = ErrNilDestination
+		} else if wasNull {
+			*d = ""
+		} else {
+			*d = val.String()
+		}
+	default:
+		err = errDestinationInvalid(dest)
+	}
+	if err != nil {
+		err = errDestinationConversionFailed(val, dest, err)
+	}
+	return
+}
+
+func (c *geoCodec) zero() geometry {
+	switch c.wkbType {
+	case wkbLineString:
+		return CqlLineString{}
+	case wkbPolygon:
+		return CqlPolygon{}
+	}
+	return CqlPoint{}
+}
+
+func (c *geoCodec) parse(s string) (geometry, error) {
+	switch c.wkbType {
+	case wkbLineString:
+		return ParseCqlLineString(s)
+	case wkbPolygon:
+		return ParseCqlPolygon(s)
+	}
+	return ParseCqlPoint(s)
+}
+
+func (p CqlPoint) wkbType() uint32 {
+	return wkbPoint
+}
+
+func (l CqlLineString) wkbType() uint32 {
+	return wkbLineString
+}
+
+func (p CqlPolygon) wkbType() uint32 {
+	return wkbPolygon
+}
+
+// String returns the Well-Known Text (WKT) representation of this point, e.g. "POINT (1 2)".
+func (p CqlPoint) String() string {
+	return "POINT (" + formatWktPoint(p) + ")"
+}
+
+// String returns the Well-Known Text (WKT) representation of this line string, e.g. "LINESTRING (1 2, 3 4)".
+func (l CqlLineString) String() string {
+	if len(l.Points) == 0 {
+		return "LINESTRING EMPTY"
+	}
+	return "LINESTRING " + formatWktPoints(l.Points)
+}
+
+// String returns the Well-Known Text (WKT) representation of this polygon, e.g. "POLYGON ((0 0, 1 0, 1 1, 0 0))".
+func (p CqlPolygon) String() string {
+	if len(p.Rings) == 0 {
+		return "POLYGON EMPTY"
+	}
+	var b strings.Builder
+	b.WriteString("POLYGON (")
+	for i, ring := range p.Rings {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(formatWktPoints(ring))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func formatWktPoint(p CqlPoint) string {
+	return strconv.FormatFloat(p.X, 'f', -1, 64) + " " + strconv.FormatFloat(p.Y, 'f', -1, 64)
+}
+
+func formatWktPoints(points []CqlPoint) string {
+	formatted := make([]string, len(points))
+	for i, p := range points {
+		formatted[i] = formatWktPoint(p)
+	}
+	return "(" + strings.Join(formatted, ", ") + ")"
+}
+
+var errInvalidWkt = errors.New("invalid WKT")
+
+// ParseCqlPoint parses the Well-Known Text (WKT) representation of a point, e.g. "POINT (1 2)". Keywords are
+// case-insensitive.
+func ParseCqlPoint(s string) (CqlPoint, error) {
+	var p CqlPoint
+	body, err := parseWktTag(s, "POINT")
+	if err == nil {
+		if body == "" {
+			err = errInvalidWkt
+		} else {
+			p, err = parseWktPoint(body)
+		}
+	}
+	if err != nil {
+		return CqlPoint{}, fmt.Errorf("cannot parse point %q: %w", s, err)
+	}
+	return p, nil
+}
+
+// ParseCqlLineString parses the Well-Known Text (WKT) representation of a line string, e.g. "LINESTRING (1 2, 3 4)"
+// or "LINESTRING EMPTY". Keywords are case-insensitive.
+func ParseCqlLineString(s string) (CqlLineString, error) {
+	var l CqlLineString
+	body, err := parseWktTag(s, "LINESTRING")
+	if err == nil && body != "" {
+		l.Points, err = parseWktPoints(body)
+	}
+	if err != nil {
+		return CqlLineString{}, fmt.Errorf("cannot parse line string %q: %w", s, err)
+	}
+	return l, nil
+}
+
+// ParseCqlPolygon parses the Well-Known Text (WKT) representation of a polygon, e.g.
+// "POLYGON ((0 0, 10 0, 10 10, 0 0), (1 1, 2 1, 2 2, 1 1))" or "POLYGON EMPTY". Keywords are case-insensitive.
+func ParseCqlPolygon(s string) (CqlPolygon, error) {
+	var p CqlPolygon
+	body, err := parseWktTag(s, "POLYGON")
+	for err == nil && body != "" {
+		end := strings.IndexByte(body, ')')
+		if !strings.HasPrefix(body, "(") || end < 0 {
+			err = errInvalidWkt
+			break
+		}
+		var ring []CqlPoint
+		if ring, err = parseWktPoints(body[1:end]); err == nil {
+			p.Rings = append(p.Rings, ring)
+			if body = strings.TrimSpace(body[end+1:]); body != "" {
+				if !strings.HasPrefix(body, ",") {
+					err = errInvalidWkt
+				}
+				body = strings.TrimSpace(body[1:])
+			}
+		}
+	}
+	if err != nil {
+		return CqlPolygon{}, fmt.Errorf("cannot parse polygon %q: %w", s, err)
+	}
+	return p, nil
+}
+
+// parseWktTag checks that the given WKT string starts with the given tag, and returns the contents of the outermost
+// parentheses that follow it, or an empty string if the tag is followed by EMPTY.
+func parseWktTag(s string, tag string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < len(tag) || !strings.EqualFold(s[:len(tag)], tag) {
+		return "", fmt.Errorf("%w: expecting %v", errInvalidWkt, tag)
+	}
+	s = strings.TrimSpace(s[len(tag):])
+	if strings.EqualFold(s, "EMPTY") {
+		return "", nil
+	} else if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return "", errInvalidWkt
+	}
+	return strings.TrimSpace(s[1 : len(s)-1]), nil
+}
+
+func parseWktPoints(s string) ([]CqlPoint, error) {
+	var points []CqlPoint
+	for _, coordinates := range strings.Split(s, ",") {
+		p, err := parseWktPoint(coordinates)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+func parseWktPoint(s string) (CqlPoint, error) {
+	coordinates := strings.Fields(s)
+	if len(coordinates) != 2 {
+		return CqlPoint{}, fmt.Errorf("%w: expecting 2 coordinates, got: %q", errInvalidWkt, s)
+	}
+	x, err := strconv.ParseFloat(coordinates[0], 64)
+	if err != nil {
+		return CqlPoint{}, err
+	}
+	y, err := strconv.ParseFloat(coordinates[1], 64)
+	if err != nil {
+		return CqlPoint{}, err
+	}
+	return CqlPoint{X: x, Y: y}, nil
+}
+
+// Implementation notes: geospatial values are encoded in the Well-Known Binary (WKB) format: a byte order byte (0 for
+// big endian, 1 for little endian), a 4-byte geometry type, then the geometry: 2 doubles for a point, a 4-byte number
+// of points followed by the points for a line string, and a 4-byte number of rings followed by the rings, each encoded
+// as a line string, for a polygon. Values are encoded in little endian, and decoded in either byte order.
+
+func writeGeometry(val geometry) []byte {
+	dest := []byte{1}
+	dest = appendWkbUint32(dest, val.wkbType())
+	switch g := val.(type) {
+	case CqlPoint:
+		dest = appendWkbPoint(dest, g)
+	case CqlLineString:
+		dest = appendWkbPoints(dest, g.Points)
+	case CqlPolygon:
+		dest = appendWkbUint32(dest, uint32(len(g.Rings)))
+		for _, ring := range g.Rings {
+			dest = appendWkbPoints(dest, ring)
+		}
+	}
+	return dest
+}
+
+func appendWkbPoints(dest []byte, points []CqlPoint) []byte {
+	dest = appendWkbUint32(dest, uint32(len(points)))
+	for _, p := range points {
+		dest = appendWkbPoint(dest, p)
+	}
+	return dest
+}
+
+func appendWkbPoint(dest []byte, p CqlPoint) []byte {
+	dest = appendWkbUint64(dest, math.Float64bits(p.X))
+	return appendWkbUint64(dest, math.Float64bits(p.Y))
+}
+
+func appendWkbUint32(dest []byte, val uint32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], val)
+	return append(dest, buf[:]...)
+}
+
+func appendWkbUint64(dest []byte, val uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], val)
+	return append(dest, buf[:]...)
+}
+
+// wkbReader reads WKB values, keeping track of the first error.
+type wkbReader struct {
+	source []byte
+	order  binary.ByteOrder
+	err    error
+}
+
+func readGeometry(source []byte, expectedType uint32) (val geometry, wasNull bool, err error) {
+	if wasNull = len(source) == 0; wasNull {
+		return nil, true, nil
+	}
+	r := &wkbReader{source: source[1:]}
+	switch source[0] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return nil, false, fmt.Errorf("cannot read WKB: invalid byte order: %d", source[0])
+	}
+	if actualType := r.readUint32(); r.err == nil && actualType != expectedType {
+		return nil, false, fmt.Errorf("cannot read WKB: expecting geometry type %d, got: %d", expectedType, actualType)
+	}
+	switch expectedType {
+	case wkbPoint:
+		val = r.readPoint()
+	case wkbLineString:
+		val = CqlLineString{Points: r.readPoints()}
+	case wkbPolygon:
+		var polygon CqlPolygon
+		for n := r.readCount(4); r.err == nil && n > 0; n-- {
+			polygon.Rings = append(polygon.Rings, r.readPoints())
+		}
+		val = polygon
+	}
+	if r.err == nil && len(r.source) > 0 {
+		r.err = errBytesRemaining(len(source), len(r.source))
+	}
+	if r.err != nil {
+		return nil, false, fmt.Errorf("cannot read WKB: %w", r.err)
+	}
+	return val, false, nil
+}
+
+func (r *wkbReader) readUint32() uint32 {
+	if r.err != nil {
+		return 0
+	} else if len(r.source) < 4 {
+		r.err = errWrongMinimumLength(4, len(r.source))
+		return 0
+	}
+	val := r.order.Uint32(r.source)
+	r.source = r.source[4:]
+	return val
+}
+
+// readCount reads a number of elements, each at least elementLength bytes long, checking it against the remaining
+// bytes, so that a corrupt count cannot cause huge allocations.
+func (r *wkbReader) readCount(elementLength int) int {
+	count := r.readUint32()
+	if r.err == nil && uint64(count)*uint64(elementLength) > uint64(len(r.source)) {
+		r.err = errWrongMinimumLength(int(count)*elementLength, len(r.source))
+		return 0
+	}
+	return int(count)
+}
+
+func (r *wkbReader) readPoints() []CqlPoint {
+	n := r.readCount(16)
+	if r.err != nil {
+		return nil
+	}
+	var points []CqlPoint
+	if n > 0 {
+		points = make([]CqlPoint, n)
+	}
+	for i := range points {
+		points[i] = r.readPoint()
+	}
+	return points
+}
+
+func (r *wkbReader) readPoint() CqlPoint {
+	if r.err != nil {
+		return CqlPoint{}
+	} else if len(r.source) < 16 {
+		r.err = errWrongMinimumLength(16, len(r.source))
+		return CqlPoint{}
+	}
+	p := CqlPoint{
+		X: math.Float64frombits(r.order.Uint64(r.source)),
+		Y: math.Float64frombits(r.order.Uint64(r.source[8:])),
+	}
+	r.source = r.source[16:]
+	return p
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+var (
+	cqlPoint      = CqlPoint{X: 1, Y: -2.5}
+	cqlLineString = CqlLineString{Points: []CqlPoint{{30, 10}, {10, 30}, {40, 40}}}
+	cqlPolygon    = CqlPolygon{Rings: [][]CqlPoint{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 0}},
+		{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+	}}
+)
+
+var (
+	cqlPointBytes = []byte{
+		1, 1, 0, 0, 0, // little endian, point
+		0, 0, 0, 0, 0, 0, 0xf0, 0x3f, // 1
+		0, 0, 0, 0, 0, 0, 0x04, 0xc0, // -2.5
+	}
+	cqlPointBigEndianBytes = []byte{
+		0, 0, 0, 0, 1, // big endian, point
+		0x3f, 0xf0, 0, 0, 0, 0, 0, 0, // 1
+		0xc0, 0x04, 0, 0, 0, 0, 0, 0, // -2.5
+	}
+	cqlLineStringEmptyBytes = []byte{1, 2, 0, 0, 0, 0, 0, 0, 0}
+	cqlPolygonEmptyBytes    = []byte{1, 3, 0, 0, 0, 0, 0, 0, 0}
+)
+
+func Test_geoCodec_DataType(t *testing.T) {
+	assert.Equal(t, datatype.DsePoint, Point.DataType())
+	assert.Equal(t, datatype.DseLineString, LineString.DataType())
+	assert.Equal(t, datatype.DsePolygon, Polygon.DataType())
+	for _, codec := range []Codec{Point, LineString, Polygon} {
+		actual, err := NewCodec(datatype.NewCustom(codec.DataType().(*datatype.Custom).ClassName))
+		require.NoError(t, err)
+		assert.Same(t, codec, actual)
+	}
+}
+
+func Test_geoCodec_Encode(t *testing.T) {
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			tests := []struct {
+				name     string
+				codec    Codec
+				source   interface{}
+				expected []byte
+				err      string
+			}{
+				{"nil", Point, nil, nil, ""},
+				{"nil pointer", Point, (*CqlPoint)(nil), nil, ""},
+				{"point", Point, cqlPoint, cqlPointBytes, ""},
+				{"point pointer", Point, &cqlPoint, cqlPointBytes, ""},
+				{"point WKT", Point, "POINT (1 -2.5)", cqlPointBytes, ""},
+				{"empty line string", LineString, CqlLineString{}, cqlLineStringEmptyBytes, ""},
+				{"empty polygon", Polygon, "POLYGON EMPTY", cqlPolygonEmptyBytes, ""},
+				{"WKT invalid", Point, "POINT (1)", nil, fmt.Sprintf("cannot encode string as CQL 'org.apache.cassandra.db.marshal.PointType' with %v: cannot convert from string to datacodec.CqlPoint: cannot parse point \"POINT (1)\": invalid WKT: expecting 2 coordinates, got: \"1\"", version)},
+				{"wrong geometry", Point, cqlLineString, nil, fmt.Sprintf("cannot encode datacodec.CqlLineString as CQL 'org.apache.cassandra.db.marshal.PointType' with %v: cannot convert from datacodec.CqlLineString to datacodec.CqlPoint: conversion not supported", version)},
+				{"conversion failed", Polygon, 123, nil, fmt.Sprintf("cannot encode int as CQL 'org.apache.cassandra.db.marshal.PolygonType' with %v: cannot convert from int to datacodec.CqlPolygon: conversion not supported", version)},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					actual, err := tt.codec.Encode(tt.source, version)
+					assert.Equal(t, tt.expected, actual)
+					assertErrorMessage(t, tt.err, err)
+				})
+			}
+		})
+	}
+}
+
+func Test_geoCodec_Decode(t *testing.T) {
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			tests := []struct {
+				name     string
+				codec    Codec
+				source   []byte
+				dest     interface{}
+				expected interface{}
+				wasNull  bool
+				err      string
+			}{
+				{"null", Point, nil, new(CqlPoint), new(CqlPoint), true, ""},
+				{"null interface", Polygon, nil, new(interface{}), new(interface{}), true, ""},
+				{"point", Point, cqlPointBytes, new(CqlPoint), &cqlPoint, false, ""},
+				{"point big endian", Point, cqlPointBigEndianBytes, new(CqlPoint), &cqlPoint, false, ""},
+				{"point interface", Point, cqlPointBytes, new(interface{}), interfacePtr(cqlPoint), false, ""},
+				{"point string", Point, cqlPointBytes, new(string), stringPtr("POINT (1 -2.5)"), false, ""},
+				{"empty line string", LineString, cqlLineStringEmptyBytes, new(CqlLineString), &CqlLineString{}, false, ""},
+				{"empty polygon string", Polygon, cqlPolygonEmptyBytes, new(string), stringPtr("POLYGON EMPTY"), false, ""},
+				{"wrong geometry", LineString, cqlPointBytes, new(CqlLineString), new(CqlLineString), false, fmt.Sprintf("cannot decode CQL 'org.apache.cassandra.db.marshal.LineStringType' as *datacodec.CqlLineString with %v: cannot read WKB: expecting geometry type 2, got: 1", version)},
+				{"wrong destination", LineString, cqlLineStringEmptyBytes, new(CqlPoint), new(CqlPoint), false, fmt.Sprintf("cannot decode CQL 'org.apache.cassandra.db.marshal.LineStringType' as *datacodec.CqlPoint with %v: cannot convert from datacodec.CqlLineString to *datacodec.CqlPoint: conversion not supported", version)},
+				{"invalid byte order", Point, []byte{2}, new(CqlPoint), new(CqlPoint), false, fmt.Sprintf("cannot decode CQL 'org.apache.cassandra.db.marshal.PointType' as *datacodec.CqlPoint with %v: cannot read WKB: invalid byte order: 2", version)},
+				{"truncated", Point, cqlPointBytes[:12], new(CqlPoint), new(CqlPoint), false, fmt.Sprintf("cannot decode CQL 'org.apache.cassandra.db.marshal.PointType' as *datacodec.CqlPoint with %v: cannot read WKB: expected at least 16 bytes but got: 7", version)},
+				{"corrupt count", LineString, []byte{1, 2, 0, 0, 0, 0xff, 0xff, 0xff, 0xff}, new(CqlLineString), new(CqlLineString), false, fmt.Sprintf("cannot decode CQL 'org.apache.cassandra.db.marshal.LineStringType' as *datacodec.CqlLineString with %v: cannot read WKB: expected at least 68719476720 bytes but got: 0", version)},
+				{"bytes remaining", Point, append(cqlPointBytes, 0), new(CqlPoint), new(CqlPoint), false, fmt.Sprintf("cannot decode CQL 'org.apache.cassandra.db.marshal.PointType' as *datacodec.CqlPoint with %v: cannot read WKB: source was not fully read: bytes total: 22, read: 21, remaining: 1", version)},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					wasNull, err := tt.codec.Decode(tt.source, tt.dest, version)
+					assert.Equal(t, tt.expected, tt.dest)
+					assert.Equal(t, tt.wasNull, wasNull)
+					assertErrorMessage(t, tt.err, err)
+				})
+			}
+		})
+	}
+}
+
+func Test_geoCodec_RoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		codec Codec
+		value interface{}
+		wkt   string
+	}{
+		{Point, cqlPoint, "POINT (1 -2.5)"},
+		{LineString, cqlLineString, "LINESTRING (30 10, 10 30, 40 40)"},
+		{Polygon, cqlPolygon, "POLYGON ((0 0, 10 0, 10 10, 0 0), (1 1, 2 1, 2 2, 1 1))"},
+	} {
+		t.Run(tt.wkt, func(t *testing.T) {
+			encoded, err := tt.codec.Encode(tt.value, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			var decoded interface{}
+			_, err = tt.codec.Decode(encoded, &decoded, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, decoded)
+			assert.Equal(t, tt.wkt, fmt.Sprint(decoded))
+			fromWkt, err := tt.codec.Encode(tt.wkt, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			assert.Equal(t, encoded, fromWkt)
+			json, err := ToJSONValue(decoded, tt.codec.DataType(), nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wkt, json)
+			fromJson, err := FromJSONValue(json, tt.codec.DataType(), nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, fromJson)
+		})
+	}
+}
+
+func TestParseCqlPolygon(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected CqlPolygon
+		err      string
+	}{
+		{"polygon((0 0,1 0,1 1,0 0))", CqlPolygon{Rings: [][]CqlPoint{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}, ""},
+		{" POLYGON ( (0 0, 1 0, 1 1, 0 0) , (0.1 0.1, 0.2 0.1, 0.1 0.1) ) ", CqlPolygon{Rings: [][]CqlPoint{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, {{0.1, 0.1}, {0.2, 0.1}, {0.1, 0.1}}}}, ""},
+		{"POLYGON empty", CqlPolygon{}, ""},
+		{"POLYGON (0 0, 1 0, 1 1, 0 0)", CqlPolygon{}, `cannot parse polygon "POLYGON (0 0, 1 0, 1 1, 0 0)": invalid WKT`},
+		{"POLYGON ((0 0, 1 0) (1 1, 0 0))", CqlPolygon{}, `cannot parse polygon "POLYGON ((0 0, 1 0) (1 1, 0 0))": invalid WKT`},
+		{"POINT (0 0)", CqlPolygon{}, `cannot parse polygon "POINT (0 0)": invalid WKT: expecting POLYGON`},
+		{"POLYGON ((0 x))", CqlPolygon{}, `cannot parse polygon "POLYGON ((0 x))": strconv.ParseFloat: parsing "x": invalid syntax`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual, err := ParseCqlPolygon(tt.input)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+	_, err := ParseCqlPoint("POINT EMPTY")
+	assert.EqualError(t, err, `cannot parse point "POINT EMPTY": invalid WKT`)
+}
@@ -33,6 +33,7 @@ func (c *inetCodec) DataType() datatype.DataType {
 }
 
 func (c *inetCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	var val net.IP
 	if val, err = convertToIP(source); err == nil && val != nil {
 		dest, err = writeInet(val)
@@ -44,6 +45,9 @@ func (c *inetCodec) Encode(source interface{}, version primitive.ProtocolVersion
 }
 
 func (c *inetCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	var val net.IP
 	if val, wasNull, err = readInet(source); err == nil {
 		err = convertFromIP(val, wasNull, dest)
@@ -33,6 +33,7 @@ func (c *intCodec) DataType() datatype.DataType {
 }
 
 func (c *intCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	var val int32
 	var wasNil bool
 	if val, wasNil, err = convertToInt32(source); err == nil && !wasNil {
@@ -45,6 +46,9 @@ func (c *intCodec) Encode(source interface{}, version primitive.ProtocolVersion)
 }
 
 func (c *intCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	var val int32
 	if val, wasNull, err = readInt32(source); err == nil {
 		err = convertFromInt32(val, wasNull, dest)
@@ -0,0 +1,346 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// CollectionIterator decodes a serialized CQL list or set element by element, without materializing the whole
+// collection in memory. Encoded elements are sliced directly from the source and are only decoded on demand.
+//
+// Typical usage:
+//
+//  it, err := NewListIterator(datatype.NewList(datatype.Int), source, version)
+//  for it.Next() {
+//      var elem int32
+//      if _, err := it.Decode(&elem); err != nil { ... }
+//  }
+//  if err := it.Err(); err != nil { ... }
+type CollectionIterator struct {
+	elementCodec Codec
+	reader       *sliceReader
+	version      primitive.ProtocolVersion
+	size         int
+	index        int
+	current      []byte
+	err          error
+}
+
+// NewListIterator creates a CollectionIterator for the given serialized CQL list. An empty source is interpreted as
+// a NULL list, and results in an iterator with no elements.
+func NewListIterator(dataType *datatype.List, source []byte, version primitive.ProtocolVersion) (*CollectionIterator, error) {
+	if dataType == nil {
+		return nil, ErrNilDataType
+	}
+	elementCodec, err := NewCodec(dataType.ElementType)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create codec for list elements: %w", err)
+	}
+	return newCollectionIterator(dataType, elementCodec, source, version)
+}
+
+// NewSetIterator creates a CollectionIterator for the given serialized CQL set. An empty source is interpreted as
+// a NULL set, and results in an iterator with no elements.
+func NewSetIterator(dataType *datatype.Set, source []byte, version primitive.ProtocolVersion) (*CollectionIterator, error) {
+	if dataType == nil {
+		return nil, ErrNilDataType
+	}
+	elementCodec, err := NewCodec(dataType.ElementType)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create codec for set elements: %w", err)
+	}
+	return newCollectionIterator(dataType, elementCodec, source, version)
+}
+
+func newCollectionIterator(dataType datatype.DataType, elementCodec Codec, source []byte, version primitive.ProtocolVersion) (*CollectionIterator, error) {
+	reader := &sliceReader{source: source}
+	size, err := reader.readSize(version)
+	if err != nil {
+		return nil, errCannotDecode(nil, dataType, version, err)
+	}
+	return &CollectionIterator{
+		elementCodec: elementCodec,
+		reader:       reader,
+		version:      version,
+		size:         size,
+		index:        -1,
+	}, nil
+}
+
+// Size returns the total number of elements in the collection.
+func (it *CollectionIterator) Size() int {
+	return it.size
+}
+
+// Index returns the zero-based index of the current element, or -1 if Next has not been called yet.
+func (it *CollectionIterator) Index() int {
+	return it.index
+}
+
+// Next advances the iterator to the next element. It returns false when there are no more elements, or when an
+// error occurred; in the latter case, Err returns the error.
+func (it *CollectionIterator) Next() bool {
+	if it.err != nil || it.index >= it.size {
+		return false
+	}
+	it.index++
+	it.current = nil
+	if it.index == it.size {
+		it.err = it.reader.checkFullyRead()
+		return false
+	}
+	if it.current, it.err = it.reader.readElement(it.version); it.err != nil {
+		it.err = errCannotReadElement(it.index, it.err)
+		return false
+	}
+	return true
+}
+
+// Bytes returns the encoded bytes of the current element; nil indicates a NULL element. The returned slice shares
+// the iterator's source and must not be modified.
+func (it *CollectionIterator) Bytes() []byte {
+	return it.current
+}
+
+// Decode decodes the current element into dest, which must be a pointer to a supported Go type for the element type.
+func (it *CollectionIterator) Decode(dest interface{}) (wasNull bool, err error) {
+	if err = it.checkCurrent(); err == nil {
+		if wasNull, err = it.elementCodec.Decode(it.current, dest, it.version); err != nil {
+			err = errCannotDecodeElement(it.index, err)
+		}
+	}
+	return
+}
+
+// Value decodes the current element into its preferred Go type; nil is returned for NULL elements.
+func (it *CollectionIterator) Value() (value interface{}, err error) {
+	_, err = it.Decode(&value)
+	return
+}
+
+// Err returns the first error encountered during iteration, if any. When the iteration completes, Err also reports
+// an error if the source contains trailing bytes.
+func (it *CollectionIterator) Err() error {
+	return it.err
+}
+
+func (it *CollectionIterator) checkCurrent() error {
+	if it.index < 0 || it.index >= it.size || it.err != nil {
+		return errNoCurrentElement
+	}
+	return nil
+}
+
+// MapIterator decodes a serialized CQL map entry by entry, without materializing the whole map in memory. Encoded
+// keys and values are sliced directly from the source and are only decoded on demand.
+type MapIterator struct {
+	keyCodec   Codec
+	valueCodec Codec
+	reader     *sliceReader
+	version    primitive.ProtocolVersion
+	size       int
+	index      int
+	key        []byte
+	value      []byte
+	err        error
+}
+
+// NewMapIterator creates a MapIterator for the given serialized CQL map. An empty source is interpreted as a NULL
+// map, and results in an iterator with no entries.
+func NewMapIterator(dataType *datatype.Map, source []byte, version primitive.ProtocolVersion) (*MapIterator, error) {
+	if dataType == nil {
+		return nil, ErrNilDataType
+	}
+	keyCodec, err := NewCodec(dataType.KeyType)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create codec for map keys: %w", err)
+	}
+	valueCodec, err := NewCodec(dataType.ValueType)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create codec for map values: %w", err)
+	}
+	reader := &sliceReader{source: source}
+	size, err := reader.readSize(version)
+	if err != nil {
+		return nil, errCannotDecode(nil, dataType, version, err)
+	}
+	return &MapIterator{
+		keyCodec:   keyCodec,
+		valueCodec: valueCodec,
+		reader:     reader,
+		version:    version,
+		size:       size,
+		index:      -1,
+	}, nil
+}
+
+// Size returns the total number of entries in the map.
+func (it *MapIterator) Size() int {
+	return it.size
+}
+
+// Index returns the zero-based index of the current entry, or -1 if Next has not been called yet.
+func (it *MapIterator) Index() int {
+	return it.index
+}
+
+// Next advances the iterator to the next entry. It returns false when there are no more entries, or when an error
+// occurred; in the latter case, Err returns the error.
+func (it *MapIterator) Next() bool {
+	if it.err != nil || it.index >= it.size {
+		return false
+	}
+	it.index++
+	it.key, it.value = nil, nil
+	if it.index == it.size {
+		it.err = it.reader.checkFullyRead()
+		return false
+	}
+	if it.key, it.err = it.reader.readElement(it.version); it.err != nil {
+		it.err = errCannotReadMapKey(it.index, it.err)
+		return false
+	}
+	if it.value, it.err = it.reader.readElement(it.version); it.err != nil {
+		it.err = errCannotReadMapValue(it.index, it.err)
+		return false
+	}
+	return true
+}
+
+// KeyBytes returns the encoded bytes of the current entry key. The returned slice shares the iterator's source and
+// must not be modified.
+func (it *MapIterator) KeyBytes() []byte {
+	return it.key
+}
+
+// ValueBytes returns the encoded bytes of the current entry value. The returned slice shares the iterator's source
+// and must not be modified.
+func (it *MapIterator) ValueBytes() []byte {
+	return it.value
+}
+
+// DecodeKey decodes the current entry key into dest, which must be a pointer to a supported Go type for the key type.
+func (it *MapIterator) DecodeKey(dest interface{}) (wasNull bool, err error) {
+	if err = it.checkCurrent(); err == nil {
+		if wasNull, err = it.keyCodec.Decode(it.key, dest, it.version); err != nil {
+			err = errCannotDecodeMapKey(it.index, err)
+		}
+	}
+	return
+}
+
+// DecodeValue decodes the current entry value into dest, which must be a pointer to a supported Go type for the
+// value type.
+func (it *MapIterator) DecodeValue(dest interface{}) (wasNull bool, err error) {
+	if err = it.checkCurrent(); err == nil {
+		if wasNull, err = it.valueCodec.Decode(it.value, dest, it.version); err != nil {
+			err = errCannotDecodeMapValue(it.index, err)
+		}
+	}
+	return
+}
+
+// Key decodes the current entry key into its preferred Go type; nil is returned for NULL keys.
+func (it *MapIterator) Key() (key interface{}, err error) {
+	_, err = it.DecodeKey(&key)
+	return
+}
+
+// Value decodes the current entry value into its preferred Go type; nil is returned for NULL values.
+func (it *MapIterator) Value() (value interface{}, err error) {
+	_, err = it.DecodeValue(&value)
+	return
+}
+
+// Err returns the first error encountered during iteration, if any. When the iteration completes, Err also reports
+// an error if the source contains trailing bytes.
+func (it *MapIterator) Err() error {
+	return it.err
+}
+
+func (it *MapIterator) checkCurrent() error {
+	if it.index < 0 || it.index >= it.size || it.err != nil {
+		return errNoCurrentElement
+	}
+	return nil
+}
+
+var errNoCurrentElement = errors.New("iterator is not positioned on an element")
+
+// sliceReader reads collection sizes and elements from a byte slice without copying them.
+type sliceReader struct {
+	source []byte
+	pos    int
+}
+
+func (r *sliceReader) readSize(version primitive.ProtocolVersion) (size int, err error) {
+	if len(r.source) == 0 {
+		// NULL collection
+		return 0, nil
+	}
+	var length int
+	if length, err = r.readLength(version); err != nil {
+		err = fmt.Errorf("cannot read collection size: %w", err)
+	} else if length < 0 {
+		err = fmt.Errorf("cannot read collection size: %w", collectionSizeNegative(length))
+	} else {
+		size = length
+	}
+	return
+}
+
+func (r *sliceReader) readElement(version primitive.ProtocolVersion) ([]byte, error) {
+	length, err := r.readLength(version)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read element length: %w", err)
+	} else if length < 0 {
+		return nil, nil
+	} else if r.pos+length > len(r.source) {
+		return nil, fmt.Errorf("cannot read element content: expected %d bytes, got %d", length, len(r.source)-r.pos)
+	}
+	elem := r.source[r.pos : r.pos+length : r.pos+length]
+	r.pos += length
+	return elem, nil
+}
+
+func (r *sliceReader) readLength(version primitive.ProtocolVersion) (int, error) {
+	if version.Uses4BytesCollectionLength() {
+		if len(r.source)-r.pos < primitive.LengthOfInt {
+			return 0, fmt.Errorf("expected %d bytes, got %d", primitive.LengthOfInt, len(r.source)-r.pos)
+		}
+		length := int32(binary.BigEndian.Uint32(r.source[r.pos:]))
+		r.pos += primitive.LengthOfInt
+		return int(length), nil
+	}
+	if len(r.source)-r.pos < primitive.LengthOfShort {
+		return 0, fmt.Errorf("expected %d bytes, got %d", primitive.LengthOfShort, len(r.source)-r.pos)
+	}
+	length := binary.BigEndian.Uint16(r.source[r.pos:])
+	r.pos += primitive.LengthOfShort
+	return int(length), nil
+}
+
+func (r *sliceReader) checkFullyRead() error {
+	if remaining := len(r.source) - r.pos; remaining != 0 {
+		return errBytesRemaining(len(r.source), remaining)
+	}
+	return nil
+}
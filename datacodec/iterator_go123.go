@@ -0,0 +1,77 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.23
+
+package datacodec
+
+import (
+	"iter"
+)
+
+// Elements returns an iter.Seq that decodes the remaining elements of the given CollectionIterator into values of
+// type T, for use with range-over-func loops in Go 1.23+:
+//
+//  it, err := NewListIterator(datatype.NewList(datatype.Int), source, version)
+//  for elem := range Elements[int32](it) {
+//      ...
+//  }
+//  if err := it.Err(); err != nil { ... }
+//
+// T must be a Go type supported by the element codec; NULL elements are yielded as the zero value of T. Iteration
+// stops at the first read or decode error, which is then reported by the iterator's Err method. The sequence shares
+// the iterator's position and can only be consumed once.
+func Elements[T any](it *CollectionIterator) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for it.Next() {
+			var elem T
+			if _, err := it.Decode(&elem); err != nil {
+				it.err = err
+				return
+			} else if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// Entries returns an iter.Seq2 that decodes the remaining entries of the given MapIterator into keys of type K and
+// values of type V, for use with range-over-func loops in Go 1.23+:
+//
+//  it, err := NewMapIterator(datatype.NewMap(datatype.Varchar, datatype.Int), source, version)
+//  for key, value := range Entries[string, int32](it) {
+//      ...
+//  }
+//  if err := it.Err(); err != nil { ... }
+//
+// K and V must be Go types supported by the key and value codecs; NULL keys and values are yielded as zero values.
+// Iteration stops at the first read or decode error, which is then reported by the iterator's Err method. The
+// sequence shares the iterator's position and can only be consumed once.
+func Entries[K, V any](it *MapIterator) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for it.Next() {
+			var key K
+			var value V
+			if _, err := it.DecodeKey(&key); err != nil {
+				it.err = err
+				return
+			} else if _, err := it.DecodeValue(&value); err != nil {
+				it.err = err
+				return
+			} else if !yield(key, value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.23
+
+package datacodec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestElements(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   []byte
+		expected []int32
+		err      string
+	}{
+		{"null", nil, nil, ""},
+		{"many elements", listOneTwoThreeBytes4, []int32{1, 2, 3}, ""},
+		{"null element", []byte{0, 0, 0, 1, 255, 255, 255, 255}, []int32{0}, ""},
+		{"truncated", listOneTwoThreeBytes4[:len(listOneTwoThreeBytes4)-2], []int32{1, 2}, "cannot read element 2: cannot read element content: expected 4 bytes, got 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it, err := NewListIterator(datatype.NewList(datatype.Int), tt.source, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			var actual []int32
+			for elem := range Elements[int32](it) {
+				actual = append(actual, elem)
+			}
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, it.Err())
+		})
+	}
+	t.Run("break", func(t *testing.T) {
+		it, err := NewSetIterator(datatype.NewSet(datatype.Int), listOneTwoThreeBytes4, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		for elem := range Elements[int](it) {
+			assert.Equal(t, 1, elem)
+			break
+		}
+		// the sequence shares the iterator's position
+		assert.Equal(t, []int{2, 3}, collect(Elements[int](it)))
+		assert.NoError(t, it.Err())
+	})
+	t.Run("decode error", func(t *testing.T) {
+		it, err := NewListIterator(datatype.NewList(datatype.Int), listOneTwoThreeBytes4, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Empty(t, collect(Elements[bool](it)))
+		assertErrorMessage(t, "cannot decode element 0", it.Err())
+		assert.False(t, it.Next())
+	})
+}
+
+func TestEntries(t *testing.T) {
+	dataType := datatype.NewMap(datatype.Varchar, datatype.Int)
+	codec, _ := NewMap(dataType)
+	source, err := codec.Encode(map[string]int32{"abc": 1, "def": 2}, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	t.Run("success", func(t *testing.T) {
+		it, err := NewMapIterator(dataType, source, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		actual := map[string]int64{}
+		for key, value := range Entries[string, int64](it) {
+			actual[key] = value
+		}
+		assert.Equal(t, map[string]int64{"abc": 1, "def": 2}, actual)
+		assert.NoError(t, it.Err())
+	})
+	t.Run("key decode error", func(t *testing.T) {
+		it, err := NewMapIterator(dataType, source, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		for range Entries[bool, int32](it) {
+			t.Fatal("expected no entries")
+		}
+		assertErrorMessage(t, "cannot decode entry 0 key", it.Err())
+	})
+	t.Run("value decode error", func(t *testing.T) {
+		it, err := NewMapIterator(dataType, source, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		for range Entries[string, bool](it) {
+			t.Fatal("expected no entries")
+		}
+		assertErrorMessage(t, "cannot decode entry 0 value", it.Err())
+	})
+	t.Run("truncated", func(t *testing.T) {
+		it, err := NewMapIterator(dataType, source[:len(source)-2], primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		count := 0
+		for range Entries[string, int32](it) {
+			count++
+		}
+		assert.Equal(t, 1, count)
+		assert.EqualError(t, it.Err(), "cannot read entry 1 value: cannot read element content: expected 4 bytes, got 2")
+	})
+}
+
+func collect[T any](seq func(func(T) bool)) []T {
+	var result []T
+	for elem := range seq {
+		result = append(result, elem)
+	}
+	return result
+}
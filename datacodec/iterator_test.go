@@ -0,0 +1,153 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestNewListIterator(t *testing.T) {
+	for _, version := range primitive.SupportedProtocolVersionsGreaterThanOrEqualTo(primitive.ProtocolVersion3) {
+		t.Run(version.String(), func(t *testing.T) {
+			tests := []struct {
+				name     string
+				source   []byte
+				expected []interface{}
+				err      string
+			}{
+				{"null", nil, nil, ""},
+				{"empty", []byte{0, 0, 0, 0}, nil, ""},
+				{"one element", listOneBytes4, []interface{}{int32(1)}, ""},
+				{"many elements", listOneTwoThreeBytes4, []interface{}{int32(1), int32(2), int32(3)}, ""},
+				{"null element", []byte{0, 0, 0, 1, 255, 255, 255, 255}, []interface{}{nil}, ""},
+				{"bytes remaining", append(listOneBytes4, 1), []interface{}{int32(1)}, "source was not fully read: bytes total: 13, read: 12, remaining: 1"},
+				{"truncated", listOneTwoThreeBytes4[:len(listOneTwoThreeBytes4)-2], []interface{}{int32(1), int32(2)}, "cannot read element 2: cannot read element content: expected 4 bytes, got 2"},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					it, err := NewListIterator(datatype.NewList(datatype.Int), tt.source, version)
+					require.NoError(t, err)
+					var actual []interface{}
+					for it.Next() {
+						value, err := it.Value()
+						require.NoError(t, err)
+						actual = append(actual, value)
+					}
+					assert.Equal(t, tt.expected, actual)
+					assertErrorMessage(t, tt.err, it.Err())
+					assert.False(t, it.Next())
+				})
+			}
+		})
+	}
+	for _, version := range primitive.SupportedProtocolVersionsLesserThan(primitive.ProtocolVersion3) {
+		t.Run(version.String(), func(t *testing.T) {
+			it, err := NewListIterator(datatype.NewList(datatype.Int), listOneTwoThreeBytes2, version)
+			require.NoError(t, err)
+			assert.Equal(t, 3, it.Size())
+			var actual []int
+			for it.Next() {
+				var elem int
+				wasNull, err := it.Decode(&elem)
+				require.NoError(t, err)
+				assert.False(t, wasNull)
+				actual = append(actual, elem)
+			}
+			assert.NoError(t, it.Err())
+			assert.Equal(t, []int{1, 2, 3}, actual)
+		})
+	}
+	t.Run("errors", func(t *testing.T) {
+		_, err := NewListIterator(nil, nil, primitive.ProtocolVersion4)
+		assert.EqualError(t, err, "data type is nil")
+		_, err = NewListIterator(datatype.NewList(wrongDataType{}), nil, primitive.ProtocolVersion4)
+		assert.EqualError(t, err, "cannot create codec for list elements: cannot create data codec for CQL type 666")
+		_, err = NewListIterator(datatype.NewList(datatype.Int), []byte{0, 0}, primitive.ProtocolVersion4)
+		assert.EqualError(t, err, "cannot decode CQL list<int> as <nil> with ProtocolVersion OSS 4: cannot read collection size: expected 4 bytes, got 2")
+		it, err := NewListIterator(datatype.NewList(datatype.Int), listOneBytes4, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		_, err = it.Value()
+		assert.EqualError(t, err, "iterator is not positioned on an element")
+		require.True(t, it.Next())
+		var dest string
+		_, err = it.Decode(dest)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot decode element 0")
+	})
+}
+
+func TestNewSetIterator(t *testing.T) {
+	it, err := NewSetIterator(datatype.NewSet(datatype.Int), listOneTwoThreeBytes4, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, 3, it.Size())
+	for i := 0; it.Next(); i++ {
+		assert.Equal(t, i, it.Index())
+		assert.Equal(t, encodeUint32(uint32(i+1)), it.Bytes())
+	}
+	assert.NoError(t, it.Err())
+	_, err = NewSetIterator(nil, nil, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "data type is nil")
+}
+
+func TestNewMapIterator(t *testing.T) {
+	dataType := datatype.NewMap(datatype.Varchar, datatype.Int)
+	codec, _ := NewMap(dataType)
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			source, err := codec.Encode(map[string]int32{"abc": 1, "def": 2}, version)
+			require.NoError(t, err)
+			it, err := NewMapIterator(dataType, source, version)
+			require.NoError(t, err)
+			assert.Equal(t, 2, it.Size())
+			actual := map[string]int32{}
+			for it.Next() {
+				key, err := it.Key()
+				require.NoError(t, err)
+				var value int32
+				_, err = it.DecodeValue(&value)
+				require.NoError(t, err)
+				actual[key.(string)] = value
+			}
+			assert.NoError(t, it.Err())
+			assert.Equal(t, map[string]int32{"abc": 1, "def": 2}, actual)
+			// truncated source
+			it, err = NewMapIterator(dataType, source[:len(source)-2], version)
+			require.NoError(t, err)
+			assert.True(t, it.Next())
+			assert.False(t, it.Next())
+			assert.EqualError(t, it.Err(), "cannot read entry 1 value: cannot read element content: expected 4 bytes, got 2")
+		})
+	}
+	t.Run("errors", func(t *testing.T) {
+		_, err := NewMapIterator(nil, nil, primitive.ProtocolVersion4)
+		assert.EqualError(t, err, "data type is nil")
+		_, err = NewMapIterator(datatype.NewMap(wrongDataType{}, datatype.Int), nil, primitive.ProtocolVersion4)
+		assert.EqualError(t, err, "cannot create codec for map keys: cannot create data codec for CQL type 666")
+		_, err = NewMapIterator(datatype.NewMap(datatype.Int, wrongDataType{}), nil, primitive.ProtocolVersion4)
+		assert.EqualError(t, err, "cannot create codec for map values: cannot create data codec for CQL type 666")
+		it, err := NewMapIterator(dataType, nil, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.False(t, it.Next())
+		assert.NoError(t, it.Err())
+		_, err = it.Key()
+		assert.EqualError(t, err, "iterator is not positioned on an element")
+	})
+}
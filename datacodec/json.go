@@ -0,0 +1,642 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+const (
+	// DefaultJSONTimestampLayout is the default layout for CQL timestamps in JSON: RFC 3339 with millisecond
+	// precision, in UTC.
+	DefaultJSONTimestampLayout = "2006-01-02T15:04:05.000Z07:00"
+	// DefaultJSONDateLayout is the default layout for CQL dates in JSON.
+	DefaultJSONDateLayout = "2006-01-02"
+	// DefaultJSONTimeLayout is the default layout for CQL times in JSON, with nanosecond precision.
+	DefaultJSONTimeLayout = "15:04:05.000000000"
+)
+
+// JSONOptions controls how CQL values are converted to and from JSON, see ToJSONValue and FromJSONValue. A nil
+// JSONOptions, or empty layouts, mean that default values are used.
+type JSONOptions struct {
+
+	// TimestampLayout is the time.Time layout to use for CQL timestamps; defaults to DefaultJSONTimestampLayout.
+	// Timestamps are always formatted in UTC. When converting from JSON, timestamps can also be provided as numbers
+	// of milliseconds since the Epoch.
+	TimestampLayout string
+
+	// DateLayout is the time.Time layout to use for CQL dates; defaults to DefaultJSONDateLayout.
+	DateLayout string
+
+	// TimeLayout is the time.Time layout to use for CQL times; defaults to DefaultJSONTimeLayout.
+	TimeLayout string
+
+	// BigNumbersAsStrings indicates whether CQL bigint, counter, varint and decimal values should be rendered as JSON
+	// strings instead of JSON numbers. This avoids precision loss in JSON parsers that read all numbers as 64-bit
+	// floats, such as JavaScript's.
+	BigNumbersAsStrings bool
+}
+
+// ToJSONValue converts the given CQL value to a representation suitable for encoding/json. The value must be
+// accepted by the codec for the given CQL type, and is typically obtained by decoding with such a codec.
+//
+// CQL NULLs are converted to nil; booleans and numbers to JSON booleans and numbers (big numbers are converted to
+// json.Number, or to strings if JSONOptions.BigNumbersAsStrings is set); float and double NaN and infinities to the
+// strings "NaN", "Infinity" and "-Infinity"; blobs to hex strings prefixed with "0x"; dates, times and timestamps to
+// strings formatted according to the layouts in JSONOptions; uuids, timeuuids and inets to their string forms;
+// durations to objects with "months", "days" and "nanos" fields; DSE geospatial values to their WKT strings; DSE date
+// ranges to their DSE Search syntax; lists, sets and tuples to slices; maps and user-defined types to objects. Since
+// JSON object keys must be strings, map keys that are not converted to JSON strings are rendered as their JSON
+// encoding, e.g. the map key 1 becomes "1".
+func ToJSONValue(value interface{}, dt datatype.DataType, options *JSONOptions) (interface{}, error) {
+	options = options.withDefaults()
+	value, err := normalizeForJSON(value, dt)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	switch dt.Code() {
+	case primitive.DataTypeCodeAscii, primitive.DataTypeCodeVarchar, primitive.DataTypeCodeBoolean,
+		primitive.DataTypeCodeInt, primitive.DataTypeCodeSmallint, primitive.DataTypeCodeTinyint:
+		return value, nil
+	case primitive.DataTypeCodeBigint, primitive.DataTypeCodeCounter:
+		return options.bigNumber(strconv.FormatInt(value.(int64), 10)), nil
+	case primitive.DataTypeCodeVarint:
+		return options.bigNumber(value.(*big.Int).String()), nil
+	case primitive.DataTypeCodeDecimal:
+		return options.bigNumber(formatDecimal(value.(CqlDecimal))), nil
+	case primitive.DataTypeCodeDouble:
+		return floatToJSON(value.(float64), 64), nil
+	case primitive.DataTypeCodeFloat:
+		return floatToJSON(float64(value.(float32)), 32), nil
+	case primitive.DataTypeCodeBlob, primitive.DataTypeCodeCustom:
+		switch v := value.(type) {
+		case geometry:
+			return v.String(), nil
+		case CqlDateRange:
+			return v.String(), nil
+		}
+		return "0x" + hex.EncodeToString(value.([]byte)), nil
+	case primitive.DataTypeCodeTimestamp:
+		return value.(time.Time).UTC().Format(options.TimestampLayout), nil
+	case primitive.DataTypeCodeDate:
+		return value.(time.Time).UTC().Format(options.DateLayout), nil
+	case primitive.DataTypeCodeTime:
+		return time.Time{}.Add(value.(time.Duration)).Format(options.TimeLayout), nil
+	case primitive.DataTypeCodeUuid, primitive.DataTypeCodeTimeuuid:
+		uuid := value.(primitive.UUID)
+		return uuid.String(), nil
+	case primitive.DataTypeCodeInet:
+		return value.(net.IP).String(), nil
+	case primitive.DataTypeCodeDuration:
+		duration := value.(CqlDuration)
+		return map[string]interface{}{
+			"months": duration.Months,
+			"days":   duration.Days,
+			"nanos":  int64(duration.Nanos),
+		}, nil
+	case primitive.DataTypeCodeList:
+		return sliceToJSON(value, func(int) datatype.DataType { return dt.(*datatype.List).ElementType }, options)
+	case primitive.DataTypeCodeSet:
+		return sliceToJSON(value, func(int) datatype.DataType { return dt.(*datatype.Set).ElementType }, options)
+	case primitive.DataTypeCodeTuple:
+		return sliceToJSON(value, func(i int) datatype.DataType { return dt.(*datatype.Tuple).FieldTypes[i] }, options)
+	case primitive.DataTypeCodeMap:
+		return mapToJSON(reflect.ValueOf(value), dt.(*datatype.Map), options)
+	case primitive.DataTypeCodeUdt:
+		return udtToJSON(value.(map[string]interface{}), dt.(*datatype.UserDefined), options)
+	}
+	return nil, errCannotCreateCodec(dt)
+}
+
+// FromJSONValue converts the given JSON value, as produced by encoding/json when decoding into an interface{}, to a
+// CQL value of the given type, that can be encoded with a codec for that type. It accepts the representations produced
+// by ToJSONValue, as well as json.Number wherever numbers are expected, and numbers and booleans encoded as strings.
+func FromJSONValue(value interface{}, dt datatype.DataType, options *JSONOptions) (interface{}, error) {
+	options = options.withDefaults()
+	if value == nil {
+		return nil, nil
+	}
+	var result interface{}
+	var err error
+	switch dt.Code() {
+	case primitive.DataTypeCodeAscii, primitive.DataTypeCodeVarchar:
+		if s, ok := value.(string); ok {
+			result = s
+		} else {
+			err = errJSONWrongType(value, "string")
+		}
+	case primitive.DataTypeCodeBoolean:
+		switch v := value.(type) {
+		case bool:
+			result = v
+		case string:
+			result, err = strconv.ParseBool(v)
+		default:
+			err = errJSONWrongType(value, "boolean")
+		}
+	case primitive.DataTypeCodeBigint, primitive.DataTypeCodeCounter, primitive.DataTypeCodeInt,
+		primitive.DataTypeCodeSmallint, primitive.DataTypeCodeTinyint:
+		result, err = jsonToInt64(value)
+	case primitive.DataTypeCodeVarint:
+		if s, ok := jsonNumberString(value); !ok {
+			err = errJSONWrongType(value, "number")
+		} else if i, ok := new(big.Int).SetString(s, 10); !ok {
+			err = fmt.Errorf("cannot parse varint: %v", s)
+		} else {
+			result = i
+		}
+	case primitive.DataTypeCodeDecimal:
+		if s, ok := jsonNumberString(value); !ok {
+			err = errJSONWrongType(value, "number")
+		} else {
+			result, err = parseDecimal(s)
+		}
+	case primitive.DataTypeCodeDouble:
+		result, err = jsonToFloat64(value)
+	case primitive.DataTypeCodeFloat:
+		var f float64
+		if f, err = jsonToFloat64(value); err == nil {
+			result = float32(f)
+		}
+	case primitive.DataTypeCodeBlob, primitive.DataTypeCodeCustom:
+		if s, ok := value.(string); !ok {
+			err = errJSONWrongType(value, "string")
+		} else if geoCodec := newGeoCodec(dt); geoCodec != nil {
+			result, err = geoCodec.parse(s)
+		} else if isDateRange(dt) {
+			result, err = ParseCqlDateRange(s)
+		} else if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+			err = fmt.Errorf("cannot parse blob: expected hex string starting with 0x, got: %v", s)
+		} else {
+			result, err = hex.DecodeString(s[2:])
+		}
+	case primitive.DataTypeCodeTimestamp:
+		if s, ok := value.(string); ok {
+			result, err = time.Parse(options.TimestampLayout, s)
+		} else {
+			var millis int64
+			if millis, err = jsonToInt64(value); err == nil {
+				result = time.Unix(0, 0).UTC().Add(time.Duration(millis) * time.Millisecond)
+			}
+		}
+	case primitive.DataTypeCodeDate:
+		if s, ok := value.(string); ok {
+			result, err = time.Parse(options.DateLayout, s)
+		} else {
+			err = errJSONWrongType(value, "string")
+		}
+	case primitive.DataTypeCodeTime:
+		if s, ok := value.(string); !ok {
+			err = errJSONWrongType(value, "string")
+		} else if t, parseErr := time.Parse(options.TimeLayout, s); parseErr != nil {
+			err = parseErr
+		} else {
+			result = time.Duration(t.Hour())*time.Hour +
+				time.Duration(t.Minute())*time.Minute +
+				time.Duration(t.Second())*time.Second +
+				time.Duration(t.Nanosecond())
+		}
+	case primitive.DataTypeCodeUuid, primitive.DataTypeCodeTimeuuid:
+		if s, ok := value.(string); !ok {
+			err = errJSONWrongType(value, "string")
+		} else {
+			var uuid *primitive.UUID
+			if uuid, err = primitive.ParseUuid(s); err == nil {
+				result = *uuid
+			}
+		}
+	case primitive.DataTypeCodeInet:
+		if s, ok := value.(string); !ok {
+			err = errJSONWrongType(value, "string")
+		} else if ip := net.ParseIP(s); ip == nil {
+			err = fmt.Errorf("cannot parse inet: %v", s)
+		} else {
+			result = ip
+		}
+	case primitive.DataTypeCodeDuration:
+		result, err = jsonToDuration(value)
+	case primitive.DataTypeCodeList:
+		result, err = jsonToSlice(value, func(int) datatype.DataType { return dt.(*datatype.List).ElementType }, -1, options)
+	case primitive.DataTypeCodeSet:
+		result, err = jsonToSlice(value, func(int) datatype.DataType { return dt.(*datatype.Set).ElementType }, -1, options)
+	case primitive.DataTypeCodeTuple:
+		tupleType := dt.(*datatype.Tuple)
+		result, err = jsonToSlice(value, func(i int) datatype.DataType { return tupleType.FieldTypes[i] }, len(tupleType.FieldTypes), options)
+	case primitive.DataTypeCodeMap:
+		result, err = jsonToMap(value, dt.(*datatype.Map), options)
+	case primitive.DataTypeCodeUdt:
+		result, err = jsonToUdt(value, dt.(*datatype.UserDefined), options)
+	default:
+		err = errCannotCreateCodec(dt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert JSON value to %v: %w", dt, err)
+	}
+	return result, nil
+}
+
+// EncodedToJSON decodes the given encoded CQL value with the given codec, and converts it to JSON. See ToJSONValue.
+func EncodedToJSON(codec Codec, source []byte, version primitive.ProtocolVersion, options *JSONOptions) ([]byte, error) {
+	decoded, err := decodePreferred(codec, source, version)
+	if err != nil {
+		return nil, err
+	} else if decoded == nil {
+		return []byte("null"), nil
+	}
+	value, err := ToJSONValue(decoded, codec.DataType(), options)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// JSONToEncoded parses the given JSON document, converts it to a CQL value and encodes it with the given codec. See
+// FromJSONValue.
+func JSONToEncoded(codec Codec, source []byte, version primitive.ProtocolVersion, options *JSONOptions) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(source))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("cannot parse JSON: %w", err)
+	}
+	converted, err := FromJSONValue(value, codec.DataType(), options)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(converted, version)
+}
+
+func (o *JSONOptions) withDefaults() *JSONOptions {
+	result := JSONOptions{}
+	if o != nil {
+		result = *o
+	}
+	if result.TimestampLayout == "" {
+		result.TimestampLayout = DefaultJSONTimestampLayout
+	}
+	if result.DateLayout == "" {
+		result.DateLayout = DefaultJSONDateLayout
+	}
+	if result.TimeLayout == "" {
+		result.TimeLayout = DefaultJSONTimeLayout
+	}
+	return &result
+}
+
+func (o *JSONOptions) bigNumber(s string) interface{} {
+	if o.BigNumbersAsStrings {
+		return s
+	}
+	return json.Number(s)
+}
+
+// normalizeForJSON converts the given value to the preferred Go type for the given CQL type, see PreferredGoType. Nil
+// values and nil pointers are returned as nil.
+func normalizeForJSON(value interface{}, dt datatype.DataType) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr && v.Type() != typeOfBigIntPointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map || v.Kind() == reflect.Ptr) && v.IsNil() {
+		return nil, nil
+	}
+	goType, err := PreferredGoType(dt)
+	if err != nil {
+		return nil, err
+	}
+	if v.Type() == goType {
+		return v.Interface(), nil
+	}
+	// round-trip through the codec to obtain the preferred Go type
+	codec, err := NewCodec(dt)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := codec.Encode(value, primitive.ProtocolVersion5)
+	if err != nil {
+		return nil, err
+	}
+	return decodePreferred(codec, encoded, primitive.ProtocolVersion5)
+}
+
+// decodePreferred decodes the given encoded value into the preferred Go type for the codec's CQL type. CQL NULLs are
+// returned as nil.
+func decodePreferred(codec Codec, source []byte, version primitive.ProtocolVersion) (interface{}, error) {
+	goType, err := PreferredGoType(codec.DataType())
+	if err != nil {
+		return nil, err
+	}
+	// pointer types such as *big.Int are decoded in place
+	isPointer := goType.Kind() == reflect.Ptr
+	if isPointer {
+		goType = goType.Elem()
+	}
+	dest := reflect.New(goType)
+	if wasNull, err := codec.Decode(source, dest.Interface(), version); err != nil {
+		return nil, err
+	} else if wasNull {
+		return nil, nil
+	} else if isPointer {
+		return dest.Interface(), nil
+	}
+	return dest.Elem().Interface(), nil
+}
+
+func floatToJSON(f float64, bitSize int) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bitSize))
+}
+
+func sliceToJSON(value interface{}, elementType func(int) datatype.DataType, options *JSONOptions) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	result := make([]interface{}, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		var err error
+		if result[i], err = ToJSONValue(v.Index(i).Interface(), elementType(i), options); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func mapToJSON(v reflect.Value, mapType *datatype.Map, options *JSONOptions) (interface{}, error) {
+	result := make(map[string]interface{}, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := ToJSONValue(iter.Key().Interface(), mapType.KeyType, options)
+		if err != nil {
+			return nil, err
+		}
+		value, err := ToJSONValue(iter.Value().Interface(), mapType.ValueType, options)
+		if err != nil {
+			return nil, err
+		}
+		keyString, ok := key.(string)
+		if !ok {
+			encodedKey, err := json.Marshal(key)
+			if err != nil {
+				return nil, err
+			}
+			keyString = string(encodedKey)
+		}
+		result[keyString] = value
+	}
+	return result, nil
+}
+
+func udtToJSON(value map[string]interface{}, udtType *datatype.UserDefined, options *JSONOptions) (interface{}, error) {
+	result := make(map[string]interface{}, len(udtType.FieldNames))
+	for i, fieldName := range udtType.FieldNames {
+		var err error
+		if result[fieldName], err = ToJSONValue(value[fieldName], udtType.FieldTypes[i], options); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func jsonToSlice(value interface{}, elementType func(int) datatype.DataType, length int, options *JSONOptions) (interface{}, error) {
+	elements, ok := value.([]interface{})
+	if !ok {
+		return nil, errJSONWrongType(value, "array")
+	} else if length >= 0 && len(elements) != length {
+		return nil, fmt.Errorf("expected array of length %d, got: %d", length, len(elements))
+	}
+	result := make([]interface{}, len(elements))
+	for i, element := range elements {
+		var err error
+		if result[i], err = FromJSONValue(element, elementType(i), options); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func jsonToMap(value interface{}, mapType *datatype.Map, options *JSONOptions) (interface{}, error) {
+	entries, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errJSONWrongType(value, "object")
+	}
+	result := make(map[interface{}]interface{}, len(entries))
+	for keyString, entryValue := range entries {
+		var jsonKey interface{} = keyString
+		if !jsonKeyIsString(mapType.KeyType) {
+			decoder := json.NewDecoder(strings.NewReader(keyString))
+			decoder.UseNumber()
+			if err := decoder.Decode(&jsonKey); err != nil {
+				return nil, fmt.Errorf("cannot parse map key %v: %w", keyString, err)
+			}
+		}
+		key, err := FromJSONValue(jsonKey, mapType.KeyType, options)
+		if err != nil {
+			return nil, err
+		} else if key != nil && !reflect.TypeOf(key).Comparable() {
+			return nil, fmt.Errorf("map keys of type %v are not supported", mapType.KeyType)
+		}
+		if result[key], err = FromJSONValue(entryValue, mapType.ValueType, options); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func jsonToUdt(value interface{}, udtType *datatype.UserDefined, options *JSONOptions) (interface{}, error) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errJSONWrongType(value, "object")
+	}
+	result := make(map[string]interface{}, len(udtType.FieldNames))
+	for i, fieldName := range udtType.FieldNames {
+		var err error
+		if result[fieldName], err = FromJSONValue(fields[fieldName], udtType.FieldTypes[i], options); err != nil {
+			return nil, err
+		}
+	}
+	for fieldName := range fields {
+		if _, found := result[fieldName]; !found {
+			return nil, fmt.Errorf("unknown field: %v", fieldName)
+		}
+	}
+	return result, nil
+}
+
+func jsonToDuration(value interface{}) (interface{}, error) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errJSONWrongType(value, "object")
+	}
+	var months, days, nanos int64
+	var err error
+	if fields["months"] != nil {
+		if months, err = jsonToInt64(fields["months"]); err != nil {
+			return nil, err
+		}
+	}
+	if fields["days"] != nil {
+		if days, err = jsonToInt64(fields["days"]); err != nil {
+			return nil, err
+		}
+	}
+	if fields["nanos"] != nil {
+		if nanos, err = jsonToInt64(fields["nanos"]); err != nil {
+			return nil, err
+		}
+	}
+	if months < math.MinInt32 || months > math.MaxInt32 || days < math.MinInt32 || days > math.MaxInt32 {
+		return nil, errValueOutOfRange(value)
+	}
+	return CqlDuration{Months: int32(months), Days: int32(days), Nanos: time.Duration(nanos)}, nil
+}
+
+// jsonKeyIsString returns true if the given CQL type is converted to a JSON string by ToJSONValue, in which case map
+// keys of that type are rendered as is, instead of being JSON-encoded.
+func jsonKeyIsString(dt datatype.DataType) bool {
+	switch dt.Code() {
+	case primitive.DataTypeCodeAscii, primitive.DataTypeCodeVarchar, primitive.DataTypeCodeBlob,
+		primitive.DataTypeCodeCustom, primitive.DataTypeCodeTimestamp, primitive.DataTypeCodeDate,
+		primitive.DataTypeCodeTime, primitive.DataTypeCodeUuid, primitive.DataTypeCodeTimeuuid,
+		primitive.DataTypeCodeInet:
+		return true
+	}
+	return false
+}
+
+func jsonNumberString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String(), true
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+func jsonToInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, errValueOutOfRange(v)
+		}
+		return int64(v), nil
+	case json.Number:
+		return v.Int64()
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	}
+	return 0, errJSONWrongType(value, "number")
+}
+
+func jsonToFloat64(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		switch v {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, errJSONWrongType(value, "number")
+}
+
+// formatDecimal formats the given decimal in plain notation, e.g. 123.45 for unscaled 12345 and scale 2.
+func formatDecimal(d CqlDecimal) string {
+	unscaled := d.Unscaled
+	if unscaled == nil {
+		unscaled = big.NewInt(0)
+	}
+	digits := new(big.Int).Abs(unscaled).String()
+	sign := ""
+	if unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	if d.Scale <= 0 {
+		if unscaled.Sign() == 0 {
+			return "0"
+		}
+		return sign + digits + strings.Repeat("0", int(-d.Scale))
+	}
+	scale := int(d.Scale)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// parseDecimal parses decimal numbers in plain or scientific notation, preserving their scale: 1.50 is parsed as
+// unscaled 150 with scale 2.
+func parseDecimal(s string) (CqlDecimal, error) {
+	mantissa, exponent := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i != -1 {
+		var err error
+		if exponent, err = strconv.ParseInt(s[i+1:], 10, 32); err != nil {
+			return CqlDecimal{}, fmt.Errorf("cannot parse decimal: %v", s)
+		}
+		mantissa = s[:i]
+	}
+	scale := int64(0)
+	if i := strings.IndexByte(mantissa, '.'); i != -1 {
+		scale = int64(len(mantissa) - i - 1)
+		mantissa = mantissa[:i] + mantissa[i+1:]
+	}
+	unscaled, ok := new(big.Int).SetString(mantissa, 10)
+	if !ok {
+		return CqlDecimal{}, fmt.Errorf("cannot parse decimal: %v", s)
+	}
+	scale -= exponent
+	if scale < math.MinInt32 || scale > math.MaxInt32 {
+		return CqlDecimal{}, errValueOutOfRange(s)
+	}
+	return CqlDecimal{Unscaled: unscaled, Scale: int32(scale)}, nil
+}
+
+func errJSONWrongType(value interface{}, expected string) error {
+	return fmt.Errorf("expected JSON %s, got: %T", expected, value)
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestJSON_RoundTrip(t *testing.T) {
+	udtType, _ := datatype.NewUserDefined("ks", "address", []string{"street", "zip"}, []datatype.DataType{datatype.Varchar, datatype.Int})
+	tests := []struct {
+		name     string
+		dt       datatype.DataType
+		value    interface{}
+		expected string
+		options  *JSONOptions
+	}{
+		{"null", datatype.Int, nil, `null`, nil},
+		{"varchar", datatype.Varchar, "abc", `"abc"`, nil},
+		{"boolean", datatype.Boolean, true, `true`, nil},
+		{"int", datatype.Int, int32(-42), `-42`, nil},
+		{"tinyint", datatype.Tinyint, int8(7), `7`, nil},
+		{"bigint", datatype.Bigint, int64(math.MaxInt64), `9223372036854775807`, nil},
+		{"bigint as string", datatype.Bigint, int64(math.MaxInt64), `"9223372036854775807"`, &JSONOptions{BigNumbersAsStrings: true}},
+		{"varint", datatype.Varint, new(big.Int).Lsh(big.NewInt(1), 70), `1180591620717411303424`, nil},
+		{"decimal", datatype.Decimal, CqlDecimal{Unscaled: big.NewInt(-12345), Scale: 2}, `-123.45`, nil},
+		{"decimal small", datatype.Decimal, CqlDecimal{Unscaled: big.NewInt(5), Scale: 3}, `0.005`, nil},
+		{"decimal as string", datatype.Decimal, CqlDecimal{Unscaled: big.NewInt(12345), Scale: 2}, `"123.45"`, &JSONOptions{BigNumbersAsStrings: true}},
+		{"double", datatype.Double, 1.5, `1.5`, nil},
+		{"double NaN", datatype.Double, math.Inf(-1), `"-Infinity"`, nil},
+		{"float", datatype.Float, float32(1.1), `1.1`, nil},
+		{"blob", datatype.Blob, []byte{0xca, 0xfe}, `"0xcafe"`, nil},
+		{"timestamp", datatype.Timestamp, time.Date(2021, 3, 4, 5, 6, 7, 8e6, time.UTC), `"2021-03-04T05:06:07.008Z"`, nil},
+		{"timestamp layout", datatype.Timestamp, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC), `"2021-03-04 05:06:07"`, &JSONOptions{TimestampLayout: "2006-01-02 15:04:05"}},
+		{"date", datatype.Date, time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), `"2021-03-04"`, nil},
+		{"time", datatype.Time, 13*time.Hour + 14*time.Minute + 15*time.Second + 16, `"13:14:15.000000016"`, nil},
+		{"uuid", datatype.Uuid, primitive.UUID{0xc0, 0xd1, 0xd2, 0x1e, 0xbb, 0x01, 0x41, 0x96, 0x86, 0xdb, 0xbc, 0x31, 0x7b, 0xc1, 0x79, 0x6a}, `"c0d1d21e-bb01-4196-86db-bc317bc1796a"`, nil},
+		{"inet", datatype.Inet, net.ParseIP("192.168.0.1").To4(), `"192.168.0.1"`, nil},
+		{"duration", datatype.Duration, CqlDuration{Months: 1, Days: 2, Nanos: 3}, `{"days":2,"months":1,"nanos":3}`, nil},
+		{"list", datatype.NewList(datatype.Int), []int32{1, 2, 3}, `[1,2,3]`, nil},
+		{"set", datatype.NewSet(datatype.Varchar), []string{"a", "b"}, `["a","b"]`, nil},
+		{"map text keys", datatype.NewMap(datatype.Varchar, datatype.Int), map[string]int32{"a": 1}, `{"a":1}`, nil},
+		{"map int keys", datatype.NewMap(datatype.Int, datatype.NewList(datatype.Boolean)), map[int32][]bool{1: {true}}, `{"1":[true]}`, nil},
+		{"tuple", datatype.NewTuple(datatype.Int, datatype.Varchar), []interface{}{int32(1), "a"}, `[1,"a"]`, nil},
+		{"udt", udtType, map[string]interface{}{"street": "Main St", "zip": int32(12345)}, `{"street":"Main St","zip":12345}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec, err := NewCodec(tt.dt)
+			require.NoError(t, err)
+			encoded, err := codec.Encode(tt.value, primitive.ProtocolVersion5)
+			require.NoError(t, err)
+			actual, err := EncodedToJSON(codec, encoded, primitive.ProtocolVersion5, tt.options)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(actual))
+			reEncoded, err := JSONToEncoded(codec, actual, primitive.ProtocolVersion5, tt.options)
+			require.NoError(t, err)
+			assert.Equal(t, encoded, reEncoded)
+		})
+	}
+}
+
+func TestToJSONValue_NonPreferredTypes(t *testing.T) {
+	value, err := ToJSONValue([]int{1, 2}, datatype.NewList(datatype.Bigint), &JSONOptions{BigNumbersAsStrings: true})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"1", "2"}, value)
+	str := "a"
+	value, err = ToJSONValue(&str, datatype.Varchar, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "a", value)
+	value, err = ToJSONValue((*string)(nil), datatype.Varchar, nil)
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestFromJSONValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		dt       datatype.DataType
+		value    interface{}
+		expected interface{}
+		err      string
+	}{
+		{"int from float", datatype.Int, 12.0, int64(12), ""},
+		{"int from fraction", datatype.Int, 12.5, nil, "cannot convert JSON value to int: value out of range: 12.5"},
+		{"int from number", datatype.Int, json.Number("12"), int64(12), ""},
+		{"boolean from string", datatype.Boolean, "true", true, ""},
+		{"timestamp from millis", datatype.Timestamp, json.Number("1000"), time.Unix(1, 0).UTC(), ""},
+		{"decimal scientific", datatype.Decimal, json.Number("1.5E3"), CqlDecimal{Unscaled: big.NewInt(15), Scale: -2}, ""},
+		{"wrong type", datatype.Varchar, 1.0, nil, "cannot convert JSON value to varchar: expected JSON string, got: float64"},
+		{"bad blob", datatype.Blob, "cafe", nil, "expected hex string starting with 0x, got: cafe"},
+		{"tuple length", datatype.NewTuple(datatype.Int), []interface{}{}, nil, "expected array of length 1, got: 0"},
+		{"unknown udt field", mustUdt(t), map[string]interface{}{"f2": 1.0}, nil, "unknown field: f2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := FromJSONValue(tt.value, tt.dt, nil)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
+
+func mustUdt(t *testing.T) datatype.DataType {
+	udtType, err := datatype.NewUserDefined("ks", "udt", []string{"f1"}, []datatype.DataType{datatype.Int})
+	require.NoError(t, err)
+	return udtType
+}
@@ -55,6 +55,7 @@ func float64Ptr(v float64) *float64           { return &v }
 func float32Ptr(v float32) *float32           { return &v }
 func byteArrayPtr(v [16]byte) *[16]byte       { return &v }
 func interfacePtr(v interface{}) *interface{} { return &v }
+func timePtr(v time.Time) *time.Time          { return &v }
 func boolNilPtr() *bool                       { return nil }
 func intNilPtr() *int                         { return nil }
 func int64NilPtr() *int64                     { return nil }
@@ -23,17 +23,43 @@ import (
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
 
-func NewMap(dataType *datatype.Map) (Codec, error) {
+// NewMap creates a codec for the given map type. Key and value codecs are resolved with DefaultCodecRegistry, unless
+// overridden with OverrideKey and OverrideValue.
+func NewMap(dataType *datatype.Map, overrides ...CodecOverride) (Codec, error) {
+	return newMap(dataType, DefaultCodecRegistry, overrides...)
+}
+
+func newMap(dataType *datatype.Map, registry *CodecRegistry, overrides ...CodecOverride) (Codec, error) {
 	if dataType == nil {
 		return nil, ErrNilDataType
 	}
-	keyCodec, err := NewCodec(dataType.KeyType)
-	if err != nil {
-		return nil, fmt.Errorf("cannot create codec for map keys: %w", err)
+	var keyCodec, valueCodec Codec
+	for _, override := range overrides {
+		switch override.kind {
+		case overrideKindKey:
+			if err := override.check(dataType.KeyType); err != nil {
+				return nil, err
+			}
+			keyCodec = override.codec
+		case overrideKindValue:
+			if err := override.check(dataType.ValueType); err != nil {
+				return nil, err
+			}
+			valueCodec = override.codec
+		default:
+			return nil, errWrongOverride(override, dataType)
+		}
 	}
-	valueCodec, err := NewCodec(dataType.ValueType)
-	if err != nil {
-		return nil, fmt.Errorf("cannot create codec for map values: %w", err)
+	var err error
+	if keyCodec == nil {
+		if keyCodec, err = registry.NewCodec(dataType.KeyType); err != nil {
+			return nil, fmt.Errorf("cannot create codec for map keys: %w", err)
+		}
+	}
+	if valueCodec == nil {
+		if valueCodec, err = registry.NewCodec(dataType.ValueType); err != nil {
+			return nil, fmt.Errorf("cannot create codec for map values: %w", err)
+		}
 	}
 	return &mapCodec{dataType, keyCodec, valueCodec}, nil
 }
@@ -49,6 +75,7 @@ func (c *mapCodec) DataType() datatype.DataType {
 }
 
 func (c *mapCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	ext, size, err := c.createExtractor(source)
 	if err == nil && ext != nil {
 		dest, err = writeMap(ext, size, c.keyCodec, c.valueCodec, version)
@@ -60,6 +87,9 @@ func (c *mapCodec) Encode(source interface{}, version primitive.ProtocolVersion)
 }
 
 func (c *mapCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	wasNull = len(source) == 0
 	var injectorFactory func(int) (keyValueInjector, error)
 	if injectorFactory, err = c.createInjector(dest, wasNull); err == nil && injectorFactory != nil {
@@ -120,7 +150,7 @@ func (c *mapCodec) createInjector(dest interface{}, wasNull bool) (injectorFacto
 		case reflect.Interface:
 			if !wasNull {
 				var targetType reflect.Type
-				if targetType, err = PreferredGoType(c.DataType()); err == nil {
+				if targetType, err = preferredGoTypeOf(c); err == nil {
 					injectorFactory = func(size int) (keyValueInjector, error) {
 						destValue.Set(reflect.MakeMapWithSize(targetType, size))
 						return newMapInjector(destValue.Elem())
@@ -135,7 +165,8 @@ func (c *mapCodec) createInjector(dest interface{}, wasNull bool) (injectorFacto
 }
 
 func writeMap(ext keyValueExtractor, size int, keyCodec Codec, valueCodec Codec, version primitive.ProtocolVersion) ([]byte, error) {
-	buf := &bytes.Buffer{}
+	buf := primitive.AcquireWriteBuffer()
+	defer primitive.ReleaseWriteBuffer(buf)
 	if err := writeCollectionSize(size, buf, version); err != nil {
 		return nil, err
 	}
@@ -166,7 +197,7 @@ func writeMap(ext keyValueExtractor, size int, keyCodec Codec, valueCodec Codec,
 			}
 		}
 	}
-	return buf.Bytes(), nil
+	return buf.CopyBytes(), nil
 }
 
 func readMap(source []byte, injectorFactory func(int) (keyValueInjector, error), keyCodec Codec, valueCodec Codec, version primitive.ProtocolVersion) error {
@@ -0,0 +1,374 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"math/big"
+	"net"
+	"reflect"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// The types below are nullable wrappers, modeled after database/sql's NullInt64 and the like, that all built-in codecs
+// accept natively: when encoding, a wrapper whose Valid field is false is encoded as a CQL NULL, otherwise its value
+// is encoded as if it were passed directly; when decoding into a pointer to a wrapper, Valid is set to false if the
+// decoded value was NULL, and to true otherwise. They are meant to be used as struct fields mapped to user-defined
+// types or tuples, or as row values, instead of pointers. With Go 1.21+, the generic Nullable type can be used to wrap
+// any other supported Go type.
+
+// NullInt64 is a nullable int64, e.g. for CQL bigint and counter.
+type NullInt64 struct {
+	Int64 int64
+	Valid bool
+}
+
+// NullInt32 is a nullable int32, e.g. for CQL int.
+type NullInt32 struct {
+	Int32 int32
+	Valid bool
+}
+
+// NullInt16 is a nullable int16, e.g. for CQL smallint.
+type NullInt16 struct {
+	Int16 int16
+	Valid bool
+}
+
+// NullInt8 is a nullable int8, e.g. for CQL tinyint.
+type NullInt8 struct {
+	Int8  int8
+	Valid bool
+}
+
+// NullBool is a nullable bool, e.g. for CQL boolean.
+type NullBool struct {
+	Bool  bool
+	Valid bool
+}
+
+// NullFloat64 is a nullable float64, e.g. for CQL double.
+type NullFloat64 struct {
+	Float64 float64
+	Valid   bool
+}
+
+// NullFloat32 is a nullable float32, e.g. for CQL float.
+type NullFloat32 struct {
+	Float32 float32
+	Valid   bool
+}
+
+// NullString is a nullable string, e.g. for CQL varchar and ascii. Note that an empty, valid string is encoded as a
+// CQL empty value, not as a NULL.
+type NullString struct {
+	String string
+	Valid  bool
+}
+
+// NullBytes is a nullable byte slice, e.g. for CQL blob. Unlike a nil []byte, a NullBytes whose Valid field is true is
+// never encoded as a NULL, even if Bytes is nil.
+type NullBytes struct {
+	Bytes []byte
+	Valid bool
+}
+
+// NullTime is a nullable time.Time, e.g. for CQL timestamp, date and time.
+type NullTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+// NullTimeOfDay is a nullable time.Duration, e.g. for CQL time.
+type NullTimeOfDay struct {
+	TimeOfDay time.Duration
+	Valid     bool
+}
+
+// NullDuration is a nullable CqlDuration, e.g. for CQL duration.
+type NullDuration struct {
+	Duration CqlDuration
+	Valid    bool
+}
+
+// NullDecimal is a nullable CqlDecimal, e.g. for CQL decimal.
+type NullDecimal struct {
+	Decimal CqlDecimal
+	Valid   bool
+}
+
+// NullVarint is a nullable big.Int, e.g. for CQL varint.
+type NullVarint struct {
+	Varint big.Int
+	Valid  bool
+}
+
+// NullUUID is a nullable primitive.UUID, e.g. for CQL uuid and timeuuid.
+type NullUUID struct {
+	UUID  primitive.UUID
+	Valid bool
+}
+
+// NullIP is a nullable net.IP, e.g. for CQL inet.
+type NullIP struct {
+	IP    net.IP
+	Valid bool
+}
+
+// nullableSource is implemented by nullable wrappers, to be encoded.
+type nullableSource interface {
+	// nullableValue returns the wrapped value, or nil if the wrapper is not valid.
+	nullableValue() interface{}
+}
+
+// nullableDest is implemented by pointers to nullable wrappers, to be decoded into.
+type nullableDest interface {
+	// nullableTarget returns a pointer to the wrapped value.
+	nullableTarget() interface{}
+	setValid(valid bool)
+}
+
+func (n NullInt64) nullableValue() interface{} {
+	return nullableValue(n.Int64, n.Valid)
+}
+
+func (n *NullInt64) nullableTarget() interface{} {
+	return &n.Int64
+}
+
+func (n *NullInt64) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullInt32) nullableValue() interface{} {
+	return nullableValue(n.Int32, n.Valid)
+}
+
+func (n *NullInt32) nullableTarget() interface{} {
+	return &n.Int32
+}
+
+func (n *NullInt32) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullInt16) nullableValue() interface{} {
+	return nullableValue(n.Int16, n.Valid)
+}
+
+func (n *NullInt16) nullableTarget() interface{} {
+	return &n.Int16
+}
+
+func (n *NullInt16) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullInt8) nullableValue() interface{} {
+	return nullableValue(n.Int8, n.Valid)
+}
+
+func (n *NullInt8) nullableTarget() interface{} {
+	return &n.Int8
+}
+
+func (n *NullInt8) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullBool) nullableValue() interface{} {
+	return nullableValue(n.Bool, n.Valid)
+}
+
+func (n *NullBool) nullableTarget() interface{} {
+	return &n.Bool
+}
+
+func (n *NullBool) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullFloat64) nullableValue() interface{} {
+	return nullableValue(n.Float64, n.Valid)
+}
+
+func (n *NullFloat64) nullableTarget() interface{} {
+	return &n.Float64
+}
+
+func (n *NullFloat64) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullFloat32) nullableValue() interface{} {
+	return nullableValue(n.Float32, n.Valid)
+}
+
+func (n *NullFloat32) nullableTarget() interface{} {
+	return &n.Float32
+}
+
+func (n *NullFloat32) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullString) nullableValue() interface{} {
+	return nullableValue(n.String, n.Valid)
+}
+
+func (n *NullString) nullableTarget() interface{} {
+	return &n.String
+}
+
+func (n *NullString) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n *NullBytes) nullableTarget() interface{} {
+	return &n.Bytes
+}
+
+func (n *NullBytes) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullTime) nullableValue() interface{} {
+	return nullableValue(n.Time, n.Valid)
+}
+
+func (n *NullTime) nullableTarget() interface{} {
+	return &n.Time
+}
+
+func (n *NullTime) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullTimeOfDay) nullableValue() interface{} {
+	return nullableValue(n.TimeOfDay, n.Valid)
+}
+
+func (n *NullTimeOfDay) nullableTarget() interface{} {
+	return &n.TimeOfDay
+}
+
+func (n *NullTimeOfDay) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullDuration) nullableValue() interface{} {
+	return nullableValue(n.Duration, n.Valid)
+}
+
+func (n *NullDuration) nullableTarget() interface{} {
+	return &n.Duration
+}
+
+func (n *NullDuration) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullDecimal) nullableValue() interface{} {
+	return nullableValue(n.Decimal, n.Valid)
+}
+
+func (n *NullDecimal) nullableTarget() interface{} {
+	return &n.Decimal
+}
+
+func (n *NullDecimal) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullVarint) nullableValue() interface{} {
+	return nullableValue(&n.Varint, n.Valid)
+}
+
+func (n *NullVarint) nullableTarget() interface{} {
+	return &n.Varint
+}
+
+func (n *NullVarint) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullUUID) nullableValue() interface{} {
+	return nullableValue(n.UUID, n.Valid)
+}
+
+func (n *NullUUID) nullableTarget() interface{} {
+	return &n.UUID
+}
+
+func (n *NullUUID) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullIP) nullableValue() interface{} {
+	return nullableValue(n.IP, n.Valid)
+}
+
+func (n *NullIP) nullableTarget() interface{} {
+	return &n.IP
+}
+
+func (n *NullIP) setValid(valid bool) {
+	n.Valid = valid
+}
+
+func (n NullBytes) nullableValue() interface{} {
+	if !n.Valid {
+		return nil
+	} else if n.Bytes == nil {
+		return []byte{}
+	}
+	return n.Bytes
+}
+
+func nullableValue(value interface{}, valid bool) interface{} {
+	if !valid {
+		return nil
+	}
+	return value
+}
+
+// unwrapNullable returns the value wrapped by the given source if it is a nullable wrapper, or nil if it is not valid;
+// otherwise it returns the source unchanged.
+func unwrapNullable(source interface{}) interface{} {
+	if n, ok := source.(nullableSource); ok {
+		if isNilPointer(source) {
+			return nil
+		}
+		return n.nullableValue()
+	}
+	return source
+}
+
+// decodeNullable decodes the given source into the value wrapped by the given nullable destination, using the given
+// codec, then sets its Valid field.
+func decodeNullable(codec Codec, source []byte, dest nullableDest, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if isNilPointer(dest) {
+		return false, errCannotDecode(dest, codec.DataType(), version, ErrNilDestination)
+	}
+	wasNull, err = codec.Decode(source, dest.nullableTarget(), version)
+	dest.setValid(err == nil && !wasNull)
+	return wasNull, err
+}
+
+func isNilPointer(v interface{}) bool {
+	value := reflect.ValueOf(v)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
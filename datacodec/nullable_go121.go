@@ -0,0 +1,46 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package datacodec
+
+// Note: since this module declares Go 1.17, generics are only available to toolchains that upgrade the language version
+// of files built with a go1.21 or higher build constraint, i.e. Go 1.21 and higher.
+
+// Nullable is a generic nullable wrapper for Go 1.21+, modeled after database/sql's Null type: it can wrap any Go type
+// supported by the codec it is used with, and is accepted natively by all built-in codecs, like NullInt64 and the
+// other nullable wrappers. When encoding, a Nullable whose Valid field is false is encoded as a CQL NULL; when
+// decoding into a *Nullable, Valid is set to false if the decoded value was NULL, and to true otherwise.
+type Nullable[T any] struct {
+	V     T
+	Valid bool
+}
+
+// NewNullable returns a valid Nullable wrapping the given value.
+func NewNullable[T any](v T) Nullable[T] {
+	return Nullable[T]{V: v, Valid: true}
+}
+
+func (n Nullable[T]) nullableValue() interface{} {
+	return nullableValue(n.V, n.Valid)
+}
+
+func (n *Nullable[T]) nullableTarget() interface{} {
+	return &n.V
+}
+
+func (n *Nullable[T]) setValid(valid bool) {
+	n.Valid = valid
+}
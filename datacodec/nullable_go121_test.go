@@ -0,0 +1,51 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package datacodec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestNullable(t *testing.T) {
+	codec, err := NewList(datatype.NewList(datatype.Int))
+	require.NoError(t, err)
+	encoded, err := codec.Encode(NewNullable([]int32{1, 2}), primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	var dest Nullable[[]int32]
+	wasNull, err := codec.Decode(encoded, &dest, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	assert.False(t, wasNull)
+	assert.Equal(t, NewNullable([]int32{1, 2}), dest)
+
+	encoded, err = codec.Encode(Nullable[[]int32]{}, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	assert.Nil(t, encoded)
+	wasNull, err = codec.Decode(encoded, &dest, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	assert.True(t, wasNull)
+	assert.Equal(t, Nullable[[]int32]{}, dest)
+
+	encoded, err = Bigint.Encode(&Nullable[int]{V: 42, Valid: true}, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 42}, encoded)
+}
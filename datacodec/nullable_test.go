@@ -0,0 +1,135 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestNullableWrappers(t *testing.T) {
+	uuid := primitive.UUID{0xC0, 0xD1, 0xD2, 0x1E, 0xBB, 0x01, 0x41, 0x96, 0x86, 0xDB, 0xBC, 0x31, 0x7B, 0xC1, 0x79, 0x6A}
+	tests := []struct {
+		codec Codec
+		valid interface{}
+	}{
+		{Bigint, NullInt64{Int64: -1, Valid: true}},
+		{Counter, NullInt64{Int64: 1, Valid: true}},
+		{Int, NullInt32{Int32: 42, Valid: true}},
+		{Smallint, NullInt16{Int16: -42, Valid: true}},
+		{Tinyint, NullInt8{Int8: 1, Valid: true}},
+		{Boolean, NullBool{Bool: true, Valid: true}},
+		{Double, NullFloat64{Float64: 1.5, Valid: true}},
+		{Float, NullFloat32{Float32: -1.5, Valid: true}},
+		{Varchar, NullString{String: "abc", Valid: true}},
+		{Varchar, NullString{String: "", Valid: true}},
+		{Ascii, NullString{String: "abc", Valid: true}},
+		{Blob, NullBytes{Bytes: []byte{1, 2, 3}, Valid: true}},
+		{Blob, NullBytes{Bytes: []byte{}, Valid: true}},
+		{Timestamp, NullTime{Time: time.Date(2021, 1, 2, 3, 4, 5, 6000000, time.UTC), Valid: true}},
+		{Date, NullTime{Time: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true}},
+		{Time, NullTimeOfDay{TimeOfDay: time.Hour, Valid: true}},
+		{Duration, NullDuration{Duration: CqlDuration{Months: 1, Days: 2, Nanos: 3}, Valid: true}},
+		{Decimal, NullDecimal{Decimal: CqlDecimal{Unscaled: big.NewInt(123), Scale: 2}, Valid: true}},
+		{Varint, NullVarint{Varint: *big.NewInt(123), Valid: true}},
+		{Uuid, NullUUID{UUID: uuid, Valid: true}},
+		{Timeuuid, NullUUID{UUID: uuid, Valid: true}},
+		{Inet, NullIP{IP: net.ParseIP("192.168.1.1").To4(), Valid: true}},
+	}
+	for _, tt := range tests {
+		wrapperType := reflect.TypeOf(tt.valid)
+		t.Run(tt.codec.DataType().AsCql()+"/"+wrapperType.Name(), func(t *testing.T) {
+			version := primitive.ProtocolVersion5
+			t.Run("valid", func(t *testing.T) {
+				for _, source := range []interface{}{tt.valid, nullablePointerTo(tt.valid)} {
+					encoded, err := tt.codec.Encode(source, version)
+					require.NoError(t, err)
+					assert.NotNil(t, encoded)
+					dest := reflect.New(wrapperType)
+					wasNull, err := tt.codec.Decode(encoded, dest.Interface(), version)
+					require.NoError(t, err)
+					assert.False(t, wasNull)
+					assert.Equal(t, tt.valid, dest.Elem().Interface())
+				}
+			})
+			t.Run("null", func(t *testing.T) {
+				null := reflect.Zero(wrapperType).Interface()
+				nilPointer := reflect.Zero(reflect.PtrTo(wrapperType)).Interface()
+				for _, source := range []interface{}{null, nullablePointerTo(null), nilPointer} {
+					encoded, err := tt.codec.Encode(source, version)
+					require.NoError(t, err)
+					assert.Nil(t, encoded)
+				}
+				// decoding a NULL must reset the wrapper
+				dest := reflect.New(wrapperType)
+				dest.Elem().Set(reflect.ValueOf(tt.valid))
+				wasNull, err := tt.codec.Decode(nil, dest.Interface(), version)
+				require.NoError(t, err)
+				assert.True(t, wasNull)
+				assert.False(t, dest.Elem().FieldByName("Valid").Bool())
+				// nil destination
+				_, err = tt.codec.Decode(nil, nilPointer, version)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), ErrNilDestination.Error())
+			})
+		})
+	}
+}
+
+func TestNullableWrappers_ValidNilBytes(t *testing.T) {
+	encoded, err := Blob.Encode(NullBytes{Valid: true}, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	assert.True(t, IsEmpty(encoded))
+}
+
+func TestNullableWrappers_UserDefined(t *testing.T) {
+	type address struct {
+		Street  NullString
+		Number  NullInt32
+		Updated NullTime `cassandra:"last_updated"`
+	}
+	udtType, err := datatype.NewUserDefined(
+		"ks1",
+		"address",
+		[]string{"street", "number", "last_updated"},
+		[]datatype.DataType{datatype.Varchar, datatype.Int, datatype.Timestamp},
+	)
+	require.NoError(t, err)
+	codec, err := NewUserDefined(udtType)
+	require.NoError(t, err)
+	source := address{Street: NullString{String: "Main street", Valid: true}}
+	encoded, err := codec.Encode(source, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	var dest address
+	wasNull, err := codec.Decode(encoded, &dest, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	assert.False(t, wasNull)
+	assert.Equal(t, source, dest)
+}
+
+func nullablePointerTo(v interface{}) interface{} {
+	ptr := reflect.New(reflect.TypeOf(v))
+	ptr.Elem().Set(reflect.ValueOf(v))
+	return ptr.Interface()
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+)
+
+type overrideKind int
+
+const (
+	overrideKindField = overrideKind(iota)
+	overrideKindElement
+	overrideKindKey
+	overrideKindValue
+)
+
+// CodecOverride replaces the codec used for one component of a complex type: a field of a user-defined type, an
+// element of a tuple, or the keys or values of a map. Overrides are passed to NewUserDefined, NewTuple and NewMap, and
+// allow a single component to use a custom Go representation without replacing the codec for the whole type, e.g. to
+// decode one UDT field into a json.RawMessage. The overriding codec's data type must match the component's CQL type.
+//
+// Create overrides with OverrideField, OverrideElement, OverrideKey and OverrideValue.
+type CodecOverride struct {
+	kind  overrideKind
+	field string
+	index int
+	codec Codec
+}
+
+// OverrideField returns a CodecOverride for the user-defined type field with the given name.
+func OverrideField(name string, codec Codec) CodecOverride {
+	return CodecOverride{kind: overrideKindField, field: name, codec: codec}
+}
+
+// OverrideElement returns a CodecOverride for the tuple element at the given (zero-based) index.
+func OverrideElement(index int, codec Codec) CodecOverride {
+	return CodecOverride{kind: overrideKindElement, index: index, codec: codec}
+}
+
+// OverrideKey returns a CodecOverride for map keys.
+func OverrideKey(codec Codec) CodecOverride {
+	return CodecOverride{kind: overrideKindKey, codec: codec}
+}
+
+// OverrideValue returns a CodecOverride for map values.
+func OverrideValue(codec Codec) CodecOverride {
+	return CodecOverride{kind: overrideKindValue, codec: codec}
+}
+
+func (o CodecOverride) String() string {
+	switch o.kind {
+	case overrideKindField:
+		return fmt.Sprintf("override for field %s", o.field)
+	case overrideKindElement:
+		return fmt.Sprintf("override for element %d", o.index)
+	case overrideKindKey:
+		return "override for map keys"
+	case overrideKindValue:
+		return "override for map values"
+	}
+	return "unknown override"
+}
+
+// check verifies that this override's codec is suitable for a component of the given type.
+func (o CodecOverride) check(componentType datatype.DataType) error {
+	if o.codec == nil {
+		return fmt.Errorf("invalid %v: codec cannot be nil", o)
+	} else if o.codec.DataType() == nil {
+		return fmt.Errorf("invalid %v: %w", o, ErrNilDataType)
+	} else if o.codec.DataType().AsCql() != componentType.AsCql() {
+		return fmt.Errorf("invalid %v: expected codec for %v, got: %v", o, componentType, o.codec.DataType())
+	}
+	return nil
+}
+
+func errWrongOverride(o CodecOverride, dt datatype.DataType) error {
+	return fmt.Errorf("invalid %v: cannot be applied to %v", o, dt)
+}
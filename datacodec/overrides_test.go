@@ -0,0 +1,141 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// rawJSONCodec is a varchar codec that encodes and decodes json.RawMessage values without parsing them.
+type rawJSONCodec struct{}
+
+func (c *rawJSONCodec) DataType() datatype.DataType {
+	return datatype.Varchar
+}
+
+func (c *rawJSONCodec) Encode(source interface{}, _ primitive.ProtocolVersion) ([]byte, error) {
+	if raw, ok := source.(json.RawMessage); !ok {
+		return nil, ErrSourceTypeNotSupported
+	} else if raw == nil {
+		return nil, nil
+	} else {
+		return raw, nil
+	}
+}
+
+func (c *rawJSONCodec) Decode(source []byte, dest interface{}, _ primitive.ProtocolVersion) (bool, error) {
+	raw, ok := dest.(*json.RawMessage)
+	if !ok {
+		return false, ErrDestinationTypeNotSupported
+	}
+	*raw = source
+	return source == nil, nil
+}
+
+func TestNewUserDefined_Overrides(t *testing.T) {
+	udtType, _ := datatype.NewUserDefined("ks", "doc", []string{"id", "body"}, []datatype.DataType{datatype.Int, datatype.Varchar})
+	type doc struct {
+		Id   int32
+		Body json.RawMessage
+	}
+	codec, err := NewUserDefined(udtType, OverrideField("body", &rawJSONCodec{}))
+	require.NoError(t, err)
+	encoded, err := codec.Encode(&doc{Id: 1, Body: json.RawMessage(`{"a":1}`)}, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	// the encoded form is the same as with the default codec
+	defaultCodec, _ := NewUserDefined(udtType)
+	expected, _ := defaultCodec.Encode(map[string]interface{}{"id": int32(1), "body": `{"a":1}`}, primitive.ProtocolVersion5)
+	assert.Equal(t, expected, encoded)
+	var decoded doc
+	_, err = codec.Decode(encoded, &decoded, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	assert.Equal(t, doc{Id: 1, Body: json.RawMessage(`{"a":1}`)}, decoded)
+}
+
+func TestNewTuple_Overrides(t *testing.T) {
+	tupleType := datatype.NewTuple(datatype.Int, datatype.Varchar)
+	codec, err := NewTuple(tupleType, OverrideElement(1, &rawJSONCodec{}))
+	require.NoError(t, err)
+	encoded, err := codec.Encode([]interface{}{int32(1), json.RawMessage(`[true]`)}, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	var decoded struct {
+		Id   int32
+		Body json.RawMessage
+	}
+	_, err = codec.Decode(encoded, &decoded, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), decoded.Id)
+	assert.Equal(t, json.RawMessage(`[true]`), decoded.Body)
+}
+
+func TestNewMap_Overrides(t *testing.T) {
+	mapType := datatype.NewMap(datatype.Varchar, datatype.Varchar)
+	codec, err := NewMap(mapType, OverrideValue(&rawJSONCodec{}))
+	require.NoError(t, err)
+	source := map[string]json.RawMessage{"a": json.RawMessage(`1`)}
+	encoded, err := codec.Encode(source, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	var decoded map[string]json.RawMessage
+	_, err = codec.Decode(encoded, &decoded, primitive.ProtocolVersion5)
+	require.NoError(t, err)
+	assert.Equal(t, source, decoded)
+}
+
+func TestCodecOverride_Errors(t *testing.T) {
+	udtType, _ := datatype.NewUserDefined("ks", "doc", []string{"id"}, []datatype.DataType{datatype.Int})
+	tupleType := datatype.NewTuple(datatype.Int)
+	mapType := datatype.NewMap(datatype.Int, datatype.Int)
+	tests := []struct {
+		name string
+		fn   func() (Codec, error)
+		err  string
+	}{
+		{"udt unknown field", func() (Codec, error) {
+			return NewUserDefined(udtType, OverrideField("body", &rawJSONCodec{}))
+		}, "invalid override for field body: no such field in ks.doc"},
+		{"udt wrong type", func() (Codec, error) {
+			return NewUserDefined(udtType, OverrideField("id", &rawJSONCodec{}))
+		}, "invalid override for field id: expected codec for int, got: varchar"},
+		{"udt wrong kind", func() (Codec, error) {
+			return NewUserDefined(udtType, OverrideKey(Int))
+		}, "invalid override for map keys: cannot be applied to ks.doc"},
+		{"tuple out of range", func() (Codec, error) {
+			return NewTuple(tupleType, OverrideElement(1, Int))
+		}, "invalid override for element 1: index out of range for tuple<int>"},
+		{"tuple nil codec", func() (Codec, error) {
+			return NewTuple(tupleType, OverrideElement(0, nil))
+		}, "invalid override for element 0: codec cannot be nil"},
+		{"map wrong type", func() (Codec, error) {
+			return NewMap(mapType, OverrideKey(&rawJSONCodec{}))
+		}, "invalid override for map keys: expected codec for int, got: varchar"},
+		{"map wrong kind", func() (Codec, error) {
+			return NewMap(mapType, OverrideField("id", Int))
+		}, "invalid override for field id: cannot be applied to map<int,int>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec, err := tt.fn()
+			assert.Nil(t, codec)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"reflect"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// preferredTypeCodec wraps a codec to decode values into a specific Go type when the destination is an *interface{},
+// instead of the type returned by PreferredGoType. See CodecRegistry.SetPreferredGoType.
+type preferredTypeCodec struct {
+	Codec
+	goType reflect.Type
+}
+
+func (c *preferredTypeCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	d, ok := dest.(*interface{})
+	if !ok {
+		return c.Codec.Decode(source, dest, version)
+	} else if d == nil {
+		return false, errCannotDecode(dest, c.DataType(), version, ErrNilDestination)
+	}
+	target := reflect.New(c.goType)
+	if wasNull, err = c.Codec.Decode(source, target.Interface(), version); err != nil {
+		return wasNull, err
+	} else if wasNull {
+		*d = nil
+	} else {
+		*d = target.Elem().Interface()
+	}
+	return wasNull, nil
+}
+
+// preferredGoTypeOf returns the Go type that the given codec uses when decoding into an *interface{}: it is the type
+// returned by PreferredGoType for its data type, unless the codec or one of its element, key or value codecs was
+// created by a CodecRegistry with a different preferred Go type.
+func preferredGoTypeOf(codec Codec) (reflect.Type, error) {
+	switch c := codec.(type) {
+	case *preferredTypeCodec:
+		return c.goType, nil
+	case *collectionCodec:
+		elemType, err := preferredGoTypeOf(c.elementCodec)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(ensureNillable(elemType)), nil
+	case *mapCodec:
+		keyType, err := preferredGoTypeOf(c.keyCodec)
+		if err != nil {
+			return nil, err
+		}
+		valueType, err := preferredGoTypeOf(c.valueCodec)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(ensureNillable(keyType), ensureNillable(valueType)), nil
+	}
+	return PreferredGoType(codec.DataType())
+}
+
+// PreferredGoType returns the Go type that codecs created by this registry use when decoding values of the given data
+// type into an *interface{}: either the type set with SetPreferredGoType, or the type returned by the package-level
+// PreferredGoType function. For lists, sets and maps, preferred types set for the element, key and value types are
+// taken into account.
+func (r *CodecRegistry) PreferredGoType(dt datatype.DataType) (reflect.Type, error) {
+	if dt == nil {
+		return nil, ErrNilDataType
+	} else if goType := r.lookupPreferredGoType(dt); goType != nil {
+		return goType, nil
+	}
+	switch dt := dt.(type) {
+	case *datatype.List:
+		if elemType, err := r.PreferredGoType(dt.ElementType); err != nil {
+			return nil, err
+		} else {
+			return reflect.SliceOf(ensureNillable(elemType)), nil
+		}
+	case *datatype.Set:
+		if elemType, err := r.PreferredGoType(dt.ElementType); err != nil {
+			return nil, err
+		} else {
+			return reflect.SliceOf(ensureNillable(elemType)), nil
+		}
+	case *datatype.Map:
+		if keyType, err := r.PreferredGoType(dt.KeyType); err != nil {
+			return nil, err
+		} else if valueType, err := r.PreferredGoType(dt.ValueType); err != nil {
+			return nil, err
+		} else {
+			return reflect.MapOf(ensureNillable(keyType), ensureNillable(valueType)), nil
+		}
+	}
+	return PreferredGoType(dt)
+}
@@ -36,6 +36,10 @@ var (
 	typeOfBoolean              = reflect.TypeOf(false)
 	typeOfCqlDecimal           = reflect.TypeOf(CqlDecimal{})
 	typeOfCqlDuration          = reflect.TypeOf(CqlDuration{})
+	typeOfCqlPoint             = reflect.TypeOf(CqlPoint{})
+	typeOfCqlLineString        = reflect.TypeOf(CqlLineString{})
+	typeOfCqlPolygon           = reflect.TypeOf(CqlPolygon{})
+	typeOfCqlDateRange         = reflect.TypeOf(CqlDateRange{})
 	typeOfTime                 = reflect.TypeOf(time.Time{})
 	typeOfDuration             = reflect.TypeOf(time.Duration(0))
 	typeOfNetIP                = reflect.TypeOf((*net.IP)(nil)).Elem()
@@ -0,0 +1,177 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+)
+
+// DefaultCodecRegistry is the CodecRegistry consulted by NewCodec and by the constructor functions for complex types:
+// NewList, NewSet, NewMap, NewTuple and NewUserDefined. It is empty by default.
+var DefaultCodecRegistry = NewCodecRegistry()
+
+// CodecRegistry holds user-provided codecs that take precedence over the built-in ones. Codecs can be registered
+// either by CQL type, or for a specific keyspace, table and column.
+//
+// Codecs registered by CQL type are matched by their data type's CQL representation; this allows codecs to be
+// registered for custom types by class name, e.g. for a codec whose data type is
+// datatype.NewCustom("com.example.MyType"). Codecs for complex types can be registered as well, e.g. for a codec
+// whose data type is datatype.NewList(datatype.Int).
+//
+// The Go types used when decoding into an *interface{} can also be customized, see SetPreferredGoType.
+//
+// A CodecRegistry is safe for concurrent use. A nil *CodecRegistry behaves like an empty registry.
+type CodecRegistry struct {
+	byType   map[string]Codec
+	byColumn map[columnKey]Codec
+	// preferredTypes are the Go types to decode into when the destination is an *interface{}, by CQL type.
+	preferredTypes map[string]reflect.Type
+	lock           sync.RWMutex
+}
+
+type columnKey struct {
+	keyspace string
+	table    string
+	column   string
+}
+
+// NewCodecRegistry creates a new, empty CodecRegistry.
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{
+		byType:         make(map[string]Codec),
+		byColumn:       make(map[columnKey]Codec),
+		preferredTypes: make(map[string]reflect.Type),
+	}
+}
+
+// Register registers the given codec for its data type, replacing any codec previously registered for that type.
+func (r *CodecRegistry) Register(codec Codec) error {
+	if codec == nil {
+		return errors.New("cannot register nil codec")
+	} else if codec.DataType() == nil {
+		return fmt.Errorf("cannot register codec: %w", ErrNilDataType)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.byType[codec.DataType().AsCql()] = codec
+	return nil
+}
+
+// RegisterColumn registers the given codec for the given keyspace, table and column, replacing any codec previously
+// registered for that column. Column codecs are only consulted by NewColumnCodec; they take precedence over codecs
+// registered by CQL type.
+func (r *CodecRegistry) RegisterColumn(keyspace string, table string, column string, codec Codec) error {
+	if codec == nil {
+		return errors.New("cannot register nil codec")
+	} else if codec.DataType() == nil {
+		return fmt.Errorf("cannot register codec: %w", ErrNilDataType)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.byColumn[columnKey{keyspace, table, column}] = codec
+	return nil
+}
+
+// SetPreferredGoType sets the Go type to decode values of the given CQL type into, when the destination of a codec
+// created by this registry is an *interface{}; this replaces the type returned by PreferredGoType, e.g. to decode
+// timestamps into int64 milliseconds instead of time.Time, typically because the decoded values are then marshalled
+// to JSON. The codec for the given CQL type must support decoding into a pointer to the given Go type. The preferred
+// type also applies when decoding lists, sets and maps containing the given CQL type into an *interface{}.
+// Passing a nil Go type removes the preferred type previously set. Codecs created before calling this method are not
+// affected.
+func (r *CodecRegistry) SetPreferredGoType(dt datatype.DataType, goType reflect.Type) error {
+	if dt == nil {
+		return fmt.Errorf("cannot set preferred Go type: %w", ErrNilDataType)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if goType == nil {
+		delete(r.preferredTypes, dt.AsCql())
+	} else {
+		r.preferredTypes[dt.AsCql()] = goType
+	}
+	return nil
+}
+
+func (r *CodecRegistry) lookupPreferredGoType(dt datatype.DataType) reflect.Type {
+	if r == nil {
+		return nil
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.preferredTypes[dt.AsCql()]
+}
+
+// Lookup returns the codec registered for the given data type, or nil if there is none.
+func (r *CodecRegistry) Lookup(dt datatype.DataType) Codec {
+	if r == nil || dt == nil {
+		return nil
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if len(r.byType) == 0 {
+		return nil
+	}
+	return r.byType[dt.AsCql()]
+}
+
+// LookupColumn returns the codec registered for the given keyspace, table and column, or nil if there is none.
+func (r *CodecRegistry) LookupColumn(keyspace string, table string, column string) Codec {
+	if r == nil {
+		return nil
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.byColumn[columnKey{keyspace, table, column}]
+}
+
+// NewCodec returns a codec for the given data type. If a codec was registered for that type, it is returned;
+// otherwise, a built-in codec is created, as in the package-level NewCodec function. For complex types, element,
+// key, value and field codecs are also resolved using this registry. If a preferred Go type was set for the data type,
+// the returned codec decodes into that type when the destination is an *interface{}, see SetPreferredGoType.
+func (r *CodecRegistry) NewCodec(dt datatype.DataType) (codec Codec, err error) {
+	if dt == nil {
+		return nil, ErrNilDataType
+	} else if codec = r.Lookup(dt); codec == nil {
+		if codec, err = newBuiltinCodec(dt, r); err != nil {
+			return nil, err
+		}
+	}
+	if goType := r.lookupPreferredGoType(dt); goType != nil {
+		codec = &preferredTypeCodec{Codec: codec, goType: goType}
+	}
+	return codec, nil
+}
+
+// NewColumnCodec returns a codec for the given keyspace, table and column, whose data type is dt. If a codec was
+// registered for that column, it is returned, provided that its data type matches dt; otherwise, this method behaves
+// like NewCodec.
+func (r *CodecRegistry) NewColumnCodec(keyspace string, table string, column string, dt datatype.DataType) (Codec, error) {
+	if dt == nil {
+		return nil, ErrNilDataType
+	} else if codec := r.LookupColumn(keyspace, table, column); codec != nil {
+		if codec.DataType().AsCql() != dt.AsCql() {
+			return nil, fmt.Errorf("codec registered for column %s.%s.%s has wrong data type: expected %v, got %v",
+				keyspace, table, column, dt, codec.DataType())
+		}
+		return codec, nil
+	}
+	return r.NewCodec(dt)
+}
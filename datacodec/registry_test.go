@@ -0,0 +1,228 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+type point struct {
+	X, Y int32
+}
+
+var pointType = datatype.NewCustom("com.example.Point")
+
+// pointCodec is an example of a user-provided codec mapping a custom CQL type to a Go struct.
+type pointCodec struct{}
+
+func (c *pointCodec) DataType() datatype.DataType {
+	return pointType
+}
+
+func (c *pointCodec) Encode(source interface{}, _ primitive.ProtocolVersion) ([]byte, error) {
+	p, ok := source.(*point)
+	if !ok {
+		return nil, ErrSourceTypeNotSupported
+	} else if p == nil {
+		return nil, nil
+	}
+	dest := make([]byte, 8)
+	binary.BigEndian.PutUint32(dest, uint32(p.X))
+	binary.BigEndian.PutUint32(dest[4:], uint32(p.Y))
+	return dest, nil
+}
+
+func (c *pointCodec) Decode(source []byte, dest interface{}, _ primitive.ProtocolVersion) (bool, error) {
+	p, ok := dest.(*point)
+	if !ok {
+		return false, ErrDestinationTypeNotSupported
+	} else if len(source) == 0 {
+		*p = point{}
+		return true, nil
+	} else if len(source) != 8 {
+		return false, errors.New("wrong length")
+	}
+	p.X = int32(binary.BigEndian.Uint32(source))
+	p.Y = int32(binary.BigEndian.Uint32(source[4:]))
+	return false, nil
+}
+
+func TestCodecRegistry_NewCodec(t *testing.T) {
+	registry := NewCodecRegistry()
+	require.NoError(t, registry.Register(&pointCodec{}))
+	t.Run("custom type by class name", func(t *testing.T) {
+		codec, err := registry.NewCodec(datatype.NewCustom("com.example.Point"))
+		require.NoError(t, err)
+		assert.IsType(t, &pointCodec{}, codec)
+		codec, err = registry.NewCodec(datatype.NewCustom("com.example.Other"))
+		require.NoError(t, err)
+		assert.IsType(t, &blobCodec{}, codec)
+	})
+	t.Run("built-in type", func(t *testing.T) {
+		codec, err := registry.NewCodec(datatype.Int)
+		require.NoError(t, err)
+		assert.Equal(t, Int, codec)
+	})
+	t.Run("nested custom type", func(t *testing.T) {
+		codec, err := registry.NewCodec(datatype.NewList(pointType))
+		require.NoError(t, err)
+		encoded, err := codec.Encode([]*point{{1, 2}, {3, 4}}, primitive.ProtocolVersion5)
+		require.NoError(t, err)
+		var decoded []*point
+		wasNull, err := codec.Decode(encoded, &decoded, primitive.ProtocolVersion5)
+		require.NoError(t, err)
+		assert.False(t, wasNull)
+		assert.Equal(t, []*point{{1, 2}, {3, 4}}, decoded)
+	})
+	t.Run("override built-in type", func(t *testing.T) {
+		override := NewCodecRegistry()
+		require.NoError(t, override.Register(StrictVarchar))
+		codec, err := override.NewCodec(datatype.NewMap(datatype.Varchar, datatype.Int))
+		require.NoError(t, err)
+		assert.Equal(t, StrictVarchar, codec.(*mapCodec).keyCodec)
+	})
+	t.Run("nil data type", func(t *testing.T) {
+		_, err := registry.NewCodec(nil)
+		assert.Equal(t, ErrNilDataType, err)
+	})
+	t.Run("nil registry", func(t *testing.T) {
+		var nilRegistry *CodecRegistry
+		codec, err := nilRegistry.NewCodec(datatype.Int)
+		require.NoError(t, err)
+		assert.Equal(t, Int, codec)
+	})
+}
+
+func TestCodecRegistry_NewColumnCodec(t *testing.T) {
+	registry := NewCodecRegistry()
+	require.NoError(t, registry.RegisterColumn("ks1", "table1", "col1", StrictVarchar))
+	codec, err := registry.NewColumnCodec("ks1", "table1", "col1", datatype.Varchar)
+	require.NoError(t, err)
+	assert.Equal(t, StrictVarchar, codec)
+	codec, err = registry.NewColumnCodec("ks1", "table1", "col2", datatype.Varchar)
+	require.NoError(t, err)
+	assert.Equal(t, Varchar, codec)
+	_, err = registry.NewColumnCodec("ks1", "table1", "col1", datatype.Int)
+	assert.EqualError(t, err, "codec registered for column ks1.table1.col1 has wrong data type: expected int, got varchar")
+}
+
+func TestCodecRegistry_Register(t *testing.T) {
+	registry := NewCodecRegistry()
+	assert.EqualError(t, registry.Register(nil), "cannot register nil codec")
+	assert.EqualError(t, registry.RegisterColumn("ks1", "table1", "col1", nil), "cannot register nil codec")
+	assert.EqualError(t, registry.Register(&blobCodec{}), "cannot register codec: data type is nil")
+}
+
+func TestNewCodec_DefaultCodecRegistry(t *testing.T) {
+	require.NoError(t, DefaultCodecRegistry.Register(&pointCodec{}))
+	defer func() { DefaultCodecRegistry = NewCodecRegistry() }()
+	codec, err := NewCodec(pointType)
+	require.NoError(t, err)
+	assert.IsType(t, &pointCodec{}, codec)
+	codec, err = NewTuple(datatype.NewTuple(datatype.Int, pointType))
+	require.NoError(t, err)
+	assert.IsType(t, &pointCodec{}, codec.(*tupleCodec).elementCodecs[1])
+}
+
+func TestCodecRegistry_SetPreferredGoType(t *testing.T) {
+	registry := NewCodecRegistry()
+	require.NoError(t, registry.Register(&pointCodec{}))
+	require.NoError(t, registry.SetPreferredGoType(datatype.Timestamp, typeOfInt64))
+	require.NoError(t, registry.SetPreferredGoType(pointType, reflect.TypeOf(point{})))
+	assert.EqualError(t, registry.SetPreferredGoType(nil, typeOfInt64), "cannot set preferred Go type: data type is nil")
+	millis := int64(1_600_000_000_123)
+	encodedTimestamp, err := Timestamp.Encode(millis, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	encodedPoint, err := (&pointCodec{}).Encode(&point{1, 2}, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	tests := []struct {
+		name     string
+		dt       datatype.DataType
+		value    interface{}
+		expected interface{}
+		goType   reflect.Type
+	}{
+		{"timestamp", datatype.Timestamp, millis, millis, typeOfInt64},
+		{"timestamp null", datatype.Timestamp, nil, nil, typeOfInt64},
+		{"custom", pointType, &point{1, 2}, point{1, 2}, reflect.TypeOf(point{})},
+		{"list", datatype.NewList(datatype.Timestamp), []int64{millis}, []*int64{&millis}, reflect.TypeOf([]*int64{})},
+		{"tuple", datatype.NewTuple(datatype.Int, datatype.Timestamp), []interface{}{int32(1), millis}, []interface{}{int32(1), millis}, typeOfInterfaceSlice},
+		{"other types unaffected", datatype.Date, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), typeOfTime},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec, err := registry.NewCodec(tt.dt)
+			require.NoError(t, err)
+			goType, err := registry.PreferredGoType(tt.dt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.goType, goType)
+			encoded, err := codec.Encode(tt.value, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			var decoded interface{}
+			wasNull, err := codec.Decode(encoded, &decoded, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value == nil, wasNull)
+			assert.Equal(t, tt.expected, decoded)
+		})
+	}
+	t.Run("map", func(t *testing.T) {
+		codec, err := registry.NewCodec(datatype.NewMap(datatype.Varchar, datatype.Timestamp))
+		require.NoError(t, err)
+		encoded, err := codec.Encode(map[string]int64{"a": millis}, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		var decoded interface{}
+		_, err = codec.Decode(encoded, &decoded, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		require.IsType(t, map[*string]*int64{}, decoded)
+		for k, v := range decoded.(map[*string]*int64) {
+			assert.Equal(t, "a", *k)
+			assert.Equal(t, millis, *v)
+		}
+	})
+	t.Run("other destinations unaffected", func(t *testing.T) {
+		codec, err := registry.NewCodec(datatype.Timestamp)
+		require.NoError(t, err)
+		var decoded time.Time
+		_, err = codec.Decode(encodedTimestamp, &decoded, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, millis, decoded.UnixNano()/int64(time.Millisecond))
+		var p point
+		codec, err = registry.NewCodec(pointType)
+		require.NoError(t, err)
+		_, err = codec.Decode(encodedPoint, &p, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, point{1, 2}, p)
+	})
+	t.Run("removed", func(t *testing.T) {
+		require.NoError(t, registry.SetPreferredGoType(datatype.Timestamp, nil))
+		codec, err := registry.NewCodec(datatype.Timestamp)
+		require.NoError(t, err)
+		assert.Equal(t, Timestamp, codec)
+		var decoded interface{}
+		_, err = codec.Decode(encodedTimestamp, &decoded, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.IsType(t, time.Time{}, decoded)
+	})
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// EncodeRoutingKey returns the routing key of the given prepared statement for the given bound values, given as Go
+// values for all the bound variables in order; values are encoded with the codecs returned by NewCodec for their
+// column types. See message.ComposeRoutingKey for the routing key format. It returns an error if the partition key
+// indices are unknown, or if any partition key value is missing, nil, or cannot be encoded.
+func EncodeRoutingKey(prepared *message.PreparedResult, values []interface{}, version primitive.ProtocolVersion) ([]byte, error) {
+	columns, err := prepared.PartitionKeyColumns()
+	if err != nil {
+		return nil, fmt.Errorf("cannot compute routing key: %w", err)
+	}
+	components := make([][]byte, len(columns))
+	for i, column := range columns {
+		index := prepared.VariablesMetadata.PkIndices[i]
+		if int(index) >= len(values) {
+			return nil, fmt.Errorf("cannot compute routing key: missing value for partition key component %d", i)
+		}
+		codec, err := NewCodec(column.Type)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compute routing key: partition key component %d: %w", i, err)
+		}
+		if components[i], err = codec.Encode(values[index], version); err != nil {
+			return nil, fmt.Errorf("cannot compute routing key: partition key component %d: %w", i, err)
+		} else if components[i] == nil {
+			return nil, fmt.Errorf("cannot compute routing key: partition key component %d is null", i)
+		}
+	}
+	return message.ComposeRoutingKey(components...)
+}
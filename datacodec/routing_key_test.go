@@ -0,0 +1,63 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestEncodeRoutingKey(t *testing.T) {
+	// INSERT INTO ks1.table1 (c, pk2, pk1) VALUES (?, ?, ?) with PRIMARY KEY ((pk1, pk2), c)
+	composite := &message.PreparedResult{
+		VariablesMetadata: &message.VariablesMetadata{
+			PkIndices: []uint16{2, 1},
+			Columns: []*message.ColumnMetadata{
+				{Keyspace: "ks1", Table: "table1", Name: "c", Index: 0, Type: datatype.Int},
+				{Keyspace: "ks1", Table: "table1", Name: "pk2", Index: 1, Type: datatype.Varchar},
+				{Keyspace: "ks1", Table: "table1", Name: "pk1", Index: 2, Type: datatype.Int},
+			},
+		},
+	}
+	// SELECT * FROM ks1.table2 WHERE pk = ?
+	single := &message.PreparedResult{
+		VariablesMetadata: &message.VariablesMetadata{
+			PkIndices: []uint16{0},
+			Columns: []*message.ColumnMetadata{
+				{Keyspace: "ks1", Table: "table2", Name: "pk", Index: 0, Type: datatype.Bigint},
+			},
+		},
+	}
+	key, err := EncodeRoutingKey(composite, []interface{}{2, "abc", 1}, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 4, 0, 0, 0, 1, 0, 0, 3, 'a', 'b', 'c', 0}, key)
+	key, err = EncodeRoutingKey(single, []interface{}{int64(42)}, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 42}, key)
+	_, err = EncodeRoutingKey(composite, []interface{}{2, "abc"}, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "cannot compute routing key: missing value for partition key component 0")
+	_, err = EncodeRoutingKey(composite, []interface{}{2, nil, 1}, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "cannot compute routing key: partition key component 1 is null")
+	_, err = EncodeRoutingKey(composite, []interface{}{2, "abc", true}, primitive.ProtocolVersion4)
+	assert.ErrorIs(t, err, ErrConversionNotSupported)
+	_, err = EncodeRoutingKey(&message.PreparedResult{}, nil, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "cannot compute routing key: prepared statement has no partition key indices")
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// NullValue is the type of Null.
+type NullValue struct{}
+
+// Null is the sentinel value representing a CQL NULL in the maps decoded and encoded by RowCodec. Unlike a nil
+// interface{}, it makes explicit that a column is present in a row, and NULL. It is marshaled to JSON as null.
+var Null = NullValue{}
+
+func (NullValue) String() string {
+	return "NULL"
+}
+
+// MarshalJSON marshals this value as a JSON null.
+func (NullValue) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// RowCodec encodes and decodes whole rows, as found in message.RowsResult, to and from maps of column names to Go
+// values; it is meant for generic tools rendering the results of arbitrary queries, e.g. REST APIs or CLIs.
+//
+// Values are decoded into their preferred Go types, as when decoding into an *interface{}, and NULLs are decoded as
+// Null. When encoding, Null and nil are both encoded as NULL; other values can be of any Go type accepted by the
+// column codecs. RowCodec instances should be created by calling NewRowCodec or CodecRegistry.NewRowCodec.
+type RowCodec struct {
+	columns []*message.ColumnMetadata
+	codecs  []Codec
+}
+
+// NewRowCodec creates a new RowCodec for the given rows metadata. Codecs registered in DefaultCodecRegistry take
+// precedence over the built-in codecs, see CodecRegistry.NewRowCodec.
+func NewRowCodec(metadata *message.RowsMetadata) (*RowCodec, error) {
+	return DefaultCodecRegistry.NewRowCodec(metadata)
+}
+
+// NewRowCodec creates a new RowCodec for the given rows metadata, using this registry to create the codec of each
+// column, see NewColumnCodec. It returns an error if the metadata has no column specs, e.g. because the query was
+// executed with the skip metadata flag, or if two columns have the same name, e.g. in "SELECT c, c FROM t"; use
+// aliases to disambiguate such columns.
+func (r *CodecRegistry) NewRowCodec(metadata *message.RowsMetadata) (*RowCodec, error) {
+	if metadata == nil {
+		return nil, errors.New("cannot create row codec: rows metadata is nil")
+	} else if len(metadata.Columns) != int(metadata.ColumnCount) {
+		return nil, fmt.Errorf("cannot create row codec: expected %d column specs, got: %d",
+			metadata.ColumnCount, len(metadata.Columns))
+	}
+	codec := &RowCodec{
+		columns: metadata.Columns,
+		codecs:  make([]Codec, len(metadata.Columns)),
+	}
+	names := make(map[string]bool, len(metadata.Columns))
+	for i, column := range metadata.Columns {
+		if column == nil {
+			return nil, fmt.Errorf("cannot create row codec: column %d is nil", i)
+		} else if names[column.Name] {
+			return nil, fmt.Errorf("cannot create row codec: duplicate column name: %s", column.Name)
+		}
+		names[column.Name] = true
+		var err error
+		if codec.codecs[i], err = r.NewColumnCodec(column.Keyspace, column.Table, column.Name, column.Type); err != nil {
+			return nil, fmt.Errorf("cannot create row codec: column %d (%s): %w", i, column.Name, err)
+		}
+	}
+	return codec, nil
+}
+
+// Columns returns the columns of the rows handled by this codec.
+func (c *RowCodec) Columns() []*message.ColumnMetadata {
+	return c.columns
+}
+
+// Decode decodes the given row into a new map of column names to values; NULL values are decoded as Null.
+func (c *RowCodec) Decode(row message.Row, version primitive.ProtocolVersion) (map[string]interface{}, error) {
+	if len(row) != len(c.columns) {
+		return nil, fmt.Errorf("cannot decode row: expected %d columns, got: %d", len(c.columns), len(row))
+	}
+	values := make(map[string]interface{}, len(c.columns))
+	for i, column := range c.columns {
+		var value interface{}
+		if wasNull, err := c.codecs[i].Decode(row[i], &value, version); err != nil {
+			return nil, fmt.Errorf("cannot decode row: column %d (%s): %w", i, column.Name, err)
+		} else if wasNull {
+			value = Null
+		}
+		values[column.Name] = value
+	}
+	return values, nil
+}
+
+// Encode encodes the given map of column names to values into a new row. Null and nil values are encoded as NULL. It
+// returns an error if a column is missing from the map, or if the map contains unknown columns.
+func (c *RowCodec) Encode(values map[string]interface{}, version primitive.ProtocolVersion) (message.Row, error) {
+	if len(values) > len(c.columns) {
+		return nil, c.errUnknownColumn(values)
+	}
+	row := make(message.Row, len(c.columns))
+	for i, column := range c.columns {
+		value, found := values[column.Name]
+		if !found {
+			return nil, fmt.Errorf("cannot encode row: missing column %d (%s)", i, column.Name)
+		} else if _, null := value.(NullValue); null {
+			continue
+		}
+		var err error
+		if row[i], err = c.codecs[i].Encode(value, version); err != nil {
+			return nil, fmt.Errorf("cannot encode row: column %d (%s): %w", i, column.Name, err)
+		}
+	}
+	return row, nil
+}
+
+func (c *RowCodec) errUnknownColumn(values map[string]interface{}) error {
+	for name := range values {
+		found := false
+		for _, column := range c.columns {
+			if column.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("cannot encode row: unknown column: %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+var rowCodecTestMetadata = &message.RowsMetadata{
+	ColumnCount: 3,
+	Columns: []*message.ColumnMetadata{
+		{Keyspace: "ks1", Table: "table1", Name: "id", Index: 0, Type: datatype.Int},
+		{Keyspace: "ks1", Table: "table1", Name: "name", Index: 1, Type: datatype.Varchar},
+		{Keyspace: "ks1", Table: "table1", Name: "tags", Index: 2, Type: datatype.NewList(datatype.Varchar)},
+	},
+}
+
+func TestRowCodec(t *testing.T) {
+	codec, err := NewRowCodec(rowCodecTestMetadata)
+	require.NoError(t, err)
+	assert.Equal(t, rowCodecTestMetadata.Columns, codec.Columns())
+	row := message.Row{
+		{0, 0, 0, 1},
+		nil,
+		{0, 0, 0, 1, 0, 0, 0, 1, 'a'},
+	}
+
+	decoded, err := codec.Decode(row, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": int32(1), "name": Null, "tags": []*string{stringPtr("a")}}, decoded)
+	doc, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"name":null,"tags":["a"]}`, string(doc))
+
+	encoded, err := codec.Encode(decoded, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, row, encoded)
+	encoded, err = codec.Encode(map[string]interface{}{"id": 1, "name": nil, "tags": []string{"a"}}, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, row, encoded)
+
+	_, err = codec.Decode(row[:2], primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "cannot decode row: expected 3 columns, got: 2")
+	_, err = codec.Decode(message.Row{{1}, nil, nil}, primitive.ProtocolVersion4)
+	assert.Error(t, err)
+	_, err = codec.Encode(map[string]interface{}{"id": 1, "name": Null}, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "cannot encode row: missing column 2 (tags)")
+	_, err = codec.Encode(map[string]interface{}{"id": 1, "name": Null, "tags": Null, "foo": 1}, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "cannot encode row: unknown column: foo")
+	_, err = codec.Encode(map[string]interface{}{"id": true, "name": Null, "tags": Null}, primitive.ProtocolVersion4)
+	assert.ErrorIs(t, err, ErrConversionNotSupported)
+}
+
+func TestCodecRegistry_NewRowCodec(t *testing.T) {
+	registry := NewCodecRegistry()
+	require.NoError(t, registry.SetPreferredGoType(datatype.Int, reflect.TypeOf(int64(0))))
+	codec, err := registry.NewRowCodec(rowCodecTestMetadata)
+	require.NoError(t, err)
+	decoded, err := codec.Decode(message.Row{{0, 0, 0, 1}, nil, nil}, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": int64(1), "name": Null, "tags": Null}, decoded)
+
+	_, err = registry.NewRowCodec(nil)
+	assert.EqualError(t, err, "cannot create row codec: rows metadata is nil")
+	_, err = registry.NewRowCodec(&message.RowsMetadata{ColumnCount: 1})
+	assert.EqualError(t, err, "cannot create row codec: expected 1 column specs, got: 0")
+	_, err = registry.NewRowCodec(&message.RowsMetadata{
+		ColumnCount: 2,
+		Columns:     []*message.ColumnMetadata{rowCodecTestMetadata.Columns[0], rowCodecTestMetadata.Columns[0]},
+	})
+	assert.EqualError(t, err, "cannot create row codec: duplicate column name: id")
+}
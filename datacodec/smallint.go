@@ -33,6 +33,10 @@ func (c *smallintCodec) DataType() datatype.DataType {
 }
 
 func (c *smallintCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return nil, errCannotEncode(source, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	var val int16
 	var wasNil bool
 	if val, wasNil, err = convertToInt16(source); err == nil && !wasNil {
@@ -45,6 +49,12 @@ func (c *smallintCodec) Encode(source interface{}, version primitive.ProtocolVer
 }
 
 func (c *smallintCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return false, errCannotDecode(dest, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	var val int16
 	if val, wasNull, err = readInt16(source); err == nil {
 		err = convertFromInt16(val, wasNull, dest)
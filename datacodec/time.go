@@ -99,6 +99,10 @@ func (c *timeCodec) DataType() datatype.DataType {
 }
 
 func (c *timeCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return nil, errCannotEncode(source, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	var val int64
 	var wasNil bool
 	if val, wasNil, err = convertToInt64Time(source, c.layout); err == nil && !wasNil {
@@ -111,6 +115,12 @@ func (c *timeCodec) Encode(source interface{}, version primitive.ProtocolVersion
 }
 
 func (c *timeCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return false, errCannotDecode(dest, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	var val int64
 	if val, wasNull, err = readInt64(source); err == nil {
 		err = convertFromInt64Time(val, wasNull, dest, c.layout)
@@ -81,16 +81,89 @@ var Timestamp = NewTimestamp(TimestampLayoutDefault, time.UTC)
 // used only when encoding from or decoding to string; it is ignored otherwise. The location is only useful if the
 // layout does not include any time zone, in which case the time zone is assumed to be in the given location.
 func NewTimestamp(layout string, location *time.Location) Codec {
-	return &timestampCodec{
-		layout:     layout,
-		location:   location,
+	return NewTimestampWithOptions(TimestampLayouts(layout), TimestampLocation(location))
+}
+
+// TimestampOption configures a codec created with NewTimestampWithOptions.
+type TimestampOption func(codec *timestampCodec)
+
+// TimestampLocation sets the location of the codec: decoded time.Time values are expressed in this location, and
+// strings and CivilDateTime values without time zone are assumed to be in this location. The default is UTC.
+func TimestampLocation(location *time.Location) TimestampOption {
+	return func(codec *timestampCodec) {
+		codec.location = location
+	}
+}
+
+// TimestampLayouts sets the layouts of the codec. The first layout is used to format strings when decoding; when
+// encoding, strings are parsed with each layout in turn, until one succeeds. The default is TimestampLayoutDefault.
+func TimestampLayouts(layouts ...string) TimestampOption {
+	return func(codec *timestampCodec) {
+		codec.layouts = layouts
+	}
+}
+
+// TimestampCivil makes the codec decode to CivilDateTime values, expressed in the codec's location, when decoding to
+// *interface{}. CivilDateTime values are always accepted when encoding, and when decoding to *CivilDateTime.
+func TimestampCivil() TimestampOption {
+	return func(codec *timestampCodec) {
+		codec.civil = true
+	}
+}
+
+// NewTimestampWithOptions creates a new codec for CQL timestamp values, configured with the given options. Without
+// options, the returned codec behaves like Timestamp.
+func NewTimestampWithOptions(options ...TimestampOption) Codec {
+	codec := &timestampCodec{
+		layouts:    []string{TimestampLayoutDefault},
+		location:   time.UTC,
 		innerCodec: &bigintCodec{dataType: datatype.Timestamp},
 	}
+	for _, option := range options {
+		option(codec)
+	}
+	if len(codec.layouts) == 0 {
+		codec.layouts = []string{TimestampLayoutDefault}
+	}
+	if codec.location == nil {
+		codec.location = time.UTC
+	}
+	return codec
+}
+
+// CivilDateTime is a date and time of day without time zone, as read on a wall clock. When encoding, it is
+// interpreted in the location of the timestamp codec; see TimestampLocation. Fields are normalized the same way
+// time.Date normalizes its arguments.
+type CivilDateTime struct {
+	Year       int
+	Month      time.Month
+	Day        int
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+// CivilDateTimeOf returns the date and time of day of the given time, in the time's location.
+func CivilDateTimeOf(t time.Time) CivilDateTime {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	return CivilDateTime{year, month, day, hour, minute, second, t.Nanosecond()}
+}
+
+// In returns the time corresponding to this date and time of day in the given location.
+func (d CivilDateTime) In(location *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, d.Hour, d.Minute, d.Second, d.Nanosecond, location)
+}
+
+func (d CivilDateTime) String() string {
+	return d.In(time.UTC).Format("2006-01-02T15:04:05.999999999")
 }
 
 type timestampCodec struct {
-	layout     string
+	layouts    []string
 	location   *time.Location
+	civil      bool
 	innerCodec *bigintCodec
 }
 
@@ -99,9 +172,10 @@ func (c *timestampCodec) DataType() datatype.DataType {
 }
 
 func (c *timestampCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	var val int64
 	var wasNil bool
-	if val, wasNil, err = convertToInt64Timestamp(source, c.layout, c.location); err == nil && !wasNil {
+	if val, wasNil, err = convertToInt64Timestamp(source, c.layouts, c.location); err == nil && !wasNil {
 		dest = writeInt64(val)
 	}
 	if err != nil {
@@ -111,9 +185,12 @@ func (c *timestampCodec) Encode(source interface{}, version primitive.ProtocolVe
 }
 
 func (c *timestampCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	var val int64
 	if val, wasNull, err = readInt64(source); err == nil {
-		err = convertFromInt64Timestamp(val, wasNull, dest, c.layout, c.location)
+		err = convertFromInt64Timestamp(val, wasNull, dest, c.layouts[0], c.location, c.civil)
 	}
 	if err != nil {
 		err = errCannotDecode(dest, c.DataType(), version, err)
@@ -121,7 +198,7 @@ func (c *timestampCodec) Decode(source []byte, dest interface{}, version primiti
 	return
 }
 
-func convertToInt64Timestamp(source interface{}, layout string, location *time.Location) (val int64, wasNil bool, err error) {
+func convertToInt64Timestamp(source interface{}, layouts []string, location *time.Location) (val int64, wasNil bool, err error) {
 	switch s := source.(type) {
 	case time.Time:
 		val, err = ConvertTimeToEpochMillis(s)
@@ -129,11 +206,17 @@ func convertToInt64Timestamp(source interface{}, layout string, location *time.L
 		if wasNil = s == nil; !wasNil {
 			val, err = ConvertTimeToEpochMillis(*s)
 		}
+	case CivilDateTime:
+		val, err = ConvertTimeToEpochMillis(s.In(location))
+	case *CivilDateTime:
+		if wasNil = s == nil; !wasNil {
+			val, err = ConvertTimeToEpochMillis(s.In(location))
+		}
 	case string:
-		val, err = stringToEpochMillis(s, layout, location)
+		val, err = stringToEpochMillisMultiLayout(s, layouts, location)
 	case *string:
 		if wasNil = s == nil; !wasNil {
-			val, err = stringToEpochMillis(*s, layout, location)
+			val, err = stringToEpochMillisMultiLayout(*s, layouts, location)
 		}
 	case nil:
 		wasNil = true
@@ -146,13 +229,28 @@ func convertToInt64Timestamp(source interface{}, layout string, location *time.L
 	return
 }
 
-func convertFromInt64Timestamp(val int64, wasNull bool, dest interface{}, layout string, location *time.Location) (err error) {
+// stringToEpochMillisMultiLayout parses the given string with each layout in turn; if all layouts fail, the error
+// returned is the one of the first layout.
+func stringToEpochMillisMultiLayout(val string, layouts []string, location *time.Location) (millis int64, err error) {
+	for i, layout := range layouts {
+		if parsed, parseErr := stringToEpochMillis(val, layout, location); parseErr == nil {
+			return parsed, nil
+		} else if i == 0 {
+			err = parseErr
+		}
+	}
+	return 0, err
+}
+
+func convertFromInt64Timestamp(val int64, wasNull bool, dest interface{}, layout string, location *time.Location, civil bool) (err error) {
 	switch d := dest.(type) {
 	case *interface{}:
 		if d == nil {
 			err = ErrNilDestination
 		} else if wasNull {
 			*d = nil
+		} else if civil {
+			*d = CivilDateTimeOf(ConvertEpochMillisToTime(val).In(location))
 		} else {
 			*d = ConvertEpochMillisToTime(val).In(location)
 		}
@@ -164,6 +262,14 @@ func convertFromInt64Timestamp(val int64, wasNull bool, dest interface{}, layout
 		} else {
 			*d = ConvertEpochMillisToTime(val).In(location)
 		}
+	case *CivilDateTime:
+		if d == nil {
+			err = ErrNilDestination
+		} else if wasNull {
+			*d = CivilDateTime{}
+		} else {
+			*d = CivilDateTimeOf(ConvertEpochMillisToTime(val).In(location))
+		}
 	case *string:
 		if d == nil {
 			err = ErrNilDestination
@@ -167,7 +167,7 @@ func Test_convertToInt64Timestamp(t *testing.T) {
 					}
 					for _, tt := range tests {
 						t.Run(tt.name, func(t *testing.T) {
-							gotVal, gotWasNil, gotErr := convertToInt64Timestamp(tt.source, layout, location)
+							gotVal, gotWasNil, gotErr := convertToInt64Timestamp(tt.source, []string{layout}, location)
 							assert.Equal(t, tt.wantVal, gotVal)
 							assert.Equal(t, tt.wantWasNil, gotWasNil)
 							assertErrorMessage(t, tt.wantErr, gotErr)
@@ -206,7 +206,7 @@ func Test_convertFromInt64Timestamp(t *testing.T) {
 					}
 					for _, tt := range tests {
 						t.Run(tt.name, func(t *testing.T) {
-							gotErr := convertFromInt64Timestamp(tt.val, tt.wasNull, tt.dest, layout, location)
+							gotErr := convertFromInt64Timestamp(tt.val, tt.wasNull, tt.dest, layout, location, false)
 							assert.Equal(t, tt.expected, tt.dest)
 							assertErrorMessage(t, tt.wantErr, gotErr)
 						})
@@ -216,3 +216,72 @@ func Test_convertFromInt64Timestamp(t *testing.T) {
 		})
 	}
 }
+
+func TestNewTimestampWithOptions(t *testing.T) {
+	// 2021-10-12 01:00:00.999 in Paris
+	civil := CivilDateTime{2021, time.October, 12, 1, 0, 0, 999_000_000}
+	codec := NewTimestampWithOptions(
+		TimestampLocation(paris),
+		TimestampLayouts("2006-01-02 15:04:05.999", "2006-01-02", TimestampLayoutDefault),
+	)
+	civilCodec := NewTimestampWithOptions(TimestampLocation(paris), TimestampCivil())
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			encodeTests := []struct {
+				name     string
+				codec    Codec
+				source   interface{}
+				expected []byte
+				err      string
+			}{
+				{"first layout", codec, "2021-10-12 01:00:00.999", timestampPosBytes, ""},
+				{"second layout", codec, "2021-10-12", encodeUint64(uint64(1633989600000)), ""},
+				{"third layout with zone", codec, "2021-10-11T23:00:00.999+00:00", timestampPosBytes, ""},
+				{"no layout matches", codec, "not a timestamp", nil, fmt.Sprintf("cannot encode string as CQL timestamp with %v: cannot convert from string to int64: parsing time \"not a timestamp\" as \"2006-01-02 15:04:05.999\": cannot parse \"not a timestamp\" as \"2006\"", version)},
+				{"civil", codec, civil, timestampPosBytes, ""},
+				{"civil pointer", codec, &civil, timestampPosBytes, ""},
+				{"civil nil pointer", codec, (*CivilDateTime)(nil), nil, ""},
+				{"civil default codec", Timestamp, CivilDateTimeOf(timestampPosUTC), timestampPosBytes, ""},
+			}
+			for _, tt := range encodeTests {
+				t.Run(tt.name, func(t *testing.T) {
+					actual, err := tt.codec.Encode(tt.source, version)
+					assert.Equal(t, tt.expected, actual)
+					assertErrorMessage(t, tt.err, err)
+				})
+			}
+			decodeTests := []struct {
+				name     string
+				codec    Codec
+				source   []byte
+				dest     interface{}
+				expected interface{}
+				wasNull  bool
+			}{
+				{"string first layout", codec, timestampPosBytes, new(string), stringPtr("2021-10-12 01:00:00.999"), false},
+				{"time in location", codec, timestampPosBytes, new(time.Time), timePtr(timestampPosUTC.In(paris)), false},
+				{"interface", codec, timestampPosBytes, new(interface{}), interfacePtr(timestampPosUTC.In(paris)), false},
+				{"civil", codec, timestampPosBytes, new(CivilDateTime), &civil, false},
+				{"civil null", codec, nil, new(CivilDateTime), new(CivilDateTime), true},
+				{"civil interface", civilCodec, timestampPosBytes, new(interface{}), interfacePtr(civil), false},
+				{"civil interface null", civilCodec, nil, new(interface{}), new(interface{}), true},
+			}
+			for _, tt := range decodeTests {
+				t.Run(tt.name, func(t *testing.T) {
+					wasNull, err := tt.codec.Decode(tt.source, tt.dest, version)
+					assert.NoError(t, err)
+					assert.Equal(t, tt.expected, tt.dest)
+					assert.Equal(t, tt.wasNull, wasNull)
+				})
+			}
+		})
+	}
+}
+
+func TestCivilDateTime(t *testing.T) {
+	civil := CivilDateTimeOf(timestampPosUTC.In(paris))
+	assert.Equal(t, CivilDateTime{2021, time.October, 12, 1, 0, 0, 999_000_000}, civil)
+	assert.Equal(t, "2021-10-12T01:00:00.999", civil.String())
+	assert.True(t, timestampPosUTC.Equal(civil.In(paris)))
+	assert.Equal(t, time.Date(2021, time.October, 12, 1, 0, 0, 999_000_000, time.UTC), civil.In(time.UTC))
+}
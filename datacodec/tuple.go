@@ -23,13 +23,31 @@ import (
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
 
-func NewTuple(tupleType *datatype.Tuple) (Codec, error) {
+// NewTuple creates a codec for the given tuple type. Element codecs are resolved with DefaultCodecRegistry, unless
+// overridden with OverrideElement.
+func NewTuple(tupleType *datatype.Tuple, overrides ...CodecOverride) (Codec, error) {
+	return newTuple(tupleType, DefaultCodecRegistry, overrides...)
+}
+
+func newTuple(tupleType *datatype.Tuple, registry *CodecRegistry, overrides ...CodecOverride) (Codec, error) {
 	if tupleType == nil {
 		return nil, ErrNilDataType
 	}
 	elementCodecs := make([]Codec, len(tupleType.FieldTypes))
+	for _, override := range overrides {
+		if override.kind != overrideKindElement {
+			return nil, errWrongOverride(override, tupleType)
+		} else if override.index < 0 || override.index >= len(tupleType.FieldTypes) {
+			return nil, fmt.Errorf("invalid %v: index out of range for %v", override, tupleType)
+		} else if err := override.check(tupleType.FieldTypes[override.index]); err != nil {
+			return nil, err
+		}
+		elementCodecs[override.index] = override.codec
+	}
 	for i, elementType := range tupleType.FieldTypes {
-		if elementCodec, err := NewCodec(elementType); err != nil {
+		if elementCodecs[i] != nil {
+			continue
+		} else if elementCodec, err := registry.NewCodec(elementType); err != nil {
 			return nil, fmt.Errorf("cannot create codec for tuple element %d: %w", i, err)
 		} else {
 			elementCodecs[i] = elementCodec
@@ -48,6 +66,10 @@ func (c *tupleCodec) DataType() datatype.DataType {
 }
 
 func (c *tupleCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return nil, errCannotEncode(source, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	var ext extractor
 	if ext, err = c.createExtractor(source); err == nil && ext != nil {
 		dest, err = writeTuple(ext, c.elementCodecs, version)
@@ -59,6 +81,12 @@ func (c *tupleCodec) Encode(source interface{}, version primitive.ProtocolVersio
 }
 
 func (c *tupleCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return false, errCannotDecode(dest, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	wasNull = len(source) == 0
 	var inj injector
 	if inj, err = c.createInjector(dest, wasNull); err == nil && inj != nil {
@@ -120,7 +148,8 @@ func (c *tupleCodec) createInjector(dest interface{}, wasNull bool) (inj injecto
 }
 
 func writeTuple(ext extractor, elementCodecs []Codec, version primitive.ProtocolVersion) ([]byte, error) {
-	buf := &bytes.Buffer{}
+	buf := primitive.AcquireWriteBuffer()
+	defer primitive.ReleaseWriteBuffer(buf)
 	for i, elementCodec := range elementCodecs {
 		if value, err := ext.getElem(i, i); err != nil {
 			return nil, errCannotExtractElement(i, err)
@@ -130,7 +159,7 @@ func writeTuple(ext extractor, elementCodecs []Codec, version primitive.Protocol
 			_ = primitive.WriteBytes(encodedElement, buf)
 		}
 	}
-	return buf.Bytes(), nil
+	return buf.CopyBytes(), nil
 }
 
 func readTuple(source []byte, inj injector, elementCodecs []Codec, version primitive.ProtocolVersion) error {
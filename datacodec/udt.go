@@ -23,13 +23,33 @@ import (
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
 
-func NewUserDefined(dataType *datatype.UserDefined) (Codec, error) {
+// NewUserDefined creates a codec for the given user-defined type. Field codecs are resolved with DefaultCodecRegistry,
+// unless overridden with OverrideField.
+func NewUserDefined(dataType *datatype.UserDefined, overrides ...CodecOverride) (Codec, error) {
+	return newUserDefined(dataType, DefaultCodecRegistry, overrides...)
+}
+
+func newUserDefined(dataType *datatype.UserDefined, registry *CodecRegistry, overrides ...CodecOverride) (Codec, error) {
 	if dataType == nil {
 		return nil, ErrNilDataType
 	}
 	fieldCodecs := make([]Codec, len(dataType.FieldTypes))
+	for _, override := range overrides {
+		if override.kind != overrideKindField {
+			return nil, errWrongOverride(override, dataType)
+		}
+		i := indexOfField(dataType.FieldNames, override.field)
+		if i < 0 {
+			return nil, fmt.Errorf("invalid %v: no such field in %v", override, dataType)
+		} else if err := override.check(dataType.FieldTypes[i]); err != nil {
+			return nil, err
+		}
+		fieldCodecs[i] = override.codec
+	}
 	for i, fieldType := range dataType.FieldTypes {
-		if fieldCodec, err := NewCodec(fieldType); err != nil {
+		if fieldCodecs[i] != nil {
+			continue
+		} else if fieldCodec, err := registry.NewCodec(fieldType); err != nil {
 			return nil, fmt.Errorf("cannot create codec for user-defined type field %d (%s): %w", i, dataType.FieldNames[i], err)
 		} else {
 			fieldCodecs[i] = fieldCodec
@@ -38,6 +58,15 @@ func NewUserDefined(dataType *datatype.UserDefined) (Codec, error) {
 	return &udtCodec{dataType, fieldCodecs}, nil
 }
 
+func indexOfField(fieldNames []string, name string) int {
+	for i, fieldName := range fieldNames {
+		if fieldName == name {
+			return i
+		}
+	}
+	return -1
+}
+
 type udtCodec struct {
 	dataType    *datatype.UserDefined
 	fieldCodecs []Codec
@@ -48,6 +77,10 @@ func (c *udtCodec) DataType() datatype.DataType {
 }
 
 func (c *udtCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return nil, errCannotEncode(source, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	var ext extractor
 	if ext, err = c.createExtractor(source); err == nil && ext != nil {
 		dest, err = writeUdt(ext, c.dataType.FieldNames, c.fieldCodecs, version)
@@ -59,6 +92,12 @@ func (c *udtCodec) Encode(source interface{}, version primitive.ProtocolVersion)
 }
 
 func (c *udtCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
+	if !version.SupportsDataType(c.DataType().Code()) {
+		return false, errCannotDecode(dest, c.DataType(), version, ErrDataTypeNotSupported)
+	}
 	wasNull = len(source) == 0
 	var inj injector
 	if inj, err = c.createInjector(dest, wasNull); err == nil && inj != nil {
@@ -139,7 +178,8 @@ func (c *udtCodec) createInjector(dest interface{}, wasNull bool) (inj injector,
 }
 
 func writeUdt(ext extractor, fieldNames []string, fieldCodecs []Codec, version primitive.ProtocolVersion) ([]byte, error) {
-	buf := &bytes.Buffer{}
+	buf := primitive.AcquireWriteBuffer()
+	defer primitive.ReleaseWriteBuffer(buf)
 	for i, fieldCodec := range fieldCodecs {
 		name := fieldNames[i]
 		if value, err := ext.getElem(i, name); err != nil {
@@ -150,7 +190,7 @@ func writeUdt(ext extractor, fieldNames []string, fieldCodecs []Codec, version p
 			_ = primitive.WriteBytes(encodedField, buf)
 		}
 	}
-	return buf.Bytes(), nil
+	return buf.CopyBytes(), nil
 }
 
 func readUdt(source []byte, inj injector, fieldNames []string, fieldCodecs []Codec, version primitive.ProtocolVersion) error {
@@ -42,6 +42,7 @@ func (c *uuidCodec) DataType() datatype.DataType {
 }
 
 func (c *uuidCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
 	if dest, err = convertToUuidBytes(source); err != nil {
 		err = errCannotEncode(source, c.DataType(), version, err)
 	}
@@ -49,6 +50,9 @@ func (c *uuidCodec) Encode(source interface{}, version primitive.ProtocolVersion
 }
 
 func (c *uuidCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
 	var val []byte
 	if val, wasNull, err = readUuid(source); err == nil {
 		err = convertFromUuidBytes(val, wasNull, dest)
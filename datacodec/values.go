@@ -0,0 +1,134 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// UnsetValue is the type of Unset.
+type UnsetValue struct{}
+
+// Unset is the sentinel value representing an unset bound value in the Go values passed to EncodeValues,
+// EncodeNamedValues and EncodeBoundValues; unset values require protocol version 4 or higher.
+var Unset = UnsetValue{}
+
+func (UnsetValue) String() string {
+	return "UNSET"
+}
+
+// EncodeValues encodes the given Go values into positional values for the QueryOptions of QUERY and EXECUTE messages,
+// using the codecs returned by NewCodec for the given data types, one per value. Nil values and Null are encoded as
+// NULL, and Unset as an unset value.
+func EncodeValues(values []interface{}, types []datatype.DataType, version primitive.ProtocolVersion) ([]*primitive.Value, error) {
+	return DefaultCodecRegistry.EncodeValues(values, types, version)
+}
+
+// EncodeNamedValues encodes the given Go values into named values for the QueryOptions of QUERY and EXECUTE messages,
+// using the codecs returned by NewCodec for the data types of the same names. Nil values and Null are encoded as
+// NULL, and Unset as an unset value. It returns an error if a value has no data type.
+func EncodeNamedValues(values map[string]interface{}, types map[string]datatype.DataType, version primitive.ProtocolVersion) (map[string]*primitive.Value, error) {
+	return DefaultCodecRegistry.EncodeNamedValues(values, types, version)
+}
+
+// EncodeBoundValues encodes the given Go values, one per bound variable of the given prepared statement in order,
+// into positional values for the QueryOptions of an EXECUTE message, using the codecs returned by NewColumnCodec for
+// the variables. Nil values and Null are encoded as NULL, and Unset as an unset value.
+func EncodeBoundValues(prepared *message.PreparedResult, values []interface{}, version primitive.ProtocolVersion) ([]*primitive.Value, error) {
+	return DefaultCodecRegistry.EncodeBoundValues(prepared, values, version)
+}
+
+// EncodeValues is like the function of the same name, but uses this registry to create codecs.
+func (r *CodecRegistry) EncodeValues(values []interface{}, types []datatype.DataType, version primitive.ProtocolVersion) ([]*primitive.Value, error) {
+	if len(values) != len(types) {
+		return nil, fmt.Errorf("cannot encode values: expected %d values, got: %d", len(types), len(values))
+	}
+	encoded := make([]*primitive.Value, len(values))
+	for i, value := range values {
+		codec, err := r.NewCodec(types[i])
+		if err == nil {
+			encoded[i], err = encodeValue(codec, value, version)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode value %d: %w", i, err)
+		}
+	}
+	return encoded, nil
+}
+
+// EncodeNamedValues is like the function of the same name, but uses this registry to create codecs.
+func (r *CodecRegistry) EncodeNamedValues(values map[string]interface{}, types map[string]datatype.DataType, version primitive.ProtocolVersion) (map[string]*primitive.Value, error) {
+	encoded := make(map[string]*primitive.Value, len(values))
+	for name, value := range values {
+		dt, found := types[name]
+		if !found {
+			return nil, fmt.Errorf("cannot encode value %s: unknown data type", name)
+		}
+		codec, err := r.NewCodec(dt)
+		if err == nil {
+			encoded[name], err = encodeValue(codec, value, version)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode value %s: %w", name, err)
+		}
+	}
+	return encoded, nil
+}
+
+// EncodeBoundValues is like the function of the same name, but uses this registry to create codecs.
+func (r *CodecRegistry) EncodeBoundValues(prepared *message.PreparedResult, values []interface{}, version primitive.ProtocolVersion) ([]*primitive.Value, error) {
+	if prepared == nil || prepared.VariablesMetadata == nil {
+		return nil, errors.New("cannot encode bound values: variables metadata is nil")
+	}
+	columns := prepared.VariablesMetadata.Columns
+	if len(values) != len(columns) {
+		return nil, fmt.Errorf("cannot encode bound values: expected %d values, got: %d", len(columns), len(values))
+	}
+	encoded := make([]*primitive.Value, len(values))
+	for i, column := range columns {
+		codec, err := r.NewColumnCodec(column.Keyspace, column.Table, column.Name, column.Type)
+		if err == nil {
+			encoded[i], err = encodeValue(codec, values[i], version)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode bound value %d (%s): %w", i, column.Name, err)
+		}
+	}
+	return encoded, nil
+}
+
+func encodeValue(codec Codec, value interface{}, version primitive.ProtocolVersion) (*primitive.Value, error) {
+	switch value.(type) {
+	case NullValue:
+		return primitive.NewNullValue(), nil
+	case UnsetValue:
+		if !version.SupportsUnsetValues() {
+			return nil, fmt.Errorf("cannot use unset value with %v", version)
+		}
+		return primitive.NewUnsetValue(), nil
+	}
+	if encoded, err := codec.Encode(value, version); err != nil {
+		return nil, err
+	} else if encoded == nil {
+		return primitive.NewNullValue(), nil
+	} else {
+		return primitive.NewValue(encoded), nil
+	}
+}
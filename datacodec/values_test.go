@@ -0,0 +1,89 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestEncodeValues(t *testing.T) {
+	values, err := EncodeValues(
+		[]interface{}{int32(1), "abc", nil, Null, Unset, []int{1}},
+		[]datatype.DataType{datatype.Int, datatype.Varchar, datatype.Int, datatype.Int, datatype.Int, datatype.NewList(datatype.Int)},
+		primitive.ProtocolVersion4,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []*primitive.Value{
+		primitive.NewValue([]byte{0, 0, 0, 1}),
+		primitive.NewValue([]byte{'a', 'b', 'c'}),
+		primitive.NewNullValue(),
+		primitive.NewNullValue(),
+		primitive.NewUnsetValue(),
+		primitive.NewValue([]byte{0, 0, 0, 1, 0, 0, 0, 4, 0, 0, 0, 1}),
+	}, values)
+	_, err = EncodeValues([]interface{}{1}, []datatype.DataType{datatype.Int, datatype.Int}, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "cannot encode values: expected 2 values, got: 1")
+	_, err = EncodeValues([]interface{}{Unset}, []datatype.DataType{datatype.Int}, primitive.ProtocolVersion3)
+	assert.EqualError(t, err, "cannot encode value 0: cannot use unset value with ProtocolVersion OSS 3")
+	_, err = EncodeValues([]interface{}{true}, []datatype.DataType{datatype.Int}, primitive.ProtocolVersion4)
+	assert.ErrorIs(t, err, ErrConversionNotSupported)
+}
+
+func TestEncodeNamedValues(t *testing.T) {
+	values, err := EncodeNamedValues(
+		map[string]interface{}{"k": int64(1), "v": nil},
+		map[string]datatype.DataType{"k": datatype.Bigint, "v": datatype.Varchar, "unused": datatype.Int},
+		primitive.ProtocolVersion4,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*primitive.Value{
+		"k": primitive.NewValue([]byte{0, 0, 0, 0, 0, 0, 0, 1}),
+		"v": primitive.NewNullValue(),
+	}, values)
+	_, err = EncodeNamedValues(map[string]interface{}{"x": 1}, map[string]datatype.DataType{}, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "cannot encode value x: unknown data type")
+}
+
+func TestEncodeBoundValues(t *testing.T) {
+	// INSERT INTO ks1.table1 (pk, v) VALUES (?, ?)
+	prepared := &message.PreparedResult{
+		VariablesMetadata: &message.VariablesMetadata{
+			Columns: []*message.ColumnMetadata{
+				{Keyspace: "ks1", Table: "table1", Name: "pk", Index: 0, Type: datatype.Int},
+				{Keyspace: "ks1", Table: "table1", Name: "v", Index: 1, Type: datatype.Varchar},
+			},
+		},
+	}
+	values, err := EncodeBoundValues(prepared, []interface{}{1, "abc"}, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, []*primitive.Value{
+		primitive.NewValue([]byte{0, 0, 0, 1}),
+		primitive.NewValue([]byte{'a', 'b', 'c'}),
+	}, values)
+	_, err = EncodeBoundValues(prepared, []interface{}{1}, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "cannot encode bound values: expected 2 values, got: 1")
+	_, err = EncodeBoundValues(prepared, []interface{}{true, "abc"}, primitive.ProtocolVersion4)
+	assert.ErrorIs(t, err, ErrConversionNotSupported)
+	assert.Contains(t, err.Error(), "cannot encode bound value 0 (pk)")
+	_, err = EncodeBoundValues(&message.PreparedResult{}, nil, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "cannot encode bound values: variables metadata is nil")
+}
@@ -15,22 +15,30 @@
 package datacodec
 
 import (
+	"unicode/utf8"
+
 	"github.com/datastax/go-cassandra-native-protocol/datatype"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
 
 // Varchar is a codec for the CQL varchar (or text) type. Its preferred Go type is string, but it can encode
 // from and decode to []byte and []rune as well.
-var Varchar Codec = &stringCodec{datatype.Varchar}
+var Varchar Codec = &stringCodec{dataType: datatype.Varchar}
+
+// StrictVarchar is a codec for the CQL varchar (or text) type that behaves like Varchar, but also validates that all
+// encoded and decoded strings are valid UTF-8. When an invalid sequence is found, the returned error contains the byte
+// offset of the first invalid sequence.
+var StrictVarchar Codec = &stringCodec{dataType: datatype.Varchar, validateUtf8: true}
 
 // Ascii is a codec for the CQL ascii type. Its preferred Go type is string, but it can encode from
 // and decode to []byte and []rune as well.
 // The returned codec does not actually enforce that all strings are valid ASCII; it's the caller's responsibility to
 // ensure that they are valid.
-var Ascii Codec = &stringCodec{datatype.Ascii}
+var Ascii Codec = &stringCodec{dataType: datatype.Ascii}
 
 type stringCodec struct {
-	dataType datatype.DataType
+	dataType     datatype.DataType
+	validateUtf8 bool
 }
 
 func (c *stringCodec) DataType() datatype.DataType {
@@ -38,14 +46,28 @@ func (c *stringCodec) DataType() datatype.DataType {
 }
 
 func (c *stringCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
-	if dest, err = convertToStringBytes(source); err != nil {
+	source = unwrapNullable(source)
+	if dest, err = convertToStringBytes(source); err == nil && c.validateUtf8 {
+		err = checkUtf8(dest)
+	}
+	if err != nil {
+		dest = nil
 		err = errCannotEncode(source, c.DataType(), version, err)
 	}
 	return
 }
 
 func (c *stringCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
-	if wasNull, err = convertFromStringBytes(source, dest); err != nil {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
+	if c.validateUtf8 {
+		err = checkUtf8(source)
+	}
+	if err == nil {
+		wasNull, err = convertFromStringBytes(source, dest)
+	}
+	if err != nil {
 		err = errCannotDecode(dest, c.DataType(), version, err)
 	}
 	return
@@ -124,3 +146,14 @@ func convertFromStringBytes(val []byte, dest interface{}) (wasNull bool, err err
 	}
 	return
 }
+
+func checkUtf8(val []byte) error {
+	for offset := 0; offset < len(val); {
+		r, size := utf8.DecodeRune(val[offset:])
+		if r == utf8.RuneError && size <= 1 {
+			return errInvalidUtf8(offset)
+		}
+		offset += size
+	}
+	return nil
+}
@@ -33,11 +33,12 @@ var (
 
 func Test_stringCodec_DataType(t *testing.T) {
 	assert.Equal(t, datatype.Varchar, Varchar.DataType())
+	assert.Equal(t, datatype.Varchar, StrictVarchar.DataType())
 	assert.Equal(t, datatype.Ascii, Ascii.DataType())
 }
 
 func Test_stringCodec_Encode(t *testing.T) {
-	codecs := []Codec{Varchar, Ascii}
+	codecs := []Codec{Varchar, StrictVarchar, Ascii}
 	for _, codec := range codecs {
 		t.Run(codec.DataType().AsCql(), func(t *testing.T) {
 			for _, version := range primitive.SupportedProtocolVersions() {
@@ -68,7 +69,7 @@ func Test_stringCodec_Encode(t *testing.T) {
 }
 
 func Test_stringCodec_Decode(t *testing.T) {
-	codecs := []Codec{Varchar, Ascii}
+	codecs := []Codec{Varchar, StrictVarchar, Ascii}
 	for _, codec := range codecs {
 		t.Run(codec.DataType().AsCql(), func(t *testing.T) {
 			for _, version := range primitive.SupportedProtocolVersions() {
@@ -82,6 +83,9 @@ func Test_stringCodec_Decode(t *testing.T) {
 						err      string
 					}{
 						{"null", nil, new(string), new(string), true, ""},
+						{"empty", []byte{}, new(string), new(string), false, ""},
+						{"empty interface", []byte{}, new(interface{}), interfacePtr(""), false, ""},
+						{"empty bytes", []byte{}, new([]byte), &[]byte{}, false, ""},
 						{"non null", abcBytes, new(string), stringPtr("abc"), false, ""},
 						{"non null interface", abcBytes, new(interface{}), interfacePtr("abc"), false, ""},
 						{"conversion failed", abcBytes, new(float64), new(float64), false, fmt.Sprintf("cannot decode CQL %v as *float64 with %v: cannot convert from []uint8 to *float64: conversion not supported", codec.DataType(), version)},
@@ -100,6 +104,60 @@ func Test_stringCodec_Decode(t *testing.T) {
 	}
 }
 
+func Test_stringCodec_Utf8Validation(t *testing.T) {
+	invalidBytes := []byte{'a', 'b', 0xE2, 0x28, 0xA1}
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			t.Run("encode", func(t *testing.T) {
+				tests := []struct {
+					name     string
+					codec    Codec
+					source   interface{}
+					expected []byte
+					err      string
+				}{
+					{"lenient valid", Varchar, greekBytes, greekBytes, ""},
+					{"lenient invalid", Varchar, invalidBytes, invalidBytes, ""},
+					{"strict valid", StrictVarchar, greekBytes, greekBytes, ""},
+					{"strict valid string", StrictVarchar, "Μιλάτε αγγλικά;", greekBytes, ""},
+					{"strict invalid", StrictVarchar, invalidBytes, nil, fmt.Sprintf("cannot encode []uint8 as CQL varchar with %v: invalid UTF-8 sequence at byte offset 2", version)},
+					{"strict invalid string", StrictVarchar, "abc\xff", nil, fmt.Sprintf("cannot encode string as CQL varchar with %v: invalid UTF-8 sequence at byte offset 3", version)},
+				}
+				for _, tt := range tests {
+					t.Run(tt.name, func(t *testing.T) {
+						actual, err := tt.codec.Encode(tt.source, version)
+						assert.Equal(t, tt.expected, actual)
+						assertErrorMessage(t, tt.err, err)
+					})
+				}
+			})
+			t.Run("decode", func(t *testing.T) {
+				tests := []struct {
+					name     string
+					codec    Codec
+					source   []byte
+					expected interface{}
+					err      string
+				}{
+					{"lenient valid", Varchar, greekBytes, stringPtr("Μιλάτε αγγλικά;"), ""},
+					{"lenient invalid", Varchar, invalidBytes, stringPtr(string(invalidBytes)), ""},
+					{"strict valid", StrictVarchar, greekBytes, stringPtr("Μιλάτε αγγλικά;"), ""},
+					{"strict invalid", StrictVarchar, invalidBytes, new(string), fmt.Sprintf("cannot decode CQL varchar as *string with %v: invalid UTF-8 sequence at byte offset 2", version)},
+				}
+				for _, tt := range tests {
+					t.Run(tt.name, func(t *testing.T) {
+						dest := new(string)
+						wasNull, err := tt.codec.Decode(tt.source, dest, version)
+						assert.Equal(t, tt.expected, dest)
+						assert.False(t, wasNull)
+						assertErrorMessage(t, tt.err, err)
+					})
+				}
+			})
+		})
+	}
+}
+
 func Test_convertToStringBytes(t *testing.T) {
 	tests := []struct {
 		name     string
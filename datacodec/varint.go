@@ -15,7 +15,9 @@
 package datacodec
 
 import (
+	"math"
 	"math/big"
+	"math/bits"
 	"strconv"
 
 	"github.com/datastax/go-cassandra-native-protocol/datatype"
@@ -33,9 +35,13 @@ func (c *varintCodec) DataType() datatype.DataType {
 }
 
 func (c *varintCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	source = unwrapNullable(source)
+	if n, ok := varintInt64(source); ok {
+		return writeVarintInt64(n), nil
+	}
 	var val *big.Int
 	if val, err = convertToBigInt(source); err == nil && val != nil {
-		dest = val.Bytes()
+		dest = writeBigInt(val)
 	}
 	if err != nil {
 		err = errCannotEncode(source, c.DataType(), version, err)
@@ -44,14 +50,96 @@ func (c *varintCodec) Encode(source interface{}, version primitive.ProtocolVersi
 }
 
 func (c *varintCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	if n, ok := dest.(nullableDest); ok {
+		return decodeNullable(c, source, n, version)
+	}
+	wasNull = len(source) == 0
+	if d, ok := dest.(*big.Int); ok && d != nil {
+		// decode directly into the destination, reusing its storage
+		if wasNull {
+			*d = big.Int{}
+		} else {
+			readBigIntInto(d, source)
+		}
+		return
+	}
+	if !wasNull && len(source) <= 8 && decodeVarintInt64(readVarintInt64(source), dest) {
+		return
+	}
 	val := readBigInt(source)
-	wasNull = val == nil
 	if err = convertFromBigInt(val, wasNull, dest); err != nil {
 		err = errCannotDecode(dest, c.DataType(), version, err)
 	}
 	return
 }
 
+// varintInt64 returns the value of the given source if it is an integer that fits in an int64; such values are
+// encoded without going through a big.Int.
+func varintInt64(source interface{}) (int64, bool) {
+	switch s := source.(type) {
+	case int64:
+		return s, true
+	case int:
+		return int64(s), true
+	case int32:
+		return int64(s), true
+	case int16:
+		return int64(s), true
+	case int8:
+		return int64(s), true
+	case uint64:
+		return int64(s), s <= math.MaxInt64
+	case uint:
+		return int64(s), uint64(s) <= math.MaxInt64
+	case uint32:
+		return int64(s), true
+	case uint16:
+		return int64(s), true
+	case uint8:
+		return int64(s), true
+	}
+	return 0, false
+}
+
+// decodeVarintInt64 stores the given decoded value in the given destination if it is a non-nil pointer to an integer
+// or string type that can hold the value, and returns false otherwise, leaving conversion errors to
+// convertFromBigInt.
+func decodeVarintInt64(val int64, dest interface{}) bool {
+	switch d := dest.(type) {
+	case *int64:
+		if d != nil {
+			*d = val
+			return true
+		}
+	case *int:
+		if d != nil && int64(int(val)) == val {
+			*d = int(val)
+			return true
+		}
+	case *int32:
+		if d != nil && val >= math.MinInt32 && val <= math.MaxInt32 {
+			*d = int32(val)
+			return true
+		}
+	case *int16:
+		if d != nil && val >= math.MinInt16 && val <= math.MaxInt16 {
+			*d = int16(val)
+			return true
+		}
+	case *int8:
+		if d != nil && val >= math.MinInt8 && val <= math.MaxInt8 {
+			*d = int8(val)
+			return true
+		}
+	case *string:
+		if d != nil {
+			*d = strconv.FormatInt(val, 10)
+			return true
+		}
+	}
+	return false
+}
+
 func convertToBigInt(source interface{}) (val *big.Int, err error) {
 	switch s := source.(type) {
 	case int64:
@@ -263,35 +351,96 @@ func writeBigInt(n *big.Int) []byte {
 	if n == nil {
 		return nil
 	}
-	switch n.Sign() {
-	case 1:
-		b := n.Bytes()
-		if b[0]&0x80 > 0 {
-			b = append([]byte{0}, b...)
-		}
-		return b
-	case -1:
-		length := uint(n.BitLen()/8+1) * 8
-		b := new(big.Int).Add(n, new(big.Int).Lsh(oneBigInt, length)).Bytes()
-		// When the most significant bit is on a byte
-		// boundary, we can get some extra significant
-		// bits, so strip them off when that happens.
-		if len(b) >= 2 && b[0] == 0xff && b[1]&0x80 != 0 {
-			b = b[1:]
-		}
-		return b
-	default:
-		return []byte{0}
+	dest := make([]byte, lengthOfBigInt(n))
+	putBigInt(dest, n)
+	return dest
+}
+
+// lengthOfBigInt returns the length of the minimal two's complement representation of the given integer.
+func lengthOfBigInt(n *big.Int) int {
+	if n.IsInt64() {
+		return lengthOfVarintInt64(n.Int64())
+	}
+	bitLen := n.BitLen()
+	// -2^k needs one bit less than its absolute value
+	if n.Sign() < 0 && n.TrailingZeroBits() == uint(bitLen-1) {
+		bitLen--
 	}
+	return bitLen/8 + 1
 }
 
-func readBigInt(source []byte) (val *big.Int) {
-	length := len(source)
-	if length > 0 {
-		val = new(big.Int).SetBytes(source)
-		if source[0]&0x80 > 0 {
-			val.Sub(val, new(big.Int).Lsh(oneBigInt, uint(length)*8))
+// putBigInt writes the two's complement representation of the given integer to dest, which must be at least
+// lengthOfBigInt(n) bytes long.
+func putBigInt(dest []byte, n *big.Int) {
+	if n.IsInt64() {
+		putVarintInt64(dest, n.Int64())
+		return
+	}
+	// FillBytes writes the absolute value, which is then negated in place if needed
+	n.FillBytes(dest)
+	if n.Sign() < 0 {
+		carry := true
+		for i := len(dest) - 1; i >= 0; i-- {
+			dest[i] = ^dest[i]
+			if carry {
+				dest[i]++
+				carry = dest[i] == 0
+			}
 		}
 	}
+}
+
+func writeVarintInt64(n int64) []byte {
+	dest := make([]byte, lengthOfVarintInt64(n))
+	putVarintInt64(dest, n)
+	return dest
+}
+
+func lengthOfVarintInt64(n int64) int {
+	if n < 0 {
+		n = ^n
+	}
+	return bits.Len64(uint64(n))/8 + 1
+}
+
+func putVarintInt64(dest []byte, n int64) {
+	for i := len(dest) - 1; i >= 0; i-- {
+		dest[i] = byte(n)
+		n >>= 8
+	}
+}
+
+func readBigInt(source []byte) (val *big.Int) {
+	if len(source) > 0 {
+		val = readBigIntInto(new(big.Int), source)
+	}
 	return
 }
+
+// readBigIntInto decodes the given non-empty two's complement representation into val, reusing its storage.
+func readBigIntInto(val *big.Int, source []byte) *big.Int {
+	if len(source) <= 8 {
+		return val.SetInt64(readVarintInt64(source))
+	}
+	if source[0]&0x80 == 0 {
+		return val.SetBytes(source)
+	}
+	// source holds 2^(8*len) - x for a negative value -x; complement its words in place to obtain x-1
+	words := val.SetBytes(source).Bits()
+	for i := range words {
+		words[i] = ^words[i]
+	}
+	words[len(words)-1] &= ^big.Word(0) >> uint(len(words)*bits.UintSize-len(source)*8)
+	val.SetBits(words)
+	val.Add(val, oneBigInt)
+	return val.Neg(val)
+}
+
+// readVarintInt64 decodes the given non-empty two's complement representation of at most 8 bytes.
+func readVarintInt64(source []byte) int64 {
+	n := int64(int8(source[0]))
+	for _, b := range source[1:] {
+		n = n<<8 | int64(b)
+	}
+	return n
+}
@@ -47,6 +47,10 @@ func Test_varintCodec_Encode(t *testing.T) {
 				{"nil", nil, nil, ""},
 				{"nil pointer", bigIntNilPtr(), nil, ""},
 				{"non nil", oneBigInt, []byte{1}, ""},
+				{"negative", big.NewInt(-129), []byte{0xff, 0x7f}, ""},
+				{"int64", int64(128), []byte{0x00, 0x80}, ""},
+				{"int64 negative", int64(-128), []byte{0x80}, ""},
+				{"uint64 max", uint64(math.MaxUint64), []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, ""},
 				{"conversion failed", float64(0), nil, fmt.Sprintf("cannot encode float64 as CQL varint with %v: cannot convert from float64 to *big.Int: conversion not supported", version)},
 			}
 			for _, tt := range tests {
@@ -74,6 +78,12 @@ func Test_varintCodec_Decode(t *testing.T) {
 				{"null", nil, new(big.Int), new(big.Int), true, ""},
 				{"non null", []byte{1}, new(big.Int), oneBigInt, false, ""},
 				{"non null interface", []byte{1}, new(interface{}), interfacePtr(oneBigInt), false, ""},
+				{"negative", []byte{0xff, 0x7f}, new(big.Int), big.NewInt(-129), false, ""},
+				{"huge", []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, new(big.Int), new(big.Int).SetUint64(math.MaxUint64), false, ""},
+				{"int64", []byte{0xff, 0x7f}, new(int64), int64Ptr(-129), false, ""},
+				{"int8", []byte{0x80}, new(int8), int8Ptr(-128), false, ""},
+				{"string", []byte{0x00, 0x80}, new(string), stringPtr("128"), false, ""},
+				{"int8 out of range", []byte{0x00, 0x80}, new(int8), new(int8), false, fmt.Sprintf("cannot decode CQL varint as *int8 with %v: cannot convert from *big.Int to *int8: value out of range: 128", version)},
 				{"conversion failed", []byte{1}, new(float64), new(float64), false, fmt.Sprintf("cannot decode CQL varint as *float64 with %v: cannot convert from *big.Int to *float64: conversion not supported", version)},
 			}
 			for _, tt := range tests {
@@ -244,6 +254,10 @@ func Test_writeBigInt(t *testing.T) {
 		{"255", big.NewInt(255), []byte{0x00, 0xff}},
 		{"MinInt32", big.NewInt(math.MinInt32), []byte{0x80, 0x00, 0x00, 0x00}},
 		{"MaxInt32", big.NewInt(math.MaxInt32), []byte{0x7f, 0xff, 0xff, 0xff}},
+		{"-128", big.NewInt(-128), []byte{0x80}},
+		{"-129", big.NewInt(-129), []byte{0xff, 0x7f}},
+		{"-2^64", new(big.Int).Neg(new(big.Int).Lsh(oneBigInt, 64)), []byte{0xff, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{"-2^64-1", new(big.Int).Sub(new(big.Int).Neg(new(big.Int).Lsh(oneBigInt, 64)), oneBigInt), []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
 		{"huge neg", hugeNeg, []byte{0xf2, 0xd8, 0x02, 0xb6, 0x52, 0x7f, 0x99, 0xee, 0x98, 0x23, 0x99, 0xa9, 0x56}},
 		{"huge pos", new(big.Int).SetUint64(math.MaxUint64), []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
 	}
@@ -271,13 +285,38 @@ func Test_readBigInt(t *testing.T) {
 		{"-100", []byte{0x9c}, big.NewInt(-100)},
 		{"128", []byte{0x00, 0x80}, big.NewInt(128)},
 		{"255", []byte{0x00, 0xff}, big.NewInt(255)},
+		{"MinInt64", []byte{0x80, 0, 0, 0, 0, 0, 0, 0}, big.NewInt(math.MinInt64)},
+		{"-2^64", []byte{0xff, 0, 0, 0, 0, 0, 0, 0, 0}, new(big.Int).Neg(new(big.Int).Lsh(oneBigInt, 64))},
 		{"huge neg", []byte{0xf2, 0xd8, 0x02, 0xb6, 0x52, 0x7f, 0x99, 0xee, 0x98, 0x23, 0x99, 0xa9, 0x56}, hugeNeg},
 		{"huge pos", []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, new(big.Int).SetUint64(math.MaxUint64)},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			actual := readBigInt(tt.source)
-			assert.Zero(t, tt.expected.Cmp(actual))
+			if tt.expected == nil {
+				assert.Nil(t, actual)
+			} else {
+				assert.Zero(t, tt.expected.Cmp(actual))
+			}
 		})
 	}
 }
+
+func Test_varintRoundTrip(t *testing.T) {
+	for shift := uint(0); shift <= 200; shift++ {
+		power := new(big.Int).Lsh(oneBigInt, shift)
+		for _, delta := range []int64{-1, 0, 1} {
+			for _, sign := range []int64{-1, 1} {
+				n := new(big.Int).Add(power, big.NewInt(delta))
+				n.Mul(n, big.NewInt(sign))
+				encoded := writeBigInt(n)
+				assert.LessOrEqual(t, len(encoded), len(n.Bytes())+1, n.String())
+				assert.Zero(t, n.Cmp(readBigInt(encoded)), n.String())
+				if n.IsInt64() {
+					assert.Equal(t, encoded, writeVarintInt64(n.Int64()), n.String())
+					assert.Equal(t, n.Int64(), readVarintInt64(encoded), n.String())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCodecs_ProtocolVersions(t *testing.T) {
+	userDefinedType, _ := datatype.NewUserDefined("ks1", "type1", []string{"f1"}, []datatype.DataType{datatype.Int})
+	tests := []struct {
+		dt         datatype.DataType
+		minVersion primitive.ProtocolVersion
+	}{
+		{datatype.Int, primitive.ProtocolVersion2},
+		{datatype.Varchar, primitive.ProtocolVersion2},
+		{datatype.NewTuple(datatype.Int), primitive.ProtocolVersion3},
+		{userDefinedType, primitive.ProtocolVersion3},
+		{datatype.Date, primitive.ProtocolVersion4},
+		{datatype.Time, primitive.ProtocolVersion4},
+		{datatype.Smallint, primitive.ProtocolVersion4},
+		{datatype.Tinyint, primitive.ProtocolVersion4},
+		{datatype.Duration, primitive.ProtocolVersion5},
+	}
+	for _, tt := range tests {
+		codec, err := NewCodec(tt.dt)
+		require.NoError(t, err)
+		for _, version := range primitive.SupportedProtocolVersions() {
+			t.Run(fmt.Sprintf("%v %v", tt.dt, version), func(t *testing.T) {
+				_, encodeErr := codec.Encode(nil, version)
+				var dest interface{}
+				_, decodeErr := codec.Decode(nil, &dest, version)
+				if version.IsDse() || version >= tt.minVersion {
+					assert.NoError(t, encodeErr)
+					assert.NoError(t, decodeErr)
+				} else {
+					assert.ErrorIs(t, encodeErr, ErrDataTypeNotSupported)
+					assert.ErrorIs(t, decodeErr, ErrDataTypeNotSupported)
+				}
+			})
+		}
+	}
+}
+
+func TestCodecs_ProtocolVersions_Elements(t *testing.T) {
+	codec, err := NewList(datatype.NewList(datatype.Smallint))
+	require.NoError(t, err)
+	_, err = codec.Encode([]int16{1}, primitive.ProtocolVersion3)
+	assert.ErrorIs(t, err, ErrDataTypeNotSupported)
+	assert.EqualError(t, err, "cannot encode []int16 as CQL list<smallint> with ProtocolVersion OSS 3: "+
+		"cannot encode element 0: cannot encode int16 as CQL smallint with ProtocolVersion OSS 3: "+
+		"data type not supported in this protocol version")
+	encoded, err := codec.Encode([]int16{1}, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	var dest []int16
+	_, err = codec.Decode(encoded, &dest, primitive.ProtocolVersion3)
+	assert.ErrorIs(t, err, ErrDataTypeNotSupported)
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datatype
+
+// Class names of the DSE geospatial types; these types are custom types, and their values are encoded in the
+// Well-Known Binary (WKB) format.
+const (
+	DsePointClassName      = "org.apache.cassandra.db.marshal.PointType"
+	DseLineStringClassName = "org.apache.cassandra.db.marshal.LineStringType"
+	DsePolygonClassName    = "org.apache.cassandra.db.marshal.PolygonType"
+)
+
+var (
+	DsePoint      = NewCustom(DsePointClassName)
+	DseLineString = NewCustom(DseLineStringClassName)
+	DsePolygon    = NewCustom(DsePolygonClassName)
+)
+
+// DseDateRangeClassName is the class name of the DSE date range type, see DseDateRange.
+const DseDateRangeClassName = "org.apache.cassandra.db.marshal.DateRangeType"
+
+// DseDateRange is the DSE date range type, a custom type used by DSE Search.
+var DseDateRange = NewCustom(DseDateRangeClassName)
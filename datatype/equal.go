@@ -0,0 +1,225 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datatype
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Equal returns true if the given data types are structurally equal: same type code, and recursively equal element,
+// key, value and field types. User-defined types are equal if they have the same keyspace, name, and the same fields in
+// the same order; custom types are equal if they have the same class name. Two nil data types are equal.
+func Equal(a DataType, b DataType) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	} else if a.Code() != b.Code() {
+		return false
+	}
+	switch a.Code() {
+	case primitive.DataTypeCodeCustom:
+		customA, okA := a.(*Custom)
+		customB, okB := b.(*Custom)
+		return okA && okB && customA.ClassName == customB.ClassName
+	case primitive.DataTypeCodeList:
+		listA, okA := a.(*List)
+		listB, okB := b.(*List)
+		return okA && okB && Equal(listA.ElementType, listB.ElementType)
+	case primitive.DataTypeCodeSet:
+		setA, okA := a.(*Set)
+		setB, okB := b.(*Set)
+		return okA && okB && Equal(setA.ElementType, setB.ElementType)
+	case primitive.DataTypeCodeMap:
+		mapA, okA := a.(*Map)
+		mapB, okB := b.(*Map)
+		return okA && okB && Equal(mapA.KeyType, mapB.KeyType) && Equal(mapA.ValueType, mapB.ValueType)
+	case primitive.DataTypeCodeTuple:
+		tupleA, okA := a.(*Tuple)
+		tupleB, okB := b.(*Tuple)
+		return okA && okB && equalTypes(tupleA.FieldTypes, tupleB.FieldTypes)
+	case primitive.DataTypeCodeUdt:
+		udtA, okA := a.(*UserDefined)
+		udtB, okB := b.(*UserDefined)
+		return okA && okB &&
+			udtA.Keyspace == udtB.Keyspace &&
+			udtA.Name == udtB.Name &&
+			equalNames(udtA.FieldNames, udtB.FieldNames) &&
+			equalTypes(udtA.FieldTypes, udtB.FieldTypes)
+	}
+	return true
+}
+
+func equalTypes(a []DataType, b []DataType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalNames(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash returns a hash of the given data type, consistent with Equal: equal data types have the same hash. The hash is
+// stable across processes, and can be used, together with Equal, to key maps by data type, e.g. in codec caches.
+func Hash(t DataType) uint64 {
+	h := fnv.New64a()
+	hashDataType(t, h)
+	return h.Sum64()
+}
+
+func hashDataType(t DataType, h hash.Hash64) {
+	if t == nil {
+		_, _ = h.Write([]byte{0xff, 0xff})
+		return
+	}
+	hashUint(uint64(t.Code()), h)
+	switch t := t.(type) {
+	case *Custom:
+		hashString(t.ClassName, h)
+	case *List:
+		hashDataType(t.ElementType, h)
+	case *Set:
+		hashDataType(t.ElementType, h)
+	case *Map:
+		hashDataType(t.KeyType, h)
+		hashDataType(t.ValueType, h)
+	case *Tuple:
+		hashUint(uint64(len(t.FieldTypes)), h)
+		for _, fieldType := range t.FieldTypes {
+			hashDataType(fieldType, h)
+		}
+	case *UserDefined:
+		hashString(t.Keyspace, h)
+		hashString(t.Name, h)
+		hashUint(uint64(len(t.FieldNames)), h)
+		for _, fieldName := range t.FieldNames {
+			hashString(fieldName, h)
+		}
+		hashUint(uint64(len(t.FieldTypes)), h)
+		for _, fieldType := range t.FieldTypes {
+			hashDataType(fieldType, h)
+		}
+	}
+}
+
+func hashUint(i uint64, h hash.Hash64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	_, _ = h.Write(buf[:binary.PutUvarint(buf, i)])
+}
+
+// hashString hashes the string length before its contents, so that adjacent strings cannot collide.
+func hashString(s string, h hash.Hash64) {
+	hashUint(uint64(len(s)), h)
+	_, _ = h.Write([]byte(s))
+}
+
+// IsAssignableTo returns true if any value serialized with the source data type is also a valid serialized value of
+// the target data type, that is, if values of the source type can be written to columns of the target type without
+// conversion. This mirrors the value compatibility rules that Cassandra applies when altering column types:
+//
+//   - every type is assignable to itself, and to blob;
+//   - ascii is assignable to varchar;
+//   - tinyint, smallint, int and bigint are assignable to varint;
+//   - bigint and timestamp are assignable to each other;
+//   - timeuuid is assignable to uuid;
+//   - lists, sets and maps are assignable if their element, key and value types are;
+//   - a tuple is assignable to a tuple with at least as many fields, if its field types are assignable to the first
+//     field types of the target;
+//   - a user-defined type is assignable to a user-defined type with the same keyspace and name, and at least the same
+//     fields in the same order, with assignable field types; this supports fields added with ALTER TYPE.
+func IsAssignableTo(source DataType, target DataType) bool {
+	if source == nil || target == nil {
+		return false
+	} else if Equal(source, target) || target.Code() == primitive.DataTypeCodeBlob {
+		return true
+	}
+	switch target.Code() {
+	case primitive.DataTypeCodeVarchar:
+		return source.Code() == primitive.DataTypeCodeAscii
+	case primitive.DataTypeCodeVarint:
+		switch source.Code() {
+		case primitive.DataTypeCodeTinyint,
+			primitive.DataTypeCodeSmallint,
+			primitive.DataTypeCodeInt,
+			primitive.DataTypeCodeBigint:
+			return true
+		}
+	case primitive.DataTypeCodeBigint:
+		return source.Code() == primitive.DataTypeCodeTimestamp
+	case primitive.DataTypeCodeTimestamp:
+		return source.Code() == primitive.DataTypeCodeBigint
+	case primitive.DataTypeCodeUuid:
+		return source.Code() == primitive.DataTypeCodeTimeuuid
+	case primitive.DataTypeCodeList:
+		sourceList, okSource := source.(*List)
+		targetList, okTarget := target.(*List)
+		return okSource && okTarget && IsAssignableTo(sourceList.ElementType, targetList.ElementType)
+	case primitive.DataTypeCodeSet:
+		sourceSet, okSource := source.(*Set)
+		targetSet, okTarget := target.(*Set)
+		return okSource && okTarget && IsAssignableTo(sourceSet.ElementType, targetSet.ElementType)
+	case primitive.DataTypeCodeMap:
+		sourceMap, okSource := source.(*Map)
+		targetMap, okTarget := target.(*Map)
+		return okSource && okTarget &&
+			IsAssignableTo(sourceMap.KeyType, targetMap.KeyType) &&
+			IsAssignableTo(sourceMap.ValueType, targetMap.ValueType)
+	case primitive.DataTypeCodeTuple:
+		sourceTuple, okSource := source.(*Tuple)
+		targetTuple, okTarget := target.(*Tuple)
+		return okSource && okTarget && assignableTypes(sourceTuple.FieldTypes, targetTuple.FieldTypes)
+	case primitive.DataTypeCodeUdt:
+		sourceUdt, okSource := source.(*UserDefined)
+		targetUdt, okTarget := target.(*UserDefined)
+		return okSource && okTarget &&
+			sourceUdt.Keyspace == targetUdt.Keyspace &&
+			sourceUdt.Name == targetUdt.Name &&
+			len(sourceUdt.FieldNames) <= len(targetUdt.FieldNames) &&
+			equalNames(sourceUdt.FieldNames, targetUdt.FieldNames[:len(sourceUdt.FieldNames)]) &&
+			assignableTypes(sourceUdt.FieldTypes, targetUdt.FieldTypes)
+	}
+	return false
+}
+
+// assignableTypes returns true if each source type is assignable to the target type at the same index; the target
+// may have more types than the source.
+func assignableTypes(source []DataType, target []DataType) bool {
+	if len(source) > len(target) {
+		return false
+	}
+	for i := range source {
+		if !IsAssignableTo(source[i], target[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datatype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newAddress(fieldNames []string, fieldTypes []DataType) *UserDefined {
+	return &UserDefined{Keyspace: "ks1", Name: "address", FieldNames: fieldNames, FieldTypes: fieldTypes}
+}
+
+func TestEqualAndHash(t *testing.T) {
+	address := newAddress([]string{"street", "zip"}, []DataType{Varchar, Int})
+	tests := []struct {
+		name     string
+		a        DataType
+		b        DataType
+		expected bool
+	}{
+		{"nil", nil, nil, true},
+		{"nil and non-nil", nil, Int, false},
+		{"same primitive", Int, Int, true},
+		{"primitive copy", Int, Int.DeepCopyDataType(), true},
+		{"different primitives", Int, Bigint, false},
+		{"same custom", NewCustom("foo.Bar"), NewCustom("foo.Bar"), true},
+		{"different customs", NewCustom("foo.Bar"), NewCustom("foo.Qix"), false},
+		{"same list", NewList(NewSet(Int)), NewList(NewSet(Int)), true},
+		{"different lists", NewList(NewSet(Int)), NewList(NewSet(Bigint)), false},
+		{"list and set", NewList(Int), NewSet(Int), false},
+		{"same map", NewMap(Varchar, NewList(Int)), NewMap(Varchar, NewList(Int)), true},
+		{"different map keys", NewMap(Varchar, Int), NewMap(Ascii, Int), false},
+		{"different map values", NewMap(Varchar, Int), NewMap(Varchar, Bigint), false},
+		{"same tuple", NewTuple(Int, Varchar), NewTuple(Int, Varchar), true},
+		{"different tuple lengths", NewTuple(Int, Varchar), NewTuple(Int), false},
+		{"different tuple fields", NewTuple(Int, Varchar), NewTuple(Varchar, Int), false},
+		{"same udt", address, address.DeepCopyDataType(), true},
+		{"different udt keyspaces", address, &UserDefined{Keyspace: "ks2", Name: "address", FieldNames: address.FieldNames, FieldTypes: address.FieldTypes}, false},
+		{"different udt names", address, &UserDefined{Keyspace: "ks1", Name: "addr", FieldNames: address.FieldNames, FieldTypes: address.FieldTypes}, false},
+		{"different udt field order", address, newAddress([]string{"zip", "street"}, []DataType{Int, Varchar}), false},
+		{"different udt field types", address, newAddress([]string{"street", "zip"}, []DataType{Varchar, Bigint}), false},
+		{"nested udts", NewList(address), NewList(address.DeepCopyDataType()), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Equal(tt.a, tt.b))
+			assert.Equal(t, tt.expected, Equal(tt.b, tt.a))
+			if tt.expected {
+				assert.Equal(t, Hash(tt.a), Hash(tt.b))
+			} else {
+				assert.NotEqual(t, Hash(tt.a), Hash(tt.b))
+			}
+		})
+	}
+}
+
+func TestHash_MapKey(t *testing.T) {
+	types := map[uint64]DataType{}
+	for _, dt := range []DataType{Int, NewList(Int), NewSet(Int), NewMap(Int, Int), NewTuple(Int), NewCustom("int")} {
+		types[Hash(dt)] = dt
+	}
+	assert.Len(t, types, 6)
+	assert.Same(t, Int, types[Hash(Int.DeepCopyDataType())])
+	// adjacent strings must not collide
+	assert.NotEqual(t,
+		Hash(newAddress([]string{"ab", "c"}, []DataType{Int, Int})),
+		Hash(newAddress([]string{"a", "bc"}, []DataType{Int, Int})),
+	)
+}
+
+func TestIsAssignableTo(t *testing.T) {
+	address := newAddress([]string{"street", "zip"}, []DataType{Varchar, Int})
+	tests := []struct {
+		name     string
+		source   DataType
+		target   DataType
+		expected bool
+	}{
+		{"nil", nil, Int, false},
+		{"same type", Int, Int, true},
+		{"any to blob", NewList(Int), Blob, true},
+		{"blob to varchar", Blob, Varchar, false},
+		{"ascii to varchar", Ascii, Varchar, true},
+		{"varchar to ascii", Varchar, Ascii, false},
+		{"int to varint", Int, Varint, true},
+		{"tinyint to varint", Tinyint, Varint, true},
+		{"varint to int", Varint, Int, false},
+		{"int to bigint", Int, Bigint, false},
+		{"timestamp to bigint", Timestamp, Bigint, true},
+		{"bigint to timestamp", Bigint, Timestamp, true},
+		{"timeuuid to uuid", Timeuuid, Uuid, true},
+		{"uuid to timeuuid", Uuid, Timeuuid, false},
+		{"list elements", NewList(Ascii), NewList(Varchar), true},
+		{"list to set", NewList(Int), NewSet(Int), false},
+		{"set elements", NewSet(Varchar), NewSet(Ascii), false},
+		{"map keys and values", NewMap(Ascii, Int), NewMap(Varchar, Varint), true},
+		{"map values", NewMap(Ascii, Varint), NewMap(Varchar, Int), false},
+		{"tuple with more fields", NewTuple(Int), NewTuple(Varint, Varchar), true},
+		{"tuple with less fields", NewTuple(Int, Varchar), NewTuple(Int), false},
+		{"udt with added field", address, newAddress([]string{"street", "zip", "city"}, []DataType{Varchar, Int, Varchar}), true},
+		{"udt with removed field", address, newAddress([]string{"street"}, []DataType{Varchar}), false},
+		{"udt with renamed field", address, newAddress([]string{"street", "code"}, []DataType{Varchar, Int}), false},
+		{"udt with widened field", address, newAddress([]string{"street", "zip"}, []DataType{Varchar, Varint}), true},
+		{"udt with another name", address, &UserDefined{Keyspace: "ks1", Name: "addr", FieldNames: address.FieldNames, FieldTypes: address.FieldTypes}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsAssignableTo(tt.source, tt.target))
+		})
+	}
+}
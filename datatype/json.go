@@ -0,0 +1,314 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datatype
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSON representation of data types: primitive types are represented by their name, as returned by String, e.g.
+// "int" or "text"; other types are represented by objects with a "type" key and the fields of the corresponding Go
+// struct, e.g. {"type":"list","elementType":"int"}. The representation of nested types is recursive.
+
+const (
+	jsonTypeCustom = "custom"
+	jsonTypeList   = "list"
+	jsonTypeSet    = "set"
+	jsonTypeMap    = "map"
+	jsonTypeTuple  = "tuple"
+	jsonTypeUdt    = "udt"
+)
+
+// primitiveTypesByName contains all primitive types, indexed by their CQL names, including aliases.
+var primitiveTypesByName = map[string]*PrimitiveType{
+	"ascii":     Ascii,
+	"bigint":    Bigint,
+	"blob":      Blob,
+	"boolean":   Boolean,
+	"counter":   Counter,
+	"date":      Date,
+	"decimal":   Decimal,
+	"double":    Double,
+	"duration":  Duration,
+	"float":     Float,
+	"inet":      Inet,
+	"int":       Int,
+	"smallint":  Smallint,
+	"text":      Varchar,
+	"time":      Time,
+	"timestamp": Timestamp,
+	"timeuuid":  Timeuuid,
+	"tinyint":   Tinyint,
+	"uuid":      Uuid,
+	"varchar":   Varchar,
+	"varint":    Varint,
+}
+
+type jsonDataType struct {
+	Type        string            `json:"type"`
+	ClassName   string            `json:"className,omitempty"`
+	ElementType json.RawMessage   `json:"elementType,omitempty"`
+	KeyType     json.RawMessage   `json:"keyType,omitempty"`
+	ValueType   json.RawMessage   `json:"valueType,omitempty"`
+	Keyspace    string            `json:"keyspace,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	FieldNames  []string          `json:"fieldNames,omitempty"`
+	FieldTypes  []json.RawMessage `json:"fieldTypes,omitempty"`
+}
+
+// UnmarshalDataTypeJSON decodes a data type from its JSON representation, as produced by json.Marshal. Since DataType
+// is an interface, this function must be used to decode data types of unknown kinds; data types of known kinds can
+// also be decoded with json.Unmarshal, e.g. into a *List. A JSON null decodes to a nil DataType.
+func UnmarshalDataTypeJSON(data []byte) (DataType, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+	if data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return nil, fmt.Errorf("cannot decode data type: %w", err)
+		}
+		if t, found := primitiveTypesByName[strings.ToLower(name)]; found {
+			return t, nil
+		}
+		return nil, fmt.Errorf("cannot decode data type: unknown primitive type: %v", name)
+	}
+	var decoded jsonDataType
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("cannot decode data type: %w", err)
+	}
+	var t DataType
+	var err error
+	switch decoded.Type {
+	case jsonTypeCustom:
+		t = &Custom{ClassName: decoded.ClassName}
+	case jsonTypeList:
+		list := &List{}
+		list.ElementType, err = UnmarshalDataTypeJSON(decoded.ElementType)
+		t = list
+	case jsonTypeSet:
+		set := &Set{}
+		set.ElementType, err = UnmarshalDataTypeJSON(decoded.ElementType)
+		t = set
+	case jsonTypeMap:
+		m := &Map{}
+		if m.KeyType, err = UnmarshalDataTypeJSON(decoded.KeyType); err == nil {
+			m.ValueType, err = UnmarshalDataTypeJSON(decoded.ValueType)
+		}
+		t = m
+	case jsonTypeTuple:
+		tuple := &Tuple{}
+		tuple.FieldTypes, err = unmarshalDataTypesJSON(decoded.FieldTypes)
+		t = tuple
+	case jsonTypeUdt:
+		udt := &UserDefined{Keyspace: decoded.Keyspace, Name: decoded.Name, FieldNames: decoded.FieldNames}
+		if udt.FieldTypes, err = unmarshalDataTypesJSON(decoded.FieldTypes); err == nil &&
+			len(udt.FieldNames) != len(udt.FieldTypes) {
+			err = fmt.Errorf("field names and field types length mismatch: %d != %d", len(udt.FieldNames), len(udt.FieldTypes))
+		}
+		t = udt
+	default:
+		return nil, fmt.Errorf("cannot decode data type: unknown type: %q", decoded.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %v type: %w", decoded.Type, err)
+	}
+	return t, nil
+}
+
+func unmarshalDataTypesJSON(data []json.RawMessage) ([]DataType, error) {
+	if data == nil {
+		return nil, nil
+	}
+	types := make([]DataType, len(data))
+	for i, element := range data {
+		var err error
+		if types[i], err = UnmarshalDataTypeJSON(element); err != nil {
+			return nil, fmt.Errorf("field %d: %w", i, err)
+		}
+	}
+	return types, nil
+}
+
+func marshalDataTypeJSON(t DataType) (json.RawMessage, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
+
+func marshalDataTypesJSON(types []DataType) ([]json.RawMessage, error) {
+	if types == nil {
+		return nil, nil
+	}
+	encoded := make([]json.RawMessage, len(types))
+	for i, t := range types {
+		var err error
+		if encoded[i], err = marshalDataTypeJSON(t); err != nil {
+			return nil, err
+		} else if encoded[i] == nil {
+			encoded[i] = json.RawMessage("null")
+		}
+	}
+	return encoded, nil
+}
+
+// unmarshalInto decodes the given JSON into a data type, and checks that the decoded type is of the expected kind.
+func unmarshalInto(data []byte, expected string) (DataType, error) {
+	t, err := UnmarshalDataTypeJSON(data)
+	if err != nil {
+		return nil, err
+	} else if t == nil {
+		return nil, fmt.Errorf("cannot decode %v type: null", expected)
+	}
+	return t, nil
+}
+
+func (t *PrimitiveType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t *PrimitiveType) UnmarshalJSON(data []byte) error {
+	if decoded, err := unmarshalInto(data, "primitive"); err != nil {
+		return err
+	} else if primitiveType, ok := decoded.(*PrimitiveType); !ok {
+		return fmt.Errorf("cannot decode primitive type: got %v", decoded)
+	} else {
+		t.code = primitiveType.code
+		return nil
+	}
+}
+
+func (t *Custom) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonDataType{Type: jsonTypeCustom, ClassName: t.ClassName})
+}
+
+func (t *Custom) UnmarshalJSON(data []byte) error {
+	if decoded, err := unmarshalInto(data, jsonTypeCustom); err != nil {
+		return err
+	} else if customType, ok := decoded.(*Custom); !ok {
+		return fmt.Errorf("cannot decode custom type: got %v", decoded)
+	} else {
+		*t = *customType
+		return nil
+	}
+}
+
+func (t *List) MarshalJSON() ([]byte, error) {
+	if elementType, err := marshalDataTypeJSON(t.ElementType); err != nil {
+		return nil, err
+	} else {
+		return json.Marshal(&jsonDataType{Type: jsonTypeList, ElementType: elementType})
+	}
+}
+
+func (t *List) UnmarshalJSON(data []byte) error {
+	if decoded, err := unmarshalInto(data, jsonTypeList); err != nil {
+		return err
+	} else if listType, ok := decoded.(*List); !ok {
+		return fmt.Errorf("cannot decode list type: got %v", decoded)
+	} else {
+		*t = *listType
+		return nil
+	}
+}
+
+func (t *Set) MarshalJSON() ([]byte, error) {
+	if elementType, err := marshalDataTypeJSON(t.ElementType); err != nil {
+		return nil, err
+	} else {
+		return json.Marshal(&jsonDataType{Type: jsonTypeSet, ElementType: elementType})
+	}
+}
+
+func (t *Set) UnmarshalJSON(data []byte) error {
+	if decoded, err := unmarshalInto(data, jsonTypeSet); err != nil {
+		return err
+	} else if setType, ok := decoded.(*Set); !ok {
+		return fmt.Errorf("cannot decode set type: got %v", decoded)
+	} else {
+		*t = *setType
+		return nil
+	}
+}
+
+func (t *Map) MarshalJSON() ([]byte, error) {
+	if keyType, err := marshalDataTypeJSON(t.KeyType); err != nil {
+		return nil, err
+	} else if valueType, err := marshalDataTypeJSON(t.ValueType); err != nil {
+		return nil, err
+	} else {
+		return json.Marshal(&jsonDataType{Type: jsonTypeMap, KeyType: keyType, ValueType: valueType})
+	}
+}
+
+func (t *Map) UnmarshalJSON(data []byte) error {
+	if decoded, err := unmarshalInto(data, jsonTypeMap); err != nil {
+		return err
+	} else if mapType, ok := decoded.(*Map); !ok {
+		return fmt.Errorf("cannot decode map type: got %v", decoded)
+	} else {
+		*t = *mapType
+		return nil
+	}
+}
+
+func (t *Tuple) MarshalJSON() ([]byte, error) {
+	if fieldTypes, err := marshalDataTypesJSON(t.FieldTypes); err != nil {
+		return nil, err
+	} else {
+		return json.Marshal(&jsonDataType{Type: jsonTypeTuple, FieldTypes: fieldTypes})
+	}
+}
+
+func (t *Tuple) UnmarshalJSON(data []byte) error {
+	if decoded, err := unmarshalInto(data, jsonTypeTuple); err != nil {
+		return err
+	} else if tupleType, ok := decoded.(*Tuple); !ok {
+		return fmt.Errorf("cannot decode tuple type: got %v", decoded)
+	} else {
+		*t = *tupleType
+		return nil
+	}
+}
+
+func (t *UserDefined) MarshalJSON() ([]byte, error) {
+	if fieldTypes, err := marshalDataTypesJSON(t.FieldTypes); err != nil {
+		return nil, err
+	} else {
+		return json.Marshal(&jsonDataType{
+			Type:       jsonTypeUdt,
+			Keyspace:   t.Keyspace,
+			Name:       t.Name,
+			FieldNames: t.FieldNames,
+			FieldTypes: fieldTypes,
+		})
+	}
+}
+
+func (t *UserDefined) UnmarshalJSON(data []byte) error {
+	if decoded, err := unmarshalInto(data, jsonTypeUdt); err != nil {
+		return err
+	} else if udtType, ok := decoded.(*UserDefined); !ok {
+		return fmt.Errorf("cannot decode udt type: got %v", decoded)
+	} else {
+		*t = *udtType
+		return nil
+	}
+}
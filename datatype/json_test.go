@@ -0,0 +1,136 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datatype
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	jsonTestUdt, _     = NewUserDefined("ks1", "address", []string{"street", "zip"}, []DataType{Varchar, Int})
+	jsonTestQuotedUdt  = &UserDefined{Keyspace: "Ks1", Name: "my type", FieldNames: []string{"f1"}, FieldTypes: []DataType{Int}}
+	jsonTestNestedType = NewMap(Varchar, NewList(NewTuple(Int, jsonTestUdt, NewSet(NewCustom("foo.Bar")))))
+)
+
+func TestDataType_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    DataType
+		expected string
+	}{
+		{"primitive", Varchar, "varchar"},
+		{"custom", NewCustom("foo.Bar"), "'foo.Bar'"},
+		{"list", NewList(Int), "list<int>"},
+		{"set", NewSet(Int), "set<int>"},
+		{"map", NewMap(Varchar, Int), "map<varchar,int>"},
+		{"tuple", NewTuple(Int, Varchar), "tuple<int,varchar>"},
+		{"udt", jsonTestUdt, "ks1.address"},
+		{"udt without keyspace", &UserDefined{Name: "address"}, "address"},
+		{"udt quoted", jsonTestQuotedUdt, `"Ks1"."my type"`},
+		{"nested", jsonTestNestedType, "map<varchar,list<tuple<int,ks1.address,set<'foo.Bar'>>>>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.input.(interface{ String() string }).String())
+		})
+	}
+}
+
+func TestDataType_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    DataType
+		expected string
+	}{
+		{"primitive", Int, `"int"`},
+		{"custom", NewCustom("foo.Bar"), `{"type":"custom","className":"foo.Bar"}`},
+		{"list", NewList(Int), `{"type":"list","elementType":"int"}`},
+		{"set", NewSet(Varchar), `{"type":"set","elementType":"varchar"}`},
+		{"map", NewMap(Varchar, Int), `{"type":"map","keyType":"varchar","valueType":"int"}`},
+		{"tuple", NewTuple(Int, Boolean), `{"type":"tuple","fieldTypes":["int","boolean"]}`},
+		{"udt", jsonTestUdt, `{"type":"udt","keyspace":"ks1","name":"address","fieldNames":["street","zip"],"fieldTypes":["varchar","int"]}`},
+		{
+			"nested",
+			jsonTestNestedType,
+			`{"type":"map","keyType":"varchar","valueType":{"type":"list","elementType":{"type":"tuple","fieldTypes":["int",` +
+				`{"type":"udt","keyspace":"ks1","name":"address","fieldNames":["street","zip"],"fieldTypes":["varchar","int"]},` +
+				`{"type":"set","elementType":{"type":"custom","className":"foo.Bar"}}]}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := json.Marshal(tt.input)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(encoded))
+			decoded, err := UnmarshalDataTypeJSON(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, tt.input, decoded)
+			// decoding into the concrete type
+			concrete := tt.input.DeepCopyDataType()
+			require.NoError(t, json.Unmarshal(encoded, concrete))
+			assert.Equal(t, tt.input, concrete)
+		})
+	}
+}
+
+func TestDataType_JSON_InStruct(t *testing.T) {
+	type column struct {
+		Name string `json:"name"`
+		Type *Map   `json:"type"`
+	}
+	input := column{Name: "c1", Type: NewMap(Varchar, NewList(Int))}
+	encoded, err := json.Marshal(input)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"c1","type":{"type":"map","keyType":"varchar","valueType":{"type":"list","elementType":"int"}}}`, string(encoded))
+	var decoded column
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, input, decoded)
+}
+
+func TestUnmarshalDataTypeJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected DataType
+		err      string
+	}{
+		{"null", `null`, nil, ""},
+		{"text alias", `"text"`, Varchar, ""},
+		{"case insensitive", `"BIGINT"`, Bigint, ""},
+		{"unknown primitive", `"foo"`, nil, "cannot decode data type: unknown primitive type: foo"},
+		{"unknown type", `{"type":"foo"}`, nil, `cannot decode data type: unknown type: "foo"`},
+		{"malformed", `{`, nil, "cannot decode data type: unexpected end of JSON input"},
+		{"invalid element", `{"type":"list","elementType":"foo"}`, nil, "cannot decode list type: cannot decode data type: unknown primitive type: foo"},
+		{"invalid tuple field", `{"type":"tuple","fieldTypes":["int","foo"]}`, nil, "cannot decode tuple type: field 1: cannot decode data type: unknown primitive type: foo"},
+		{"udt length mismatch", `{"type":"udt","keyspace":"ks1","name":"t1","fieldNames":["f1"],"fieldTypes":[]}`, nil, "cannot decode udt type: field names and field types length mismatch: 1 != 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := UnmarshalDataTypeJSON([]byte(tt.input))
+			assert.Equal(t, tt.expected, actual)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+	var list List
+	assert.EqualError(t, json.Unmarshal([]byte(`"int"`), &list), "cannot decode list type: got int")
+}
@@ -36,8 +36,10 @@ func (t *List) Code() primitive.DataTypeCode {
 	return primitive.DataTypeCodeList
 }
 
+// String returns the type as a CQL type string, e.g. list<varchar>. It differs from AsCql only when the element type
+// is a user-defined type; see UserDefined.String.
 func (t *List) String() string {
-	return t.AsCql()
+	return fmt.Sprintf("list<%v>", t.ElementType)
 }
 
 func (t *List) AsCql() string {
@@ -33,8 +33,10 @@ func (t *Map) Code() primitive.DataTypeCode {
 	return primitive.DataTypeCodeMap
 }
 
+// String returns the type as a CQL type string, e.g. map<varchar,int>. It differs from AsCql only when the key or
+// value type is a user-defined type; see UserDefined.String.
 func (t *Map) String() string {
-	return t.AsCql()
+	return fmt.Sprintf("map<%v,%v>", t.KeyType, t.ValueType)
 }
 
 func (t *Map) AsCql() string {
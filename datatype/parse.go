@@ -0,0 +1,253 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datatype
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UserDefinedResolver resolves a user-defined type referenced by name in a CQL type string. The keyspace is empty when
+// the reference is not qualified. Identifiers are given in their internal form, i.e. unquoted, and lowercased unless
+// they were quoted.
+type UserDefinedResolver func(keyspace string, name string) (*UserDefined, error)
+
+// Parse parses a CQL type string, such as "int", "map<text, frozen<list<int>>>" or "'com.example.MyType'", and returns
+// the corresponding DataType. Whitespace is not significant, type names are case-insensitive, and frozen<> qualifiers
+// are accepted but dropped, since the native protocol does not convey them.
+//
+// The strings returned by String and AsCql are accepted, so that Parse(t.String()) is equal to t for every DataType t,
+// except for user-defined types: a reference to a user-defined type, e.g. ks1.address, is parsed as a *UserDefined
+// with no fields; use ParseWithResolver to resolve its fields. The AsCql form of user-defined types, e.g.
+// ks1.address<street:varchar,zip:int>, is parsed with its fields.
+func Parse(s string) (DataType, error) {
+	return ParseWithResolver(s, nil)
+}
+
+// ParseWithResolver is like Parse, but invokes the given resolver for each user-defined type referenced by name, e.g.
+// to look up its fields in the schema tables. If the resolver is nil, references are parsed as user-defined types
+// with no fields.
+func ParseWithResolver(s string, resolver UserDefinedResolver) (DataType, error) {
+	p := &typeParser{input: s, resolver: resolver}
+	dt, err := p.parseType()
+	if err == nil {
+		if p.skipSpaces(); p.pos < len(p.input) {
+			err = p.errorf("unexpected trailing characters")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse data type %q: %w", s, err)
+	}
+	return dt, nil
+}
+
+type typeParser struct {
+	input    string
+	pos      int
+	resolver UserDefinedResolver
+}
+
+func (p *typeParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("at position %d: %v", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *typeParser) skipSpaces() {
+	for p.pos < len(p.input) && strings.IndexByte(" \t\r\n", p.input[p.pos]) != -1 {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space character, or zero if the end of the input was reached.
+func (p *typeParser) peek() byte {
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *typeParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("expected '%c'", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *typeParser) parseType() (DataType, error) {
+	switch p.peek() {
+	case 0:
+		return nil, p.errorf("expected data type")
+	case '\'':
+		className, err := p.parseQuoted('\'')
+		if err != nil {
+			return nil, err
+		}
+		return NewCustom(className), nil
+	}
+	start := p.pos
+	name, quoted, err := p.parseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	if !quoted {
+		if dt, found := primitiveTypesByName[name]; found {
+			return dt, nil
+		}
+		switch name {
+		case "frozen":
+			return p.parseFrozen()
+		case "list", "set", "map", "tuple":
+			return p.parseCollectionOrTuple(name, start)
+		}
+	}
+	return p.parseUserDefined(name)
+}
+
+func (p *typeParser) parseFrozen() (DataType, error) {
+	if err := p.expect('<'); err != nil {
+		return nil, err
+	}
+	dt, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expect('>'); err != nil {
+		return nil, err
+	}
+	return dt, nil
+}
+
+func (p *typeParser) parseCollectionOrTuple(name string, start int) (DataType, error) {
+	if err := p.expect('<'); err != nil {
+		return nil, err
+	}
+	var arguments []DataType
+	for {
+		argument, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, argument)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if err := p.expect('>'); err != nil {
+		return nil, err
+	}
+	switch {
+	case name == "list" && len(arguments) == 1:
+		return NewList(arguments[0]), nil
+	case name == "set" && len(arguments) == 1:
+		return NewSet(arguments[0]), nil
+	case name == "map" && len(arguments) == 2:
+		return NewMap(arguments[0], arguments[1]), nil
+	case name == "tuple":
+		return NewTuple(arguments...), nil
+	}
+	p.pos = start
+	return nil, p.errorf("wrong number of type arguments for %v: %d", name, len(arguments))
+}
+
+// parseUserDefined parses a reference to a user-defined type, possibly qualified with its keyspace, and optionally
+// followed by its fields, as returned by UserDefined.AsCql.
+func (p *typeParser) parseUserDefined(name string) (DataType, error) {
+	var keyspace string
+	if p.peek() == '.' {
+		p.pos++
+		keyspace = name
+		var err error
+		if name, _, err = p.parseIdentifier(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek() != '<' {
+		if p.resolver != nil {
+			udt, err := p.resolver(keyspace, name)
+			if err != nil {
+				return nil, fmt.Errorf("cannot resolve user-defined type %v: %w", (&UserDefined{Keyspace: keyspace, Name: name}), err)
+			}
+			return udt, nil
+		}
+		return &UserDefined{Keyspace: keyspace, Name: name}, nil
+	}
+	p.pos++
+	var fieldNames []string
+	var fieldTypes []DataType
+	for p.peek() != '>' {
+		if len(fieldNames) > 0 {
+			if err := p.expect(','); err != nil {
+				return nil, err
+			}
+		}
+		fieldName, _, err := p.parseIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(':'); err != nil {
+			return nil, err
+		}
+		fieldType, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		fieldNames = append(fieldNames, fieldName)
+		fieldTypes = append(fieldTypes, fieldType)
+	}
+	p.pos++
+	return NewUserDefined(keyspace, name, fieldNames, fieldTypes)
+}
+
+// parseIdentifier parses a CQL identifier: unquoted identifiers are case-insensitive and returned lowercased; quoted
+// identifiers are returned unquoted, as is.
+func (p *typeParser) parseIdentifier() (identifier string, quoted bool, err error) {
+	if p.peek() == '"' {
+		identifier, err = p.parseQuoted('"')
+		return identifier, true, err
+	}
+	start := p.pos
+	for p.pos < len(p.input) && isIdentifierChar(p.input[p.pos], p.pos > start) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", false, p.errorf("expected identifier")
+	}
+	return strings.ToLower(p.input[start:p.pos]), false, nil
+}
+
+func isIdentifierChar(c byte, notFirst bool) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || notFirst && (c >= '0' && c <= '9' || c == '_')
+}
+
+// parseQuoted parses a string enclosed in the given quote character; the quote character is escaped by doubling it.
+func (p *typeParser) parseQuoted(quote byte) (string, error) {
+	start := p.pos
+	buf := &strings.Builder{}
+	for p.pos++; p.pos < len(p.input); p.pos++ {
+		if c := p.input[p.pos]; c != quote {
+			buf.WriteByte(c)
+		} else if p.pos+1 < len(p.input) && p.input[p.pos+1] == quote {
+			buf.WriteByte(quote)
+			p.pos++
+		} else {
+			p.pos++
+			return buf.String(), nil
+		}
+	}
+	p.pos = start
+	return "", p.errorf("unterminated quoted string")
+}
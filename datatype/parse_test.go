@@ -0,0 +1,94 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datatype
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected DataType
+		err      string
+	}{
+		{"int", Int, ""},
+		{" TEXT ", Varchar, ""},
+		{"map<text, frozen<list<int>>>", NewMap(Varchar, NewList(Int)), ""},
+		{"frozen<tuple<int, varchar, 'foo.Bar'>>", NewTuple(Int, Varchar, NewCustom("foo.Bar")), ""},
+		{"'it''s'", NewCustom("it's"), ""},
+		{"set<ks1.address>", NewSet(&UserDefined{Keyspace: "ks1", Name: "address"}), ""},
+		{`"Ks1"."my type"`, &UserDefined{Keyspace: "Ks1", Name: "my type"}, ""},
+		{"Address", &UserDefined{Name: "address"}, ""},
+		{`"int"`, &UserDefined{Name: "int"}, ""},
+		{"ks1.address<street:varchar, zip:int>", jsonTestUdt, ""},
+		{"", nil, `cannot parse data type "": at position 0: expected data type`},
+		{"map<int>", nil, `cannot parse data type "map<int>": at position 0: wrong number of type arguments for map: 1`},
+		{"list<int", nil, `cannot parse data type "list<int": at position 8: expected '>'`},
+		{"int int", nil, `cannot parse data type "int int": at position 4: unexpected trailing characters`},
+		{"'foo", nil, `cannot parse data type "'foo": at position 0: unterminated quoted string`},
+		{"ks1.", nil, `cannot parse data type "ks1.": at position 4: expected identifier`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual, err := Parse(tt.input)
+			assert.Equal(t, tt.expected, actual)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	for _, dt := range []DataType{
+		Ascii, Bigint, Blob, Boolean, Counter, Date, Decimal, Double, Duration, Float, Inet, Int, Smallint, Time,
+		Timestamp, Timeuuid, Tinyint, Uuid, Varchar, Varint,
+		NewCustom("foo.Bar"),
+		NewList(Int),
+		NewSet(NewTuple(Int, Varchar)),
+		jsonTestNestedType,
+		jsonTestQuotedUdt,
+	} {
+		t.Run(dt.AsCql(), func(t *testing.T) {
+			fromCql, err := Parse(dt.AsCql())
+			require.NoError(t, err)
+			assert.Equal(t, dt.AsCql(), fromCql.AsCql())
+			fromString, err := Parse(dt.(interface{ String() string }).String())
+			require.NoError(t, err)
+			assert.Equal(t, dt.(interface{ String() string }).String(), fromString.(interface{ String() string }).String())
+		})
+	}
+}
+
+func TestParseWithResolver(t *testing.T) {
+	resolver := func(keyspace string, name string) (*UserDefined, error) {
+		if keyspace == "ks1" && name == "address" {
+			return jsonTestUdt, nil
+		}
+		return nil, errors.New("not found")
+	}
+	actual, err := ParseWithResolver("list<frozen<ks1.address>>", resolver)
+	require.NoError(t, err)
+	assert.Equal(t, NewList(jsonTestUdt), actual)
+	_, err = ParseWithResolver("list<ks1.phone>", resolver)
+	assert.EqualError(t, err, `cannot parse data type "list<ks1.phone>": cannot resolve user-defined type ks1.phone: not found`)
+}
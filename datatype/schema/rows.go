@@ -0,0 +1,168 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Rows are the rows of the system_schema tables describing a schema; see Build. Each row type only has fields for
+// the columns needed to build the schema, tagged with the column names.
+type Rows struct {
+	Keyspaces []*KeyspaceRow
+	Tables    []*TableRow
+	Columns   []*ColumnRow
+	Types     []*TypeRow
+}
+
+// KeyspaceRow is a row of system_schema.keyspaces.
+type KeyspaceRow struct {
+	KeyspaceName  string            `cassandra:"keyspace_name"`
+	DurableWrites bool              `cassandra:"durable_writes"`
+	Replication   map[string]string `cassandra:"replication"`
+}
+
+// TableRow is a row of system_schema.tables.
+type TableRow struct {
+	KeyspaceName string `cassandra:"keyspace_name"`
+	TableName    string `cassandra:"table_name"`
+}
+
+// ColumnRow is a row of system_schema.columns. Type is a CQL type string, e.g. "frozen<list<address>>".
+type ColumnRow struct {
+	KeyspaceName    string `cassandra:"keyspace_name"`
+	TableName       string `cassandra:"table_name"`
+	ColumnName      string `cassandra:"column_name"`
+	Kind            string `cassandra:"kind"`
+	Position        int32  `cassandra:"position"`
+	ClusteringOrder string `cassandra:"clustering_order"`
+	Type            string `cassandra:"type"`
+}
+
+// TypeRow is a row of system_schema.types. FieldTypes are CQL type strings.
+type TypeRow struct {
+	KeyspaceName string   `cassandra:"keyspace_name"`
+	TypeName     string   `cassandra:"type_name"`
+	FieldNames   []string `cassandra:"field_names"`
+	FieldTypes   []string `cassandra:"field_types"`
+}
+
+// Queries used by Load to read the system_schema tables.
+const (
+	KeyspacesQuery = "SELECT * FROM system_schema.keyspaces"
+	TablesQuery    = "SELECT * FROM system_schema.tables"
+	ColumnsQuery   = "SELECT * FROM system_schema.columns"
+	TypesQuery     = "SELECT * FROM system_schema.types"
+)
+
+// DecodeRows decodes the rows of the given results of KeyspacesQuery, TablesQuery, ColumnsQuery and TypesQuery, in
+// this order; columns without a corresponding row field are ignored.
+func DecodeRows(
+	keyspaces, tables, columns, types *message.RowsResult,
+	version primitive.ProtocolVersion,
+) (*Rows, error) {
+	rows := &Rows{}
+	for _, r := range []struct {
+		result *message.RowsResult
+		dest   interface{}
+	}{
+		{keyspaces, &rows.Keyspaces},
+		{tables, &rows.Tables},
+		{columns, &rows.Columns},
+		{types, &rows.Types},
+	} {
+		if err := decodeRows(r.result, r.dest, version); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// decodeRows decodes the given result into dest, which must be a pointer to a slice of pointers to structs whose
+// fields are tagged with column names.
+func decodeRows(result *message.RowsResult, dest interface{}, version primitive.ProtocolVersion) error {
+	slice := reflect.ValueOf(dest).Elem()
+	rowType := slice.Type().Elem().Elem()
+	if result == nil || result.Metadata == nil {
+		return fmt.Errorf("cannot decode %v rows: missing rows metadata", rowType.Name())
+	}
+	fields := make([]int, len(result.Metadata.Columns))
+	codecs := make([]datacodec.Codec, len(result.Metadata.Columns))
+	for i, column := range result.Metadata.Columns {
+		fields[i] = -1
+		for j := 0; j < rowType.NumField(); j++ {
+			if rowType.Field(j).Tag.Get("cassandra") == column.Name {
+				fields[i] = j
+				break
+			}
+		}
+		if fields[i] >= 0 {
+			var err error
+			if codecs[i], err = datacodec.NewCodec(column.Type); err != nil {
+				return fmt.Errorf("cannot decode %v rows: column %v: %w", rowType.Name(), column.Name, err)
+			}
+		}
+	}
+	for _, row := range result.Data {
+		if len(row) != len(fields) {
+			return fmt.Errorf("cannot decode %v rows: expected %d columns, got: %d", rowType.Name(), len(fields), len(row))
+		}
+		value := reflect.New(rowType)
+		for i, field := range fields {
+			if field >= 0 {
+				if _, err := codecs[i].Decode(row[i], value.Elem().Field(field).Addr().Interface(), version); err != nil {
+					return fmt.Errorf("cannot decode %v rows: column %v: %w",
+						rowType.Name(), result.Metadata.Columns[i].Name, err)
+				}
+			}
+		}
+		slice.Set(reflect.Append(slice, value))
+	}
+	return nil
+}
+
+// Load queries the system_schema tables through the given connection, which must be initialized, and builds the schema
+// of the cluster; see Build. The queries are sent with the given protocol version and consistency ONE, using managed
+// stream ids.
+func Load(ctx context.Context, conn *client.CqlClientConnection, version primitive.ProtocolVersion) (*Schema, error) {
+	results := make([]*message.RowsResult, 4)
+	for i, query := range []string{KeyspacesQuery, TablesQuery, ColumnsQuery, TypesQuery} {
+		request := frame.NewFrame(version, client.ManagedStreamId, &message.Query{
+			Query:   query,
+			Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevelOne},
+		})
+		response, err := conn.SendAndReceiveContext(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load schema: %w", err)
+		}
+		var ok bool
+		if results[i], ok = response.Body.Message.(*message.RowsResult); !ok {
+			return nil, fmt.Errorf("cannot load schema: %v: expected ROWS RESULT, got %v", query, response.Body.Message)
+		}
+	}
+	rows, err := DecodeRows(results[0], results[1], results[2], results[3], version)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load schema: %w", err)
+	}
+	return Build(rows)
+}
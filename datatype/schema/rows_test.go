@@ -0,0 +1,132 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// rowsResult encodes the given rows of the given system_schema table.
+func rowsResult(
+	t *testing.T,
+	table string,
+	types map[string]datatype.DataType,
+	rows ...map[string]interface{},
+) *message.RowsResult {
+	metadata := &message.RowsMetadata{ColumnCount: int32(len(types))}
+	for _, name := range []string{
+		"keyspace_name", "table_name", "type_name", "column_name", "clustering_order", "column_name_bytes",
+		"durable_writes", "field_names", "field_types", "kind", "position", "replication", "type", "comment",
+	} {
+		if dt, found := types[name]; found {
+			metadata.Columns = append(metadata.Columns,
+				&message.ColumnMetadata{Keyspace: "system_schema", Table: table, Name: name, Type: dt})
+		}
+	}
+	codec, err := datacodec.NewRowCodec(metadata)
+	require.NoError(t, err)
+	result := &message.RowsResult{Metadata: metadata}
+	for _, values := range rows {
+		row, err := codec.Encode(values, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		result.Data = append(result.Data, row)
+	}
+	return result
+}
+
+func TestLoad(t *testing.T) {
+	text, list := datatype.Varchar, datatype.NewList(datatype.Varchar)
+	results := map[string]*message.RowsResult{
+		KeyspacesQuery: rowsResult(t, "keyspaces", map[string]datatype.DataType{
+			"keyspace_name": text, "durable_writes": datatype.Boolean, "replication": datatype.NewMap(text, text),
+		}, map[string]interface{}{
+			"keyspace_name": "ks1", "durable_writes": true, "replication": map[string]string{"class": "SimpleStrategy"},
+		}),
+		TablesQuery: rowsResult(t, "tables", map[string]datatype.DataType{
+			"keyspace_name": text, "table_name": text, "comment": text,
+		}, map[string]interface{}{"keyspace_name": "ks1", "table_name": "t1", "comment": "ignored"}),
+		ColumnsQuery: rowsResult(t, "columns", map[string]datatype.DataType{
+			"keyspace_name": text, "table_name": text, "column_name": text, "clustering_order": text,
+			"column_name_bytes": datatype.Blob, "kind": text, "position": datatype.Int, "type": text,
+		}, map[string]interface{}{
+			"keyspace_name": "ks1", "table_name": "t1", "column_name": "pk", "clustering_order": "none",
+			"column_name_bytes": []byte("pk"), "kind": "partition_key", "position": int32(0), "type": "int",
+		}, map[string]interface{}{
+			"keyspace_name": "ks1", "table_name": "t1", "column_name": "v", "clustering_order": "none",
+			"column_name_bytes": []byte("v"), "kind": "regular", "position": int32(-1), "type": "frozen<udt1>",
+		}),
+		TypesQuery: rowsResult(t, "types", map[string]datatype.DataType{
+			"keyspace_name": text, "type_name": text, "field_names": list, "field_types": list,
+		}, map[string]interface{}{
+			"keyspace_name": "ks1", "type_name": "udt1", "field_names": []string{"f1"}, "field_types": []string{"int"},
+		}),
+	}
+	server := client.NewCqlServer("127.0.0.1:9048", nil)
+	server.RequestHandlers = []client.RequestHandler{
+		client.HandshakeHandler,
+		func(request *frame.Frame, _ *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+			if query, ok := request.Body.Message.(*message.Query); ok && results[query.Query] != nil {
+				return frame.NewFrame(request.Header.Version, request.Header.StreamId, results[query.Query])
+			}
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Invalid{ErrorMessage: "unknown table"})
+		},
+	}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clt := client.NewCqlClient("127.0.0.1:9048", nil)
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	schema, err := Load(ctx, clientConn, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	udt1, _ := datatype.NewUserDefined("ks1", "udt1", []string{"f1"}, []datatype.DataType{datatype.Int})
+	assert.Equal(t, &Schema{Keyspaces: map[string]*Keyspace{"ks1": {
+		Name:          "ks1",
+		DurableWrites: true,
+		Replication:   map[string]string{"class": "SimpleStrategy"},
+		Tables: map[string]*Table{"t1": {
+			Keyspace: "ks1",
+			Name:     "t1",
+			Columns: []*Column{
+				{"pk", ColumnKindPartitionKey, 0, "none", datatype.Int},
+				{"v", ColumnKindRegular, -1, "none", udt1},
+			},
+			PartitionKey: []*Column{{"pk", ColumnKindPartitionKey, 0, "none", datatype.Int}},
+		}},
+		UserTypes: map[string]*datatype.UserDefined{"udt1": udt1},
+	}}}, schema)
+
+	delete(results, TypesQuery)
+	_, err = Load(ctx, clientConn, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "cannot load schema: SELECT * FROM system_schema.types: expected ROWS RESULT, "+
+		"got ERROR INVALID (code=ErrorCode Invalid [0x00002200], msg=unknown table)")
+
+	cancelFn()
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
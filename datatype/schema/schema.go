@@ -0,0 +1,254 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema contains a model of the schema of a cluster, i.e. its keyspaces, tables, columns and user-defined
+// types, built from the rows of the system_schema tables, with data types resolved, so that values can be encoded and
+// decoded with the datacodec package. Use Build to build a schema from rows obtained by other means, or Load to query
+// the system_schema tables through a client connection.
+package schema
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+)
+
+// Schema is the schema of a cluster, as described by its system_schema tables.
+type Schema struct {
+	// Keyspaces are the keyspaces of the cluster, keyed by name.
+	Keyspaces map[string]*Keyspace
+}
+
+// Keyspace is the metadata of a keyspace.
+type Keyspace struct {
+	Name          string
+	DurableWrites bool
+	Replication   map[string]string
+	// Tables are the tables of the keyspace, keyed by name.
+	Tables map[string]*Table
+	// UserTypes are the user-defined types of the keyspace, keyed by name, with their field types resolved.
+	UserTypes map[string]*datatype.UserDefined
+}
+
+// Table is the metadata of a table.
+type Table struct {
+	Keyspace string
+	Name     string
+	// Columns are all the columns of the table, in the order of "SELECT *": partition key columns, then clustering
+	// columns, then the other columns sorted by name.
+	Columns []*Column
+	// PartitionKey are the partition key columns of the table, in order.
+	PartitionKey []*Column
+	// ClusteringColumns are the clustering columns of the table, in order.
+	ClusteringColumns []*Column
+}
+
+// Column returns the column of this table with the given name, or nil if no such column exists.
+func (t *Table) Column(name string) *Column {
+	for _, column := range t.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+	return nil
+}
+
+// ColumnMetadata returns the metadata of the given columns of this table, as found in the results of queries
+// selecting them, e.g. to create a datacodec.RowCodec; if no names are given, all the columns are returned, in the
+// order of Columns. It returns an error if a column does not exist.
+func (t *Table) ColumnMetadata(names ...string) ([]*message.ColumnMetadata, error) {
+	columns := t.Columns
+	if len(names) > 0 {
+		columns = make([]*Column, len(names))
+		for i, name := range names {
+			if columns[i] = t.Column(name); columns[i] == nil {
+				return nil, fmt.Errorf("unknown column in table %v.%v: %v", t.Keyspace, t.Name, name)
+			}
+		}
+	}
+	metadata := make([]*message.ColumnMetadata, len(columns))
+	for i, column := range columns {
+		metadata[i] = &message.ColumnMetadata{Keyspace: t.Keyspace, Table: t.Name, Name: column.Name, Type: column.Type}
+	}
+	return metadata, nil
+}
+
+// ColumnKind is the kind of a column, as found in the kind column of system_schema.columns.
+type ColumnKind string
+
+const (
+	ColumnKindPartitionKey = ColumnKind("partition_key")
+	ColumnKindClustering   = ColumnKind("clustering")
+	ColumnKindRegular      = ColumnKind("regular")
+	ColumnKindStatic       = ColumnKind("static")
+)
+
+// Column is the metadata of a column.
+type Column struct {
+	Name string
+	Kind ColumnKind
+	// Position is the position of the column in the partition key or among the clustering columns, starting at zero;
+	// it is -1 for other columns.
+	Position int
+	// ClusteringOrder is "asc" or "desc" for clustering columns, and "none" for other columns.
+	ClusteringOrder string
+	// Type is the data type of the column, with user-defined types resolved.
+	Type datatype.DataType
+}
+
+// Build builds a schema from the given rows of the system_schema tables. User-defined types are resolved, including
+// user-defined types nested in other user-defined types, regardless of the order of their rows. The rows of columns
+// belonging to unknown tables, e.g. the columns of materialized views, are ignored. It returns an error if a table or
+// a type belongs to an unknown keyspace, or if a type string cannot be parsed or references an unknown type.
+func Build(rows *Rows) (*Schema, error) {
+	schema := &Schema{Keyspaces: make(map[string]*Keyspace, len(rows.Keyspaces))}
+	for _, row := range rows.Keyspaces {
+		schema.Keyspaces[row.KeyspaceName] = &Keyspace{
+			Name:          row.KeyspaceName,
+			DurableWrites: row.DurableWrites,
+			Replication:   row.Replication,
+			Tables:        map[string]*Table{},
+			UserTypes:     map[string]*datatype.UserDefined{},
+		}
+	}
+	resolver := &typeResolver{schema: schema, rows: map[string]*TypeRow{}, resolving: map[string]bool{}}
+	for _, row := range rows.Types {
+		if schema.Keyspaces[row.KeyspaceName] == nil {
+			return nil, fmt.Errorf("cannot build schema: type %v.%v: unknown keyspace", row.KeyspaceName, row.TypeName)
+		}
+		resolver.rows[row.KeyspaceName+"."+row.TypeName] = row
+	}
+	for _, row := range rows.Types {
+		if _, err := resolver.resolve(row.KeyspaceName, row.TypeName); err != nil {
+			return nil, fmt.Errorf("cannot build schema: %w", err)
+		}
+	}
+	for _, row := range rows.Tables {
+		keyspace := schema.Keyspaces[row.KeyspaceName]
+		if keyspace == nil {
+			return nil, fmt.Errorf("cannot build schema: table %v.%v: unknown keyspace", row.KeyspaceName, row.TableName)
+		}
+		keyspace.Tables[row.TableName] = &Table{Keyspace: row.KeyspaceName, Name: row.TableName}
+	}
+	for _, row := range rows.Columns {
+		var table *Table
+		if keyspace := schema.Keyspaces[row.KeyspaceName]; keyspace != nil {
+			table = keyspace.Tables[row.TableName]
+		}
+		if table == nil {
+			continue
+		}
+		dt, err := datatype.ParseWithResolver(row.Type, resolver.resolverFor(row.KeyspaceName))
+		if err != nil {
+			return nil, fmt.Errorf("cannot build schema: column %v.%v.%v: %w",
+				row.KeyspaceName, row.TableName, row.ColumnName, err)
+		}
+		table.Columns = append(table.Columns, &Column{
+			Name:            row.ColumnName,
+			Kind:            ColumnKind(row.Kind),
+			Position:        int(row.Position),
+			ClusteringOrder: row.ClusteringOrder,
+			Type:            dt,
+		})
+	}
+	for _, keyspace := range schema.Keyspaces {
+		for _, table := range keyspace.Tables {
+			table.sortColumns()
+		}
+	}
+	return schema, nil
+}
+
+// sortColumns sorts the columns of this table in the order of "SELECT *", and sets its partition key and clustering
+// columns.
+func (t *Table) sortColumns() {
+	rank := func(c *Column) int {
+		switch c.Kind {
+		case ColumnKindPartitionKey:
+			return 0
+		case ColumnKindClustering:
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(t.Columns, func(i, j int) bool {
+		ci, cj := t.Columns[i], t.Columns[j]
+		if rank(ci) != rank(cj) {
+			return rank(ci) < rank(cj)
+		} else if rank(ci) < 2 {
+			return ci.Position < cj.Position
+		}
+		return ci.Name < cj.Name
+	})
+	t.PartitionKey, t.ClusteringColumns = nil, nil
+	for _, column := range t.Columns {
+		switch column.Kind {
+		case ColumnKindPartitionKey:
+			t.PartitionKey = append(t.PartitionKey, column)
+		case ColumnKindClustering:
+			t.ClusteringColumns = append(t.ClusteringColumns, column)
+		}
+	}
+}
+
+// typeResolver resolves user-defined types from the rows of system_schema.types, resolving the types they reference
+// first; resolved types are stored in the keyspaces of the schema being built.
+type typeResolver struct {
+	schema    *Schema
+	rows      map[string]*TypeRow
+	resolving map[string]bool
+}
+
+func (r *typeResolver) resolve(keyspace string, name string) (*datatype.UserDefined, error) {
+	if ks := r.schema.Keyspaces[keyspace]; ks != nil && ks.UserTypes[name] != nil {
+		return ks.UserTypes[name], nil
+	}
+	key := keyspace + "." + name
+	row := r.rows[key]
+	if row == nil {
+		return nil, fmt.Errorf("unknown type: %v", key)
+	} else if r.resolving[key] {
+		return nil, fmt.Errorf("type %v references itself", key)
+	} else if len(row.FieldNames) != len(row.FieldTypes) {
+		return nil, fmt.Errorf("type %v: field names and field types length mismatch: %d != %d",
+			key, len(row.FieldNames), len(row.FieldTypes))
+	}
+	r.resolving[key] = true
+	defer delete(r.resolving, key)
+	fieldTypes := make([]datatype.DataType, len(row.FieldTypes))
+	for i, fieldType := range row.FieldTypes {
+		var err error
+		if fieldTypes[i], err = datatype.ParseWithResolver(fieldType, r.resolverFor(keyspace)); err != nil {
+			return nil, fmt.Errorf("type %v: field %v: %w", key, row.FieldNames[i], err)
+		}
+	}
+	udt, err := datatype.NewUserDefined(keyspace, name, row.FieldNames, fieldTypes)
+	if err != nil {
+		return nil, err
+	}
+	r.schema.Keyspaces[keyspace].UserTypes[name] = udt
+	return udt, nil
+}
+
+// resolverFor returns a datatype.UserDefinedResolver resolving unqualified type names in the given keyspace.
+func (r *typeResolver) resolverFor(keyspace string) datatype.UserDefinedResolver {
+	return func(ks string, name string) (*datatype.UserDefined, error) {
+		if ks == "" {
+			ks = keyspace
+		}
+		return r.resolve(ks, name)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+)
+
+func testRows() *Rows {
+	return &Rows{
+		Keyspaces: []*KeyspaceRow{
+			{"ks1", true, map[string]string{"class": "org.apache.cassandra.locator.SimpleStrategy", "replication_factor": "1"}},
+			{"ks2", false, nil},
+		},
+		Tables: []*TableRow{{"ks1", "users"}, {"ks2", "empty"}},
+		Columns: []*ColumnRow{
+			{"ks1", "users", "name", "regular", -1, "none", "text"},
+			{"ks1", "users", "added", "clustering", 1, "desc", "timestamp"},
+			{"ks1", "users", "id", "partition_key", 0, "none", "uuid"},
+			{"ks1", "users", "addresses", "regular", -1, "none", "frozen<list<address>>"},
+			{"ks1", "users", "bucket", "partition_key", 1, "none", "int"},
+			{"ks1", "users", "country", "clustering", 0, "asc", "text"},
+			{"ks1", "users", "version", "static", -1, "none", "int"},
+			// materialized view
+			{"ks1", "users_by_name", "name", "partition_key", 0, "none", "text"},
+		},
+		// nested types first, so that they must be resolved before their rows are reached
+		Types: []*TypeRow{
+			{"ks1", "address", []string{"street", "location"}, []string{"text", "frozen<location>"}},
+			{"ks1", "location", []string{"lat", "lon", "tags"}, []string{"double", "double", "set<text>"}},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	schema, err := Build(testRows())
+	require.NoError(t, err)
+	require.Len(t, schema.Keyspaces, 2)
+
+	ks1 := schema.Keyspaces["ks1"]
+	assert.Equal(t, "ks1", ks1.Name)
+	assert.True(t, ks1.DurableWrites)
+	assert.Equal(t, "1", ks1.Replication["replication_factor"])
+	location, _ := datatype.NewUserDefined("ks1", "location", []string{"lat", "lon", "tags"},
+		[]datatype.DataType{datatype.Double, datatype.Double, datatype.NewSet(datatype.Varchar)})
+	address, _ := datatype.NewUserDefined("ks1", "address", []string{"street", "location"},
+		[]datatype.DataType{datatype.Varchar, location})
+	assert.Equal(t, map[string]*datatype.UserDefined{"address": address, "location": location}, ks1.UserTypes)
+
+	require.Len(t, ks1.Tables, 1)
+	users := ks1.Tables["users"]
+	var names []string
+	for _, column := range users.Columns {
+		names = append(names, column.Name)
+	}
+	assert.Equal(t, []string{"id", "bucket", "country", "added", "addresses", "name", "version"}, names)
+	assert.Equal(t, users.Columns[:2], users.PartitionKey)
+	assert.Equal(t, users.Columns[2:4], users.ClusteringColumns)
+	assert.Equal(t, &Column{"added", ColumnKindClustering, 1, "desc", datatype.Timestamp}, users.Column("added"))
+	assert.Equal(t, datatype.NewList(address), users.Column("addresses").Type)
+	assert.Equal(t, ColumnKindStatic, users.Column("version").Kind)
+	assert.Nil(t, users.Column("unknown"))
+
+	metadata, err := users.ColumnMetadata("name", "addresses")
+	require.NoError(t, err)
+	assert.Equal(t, []*message.ColumnMetadata{
+		{Keyspace: "ks1", Table: "users", Name: "name", Type: datatype.Varchar},
+		{Keyspace: "ks1", Table: "users", Name: "addresses", Type: datatype.NewList(address)},
+	}, metadata)
+	metadata, err = users.ColumnMetadata()
+	require.NoError(t, err)
+	assert.Len(t, metadata, 7)
+	_, err = users.ColumnMetadata("unknown")
+	assert.EqualError(t, err, "unknown column in table ks1.users: unknown")
+
+	ks2 := schema.Keyspaces["ks2"]
+	assert.False(t, ks2.DurableWrites)
+	assert.Empty(t, ks2.UserTypes)
+	assert.Empty(t, ks2.Tables["empty"].Columns)
+}
+
+func TestBuild_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(rows *Rows)
+		expected string
+	}{
+		{"table of unknown keyspace", func(rows *Rows) {
+			rows.Tables = append(rows.Tables, &TableRow{"ks3", "t"})
+		}, "cannot build schema: table ks3.t: unknown keyspace"},
+		{"type of unknown keyspace", func(rows *Rows) {
+			rows.Types = append(rows.Types, &TypeRow{"ks3", "t", nil, nil})
+		}, "cannot build schema: type ks3.t: unknown keyspace"},
+		{"unknown field type", func(rows *Rows) {
+			rows.Types[1].FieldTypes[2] = "frozen<unknown>"
+		}, `cannot build schema: type ks1.address: field location: cannot parse data type "frozen<location>": ` +
+			`cannot resolve user-defined type location: type ks1.location: field tags: cannot parse data type ` +
+			`"frozen<unknown>": cannot resolve user-defined type unknown: unknown type: ks1.unknown`},
+		{"cyclic types", func(rows *Rows) {
+			rows.Types[1].FieldTypes[2] = "address"
+		}, `cannot build schema: type ks1.address: field location: cannot parse data type "frozen<location>": ` +
+			`cannot resolve user-defined type location: type ks1.location: field tags: cannot parse data type ` +
+			`"address": cannot resolve user-defined type address: type ks1.address references itself`},
+		{"field length mismatch", func(rows *Rows) {
+			rows.Types[1].FieldNames = rows.Types[1].FieldNames[:2]
+		}, `cannot build schema: type ks1.address: field location: cannot parse data type "frozen<location>": ` +
+			`cannot resolve user-defined type location: type ks1.location: field names and field types length ` +
+			`mismatch: 2 != 3`},
+		{"invalid column type", func(rows *Rows) {
+			rows.Columns[0].Type = "list<"
+		}, `cannot build schema: column ks1.users.name: cannot parse data type "list<": ` +
+			`at position 5: expected data type`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := testRows()
+			tt.modify(rows)
+			_, err := Build(rows)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
@@ -32,8 +32,10 @@ func (t *Set) Code() primitive.DataTypeCode {
 	return primitive.DataTypeCodeSet
 }
 
+// String returns the type as a CQL type string, e.g. set<varchar>. It differs from AsCql only when the element type
+// is a user-defined type; see UserDefined.String.
 func (t *Set) String() string {
-	return t.AsCql()
+	return fmt.Sprintf("set<%v>", t.ElementType)
 }
 
 func (t *Set) AsCql() string {
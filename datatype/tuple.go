@@ -37,8 +37,19 @@ func (t *Tuple) Code() primitive.DataTypeCode {
 	return primitive.DataTypeCodeTuple
 }
 
+// String returns the type as a CQL type string, e.g. tuple<int,varchar>. It differs from AsCql only when an element
+// type is a user-defined type; see UserDefined.String.
 func (t *Tuple) String() string {
-	return t.AsCql()
+	buf := &bytes.Buffer{}
+	buf.WriteString("tuple<")
+	for i, elementType := range t.FieldTypes {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprint(buf, elementType)
+	}
+	buf.WriteString(">")
+	return buf.String()
 }
 
 func (t *Tuple) AsCql() string {
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
@@ -46,21 +47,40 @@ func (t *UserDefined) Code() primitive.DataTypeCode {
 	return primitive.DataTypeCodeUdt
 }
 
+// String returns the type as a CQL type string, as displayed by cqlsh, i.e. its qualified name, e.g. ks1.address;
+// identifiers are double-quoted when required. Use AsCql to include field names and types.
 func (t *UserDefined) String() string {
-	return t.AsCql()
+	if t.Keyspace == "" {
+		return quoteIdentifier(t.Name)
+	}
+	return quoteIdentifier(t.Keyspace) + "." + quoteIdentifier(t.Name)
+}
+
+// quoteIdentifier double-quotes the given CQL identifier, unless it only contains lowercase letters, digits and
+// underscores, and starts with a letter.
+func quoteIdentifier(identifier string) string {
+	for i, c := range identifier {
+		if !(c >= 'a' && c <= 'z' || i > 0 && (c >= '0' && c <= '9' || c == '_')) {
+			return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+		}
+	}
+	if identifier == "" {
+		return `""`
+	}
+	return identifier
 }
 
+// AsCql returns the type as a CQL type string including its field names and types, e.g.
+// ks1.address<street:varchar,zip:int>; identifiers are double-quoted when required. See Parse.
 func (t *UserDefined) AsCql() string {
 	buf := &bytes.Buffer{}
-	buf.WriteString(t.Keyspace)
-	buf.WriteString(".")
-	buf.WriteString(t.Name)
+	buf.WriteString(t.String())
 	buf.WriteString("<")
 	for i, fieldType := range t.FieldTypes {
 		if i > 0 {
 			buf.WriteString(",")
 		}
-		buf.WriteString(t.FieldNames[i])
+		buf.WriteString(quoteIdentifier(t.FieldNames[i]))
 		buf.WriteString(":")
 		buf.WriteString(fieldType.AsCql())
 	}
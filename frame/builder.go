@@ -0,0 +1,92 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Builder is a fluent builder for frames. It takes care of adjusting the header flags according to the body contents,
+// and validates the resulting frame when Build is called:
+//
+//  f, err := frame.NewBuilder(primitive.ProtocolVersion4, 1, &message.VoidResult{}).
+//      SetWarnings("warning 1").
+//      AddCustomPayload("key", []byte("value")).
+//      SetTracingId(tracingId).
+//      Build()
+type Builder struct {
+	frame *Frame
+}
+
+// NewBuilder creates a new Builder for a frame with the given version, stream id and message.
+func NewBuilder(version primitive.ProtocolVersion, streamId int16, msg message.Message) *Builder {
+	return &Builder{frame: NewFrame(version, streamId, msg)}
+}
+
+// SetWarnings sets the query warnings of the frame being built. Query warnings are only valid for response frames,
+// and only from protocol version 4 onwards.
+func (b *Builder) SetWarnings(warnings ...string) *Builder {
+	b.frame.SetWarnings(warnings)
+	return b
+}
+
+// AddCustomPayload adds the given key and value to the custom payload of the frame being built. Custom payloads are
+// only valid from protocol version 4 onwards.
+func (b *Builder) AddCustomPayload(key string, value []byte) *Builder {
+	customPayload := b.frame.Body.CustomPayload
+	if customPayload == nil {
+		customPayload = make(map[string][]byte)
+	}
+	customPayload[key] = value
+	b.frame.SetCustomPayload(customPayload)
+	return b
+}
+
+// SetCustomPayload replaces the custom payload of the frame being built. Custom payloads are only valid from protocol
+// version 4 onwards.
+func (b *Builder) SetCustomPayload(customPayload map[string][]byte) *Builder {
+	b.frame.SetCustomPayload(customPayload)
+	return b
+}
+
+// SetTracingId sets the tracing id of the frame being built. Tracing ids are only valid for response frames; use
+// RequestTracingId for request frames.
+func (b *Builder) SetTracingId(tracingId *primitive.UUID) *Builder {
+	b.frame.SetTracingId(tracingId)
+	return b
+}
+
+// RequestTracingId configures the frame being built to request a tracing id from the server. Only valid for request
+// frames.
+func (b *Builder) RequestTracingId(tracing bool) *Builder {
+	b.frame.RequestTracingId(tracing)
+	return b
+}
+
+// SetCompress configures the frame being built to use compression. See Frame.SetCompress.
+func (b *Builder) SetCompress(compress bool) *Builder {
+	b.frame.SetCompress(compress)
+	return b
+}
+
+// Build validates and returns the frame being built. See Frame.Validate for the validation rules. The builder should
+// not be reused after calling this method.
+func (b *Builder) Build() (*Frame, error) {
+	if err := b.frame.Validate(); err != nil {
+		return nil, err
+	}
+	return b.frame, nil
+}
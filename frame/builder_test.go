@@ -0,0 +1,123 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestBuilder(t *testing.T) {
+	tracingId := &primitive.UUID{0xca, 0xfe}
+	f, err := NewBuilder(primitive.ProtocolVersion4, 1, &message.VoidResult{}).
+		SetWarnings("warning 1", "warning 2").
+		AddCustomPayload("key1", []byte("value1")).
+		AddCustomPayload("key2", []byte("value2")).
+		SetTracingId(tracingId).
+		SetCompress(true).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"warning 1", "warning 2"}, f.Body.Warnings)
+	assert.Equal(t, map[string][]byte{"key1": []byte("value1"), "key2": []byte("value2")}, f.Body.CustomPayload)
+	assert.Equal(t, tracingId, f.Body.TracingId)
+	assert.True(t, f.Header.Flags.Contains(primitive.HeaderFlagWarning))
+	assert.True(t, f.Header.Flags.Contains(primitive.HeaderFlagCustomPayload))
+	assert.True(t, f.Header.Flags.Contains(primitive.HeaderFlagTracing))
+	assert.True(t, f.Header.Flags.Contains(primitive.HeaderFlagCompressed))
+	f.SetCompress(false)
+	encoded := &bytes.Buffer{}
+	codec := NewCodec()
+	require.NoError(t, codec.EncodeFrame(f, encoded))
+	decoded, err := codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, f, decoded)
+}
+
+func TestFrame_Validate(t *testing.T) {
+	tracingId := &primitive.UUID{0xca, 0xfe}
+	tests := []struct {
+		name    string
+		builder *Builder
+		err     string
+	}{
+		{
+			"valid request",
+			NewBuilder(primitive.ProtocolVersion3, 1, &message.Query{Query: "SELECT"}).RequestTracingId(true),
+			"",
+		},
+		{
+			"valid request with custom payload",
+			NewBuilder(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT"}).AddCustomPayload("k", []byte{1}),
+			"",
+		},
+		{
+			"unsupported version",
+			NewBuilder(primitive.ProtocolVersion(1), 1, &message.Query{Query: "SELECT"}),
+			"invalid frame: invalid protocol version: ProtocolVersion ? [0X01]",
+		},
+		{
+			"custom payload on v3",
+			NewBuilder(primitive.ProtocolVersion3, 1, &message.Query{Query: "SELECT"}).AddCustomPayload("k", []byte{1}),
+			"invalid frame: custom payloads are not supported in ProtocolVersion OSS 3",
+		},
+		{
+			"warnings on v3",
+			NewBuilder(primitive.ProtocolVersion3, 1, &message.VoidResult{}).SetWarnings("w"),
+			"invalid frame: warnings are not supported in ProtocolVersion OSS 3",
+		},
+		{
+			"warnings on request",
+			NewBuilder(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT"}).SetWarnings("w"),
+			"invalid frame: warnings are only valid for response frames",
+		},
+		{
+			"tracing id on request",
+			NewBuilder(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT"}).SetTracingId(tracingId),
+			"invalid frame: tracing ids are only valid for response frames",
+		},
+		{
+			"tracing flag without tracing id on response",
+			NewBuilder(primitive.ProtocolVersion4, 1, &message.VoidResult{}).RequestTracingId(true),
+			"invalid frame: tracing flag is set but tracing id is nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := tt.builder.Build()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, f)
+			} else {
+				assert.EqualError(t, err, tt.err)
+				assert.Nil(t, f)
+			}
+		})
+	}
+	t.Run("opcode mismatch", func(t *testing.T) {
+		f := NewFrame(primitive.ProtocolVersion4, 1, &message.Ready{})
+		f.Header.OpCode = primitive.OpCodeQuery
+		assert.EqualError(t, f.Validate(), "invalid frame: opcode mismatch between header and body: OpCode QUERY [0x07] != OpCode READY [0x02]")
+	})
+	t.Run("nil body", func(t *testing.T) {
+		f := &Frame{Header: &Header{Version: primitive.ProtocolVersion4}}
+		assert.EqualError(t, f.Validate(), "invalid frame: body message is nil")
+	})
+}
@@ -17,6 +17,7 @@ package frame
 import (
 	"fmt"
 	"io"
+	"net"
 
 	"github.com/datastax/go-cassandra-native-protocol/message"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
@@ -28,41 +29,82 @@ type Encoder interface {
 	EncodeFrame(frame *Frame, dest io.Writer) error
 }
 
-type RawEncoder interface {
-
-	// EncodeRawFrame encodes the given RawFrame.
-	EncodeRawFrame(frame *RawFrame, dest io.Writer) error
+// PartialEncoder encodes frame headers and bodies separately, e.g. for proxies that modify headers only.
+type PartialEncoder interface {
 
 	// EncodeHeader encodes the given frame Header. This is a partial operation; after calling this method, one must
-	// call EncodeBody to fully encode the entire frame.
+	// call EncodeBody to fully encode the entire frame. The header's BodyLength is written as is: it must be the
+	// length of the body as EncodeBody will write it, that is, after compression and after the encode hook, if any.
+	// When the body is compressed, or when its length is unknown, encode it first to a buffer, then set BodyLength to
+	// the buffer length before calling this method.
 	EncodeHeader(header *Header, dest io.Writer) error
 
 	// EncodeBody encodes the given frame Body. The body will be compressed depending on whether the Compressed flag is
-	// set in the given Header. This is a partial operation; it is illegal to call this method before calling
-	// EncodeHeader.
+	// set in the given Header; compression fails if the codec has no compressor. This is a partial operation; it is
+	// illegal to call this method before calling EncodeHeader.
 	EncodeBody(header *Header, body *Body, dest io.Writer) error
 }
 
+type RawEncoder interface {
+	PartialEncoder
+
+	// EncodeRawFrame encodes the given RawFrame.
+	EncodeRawFrame(frame *RawFrame, dest io.Writer) error
+}
+
+// VectoredEncoder is an optional interface that encodes frames into separate header and body buffers, so that they
+// can be written without being concatenated first: when the destination is a net.Conn, net.Buffers.WriteTo writes all
+// the buffers with a single vectored write (writev) where the platform supports it. This avoids copying each frame
+// body into a larger buffer, which matters for proxies forwarding many large frames. Codecs created by this package
+// implement it; since it is not part of RawCodec, check for it with a type assertion.
+type VectoredEncoder interface {
+
+	// EncodeFrameBuffers encodes the entire frame, compressing the body if needed, and returns the encoded header and
+	// the encoded body as two separate buffers.
+	EncodeFrameBuffers(frame *Frame) (net.Buffers, error)
+
+	// EncodeRawFrameBuffers encodes the header of the given RawFrame, and returns it along with the frame body, which
+	// is not copied: the returned buffers must not be used after the frame body is modified.
+	EncodeRawFrameBuffers(frame *RawFrame) (net.Buffers, error)
+}
+
 type Decoder interface {
 
 	// DecodeFrame decodes the entire frame, decompressing the body if needed.
 	DecodeFrame(source io.Reader) (*Frame, error)
 }
 
-type RawDecoder interface {
-
-	// DecodeRawFrame decodes a RawFrame from the given source.
-	DecodeRawFrame(source io.Reader) (*RawFrame, error)
+// PartialDecoder decodes frame headers and bodies separately, e.g. for proxies that only need to inspect headers,
+// and defer body decoding until needed.
+type PartialDecoder interface {
 
 	// DecodeHeader decodes a frame Header from the given source, leaving the body contents unread. This is a partial
-	// operation; after calling this method, one must either call DecodeBody, DecodeRawBody or DiscardBody to fully
-	// read or discard the body contents.
+	// operation; after calling this method, one must either call DecodeBody, DecodeBodyLazily, or, with a RawDecoder,
+	// DecodeRawBody or DiscardBody to fully read or discard the body contents.
 	DecodeHeader(source io.Reader) (*Header, error)
 
-	// DecodeBody decodes a frame Body from the given source, decompressing it if required. This is a partial
-	// operation; It is illegal to call this method before calling DecodeHeader.
+	// DecodeBody decodes a frame Body from the given source, decompressing it if the Compressed flag is set in the
+	// given Header; decompression fails if the codec has no compressor. Exactly BodyLength bytes are consumed from the
+	// source, even if the message does not use them all; how such trailing bytes and other anomalies are handled
+	// depends on the codec's DecodingMode. This is a partial operation; It is illegal to call this method before
+	// calling DecodeHeader.
 	DecodeBody(header *Header, source io.Reader) (*Body, error)
 
+	// DecodeBodyLazily reads the body of a frame from the given source, like DecodeRawBody, and returns its raw bytes,
+	// as read from the source, still compressed if the Compressed flag is set in the given Header, along with a
+	// function decoding them like DecodeBody would. The function decodes the body on its first invocation only, and
+	// returns the same results afterwards; it is safe for concurrent use. This allows proxies to defer body decoding
+	// until needed, e.g. until a routing rule matches, while still being able to forward the raw body as is. This is a
+	// partial operation; It is illegal to call this method before calling DecodeHeader.
+	DecodeBodyLazily(header *Header, source io.Reader) (rawBody []byte, decode func() (*Body, error), err error)
+}
+
+type RawDecoder interface {
+	PartialDecoder
+
+	// DecodeRawFrame decodes a RawFrame from the given source.
+	DecodeRawFrame(source io.Reader) (*RawFrame, error)
+
 	// DecodeRawBody decodes a frame RawBody from the given source. This is a partial operation; it is illegal to call
 	// this method before calling DecodeHeader.
 	DecodeRawBody(header *Header, source io.Reader) ([]byte, error)
@@ -75,7 +117,8 @@ type RawDecoder interface {
 type RawConverter interface {
 
 	// ConvertToRawFrame converts a Frame to a RawFrame, encoding the body and compressing it if necessary. The
-	// returned RawFrame will share the same header with the initial Frame.
+	// returned RawFrame will share the same header with the initial Frame, unless the header flags must be changed to
+	// carry the idempotence of the message; see CodecBuilder.WithIdempotencePayload.
 	ConvertToRawFrame(frame *Frame) (*RawFrame, error)
 
 	// ConvertFromRawFrame converts a RawFrame to a Frame, decoding the body and decompressing it if necessary. The
@@ -83,11 +126,13 @@ type RawConverter interface {
 	ConvertFromRawFrame(frame *RawFrame) (*Frame, error)
 }
 
-// Codec exposes basic encoding and decoding operations for Frame instances. It should be the preferred interface to
-// use in typical client applications such as drivers.
+// Codec exposes basic encoding and decoding operations for Frame instances, including partial operations on headers
+// and bodies. It should be the preferred interface to use in typical client applications such as drivers.
 type Codec interface {
 	Encoder
 	Decoder
+	PartialEncoder
+	PartialDecoder
 }
 
 // RawCodec exposes advanced encoding and decoding operations for both Frame and RawFrame instances. It should be used
@@ -102,7 +147,15 @@ type RawCodec interface {
 
 type codec struct {
 	messageCodecs map[primitive.OpCode]message.Codec
+	betaOpCodes   map[primitive.OpCode]bool
 	compressor    BodyCompressor
+	allowBeta     bool
+	decodingMode  DecodingMode
+	reporter      *message.AnomalyReporter
+	encodeHook    BodyHook
+	decodeHook    BodyHook
+	limits        Limits
+	idempotence   bool
 }
 
 func NewCodec(messageCodecs ...message.Codec) Codec {
@@ -118,17 +171,7 @@ func NewRawCodec(messageCodecs ...message.Codec) RawCodec {
 }
 
 func NewRawCodecWithCompression(compressor BodyCompressor, messageCodecs ...message.Codec) RawCodec {
-	frameCodec := &codec{
-		compressor:    compressor,
-		messageCodecs: make(map[primitive.OpCode]message.Codec, len(message.DefaultMessageCodecs)+len(messageCodecs)),
-	}
-	for _, messageCodec := range message.DefaultMessageCodecs {
-		frameCodec.messageCodecs[messageCodec.GetOpCode()] = messageCodec
-	}
-	for _, messageCodec := range messageCodecs {
-		frameCodec.messageCodecs[messageCodec.GetOpCode()] = messageCodec
-	}
-	return frameCodec
+	return NewCodecBuilder().WithCompressor(compressor).WithMessageCodecs(messageCodecs...).Build()
 }
 
 func (c *codec) GetBodyCompressor() BodyCompressor {
@@ -139,14 +182,56 @@ func (c *codec) SetBodyCompressor(compressor BodyCompressor) {
 	c.compressor = compressor
 }
 
-func (c *codec) findMessageCodec(opCode primitive.OpCode) (message.Codec, error) {
+func (c *codec) findMessageCodec(opCode primitive.OpCode, version primitive.ProtocolVersion) (message.Codec, error) {
 	if encoder, found := c.messageCodecs[opCode]; !found {
 		return nil, fmt.Errorf("unsupported opcode %d", opCode)
+	} else if c.betaOpCodes[opCode] && !version.IsBeta() {
+		return nil, fmt.Errorf("opcode %d is only available in beta protocol versions with the USE_BETA flag, got: %v", opCode, version)
 	} else {
 		return encoder, nil
 	}
 }
 
+// findMessageEncoder returns the encoder for the given message; raw messages are encoded verbatim by all codecs.
+func (c *codec) findMessageEncoder(msg message.Message, version primitive.ProtocolVersion) (message.Encoder, error) {
+	if raw, ok := msg.(*message.RawMessage); ok {
+		return message.NewRawMessageCodec(raw.OpCode, raw.Response), nil
+	}
+	return c.findMessageCodec(msg.GetOpCode(), version)
+}
+
+// findMessageDecoder returns the decoder for the given header's opcode. In lenient decoding mode, the bodies of frames
+// with an opcode that has no registered codec are decoded as message.RawMessage, and an anomaly is reported.
+func (c *codec) findMessageDecoder(header *Header, onAnomaly message.AnomalyHandler) (message.Decoder, error) {
+	decoder, err := c.findMessageCodec(header.OpCode, header.Version)
+	if err != nil && c.decodingMode == DecodingModeLenient && c.messageCodecs[header.OpCode] == nil {
+		decoder = message.NewRawMessageCodec(header.OpCode, header.IsResponse)
+		err = onAnomaly(message.Anomaly{
+			Kind:    message.AnomalyUnknownOpCode,
+			Message: fmt.Sprintf("no codec for %v, body left undecoded", header.OpCode),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decoder, nil
+}
+
+// checkProtocolVersion checks that the given version can be used with this codec, and that the USE_BETA flag is set
+// if and only if required.
+func (c *codec) checkProtocolVersion(version primitive.ProtocolVersion, useBetaFlag bool) error {
+	if version.IsBeta() {
+		if !c.allowBeta {
+			return NewProtocolVersionErr("beta protocol versions are not enabled, see CodecBuilder.WithBetaVersions", version, useBetaFlag)
+		} else if !useBetaFlag {
+			return NewProtocolVersionErr("expected USE_BETA flag to be set", version, useBetaFlag)
+		}
+	} else if err := primitive.CheckSupportedProtocolVersion(version); err != nil {
+		return NewProtocolVersionErr(err.Error(), version, useBetaFlag)
+	}
+	return nil
+}
+
 type ProtocolVersionErr struct {
 	Err     string
 	Version primitive.ProtocolVersion
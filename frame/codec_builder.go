@@ -0,0 +1,192 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// CodecBuilder is a fluent builder for frame codecs with a custom set of message codecs. It starts with the codecs in
+// message.DefaultMessageCodecs; message codecs can then be added, replaced or removed by opcode:
+//
+//  codec := frame.NewCodecBuilder().
+//      WithMessageCodecs(message.NewLenientResultCodec(), myVendorExtensionCodec).
+//      WithoutOpCodes(primitive.OpCodeDseRevise).
+//      WithCompressor(compressor).
+//      Build()
+//
+// Message codecs for opcodes that are not defined by the protocol, e.g. vendor extensions, can be registered too; the
+// resulting codec accepts such opcodes in frame headers, regardless of the frame direction.
+//
+// Beta protocol versions, such as primitive.ProtocolVersion6, are rejected unless enabled with WithBetaVersions;
+// message codecs for draft features of beta versions can be registered with WithBetaMessageCodecs.
+//
+// By default, deviations from the protocol specification that do not prevent decoding are tolerated. Use
+// WithDecodingMode to reject them, see DecodingModeStrict, or to collect them, see DecodingModeLenient, and
+// WithAnomalyReporter to count them and emit throttled warnings.
+//
+// Experimental transformations of frame bodies on the wire, such as encryption, can be plugged in with
+// WithEncodeHook and WithDecodeHook.
+//
+// Servers exposed to untrusted clients should bound the frames and contents they accept with WithLimits.
+type CodecBuilder struct {
+	messageCodecs map[primitive.OpCode]message.Codec
+	betaOpCodes   map[primitive.OpCode]bool
+	compressor    BodyCompressor
+	allowBeta     bool
+	decodingMode  DecodingMode
+	reporter      *message.AnomalyReporter
+	encodeHook    BodyHook
+	decodeHook    BodyHook
+	limits        Limits
+	idempotence   bool
+}
+
+// NewCodecBuilder creates a new CodecBuilder initialized with the message codecs in message.DefaultMessageCodecs, and
+// no compressor.
+func NewCodecBuilder() *CodecBuilder {
+	b := &CodecBuilder{
+		messageCodecs: make(map[primitive.OpCode]message.Codec, len(message.DefaultMessageCodecs)),
+		betaOpCodes:   make(map[primitive.OpCode]bool),
+	}
+	return b.WithMessageCodecs(message.DefaultMessageCodecs...)
+}
+
+// WithMessageCodecs registers the given message codecs, replacing any previously registered codec for the same
+// opcode.
+func (b *CodecBuilder) WithMessageCodecs(messageCodecs ...message.Codec) *CodecBuilder {
+	for _, messageCodec := range messageCodecs {
+		b.messageCodecs[messageCodec.GetOpCode()] = messageCodec
+		delete(b.betaOpCodes, messageCodec.GetOpCode())
+	}
+	return b
+}
+
+// WithBetaMessageCodecs registers the given message codecs like WithMessageCodecs, but only for use with beta
+// protocol versions: codecs built afterwards fail to encode and decode frames with these opcodes if the frame version
+// is not a beta version. This is useful to experiment with messages introduced by a draft version of the protocol.
+// Note that beta versions must also be enabled with WithBetaVersions.
+func (b *CodecBuilder) WithBetaMessageCodecs(messageCodecs ...message.Codec) *CodecBuilder {
+	for _, messageCodec := range messageCodecs {
+		b.messageCodecs[messageCodec.GetOpCode()] = messageCodec
+		b.betaOpCodes[messageCodec.GetOpCode()] = true
+	}
+	return b
+}
+
+// WithBetaVersions enables encoding and decoding of frames using beta protocol versions, see
+// primitive.SupportedBetaProtocolVersions. Such frames must have the USE_BETA flag set, which frame.NewFrame does
+// automatically.
+func (b *CodecBuilder) WithBetaVersions() *CodecBuilder {
+	b.allowBeta = true
+	return b
+}
+
+// WithDecodingMode sets the DecodingMode to use; the default is DecodingModeDefault.
+func (b *CodecBuilder) WithDecodingMode(mode DecodingMode) *CodecBuilder {
+	b.decodingMode = mode
+	return b
+}
+
+// WithAnomalyReporter sets the message.AnomalyReporter to report the anomalies found when decoding to, whether they
+// are tolerated or not, see WithDecodingMode; nil means no reporter. The reporter can be shared by many codecs.
+func (b *CodecBuilder) WithAnomalyReporter(reporter *message.AnomalyReporter) *CodecBuilder {
+	b.reporter = reporter
+	return b
+}
+
+// WithEncodeHook sets the BodyHook invoked with the bytes of each encoded frame body, after compression and before
+// they are written; nil means no hook. The hook applies to EncodeFrame, EncodeToBytes, EncodeFrameToBytes, EncodeBody
+// and ConvertToRawFrame, but not to EncodeRawFrame, since raw frames are already encoded. Note that when the hook
+// changes the body length, EncodeHeader cannot be used before EncodeBody, as with compression.
+func (b *CodecBuilder) WithEncodeHook(hook BodyHook) *CodecBuilder {
+	b.encodeHook = hook
+	return b
+}
+
+// WithDecodeHook sets the BodyHook invoked with the bytes of each frame body read, before decompression and
+// decoding; nil means no hook. The hook applies to DecodeFrame, DecodeFromBytes, DecodeBody and ConvertFromRawFrame,
+// but not to DecodeRawFrame and DecodeRawBody, since raw frames are not decoded. It is typically the inverse of the
+// encode hook, see WithEncodeHook.
+func (b *CodecBuilder) WithDecodeHook(hook BodyHook) *CodecBuilder {
+	b.decodeHook = hook
+	return b
+}
+
+// WithLimits sets the Limits to enforce when decoding; the default is no limits. Frames exceeding them are rejected
+// with a *primitive.LimitExceededError before their oversized contents are read.
+func (b *CodecBuilder) WithLimits(limits Limits) *CodecBuilder {
+	b.limits = limits
+	return b
+}
+
+// WithIdempotencePayload makes codecs carry the idempotence of QUERY, EXECUTE and BATCH requests in their custom
+// payload, see message.IdempotencePayloadKey; by default, idempotence is neither encoded nor decoded. When enabled,
+// EncodeFrame, EncodeToBytes, EncodeFrameToBytes, EncodedBodyLength, EncodeFrameBuffers and ConvertToRawFrame add the
+// idempotence of the message, if set, to the custom payload and set the CUSTOM_PAYLOAD header flag, without modifying
+// the given frame; EncodeBody adds it as well, but since the header is encoded separately, it fails if the header does
+// not have the CUSTOM_PAYLOAD flag set. EncodeRawFrame writes raw frames as they are. Encoding fails for protocol
+// versions lower than 4, which do not support custom payloads. When decoding, the Idempotent field of the message is
+// set from the custom payload.
+func (b *CodecBuilder) WithIdempotencePayload() *CodecBuilder {
+	b.idempotence = true
+	return b
+}
+
+// WithoutOpCodes removes the message codecs registered for the given opcodes. Codecs built afterwards will fail to
+// encode and decode frames with these opcodes.
+func (b *CodecBuilder) WithoutOpCodes(opCodes ...primitive.OpCode) *CodecBuilder {
+	for _, opCode := range opCodes {
+		delete(b.messageCodecs, opCode)
+		delete(b.betaOpCodes, opCode)
+	}
+	return b
+}
+
+// WithCompressor sets the BodyCompressor to use; nil means no compression.
+func (b *CodecBuilder) WithCompressor(compressor BodyCompressor) *CodecBuilder {
+	b.compressor = compressor
+	return b
+}
+
+// MessageCodec returns the message codec currently registered for the given opcode, or nil if none.
+func (b *CodecBuilder) MessageCodec(opCode primitive.OpCode) message.Codec {
+	return b.messageCodecs[opCode]
+}
+
+// Build creates a new RawCodec with the current message codecs and compressor. The builder can be further modified
+// and reused afterwards without affecting the codecs already built.
+func (b *CodecBuilder) Build() RawCodec {
+	frameCodec := &codec{
+		compressor:    b.compressor,
+		messageCodecs: make(map[primitive.OpCode]message.Codec, len(b.messageCodecs)),
+		betaOpCodes:   make(map[primitive.OpCode]bool, len(b.betaOpCodes)),
+		allowBeta:     b.allowBeta,
+		decodingMode:  b.decodingMode,
+		reporter:      b.reporter,
+		encodeHook:    b.encodeHook,
+		decodeHook:    b.decodeHook,
+		limits:        b.limits,
+		idempotence:   b.idempotence,
+	}
+	for opCode, messageCodec := range b.messageCodecs {
+		frameCodec.messageCodecs[opCode] = messageCodec
+	}
+	for opCode := range b.betaOpCodes {
+		frameCodec.betaOpCodes[opCode] = true
+	}
+	return frameCodec
+}
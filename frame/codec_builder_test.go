@@ -0,0 +1,156 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+const opCodeVendorPing = primitive.OpCode(0x42)
+
+// vendorPing is a proprietary request message with a single [string] field.
+type vendorPing struct {
+	Data string
+}
+
+func (m *vendorPing) IsResponse() bool {
+	return false
+}
+
+func (m *vendorPing) GetOpCode() primitive.OpCode {
+	return opCodeVendorPing
+}
+
+func (m *vendorPing) DeepCopyMessage() message.Message {
+	return &vendorPing{Data: m.Data}
+}
+
+type vendorPingCodec struct{}
+
+func (c *vendorPingCodec) Encode(msg message.Message, dest io.Writer, _ primitive.ProtocolVersion) error {
+	return primitive.WriteString(msg.(*vendorPing).Data, dest)
+}
+
+func (c *vendorPingCodec) EncodedLength(msg message.Message, _ primitive.ProtocolVersion) (int, error) {
+	return primitive.LengthOfString(msg.(*vendorPing).Data), nil
+}
+
+func (c *vendorPingCodec) Decode(source io.Reader, _ primitive.ProtocolVersion) (message.Message, error) {
+	data, err := primitive.ReadString(source)
+	return &vendorPing{Data: data}, err
+}
+
+func (c *vendorPingCodec) GetOpCode() primitive.OpCode {
+	return opCodeVendorPing
+}
+
+func TestCodecBuilder(t *testing.T) {
+	t.Run("custom opcode", func(t *testing.T) {
+		rawCodec := NewCodecBuilder().WithMessageCodecs(&vendorPingCodec{}).Build()
+		ping := NewFrame(primitive.ProtocolVersion4, 1, &vendorPing{Data: "hello"})
+		buf := &bytes.Buffer{}
+		require.NoError(t, rawCodec.EncodeFrame(ping, buf))
+		assert.Equal(t, byte(opCodeVendorPing), buf.Bytes()[4])
+		decoded, err := rawCodec.DecodeFrame(buf)
+		require.NoError(t, err)
+		assert.Equal(t, ping, decoded)
+		// the default codec does not know about the custom opcode
+		buf.Reset()
+		require.NoError(t, rawCodec.EncodeFrame(ping, buf))
+		_, err = NewCodec().DecodeFrame(buf)
+		assert.EqualError(t, err, "cannot decode frame header: invalid opcode: OpCode ? [0X42]")
+	})
+	t.Run("replace codec", func(t *testing.T) {
+		builder := NewCodecBuilder()
+		assert.Equal(t, message.DefaultMessageCodecs[12], builder.MessageCodec(primitive.OpCodeResult))
+		lenient := message.NewLenientResultCodec()
+		builder.WithMessageCodecs(lenient)
+		assert.Same(t, lenient, builder.MessageCodec(primitive.OpCodeResult))
+		assert.Same(t, lenient, builder.Build().(*codec).messageCodecs[primitive.OpCodeResult])
+	})
+	t.Run("remove codec", func(t *testing.T) {
+		builder := NewCodecBuilder()
+		withRevise := builder.Build()
+		withoutRevise := builder.WithoutOpCodes(primitive.OpCodeDseRevise).Build()
+		assert.Nil(t, builder.MessageCodec(primitive.OpCodeDseRevise))
+		revise := NewFrame(primitive.ProtocolVersionDse2, 1, &message.Revise{
+			RevisionType:   primitive.DseRevisionTypeCancelContinuousPaging,
+			TargetStreamId: 1,
+		})
+		assert.NoError(t, withRevise.EncodeFrame(revise, &bytes.Buffer{}))
+		assert.EqualError(t, withoutRevise.EncodeFrame(revise, &bytes.Buffer{}), "cannot compute length of uncompressed message body: unsupported opcode 255")
+	})
+	t.Run("compressor", func(t *testing.T) {
+		rawCodec := NewCodecBuilder().WithCompressor(lz4.Compressor{}).Build()
+		assert.Equal(t, lz4.Compressor{}, rawCodec.(*codec).compressor)
+	})
+}
+
+func TestCodecBuilder_BetaVersions(t *testing.T) {
+	query := NewFrame(primitive.ProtocolVersion6, 1, &message.Query{Query: "SELECT", Options: &message.QueryOptions{}})
+	assert.True(t, query.Header.Flags.Contains(primitive.HeaderFlagUseBeta))
+	assert.NoError(t, query.Validate())
+	t.Run("beta disabled", func(t *testing.T) {
+		_, err := EncodeToBytes(NewCodec(), query)
+		assert.EqualError(t, err, "cannot encode frame header: unsupported protocol version (version=ProtocolVersion OSS 6 (beta), useBeta=true): beta protocol versions are not enabled, see CodecBuilder.WithBetaVersions")
+		encoded, err := EncodeToBytes(NewCodecBuilder().WithBetaVersions().Build(), query)
+		require.NoError(t, err)
+		_, err = DecodeFromBytes(NewCodec(), encoded)
+		var versionErr *ProtocolVersionErr
+		require.ErrorAs(t, err, &versionErr)
+		assert.Equal(t, primitive.ProtocolVersion6, versionErr.Version)
+		assert.True(t, versionErr.UseBeta)
+	})
+	t.Run("beta enabled", func(t *testing.T) {
+		rawCodec := NewCodecBuilder().WithBetaVersions().Build()
+		encoded, err := EncodeToBytes(rawCodec, query)
+		require.NoError(t, err)
+		decoded, err := DecodeFromBytes(rawCodec, encoded)
+		require.NoError(t, err)
+		assert.Equal(t, query, decoded)
+		// the USE_BETA flag is mandatory
+		noFlag := query.DeepCopy()
+		noFlag.Header.Flags = noFlag.Header.Flags.Remove(primitive.HeaderFlagUseBeta)
+		_, err = EncodeToBytes(rawCodec, noFlag)
+		assert.EqualError(t, err, "cannot encode frame header: unsupported protocol version (version=ProtocolVersion OSS 6 (beta), useBeta=false): expected USE_BETA flag to be set")
+		encoded[1] &^= byte(primitive.HeaderFlagUseBeta)
+		_, err = DecodeFromBytes(rawCodec, encoded)
+		assert.EqualError(t, err, "cannot decode frame header: unsupported protocol version (version=ProtocolVersion OSS 6 (beta), useBeta=false): expected USE_BETA flag to be set")
+	})
+	t.Run("beta message codecs", func(t *testing.T) {
+		rawCodec := NewCodecBuilder().WithBetaVersions().WithBetaMessageCodecs(&vendorPingCodec{}).Build()
+		ping := NewFrame(primitive.ProtocolVersion6, 1, &vendorPing{Data: "hello"})
+		encoded, err := EncodeToBytes(rawCodec, ping)
+		require.NoError(t, err)
+		decoded, err := DecodeFromBytes(rawCodec, encoded)
+		require.NoError(t, err)
+		assert.Equal(t, ping, decoded)
+		_, err = EncodeToBytes(rawCodec, NewFrame(primitive.ProtocolVersion5, 1, &vendorPing{Data: "hello"}))
+		assert.EqualError(t, err, "cannot compute length of uncompressed message body: opcode 66 is only available in beta protocol versions with the USE_BETA flag, got: ProtocolVersion OSS 5")
+		// registering the codec again as a regular codec lifts the restriction
+		rawCodec = NewCodecBuilder().WithBetaMessageCodecs(&vendorPingCodec{}).WithMessageCodecs(&vendorPingCodec{}).Build()
+		_, err = EncodeToBytes(rawCodec, NewFrame(primitive.ProtocolVersion5, 1, &vendorPing{Data: "hello"}))
+		assert.NoError(t, err)
+	})
+}
@@ -61,6 +61,182 @@ func TestFrameEncodeDecode(t *testing.T) {
 	}
 }
 
+func TestFrameEncode_TracingRequest(t *testing.T) {
+	request := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT", Options: &message.QueryOptions{}})
+	request.RequestTracingId(true)
+	encodedFrame := bytes.Buffer{}
+	require.NoError(t, NewCodec().EncodeFrame(request, &encodedFrame))
+	// requests do not carry a tracing id, the body length must not account for one
+	assert.EqualValues(t, encodedFrame.Len()-primitive.ProtocolVersion4.FrameHeaderLengthInBytes(), request.Header.BodyLength)
+	decodedFrame, err := NewCodec().DecodeFrame(&encodedFrame)
+	require.NoError(t, err)
+	assert.Equal(t, request, decodedFrame)
+	assert.Equal(t, 0, encodedFrame.Len())
+}
+
+func TestFrameEncodeToBytesDecodeFromBytes(t *testing.T) {
+	codecs := createCodecs()
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			request, response := createFrames(version)
+			for algorithm, codec := range codecs {
+				t.Run(algorithm, func(t *testing.T) {
+					for _, f := range []*Frame{request, response} {
+						encodedFrame, err := EncodeToBytes(codec, f)
+						require.NoError(t, err)
+						expected := bytes.Buffer{}
+						require.NoError(t, codec.EncodeFrame(f, &expected))
+						assert.Equal(t, expected.Bytes(), encodedFrame)
+						decodedFrame, err := DecodeFromBytes(codec, encodedFrame)
+						require.NoError(t, err)
+						assert.Equal(t, f, decodedFrame)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestFrameEncodeFrameToBytes(t *testing.T) {
+	codecs := createCodecs()
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			request, response := createFrames(version)
+			for algorithm, codec := range codecs {
+				t.Run(algorithm, func(t *testing.T) {
+					for _, f := range []*Frame{request, response} {
+						expected, err := EncodeToBytes(codec, f)
+						require.NoError(t, err)
+						prefix := []byte{0xca, 0xfe}
+						encodedFrame, err := EncodeFrameToBytes(codec, f, append(make([]byte, 0, 1024), prefix...))
+						require.NoError(t, err)
+						assert.Equal(t, append(prefix, expected...), encodedFrame)
+						assert.Equal(t, 1024, cap(encodedFrame))
+						bodyLength, err := EncodedBodyLength(codec, f)
+						require.NoError(t, err)
+						if !f.Header.Flags.Contains(primitive.HeaderFlagCompressed) {
+							assert.Equal(t, version.FrameHeaderLengthInBytes()+bodyLength, len(expected))
+						}
+						// other encoders encode the frame to compute its body length
+						bodyLength, err = EncodedBodyLength(struct{ Encoder }{codec}, f)
+						require.NoError(t, err)
+						assert.Equal(t, version.FrameHeaderLengthInBytes()+bodyLength, len(expected))
+					}
+				})
+			}
+		})
+	}
+	compressed := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM system.local"})
+	compressed.SetCompress(true)
+	_, err := EncodeFrameToBytes(NewCodec(), compressed, nil)
+	assert.EqualError(t, err, "cannot encode frame body: cannot compress body: no compressor available")
+}
+
+func TestFrameDecodeFromBytes_Errors(t *testing.T) {
+	codec := NewCodec()
+	encodedFrame, err := EncodeToBytes(codec, NewFrame(primitive.ProtocolVersion4, 1, &message.Options{}))
+	require.NoError(t, err)
+	_, err = DecodeFromBytes(codec, append(encodedFrame, 1, 2))
+	assert.EqualError(t, err, "2 trailing bytes after decoded frame")
+	_, err = DecodeFromBytes(codec, encodedFrame[:5])
+	assert.Error(t, err)
+	_, err = DecodeFromBytes(codec, nil)
+	assert.Error(t, err)
+}
+
+func BenchmarkEncodeFrame(b *testing.B) {
+	codec := NewCodec()
+	query := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{
+		Query:   "SELECT * FROM ks.t1 WHERE pk = ?",
+		Options: &message.QueryOptions{PositionalValues: []*primitive.Value{primitive.NewValue([]byte{1, 2, 3, 4})}},
+	})
+	rows := NewFrame(primitive.ProtocolVersion4, 1, &message.RowsResult{
+		Metadata: &message.RowsMetadata{ColumnCount: 2},
+		Data:     make(message.RowSet, 100),
+	})
+	for i := range rows.Body.Message.(*message.RowsResult).Data {
+		rows.Body.Message.(*message.RowsResult).Data[i] = message.Row{{0, 0, 0, byte(i)}, []byte("some text value")}
+	}
+	for name, f := range map[string]*Frame{"Query": query, "Rows": rows} {
+		b.Run(name, func(b *testing.B) {
+			b.Run("bytes.Buffer", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					buf := &bytes.Buffer{}
+					if err := codec.EncodeFrame(f, buf); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run("EncodeToBytes", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := EncodeToBytes(codec, f); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run("WriteBuffer", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					buf := primitive.AcquireWriteBuffer()
+					if err := codec.EncodeFrame(f, buf); err != nil {
+						b.Fatal(err)
+					}
+					primitive.ReleaseWriteBuffer(buf)
+				}
+			})
+			b.Run("EncodeFrameToBytes", func(b *testing.B) {
+				bodyLength, err := EncodedBodyLength(codec, f)
+				if err != nil {
+					b.Fatal(err)
+				}
+				dst := make([]byte, 0, f.Header.Version.FrameHeaderLengthInBytes()+bodyLength)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if dst, err = EncodeFrameToBytes(codec, f, dst[:0]); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestFrameDecode_LenientSchemaChange(t *testing.T) {
+	// a lenient codec consumes the remainder of the body for unknown schema change targets: make sure it does not
+	// consume the next frame in the stream, whether the body is decoded as part of the frame or on its own.
+	codec := NewCodec(message.NewLenientEventCodec())
+	event := &message.SchemaChangeEvent{
+		ChangeType:       primitive.SchemaChangeTypeCreated,
+		Target:           primitive.SchemaChangeTarget("VIEW"),
+		Keyspace:         "ks1",
+		RawTargetOptions: []byte{1, 2, 3},
+	}
+	first := NewFrame(primitive.ProtocolVersion4, -1, event)
+	second := NewFrame(primitive.ProtocolVersion4, 1, &message.Ready{})
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.EncodeFrame(first, encoded))
+	require.NoError(t, codec.EncodeFrame(second, encoded))
+	decoded, err := codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, first, decoded)
+	decoded, err = codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, second, decoded)
+	require.NoError(t, codec.EncodeFrame(first, encoded))
+	require.NoError(t, codec.EncodeFrame(second, encoded))
+	header, err := codec.DecodeHeader(encoded)
+	require.NoError(t, err)
+	body, err := codec.DecodeBody(header, encoded)
+	require.NoError(t, err)
+	assert.Equal(t, first.Body, body)
+	decoded, err = codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, second, decoded)
+}
+
 func TestRawFrameEncodeDecode(t *testing.T) {
 	codecs := createCodecs()
 	for _, version := range primitive.SupportedProtocolVersions() {
@@ -135,6 +311,102 @@ func TestConvertToRawFrame(t *testing.T) {
 	}
 }
 
+func TestCodec_PartialEncodeDecode(t *testing.T) {
+	for algorithm, rawCodec := range createCodecs() {
+		t.Run(algorithm, func(t *testing.T) {
+			var codec Codec = rawCodec
+			_, response := createFrames(primitive.ProtocolVersion4)
+			response.SetCompress(algorithm != "NONE")
+			// compressed bodies must be encoded first, to compute their length
+			body := &bytes.Buffer{}
+			require.NoError(t, codec.EncodeBody(response.Header, response.Body, body))
+			header := response.Header.DeepCopy()
+			header.BodyLength = int32(body.Len())
+			encodedFrame := &bytes.Buffer{}
+			require.NoError(t, codec.EncodeHeader(header, encodedFrame))
+			encodedFrame.Write(body.Bytes())
+			expected, err := EncodeToBytes(codec, response)
+			require.NoError(t, err)
+			require.Equal(t, expected, encodedFrame.Bytes())
+			t.Run("eager", func(t *testing.T) {
+				source := bytes.NewReader(expected)
+				decodedHeader, err := codec.DecodeHeader(source)
+				require.NoError(t, err)
+				assert.Equal(t, header, decodedHeader)
+				decodedBody, err := codec.DecodeBody(decodedHeader, source)
+				require.NoError(t, err)
+				assert.Equal(t, response.Body, decodedBody)
+				assert.Zero(t, source.Len())
+			})
+			t.Run("lazy", func(t *testing.T) {
+				source := bytes.NewReader(expected)
+				decodedHeader, err := codec.DecodeHeader(source)
+				require.NoError(t, err)
+				rawBody, decode, err := codec.DecodeBodyLazily(decodedHeader, source)
+				require.NoError(t, err)
+				assert.Zero(t, source.Len())
+				// the raw body is the body as found on the wire, still compressed
+				assert.Equal(t, body.Bytes(), rawBody)
+				decodedBody, err := decode()
+				require.NoError(t, err)
+				assert.Equal(t, response.Body, decodedBody)
+				again, err := decode()
+				require.NoError(t, err)
+				assert.Same(t, decodedBody, again)
+			})
+		})
+	}
+}
+
+func TestCodec_DecodeBodyLazily_Errors(t *testing.T) {
+	codec := NewCodec()
+	header := &Header{Version: primitive.ProtocolVersion4, OpCode: primitive.OpCodeStartup, BodyLength: 10}
+	_, _, err := codec.DecodeBodyLazily(header, bytes.NewReader([]byte{1, 2, 3}))
+	assert.Error(t, err)
+	header.BodyLength = 3
+	_, decode, err := codec.DecodeBodyLazily(header, bytes.NewReader([]byte{1, 2, 3}))
+	require.NoError(t, err)
+	_, err = decode()
+	assert.Error(t, err)
+}
+
+func TestCodec_EncodeFrameBuffers(t *testing.T) {
+	for algorithm, codec := range createCodecs() {
+		t.Run(algorithm, func(t *testing.T) {
+			vectored, ok := codec.(VectoredEncoder)
+			require.True(t, ok)
+			for _, version := range primitive.SupportedProtocolVersions() {
+				t.Run(version.String(), func(t *testing.T) {
+					request, _ := createFrames(version)
+					request.SetCompress(algorithm != "NONE")
+					expected, err := EncodeToBytes(codec, request)
+					require.NoError(t, err)
+					buffers, err := vectored.EncodeFrameBuffers(request)
+					require.NoError(t, err)
+					require.Len(t, buffers, 2)
+					assert.Len(t, buffers[0], version.FrameHeaderLengthInBytes())
+					assert.Equal(t, int(request.Header.BodyLength), len(buffers[1]))
+					encoded := &bytes.Buffer{}
+					_, err = buffers.WriteTo(encoded)
+					require.NoError(t, err)
+					assert.Equal(t, expected, encoded.Bytes())
+					rawFrame, err := codec.ConvertToRawFrame(request)
+					require.NoError(t, err)
+					buffers, err = vectored.EncodeRawFrameBuffers(rawFrame)
+					require.NoError(t, err)
+					require.Len(t, buffers, 2)
+					// the raw body is not copied
+					assert.Same(t, &rawFrame.Body[0], &buffers[1][0])
+					encoded.Reset()
+					_, err = buffers.WriteTo(encoded)
+					require.NoError(t, err)
+					assert.Equal(t, expected, encoded.Bytes())
+				})
+			}
+		})
+	}
+}
+
 func createCodecs() map[string]RawCodec {
 	codecs := map[string]RawCodec{
 		"NONE":   NewRawCodec(),
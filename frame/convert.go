@@ -20,11 +20,17 @@ import (
 )
 
 func (c *codec) ConvertToRawFrame(frame *Frame) (*RawFrame, error) {
+	original := frame
+	frame, err := c.withIdempotencePayload(frame)
+	if err != nil {
+		return nil, err
+	}
 	var body bytes.Buffer
 	if err := c.EncodeBody(frame.Header, frame.Body, &body); err != nil {
 		return nil, fmt.Errorf("cannot encode body: %w", err)
 	}
 	frame.Header.BodyLength = int32(body.Len())
+	original.Header.BodyLength = frame.Header.BodyLength
 	return &RawFrame{
 		Header: frame.Header,
 		Body:   body.Bytes(),
@@ -0,0 +1,221 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ByteDiff describes a contiguous range of bytes that differ between an original encoded frame and its re-encoded
+// form.
+type ByteDiff struct {
+	// Offset is the offset of the first differing byte, relative to the beginning of the frame.
+	Offset int
+	// Region is the name of the frame region where the difference is located: one of "version", "flags",
+	// "stream id", "opcode", "body length" or "body".
+	Region string
+	// RegionOffset is the offset of the first differing byte, relative to the beginning of the region.
+	RegionOffset int
+	// Expected contains the original bytes; it may be shorter than Actual if the original frame was shorter.
+	Expected []byte
+	// Actual contains the re-encoded bytes; it may be shorter than Expected if the re-encoded frame was shorter.
+	Actual []byte
+}
+
+func (d *ByteDiff) String() string {
+	return fmt.Sprintf("offset %d (%s+%d): expected [%s], got [%s]",
+		d.Offset, d.Region, d.RegionOffset, hex.EncodeToString(d.Expected), hex.EncodeToString(d.Actual))
+}
+
+// RoundTripResult is the result of decoding and re-encoding an encoded frame, see RoundTrip.
+type RoundTripResult struct {
+	// Name identifies the encoded frame, e.g. the corpus file it was read from.
+	Name string
+	// Original contains the original encoded frame.
+	Original []byte
+	// ReEncoded contains the re-encoded frame; nil if decoding or encoding failed.
+	ReEncoded []byte
+	// Frame is the decoded frame; nil if decoding failed.
+	Frame *Frame
+	// Err is the error that occurred while decoding or re-encoding the frame, if any.
+	Err error
+	// Diffs contains the differences between the original and the re-encoded frames, if any.
+	Diffs []*ByteDiff
+}
+
+// OK returns true if the frame could be decoded and re-encoded, and the re-encoded bytes are identical to the
+// original ones.
+func (r *RoundTripResult) OK() bool {
+	return r.Err == nil && len(r.Diffs) == 0
+}
+
+// String returns a human-readable report of this result, with one line per difference found.
+func (r *RoundTripResult) String() string {
+	sb := &strings.Builder{}
+	if r.Err != nil {
+		_, _ = fmt.Fprintf(sb, "%s: %v", r.Name, r.Err)
+	} else if len(r.Diffs) == 0 {
+		_, _ = fmt.Fprintf(sb, "%s: OK", r.Name)
+	} else {
+		_, _ = fmt.Fprintf(sb, "%s: %d difference(s) found in %v", r.Name, len(r.Diffs), r.Frame)
+		for _, diff := range r.Diffs {
+			sb.WriteString("\n  ")
+			sb.WriteString(diff.String())
+		}
+	}
+	return sb.String()
+}
+
+// RoundTrip decodes the given encoded frame with the given codec, re-encodes it, and compares the re-encoded bytes
+// with the original ones. The encoded slice must contain exactly one frame.
+func RoundTrip(codec Codec, name string, encoded []byte) *RoundTripResult {
+	result := &RoundTripResult{Name: name, Original: encoded}
+	source := bytes.NewReader(encoded)
+	if result.Frame, result.Err = codec.DecodeFrame(source); result.Err != nil {
+		result.Frame = nil
+		return result
+	} else if source.Len() > 0 {
+		result.Err = fmt.Errorf("%d trailing bytes after decoded frame", source.Len())
+		return result
+	}
+	dest := &bytes.Buffer{}
+	if result.Err = codec.EncodeFrame(result.Frame, dest); result.Err != nil {
+		result.Err = fmt.Errorf("cannot re-encode decoded frame: %w", result.Err)
+		return result
+	}
+	result.ReEncoded = dest.Bytes()
+	result.Diffs = DiffEncodedFrames(result.Original, result.ReEncoded)
+	return result
+}
+
+// VerifyCorpus round-trips every encoded frame found in the given corpus directory and its subdirectories, see
+// RoundTrip. Files with the extension ".bin" must contain one raw encoded frame; files with the extension ".hex" must
+// contain one encoded frame in hexadecimal form, where whitespace is ignored, as well as lines starting with '#'.
+// Other files are ignored. Results are returned in lexical order of file paths, relative to the corpus directory.
+// An error is returned only if the corpus could not be read; round-trip failures are reported in the results.
+func VerifyCorpus(codec Codec, dir string) ([]*RoundTripResult, error) {
+	var results []*RoundTripResult
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		var encoded []byte
+		switch filepath.Ext(path) {
+		case ".bin":
+			if encoded, err = os.ReadFile(path); err != nil {
+				return err
+			}
+		case ".hex":
+			if encoded, err = readHexFile(path); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+		name, _ := filepath.Rel(dir, path)
+		results = append(results, RoundTrip(codec, name, encoded))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read corpus %s: %w", dir, err)
+	}
+	return results, nil
+}
+
+func readHexFile(path string) ([]byte, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sb := &strings.Builder{}
+	for _, line := range strings.Split(string(contents), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, r := range line {
+			if !unicode.IsSpace(r) {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	decoded, err := hex.DecodeString(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s: %w", path, err)
+	}
+	return decoded, nil
+}
+
+// DiffEncodedFrames compares the given encoded frames byte by byte, and returns the differences found, annotated
+// with the frame region where they are located. Contiguous differing bytes within the same region are reported as a
+// single ByteDiff.
+func DiffEncodedFrames(expected []byte, actual []byte) []*ByteDiff {
+	var diffs []*ByteDiff
+	var current *ByteDiff
+	length := len(expected)
+	if len(actual) > length {
+		length = len(actual)
+	}
+	for i := 0; i < length; i++ {
+		if i < len(expected) && i < len(actual) && expected[i] == actual[i] {
+			current = nil
+			continue
+		}
+		region, regionOffset := frameRegion(i, expected)
+		if current == nil || current.Region != region {
+			current = &ByteDiff{Offset: i, Region: region, RegionOffset: regionOffset, Expected: []byte{}, Actual: []byte{}}
+			diffs = append(diffs, current)
+		}
+		if i < len(expected) {
+			current.Expected = append(current.Expected, expected[i])
+		}
+		if i < len(actual) {
+			current.Actual = append(current.Actual, actual[i])
+		}
+	}
+	return diffs
+}
+
+// frameRegion returns the name of the region of the given offset, and the offset relative to the beginning of that
+// region. The first byte of the encoded frame is used to determine the header layout.
+func frameRegion(offset int, encoded []byte) (string, int) {
+	headerLength := primitive.FrameHeaderLengthV3AndHigher
+	if len(encoded) > 0 {
+		headerLength = primitive.ProtocolVersion(encoded[0] & 0b0111_1111).FrameHeaderLengthInBytes()
+	}
+	opCodeOffset := headerLength - primitive.LengthOfInt - 1
+	switch {
+	case offset == 0:
+		return "version", 0
+	case offset == 1:
+		return "flags", 0
+	case offset < opCodeOffset:
+		return "stream id", offset - 2
+	case offset == opCodeOffset:
+		return "opcode", 0
+	case offset < headerLength:
+		return "body length", offset - opCodeOffset - 1
+	default:
+		return "body", offset - headerLength
+	}
+}
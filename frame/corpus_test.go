@@ -0,0 +1,138 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// upperCaseQueryCodec is a deliberately broken QUERY codec that upper-cases query strings when encoding.
+type upperCaseQueryCodec struct {
+	message.Codec
+}
+
+func (c *upperCaseQueryCodec) Encode(msg message.Message, dest io.Writer, version primitive.ProtocolVersion) error {
+	query := *msg.(*message.Query)
+	query.Query = strings.ToUpper(query.Query)
+	return c.Codec.Encode(&query, dest, version)
+}
+
+func encodeFrame(t *testing.T, f *Frame) []byte {
+	buf := &bytes.Buffer{}
+	require.NoError(t, NewCodec().EncodeFrame(f, buf))
+	return buf.Bytes()
+}
+
+func TestVerifyCorpus(t *testing.T) {
+	dir := t.TempDir()
+	query := encodeFrame(t, NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "select * from t"}))
+	ready := encodeFrame(t, NewFrame(primitive.ProtocolVersion2, 2, &message.Ready{}))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "v4"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "v4", "query.bin"), query, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ready.hex"), []byte("# READY v2\n"+hex.EncodeToString(ready[:4])+"\n"+hex.EncodeToString(ready[4:])+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "trailing.bin"), append(ready, 0), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0644))
+
+	t.Run("default codec", func(t *testing.T) {
+		results, err := VerifyCorpus(NewCodec(), dir)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, "ready.hex", results[0].Name)
+		assert.True(t, results[0].OK(), results[0].String())
+		assert.Equal(t, "ready.hex: OK", results[0].String())
+		assert.Equal(t, "trailing.bin", results[1].Name)
+		assert.False(t, results[1].OK())
+		assert.EqualError(t, results[1].Err, "1 trailing bytes after decoded frame")
+		assert.Equal(t, filepath.Join("v4", "query.bin"), results[2].Name)
+		assert.True(t, results[2].OK(), results[2].String())
+	})
+
+	t.Run("broken custom codec", func(t *testing.T) {
+		codec := NewCodec(&upperCaseQueryCodec{NewCodecBuilder().MessageCodec(primitive.OpCodeQuery)})
+		results, err := VerifyCorpus(codec, filepath.Join(dir, "v4"))
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		result := results[0]
+		assert.False(t, result.OK())
+		require.Len(t, result.Diffs, 3)
+		assert.Equal(t, &ByteDiff{
+			Offset:       13,
+			Region:       "body",
+			RegionOffset: 4,
+			Expected:     []byte("select"),
+			Actual:       []byte("SELECT"),
+		}, result.Diffs[0])
+		assert.Equal(t, "offset 22 (body+13): expected [66726f6d], got [46524f4d]", result.Diffs[1].String())
+		assert.True(t, strings.HasPrefix(result.String(), "query.bin: 3 difference(s) found in "))
+	})
+
+	t.Run("missing corpus", func(t *testing.T) {
+		_, err := VerifyCorpus(NewCodec(), filepath.Join(dir, "nonexistent"))
+		assert.Error(t, err)
+	})
+}
+
+func TestDiffEncodedFrames(t *testing.T) {
+	expected := []byte{0x04, 0x00, 0x00, 0x01, 0x07, 0x00, 0x00, 0x00, 0x01, 0xaa}
+	tests := []struct {
+		name     string
+		actual   []byte
+		expected []*ByteDiff
+	}{
+		{"identical", expected, nil},
+		{
+			"header regions",
+			[]byte{0x84, 0x01, 0x00, 0x02, 0x08, 0x00, 0x00, 0x00, 0x02, 0xaa},
+			[]*ByteDiff{
+				{Offset: 0, Region: "version", Expected: []byte{0x04}, Actual: []byte{0x84}},
+				{Offset: 1, Region: "flags", Expected: []byte{0x00}, Actual: []byte{0x01}},
+				{Offset: 3, Region: "stream id", RegionOffset: 1, Expected: []byte{0x01}, Actual: []byte{0x02}},
+				{Offset: 4, Region: "opcode", Expected: []byte{0x07}, Actual: []byte{0x08}},
+				{Offset: 8, Region: "body length", RegionOffset: 3, Expected: []byte{0x01}, Actual: []byte{0x02}},
+			},
+		},
+		{
+			"longer",
+			append(expected, 0xbb, 0xcc),
+			[]*ByteDiff{{Offset: 10, Region: "body", RegionOffset: 1, Expected: []byte{}, Actual: []byte{0xbb, 0xcc}}},
+		},
+		{
+			"shorter",
+			expected[:9],
+			[]*ByteDiff{{Offset: 9, Region: "body", Expected: []byte{0xaa}, Actual: []byte{}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DiffEncodedFrames(expected, tt.actual))
+		})
+	}
+	t.Run("v2 header", func(t *testing.T) {
+		diffs := DiffEncodedFrames([]byte{0x02, 0x00, 0x01, 0x07, 0, 0, 0, 0}, []byte{0x02, 0x00, 0x01, 0x08, 0, 0, 0, 0})
+		assert.Equal(t, []*ByteDiff{{Offset: 3, Region: "opcode", Expected: []byte{0x07}, Actual: []byte{0x08}}}, diffs)
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"sync"
 
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
@@ -34,6 +35,32 @@ func (c *codec) DecodeFrame(source io.Reader) (*Frame, error) {
 	}
 }
 
+var readerPool = sync.Pool{
+	New: func() interface{} {
+		return &bytes.Reader{}
+	},
+}
+
+// DecodeFromBytes decodes the entire frame from the given bytes with the given decoder, decompressing the body if
+// needed. This is a convenience function equivalent to calling Decoder.DecodeFrame with a reader over the given bytes;
+// the source must contain exactly one frame, and trailing bytes cause an error. The returned frame does not retain the
+// source slice.
+func DecodeFromBytes(decoder Decoder, source []byte) (*Frame, error) {
+	reader := readerPool.Get().(*bytes.Reader)
+	defer func() {
+		reader.Reset(nil)
+		readerPool.Put(reader)
+	}()
+	reader.Reset(source)
+	if frame, err := decoder.DecodeFrame(reader); err != nil {
+		return nil, err
+	} else if reader.Len() > 0 {
+		return nil, fmt.Errorf("%d trailing bytes after decoded frame", reader.Len())
+	} else {
+		return frame, nil
+	}
+}
+
 func (c *codec) DecodeRawFrame(source io.Reader) (*RawFrame, error) {
 	if header, err := c.DecodeHeader(source); err != nil {
 		return nil, fmt.Errorf("cannot decode frame header: %w", err)
@@ -63,20 +90,23 @@ func (c *codec) DecodeHeader(source io.Reader) (*Header, error) {
 		useBetaFlag := primitive.HeaderFlag(flags).Contains(primitive.HeaderFlagUseBeta)
 
 		var opCode uint8
-		if err = primitive.CheckSupportedProtocolVersion(version); err != nil {
-			return nil, NewProtocolVersionErr(err.Error(), version, useBetaFlag)
-		} else if version.IsBeta() && !useBetaFlag {
-			return nil, NewProtocolVersionErr("expected USE_BETA flag to be set", version, useBetaFlag)
+		if err = c.checkProtocolVersion(version, useBetaFlag); err != nil {
+			return nil, err
 		} else if header.StreamId, err = primitive.ReadStreamId(source, version); err != nil {
 			return nil, fmt.Errorf("cannot decode header stream id: %w", err)
 		} else if opCode, err = primitive.ReadByte(source); err != nil {
 			return nil, fmt.Errorf("cannot decode header opcode: %w", err)
 		} else if header.BodyLength, err = primitive.ReadInt(source); err != nil {
 			return nil, fmt.Errorf("cannot decode header body length: %w", err)
+		} else if err = c.limits.checkBodyLength(header.BodyLength); err != nil {
+			return nil, err
 		}
 		header.Flags = primitive.HeaderFlag(flags)
 		header.OpCode = primitive.OpCode(opCode)
-		if err := primitive.CheckValidOpCode(header.OpCode); err != nil {
+		if !header.OpCode.IsValid() && (c.messageCodecs[header.OpCode] != nil || c.decodingMode == DecodingModeLenient) {
+			// custom opcode, or unknown opcode in lenient mode: its direction cannot be checked
+			return header, nil
+		} else if err := primitive.CheckValidOpCode(header.OpCode); err != nil {
 			return nil, err
 		} else if isResponse {
 			if err := primitive.CheckResponseOpCode(header.OpCode); err != nil {
@@ -92,19 +122,40 @@ func (c *codec) DecodeHeader(source io.Reader) (*Header, error) {
 }
 
 func (c *codec) DecodeBody(header *Header, source io.Reader) (body *Body, err error) {
+	body = &Body{}
+	onAnomaly := c.anomalyHandler(body)
+	if err := checkHeaderFlags(header, onAnomaly); err != nil {
+		return nil, err
+	} else if err := c.limits.checkBodyLength(header.BodyLength); err != nil {
+		return nil, err
+	}
+	limitedSource := &io.LimitedReader{R: source, N: int64(header.BodyLength)}
+	if c.decodeHook != nil {
+		transformedSource, length, err := c.applyDecodeHook(header, limitedSource)
+		if err != nil {
+			return nil, err
+		}
+		limitedSource = &io.LimitedReader{R: transformedSource, N: length}
+	}
+	source = limitedSource
+	var decompressedBody *bytes.Buffer
 	if compressed := header.Flags.Contains(primitive.HeaderFlagCompressed); compressed {
 		if c.compressor == nil {
 			return nil, errors.New("cannot decompress body: no compressor available")
 		} else {
-			decompressedBody := &bytes.Buffer{}
-			if err := c.compressor.DecompressWithLength(io.LimitReader(source, int64(header.BodyLength)), decompressedBody); err != nil {
+			decompressedBody = &bytes.Buffer{}
+			var dest io.Writer = decompressedBody
+			if c.limits.MaxBodyLength > 0 {
+				dest = &limitedWriter{w: decompressedBody, limit: int64(c.limits.MaxBodyLength)}
+			}
+			if err := c.compressor.DecompressWithLength(limitedSource, dest); err != nil {
 				return nil, fmt.Errorf("cannot decompress body: %w", err)
 			} else {
 				source = decompressedBody
 			}
 		}
 	}
-	body = &Body{}
+	source = primitive.WithDecodeLimits(source, c.limits.DecodeLimits)
 	if header.IsResponse && header.Flags.Contains(primitive.HeaderFlagTracing) {
 		if body.TracingId, err = primitive.ReadUuid(source); err != nil {
 			return nil, fmt.Errorf("cannot decode body tracing id: %w", err)
@@ -120,28 +171,50 @@ func (c *codec) DecodeBody(header *Header, source io.Reader) (body *Body, err er
 			return nil, fmt.Errorf("cannot decode body warnings: %w", err)
 		}
 	}
-	if decoder, err := c.findMessageCodec(header.OpCode); err != nil {
+	if decoder, err := c.findMessageDecoder(header, onAnomaly); err != nil {
 		return nil, err
-	} else if body.Message, err = decoder.Decode(source, header.Version); err != nil {
+	} else if body.Message, err = c.decodeMessage(decoder, source, header.Version, onAnomaly); err != nil {
 		return nil, fmt.Errorf("cannot decode body message: %w", err)
 	}
+	if err = checkTrailingBytes(limitedSource, decompressedBody, body.Message, onAnomaly); err != nil {
+		return nil, err
+	}
+	c.decodeIdempotencePayload(body)
 	return body, err
 }
 
 func (c *codec) DecodeRawBody(header *Header, source io.Reader) (body []byte, err error) {
 	if header.BodyLength < 0 {
 		return nil, fmt.Errorf("invalid body length: %d", header.BodyLength)
+	} else if err := c.limits.checkBodyLength(header.BodyLength); err != nil {
+		return nil, err
 	} else if header.BodyLength == 0 {
 		return []byte{}, nil
 	}
 	count := int64(header.BodyLength)
-	buf := bytes.NewBuffer(make([]byte, 0, count))
+	buf := bytes.NewBuffer(make([]byte, 0, primitive.PreallocatedLength(int(count))))
 	if _, err := io.CopyN(buf, source, count); err != nil {
 		return nil, fmt.Errorf("cannot decode raw body: %w", err)
 	}
 	return buf.Bytes(), nil
 }
 
+func (c *codec) DecodeBodyLazily(header *Header, source io.Reader) ([]byte, func() (*Body, error), error) {
+	rawBody, err := c.DecodeRawBody(header, source)
+	if err != nil {
+		return nil, nil, err
+	}
+	var once sync.Once
+	var body *Body
+	decode := func() (*Body, error) {
+		once.Do(func() {
+			body, err = c.DecodeBody(header, bytes.NewReader(rawBody))
+		})
+		return body, err
+	}
+	return rawBody, decode, nil
+}
+
 func (c *codec) DiscardBody(header *Header, source io.Reader) (err error) {
 	if header.BodyLength < 0 {
 		return fmt.Errorf("invalid body length: %d", header.BodyLength)
@@ -0,0 +1,161 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// DecodingMode tells how a codec reacts to deviations from the protocol specification when decoding frames, such as
+// empty query strings, unknown enum values, trailing bytes after the message of a frame body, or unexpected header
+// flags. See message.Anomaly.
+type DecodingMode int
+
+const (
+	// DecodingModeDefault is the default mode: frames are decoded as they always have been, i.e. deviations from the
+	// protocol specification that do not prevent decoding are tolerated, and only reported to the codec's reporter, if
+	// any. Anomalies are not collected in Body.Anomalies.
+	DecodingModeDefault = DecodingMode(iota)
+	// DecodingModeStrict makes decoding fail with a *message.AnomalyError describing the first anomaly found. This
+	// mode is suitable for conformance testing.
+	DecodingModeStrict
+	// DecodingModeLenient tolerates anomalies whenever possible, and collects them in Body.Anomalies; frames with
+	// unknown opcodes are decoded as message.RawMessage. This mode is suitable for proxies, which should forward
+	// whatever they receive. Note that lenient message codecs, such as
+	// message.NewLenientResultCodec, can be registered to tolerate even more anomalies; see CodecBuilder.
+	DecodingModeLenient
+)
+
+func (m DecodingMode) String() string {
+	switch m {
+	case DecodingModeDefault:
+		return "default"
+	case DecodingModeStrict:
+		return "strict"
+	case DecodingModeLenient:
+		return "lenient"
+	}
+	return fmt.Sprintf("DecodingMode ? [%d]", int(m))
+}
+
+// knownHeaderFlags are the header flags defined by the protocol specification.
+const knownHeaderFlags = primitive.HeaderFlagCompressed |
+	primitive.HeaderFlagTracing |
+	primitive.HeaderFlagCustomPayload |
+	primitive.HeaderFlagWarning |
+	primitive.HeaderFlagUseBeta
+
+// anomalyHandler returns the message.AnomalyHandler to use when decoding the given body: in strict mode, anomalies
+// are rejected; in lenient mode, they are collected in the body; in default mode, they are ignored. Anomalies are
+// also reported to the codec's reporter, if any.
+func (c *codec) anomalyHandler(body *Body) message.AnomalyHandler {
+	var handler message.AnomalyHandler
+	switch c.decodingMode {
+	case DecodingModeStrict:
+		handler = message.StrictAnomalyHandler
+	case DecodingModeLenient:
+		handler = func(anomaly message.Anomaly) error {
+			body.Anomalies = append(body.Anomalies, anomaly)
+			return nil
+		}
+	default:
+		handler = func(message.Anomaly) error { return nil }
+	}
+	if c.reporter != nil {
+		return c.reporter.Handler(handler)
+	}
+	return handler
+}
+
+func checkHeaderFlags(header *Header, onAnomaly message.AnomalyHandler) error {
+	var anomaly *message.Anomaly
+	if unknown := header.Flags.Remove(knownHeaderFlags); unknown != 0 {
+		anomaly = &message.Anomaly{
+			Kind:    message.AnomalyUnexpectedFlags,
+			Message: fmt.Sprintf("unknown header flags: %#.2x", uint8(unknown)),
+		}
+	} else if !header.IsResponse && header.Flags.Contains(primitive.HeaderFlagWarning) {
+		anomaly = &message.Anomaly{
+			Kind:    message.AnomalyUnexpectedFlags,
+			Message: "WARNING header flag set on a request",
+		}
+	} else if header.Version < primitive.ProtocolVersion4 &&
+		header.Flags.Contains(primitive.HeaderFlagCustomPayload|primitive.HeaderFlagWarning) {
+		anomaly = &message.Anomaly{
+			Kind:    message.AnomalyUnexpectedFlags,
+			Message: fmt.Sprintf("CUSTOM_PAYLOAD and WARNING header flags are not supported in %v", header.Version),
+		}
+	}
+	if anomaly != nil {
+		return onAnomaly(*anomaly)
+	}
+	return nil
+}
+
+// decodeMessage decodes a message and reports its anomalies. In default mode, the message is decoded with its
+// decoder's Decode method, so that messages it rejects, e.g. with unknown consistency levels, are still rejected.
+func (c *codec) decodeMessage(
+	decoder message.Decoder,
+	source io.Reader,
+	version primitive.ProtocolVersion,
+	onAnomaly message.AnomalyHandler,
+) (msg message.Message, err error) {
+	if anomalyDecoder, ok := decoder.(message.AnomalyDecoder); ok && c.decodingMode != DecodingModeDefault {
+		msg, err = anomalyDecoder.DecodeWithAnomalies(source, version, onAnomaly)
+	} else {
+		msg, err = decoder.Decode(source, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, anomaly := range message.FindAnomalies(msg) {
+		if err = onAnomaly(anomaly); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// checkTrailingBytes discards the bytes of the body that were not consumed when decoding its message, and reports
+// them as an anomaly. Discarding them keeps the source aligned on the next frame, regardless of the decoding mode.
+func checkTrailingBytes(
+	source *io.LimitedReader,
+	decompressedBody *bytes.Buffer,
+	msg message.Message,
+	onAnomaly message.AnomalyHandler,
+) error {
+	trailing := source.N
+	if trailing > 0 {
+		if _, err := io.CopyN(ioutil.Discard, source, trailing); err != nil {
+			return fmt.Errorf("cannot discard body trailing bytes: %w", err)
+		}
+	}
+	if decompressedBody != nil {
+		trailing += int64(decompressedBody.Len())
+	}
+	if trailing > 0 {
+		return onAnomaly(message.Anomaly{
+			Kind:    message.AnomalyTrailingBytes,
+			Message: fmt.Sprintf("%d bytes after the end of %v message", trailing, msg.GetOpCode()),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func encodeRawTestFrame(t *testing.T, header *Header, body []byte) []byte {
+	encoded := &bytes.Buffer{}
+	require.NoError(t, NewRawCodec().EncodeRawFrame(&RawFrame{Header: header, Body: body}, encoded))
+	return encoded.Bytes()
+}
+
+func encodeTestQueryBody(t *testing.T, query string, consistency uint16) []byte {
+	body := &bytes.Buffer{}
+	require.NoError(t, primitive.WriteLongString(query, body))
+	require.NoError(t, primitive.WriteShort(consistency, body))
+	require.NoError(t, primitive.WriteByte(0, body)) // query flags
+	return body.Bytes()
+}
+
+func TestCodec_DecodingMode(t *testing.T) {
+	queryHeader := func(flags primitive.HeaderFlag) *Header {
+		return &Header{Version: primitive.ProtocolVersion4, Flags: flags, StreamId: 1, OpCode: primitive.OpCodeQuery}
+	}
+	validBody := encodeTestQueryBody(t, "SELECT", uint16(primitive.ConsistencyLevelOne))
+	tests := []struct {
+		name     string
+		encoded  []byte
+		expected message.Message
+		anomaly  message.Anomaly
+		// whether the default mode rejects the frame, as it always has
+		rejected bool
+	}{
+		{
+			"unknown header flags",
+			encodeRawTestFrame(t, queryHeader(0x40), validBody),
+			&message.Query{Query: "SELECT", Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevelOne}},
+			message.Anomaly{Kind: message.AnomalyUnexpectedFlags, Message: "unknown header flags: 0x40"},
+			false,
+		},
+		{
+			"trailing bytes",
+			encodeRawTestFrame(t, queryHeader(0), append(append([]byte{}, validBody...), 1, 2, 3)),
+			&message.Query{Query: "SELECT", Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevelOne}},
+			message.Anomaly{Kind: message.AnomalyTrailingBytes, Message: "3 bytes after the end of OpCode QUERY [0x07] message"},
+			false,
+		},
+		{
+			"empty query string",
+			encodeRawTestFrame(t, queryHeader(0), encodeTestQueryBody(t, "", uint16(primitive.ConsistencyLevelOne))),
+			&message.Query{Query: "", Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevelOne}},
+			message.Anomaly{Kind: message.AnomalyEmptyQueryString, Message: "QUERY query string is empty"},
+			false,
+		},
+		{
+			"unknown consistency",
+			encodeRawTestFrame(t, queryHeader(0), encodeTestQueryBody(t, "SELECT", 0x42)),
+			&message.Query{Query: "SELECT", Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevel(0x42)}},
+			message.Anomaly{
+				Kind:    message.AnomalyUnknownEnumValue,
+				Message: "consistency is unknown: " + primitive.ConsistencyLevel(0x42).String(),
+			},
+			true,
+		},
+	}
+	next := encodeRawTestFrame(t, queryHeader(0), validBody)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Run("default", func(t *testing.T) {
+				codec := NewCodecBuilder().Build()
+				source := bytes.NewReader(append(append([]byte{}, tt.encoded...), next...))
+				decoded, err := codec.DecodeFrame(source)
+				if tt.rejected {
+					require.Error(t, err)
+					var anomalyErr *message.AnomalyError
+					assert.False(t, errors.As(err, &anomalyErr), "expected plain error, got: %v", err)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, decoded.Body.Message)
+				assert.Nil(t, decoded.Body.Anomalies)
+				// the source is still aligned on the next frame
+				_, err = codec.DecodeFrame(source)
+				require.NoError(t, err)
+				assert.Equal(t, 0, source.Len())
+			})
+			t.Run("strict", func(t *testing.T) {
+				codec := NewCodecBuilder().WithDecodingMode(DecodingModeStrict).Build()
+				_, err := codec.DecodeFrame(bytes.NewReader(tt.encoded))
+				var anomalyErr *message.AnomalyError
+				require.True(t, errors.As(err, &anomalyErr), "expected AnomalyError, got: %v", err)
+				assert.Equal(t, tt.anomaly, anomalyErr.Anomaly)
+			})
+			t.Run("lenient", func(t *testing.T) {
+				codec := NewCodecBuilder().WithDecodingMode(DecodingModeLenient).Build()
+				source := bytes.NewReader(append(append([]byte{}, tt.encoded...), next...))
+				decoded, err := codec.DecodeFrame(source)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, decoded.Body.Message)
+				assert.Equal(t, []message.Anomaly{tt.anomaly}, decoded.Body.Anomalies)
+				// the source is still aligned on the next frame
+				decoded, err = codec.DecodeFrame(source)
+				require.NoError(t, err)
+				assert.Nil(t, decoded.Body.Anomalies)
+				assert.Equal(t, 0, source.Len())
+			})
+		})
+	}
+}
+
+func TestCodec_DecodingMode_NoAnomalies(t *testing.T) {
+	for _, mode := range []DecodingMode{DecodingModeDefault, DecodingModeStrict, DecodingModeLenient} {
+		t.Run(mode.String(), func(t *testing.T) {
+			codec := NewCodecBuilder().WithDecodingMode(mode).Build()
+			request, response := createFrames(primitive.ProtocolVersion4)
+			for _, f := range []*Frame{request, response} {
+				encoded, err := EncodeToBytes(codec, f)
+				require.NoError(t, err)
+				decoded, err := DecodeFromBytes(codec, encoded)
+				require.NoError(t, err)
+				assert.Equal(t, f, decoded)
+			}
+		})
+	}
+}
+
+func TestCodec_RawMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   *Header
+		codec    RawCodec
+		expected message.Message
+	}{
+		{
+			"unknown opcode",
+			&Header{IsResponse: true, Version: primitive.ProtocolVersion4, StreamId: 1, OpCode: 0x42},
+			NewCodecBuilder().WithDecodingMode(DecodingModeLenient).Build(),
+			&message.RawMessage{OpCode: 0x42, Response: true, Body: []byte{1, 2, 3}},
+		},
+		{
+			"unregistered opcode",
+			&Header{Version: primitive.ProtocolVersion4, StreamId: 1, OpCode: primitive.OpCodeQuery},
+			NewCodecBuilder().WithDecodingMode(DecodingModeLenient).WithoutOpCodes(primitive.OpCodeQuery).Build(),
+			&message.RawMessage{OpCode: primitive.OpCodeQuery, Body: []byte{1, 2, 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := encodeRawTestFrame(t, tt.header, []byte{1, 2, 3})
+			_, err := NewCodecBuilder().WithoutOpCodes(primitive.OpCodeQuery).Build().DecodeFrame(bytes.NewReader(encoded))
+			assert.Error(t, err)
+			decoded, err := tt.codec.DecodeFrame(bytes.NewReader(encoded))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, decoded.Body.Message)
+			assert.Equal(t, []message.Anomaly{{
+				Kind:    message.AnomalyUnknownOpCode,
+				Message: "no codec for " + tt.header.OpCode.String() + ", body left undecoded",
+			}}, decoded.Body.Anomalies)
+			// raw messages are encoded verbatim, by all codecs
+			reencoded, err := EncodeToBytes(NewCodec(), decoded)
+			require.NoError(t, err)
+			assert.Equal(t, encoded, reencoded)
+			// raw messages carry the header direction and opcode
+			header := NewFrame(tt.header.Version, tt.header.StreamId, tt.expected).Header
+			assert.Equal(t, tt.header.IsResponse, header.IsResponse)
+			assert.Equal(t, tt.header.OpCode, header.OpCode)
+		})
+	}
+}
+
+func TestCodec_AnomalyReporter(t *testing.T) {
+	header := &Header{Version: primitive.ProtocolVersion4, StreamId: 1, OpCode: primitive.OpCodeQuery}
+	encoded := encodeRawTestFrame(t, header, encodeTestQueryBody(t, "", uint16(primitive.ConsistencyLevelOne)))
+	var warnings []message.Anomaly
+	reporter := message.NewAnomalyReporter(time.Hour, func(anomaly message.Anomaly, _ int64) {
+		warnings = append(warnings, anomaly)
+	})
+	strict := NewCodecBuilder().WithAnomalyReporter(reporter).WithDecodingMode(DecodingModeStrict).Build()
+	lenient := NewCodecBuilder().WithAnomalyReporter(reporter).WithDecodingMode(DecodingModeLenient).Build()
+	byDefault := NewCodecBuilder().WithAnomalyReporter(reporter).Build()
+	_, err := strict.DecodeFrame(bytes.NewReader(encoded))
+	assert.Error(t, err)
+	_, err = lenient.DecodeFrame(bytes.NewReader(encoded))
+	assert.NoError(t, err)
+	_, err = byDefault.DecodeFrame(bytes.NewReader(encoded))
+	assert.NoError(t, err)
+	assert.Equal(t, map[message.AnomalyKind]int64{message.AnomalyEmptyQueryString: 3}, reporter.Counts())
+	assert.Equal(t, []message.Anomaly{{Kind: message.AnomalyEmptyQueryString, Message: "QUERY query string is empty"}}, warnings)
+}
+
+func TestCheckHeaderFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   *Header
+		expected string
+	}{
+		{"valid request", &Header{Version: primitive.ProtocolVersion4, Flags: primitive.HeaderFlagTracing}, ""},
+		{"valid response", &Header{IsResponse: true, Version: primitive.ProtocolVersion4, Flags: primitive.HeaderFlagWarning}, ""},
+		{"unknown flags", &Header{Version: primitive.ProtocolVersion4, Flags: 0xa0}, "unexpected flags: unknown header flags: 0xa0"},
+		{"warning on request", &Header{Version: primitive.ProtocolVersion4, Flags: primitive.HeaderFlagWarning}, "unexpected flags: WARNING header flag set on a request"},
+		{"payload in v3", &Header{Version: primitive.ProtocolVersion3, Flags: primitive.HeaderFlagCustomPayload}, "unexpected flags: CUSTOM_PAYLOAD and WARNING header flags are not supported in ProtocolVersion OSS 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkHeaderFlags(tt.header, message.StrictAnomalyHandler)
+			if tt.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expected)
+			}
+		})
+	}
+}
@@ -19,6 +19,10 @@
 
 package frame
 
+import (
+	message "github.com/datastax/go-cassandra-native-protocol/message"
+)
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Body) DeepCopyInto(out *Body) {
 	*out = *in
@@ -49,6 +53,11 @@ func (in *Body) DeepCopyInto(out *Body) {
 	if in.Message != nil {
 		out.Message = in.Message.DeepCopyMessage()
 	}
+	if in.Anomalies != nil {
+		in, out := &in.Anomalies, &out.Anomalies
+		*out = make([]message.Anomaly, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
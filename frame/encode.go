@@ -19,18 +19,72 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
 
 func (c *codec) EncodeFrame(frame *Frame, dest io.Writer) error {
-	if frame.Header.Flags.Contains(primitive.HeaderFlagCompressed) {
-		return c.encodeFrameCompressed(frame, dest)
+	if withIdempotence, err := c.withIdempotencePayload(frame); err != nil {
+		return err
+	} else if withIdempotence != frame {
+		defer func(original *Frame) { original.Header.BodyLength = withIdempotence.Header.BodyLength }(frame)
+		frame = withIdempotence
+	}
+	if frame.Header.Flags.Contains(primitive.HeaderFlagCompressed) || c.encodeHook != nil {
+		// the body length is only known after the body is encoded
+		return c.encodeFrameBuffered(frame, dest)
 	} else {
 		return c.encodeFrameUncompressed(frame, dest)
 	}
 }
 
+// EncodeToBytes encodes the entire frame with the given encoder, compressing the body if needed, and returns the
+// encoded bytes. This is a convenience function equivalent to calling Encoder.EncodeFrame with a buffer; the
+// intermediary buffer is pooled.
+func EncodeToBytes(encoder Encoder, frame *Frame) ([]byte, error) {
+	buf := primitive.AcquireWriteBuffer()
+	defer primitive.ReleaseWriteBuffer(buf)
+	if err := encoder.EncodeFrame(frame, buf); err != nil {
+		return nil, err
+	}
+	return buf.CopyBytes(), nil
+}
+
+// EncodeFrameToBytes encodes the entire frame with the given encoder, compressing the body if needed, appends the
+// encoded bytes to dst and returns the extended slice. The frame is encoded into a pooled buffer first, like with
+// EncodeToBytes; if dst has enough spare capacity, no memory is allocated. Use EncodedBodyLength to size dst.
+func EncodeFrameToBytes(encoder Encoder, frame *Frame, dst []byte) ([]byte, error) {
+	buf := primitive.AcquireWriteBuffer()
+	defer primitive.ReleaseWriteBuffer(buf)
+	if err := encoder.EncodeFrame(frame, buf); err != nil {
+		return nil, err
+	}
+	return append(dst, buf.Bytes()...), nil
+}
+
+// EncodedBodyLength returns the length of the given frame's body once encoded by the given encoder. For codecs
+// created by this package, this is the length before compression and before the encode hook, if any, is applied, and
+// the frame is not encoded; for other encoders, the frame is encoded to compute it. The encoded frame length is that
+// plus the header length, see primitive.ProtocolVersion.FrameHeaderLengthInBytes; for compressed frames, this is only
+// a size hint.
+func EncodedBodyLength(encoder Encoder, frame *Frame) (int, error) {
+	if c, ok := encoder.(*codec); ok {
+		if withIdempotence, err := c.withIdempotencePayload(frame); err != nil {
+			return -1, err
+		} else {
+			frame = withIdempotence
+		}
+		return c.uncompressedBodyLength(frame.Header, frame.Body)
+	}
+	buf := primitive.AcquireWriteBuffer()
+	defer primitive.ReleaseWriteBuffer(buf)
+	if err := encoder.EncodeFrame(frame, buf); err != nil {
+		return -1, err
+	}
+	return buf.Len() - frame.Header.Version.FrameHeaderLengthInBytes(), nil
+}
+
 func (c *codec) encodeFrameUncompressed(frame *Frame, dest io.Writer) error {
 	if encodedBodyLength, err := c.uncompressedBodyLength(frame.Header, frame.Body); err != nil {
 		return fmt.Errorf("cannot compute length of uncompressed message body: %w", err)
@@ -45,41 +99,70 @@ func (c *codec) encodeFrameUncompressed(frame *Frame, dest io.Writer) error {
 	return nil
 }
 
-func (c *codec) encodeFrameCompressed(frame *Frame, dest io.Writer) error {
-	compressedBody := bytes.Buffer{}
-	if err := c.EncodeBody(frame.Header, frame.Body, &compressedBody); err != nil {
-		return fmt.Errorf("cannot encode frame body: %w", err)
-	} else {
-		frame.Header.BodyLength = int32(compressedBody.Len())
-		if err := c.EncodeHeader(frame.Header, dest); err != nil {
-			return fmt.Errorf("cannot encode frame header: %w", err)
-		} else if _, err := compressedBody.WriteTo(dest); err != nil {
-			return fmt.Errorf("cannot concat frame body to frame header: %w", err)
-		}
+func (c *codec) encodeFrameBuffered(frame *Frame, dest io.Writer) error {
+	if buffers, err := c.encodeFrameBuffers(frame); err != nil {
+		return err
+	} else if _, err := buffers.WriteTo(dest); err != nil {
+		return fmt.Errorf("cannot write frame: %w", err)
 	}
 	return nil
 }
 
+func (c *codec) EncodeFrameBuffers(frame *Frame) (net.Buffers, error) {
+	if withIdempotence, err := c.withIdempotencePayload(frame); err != nil {
+		return nil, err
+	} else if withIdempotence != frame {
+		defer func(original *Frame) { original.Header.BodyLength = withIdempotence.Header.BodyLength }(frame)
+		frame = withIdempotence
+	}
+	return c.encodeFrameBuffers(frame)
+}
+
+func (c *codec) encodeFrameBuffers(frame *Frame) (net.Buffers, error) {
+	encodedBody := &bytes.Buffer{}
+	if err := c.EncodeBody(frame.Header, frame.Body, encodedBody); err != nil {
+		return nil, fmt.Errorf("cannot encode frame body: %w", err)
+	}
+	frame.Header.BodyLength = int32(encodedBody.Len())
+	if encodedHeader, err := c.encodeHeaderToBytes(frame.Header); err != nil {
+		return nil, fmt.Errorf("cannot encode frame header: %w", err)
+	} else {
+		return net.Buffers{encodedHeader, encodedBody.Bytes()}, nil
+	}
+}
+
 func (c *codec) EncodeRawFrame(frame *RawFrame, dest io.Writer) error {
-	if err := primitive.CheckSupportedProtocolVersion(frame.Header.Version); err != nil {
+	if buffers, err := c.EncodeRawFrameBuffers(frame); err != nil {
 		return err
-	} else {
-		frame.Header.BodyLength = int32(len(frame.Body))
-		if err := c.EncodeHeader(frame.Header, dest); err != nil {
-			return fmt.Errorf("cannot encode raw header: %w", err)
-		} else if _, err := dest.Write(frame.Body); err != nil {
-			return fmt.Errorf("cannot write raw body: %w", err)
-		}
+	} else if _, err := buffers.WriteTo(dest); err != nil {
+		return fmt.Errorf("cannot write raw frame: %w", err)
 	}
 	return nil
 }
 
+func (c *codec) EncodeRawFrameBuffers(frame *RawFrame) (net.Buffers, error) {
+	if err := c.checkProtocolVersion(frame.Header.Version, frame.Header.Flags.Contains(primitive.HeaderFlagUseBeta)); err != nil {
+		return nil, err
+	}
+	frame.Header.BodyLength = int32(len(frame.Body))
+	if encodedHeader, err := c.encodeHeaderToBytes(frame.Header); err != nil {
+		return nil, fmt.Errorf("cannot encode raw header: %w", err)
+	} else {
+		return net.Buffers{encodedHeader, frame.Body}, nil
+	}
+}
+
+func (c *codec) encodeHeaderToBytes(header *Header) ([]byte, error) {
+	encodedHeader := bytes.NewBuffer(make([]byte, 0, primitive.FrameHeaderLengthV3AndHigher))
+	if err := c.EncodeHeader(header, encodedHeader); err != nil {
+		return nil, err
+	}
+	return encodedHeader.Bytes(), nil
+}
+
 func (c *codec) EncodeHeader(header *Header, dest io.Writer) error {
-	useBetaFlag := header.Flags.Contains(primitive.HeaderFlagUseBeta)
-	if err := primitive.CheckSupportedProtocolVersion(header.Version); err != nil {
-		return NewProtocolVersionErr(err.Error(), header.Version, useBetaFlag)
-	} else if header.Version.IsBeta() && !useBetaFlag {
-		return NewProtocolVersionErr("expected USE_BETA flag to be set", header.Version, useBetaFlag)
+	if err := c.checkProtocolVersion(header.Version, header.Flags.Contains(primitive.HeaderFlagUseBeta)); err != nil {
+		return err
 	}
 
 	versionAndDirection := uint8(header.Version)
@@ -101,6 +184,17 @@ func (c *codec) EncodeHeader(header *Header, dest io.Writer) error {
 }
 
 func (c *codec) EncodeBody(header *Header, body *Body, dest io.Writer) error {
+	body, err := c.withIdempotenceBody(header, body)
+	if err != nil {
+		return err
+	}
+	if c.encodeHook != nil {
+		return c.applyEncodeHook(header, body, dest)
+	}
+	return c.encodeBody(header, body, dest)
+}
+
+func (c *codec) encodeBody(header *Header, body *Body, dest io.Writer) error {
 	if header.OpCode != body.Message.GetOpCode() {
 		return fmt.Errorf("opcode mismatch between header and body: %d != %d", header.OpCode, body.Message.GetOpCode())
 	} else if header.Flags.Contains(primitive.HeaderFlagCompressed) {
@@ -123,7 +217,7 @@ func (c *codec) EncodeBody(header *Header, body *Body, dest io.Writer) error {
 }
 
 func (c *codec) encodeBodyUncompressed(header *Header, body *Body, dest io.Writer) (err error) {
-	if header.Flags.Contains(primitive.HeaderFlagTracing) && body.Message.IsResponse() {
+	if hasTracingId(header, body) {
 		if err = primitive.WriteUuid(body.TracingId, dest); err != nil {
 			return fmt.Errorf("cannot encode body tracing id: %w", err)
 		}
@@ -142,7 +236,7 @@ func (c *codec) encodeBodyUncompressed(header *Header, body *Body, dest io.Write
 			return fmt.Errorf("cannot encode body warnings: %w", err)
 		}
 	}
-	if encoder, err := c.findMessageCodec(body.Message.GetOpCode()); err != nil {
+	if encoder, err := c.findMessageEncoder(body.Message, header.Version); err != nil {
 		return err
 	} else if err = encoder.Encode(body.Message, dest, header.Version); err != nil {
 		return fmt.Errorf("cannot encode body message: %w", err)
@@ -150,13 +244,19 @@ func (c *codec) encodeBodyUncompressed(header *Header, body *Body, dest io.Write
 	return nil
 }
 
+// hasTracingId tells whether the given body starts with a tracing id: only responses carry one, the tracing flag of a
+// request merely asks the server for it.
+func hasTracingId(header *Header, body *Body) bool {
+	return header.Flags.Contains(primitive.HeaderFlagTracing) && body.Message.IsResponse()
+}
+
 func (c *codec) uncompressedBodyLength(header *Header, body *Body) (length int, err error) {
-	if encoder, err := c.findMessageCodec(body.Message.GetOpCode()); err != nil {
+	if encoder, err := c.findMessageEncoder(body.Message, header.Version); err != nil {
 		return -1, err
 	} else if length, err = encoder.EncodedLength(body.Message, header.Version); err != nil {
 		return -1, fmt.Errorf("cannot compute message length: %w", err)
 	}
-	if header.Flags.Contains(primitive.HeaderFlagTracing) {
+	if hasTracingId(header, body) {
 		length += primitive.LengthOfUuid
 	}
 	if header.Flags.Contains(primitive.HeaderFlagCustomPayload) {
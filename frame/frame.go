@@ -17,6 +17,7 @@ package frame
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
 
 	"github.com/datastax/go-cassandra-native-protocol/message"
@@ -70,6 +71,8 @@ type Body struct {
 	Warnings []string
 	// The body message.
 	Message message.Message
+	// The anomalies tolerated when decoding this body with DecodingModeLenient. Ignored when encoding.
+	Anomalies []message.Anomaly
 }
 
 // NewFrame Creates a new Frame with the given version, stream id and message.
@@ -151,6 +154,51 @@ func (f *Frame) SetCompress(compress bool) {
 	}
 }
 
+// Validate checks that this frame's header and body are consistent with each other and with the frame's protocol
+// version. It returns a descriptive error when, for example, a custom payload is set on a protocol version 3 frame, or
+// a tracing id is set on a request frame.
+func (f *Frame) Validate() error {
+	if f.Header == nil {
+		return errors.New("invalid frame: header is nil")
+	} else if f.Body == nil || f.Body.Message == nil {
+		return errors.New("invalid frame: body message is nil")
+	}
+	version := f.Header.Version
+	// beta versions are valid, provided that the USE_BETA flag is set, see below
+	if err := primitive.CheckSupportedProtocolVersion(version); err != nil && !version.IsBeta() {
+		return fmt.Errorf("invalid frame: %w", err)
+	} else if f.Header.OpCode != f.Body.Message.GetOpCode() {
+		return fmt.Errorf("invalid frame: opcode mismatch between header and body: %v != %v",
+			f.Header.OpCode, f.Body.Message.GetOpCode())
+	} else if f.Header.IsResponse != f.Body.Message.IsResponse() {
+		return fmt.Errorf("invalid frame: direction mismatch between header and body: response = %v, message = %v",
+			f.Header.IsResponse, f.Body.Message)
+	} else if version.IsBeta() != f.Header.Flags.Contains(primitive.HeaderFlagUseBeta) {
+		return fmt.Errorf("invalid frame: USE_BETA flag must be set if and only if the version is beta, got %v", version)
+	}
+	if f.Header.Flags.Contains(primitive.HeaderFlagCustomPayload) || len(f.Body.CustomPayload) > 0 {
+		if version < primitive.ProtocolVersion4 {
+			return fmt.Errorf("invalid frame: custom payloads are not supported in %v", version)
+		}
+	}
+	if f.Header.Flags.Contains(primitive.HeaderFlagWarning) || len(f.Body.Warnings) > 0 {
+		if version < primitive.ProtocolVersion4 {
+			return fmt.Errorf("invalid frame: warnings are not supported in %v", version)
+		} else if !f.Header.IsResponse {
+			return errors.New("invalid frame: warnings are only valid for response frames")
+		}
+	}
+	if f.Body.TracingId != nil && !f.Header.IsResponse {
+		return errors.New("invalid frame: tracing ids are only valid for response frames")
+	} else if f.Header.IsResponse && f.Header.Flags.Contains(primitive.HeaderFlagTracing) && f.Body.TracingId == nil {
+		return errors.New("invalid frame: tracing flag is set but tracing id is nil")
+	}
+	if f.Header.Flags.Contains(primitive.HeaderFlagCompressed) && !isCompressible(f.Header.OpCode) {
+		return fmt.Errorf("invalid frame: %v cannot be compressed", f.Header.OpCode)
+	}
+	return nil
+}
+
 func (f *Frame) String() string {
 	return fmt.Sprintf("{header: %v, body: %v}", f.Header, f.Body)
 }
@@ -171,11 +219,10 @@ func (b *Body) String() string {
 
 // Dump encodes and dumps the contents of this frame, for debugging purposes.
 func (f *Frame) Dump() (string, error) {
-	buffer := bytes.Buffer{}
-	if err := NewCodec().EncodeFrame(f, &buffer); err != nil {
+	if encoded, err := EncodeToBytes(NewCodec(), f); err != nil {
 		return "", err
 	} else {
-		return hex.Dump(buffer.Bytes()), nil
+		return hex.Dump(encoded), nil
 	}
 }
 
@@ -0,0 +1,91 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package frame_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// FuzzDecode runs frame.FuzzDecode with Go native fuzzing, e.g.: go test -fuzz=FuzzDecode ./frame. The seed corpus
+// contains the message conformance samples, encoded with all supported protocol versions.
+func FuzzDecode(f *testing.F) {
+	codec := frame.NewCodec()
+	for _, version := range primitive.SupportedProtocolVersions() {
+		for _, sample := range message.ConformanceSamples(version) {
+			buf := &bytes.Buffer{}
+			if err := codec.EncodeFrame(frame.NewFrame(version, 1, sample.Message), buf); err == nil {
+				f.Add(buf.Bytes())
+			}
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		frame.FuzzDecode(data)
+	})
+}
+
+func TestFuzzDecode_HostileLengths(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"huge body length", []byte{0x04, 0x00, 0x00, 0x01, 0x08, 0x7f, 0xff, 0xff, 0xff, 0x00}},
+		{"negative body length", []byte{0x04, 0x00, 0x00, 0x01, 0x08, 0xff, 0xff, 0xff, 0xff}},
+		{"huge rows count", []byte{
+			0x84, 0x00, 0x00, 0x01, 0x08, 0x00, 0x00, 0x00, 0x18,
+			0x00, 0x00, 0x00, 0x02, // rows
+			0x00, 0x00, 0x00, 0x04, // flags: no metadata
+			0x7f, 0xff, 0xff, 0xff, // column count
+			0x7f, 0xff, 0xff, 0xff, // rows count
+			0x7f, 0xff, 0xff, 0xff, // first cell length
+			0x00, 0x00, 0x00, 0x00,
+		}},
+		{"negative rows count", []byte{
+			0x84, 0x00, 0x00, 0x01, 0x08, 0x00, 0x00, 0x00, 0x10,
+			0x00, 0x00, 0x00, 0x02, // rows
+			0x00, 0x00, 0x00, 0x04, // flags: no metadata
+			0x00, 0x00, 0x00, 0x01, // column count
+			0xff, 0xff, 0xff, 0xff, // rows count
+		}},
+		{"rows without columns", []byte{
+			0x84, 0x00, 0x00, 0x01, 0x08, 0x00, 0x00, 0x00, 0x10,
+			0x00, 0x00, 0x00, 0x02, // rows
+			0x00, 0x00, 0x00, 0x04, // flags: no metadata
+			0x00, 0x00, 0x00, 0x00, // column count
+			0x7f, 0xff, 0xff, 0xff, // rows count
+		}},
+		{"negative column count", []byte{
+			0x84, 0x00, 0x00, 0x01, 0x08, 0x00, 0x00, 0x00, 0x10,
+			0x00, 0x00, 0x00, 0x02, // rows
+			0x00, 0x00, 0x00, 0x00, // flags
+			0xff, 0xff, 0xff, 0xff, // column count
+			0x00, 0x00, 0x00, 0x00, // rows count
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if frame.FuzzDecode(tt.data) != 0 {
+				t.Errorf("expected decoding to fail")
+			}
+		})
+	}
+}
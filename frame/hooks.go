@@ -0,0 +1,61 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// BodyHook transforms the bytes of a frame body, as they appear on the wire, i.e. after compression when encoding,
+// and before decompression when decoding. Hooks make it possible to prototype experimental layers, such as payload
+// encryption or obfuscation, on top of a codec. The header is the header of the frame being encoded or decoded; it
+// must not be modified, and its BodyLength is not meaningful when encoding. The returned bytes may have a different
+// length than the given ones; the header's BodyLength is updated accordingly when encoding. See
+// CodecBuilder.WithEncodeHook and CodecBuilder.WithDecodeHook.
+type BodyHook func(header *Header, body []byte) ([]byte, error)
+
+// applyEncodeHook encodes the given body in memory, then writes the result of the encode hook to dest.
+func (c *codec) applyEncodeHook(header *Header, body *Body, dest io.Writer) error {
+	encodedBody := &bytes.Buffer{}
+	if err := c.encodeBody(header, body, encodedBody); err != nil {
+		return err
+	}
+	transformed, err := c.encodeHook(header, encodedBody.Bytes())
+	if err != nil {
+		return fmt.Errorf("encode hook failed: %w", err)
+	} else if _, err = dest.Write(transformed); err != nil {
+		return fmt.Errorf("cannot write transformed body: %w", err)
+	}
+	return nil
+}
+
+// applyDecodeHook reads the body from the given source, which must be limited to the body length, and returns a new
+// source containing the result of the decode hook, along with its length.
+func (c *codec) applyDecodeHook(header *Header, source io.Reader) (io.Reader, int64, error) {
+	body, err := ioutil.ReadAll(source)
+	if err != nil {
+		return nil, -1, fmt.Errorf("cannot read body: %w", err)
+	} else if len(body) != int(header.BodyLength) {
+		return nil, -1, fmt.Errorf("cannot read body: expected %d bytes, got: %d", header.BodyLength, len(body))
+	}
+	transformed, err := c.decodeHook(header, body)
+	if err != nil {
+		return nil, -1, fmt.Errorf("decode hook failed: %w", err)
+	}
+	return bytes.NewReader(transformed), int64(len(transformed)), nil
+}
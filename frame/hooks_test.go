@@ -0,0 +1,102 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// xorHook is a toy obfuscation layer: it xors every byte of the body, and appends a one-byte checksum.
+func xorHook(encode bool) BodyHook {
+	return func(header *Header, body []byte) ([]byte, error) {
+		if !encode {
+			if len(body) == 0 {
+				return nil, errors.New("missing checksum")
+			}
+			body = body[:len(body)-1]
+		}
+		transformed := make([]byte, len(body), len(body)+1)
+		var checksum byte
+		for i, b := range body {
+			transformed[i] = b ^ 0x5a
+			checksum ^= b
+		}
+		if encode {
+			transformed = append(transformed, checksum)
+		}
+		return transformed, nil
+	}
+}
+
+func TestCodec_Hooks(t *testing.T) {
+	plainCodec := NewCodecBuilder().WithCompressor(lz4.Compressor{}).Build()
+	hookedCodec := NewCodecBuilder().
+		WithCompressor(lz4.Compressor{}).
+		WithEncodeHook(xorHook(true)).
+		WithDecodeHook(xorHook(false)).
+		Build()
+	for _, compressed := range []bool{false, true} {
+		request, response := createFrames(primitive.ProtocolVersion4)
+		for _, f := range []*Frame{request, response} {
+			if compressed {
+				f.SetCompress(true)
+			}
+			t.Run(f.String(), func(t *testing.T) {
+				plain, err := EncodeToBytes(plainCodec, f)
+				require.NoError(t, err)
+				hooked, err := EncodeToBytes(hookedCodec, f)
+				require.NoError(t, err)
+				assert.Equal(t, len(plain)+1, len(hooked))
+				assert.Equal(t, int32(len(hooked)-primitive.FrameHeaderLengthV3AndHigher), f.Header.BodyLength)
+				assert.NotEqual(t, plain[primitive.FrameHeaderLengthV3AndHigher:], hooked[primitive.FrameHeaderLengthV3AndHigher:])
+				decoded, err := DecodeFromBytes(hookedCodec, hooked)
+				require.NoError(t, err)
+				assert.Equal(t, f, decoded)
+				// raw frames are not transformed
+				rawFrame, err := hookedCodec.ConvertToRawFrame(f)
+				require.NoError(t, err)
+				rawEncoded := &bytes.Buffer{}
+				require.NoError(t, hookedCodec.EncodeRawFrame(rawFrame, rawEncoded))
+				assert.Equal(t, hooked, rawEncoded.Bytes())
+				decodedRaw, err := hookedCodec.DecodeRawFrame(bytes.NewReader(hooked))
+				require.NoError(t, err)
+				assert.Equal(t, rawFrame, decodedRaw)
+				decoded, err = hookedCodec.ConvertFromRawFrame(decodedRaw)
+				require.NoError(t, err)
+				assert.Equal(t, f, decoded)
+			})
+		}
+	}
+}
+
+func TestCodec_Hooks_Errors(t *testing.T) {
+	failing := func(*Header, []byte) ([]byte, error) { return nil, errors.New("boom") }
+	f := NewFrame(primitive.ProtocolVersion4, 1, &message.Options{})
+	_, err := EncodeToBytes(NewCodecBuilder().WithEncodeHook(failing).Build(), f)
+	assert.EqualError(t, err, "cannot encode frame body: encode hook failed: boom")
+	encoded, err := EncodeToBytes(NewCodecBuilder().Build(), f)
+	require.NoError(t, err)
+	_, err = DecodeFromBytes(NewCodecBuilder().WithDecodeHook(failing).Build(), encoded)
+	assert.EqualError(t, err, "cannot decode frame body: decode hook failed: boom")
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// withIdempotencePayload returns a shallow copy of the given frame whose custom payload carries the idempotence of
+// its message, as per message.IdempotencePayloadKey, and whose header has the CUSTOM_PAYLOAD flag set; if the codec
+// does not carry idempotence, see CodecBuilder.WithIdempotencePayload, or if there is nothing to add, the frame itself
+// is returned. The given frame is never modified.
+func (c *codec) withIdempotencePayload(frame *Frame) (*Frame, error) {
+	if value, err := c.idempotencePayloadValue(frame.Header, frame.Body); value == nil || err != nil {
+		return frame, err
+	} else if frame.Header.Flags.Contains(primitive.HeaderFlagCustomPayload) &&
+		bytes.Equal(frame.Body.CustomPayload[message.IdempotencePayloadKey], value) {
+		return frame, nil
+	} else {
+		header := *frame.Header
+		header.Flags = header.Flags.Add(primitive.HeaderFlagCustomPayload)
+		return &Frame{Header: &header, Body: withCustomPayloadEntry(frame.Body, value)}, nil
+	}
+}
+
+// withIdempotenceBody is the counterpart of withIdempotencePayload for partial encoding: since the header is encoded
+// separately, it must already have the CUSTOM_PAYLOAD flag set if the body carries idempotence.
+func (c *codec) withIdempotenceBody(header *Header, body *Body) (*Body, error) {
+	if value, err := c.idempotencePayloadValue(header, body); value == nil || err != nil {
+		return body, err
+	} else if !header.Flags.Contains(primitive.HeaderFlagCustomPayload) {
+		return nil, errors.New("cannot encode idempotence: the CUSTOM_PAYLOAD flag is not set in the header")
+	} else if bytes.Equal(body.CustomPayload[message.IdempotencePayloadKey], value) {
+		return body, nil
+	} else {
+		return withCustomPayloadEntry(body, value), nil
+	}
+}
+
+// idempotencePayloadValue returns the custom payload value carrying the idempotence of the given body's message, or
+// nil if the codec does not carry idempotence or if the message idempotence is unspecified.
+func (c *codec) idempotencePayloadValue(header *Header, body *Body) ([]byte, error) {
+	if !c.idempotence {
+		return nil, nil
+	}
+	request, ok := body.Message.(message.IdempotentRequest)
+	if !ok || request.GetIdempotent() == nil {
+		return nil, nil
+	} else if header.Version < primitive.ProtocolVersion4 {
+		return nil, fmt.Errorf("cannot encode idempotence: custom payloads are not supported in protocol version %v", header.Version)
+	}
+	return message.EncodeIdempotence(*request.GetIdempotent()), nil
+}
+
+// withCustomPayloadEntry returns a shallow copy of the given body whose custom payload also contains the given
+// idempotence value.
+func withCustomPayloadEntry(body *Body, value []byte) *Body {
+	customPayload := make(map[string][]byte, len(body.CustomPayload)+1)
+	for key, v := range body.CustomPayload {
+		customPayload[key] = v
+	}
+	customPayload[message.IdempotencePayloadKey] = value
+	copied := *body
+	copied.CustomPayload = customPayload
+	return &copied
+}
+
+// decodeIdempotencePayload sets the idempotence of the decoded message from the custom payload, if present and if the
+// codec carries idempotence. Since custom payloads are opaque to the protocol, invalid values are ignored rather than
+// failing the decoding.
+func (c *codec) decodeIdempotencePayload(body *Body) {
+	if !c.idempotence {
+		return
+	}
+	if value, found := body.CustomPayload[message.IdempotencePayloadKey]; found {
+		if request, ok := body.Message.(message.IdempotentRequest); ok {
+			if idempotent, err := message.DecodeIdempotence(value); err == nil {
+				request.SetIdempotent(&idempotent)
+			}
+		}
+	}
+}
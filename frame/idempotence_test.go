@@ -0,0 +1,136 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestFrameEncodeDecode_Idempotence(t *testing.T) {
+	idempotent := true
+	notIdempotent := false
+	codec := NewCodecBuilder().WithIdempotencePayload().Build()
+	tests := []struct {
+		name    string
+		message message.IdempotentRequest
+		payload map[string][]byte
+	}{
+		{"query idempotent", &message.Query{Query: "SELECT", Idempotent: &idempotent}, nil},
+		{"query not idempotent", &message.Query{Query: "INSERT", Idempotent: &notIdempotent}, nil},
+		{"execute", &message.Execute{QueryId: []byte{1}, Idempotent: &idempotent}, nil},
+		{"batch", &message.Batch{Children: []*message.BatchChild{{Query: "INSERT"}}, Idempotent: &idempotent}, nil},
+		{"with other payload", &message.Query{Query: "SELECT", Idempotent: &idempotent}, map[string][]byte{"foo": {42}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := NewFrame(primitive.ProtocolVersion4, 1, tt.message)
+			request.SetCustomPayload(tt.payload)
+			original := request.DeepCopy()
+			encoded := &bytes.Buffer{}
+			require.NoError(t, codec.EncodeFrame(request, encoded))
+			// the encoded frame is not modified, except for its body length
+			original.Header.BodyLength = request.Header.BodyLength
+			assert.Equal(t, original, request)
+			assert.Equal(t, int32(encoded.Len()-primitive.FrameHeaderLengthV3AndHigher), request.Header.BodyLength)
+			decoded, err := codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+			require.NoError(t, err)
+			assert.True(t, decoded.Header.Flags.Contains(primitive.HeaderFlagCustomPayload))
+			assert.Equal(t, message.EncodeIdempotence(*tt.message.GetIdempotent()), decoded.Body.CustomPayload[message.IdempotencePayloadKey])
+			for key, value := range tt.payload {
+				assert.Equal(t, value, decoded.Body.CustomPayload[key])
+			}
+			assert.Equal(t, tt.message.GetIdempotent(), decoded.Body.Message.(message.IdempotentRequest).GetIdempotent())
+			// all encode paths produce the same frame; custom payload entries may be encoded in any order
+			assertSameFrame := func(actual []byte) {
+				assert.Equal(t, encoded.Len(), len(actual))
+				actualDecoded, err := codec.DecodeFrame(bytes.NewReader(actual))
+				require.NoError(t, err)
+				assert.Equal(t, decoded, actualDecoded)
+			}
+			bodyLength, err := EncodedBodyLength(codec, request)
+			require.NoError(t, err)
+			assert.Equal(t, int(request.Header.BodyLength), bodyLength)
+			buffers, err := codec.(VectoredEncoder).EncodeFrameBuffers(request)
+			require.NoError(t, err)
+			assertSameFrame(bytes.Join(buffers, nil))
+			raw, err := codec.ConvertToRawFrame(request)
+			require.NoError(t, err)
+			rawEncoded := &bytes.Buffer{}
+			require.NoError(t, codec.EncodeRawFrame(raw, rawEncoded))
+			assertSameFrame(rawEncoded.Bytes())
+			partial := request.DeepCopy()
+			partial.Header.Flags = partial.Header.Flags.Add(primitive.HeaderFlagCustomPayload)
+			partialEncoded := &bytes.Buffer{}
+			require.NoError(t, codec.EncodeHeader(partial.Header, partialEncoded))
+			require.NoError(t, codec.EncodeBody(partial.Header, partial.Body, partialEncoded))
+			assertSameFrame(partialEncoded.Bytes())
+			converted, err := codec.ConvertFromRawFrame(raw)
+			require.NoError(t, err)
+			assert.Equal(t, decoded.Body, converted.Body)
+		})
+	}
+}
+
+func TestFrameEncodeDecode_IdempotenceDisabled(t *testing.T) {
+	idempotent := true
+	codec := NewCodec()
+	request := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT", Idempotent: &idempotent})
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.EncodeFrame(request, encoded))
+	decoded, err := codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+	assert.False(t, decoded.Header.Flags.Contains(primitive.HeaderFlagCustomPayload))
+	assert.Nil(t, decoded.Body.Message.(*message.Query).Idempotent)
+	// the payload is not interpreted either
+	request = NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT"})
+	request.SetCustomPayload(map[string][]byte{message.IdempotencePayloadKey: {1}})
+	encoded.Reset()
+	require.NoError(t, codec.EncodeFrame(request, encoded))
+	decoded, err = codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+	assert.Nil(t, decoded.Body.Message.(*message.Query).Idempotent)
+}
+
+func TestFrameEncodeDecode_IdempotenceErrors(t *testing.T) {
+	idempotent := true
+	codec := NewCodecBuilder().WithIdempotencePayload().Build()
+	// custom payloads are not supported in protocol version 3
+	request := NewFrame(primitive.ProtocolVersion3, 1, &message.Query{Query: "SELECT", Idempotent: &idempotent})
+	err := codec.EncodeFrame(request, &bytes.Buffer{})
+	assert.EqualError(t, err, "cannot encode idempotence: custom payloads are not supported in protocol version ProtocolVersion OSS 3")
+	_, err = codec.ConvertToRawFrame(request)
+	assert.Error(t, err)
+	// the CUSTOM_PAYLOAD flag must be set in the header when encoding the body separately
+	request = NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT", Idempotent: &idempotent})
+	err = codec.EncodeBody(request.Header, request.Body, &bytes.Buffer{})
+	assert.EqualError(t, err, "cannot encode idempotence: the CUSTOM_PAYLOAD flag is not set in the header")
+	// invalid payload values and messages that cannot carry idempotence are ignored
+	for _, msg := range []message.Message{&message.Query{Query: "SELECT", Options: &message.QueryOptions{}}, &message.Prepare{Query: "SELECT"}} {
+		request = NewFrame(primitive.ProtocolVersion4, 1, msg)
+		request.SetCustomPayload(map[string][]byte{message.IdempotencePayloadKey: {1, 2}})
+		encoded := &bytes.Buffer{}
+		require.NoError(t, codec.EncodeFrame(request, encoded))
+		decoded, err := codec.DecodeFrame(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, msg, decoded.Body.Message)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// JSON representation of frames: a frame is represented by an object with the exported fields of Frame, Header and
+// Body, e.g. {"Header":{"IsResponse":false,"Version":4,"Flags":[],"StreamId":1,"OpCode":7},"Body":{"Message":{...}}}.
+// The message is represented as described in message.MarshalMessageJSON, with a type discriminator, so that frames
+// can be decoded back with their concrete message types. When decoding, the header's BodyLength is ignored, and its
+// IsResponse and OpCode fields can be omitted, in which case they are inferred from the message; this makes
+// hand-written fixtures shorter. The YAML representation of frames is the same as the JSON one.
+
+type jsonBody struct {
+	TracingId     *primitive.UUID   `json:",omitempty"`
+	CustomPayload map[string][]byte `json:",omitempty"`
+	Warnings      []string          `json:",omitempty"`
+	Message       json.RawMessage   `json:",omitempty"`
+	Anomalies     []message.Anomaly `json:",omitempty"`
+}
+
+type jsonHeader struct {
+	IsResponse *bool
+	Version    primitive.ProtocolVersion
+	Flags      primitive.HeaderFlag
+	StreamId   int16
+	OpCode     *primitive.OpCode
+}
+
+type jsonFrame struct {
+	Header *jsonHeader
+	Body   *Body
+}
+
+// MarshalJSON encodes this body to its JSON representation, including the type discriminator of its message.
+func (b *Body) MarshalJSON() ([]byte, error) {
+	msg, err := message.MarshalMessageJSON(b.Message)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&jsonBody{
+		TracingId:     b.TracingId,
+		CustomPayload: b.CustomPayload,
+		Warnings:      b.Warnings,
+		Message:       msg,
+		Anomalies:     b.Anomalies,
+	})
+}
+
+// UnmarshalJSON decodes this body from its JSON representation, as produced by MarshalJSON.
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var decoded jsonBody
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("cannot decode frame body: %w", err)
+	}
+	msg, err := message.UnmarshalMessageJSON(decoded.Message)
+	if err != nil {
+		return fmt.Errorf("cannot decode frame body: %w", err)
+	}
+	*b = Body{
+		TracingId:     decoded.TracingId,
+		CustomPayload: decoded.CustomPayload,
+		Warnings:      decoded.Warnings,
+		Message:       msg,
+		Anomalies:     decoded.Anomalies,
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes this frame from its JSON representation, as produced by json.Marshal. The header's
+// IsResponse and OpCode fields are inferred from the message when omitted.
+func (f *Frame) UnmarshalJSON(data []byte) error {
+	var decoded jsonFrame
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	} else if decoded.Header == nil {
+		return fmt.Errorf("cannot decode frame: missing header")
+	} else if decoded.Body == nil || decoded.Body.Message == nil {
+		return fmt.Errorf("cannot decode frame: missing body message")
+	}
+	msg := decoded.Body.Message
+	header := &Header{
+		IsResponse: msg.IsResponse(),
+		Version:    decoded.Header.Version,
+		Flags:      decoded.Header.Flags,
+		StreamId:   decoded.Header.StreamId,
+		OpCode:     msg.GetOpCode(),
+	}
+	if decoded.Header.IsResponse != nil {
+		header.IsResponse = *decoded.Header.IsResponse
+	}
+	if decoded.Header.OpCode != nil {
+		header.OpCode = *decoded.Header.OpCode
+	}
+	*f = Frame{Header: header, Body: decoded.Body}
+	return nil
+}
+
+// MarshalYAML encodes this frame to its YAML representation, which is the same as its JSON representation.
+func (f *Frame) MarshalYAML() (interface{}, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	// JSON is a subset of YAML
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	return unflow(node.Content[0]), nil
+}
+
+// UnmarshalYAML decodes this frame from its YAML representation, as produced by MarshalYAML.
+func (f *Frame) UnmarshalYAML(value *yaml.Node) error {
+	var decoded interface{}
+	if err := value.Decode(&decoded); err != nil {
+		return err
+	}
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		return fmt.Errorf("cannot decode frame: %w", err)
+	}
+	return f.UnmarshalJSON(data)
+}
+
+// unflow switches the given node, obtained by parsing JSON, and its children to the block style, and removes the
+// quotes around strings, when possible.
+func unflow(node *yaml.Node) *yaml.Node {
+	node.Style = 0
+	for _, child := range node.Content {
+		unflow(child)
+	}
+	return node
+}
+
+// ParseFramesJSON decodes frames from the given JSON array, e.g. a file-based test fixture.
+func ParseFramesJSON(data []byte) ([]*Frame, error) {
+	var frames []*Frame
+	if err := json.Unmarshal(bytes.TrimSpace(data), &frames); err != nil {
+		return nil, fmt.Errorf("cannot decode frames: %w", err)
+	}
+	return frames, nil
+}
+
+// ParseFramesYAML decodes frames from the given YAML sequence, e.g. a file-based test fixture.
+func ParseFramesYAML(data []byte) ([]*Frame, error) {
+	var frames []*Frame
+	if err := yaml.Unmarshal(data, &frames); err != nil {
+		return nil, fmt.Errorf("cannot decode frames: %w", err)
+	}
+	return frames, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func jsonTestFrames() []*Frame {
+	timestamp := int64(1 << 60)
+	query := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{
+		Query: "SELECT * FROM ks1.table1 WHERE pk = ?",
+		Options: &message.QueryOptions{
+			Consistency:      primitive.ConsistencyLevelLocalQuorum,
+			PositionalValues: []*primitive.Value{primitive.NewValue([]byte{0, 0, 0, 1})},
+			DefaultTimestamp: &timestamp,
+		},
+	})
+	query.SetCustomPayload(map[string][]byte{"key": {0xca, 0xfe}})
+	rows := NewFrame(primitive.ProtocolVersion4, 1, &message.RowsResult{
+		Metadata: &message.RowsMetadata{
+			ColumnCount: 1,
+			Columns: []*message.ColumnMetadata{
+				{Keyspace: "ks1", Table: "table1", Name: "c1", Type: datatype.NewMap(datatype.Varchar, datatype.Int)},
+			},
+		},
+		Data: message.RowSet{{message.Column{1, 2, 3}}},
+	})
+	rows.SetTracingId(&primitive.UUID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	rows.SetWarnings([]string{"warning"})
+	event := NewFrame(primitive.ProtocolVersion5, -1, &message.SchemaChangeEvent{
+		ChangeType: primitive.SchemaChangeTypeCreated,
+		Target:     primitive.SchemaChangeTargetKeyspace,
+		Keyspace:   "ks1",
+	})
+	// unknown enum values, e.g. decoded by lenient codecs, are marshaled in hexadecimal
+	batch := NewFrame(primitive.ProtocolVersion4, 2, &message.Batch{
+		Type:        primitive.BatchType(0x0F),
+		Children:    []*message.BatchChild{{Query: "INSERT INTO ks1.table1 (pk) VALUES (1)"}},
+		Consistency: primitive.ConsistencyLevel(0x00FF),
+	})
+	return []*Frame{query, rows, event, batch}
+}
+
+func TestFrameJSON_RoundTrip(t *testing.T) {
+	for _, f := range jsonTestFrames() {
+		t.Run(f.String(), func(t *testing.T) {
+			data, err := json.Marshal(f)
+			require.NoError(t, err)
+			var decoded Frame
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, f, &decoded)
+		})
+	}
+}
+
+func TestFrameYAML_RoundTrip(t *testing.T) {
+	frames := jsonTestFrames()
+	data, err := yaml.Marshal(frames)
+	require.NoError(t, err)
+	decoded, err := ParseFramesYAML(data)
+	require.NoError(t, err)
+	assert.Equal(t, frames, decoded)
+}
+
+func TestParseFramesJSON(t *testing.T) {
+	// IsResponse and OpCode are inferred from the messages
+	frames, err := ParseFramesJSON([]byte(`[
+		{"Header": {"Version": 4, "StreamId": 1}, "Body": {"Message": {"messageType": "Options"}}},
+		{"Header": {"Version": 4, "StreamId": 1}, "Body": {"Message": {"messageType": "Supported", "Options": {"CQL_VERSION": ["3.4.5"]}}}}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, []*Frame{
+		NewFrame(primitive.ProtocolVersion4, 1, &message.Options{}),
+		NewFrame(primitive.ProtocolVersion4, 1, &message.Supported{Options: map[string][]string{"CQL_VERSION": {"3.4.5"}}}),
+	}, frames)
+	_, err = ParseFramesJSON([]byte(`[{"Body": {"Message": {"messageType": "Options"}}}]`))
+	assert.EqualError(t, err, "cannot decode frames: cannot decode frame: missing header")
+	_, err = ParseFramesJSON([]byte(`[{"Header": {"Version": 4}, "Body": {}}]`))
+	assert.EqualError(t, err, "cannot decode frames: cannot decode frame: missing body message")
+	_, err = ParseFramesJSON([]byte(`[{"Header": {"Version": 4}, "Body": {"Message": {"messageType": "Foo"}}}]`))
+	assert.EqualError(t, err, `cannot decode frames: cannot decode frame body: cannot decode message: unknown message type: "Foo"`)
+}
+
+func TestParseFramesYAML(t *testing.T) {
+	frames, err := ParseFramesYAML([]byte(`
+- Header: {Version: 5, StreamId: 2}
+  Body:
+    Message:
+      messageType: Query
+      Query: SELECT * FROM system.local
+      Options: {Consistency: ONE}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []*Frame{NewFrame(primitive.ProtocolVersion5, 2, &message.Query{
+		Query:   "SELECT * FROM system.local",
+		Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevelOne},
+	})}, frames)
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"io"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Limits are upper bounds enforced when decoding frames from untrusted peers, see CodecBuilder.WithLimits. When a
+// limit is exceeded, decoding fails with a *primitive.LimitExceededError, which can be detected with errors.As. Zero
+// means no limit.
+type Limits struct {
+	// MaxBodyLength is the maximum length of frame bodies, as declared in frame headers: oversized frames are rejected
+	// by DecodeHeader, before their body is read. It also bounds the length of decompressed bodies.
+	MaxBodyLength int32
+	// DecodeLimits bound the lengths of strings, bytes and collections read when decoding frame bodies.
+	primitive.DecodeLimits
+}
+
+func (l Limits) checkBodyLength(length int32) error {
+	if l.MaxBodyLength > 0 && length > l.MaxBodyLength {
+		return &primitive.LimitExceededError{Kind: "frame body", Length: int64(length), Limit: int64(l.MaxBodyLength)}
+	}
+	return nil
+}
+
+// limitedWriter fails with a *primitive.LimitExceededError as soon as more than limit bytes are written to it.
+type limitedWriter struct {
+	w       io.Writer
+	written int64
+	limit   int64
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.written+int64(len(p)) > w.limit {
+		return 0, &primitive.LimitExceededError{Kind: "decompressed frame body", Length: w.written + int64(len(p)), Limit: w.limit}
+	}
+	n, err := w.w.Write(p)
+	w.written += int64(n)
+	return n, err
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCodec_Limits(t *testing.T) {
+	query := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{
+		Query: "SELECT * FROM ks.t WHERE k = ?",
+		Options: &message.QueryOptions{
+			Consistency:      primitive.ConsistencyLevelOne,
+			PositionalValues: []*primitive.Value{primitive.NewValue(make([]byte, 100))},
+		},
+	})
+	encoded, err := EncodeToBytes(NewCodec(), query)
+	require.NoError(t, err)
+	tests := []struct {
+		name     string
+		limits   Limits
+		expected *primitive.LimitExceededError
+	}{
+		{"no limits", Limits{}, nil},
+		{"within limits", Limits{
+			MaxBodyLength: int32(len(encoded) - primitive.LengthOfByte*5 - primitive.LengthOfInt),
+			DecodeLimits:  primitive.DecodeLimits{MaxStringLength: 30, MaxBytesLength: 100, MaxCollectionLength: 1},
+		}, nil},
+		{"body", Limits{MaxBodyLength: 100}, &primitive.LimitExceededError{Kind: "frame body", Length: 143, Limit: 100}},
+		{"string", Limits{DecodeLimits: primitive.DecodeLimits{MaxStringLength: 29}},
+			&primitive.LimitExceededError{Kind: "[long string]", Length: 30, Limit: 29}},
+		{"bytes", Limits{DecodeLimits: primitive.DecodeLimits{MaxBytesLength: 99}},
+			&primitive.LimitExceededError{Kind: "[value]", Length: 100, Limit: 99}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeFromBytes(NewCodecBuilder().WithLimits(tt.limits).Build(), encoded)
+			if tt.expected == nil {
+				require.NoError(t, err)
+				assert.Equal(t, query, decoded)
+			} else {
+				var limitErr *primitive.LimitExceededError
+				require.True(t, errors.As(err, &limitErr), err)
+				assert.Equal(t, tt.expected, limitErr)
+			}
+		})
+	}
+}
+
+func TestCodec_Limits_RejectedBeforeBody(t *testing.T) {
+	rawCodec := NewCodecBuilder().WithLimits(Limits{MaxBodyLength: 1024}).Build()
+	// a header declaring a 2 GiB body, and no body at all
+	header := []byte{0x04, 0x00, 0x00, 0x01, byte(primitive.OpCodeQuery), 0x7f, 0xff, 0xff, 0xff}
+	_, err := rawCodec.DecodeFrame(bytes.NewReader(header))
+	assert.EqualError(t, err, "cannot decode frame header: frame body length 2147483647 exceeds limit of 1024")
+	_, err = rawCodec.DecodeRawFrame(bytes.NewReader(header))
+	var limitErr *primitive.LimitExceededError
+	assert.True(t, errors.As(err, &limitErr))
+}
+
+func TestCodec_Limits_Decompressed(t *testing.T) {
+	sb := &strings.Builder{}
+	for i := 0; i < 200; i++ {
+		sb.WriteString("INSERT INTO ks.t (k) VALUES (" + strconv.Itoa(i*7919) + ");")
+	}
+	query := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: sb.String()})
+	query.SetCompress(true)
+	encoded, err := EncodeToBytes(NewCodecBuilder().WithCompressor(lz4.Compressor{}).Build(), query)
+	require.NoError(t, err)
+	compressedLength := int32(len(encoded) - 9)
+	require.Less(t, int(compressedLength), sb.Len())
+	// the compressed body is within the limit, but not the decompressed one
+	codec := NewCodecBuilder().
+		WithCompressor(lz4.Compressor{}).
+		WithLimits(Limits{MaxBodyLength: compressedLength}).
+		Build()
+	_, err = DecodeFromBytes(codec, encoded)
+	var limitErr *primitive.LimitExceededError
+	require.True(t, errors.As(err, &limitErr), err)
+	assert.Equal(t, "decompressed frame body", limitErr.Kind)
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// AnomalyKind is the category of an Anomaly.
+type AnomalyKind string
+
+const (
+	// AnomalyEmptyQueryString is reported when a QUERY, PREPARE or BATCH request contains an empty query string.
+	AnomalyEmptyQueryString = AnomalyKind("empty query string")
+	// AnomalyUnknownEnumValue is reported when an enumerated value, such as a consistency level, is unknown.
+	AnomalyUnknownEnumValue = AnomalyKind("unknown enum value")
+	// AnomalyTrailingBytes is reported when a frame body contains bytes after the end of its message.
+	AnomalyTrailingBytes = AnomalyKind("trailing bytes")
+	// AnomalyUnexpectedFlags is reported when unknown flags are set, or flags that are not valid in the context.
+	AnomalyUnexpectedFlags = AnomalyKind("unexpected flags")
+	// AnomalyUnknownOpCode is reported when a frame has an opcode that the frame codec does not know; in lenient
+	// decoding mode, its body is decoded as a RawMessage.
+	AnomalyUnknownOpCode = AnomalyKind("unknown opcode")
+)
+
+// Anomaly is a deviation from the protocol specification found when decoding, that could be tolerated.
+type Anomaly struct {
+	Kind    AnomalyKind
+	Message string
+}
+
+func (a Anomaly) String() string {
+	return fmt.Sprintf("%v: %v", a.Kind, a.Message)
+}
+
+// AnomalyError is the error returned when an Anomaly is not tolerated, e.g. by a frame codec in strict decoding mode.
+type AnomalyError struct {
+	Anomaly Anomaly
+}
+
+func (e *AnomalyError) Error() string {
+	return e.Anomaly.String()
+}
+
+// AnomalyHandler is invoked with each anomaly found while decoding. If it returns an error, decoding fails with that
+// error; otherwise, the anomaly is tolerated and decoding proceeds.
+type AnomalyHandler func(anomaly Anomaly) error
+
+// StrictAnomalyHandler is an AnomalyHandler that does not tolerate any anomaly, and returns an *AnomalyError.
+func StrictAnomalyHandler(anomaly Anomaly) error {
+	return &AnomalyError{Anomaly: anomaly}
+}
+
+// AnomalyDecoder is implemented by message codecs able to tolerate anomalies that would otherwise make Decode fail,
+// such as unknown consistency levels. Frame codecs use it when available, see frame.DecodingMode.
+type AnomalyDecoder interface {
+	// DecodeWithAnomalies decodes a message like Decode does, but invokes the given handler with the anomalies found,
+	// instead of failing.
+	DecodeWithAnomalies(source io.Reader, version primitive.ProtocolVersion, onAnomaly AnomalyHandler) (Message, error)
+}
+
+// FindAnomalies inspects a decoded message and returns the anomalies that could not be detected when decoding it, such
+// as empty query strings.
+func FindAnomalies(msg Message) []Anomaly {
+	var anomalies []Anomaly
+	switch msg := msg.(type) {
+	case *Query:
+		if msg.Query == "" {
+			anomalies = append(anomalies, Anomaly{AnomalyEmptyQueryString, "QUERY query string is empty"})
+		}
+	case *Prepare:
+		if msg.Query == "" {
+			anomalies = append(anomalies, Anomaly{AnomalyEmptyQueryString, "PREPARE query string is empty"})
+		}
+	case *Batch:
+		for i, child := range msg.Children {
+			if child.Id == nil && child.Query == "" {
+				anomalies = append(anomalies, Anomaly{
+					AnomalyEmptyQueryString,
+					fmt.Sprintf("BATCH child %d query string is empty", i),
+				})
+			}
+		}
+		if !msg.Consistency.IsValid() {
+			anomalies = append(anomalies, unknownConsistencyAnomaly("BATCH consistency", msg.Consistency))
+		}
+		if msg.SerialConsistency != nil && !msg.SerialConsistency.IsValid() {
+			anomalies = append(anomalies, unknownConsistencyAnomaly("BATCH serial consistency", *msg.SerialConsistency))
+		}
+	}
+	return anomalies
+}
+
+func unknownConsistencyAnomaly(name string, consistency primitive.ConsistencyLevel) Anomaly {
+	return Anomaly{AnomalyUnknownEnumValue, fmt.Sprintf("%v is unknown: %v", name, consistency)}
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"sync"
+	"time"
+)
+
+// AnomalyWarner is invoked by an AnomalyReporter to emit a warning about the given anomaly; suppressed is the number
+// of anomalies of the same kind that were not reported since the previous warning, because of throttling.
+type AnomalyWarner func(anomaly Anomaly, suppressed int64)
+
+// AnomalyReporter counts the anomalies found while decoding, and emits throttled warnings about them, so that
+// long-running proxies can detect protocol drift without flooding their logs: at most one warning is emitted per
+// anomaly kind and interval, in the manner of Apache Cassandra's NoSpamLogger. Anomalies are reported regardless of
+// whether they are tolerated. To enable it, see frame.CodecBuilder.WithAnomalyReporter, or wrap an AnomalyHandler
+// with Handler. An AnomalyReporter is safe for concurrent use.
+type AnomalyReporter struct {
+	interval   time.Duration
+	warn       AnomalyWarner
+	counts     map[AnomalyKind]int64
+	lastWarned map[AnomalyKind]time.Time
+	suppressed map[AnomalyKind]int64
+	now        func() time.Time
+	lock       sync.Mutex
+}
+
+// NewAnomalyReporter creates a new AnomalyReporter invoking the given warner at most once per anomaly kind and
+// interval; a zero interval disables throttling, and a nil warner disables warnings, in which case anomalies are only
+// counted.
+func NewAnomalyReporter(interval time.Duration, warn AnomalyWarner) *AnomalyReporter {
+	return &AnomalyReporter{
+		interval:   interval,
+		warn:       warn,
+		counts:     make(map[AnomalyKind]int64),
+		lastWarned: make(map[AnomalyKind]time.Time),
+		suppressed: make(map[AnomalyKind]int64),
+		now:        time.Now,
+	}
+}
+
+// Report counts the given anomaly, and emits a warning about it, unless a warning was already emitted for the same
+// kind of anomaly less than an interval ago.
+func (r *AnomalyReporter) Report(anomaly Anomaly) {
+	r.lock.Lock()
+	r.counts[anomaly.Kind]++
+	if r.warn == nil {
+		r.lock.Unlock()
+		return
+	}
+	now := r.now()
+	if last, found := r.lastWarned[anomaly.Kind]; found && r.interval > 0 && now.Sub(last) < r.interval {
+		r.suppressed[anomaly.Kind]++
+		r.lock.Unlock()
+		return
+	}
+	suppressed := r.suppressed[anomaly.Kind]
+	r.lastWarned[anomaly.Kind] = now
+	r.suppressed[anomaly.Kind] = 0
+	r.lock.Unlock()
+	r.warn(anomaly, suppressed)
+}
+
+// Counts returns the number of anomalies reported so far, per kind.
+func (r *AnomalyReporter) Counts() map[AnomalyKind]int64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	counts := make(map[AnomalyKind]int64, len(r.counts))
+	for kind, count := range r.counts {
+		counts[kind] = count
+	}
+	return counts
+}
+
+// Handler returns an AnomalyHandler that reports each anomaly, then invokes the given handler; if the given handler
+// is nil, anomalies are tolerated.
+func (r *AnomalyReporter) Handler(handler AnomalyHandler) AnomalyHandler {
+	return func(anomaly Anomaly) error {
+		r.Report(anomaly)
+		if handler == nil {
+			return nil
+		}
+		return handler(anomaly)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnomalyReporter(test *testing.T) {
+	type warning struct {
+		anomaly    Anomaly
+		suppressed int64
+	}
+	var warnings []warning
+	reporter := NewAnomalyReporter(time.Minute, func(anomaly Anomaly, suppressed int64) {
+		warnings = append(warnings, warning{anomaly, suppressed})
+	})
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	reporter.now = func() time.Time { return now }
+	flags1 := Anomaly{AnomalyUnexpectedFlags, "unknown query flags: 0x00000100"}
+	flags2 := Anomaly{AnomalyUnexpectedFlags, "unknown query flags: 0x00000200"}
+	trailing := Anomaly{AnomalyTrailingBytes, "3 bytes after the end of OpCode QUERY [0x07] message"}
+
+	reporter.Report(flags1)
+	reporter.Report(flags2)
+	reporter.Report(trailing)
+	now = now.Add(30 * time.Second)
+	reporter.Report(flags1)
+	assert.Equal(test, []warning{{flags1, 0}, {trailing, 0}}, warnings)
+	now = now.Add(30 * time.Second)
+	reporter.Report(flags2)
+	assert.Equal(test, []warning{{flags1, 0}, {trailing, 0}, {flags2, 2}}, warnings)
+	assert.Equal(test, map[AnomalyKind]int64{AnomalyUnexpectedFlags: 4, AnomalyTrailingBytes: 1}, reporter.Counts())
+
+	test.Run("no throttling", func(t *testing.T) {
+		count := 0
+		reporter := NewAnomalyReporter(0, func(Anomaly, int64) { count++ })
+		reporter.Report(flags1)
+		reporter.Report(flags1)
+		assert.Equal(t, 2, count)
+	})
+
+	test.Run("no warner", func(t *testing.T) {
+		reporter := NewAnomalyReporter(time.Minute, nil)
+		reporter.Report(flags1)
+		assert.Equal(t, map[AnomalyKind]int64{AnomalyUnexpectedFlags: 1}, reporter.Counts())
+	})
+
+	test.Run("handler", func(t *testing.T) {
+		reporter := NewAnomalyReporter(time.Minute, nil)
+		assert.NoError(t, reporter.Handler(nil)(flags1))
+		err := reporter.Handler(StrictAnomalyHandler)(flags1)
+		var anomalyErr *AnomalyError
+		assert.True(t, errors.As(err, &anomalyErr))
+		assert.Equal(t, map[AnomalyKind]int64{AnomalyUnexpectedFlags: 2}, reporter.Counts())
+	})
+}
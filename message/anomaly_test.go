@@ -0,0 +1,119 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestFindAnomalies(test *testing.T) {
+	invalid := primitive.ConsistencyLevel(0x42)
+	tests := []struct {
+		name     string
+		msg      Message
+		expected []Anomaly
+	}{
+		{"valid query", &Query{Query: "SELECT"}, nil},
+		{"empty query", &Query{}, []Anomaly{{AnomalyEmptyQueryString, "QUERY query string is empty"}}},
+		{"empty prepare", &Prepare{}, []Anomaly{{AnomalyEmptyQueryString, "PREPARE query string is empty"}}},
+		{"batch", &Batch{
+			Children:          []*BatchChild{{Query: "INSERT"}, {Id: []byte{1}}, {Query: ""}},
+			Consistency:       invalid,
+			SerialConsistency: &invalid,
+		}, []Anomaly{
+			{AnomalyEmptyQueryString, "BATCH child 2 query string is empty"},
+			{AnomalyUnknownEnumValue, "BATCH consistency is unknown: " + invalid.String()},
+			{AnomalyUnknownEnumValue, "BATCH serial consistency is unknown: " + invalid.String()},
+		}},
+		{"other", &Options{}, nil},
+	}
+	for _, tt := range tests {
+		test.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FindAnomalies(tt.msg))
+		})
+	}
+}
+
+func TestExecuteCodec_DecodeWithAnomalies(test *testing.T) {
+	encoded := &bytes.Buffer{}
+	require.NoError(test, primitive.WriteShortBytes([]byte{1}, encoded))
+	require.NoError(test, primitive.WriteShort(0x42, encoded))
+	require.NoError(test, primitive.WriteByte(byte(primitive.QueryFlagSerialConsistency), encoded))
+	require.NoError(test, primitive.WriteShort(0x43, encoded))
+	codec := &executeCodec{}
+
+	_, err := codec.Decode(bytes.NewReader(encoded.Bytes()), primitive.ProtocolVersion4)
+	assert.EqualError(test, err, "cannot read EXECUTE query options: invalid consistency level: "+primitive.ConsistencyLevel(0x42).String())
+
+	var anomalies []Anomaly
+	msg, err := codec.DecodeWithAnomalies(bytes.NewReader(encoded.Bytes()), primitive.ProtocolVersion4, func(anomaly Anomaly) error {
+		anomalies = append(anomalies, anomaly)
+		return nil
+	})
+	require.NoError(test, err)
+	serial := primitive.ConsistencyLevel(0x43)
+	assert.Equal(test, &Execute{
+		QueryId: []byte{1},
+		Options: &QueryOptions{Consistency: primitive.ConsistencyLevel(0x42), SerialConsistency: &serial},
+	}, msg)
+	assert.Equal(test, []Anomaly{
+		{AnomalyUnknownEnumValue, "consistency is unknown: " + primitive.ConsistencyLevel(0x42).String()},
+		{AnomalyUnknownEnumValue, "serial consistency is unknown: " + serial.String()},
+	}, anomalies)
+
+	_, err = codec.DecodeWithAnomalies(bytes.NewReader(encoded.Bytes()), primitive.ProtocolVersion4, StrictAnomalyHandler)
+	assert.EqualError(test, err, "cannot read EXECUTE query options: unknown enum value: consistency is unknown: "+primitive.ConsistencyLevel(0x42).String())
+}
+
+func TestQueryCodec_DecodeWithAnomalies_UnknownFlags(test *testing.T) {
+	encoded := &bytes.Buffer{}
+	require.NoError(test, primitive.WriteLongString("SELECT", encoded))
+	require.NoError(test, primitive.WriteShort(uint16(primitive.ConsistencyLevelOne), encoded))
+	require.NoError(test, primitive.WriteInt(int32(primitive.QueryFlagPageSize)|0x00010000, encoded))
+	require.NoError(test, primitive.WriteInt(100, encoded))
+	codec := &queryCodec{}
+
+	expected := &Query{
+		Query:   "SELECT",
+		Options: &QueryOptions{Consistency: primitive.ConsistencyLevelOne, PageSize: 100, RawFlags: 0x00010000},
+	}
+	msg, err := codec.Decode(bytes.NewReader(encoded.Bytes()), primitive.ProtocolVersion5)
+	require.NoError(test, err)
+	assert.Equal(test, expected, msg)
+
+	_, err = codec.DecodeWithAnomalies(bytes.NewReader(encoded.Bytes()), primitive.ProtocolVersion5, StrictAnomalyHandler)
+	assert.EqualError(test, err, "unexpected flags: unknown query flags: 0x00010000")
+
+	var anomalies []Anomaly
+	msg, err = codec.DecodeWithAnomalies(bytes.NewReader(encoded.Bytes()), primitive.ProtocolVersion5, func(anomaly Anomaly) error {
+		anomalies = append(anomalies, anomaly)
+		return nil
+	})
+	require.NoError(test, err)
+	assert.Equal(test, expected, msg)
+	assert.Equal(test, []Anomaly{{AnomalyUnexpectedFlags, "unknown query flags: 0x00010000"}}, anomalies)
+
+	// unknown bits are passed through, known ones are computed from the other fields
+	msg.(*Query).Options.RawFlags |= primitive.QueryFlagSkipMetadata
+	reencoded := &bytes.Buffer{}
+	require.NoError(test, codec.Encode(msg, reencoded, primitive.ProtocolVersion5))
+	assert.Equal(test, encoded.Bytes(), reencoded.Bytes())
+}
@@ -15,6 +15,7 @@
 package message
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -49,6 +50,9 @@ type Batch struct {
 	Keyspace string
 	// Introduced in Protocol Version 5, not present in DSE protocol versions.
 	NowInSeconds *int32
+	// Idempotent tells whether the batch is idempotent, or is nil if unspecified. It is not part of the BATCH message
+	// itself, but is carried in the custom payload of the enclosing frame; see IdempotencePayloadKey.
+	Idempotent *bool
 }
 
 func (m *Batch) IsResponse() bool {
@@ -78,24 +82,70 @@ func (m *Batch) Flags() primitive.QueryFlag {
 	if m.NowInSeconds != nil {
 		flags = flags.Add(primitive.QueryFlagNowInSeconds)
 	}
-	// Note: the named values flag is in theory possible, but server-side implementation is
-	// broken. See https://issues.apache.org/jira/browse/CASSANDRA-10246
+	// Note: named values are only supported by lenient codecs, see NewLenientBatchCodec.
+	if m.hasNamedValues() {
+		flags = flags.Add(primitive.QueryFlagValueNames)
+	}
 	return flags
 }
 
+func (m *Batch) hasNamedValues() bool {
+	for _, child := range m.Children {
+		if child.NamedValues != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // BatchChild represents a BATCH child statement.
 // +k8s:deepcopy-gen=true
 type BatchChild struct {
 	// The CQL statement to execute. Exactly one of Query or Id must be present, never both.
 	Query string
 	// The prepared id of the statement to execute. Exactly one of Query or Id must be present, never both.
-	Id []byte
-	// Note: named values are in theory possible, but their server-side implementation is
-	// broken. See https://issues.apache.org/jira/browse/CASSANDRA-10246
+	Id PreparedId
+	// The positional values of the statement. At most one of Values or NamedValues can be present.
 	Values []*primitive.Value
+	// The named values of the statement. At most one of Values or NamedValues can be present. Named values in batch
+	// children are allowed by the protocol specs, but their server-side implementation is broken, see
+	// https://issues.apache.org/jira/browse/CASSANDRA-10246. Since the named values flag applies to the whole batch,
+	// either all children use named values, or none of them has any values.
+	// Named values are only supported by lenient codecs, see NewLenientBatchCodec; the default codec rejects them.
+	NamedValues map[string]*primitive.Value
 }
 
-type batchCodec struct{}
+var errBatchNamedValues = errors.New("cannot use BATCH with named values, see CASSANDRA-10246")
+
+// NewLenientBatchCodec returns a BATCH codec that encodes and decodes named values in batch children, see
+// BatchChild.NamedValues, instead of failing. This is useful e.g. for proxies that must faithfully relay frames
+// produced by drivers using this corner of the protocol specs. The returned codec can be passed to frame.NewCodec to
+// override the default BATCH codec. Since the named values flag comes after the children, the returned codec reads
+// the whole source when decoding.
+func NewLenientBatchCodec() Codec {
+	return &batchCodec{lenient: true}
+}
+
+type batchCodec struct {
+	lenient bool
+}
+
+// checkNamedValues checks whether the given batch can be written with named values.
+func (c *batchCodec) checkNamedValues(batch *Batch, version primitive.ProtocolVersion) error {
+	if !batch.hasNamedValues() {
+		return nil
+	} else if !c.lenient {
+		return errBatchNamedValues
+	} else if !version.SupportsBatchQueryFlags() {
+		return fmt.Errorf("BATCH named values are not supported in protocol version %v", version)
+	}
+	for i, child := range batch.Children {
+		if len(child.Values) > 0 {
+			return fmt.Errorf("cannot mix BATCH named and positional values: child #%d has positional values", i)
+		}
+	}
+	return nil
+}
 
 func (c *batchCodec) Encode(msg Message, dest io.Writer, version primitive.ProtocolVersion) (err error) {
 	batch, ok := msg.(*Batch)
@@ -104,6 +154,8 @@ func (c *batchCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 	}
 	if err = primitive.CheckValidBatchType(batch.Type); err != nil {
 		return err
+	} else if err = c.checkNamedValues(batch, version); err != nil {
+		return err
 	} else if err = primitive.WriteByte(uint8(batch.Type), dest); err != nil {
 		return fmt.Errorf("cannot write BATCH type: %w", err)
 	}
@@ -131,7 +183,11 @@ func (c *batchCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 				return fmt.Errorf("cannot write BATCH query id for child #%d: %w", i, err)
 			}
 		}
-		if err = primitive.WritePositionalValues(child.Values, dest, version); err != nil {
+		if batch.hasNamedValues() {
+			if err = primitive.WriteNamedValues(child.NamedValues, dest, version); err != nil {
+				return fmt.Errorf("cannot write BATCH named values for child #%d: %w", i, err)
+			}
+		} else if err = primitive.WritePositionalValues(child.Values, dest, version); err != nil {
 			return fmt.Errorf("cannot write BATCH positional values for child #%d: %w", i, err)
 		}
 	}
@@ -183,6 +239,7 @@ func (c *batchCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 	if childrenCount > 0xFFFF {
 		return -1, errors.New(fmt.Sprintf("BATCH messages can contain at most %d queries", 0xFFFF))
 	}
+	namedValues := batch.hasNamedValues()
 	length += primitive.LengthOfByte  // type
 	length += primitive.LengthOfShort // number of queries
 	for i, child := range batch.Children {
@@ -192,7 +249,13 @@ func (c *batchCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 		} else {
 			length += primitive.LengthOfShortBytes(child.Id)
 		}
-		if valuesLength, err := primitive.LengthOfPositionalValues(child.Values); err != nil {
+		if namedValues {
+			if valuesLength, err := primitive.LengthOfNamedValues(child.NamedValues); err != nil {
+				return -1, fmt.Errorf("cannot compute length of BATCH named values for child #%d: %w", i, err)
+			} else {
+				length += valuesLength
+			}
+		} else if valuesLength, err := primitive.LengthOfPositionalValues(child.Values); err != nil {
 			return -1, fmt.Errorf("cannot compute length of BATCH positional values for child #%d: %w", i, err)
 		} else {
 			length += valuesLength
@@ -224,6 +287,27 @@ func (c *batchCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 }
 
 func (c *batchCodec) Decode(source io.Reader, version primitive.ProtocolVersion) (msg Message, err error) {
+	if !c.lenient {
+		return c.decode(source, version, false)
+	}
+	// The named values flag comes after the children, so the whole message is buffered, in order to decode the
+	// children again with named values if the flag turns out to be set.
+	data, err := io.ReadAll(source)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read BATCH message: %w", err)
+	}
+	limits := primitive.DecodeLimitsOf(source)
+	if msg, err = c.decode(primitive.WithDecodeLimits(bytes.NewReader(data), limits), version, false); err != nil && version.SupportsBatchQueryFlags() {
+		if named, namedErr := c.decode(primitive.WithDecodeLimits(bytes.NewReader(data), limits), version, true); namedErr == nil {
+			return named, nil
+		}
+	}
+	return msg, err
+}
+
+// decode decodes a BATCH message, reading children values as named values if namedValues is true; it fails if the
+// named values flag does not match namedValues.
+func (c *batchCodec) decode(source io.Reader, version primitive.ProtocolVersion, namedValues bool) (msg Message, err error) {
 	batch := &Batch{}
 	var batchType uint8
 	if batchType, err = primitive.ReadByte(source); err != nil {
@@ -236,6 +320,8 @@ func (c *batchCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 	var childrenCount uint16
 	if childrenCount, err = primitive.ReadShort(source); err != nil {
 		return nil, fmt.Errorf("cannot read BATCH query count: %w", err)
+	} else if err = primitive.CheckCollectionLength(source, "BATCH children", int(childrenCount)); err != nil {
+		return nil, err
 	}
 	batch.Children = make([]*BatchChild, childrenCount)
 	for i := 0; i < int(childrenCount); i++ {
@@ -256,7 +342,11 @@ func (c *batchCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 		default:
 			return nil, fmt.Errorf("unsupported BATCH child type for child #%d: %v", i, childType)
 		}
-		if child.Values, err = primitive.ReadPositionalValues(source, version); err != nil {
+		if namedValues {
+			if child.NamedValues, err = primitive.ReadNamedValues(source, version); err != nil {
+				return nil, fmt.Errorf("cannot read BATCH named values for child #%d: %w", i, err)
+			}
+		} else if child.Values, err = primitive.ReadPositionalValues(source, version); err != nil {
 			return nil, fmt.Errorf("cannot read BATCH positional values for child #%d: %w", i, err)
 		}
 		batch.Children[i] = child
@@ -280,8 +370,11 @@ func (c *batchCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 		if err != nil {
 			return nil, fmt.Errorf("cannot read BATCH query flags: %w", err)
 		}
-		if flags.Contains(primitive.QueryFlagValueNames) {
-			return nil, errors.New("cannot use BATCH with named values, see CASSANDRA-10246")
+		if flags.Contains(primitive.QueryFlagValueNames) != namedValues {
+			if namedValues {
+				return nil, errors.New("cannot read BATCH named values: named values flag not set")
+			}
+			return nil, errBatchNamedValues
 		}
 		if flags.Contains(primitive.QueryFlagSerialConsistency) {
 			var batchSerialConsistencyUint uint16
@@ -1371,3 +1371,78 @@ func TestBatchCodec_Decode(t *testing.T) {
 		}
 	})
 }
+
+func TestBatchChild_Clone_NamedValues(t *testing.T) {
+	child := &BatchChild{Query: "query", NamedValues: map[string]*primitive.Value{"a": primitive.NewValue([]byte{1})}}
+	cloned := child.DeepCopy()
+	assert.Equal(t, child, cloned)
+	cloned.NamedValues["a"].Contents[0] = 2
+	assert.Equal(t, []byte{1}, child.NamedValues["a"].Contents)
+}
+
+func TestLenientBatchCodec(t *testing.T) {
+	namedBatch := &Batch{
+		Children: []*BatchChild{
+			{Query: "INSERT", NamedValues: map[string]*primitive.Value{"a": primitive.NewValue([]byte{1, 2, 3, 4})}},
+			{Id: []byte{0xca, 0xfe}},
+		},
+	}
+	namedBatchBytes := []byte{
+		byte(primitive.BatchTypeLogged),
+		0, 2, // children count
+		0,                            // child 1 kind
+		0, 0, 0, 6, I, N, S, E, R, T, // child 1 query
+		0, 1, // child 1 values count
+		0, 1, a, // child 1 value 1 name
+		0, 0, 0, 4, 1, 2, 3, 4, // child 1 value 1
+		1,                // child 2 kind
+		0, 2, 0xca, 0xfe, // child 2 query id
+		0, 0, // child 2 values count
+		0, 0, // consistency level
+		0, 0, 0, 0x40, // flags
+	}
+	version := primitive.ProtocolVersion5
+	t.Run("strict", func(t *testing.T) {
+		codec := &batchCodec{}
+		assert.EqualError(t, codec.Encode(namedBatch, &bytes.Buffer{}, version), "cannot use BATCH with named values, see CASSANDRA-10246")
+		// named values are misread as positional values
+		_, err := codec.Decode(bytes.NewBuffer(namedBatchBytes), version)
+		assert.Error(t, err)
+	})
+	t.Run("lenient", func(t *testing.T) {
+		codec := NewLenientBatchCodec()
+		dest := &bytes.Buffer{}
+		assert.NoError(t, codec.Encode(namedBatch, dest, version))
+		assert.Equal(t, namedBatchBytes, dest.Bytes())
+		length, err := codec.EncodedLength(namedBatch, version)
+		assert.NoError(t, err)
+		assert.Equal(t, len(namedBatchBytes), length)
+		decoded, err := codec.Decode(bytes.NewBuffer(namedBatchBytes), version)
+		assert.NoError(t, err)
+		assert.Equal(t, &Batch{
+			Children: []*BatchChild{
+				{Query: "INSERT", NamedValues: map[string]*primitive.Value{"a": primitive.NewValue([]byte{1, 2, 3, 4})}},
+				{Id: []byte{0xca, 0xfe}, NamedValues: map[string]*primitive.Value{}},
+			},
+		}, decoded)
+	})
+	t.Run("lenient positional", func(t *testing.T) {
+		codec := NewLenientBatchCodec()
+		positional := &Batch{Children: []*BatchChild{{Query: "INSERT", Values: []*primitive.Value{primitive.NewValue([]byte{1})}}}}
+		dest := &bytes.Buffer{}
+		assert.NoError(t, codec.Encode(positional, dest, version))
+		decoded, err := codec.Decode(dest, version)
+		assert.NoError(t, err)
+		assert.Equal(t, positional, decoded)
+	})
+	t.Run("lenient errors", func(t *testing.T) {
+		codec := NewLenientBatchCodec()
+		mixed := namedBatch.DeepCopy()
+		mixed.Children[1].Values = []*primitive.Value{primitive.NewValue([]byte{1})}
+		assert.EqualError(t, codec.Encode(mixed, &bytes.Buffer{}, version), "cannot mix BATCH named and positional values: child #1 has positional values")
+		assert.EqualError(t, codec.Encode(namedBatch, &bytes.Buffer{}, primitive.ProtocolVersion2), "BATCH named values are not supported in protocol version ProtocolVersion OSS 2")
+		// truncated message: the error of the positional attempt is returned
+		_, err := codec.Decode(bytes.NewBuffer(namedBatchBytes[:10]), version)
+		assert.EqualError(t, err, "cannot read BATCH query string for child #0: cannot read [long string] content: unexpected EOF")
+	})
+}
@@ -0,0 +1,481 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ConformanceSample is a message instance used to check the conformance of message codecs, see ConformanceSamples.
+type ConformanceSample struct {
+	// Name identifies the sample, e.g. "QUERY/all options".
+	Name    string
+	Message Message
+}
+
+// ConformanceResult is the result of a conformance round-trip, see ConformanceRoundTrip.
+type ConformanceResult struct {
+	// Name identifies the sample message.
+	Name string
+	// Message is the sample message.
+	Message Message
+	// Encoded contains the encoded sample message; nil if encoding failed.
+	Encoded []byte
+	// Decoded is the decoded message; nil if decoding failed.
+	Decoded Message
+	// ReEncoded contains the re-encoded decoded message; nil if decoding or re-encoding failed.
+	ReEncoded []byte
+	// Err describes the first error or mismatch found, if any.
+	Err error
+}
+
+// OK returns true if the sample message could be encoded, decoded and re-encoded without any mismatch.
+func (r *ConformanceResult) OK() bool {
+	return r.Err == nil
+}
+
+// String returns a human-readable report of this result.
+func (r *ConformanceResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: %v", r.Name, r.Err)
+	}
+	return fmt.Sprintf("%s: OK", r.Name)
+}
+
+// ConformanceRoundTrip encodes the given sample message with the given codec, decodes it, then re-encodes the decoded
+// message. The round-trip fails if any step fails, if EncodedLength does not match the actual encoded length, if
+// decoding does not consume all the encoded bytes, if the decoded message differs from the sample message, or if
+// the re-encoded bytes differ from the encoded ones. For the latter check to be reliable, sample messages should not
+// contain maps with more than one entry, since maps are encoded in random order.
+func ConformanceRoundTrip(codec Codec, sample *ConformanceSample, version primitive.ProtocolVersion) *ConformanceResult {
+	result := &ConformanceResult{Name: sample.Name, Message: sample.Message}
+	if result.Encoded, result.Err = conformanceEncode(codec, sample.Message, version); result.Err != nil {
+		result.Encoded = nil
+		return result
+	}
+	source := bytes.NewReader(result.Encoded)
+	if result.Decoded, result.Err = codec.Decode(source, version); result.Err != nil {
+		result.Decoded = nil
+		result.Err = fmt.Errorf("cannot decode: %w", result.Err)
+		return result
+	} else if source.Len() > 0 {
+		result.Err = fmt.Errorf("%d trailing bytes after decoded message", source.Len())
+		return result
+	} else if !reflect.DeepEqual(sample.Message, result.Decoded) {
+		result.Err = fmt.Errorf("decoded message differs (expected != got):\n%v", Diff(sample.Message, result.Decoded))
+		return result
+	}
+	if result.ReEncoded, result.Err = conformanceEncode(codec, result.Decoded, version); result.Err != nil {
+		result.ReEncoded = nil
+		result.Err = fmt.Errorf("cannot re-encode decoded message: %w", result.Err)
+	} else if !bytes.Equal(result.Encoded, result.ReEncoded) {
+		result.Err = fmt.Errorf("re-encoded bytes differ: expected %x, got %x", result.Encoded, result.ReEncoded)
+	}
+	return result
+}
+
+func conformanceEncode(codec Codec, msg Message, version primitive.ProtocolVersion) ([]byte, error) {
+	dest := &bytes.Buffer{}
+	if err := codec.Encode(msg, dest, version); err != nil {
+		return nil, fmt.Errorf("cannot encode: %w", err)
+	} else if length, err := codec.EncodedLength(msg, version); err != nil {
+		return nil, fmt.Errorf("cannot compute encoded length: %w", err)
+	} else if length != dest.Len() {
+		return nil, fmt.Errorf("wrong encoded length: computed %d, actual %d", length, dest.Len())
+	}
+	return dest.Bytes(), nil
+}
+
+// CheckConformance performs a conformance round-trip, see ConformanceRoundTrip, for each sample message returned by
+// ConformanceSamples for the given version, using the given codecs, e.g. DefaultMessageCodecs along with custom
+// codecs. When several codecs have the same opcode, the last one is used, as frame.CodecBuilder does. Results are
+// returned in the order of the samples; a sample for which no codec is found fails. Use ConformanceResult.OK to find
+// mismatches.
+func CheckConformance(codecs []Codec, version primitive.ProtocolVersion) []*ConformanceResult {
+	codecsByOpCode := make(map[primitive.OpCode]Codec, len(codecs))
+	for _, codec := range codecs {
+		codecsByOpCode[codec.GetOpCode()] = codec
+	}
+	samples := ConformanceSamples(version)
+	results := make([]*ConformanceResult, len(samples))
+	for i, sample := range samples {
+		if codec, found := codecsByOpCode[sample.Message.GetOpCode()]; !found {
+			results[i] = &ConformanceResult{
+				Name:    sample.Name,
+				Message: sample.Message,
+				Err:     fmt.Errorf("no codec found for opcode %v", sample.Message.GetOpCode()),
+			}
+		} else {
+			results[i] = ConformanceRoundTrip(codec, sample, version)
+		}
+	}
+	return results
+}
+
+// DumpCodecs returns a human-readable description of the given codecs, with one line per opcode, in opcode order,
+// giving the Go type of the codec used for that opcode. When several codecs have the same opcode, the last one is
+// used, as frame.CodecBuilder does, and the overridden ones are listed as well.
+func DumpCodecs(codecs []Codec) string {
+	codecsByOpCode := make(map[primitive.OpCode][]Codec, len(codecs))
+	var opCodes []primitive.OpCode
+	for _, codec := range codecs {
+		opCode := codec.GetOpCode()
+		if _, found := codecsByOpCode[opCode]; !found {
+			opCodes = append(opCodes, opCode)
+		}
+		codecsByOpCode[opCode] = append(codecsByOpCode[opCode], codec)
+	}
+	sort.Slice(opCodes, func(i, j int) bool { return opCodes[i] < opCodes[j] })
+	sb := &strings.Builder{}
+	for _, opCode := range opCodes {
+		registered := codecsByOpCode[opCode]
+		_, _ = fmt.Fprintf(sb, "%v: %T", opCode, registered[len(registered)-1])
+		for i := len(registered) - 2; i >= 0; i-- {
+			_, _ = fmt.Fprintf(sb, " (overrides %T)", registered[i])
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// ConformanceSamples returns sample instances of every message type supported by the given version, covering edge
+// cases such as empty strings and byte slices, null, empty and unset values, extreme numeric values, and all the
+// optional fields supported by the version. Every call returns new instances.
+func ConformanceSamples(version primitive.ProtocolVersion) []*ConformanceSample {
+	var samples []*ConformanceSample
+	add := func(name string, msg Message) {
+		samples = append(samples, &ConformanceSample{Name: opCodeName(msg.GetOpCode()) + "/" + name, Message: msg})
+	}
+	add("default", NewStartup())
+	add("empty", &Startup{Options: map[string]string{}})
+	add("custom option", &Startup{Options: map[string]string{"CUSTOM_KEY": ""}})
+	add("default", &Options{})
+	add("default", &Ready{})
+	add("default", &Authenticate{Authenticator: "org.apache.cassandra.auth.PasswordAuthenticator"})
+	add("empty", &Supported{Options: map[string][]string{}})
+	add("all options", &Supported{
+		Options: map[string][]string{
+			StartupOptionCqlVersion:  {"3.4.5"},
+			StartupOptionCompression: {},
+			"CUSTOM_KEY":             {"", "Μιλάτε αγγλικά;"},
+		},
+		OptionKeys: []string{StartupOptionCqlVersion, StartupOptionCompression, "CUSTOM_KEY"},
+	})
+	add("all event types", &Register{EventTypes: []primitive.EventType{
+		primitive.EventTypeTopologyChange,
+		primitive.EventTypeStatusChange,
+		primitive.EventTypeSchemaChange,
+	}})
+	for _, token := range [][]byte{nil, {}, {0xca, 0xfe}} {
+		name := conformanceBytesName(token)
+		add(name, &AuthResponse{Token: token})
+		add(name, &AuthChallenge{Token: token})
+		add(name, &AuthSuccess{Token: token})
+	}
+	add("empty query", &Query{Query: "", Options: &QueryOptions{}})
+	add("default options", &Query{Query: "SELECT * FROM system.local", Options: &QueryOptions{}})
+	add("all options", &Query{Query: "SELECT * FROM ks.t WHERE k = ?", Options: conformanceQueryOptions(version)})
+	add("named values", &Query{
+		Query: "SELECT * FROM ks.t WHERE k = :k",
+		Options: &QueryOptions{
+			Consistency: primitive.ConsistencyLevelAll,
+			NamedValues: map[string]*primitive.Value{"k": primitive.NewValue([]byte{1})},
+		},
+	})
+	add("default", &Prepare{Query: "SELECT * FROM ks.t WHERE k = ?"})
+	if version.SupportsPrepareFlags() {
+		add("keyspace", &Prepare{Query: "SELECT * FROM t WHERE k = ?", Keyspace: "ks"})
+	}
+	execute := &Execute{QueryId: []byte{0xca, 0xfe}, Options: &QueryOptions{}}
+	if version.SupportsResultMetadataId() {
+		execute.ResultMetadataId = []byte{0xba, 0xbe}
+	}
+	add("default options", execute)
+	execute = &Execute{QueryId: []byte{0xca, 0xfe}, Options: conformanceQueryOptions(version)}
+	if version.SupportsResultMetadataId() {
+		execute.ResultMetadataId = []byte{0xba, 0xbe}
+	}
+	add("all options", execute)
+	add("empty", &Batch{Type: primitive.BatchTypeLogged, Children: []*BatchChild{}})
+	add("all options", conformanceBatch(version))
+	for _, sample := range conformanceResults(version) {
+		add(sample.Name, sample.Message)
+	}
+	for _, sample := range conformanceErrors(version) {
+		add(sample.Name, sample.Message)
+	}
+	for _, sample := range conformanceEvents(version) {
+		add(sample.Name, sample.Message)
+	}
+	if version.SupportsDseRevisionType(primitive.DseRevisionTypeCancelContinuousPaging) {
+		add("cancel continuous paging", &Revise{
+			RevisionType:   primitive.DseRevisionTypeCancelContinuousPaging,
+			TargetStreamId: math.MaxInt32,
+		})
+	}
+	if version.SupportsDseRevisionType(primitive.DseRevisionTypeMoreContinuousPages) {
+		add("more continuous pages", &Revise{
+			RevisionType:   primitive.DseRevisionTypeMoreContinuousPages,
+			TargetStreamId: 1,
+			NextPages:      math.MaxInt32,
+		})
+	}
+	return samples
+}
+
+// opCodeName returns the name of the given opcode, e.g. "AUTH RESPONSE".
+func opCodeName(opCode primitive.OpCode) string {
+	name := strings.TrimPrefix(opCode.String(), "OpCode ")
+	if i := strings.Index(name, " ["); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func conformanceBytesName(b []byte) string {
+	if b == nil {
+		return "nil"
+	} else if len(b) == 0 {
+		return "empty"
+	}
+	return "non empty"
+}
+
+func conformanceQueryOptions(version primitive.ProtocolVersion) *QueryOptions {
+	serialConsistency := primitive.ConsistencyLevelLocalSerial
+	defaultTimestamp := int64(math.MinInt64)
+	options := &QueryOptions{
+		Consistency: primitive.ConsistencyLevelLocalQuorum,
+		PositionalValues: []*primitive.Value{
+			primitive.NewValue([]byte{1, 2, 3}),
+			primitive.NewNullValue(),
+			primitive.NewValue([]byte{}),
+		},
+		SkipMetadata:      true,
+		PageSize:          math.MaxInt32,
+		PagingState:       []byte{0xca, 0xfe},
+		SerialConsistency: &serialConsistency,
+		DefaultTimestamp:  &defaultTimestamp,
+	}
+	if version.SupportsUnsetValues() {
+		options.PositionalValues = append(options.PositionalValues, primitive.NewUnsetValue())
+	}
+	if version.SupportsQueryFlag(primitive.QueryFlagWithKeyspace) {
+		options.Keyspace = "ks"
+	}
+	if version.SupportsQueryFlag(primitive.QueryFlagNowInSeconds) {
+		nowInSeconds := int32(math.MaxInt32)
+		options.NowInSeconds = &nowInSeconds
+	}
+	if version.SupportsQueryFlag(primitive.QueryFlagDsePageSizeBytes) {
+		options.PageSizeInBytes = true
+	}
+	if version.SupportsQueryFlag(primitive.QueryFlagDseWithContinuousPagingOptions) {
+		options.ContinuousPagingOptions = &ContinuousPagingOptions{MaxPages: math.MaxInt32, PagesPerSecond: 1}
+		if version >= primitive.ProtocolVersionDse2 {
+			options.ContinuousPagingOptions.NextPages = 2
+		}
+	}
+	return options
+}
+
+func conformanceBatch(version primitive.ProtocolVersion) *Batch {
+	batch := &Batch{
+		Type: primitive.BatchTypeUnlogged,
+		Children: []*BatchChild{
+			{Query: "INSERT INTO ks.t (k) VALUES (?)", Values: []*primitive.Value{primitive.NewValue([]byte{})}},
+			{Id: []byte{0xca, 0xfe}, Values: []*primitive.Value{primitive.NewNullValue()}},
+			{Query: "SELECT * FROM system.local", Values: []*primitive.Value{}},
+		},
+		Consistency: primitive.ConsistencyLevelEachQuorum,
+	}
+	if version.SupportsBatchQueryFlags() {
+		serialConsistency := primitive.ConsistencyLevelSerial
+		defaultTimestamp := int64(math.MaxInt64)
+		batch.SerialConsistency = &serialConsistency
+		batch.DefaultTimestamp = &defaultTimestamp
+	}
+	if version.SupportsQueryFlag(primitive.QueryFlagWithKeyspace) {
+		batch.Keyspace = "ks"
+	}
+	if version.SupportsQueryFlag(primitive.QueryFlagNowInSeconds) {
+		nowInSeconds := int32(0)
+		batch.NowInSeconds = &nowInSeconds
+	}
+	return batch
+}
+
+func conformanceResults(version primitive.ProtocolVersion) []*ConformanceSample {
+	columns := []*ColumnMetadata{
+		{Keyspace: "ks", Table: "t", Name: "k", Type: datatype.Int},
+		{Keyspace: "ks", Table: "t", Name: "", Type: datatype.NewMap(datatype.Varchar, datatype.NewList(datatype.Blob))},
+	}
+	rowsMetadata := &RowsMetadata{ColumnCount: 2, PagingState: []byte{0xca, 0xfe}, Columns: columns}
+	if version.SupportsRowsFlag(primitive.RowsFlagMetadataChanged) {
+		rowsMetadata.NewResultMetadataId = []byte{0xba, 0xbe}
+	}
+	if version.SupportsRowsFlag(primitive.RowsFlagDseContinuousPaging) {
+		rowsMetadata.ContinuousPageNumber = math.MaxInt32
+		rowsMetadata.LastContinuousPage = true
+	}
+	prepared := &PreparedResult{
+		PreparedQueryId:   []byte{0xca, 0xfe},
+		VariablesMetadata: &VariablesMetadata{Columns: columns},
+		ResultMetadata:    &RowsMetadata{ColumnCount: 2, Columns: columns},
+	}
+	if version >= primitive.ProtocolVersion4 {
+		prepared.VariablesMetadata.PkIndices = []uint16{0}
+	}
+	if version.SupportsResultMetadataId() {
+		prepared.ResultMetadataId = []byte{0xba, 0xbe}
+	}
+	samples := []*ConformanceSample{
+		{"void", &VoidResult{}},
+		{"set keyspace", &SetKeyspaceResult{Keyspace: "ks"}},
+		{"rows/empty", &RowsResult{Metadata: &RowsMetadata{ColumnCount: 0}, Data: RowSet{}}},
+		{"rows/no metadata", &RowsResult{Metadata: &RowsMetadata{ColumnCount: 2}, Data: RowSet{{nil, {}}}}},
+		{"rows/all fields", &RowsResult{Metadata: rowsMetadata, Data: RowSet{{{0, 0, 0, 1}, nil}, {{}, {}}}}},
+		{"prepared/no variables", &PreparedResult{
+			PreparedQueryId:   []byte{0xca, 0xfe},
+			ResultMetadataId:  prepared.ResultMetadataId,
+			VariablesMetadata: &VariablesMetadata{},
+			ResultMetadata:    &RowsMetadata{},
+		}},
+		{"prepared/all fields", prepared},
+		{"schema change/keyspace", &SchemaChangeResult{
+			ChangeType: primitive.SchemaChangeTypeCreated,
+			Target:     primitive.SchemaChangeTargetKeyspace,
+			Keyspace:   "ks",
+		}},
+		{"schema change/table", &SchemaChangeResult{
+			ChangeType: primitive.SchemaChangeTypeDropped,
+			Target:     primitive.SchemaChangeTargetTable,
+			Keyspace:   "ks",
+			Object:     "t",
+		}},
+	}
+	if version.SupportsSchemaChangeTarget(primitive.SchemaChangeTargetFunction) {
+		samples = append(samples, &ConformanceSample{"schema change/function", &SchemaChangeResult{
+			ChangeType: primitive.SchemaChangeTypeUpdated,
+			Target:     primitive.SchemaChangeTargetFunction,
+			Keyspace:   "ks",
+			Object:     "f",
+			Arguments:  []string{"int", ""},
+		}})
+	}
+	return samples
+}
+
+func conformanceErrors(version primitive.ProtocolVersion) []*ConformanceSample {
+	endpoint := net.ParseIP("192.168.1.1")
+	samples := []*ConformanceSample{
+		{"server error", NewServerError("")},
+		{"protocol error", NewProtocolError("Μιλάτε αγγλικά;")},
+		{"authentication error", NewAuthenticationError("bad credentials")},
+		{"overloaded", NewOverloaded("overloaded")},
+		{"is bootstrapping", NewIsBootstrapping("bootstrapping")},
+		{"truncate error", NewTruncateError("truncate")},
+		{"syntax error", NewSyntaxError("syntax")},
+		{"unauthorized", NewUnauthorized("unauthorized")},
+		{"invalid", NewInvalid("invalid")},
+		{"config error", NewConfigError("config")},
+		{"unavailable", &Unavailable{ErrorMessage: "unavailable", Consistency: primitive.ConsistencyLevelAll, Required: math.MaxInt32, Alive: 0}},
+		{"read timeout", &ReadTimeout{ErrorMessage: "read timeout", Consistency: primitive.ConsistencyLevelQuorum, Received: 1, BlockFor: 2, DataPresent: true}},
+		{"write timeout", &WriteTimeout{ErrorMessage: "write timeout", Consistency: primitive.ConsistencyLevelOne, Received: 0, BlockFor: 1, WriteType: primitive.WriteTypeBatchLog}},
+		{"function failure", &FunctionFailure{ErrorMessage: "function failure", Keyspace: "ks", Function: "f", Arguments: []string{"int", ""}}},
+		{"unprepared", &Unprepared{ErrorMessage: "unprepared", Id: []byte{0xca, 0xfe}}},
+		{"already exists/keyspace", &AlreadyExists{ErrorMessage: "already exists", Keyspace: "ks", Table: ""}},
+		{"already exists/table", &AlreadyExists{ErrorMessage: "already exists", Keyspace: "ks", Table: "t"}},
+	}
+	if version.SupportsWriteTimeoutContentions() {
+		samples = append(samples, &ConformanceSample{"write timeout/cas", &WriteTimeout{
+			ErrorMessage: "write timeout",
+			Consistency:  primitive.ConsistencyLevelSerial,
+			Received:     1,
+			BlockFor:     2,
+			WriteType:    primitive.WriteTypeCas,
+			Contentions:  math.MaxUint16,
+		}})
+	}
+	readFailure := &ReadFailure{ErrorMessage: "read failure", Consistency: primitive.ConsistencyLevelTwo, Received: 1, BlockFor: 2}
+	writeFailure := &WriteFailure{ErrorMessage: "write failure", Consistency: primitive.ConsistencyLevelThree, Received: 1, BlockFor: 3, WriteType: primitive.WriteTypeSimple}
+	if version.SupportsReadWriteFailureReasonMap() {
+		readFailure.FailureReasons = []*primitive.FailureReason{{Endpoint: endpoint, Code: primitive.FailureCodeTooManyTombstonesRead}}
+		writeFailure.FailureReasons = []*primitive.FailureReason{{Endpoint: endpoint, Code: primitive.FailureCodeUnknown}}
+	} else {
+		readFailure.NumFailures = 1
+		writeFailure.NumFailures = math.MaxInt32
+	}
+	samples = append(samples, &ConformanceSample{"read failure", readFailure}, &ConformanceSample{"write failure", writeFailure})
+	if version >= primitive.ProtocolVersion5 {
+		samples = append(samples,
+			&ConformanceSample{"cdc write failure", NewCdcWriteFailure("cdc write failure")},
+			&ConformanceSample{"cas write unknown", &CasWriteUnknown{
+				ErrorMessage: "cas write unknown",
+				Consistency:  primitive.ConsistencyLevelLocalSerial,
+				Received:     1,
+				BlockFor:     2,
+			}},
+		)
+	}
+	return samples
+}
+
+func conformanceEvents(version primitive.ProtocolVersion) []*ConformanceSample {
+	address := &primitive.Inet{Addr: net.ParseIP("192.168.1.1"), Port: 9042}
+	ipv6Address := &primitive.Inet{Addr: net.ParseIP("::1"), Port: math.MaxUint16}
+	samples := []*ConformanceSample{
+		{"status change/up", &StatusChangeEvent{ChangeType: primitive.StatusChangeTypeUp, Address: address}},
+		{"status change/down", &StatusChangeEvent{ChangeType: primitive.StatusChangeTypeDown, Address: ipv6Address}},
+		{"topology change/new node", &TopologyChangeEvent{ChangeType: primitive.TopologyChangeTypeNewNode, Address: address}},
+		{"topology change/removed node", &TopologyChangeEvent{ChangeType: primitive.TopologyChangeTypeRemovedNode, Address: ipv6Address}},
+		{"schema change/keyspace", &SchemaChangeEvent{
+			ChangeType: primitive.SchemaChangeTypeCreated,
+			Target:     primitive.SchemaChangeTargetKeyspace,
+			Keyspace:   "ks",
+		}},
+		{"schema change/table", &SchemaChangeEvent{
+			ChangeType: primitive.SchemaChangeTypeUpdated,
+			Target:     primitive.SchemaChangeTargetTable,
+			Keyspace:   "ks",
+			Object:     "t",
+		}},
+	}
+	if version.SupportsTopologyChangeType(primitive.TopologyChangeTypeMovedNode) {
+		samples = append(samples, &ConformanceSample{"topology change/moved node", &TopologyChangeEvent{
+			ChangeType: primitive.TopologyChangeTypeMovedNode,
+			Address:    address,
+		}})
+	}
+	if version.SupportsSchemaChangeTarget(primitive.SchemaChangeTargetAggregate) {
+		samples = append(samples, &ConformanceSample{"schema change/aggregate", &SchemaChangeEvent{
+			ChangeType: primitive.SchemaChangeTypeDropped,
+			Target:     primitive.SchemaChangeTargetAggregate,
+			Keyspace:   "ks",
+			Object:     "a",
+			Arguments:  []string{},
+		}})
+	}
+	return samples
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCheckConformance(t *testing.T) {
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			results := CheckConformance(DefaultMessageCodecs, version)
+			assert.Len(t, results, len(ConformanceSamples(version)))
+			for _, result := range results {
+				assert.True(t, result.OK(), result.String())
+			}
+		})
+	}
+}
+
+func TestConformanceRoundTrip_Mismatch(t *testing.T) {
+	// a codec that drops the authenticator when decoding
+	result := ConformanceRoundTrip(&lossyAuthenticateCodec{}, &ConformanceSample{
+		Name:    "AUTHENTICATE/default",
+		Message: &Authenticate{Authenticator: "org.apache.cassandra.auth.PasswordAuthenticator"},
+	}, primitive.ProtocolVersion4)
+	assert.False(t, result.OK())
+	assert.NotNil(t, result.Encoded)
+	assert.Equal(t, &Authenticate{}, result.Decoded)
+	assert.Nil(t, result.ReEncoded)
+	assert.Contains(t, result.String(), "AUTHENTICATE/default: decoded message differs")
+	assert.Contains(t, result.String(), `Authenticator: "org.apache.cassandra.auth.PasswordAuthenticator" != ""`)
+}
+
+func TestCheckConformance_MissingCodec(t *testing.T) {
+	results := CheckConformance([]Codec{&optionsCodec{}}, primitive.ProtocolVersion4)
+	for _, result := range results {
+		if result.Message.GetOpCode() == primitive.OpCodeOptions {
+			assert.True(t, result.OK(), result.String())
+		} else {
+			assert.False(t, result.OK())
+			assert.Contains(t, result.String(), "no codec found for opcode")
+		}
+	}
+}
+
+func TestDumpCodecs(t *testing.T) {
+	dump := DumpCodecs([]Codec{&startupCodec{}, &authenticateCodec{}, &optionsCodec{}, &lossyAuthenticateCodec{}})
+	assert.Equal(t, "OpCode STARTUP [0x01]: *message.startupCodec\n"+
+		"OpCode AUTHENTICATE [0x03]: *message.lossyAuthenticateCodec (overrides *message.authenticateCodec)\n"+
+		"OpCode OPTIONS [0x05]: *message.optionsCodec\n", dump)
+}
+
+type lossyAuthenticateCodec struct {
+	authenticateCodec
+}
+
+func (c *lossyAuthenticateCodec) Decode(source io.Reader, version primitive.ProtocolVersion) (Message, error) {
+	if _, err := c.authenticateCodec.Decode(source, version); err != nil {
+		return nil, err
+	}
+	return &Authenticate{}, nil
+}
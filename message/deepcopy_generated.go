@@ -211,6 +211,11 @@ func (in *Batch) DeepCopyInto(out *Batch) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Idempotent != nil {
+		in, out := &in.Idempotent, &out.Idempotent
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -237,7 +242,7 @@ func (in *BatchChild) DeepCopyInto(out *BatchChild) {
 	*out = *in
 	if in.Id != nil {
 		in, out := &in.Id, &out.Id
-		*out = make([]byte, len(*in))
+		*out = make(PreparedId, len(*in))
 		copy(*out, *in)
 	}
 	if in.Values != nil {
@@ -251,6 +256,21 @@ func (in *BatchChild) DeepCopyInto(out *BatchChild) {
 			}
 		}
 	}
+	if in.NamedValues != nil {
+		in, out := &in.NamedValues, &out.NamedValues
+		*out = make(map[string]*primitive.Value, len(*in))
+		for key, val := range *in {
+			var outVal *primitive.Value
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(primitive.Value)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	return
 }
 
@@ -264,6 +284,54 @@ func (in *BatchChild) DeepCopy() *BatchChild {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CasWriteUnknown) DeepCopyInto(out *CasWriteUnknown) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CasWriteUnknown.
+func (in *CasWriteUnknown) DeepCopy() *CasWriteUnknown {
+	if in == nil {
+		return nil
+	}
+	out := new(CasWriteUnknown)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyMessage is an autogenerated deepcopy function, copying the receiver, creating a new Message.
+func (in *CasWriteUnknown) DeepCopyMessage() Message {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CdcWriteFailure) DeepCopyInto(out *CdcWriteFailure) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CdcWriteFailure.
+func (in *CdcWriteFailure) DeepCopy() *CdcWriteFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(CdcWriteFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyMessage is an autogenerated deepcopy function, copying the receiver, creating a new Message.
+func (in *CdcWriteFailure) DeepCopyMessage() Message {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ColumnMetadata) DeepCopyInto(out *ColumnMetadata) {
 	*out = *in
@@ -328,12 +396,12 @@ func (in *Execute) DeepCopyInto(out *Execute) {
 	*out = *in
 	if in.QueryId != nil {
 		in, out := &in.QueryId, &out.QueryId
-		*out = make([]byte, len(*in))
+		*out = make(PreparedId, len(*in))
 		copy(*out, *in)
 	}
 	if in.ResultMetadataId != nil {
 		in, out := &in.ResultMetadataId, &out.ResultMetadataId
-		*out = make([]byte, len(*in))
+		*out = make(ResultMetadataId, len(*in))
 		copy(*out, *in)
 	}
 	if in.Options != nil {
@@ -341,6 +409,11 @@ func (in *Execute) DeepCopyInto(out *Execute) {
 		*out = new(QueryOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Idempotent != nil {
+		in, out := &in.Idempotent, &out.Idempotent
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -516,12 +589,12 @@ func (in *PreparedResult) DeepCopyInto(out *PreparedResult) {
 	*out = *in
 	if in.PreparedQueryId != nil {
 		in, out := &in.PreparedQueryId, &out.PreparedQueryId
-		*out = make([]byte, len(*in))
+		*out = make(PreparedId, len(*in))
 		copy(*out, *in)
 	}
 	if in.ResultMetadataId != nil {
 		in, out := &in.ResultMetadataId, &out.ResultMetadataId
-		*out = make([]byte, len(*in))
+		*out = make(ResultMetadataId, len(*in))
 		copy(*out, *in)
 	}
 	if in.VariablesMetadata != nil {
@@ -587,6 +660,11 @@ func (in *Query) DeepCopyInto(out *Query) {
 		*out = new(QueryOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Idempotent != nil {
+		in, out := &in.Idempotent, &out.Idempotent
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -675,6 +753,64 @@ func (in *QueryOptions) DeepCopy() *QueryOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RawEvent) DeepCopyInto(out *RawEvent) {
+	*out = *in
+	if in.Payload != nil {
+		in, out := &in.Payload, &out.Payload
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RawEvent.
+func (in *RawEvent) DeepCopy() *RawEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(RawEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyMessage is an autogenerated deepcopy function, copying the receiver, creating a new Message.
+func (in *RawEvent) DeepCopyMessage() Message {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RawMessage) DeepCopyInto(out *RawMessage) {
+	*out = *in
+	if in.Body != nil {
+		in, out := &in.Body, &out.Body
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RawMessage.
+func (in *RawMessage) DeepCopy() *RawMessage {
+	if in == nil {
+		return nil
+	}
+	out := new(RawMessage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyMessage is an autogenerated deepcopy function, copying the receiver, creating a new Message.
+func (in *RawMessage) DeepCopyMessage() Message {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReadFailure) DeepCopyInto(out *ReadFailure) {
 	*out = *in
@@ -790,6 +926,11 @@ func (in *Register) DeepCopyMessage() Message {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Revise) DeepCopyInto(out *Revise) {
 	*out = *in
+	if in.Operands != nil {
+		in, out := &in.Operands, &out.Operands
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -821,7 +962,7 @@ func (in *RowsMetadata) DeepCopyInto(out *RowsMetadata) {
 	}
 	if in.NewResultMetadataId != nil {
 		in, out := &in.NewResultMetadataId, &out.NewResultMetadataId
-		*out = make([]byte, len(*in))
+		*out = make(ResultMetadataId, len(*in))
 		copy(*out, *in)
 	}
 	if in.Columns != nil {
@@ -902,6 +1043,11 @@ func (in *SchemaChangeEvent) DeepCopyInto(out *SchemaChangeEvent) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RawTargetOptions != nil {
+		in, out := &in.RawTargetOptions, &out.RawTargetOptions
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -931,6 +1077,11 @@ func (in *SchemaChangeResult) DeepCopyInto(out *SchemaChangeResult) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RawTargetOptions != nil {
+		in, out := &in.RawTargetOptions, &out.RawTargetOptions
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1039,6 +1190,11 @@ func (in *StatusChangeEvent) DeepCopyInto(out *StatusChangeEvent) {
 		*out = new(primitive.Inet)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExtraBytes != nil {
+		in, out := &in.ExtraBytes, &out.ExtraBytes
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1078,6 +1234,11 @@ func (in *Supported) DeepCopyInto(out *Supported) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.OptionKeys != nil {
+		in, out := &in.OptionKeys, &out.OptionKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1131,6 +1292,11 @@ func (in *TopologyChangeEvent) DeepCopyInto(out *TopologyChangeEvent) {
 		*out = new(primitive.Inet)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExtraBytes != nil {
+		in, out := &in.ExtraBytes, &out.ExtraBytes
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1229,7 +1395,7 @@ func (in *Unprepared) DeepCopyInto(out *Unprepared) {
 	*out = *in
 	if in.Id != nil {
 		in, out := &in.Id, &out.Id
-		*out = make([]byte, len(*in))
+		*out = make(PreparedId, len(*in))
 		copy(*out, *in)
 	}
 	return
@@ -0,0 +1,322 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Difference is a difference between two messages, as found by Diff.
+type Difference struct {
+	// Path locates the differing field from the message root, e.g. "Options.PositionalValues[0].Contents"; it is empty
+	// if the messages themselves differ, e.g. because they have different types.
+	Path string
+	// Left is the formatted value found in the first message, or "<missing>" if the field has no counterpart, e.g. for
+	// slice elements beyond the length of the first slice.
+	Left string
+	// Right is the formatted value found in the second message, or "<missing>".
+	Right string
+}
+
+func (d Difference) String() string {
+	if d.Path == "" {
+		return fmt.Sprintf("%s != %s", d.Left, d.Right)
+	}
+	return fmt.Sprintf("%s: %s != %s", d.Path, d.Left, d.Right)
+}
+
+// Differences is a list of differences between two messages, as returned by Diff.
+type Differences []Difference
+
+// String returns the differences, one per line.
+func (d Differences) String() string {
+	lines := make([]string, len(d))
+	for i, difference := range d {
+		lines[i] = difference.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+const missingValue = "<missing>"
+
+// Diff compares the two given messages field by field, and returns their differences, or nil if the messages are
+// equal, in the sense of reflect.DeepEqual. Values are formatted in a protocol-aware manner: enumerated values, such as
+// consistency levels, are formatted with their names, byte slices in hexadecimal, data types as CQL types, and
+// [value]s as "null", "unset" or their hexadecimal contents. Diff is meant to make test failures, e.g. conformance
+// failures, easier to triage.
+func Diff(a Message, b Message) Differences {
+	var differences Differences
+	diffValues("", reflect.ValueOf(a), reflect.ValueOf(b), &differences)
+	return differences
+}
+
+func diffValues(path string, a reflect.Value, b reflect.Value, differences *Differences) {
+	addDifference := func() {
+		*differences = append(*differences, Difference{Path: path, Left: formatValue(a), Right: formatValue(b)})
+	}
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			addDifference()
+		}
+		return
+	} else if a.Type() != b.Type() {
+		addDifference()
+		return
+	} else if _, leaf := formatLeaf(a); leaf {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			addDifference()
+		}
+		return
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				addDifference()
+			}
+		} else {
+			diffValues(path, a.Elem(), b.Elem(), differences)
+		}
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if field := a.Type().Field(i); field.PkgPath == "" {
+				diffValues(joinPath(path, field.Name), a.Field(i), b.Field(i), differences)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			elementPath := fmt.Sprintf("%s[%d]", path, i)
+			if i >= a.Len() {
+				*differences = append(*differences, Difference{elementPath, missingValue, formatValue(b.Index(i))})
+			} else if i >= b.Len() {
+				*differences = append(*differences, Difference{elementPath, formatValue(a.Index(i)), missingValue})
+			} else {
+				diffValues(elementPath, a.Index(i), b.Index(i), differences)
+			}
+		}
+		if a.Kind() == reflect.Slice && a.Len() == 0 && b.Len() == 0 && a.IsNil() != b.IsNil() {
+			addDifference()
+		}
+	case reflect.Map:
+		for _, key := range sortedMapKeys(a, b) {
+			keyPath := fmt.Sprintf("%s[%s]", path, formatValue(key))
+			valueA, valueB := a.MapIndex(key), b.MapIndex(key)
+			if !valueA.IsValid() {
+				*differences = append(*differences, Difference{keyPath, missingValue, formatValue(valueB)})
+			} else if !valueB.IsValid() {
+				*differences = append(*differences, Difference{keyPath, formatValue(valueA), missingValue})
+			} else {
+				diffValues(keyPath, valueA, valueB, differences)
+			}
+		}
+		if a.Len() == 0 && b.Len() == 0 && a.IsNil() != b.IsNil() {
+			addDifference()
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			addDifference()
+		}
+	}
+}
+
+// PrettyPrint returns a multi-line, human-readable representation of the given message, with one field per line,
+// nested fields being indented. Values are formatted like in Diff.
+func PrettyPrint(msg Message) string {
+	if msg == nil {
+		return "nil"
+	}
+	sb := &strings.Builder{}
+	value := reflect.ValueOf(msg)
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	sb.WriteString(fmt.Sprintf("%s (%v)", value.Type().Name(), msg.GetOpCode()))
+	prettyPrintFields(sb, value, 1)
+	return sb.String()
+}
+
+func prettyPrintFields(sb *strings.Builder, value reflect.Value, depth int) {
+	indent := strings.Repeat("  ", depth)
+	printChild := func(label string, child reflect.Value) {
+		sb.WriteString("\n" + indent + label + ":")
+		for (child.Kind() == reflect.Ptr || child.Kind() == reflect.Interface) && !child.IsNil() {
+			child = child.Elem()
+		}
+		if formatted, leaf := formatLeaf(child); leaf || isNilOrEmpty(child) {
+			if !leaf {
+				formatted = formatValue(child)
+			}
+			sb.WriteString(" " + formatted)
+		} else {
+			prettyPrintFields(sb, child, depth+1)
+		}
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if field := value.Type().Field(i); field.PkgPath == "" {
+				printChild(field.Name, value.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			printChild(fmt.Sprintf("[%d]", i), value.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range sortedMapKeys(value) {
+			printChild(fmt.Sprintf("[%s]", formatValue(key)), value.MapIndex(key))
+		}
+	}
+}
+
+func isNilOrEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return false
+}
+
+// formatValue formats the given value on a single line.
+func formatValue(value reflect.Value) string {
+	if formatted, leaf := formatLeaf(value); leaf {
+		return formatted
+	}
+	switch value.Kind() {
+	case reflect.Invalid:
+		return "nil"
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return "nil"
+		}
+		return formatValue(value.Elem())
+	case reflect.Struct:
+		fields := make([]string, 0, value.NumField())
+		for i := 0; i < value.NumField(); i++ {
+			if field := value.Type().Field(i); field.PkgPath == "" {
+				fields = append(fields, field.Name+": "+formatValue(value.Field(i)))
+			}
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return "nil"
+		}
+		elements := make([]string, value.Len())
+		for i := range elements {
+			elements[i] = formatValue(value.Index(i))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case reflect.Map:
+		if value.IsNil() {
+			return "nil"
+		}
+		keys := sortedMapKeys(value)
+		entries := make([]string, len(keys))
+		for i, key := range keys {
+			entries[i] = formatValue(key) + ": " + formatValue(value.MapIndex(key))
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+var (
+	typeOfDataType = reflect.TypeOf((*datatype.DataType)(nil)).Elem()
+	typeOfStringer = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	typeOfValue    = reflect.TypeOf(primitive.Value{})
+)
+
+// formatLeaf formats the given value if it should not be broken down into fields or elements: scalars, byte slices,
+// data types, [value]s and enumerated values.
+func formatLeaf(value reflect.Value) (string, bool) {
+	if !value.IsValid() || !value.CanInterface() {
+		return "", false
+	}
+	if value.Type() == typeOfValue {
+		v := value.Interface().(primitive.Value)
+		switch v.Type {
+		case primitive.ValueTypeNull:
+			return "null", true
+		case primitive.ValueTypeUnset:
+			return "unset", true
+		case primitive.ValueTypeRegular:
+			return "0x" + hex.EncodeToString(v.Contents), true
+		}
+		return fmt.Sprintf("%+v", v), true
+	}
+	if value.Type().Implements(typeOfDataType) && value.Kind() != reflect.Struct {
+		if (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && value.IsNil() {
+			return "nil", true
+		}
+		return value.Interface().(datatype.DataType).AsCql(), true
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%q", value.String()), true
+	case reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// enumerated values, such as consistency levels, are formatted with their names
+		return fmt.Sprint(value.Interface()), true
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() != reflect.Uint8 {
+			return "", false
+		} else if value.Type().Implements(typeOfStringer) {
+			// e.g. net.IP or primitive.UUID
+			return fmt.Sprint(value.Interface()), true
+		} else if value.Kind() == reflect.Slice && value.IsNil() {
+			return "nil", true
+		}
+		bytes := make([]byte, value.Len())
+		reflect.Copy(reflect.ValueOf(bytes), value)
+		return "0x" + hex.EncodeToString(bytes), true
+	}
+	return "", false
+}
+
+// sortedMapKeys returns the union of the keys of the given maps, sorted by their formatted values.
+func sortedMapKeys(maps ...reflect.Value) []reflect.Value {
+	var keys []reflect.Value
+	seen := map[string]bool{}
+	for _, m := range maps {
+		for _, key := range m.MapKeys() {
+			if formatted := formatValue(key); !seen[formatted] {
+				seen[formatted] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return formatValue(keys[i]) < formatValue(keys[j]) })
+	return keys
+}
+
+func joinPath(path string, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestDiff(t *testing.T) {
+	query := func() *Query {
+		return &Query{
+			Query: "SELECT * FROM ks.t WHERE k = ?",
+			Options: &QueryOptions{
+				Consistency:      primitive.ConsistencyLevelOne,
+				PositionalValues: []*primitive.Value{primitive.NewValue([]byte{1, 2})},
+				NamedValues:      map[string]*primitive.Value{"a": primitive.NewValue([]byte{1})},
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		a        Message
+		b        Message
+		expected Differences
+	}{
+		{"equal", query(), query(), nil},
+		{"both nil", nil, nil, nil},
+		{"one nil", query(), nil, Differences{{"", `{Query: "SELECT * FROM ks.t WHERE k = ?", Options: {Consistency: ` +
+			`ConsistencyLevel ONE [0x0001], PositionalValues: [0x0102], NamedValues: {"a": 0x01}, ` +
+			`SkipMetadata: false, PageSize: 0, PageSizeInBytes: false, PagingState: nil, SerialConsistency: nil, ` +
+			`DefaultTimestamp: nil, Keyspace: "", NowInSeconds: nil, ContinuousPagingOptions: nil, ` +
+			`RawFlags: QueryFlag None [0x00000000 0b00000000000000000000000000000000]}, Idempotent: nil}`, "nil"}}},
+		{"different types", &Options{}, &Ready{}, Differences{{"", "{}", "{}"}}},
+		{"fields", query(), func() Message {
+			q := query()
+			q.Query = "SELECT * FROM ks.t"
+			q.Options.Consistency = primitive.ConsistencyLevelQuorum
+			q.Options.PositionalValues[0] = primitive.NewUnsetValue()
+			q.Options.PositionalValues = append(q.Options.PositionalValues, primitive.NewNullValue())
+			q.Options.NamedValues["a"] = primitive.NewValue([]byte{2})
+			q.Options.NamedValues["b"] = primitive.NewNullValue()
+			return q
+		}(), Differences{
+			{"Query", `"SELECT * FROM ks.t WHERE k = ?"`, `"SELECT * FROM ks.t"`},
+			{"Options.Consistency", "ConsistencyLevel ONE [0x0001]", "ConsistencyLevel QUORUM [0x0004]"},
+			{"Options.PositionalValues[0]", "0x0102", "unset"},
+			{"Options.PositionalValues[1]", "<missing>", "null"},
+			{`Options.NamedValues["a"]`, "0x01", "0x02"},
+			{`Options.NamedValues["b"]`, "<missing>", "null"},
+		}},
+		{"data types", &RowsResult{Metadata: &RowsMetadata{Columns: []*ColumnMetadata{{Name: "c", Type: datatype.Int}}}},
+			&RowsResult{Metadata: &RowsMetadata{Columns: []*ColumnMetadata{{Name: "c", Type: datatype.NewList(datatype.Int)}}}},
+			Differences{{"Metadata.Columns[0].Type", "int", "list<int>"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Diff(tt.a, tt.b))
+		})
+	}
+}
+
+func TestDifferences_String(t *testing.T) {
+	differences := Differences{{"Query", `"a"`, `"b"`}, {"", "{}", "nil"}}
+	assert.Equal(t, "Query: \"a\" != \"b\"\n{} != nil", differences.String())
+}
+
+func TestPrettyPrint(t *testing.T) {
+	msg := &Query{
+		Query: "SELECT * FROM ks.t",
+		Options: &QueryOptions{
+			Consistency:      primitive.ConsistencyLevelLocalQuorum,
+			PositionalValues: []*primitive.Value{primitive.NewValue([]byte{0xca, 0xfe})},
+		},
+	}
+	expected := `Query (OpCode QUERY [0x07])
+  Query: "SELECT * FROM ks.t"
+  Options:
+    Consistency: ConsistencyLevel LOCAL_QUORUM [0x0006]
+    PositionalValues:
+      [0]: 0xcafe
+    NamedValues: nil
+    SkipMetadata: false
+    PageSize: 0
+    PageSizeInBytes: false
+    PagingState: nil
+    SerialConsistency: nil
+    DefaultTimestamp: nil
+    Keyspace: ""
+    NowInSeconds: nil
+    ContinuousPagingOptions: nil
+    RawFlags: QueryFlag None [0x00000000 0b00000000000000000000000000000000]
+  Idempotent: nil`
+	assert.Equal(t, expected, PrettyPrint(msg))
+	assert.Equal(t, "nil", PrettyPrint(nil))
+}
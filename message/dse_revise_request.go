@@ -31,6 +31,10 @@ type Revise struct {
 	// Valid for DSE v2 only when RevisionType is 2 (DseRevisionTypeMoreContinuousPages).
 	// See ContinuousPagingOptions.
 	NextPages int32
+	// Operands contains the raw bytes following the operands known to this package: for revision types unknown to
+	// this package, all the bytes following the target stream id. They are only read by lenient codecs, see
+	// NewLenientReviseCodec, and are written back verbatim.
+	Operands []byte
 }
 
 func (m *Revise) IsResponse() bool {
@@ -45,7 +49,18 @@ func (m *Revise) String() string {
 	return fmt.Sprintf("REVISE_REQUEST operation type: %v, stream id: %v", m.RevisionType, m.TargetStreamId)
 }
 
-type reviseCodec struct{}
+// NewLenientReviseCodec returns a REVISE codec that preserves revision types unknown to this package, e.g. revision
+// types introduced by newer DSE versions, instead of failing; the bytes following the target stream id of such
+// revisions, as well as any extra bytes following the operands of known revision types, are preserved in
+// Revise.Operands. This is useful e.g. for proxies relaying requests to newer DSE servers. The returned codec can be
+// passed to frame.NewCodec to override the default REVISE codec.
+func NewLenientReviseCodec() Codec {
+	return &reviseCodec{lenient: true}
+}
+
+type reviseCodec struct {
+	lenient bool
+}
 
 func (c *reviseCodec) Encode(msg Message, dest io.Writer, version primitive.ProtocolVersion) error {
 	revise, ok := msg.(*Revise)
@@ -54,7 +69,7 @@ func (c *reviseCodec) Encode(msg Message, dest io.Writer, version primitive.Prot
 	}
 	if err := primitive.CheckDseProtocolVersion(version); err != nil {
 		return err
-	} else if err := primitive.CheckValidDseRevisionType(revise.RevisionType, version); err != nil {
+	} else if err := checkReviseRevisionType(revise.RevisionType, version, c.lenient); err != nil {
 		return err
 	} else if err := primitive.WriteInt(int32(revise.RevisionType), dest); err != nil {
 		return fmt.Errorf("cannot write REVISE/CANCEL revision type: %w", err)
@@ -68,6 +83,11 @@ func (c *reviseCodec) Encode(msg Message, dest io.Writer, version primitive.Prot
 			return fmt.Errorf("cannot write REVISE/CANCEL next pages: %w", err)
 		}
 	}
+	if len(revise.Operands) > 0 {
+		if _, err := dest.Write(revise.Operands); err != nil {
+			return fmt.Errorf("cannot write REVISE/CANCEL operands: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -85,6 +105,7 @@ func (c *reviseCodec) EncodedLength(msg Message, version primitive.ProtocolVersi
 	case primitive.DseRevisionTypeMoreContinuousPages:
 		length += primitive.LengthOfInt // next pages
 	}
+	length += len(revise.Operands)
 	return length, nil
 }
 
@@ -98,7 +119,7 @@ func (c *reviseCodec) Decode(source io.Reader, version primitive.ProtocolVersion
 		return nil, fmt.Errorf("cannot read REVISE/CANCEL revision type: %w", err)
 	}
 	revise.RevisionType = primitive.DseRevisionType(revisionType)
-	if err := primitive.CheckValidDseRevisionType(revise.RevisionType, version); err != nil {
+	if err := checkReviseRevisionType(revise.RevisionType, version, c.lenient); err != nil {
 		return nil, err
 	} else if revise.TargetStreamId, err = primitive.ReadInt(source); err != nil {
 		return nil, fmt.Errorf("cannot read REVISE/CANCEL target stream id: %w", err)
@@ -109,9 +130,25 @@ func (c *reviseCodec) Decode(source io.Reader, version primitive.ProtocolVersion
 			return nil, fmt.Errorf("cannot read REVISE/CANCEL next pages: %w", err)
 		}
 	}
+	if c.lenient {
+		if revise.Operands, err = io.ReadAll(source); err != nil {
+			return nil, fmt.Errorf("cannot read REVISE/CANCEL operands: %w", err)
+		} else if len(revise.Operands) == 0 {
+			revise.Operands = nil
+		}
+	}
 	return revise, nil
 }
 
+// checkReviseRevisionType checks that the given revision type is supported by the given version; if allowUnknown is
+// true, revision types unknown to this package are accepted, since their operands are preserved in Revise.Operands.
+func checkReviseRevisionType(t primitive.DseRevisionType, version primitive.ProtocolVersion, allowUnknown bool) error {
+	if allowUnknown && !t.IsValid() {
+		return nil
+	}
+	return primitive.CheckValidDseRevisionType(t, version)
+}
+
 func (c *reviseCodec) GetOpCode() primitive.OpCode {
 	return primitive.OpCodeDseRevise
 }
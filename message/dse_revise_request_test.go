@@ -29,16 +29,19 @@ func TestRevise_DeepCopy(t *testing.T) {
 		RevisionType:   primitive.DseRevisionTypeCancelContinuousPaging,
 		TargetStreamId: 5,
 		NextPages:      10,
+		Operands:       []byte{1},
 	}
 	cloned := obj.DeepCopy()
 	assert.Equal(t, obj, cloned)
 	cloned.RevisionType = primitive.DseRevisionTypeMoreContinuousPages
 	cloned.TargetStreamId = 6
 	cloned.NextPages = 7
+	cloned.Operands[0] = 2
 	assert.NotEqual(t, obj, cloned)
 	assert.Equal(t, primitive.DseRevisionTypeCancelContinuousPaging, obj.RevisionType)
 	assert.EqualValues(t, 5, obj.TargetStreamId)
 	assert.EqualValues(t, 10, obj.NextPages)
+	assert.Equal(t, []byte{1}, obj.Operands)
 	assert.Equal(t, primitive.DseRevisionTypeMoreContinuousPages, cloned.RevisionType)
 	assert.EqualValues(t, 6, cloned.TargetStreamId)
 	assert.EqualValues(t, 7, cloned.NextPages)
@@ -259,3 +262,65 @@ func TestReviseCodec_Decode(t *testing.T) {
 		}
 	})
 }
+
+func TestLenientReviseCodec(t *testing.T) {
+	version := primitive.ProtocolVersionDse2
+	encoded := []byte{
+		0, 0, 0, 0x10, // unknown revision type
+		0, 0, 0, 123, // stream id
+		0xca, 0xfe, // operands
+	}
+	unknown := &Revise{
+		RevisionType:   primitive.DseRevisionType(0x10),
+		TargetStreamId: 123,
+		Operands:       []byte{0xca, 0xfe},
+	}
+	t.Run("strict", func(t *testing.T) {
+		codec := &reviseCodec{}
+		_, err := codec.Decode(bytes.NewBuffer(encoded), version)
+		assert.EqualError(t, err, "invalid DSE revision type for ProtocolVersion DSE 2: DseRevisionType ? [0X00000010]")
+		err = codec.Encode(unknown, &bytes.Buffer{}, version)
+		assert.EqualError(t, err, "invalid DSE revision type for ProtocolVersion DSE 2: DseRevisionType ? [0X00000010]")
+	})
+	t.Run("unknown revision type", func(t *testing.T) {
+		codec := NewLenientReviseCodec()
+		actual, err := codec.Decode(bytes.NewBuffer(encoded), version)
+		assert.NoError(t, err)
+		assert.Equal(t, unknown, actual)
+		length, err := codec.EncodedLength(unknown, version)
+		assert.NoError(t, err)
+		assert.Equal(t, len(encoded), length)
+		dest := &bytes.Buffer{}
+		assert.NoError(t, codec.Encode(unknown, dest, version))
+		assert.Equal(t, encoded, dest.Bytes())
+	})
+	t.Run("extra operands", func(t *testing.T) {
+		codec := NewLenientReviseCodec()
+		source := []byte{
+			0, 0, 0, 2, // revision type
+			0, 0, 0, 123, // stream id
+			0, 0, 0, 4, // next pages
+			0xca, 0xfe, // extra operands
+		}
+		expected := &Revise{
+			RevisionType:   primitive.DseRevisionTypeMoreContinuousPages,
+			TargetStreamId: 123,
+			NextPages:      4,
+			Operands:       []byte{0xca, 0xfe},
+		}
+		actual, err := codec.Decode(bytes.NewBuffer(source), version)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+		dest := &bytes.Buffer{}
+		assert.NoError(t, codec.Encode(expected, dest, version))
+		assert.Equal(t, source, dest.Bytes())
+	})
+	t.Run("unsupported known revision type", func(t *testing.T) {
+		source := []byte{
+			0, 0, 0, 2, // revision type
+			0, 0, 0, 123, // stream id
+		}
+		_, err := NewLenientReviseCodec().Decode(bytes.NewBuffer(source), primitive.ProtocolVersionDse1)
+		assert.EqualError(t, err, "invalid DSE revision type for ProtocolVersion DSE 1: DseRevisionType MoreContinuousPages [0x00000002]")
+	})
+}
@@ -15,8 +15,10 @@
 package message
 
 import (
+	"encoding/hex"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
@@ -56,6 +58,14 @@ func (m *ServerError) String() string {
 	return fmt.Sprintf("ERROR SERVER ERROR (code=%v, msg=%v)", m.GetErrorCode(), m.ErrorMessage)
 }
 
+// NewServerError creates a new ServerError with the given error message. If the message is empty, a default message is used.
+func NewServerError(errorMessage string) *ServerError {
+	if errorMessage == "" {
+		errorMessage = "Server error"
+	}
+	return &ServerError{ErrorMessage: errorMessage}
+}
+
 // PROTOCOL ERROR
 
 // ProtocolError is a protocol error response.
@@ -85,6 +95,14 @@ func (m *ProtocolError) String() string {
 	return fmt.Sprintf("ERROR PROTOCOL ERROR (code=%v, msg=%v)", m.GetErrorCode(), m.ErrorMessage)
 }
 
+// NewProtocolError creates a new ProtocolError with the given error message. If the message is empty, a default message is used.
+func NewProtocolError(errorMessage string) *ProtocolError {
+	if errorMessage == "" {
+		errorMessage = "Protocol error"
+	}
+	return &ProtocolError{ErrorMessage: errorMessage}
+}
+
 // AUTHENTICATION ERROR
 
 // AuthenticationError is an authentication error response.
@@ -114,6 +132,14 @@ func (m *AuthenticationError) String() string {
 	return fmt.Sprintf("ERROR AUTHENTICATION ERROR (code=%v, msg=%v)", m.GetErrorCode(), m.ErrorMessage)
 }
 
+// NewAuthenticationError creates a new AuthenticationError with the given error message. If the message is empty, a default message is used.
+func NewAuthenticationError(errorMessage string) *AuthenticationError {
+	if errorMessage == "" {
+		errorMessage = "Authentication failed"
+	}
+	return &AuthenticationError{ErrorMessage: errorMessage}
+}
+
 // OVERLOADED
 
 // Overloaded is an error response sent when the coordinator is overloaded.
@@ -143,6 +169,14 @@ func (m *Overloaded) String() string {
 	return fmt.Sprintf("ERROR OVERLOADED (code=%v, msg=%v)", m.GetErrorCode(), m.ErrorMessage)
 }
 
+// NewOverloaded creates a new Overloaded with the given error message. If the message is empty, a default message is used.
+func NewOverloaded(errorMessage string) *Overloaded {
+	if errorMessage == "" {
+		errorMessage = "Server is in overloaded state. Cannot accept more requests at this point"
+	}
+	return &Overloaded{ErrorMessage: errorMessage}
+}
+
 // IS BOOTSTRAPPING
 
 // IsBootstrapping is an error response sent when the coordinator is bootstrapping.
@@ -172,6 +206,14 @@ func (m *IsBootstrapping) String() string {
 	return fmt.Sprintf("ERROR IS BOOTSTRAPPING (code=%v, msg=%v)", m.GetErrorCode(), m.ErrorMessage)
 }
 
+// NewIsBootstrapping creates a new IsBootstrapping with the given error message. If the message is empty, a default message is used.
+func NewIsBootstrapping(errorMessage string) *IsBootstrapping {
+	if errorMessage == "" {
+		errorMessage = "Cannot read from a bootstrapping node"
+	}
+	return &IsBootstrapping{ErrorMessage: errorMessage}
+}
+
 // TRUNCATE ERROR
 
 // TruncateError is an error response notifying that a TRUNCATE statement failed.
@@ -201,6 +243,14 @@ func (m *TruncateError) String() string {
 	return fmt.Sprintf("ERROR TRUNCATE ERROR (code=%v, msg=%v)", m.GetErrorCode(), m.ErrorMessage)
 }
 
+// NewTruncateError creates a new TruncateError with the given error message. If the message is empty, a default message is used.
+func NewTruncateError(errorMessage string) *TruncateError {
+	if errorMessage == "" {
+		errorMessage = "Error during truncate"
+	}
+	return &TruncateError{ErrorMessage: errorMessage}
+}
+
 // SYNTAX ERROR
 
 // SyntaxError is an error response notifying that the query has a syntax error.
@@ -230,6 +280,14 @@ func (m *SyntaxError) String() string {
 	return fmt.Sprintf("ERROR SYNTAX ERROR (code=%v, msg=%v)", m.GetErrorCode(), m.ErrorMessage)
 }
 
+// NewSyntaxError creates a new SyntaxError with the given error message. If the message is empty, a default message is used.
+func NewSyntaxError(errorMessage string) *SyntaxError {
+	if errorMessage == "" {
+		errorMessage = "Syntax error"
+	}
+	return &SyntaxError{ErrorMessage: errorMessage}
+}
+
 // UNAUTHORIZED
 
 // Unauthorized is an error response notifying that the logged user is not authorized to perform the request.
@@ -259,6 +317,14 @@ func (m *Unauthorized) String() string {
 	return fmt.Sprintf("ERROR UNAUTHORIZED (code=%v, msg=%v)", m.GetErrorCode(), m.ErrorMessage)
 }
 
+// NewUnauthorized creates a new Unauthorized with the given error message. If the message is empty, a default message is used.
+func NewUnauthorized(errorMessage string) *Unauthorized {
+	if errorMessage == "" {
+		errorMessage = "Unauthorized"
+	}
+	return &Unauthorized{ErrorMessage: errorMessage}
+}
+
 // INVALID
 
 // Invalid is an error response sent when the query is syntactically correct but invalid.
@@ -288,6 +354,14 @@ func (m *Invalid) String() string {
 	return fmt.Sprintf("ERROR INVALID (code=%v, msg=%v)", m.GetErrorCode(), m.ErrorMessage)
 }
 
+// NewInvalid creates a new Invalid with the given error message. If the message is empty, a default message is used.
+func NewInvalid(errorMessage string) *Invalid {
+	if errorMessage == "" {
+		errorMessage = "Invalid query"
+	}
+	return &Invalid{ErrorMessage: errorMessage}
+}
+
 // CONFIG ERROR
 
 // ConfigError is an error response sent when the query cannot be executed due to some configuration issue.
@@ -317,6 +391,14 @@ func (m *ConfigError) String() string {
 	return fmt.Sprintf("ERROR CONFIG ERROR (code=%v, msg=%v)", m.GetErrorCode(), m.ErrorMessage)
 }
 
+// NewConfigError creates a new ConfigError with the given error message. If the message is empty, a default message is used.
+func NewConfigError(errorMessage string) *ConfigError {
+	if errorMessage == "" {
+		errorMessage = "Configuration error"
+	}
+	return &ConfigError{ErrorMessage: errorMessage}
+}
+
 // UNAVAILABLE
 
 // Unavailable is an error response sent when the coordinator knows that the consistency level cannot be fulfilled.
@@ -360,6 +442,22 @@ func (m *Unavailable) String() string {
 	)
 }
 
+// NewUnavailable creates a new Unavailable error with a message similar to the one produced by Cassandra. The
+// consistency level must be valid, and the number of alive replicas must be lesser than the number of required ones.
+func NewUnavailable(consistency primitive.ConsistencyLevel, required int32, alive int32) (*Unavailable, error) {
+	if err := primitive.CheckValidConsistencyLevel(consistency); err != nil {
+		return nil, fmt.Errorf("cannot create UNAVAILABLE error: %w", err)
+	} else if alive < 0 || alive >= required {
+		return nil, fmt.Errorf("cannot create UNAVAILABLE error: invalid required/alive replicas: %v/%v", required, alive)
+	}
+	return &Unavailable{
+		ErrorMessage: fmt.Sprintf("Cannot achieve consistency level %v", consistencyLevelName(consistency)),
+		Consistency:  consistency,
+		Required:     required,
+		Alive:        alive,
+	}, nil
+}
+
 // READ TIMEOUT
 
 // ReadTimeout is an error response sent when the coordinator does not receive enough responses from replicas for a read
@@ -409,6 +507,21 @@ func (m *ReadTimeout) String() string {
 	)
 }
 
+// NewReadTimeout creates a new ReadTimeout error with a message similar to the one produced by Cassandra. The
+// consistency level must be valid, and the number of received and required responses cannot be negative.
+func NewReadTimeout(consistency primitive.ConsistencyLevel, received int32, blockFor int32, dataPresent bool) (*ReadTimeout, error) {
+	if err := checkConsistencyAndResponses(consistency, received, blockFor); err != nil {
+		return nil, fmt.Errorf("cannot create READ TIMEOUT error: %w", err)
+	}
+	return &ReadTimeout{
+		ErrorMessage: fmt.Sprintf("Operation timed out - received only %d responses.", received),
+		Consistency:  consistency,
+		Received:     received,
+		BlockFor:     blockFor,
+		DataPresent:  dataPresent,
+	}, nil
+}
+
 // WRITE TIMEOUT
 
 // WriteTimeout is an error response sent when the coordinator does not receive enough responses from replicas for a
@@ -458,6 +571,29 @@ func (m *WriteTimeout) String() string {
 	)
 }
 
+// NewWriteTimeout creates a new WriteTimeout error with a message similar to the one produced by Cassandra. The
+// consistency level and the write type must be valid, and the number of received and required responses cannot be
+// negative. For CAS write types, the number of contentions can be set afterwards.
+func NewWriteTimeout(
+	consistency primitive.ConsistencyLevel,
+	received int32,
+	blockFor int32,
+	writeType primitive.WriteType,
+) (*WriteTimeout, error) {
+	if err := checkConsistencyAndResponses(consistency, received, blockFor); err != nil {
+		return nil, fmt.Errorf("cannot create WRITE TIMEOUT error: %w", err)
+	} else if err := primitive.CheckValidWriteType(writeType); err != nil {
+		return nil, fmt.Errorf("cannot create WRITE TIMEOUT error: %w", err)
+	}
+	return &WriteTimeout{
+		ErrorMessage: fmt.Sprintf("Operation timed out - received only %d responses.", received),
+		Consistency:  consistency,
+		Received:     received,
+		BlockFor:     blockFor,
+		WriteType:    writeType,
+	}, nil
+}
+
 // READ FAILURE
 
 // ReadFailure is an error response sent when the coordinator receives a read failure from a replica.
@@ -509,6 +645,30 @@ func (m *ReadFailure) String() string {
 	)
 }
 
+// NewReadFailure creates a new ReadFailure error with a message similar to the one produced by Cassandra. Both
+// NumFailures and FailureReasons are filled, so that the error can be encoded with any protocol version. The
+// consistency level must be valid, and the number of received and required responses cannot be negative.
+func NewReadFailure(
+	consistency primitive.ConsistencyLevel,
+	received int32,
+	blockFor int32,
+	failureReasons []*primitive.FailureReason,
+	dataPresent bool,
+) (*ReadFailure, error) {
+	if err := checkConsistencyAndResponses(consistency, received, blockFor); err != nil {
+		return nil, fmt.Errorf("cannot create READ FAILURE error: %w", err)
+	}
+	return &ReadFailure{
+		ErrorMessage:   fmt.Sprintf("Operation failed - received %d responses and %d failures", received, len(failureReasons)),
+		Consistency:    consistency,
+		Received:       received,
+		BlockFor:       blockFor,
+		NumFailures:    int32(len(failureReasons)),
+		FailureReasons: failureReasons,
+		DataPresent:    dataPresent,
+	}, nil
+}
+
 // WRITE FAILURE
 
 // WriteFailure is an error response sent when the coordinator receives a write failure from a replica.
@@ -560,6 +720,128 @@ func (m *WriteFailure) String() string {
 	)
 }
 
+// NewWriteFailure creates a new WriteFailure error with a message similar to the one produced by Cassandra. Both
+// NumFailures and FailureReasons are filled, so that the error can be encoded with any protocol version. The
+// consistency level and the write type must be valid, and the number of received and required responses cannot be
+// negative.
+func NewWriteFailure(
+	consistency primitive.ConsistencyLevel,
+	received int32,
+	blockFor int32,
+	failureReasons []*primitive.FailureReason,
+	writeType primitive.WriteType,
+) (*WriteFailure, error) {
+	if err := checkConsistencyAndResponses(consistency, received, blockFor); err != nil {
+		return nil, fmt.Errorf("cannot create WRITE FAILURE error: %w", err)
+	} else if err := primitive.CheckValidWriteType(writeType); err != nil {
+		return nil, fmt.Errorf("cannot create WRITE FAILURE error: %w", err)
+	}
+	return &WriteFailure{
+		ErrorMessage:   fmt.Sprintf("Operation failed - received %d responses and %d failures", received, len(failureReasons)),
+		Consistency:    consistency,
+		Received:       received,
+		BlockFor:       blockFor,
+		NumFailures:    int32(len(failureReasons)),
+		FailureReasons: failureReasons,
+		WriteType:      writeType,
+	}, nil
+}
+
+// CDC WRITE FAILURE
+
+// CdcWriteFailure is an error response sent when a write to a table with change data capture enabled fails because the
+// CDC space is full. It was introduced in protocol version 5.
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=github.com/datastax/go-cassandra-native-protocol/message.Message
+type CdcWriteFailure struct {
+	ErrorMessage string
+}
+
+func (m *CdcWriteFailure) IsResponse() bool {
+	return true
+}
+
+func (m *CdcWriteFailure) GetOpCode() primitive.OpCode {
+	return primitive.OpCodeError
+}
+
+func (m *CdcWriteFailure) GetErrorCode() primitive.ErrorCode {
+	return primitive.ErrorCodeCdcWriteFailure
+}
+
+func (m *CdcWriteFailure) GetErrorMessage() string {
+	return m.ErrorMessage
+}
+
+func (m *CdcWriteFailure) String() string {
+	return fmt.Sprintf("ERROR CDC WRITE FAILURE (code=%v, msg=%v)", m.GetErrorCode(), m.ErrorMessage)
+}
+
+// NewCdcWriteFailure creates a new CdcWriteFailure with the given error message. If the message is empty, a default message is used.
+func NewCdcWriteFailure(errorMessage string) *CdcWriteFailure {
+	if errorMessage == "" {
+		errorMessage = "Rejecting mutation to table with CDC enabled: CDC space is full"
+	}
+	return &CdcWriteFailure{ErrorMessage: errorMessage}
+}
+
+// CAS WRITE UNKNOWN
+
+// CasWriteUnknown is an error response sent when the outcome of a lightweight transaction is unknown, because the
+// coordinator could not determine whether its commit phase was successful. It was introduced in protocol version 5.
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=github.com/datastax/go-cassandra-native-protocol/message.Message
+type CasWriteUnknown struct {
+	ErrorMessage string
+	// The consistency level of the query that triggered the exception.
+	Consistency primitive.ConsistencyLevel
+	// The number of nodes having acknowledged the request.
+	Received int32
+	// The number of replicas whose acknowledgement is required to achieve Consistency.
+	BlockFor int32
+}
+
+func (m *CasWriteUnknown) IsResponse() bool {
+	return true
+}
+
+func (m *CasWriteUnknown) GetOpCode() primitive.OpCode {
+	return primitive.OpCodeError
+}
+
+func (m *CasWriteUnknown) GetErrorCode() primitive.ErrorCode {
+	return primitive.ErrorCodeCasWriteUnknown
+}
+
+func (m *CasWriteUnknown) GetErrorMessage() string {
+	return m.ErrorMessage
+}
+
+func (m *CasWriteUnknown) String() string {
+	return fmt.Sprintf(
+		"ERROR CAS WRITE UNKNOWN (code=%v, msg=%v, cl=%v, received=%v, blockfor=%v)",
+		m.GetErrorCode(),
+		m.GetErrorMessage(),
+		m.Consistency,
+		m.Received,
+		m.BlockFor,
+	)
+}
+
+// NewCasWriteUnknown creates a new CasWriteUnknown error with a message similar to the one produced by Cassandra. The
+// consistency level must be valid, and the number of received and required responses cannot be negative.
+func NewCasWriteUnknown(consistency primitive.ConsistencyLevel, received int32, blockFor int32) (*CasWriteUnknown, error) {
+	if err := checkConsistencyAndResponses(consistency, received, blockFor); err != nil {
+		return nil, fmt.Errorf("cannot create CAS WRITE UNKNOWN error: %w", err)
+	}
+	return &CasWriteUnknown{
+		ErrorMessage: fmt.Sprintf("CAS operation result is unknown - proposal accepted by %d but not a quorum.", received),
+		Consistency:  consistency,
+		Received:     received,
+		BlockFor:     blockFor,
+	}, nil
+}
+
 // FUNCTION FAILURE
 
 // FunctionFailure is an error response sent when the coordinator receives an error from a replica while executing a
@@ -600,6 +882,20 @@ func (m *FunctionFailure) String() string {
 	)
 }
 
+// NewFunctionFailure creates a new FunctionFailure error with a message similar to the one produced by Cassandra. The
+// keyspace and function names cannot be empty.
+func NewFunctionFailure(keyspace string, function string, arguments []string, cause string) (*FunctionFailure, error) {
+	if keyspace == "" || function == "" {
+		return nil, fmt.Errorf("cannot create FUNCTION FAILURE error: keyspace and function cannot be empty")
+	}
+	return &FunctionFailure{
+		ErrorMessage: fmt.Sprintf("execution of '%v.%v[%v]' failed: %v", keyspace, function, strings.Join(arguments, ", "), cause),
+		Keyspace:     keyspace,
+		Function:     function,
+		Arguments:    arguments,
+	}, nil
+}
+
 // UNPREPARED
 
 // Unprepared is an error response sent when an unprepared query execution is attempted.
@@ -607,7 +903,7 @@ func (m *FunctionFailure) String() string {
 // +k8s:deepcopy-gen:interfaces=github.com/datastax/go-cassandra-native-protocol/message.Message
 type Unprepared struct {
 	ErrorMessage string
-	Id           []byte
+	Id           PreparedId
 }
 
 func (m *Unprepared) IsResponse() bool {
@@ -635,6 +931,20 @@ func (m *Unprepared) String() string {
 	)
 }
 
+// NewUnprepared creates a new Unprepared error with a message similar to the one produced by Cassandra. The prepared
+// id cannot be empty.
+func NewUnprepared(id []byte) (*Unprepared, error) {
+	if len(id) == 0 {
+		return nil, fmt.Errorf("cannot create UNPREPARED error: prepared id cannot be empty")
+	}
+	return &Unprepared{
+		ErrorMessage: fmt.Sprintf("Prepared query with ID %v not found (either the query was not prepared on this "+
+			"host (maybe the host has been restarted?) or you have prepared too many queries and it has been evicted "+
+			"from the internal cache)", hex.EncodeToString(id)),
+		Id: id,
+	}, nil
+}
+
 // ALREADY EXISTS
 
 // AlreadyExists is an error response sent when the creation of a schema object fails because the object already exists.
@@ -672,6 +982,49 @@ func (m *AlreadyExists) String() string {
 	)
 }
 
+// NewAlreadyExists creates a new AlreadyExists error with a message similar to the one produced by Cassandra. The
+// keyspace cannot be empty; if the table is empty, the error denotes an existing keyspace, otherwise an existing table.
+func NewAlreadyExists(keyspace string, table string) (*AlreadyExists, error) {
+	if keyspace == "" {
+		return nil, fmt.Errorf("cannot create ALREADY EXISTS error: keyspace cannot be empty")
+	}
+	var errorMessage string
+	if table == "" {
+		errorMessage = fmt.Sprintf("Cannot add existing keyspace \"%v\"", keyspace)
+	} else {
+		errorMessage = fmt.Sprintf("Cannot add already existing table \"%v\" to keyspace \"%v\"", table, keyspace)
+	}
+	return &AlreadyExists{
+		ErrorMessage: errorMessage,
+		Keyspace:     keyspace,
+		Table:        table,
+	}, nil
+}
+
+func consistencyLevelName(consistency primitive.ConsistencyLevel) string {
+	if name, err := consistency.MarshalText(); err == nil {
+		return string(name)
+	}
+	return consistency.String()
+}
+
+func checkConsistencyAndResponses(consistency primitive.ConsistencyLevel, received int32, blockFor int32) error {
+	if err := primitive.CheckValidConsistencyLevel(consistency); err != nil {
+		return err
+	} else if received < 0 || blockFor < 0 {
+		return fmt.Errorf("invalid received/blockfor responses: %v/%v", received, blockFor)
+	}
+	return nil
+}
+
+func checkErrorCode(code primitive.ErrorCode, version primitive.ProtocolVersion) error {
+	// unknown codes are reported by the codec itself
+	if code.IsValid() && !version.SupportsErrorCode(code) {
+		return fmt.Errorf("%v is not supported in protocol version %v", code, version)
+	}
+	return nil
+}
+
 // CODEC
 
 type errorCodec struct{}
@@ -681,6 +1034,9 @@ func (c *errorCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 	if !ok {
 		return fmt.Errorf("expected Error, got %T", msg)
 	}
+	if err = checkErrorCode(errMsg.GetErrorCode(), version); err != nil {
+		return fmt.Errorf("cannot write ERROR: %w", err)
+	}
 	if err = primitive.WriteInt(int32(errMsg.GetErrorCode()), dest); err != nil {
 		return fmt.Errorf("cannot write ERROR code: %w", err)
 	}
@@ -698,6 +1054,7 @@ func (c *errorCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 	case primitive.ErrorCodeUnauthorized:
 	case primitive.ErrorCodeInvalid:
 	case primitive.ErrorCodeConfigError:
+	case primitive.ErrorCodeCdcWriteFailure:
 
 	case primitive.ErrorCodeUnavailable:
 		unavailable, ok := errMsg.(*Unavailable)
@@ -807,6 +1164,19 @@ func (c *errorCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 			return fmt.Errorf("cannot write ERROR WRITE FAILURE write type: %w", err)
 		}
 
+	case primitive.ErrorCodeCasWriteUnknown:
+		casWriteUnknown, ok := errMsg.(*CasWriteUnknown)
+		if !ok {
+			return fmt.Errorf("expected *message.CasWriteUnknown, got %T", msg)
+		}
+		if err = primitive.WriteShort(uint16(casWriteUnknown.Consistency), dest); err != nil {
+			return fmt.Errorf("cannot write ERROR CAS WRITE UNKNOWN consistency: %w", err)
+		} else if err = primitive.WriteInt(casWriteUnknown.Received, dest); err != nil {
+			return fmt.Errorf("cannot write ERROR CAS WRITE UNKNOWN received: %w", err)
+		} else if err = primitive.WriteInt(casWriteUnknown.BlockFor, dest); err != nil {
+			return fmt.Errorf("cannot write ERROR CAS WRITE UNKNOWN block for: %w", err)
+		}
+
 	case primitive.ErrorCodeFunctionFailure:
 		functionFailure, ok := errMsg.(*FunctionFailure)
 		if !ok {
@@ -848,6 +1218,9 @@ func (c *errorCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 
 func (c *errorCodec) EncodedLength(msg Message, version primitive.ProtocolVersion) (length int, err error) {
 	errMsg := msg.(Error)
+	if err = checkErrorCode(errMsg.GetErrorCode(), version); err != nil {
+		return -1, err
+	}
 	length += primitive.LengthOfInt // error code
 	length += primitive.LengthOfString(errMsg.GetErrorMessage())
 	switch errMsg.GetErrorCode() {
@@ -861,6 +1234,7 @@ func (c *errorCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 	case primitive.ErrorCodeUnauthorized:
 	case primitive.ErrorCodeInvalid:
 	case primitive.ErrorCodeConfigError:
+	case primitive.ErrorCodeCdcWriteFailure:
 
 	case primitive.ErrorCodeUnavailable:
 		length += primitive.LengthOfShort // consistency
@@ -924,6 +1298,11 @@ func (c *errorCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 			return length + primitive.LengthOfInt /* num failures */, nil
 		}
 
+	case primitive.ErrorCodeCasWriteUnknown:
+		length += primitive.LengthOfShort // consistency
+		length += primitive.LengthOfInt   // received
+		length += primitive.LengthOfInt   // block for
+
 	case primitive.ErrorCodeFunctionFailure:
 		functionFailure := errMsg.(*FunctionFailure)
 		length += primitive.LengthOfString(functionFailure.Keyspace)
@@ -951,6 +1330,9 @@ func (c *errorCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 	if code, err = primitive.ReadInt(source); err != nil {
 		return nil, fmt.Errorf("cannot read ERROR code: %w", err)
 	}
+	if err = checkErrorCode(primitive.ErrorCode(code), version); err != nil {
+		return nil, fmt.Errorf("cannot read ERROR: %w", err)
+	}
 	var errorMsg string
 	if errorMsg, err = primitive.ReadString(source); err != nil {
 		return nil, fmt.Errorf("cannot read ERROR message: %w", err)
@@ -976,6 +1358,8 @@ func (c *errorCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 		return &Invalid{errorMsg}, nil
 	case primitive.ErrorCodeConfigError:
 		return &ConfigError{errorMsg}, nil
+	case primitive.ErrorCodeCdcWriteFailure:
+		return &CdcWriteFailure{errorMsg}, nil
 
 	case primitive.ErrorCodeUnavailable:
 		var msg = &Unavailable{ErrorMessage: errorMsg}
@@ -1108,6 +1492,21 @@ func (c *errorCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 		}
 		return msg, nil
 
+	case primitive.ErrorCodeCasWriteUnknown:
+		var msg = &CasWriteUnknown{ErrorMessage: errorMsg}
+		var consistency uint16
+		if consistency, err = primitive.ReadShort(source); err != nil {
+			return nil, fmt.Errorf("cannot read ERROR CAS WRITE UNKNOWN consistency: %w", err)
+		}
+		msg.Consistency = primitive.ConsistencyLevel(consistency)
+		if msg.Received, err = primitive.ReadInt(source); err != nil {
+			return nil, fmt.Errorf("cannot read ERROR CAS WRITE UNKNOWN received: %w", err)
+		}
+		if msg.BlockFor, err = primitive.ReadInt(source); err != nil {
+			return nil, fmt.Errorf("cannot read ERROR CAS WRITE UNKNOWN block for: %w", err)
+		}
+		return msg, nil
+
 	case primitive.ErrorCodeFunctionFailure:
 		var msg = &FunctionFailure{ErrorMessage: errorMsg}
 		if msg.Keyspace, err = primitive.ReadString(source); err != nil {
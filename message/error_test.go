@@ -346,6 +346,42 @@ func TestWriteFailure_DeepCopy(t *testing.T) {
 	assert.Equal(t, primitive.WriteTypeCdc, cloned.WriteType)
 }
 
+func TestCdcWriteFailure_DeepCopy(t *testing.T) {
+	msg := &CdcWriteFailure{
+		ErrorMessage: "msg",
+	}
+	cloned := msg.DeepCopy()
+	assert.Equal(t, msg, cloned)
+	cloned.ErrorMessage = "alt msg"
+	assert.NotEqual(t, msg, cloned)
+	assert.Equal(t, "msg", msg.ErrorMessage)
+	assert.Equal(t, "alt msg", cloned.ErrorMessage)
+}
+
+func TestCasWriteUnknown_DeepCopy(t *testing.T) {
+	msg := &CasWriteUnknown{
+		ErrorMessage: "msg",
+		Consistency:  primitive.ConsistencyLevelSerial,
+		Received:     1,
+		BlockFor:     2,
+	}
+	cloned := msg.DeepCopy()
+	assert.Equal(t, msg, cloned)
+	cloned.ErrorMessage = "alt msg"
+	cloned.Consistency = primitive.ConsistencyLevelLocalSerial
+	cloned.Received = 2
+	cloned.BlockFor = 3
+	assert.NotEqual(t, msg, cloned)
+	assert.Equal(t, "msg", msg.ErrorMessage)
+	assert.Equal(t, primitive.ConsistencyLevelSerial, msg.Consistency)
+	assert.Equal(t, int32(1), msg.Received)
+	assert.Equal(t, int32(2), msg.BlockFor)
+	assert.Equal(t, "alt msg", cloned.ErrorMessage)
+	assert.Equal(t, primitive.ConsistencyLevelLocalSerial, cloned.Consistency)
+	assert.Equal(t, int32(2), cloned.Received)
+	assert.Equal(t, int32(3), cloned.BlockFor)
+}
+
 func TestFunctionFailure_DeepCopy(t *testing.T) {
 	msg := &FunctionFailure{
 		ErrorMessage: "msg",
@@ -381,9 +417,9 @@ func TestUnprepared_DeepCopy(t *testing.T) {
 	cloned.Id = []byte{0x02, 0x03}
 	assert.NotEqual(t, msg, cloned)
 	assert.Equal(t, "msg", msg.ErrorMessage)
-	assert.Equal(t, []byte{0x01}, msg.Id)
+	assert.Equal(t, PreparedId{0x01}, msg.Id)
 	assert.Equal(t, "alt msg", cloned.ErrorMessage)
-	assert.Equal(t, []byte{0x02, 0x03}, cloned.Id)
+	assert.Equal(t, PreparedId{0x02, 0x03}, cloned.Id)
 }
 
 func TestAlreadyExists_DeepCopy(t *testing.T) {
@@ -748,6 +784,52 @@ func TestErrorCodec_Encode(test *testing.T) {
 			})
 		}
 	})
+	// CDC write failure and CAS write unknown in v5+
+	for _, version := range primitive.SupportedProtocolVersionsGreaterThanOrEqualTo(primitive.ProtocolVersion5) {
+		test.Run(fmt.Sprintf("cdc write failure and cas write unknown version %v", version), func(test *testing.T) {
+			tests := []encodeTestCase{
+				{
+					"cdc write failure",
+					&CdcWriteFailure{"BOOM"},
+					[]byte{
+						0, 0, 0b_0001_0110, 0b_0000_0000,
+						0, 4, B, O, O, M,
+					},
+					nil,
+				},
+				{
+					"cas write unknown",
+					&CasWriteUnknown{"BOOM", primitive.ConsistencyLevelSerial, 1, 2},
+					[]byte{
+						0, 0, 0b_0001_0111, 0b_0000_0000,
+						0, 4, B, O, O, M,
+						0, 8, // consistency
+						0, 0, 0, 1,
+						0, 0, 0, 2,
+					},
+					nil,
+				},
+			}
+			for _, tt := range tests {
+				test.Run(tt.name, func(t *testing.T) {
+					dest := &bytes.Buffer{}
+					err := codec.Encode(tt.input, dest, version)
+					assert.Equal(t, tt.expected, dest.Bytes())
+					assert.Equal(t, tt.err, err)
+				})
+			}
+		})
+	}
+	for _, version := range primitive.SupportedProtocolVersionsLesserThanOrEqualTo(primitive.ProtocolVersion4) {
+		test.Run(fmt.Sprintf("cdc write failure and cas write unknown version %v", version), func(t *testing.T) {
+			for _, msg := range []Message{&CdcWriteFailure{"BOOM"}, &CasWriteUnknown{"BOOM", primitive.ConsistencyLevelSerial, 1, 2}} {
+				err := codec.Encode(msg, &bytes.Buffer{}, version)
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), "is not supported in protocol version")
+				}
+			}
+		})
+	}
 }
 
 func TestErrorCodec_EncodedLength(test *testing.T) {
@@ -1021,6 +1103,46 @@ func TestErrorCodec_EncodedLength(test *testing.T) {
 			})
 		}
 	})
+	// CDC write failure and CAS write unknown in v5+
+	for _, version := range primitive.SupportedProtocolVersionsGreaterThanOrEqualTo(primitive.ProtocolVersion5) {
+		test.Run(fmt.Sprintf("cdc write failure and cas write unknown version %v", version), func(test *testing.T) {
+			tests := []encodedLengthTestCase{
+				{
+					"cdc write failure",
+					&CdcWriteFailure{"BOOM"},
+					primitive.LengthOfInt + primitive.LengthOfString("BOOM"),
+					nil,
+				},
+				{
+					"cas write unknown",
+					&CasWriteUnknown{"BOOM", primitive.ConsistencyLevelSerial, 1, 2},
+					primitive.LengthOfInt +
+						primitive.LengthOfString("BOOM") +
+						primitive.LengthOfShort + // consistency
+						primitive.LengthOfInt + // received
+						primitive.LengthOfInt, // block for
+					nil,
+				},
+			}
+			for _, tt := range tests {
+				test.Run(tt.name, func(t *testing.T) {
+					actual, err := codec.EncodedLength(tt.input, version)
+					assert.Equal(t, tt.expected, actual)
+					assert.Equal(t, tt.err, err)
+				})
+			}
+		})
+	}
+	for _, version := range primitive.SupportedProtocolVersionsLesserThanOrEqualTo(primitive.ProtocolVersion4) {
+		test.Run(fmt.Sprintf("cdc write failure and cas write unknown version %v", version), func(t *testing.T) {
+			for _, msg := range []Message{&CdcWriteFailure{"BOOM"}, &CasWriteUnknown{"BOOM", primitive.ConsistencyLevelSerial, 1, 2}} {
+				_, err := codec.EncodedLength(msg, version)
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), "is not supported in protocol version")
+				}
+			}
+		})
+	}
 }
 
 func TestErrorCodec_Decode(test *testing.T) {
@@ -1378,4 +1500,200 @@ func TestErrorCodec_Decode(test *testing.T) {
 			})
 		}
 	})
+	// CDC write failure and CAS write unknown in v5+
+	for _, version := range primitive.SupportedProtocolVersionsGreaterThanOrEqualTo(primitive.ProtocolVersion5) {
+		test.Run(fmt.Sprintf("cdc write failure and cas write unknown version %v", version), func(test *testing.T) {
+			tests := []decodeTestCase{
+				{
+					"cdc write failure",
+					[]byte{
+						0, 0, 0b_0001_0110, 0b_0000_0000,
+						0, 4, B, O, O, M,
+					},
+					&CdcWriteFailure{"BOOM"},
+					nil,
+				},
+				{
+					"cas write unknown",
+					[]byte{
+						0, 0, 0b_0001_0111, 0b_0000_0000,
+						0, 4, B, O, O, M,
+						0, 8, // consistency
+						0, 0, 0, 1,
+						0, 0, 0, 2,
+					},
+					&CasWriteUnknown{"BOOM", primitive.ConsistencyLevelSerial, 1, 2},
+					nil,
+				},
+			}
+			for _, tt := range tests {
+				test.Run(tt.name, func(t *testing.T) {
+					source := bytes.NewBuffer(tt.input)
+					actual, err := codec.Decode(source, version)
+					assert.Equal(t, tt.expected, actual)
+					assert.Equal(t, tt.err, err)
+				})
+			}
+		})
+	}
+	for _, version := range primitive.SupportedProtocolVersionsLesserThanOrEqualTo(primitive.ProtocolVersion4) {
+		test.Run(fmt.Sprintf("cdc write failure and cas write unknown version %v", version), func(t *testing.T) {
+			for _, code := range []byte{0b_0001_0110, 0b_0001_0111} {
+				_, err := codec.Decode(bytes.NewBuffer([]byte{0, 0, code, 0, 0, 4, B, O, O, M}), version)
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), "is not supported in protocol version")
+				}
+			}
+		})
+	}
+}
+
+func TestNewSimpleErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   Error
+		expected Error
+	}{
+		{"server error", NewServerError("BOOM"), &ServerError{ErrorMessage: "BOOM"}},
+		{"server error default", NewServerError(""), &ServerError{ErrorMessage: "Server error"}},
+		{"protocol error default", NewProtocolError(""), &ProtocolError{ErrorMessage: "Protocol error"}},
+		{"authentication error default", NewAuthenticationError(""), &AuthenticationError{ErrorMessage: "Authentication failed"}},
+		{"overloaded default", NewOverloaded(""), &Overloaded{ErrorMessage: "Server is in overloaded state. Cannot accept more requests at this point"}},
+		{"is bootstrapping default", NewIsBootstrapping(""), &IsBootstrapping{ErrorMessage: "Cannot read from a bootstrapping node"}},
+		{"truncate error default", NewTruncateError(""), &TruncateError{ErrorMessage: "Error during truncate"}},
+		{"syntax error", NewSyntaxError("line 1:0 no viable alternative"), &SyntaxError{ErrorMessage: "line 1:0 no viable alternative"}},
+		{"unauthorized default", NewUnauthorized(""), &Unauthorized{ErrorMessage: "Unauthorized"}},
+		{"invalid", NewInvalid("unconfigured table t1"), &Invalid{ErrorMessage: "unconfigured table t1"}},
+		{"config error default", NewConfigError(""), &ConfigError{ErrorMessage: "Configuration error"}},
+		{"cdc write failure default", NewCdcWriteFailure(""), &CdcWriteFailure{ErrorMessage: "Rejecting mutation to table with CDC enabled: CDC space is full"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.actual)
+		})
+	}
+}
+
+func TestNewUnavailable(t *testing.T) {
+	actual, err := NewUnavailable(primitive.ConsistencyLevelLocalQuorum, 2, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &Unavailable{
+		ErrorMessage: "Cannot achieve consistency level LOCAL_QUORUM",
+		Consistency:  primitive.ConsistencyLevelLocalQuorum,
+		Required:     2,
+		Alive:        1,
+	}, actual)
+	_, err = NewUnavailable(primitive.ConsistencyLevel(42), 2, 1)
+	assert.EqualError(t, err, "cannot create UNAVAILABLE error: invalid consistency level: ConsistencyLevel ? [0X002A]")
+	_, err = NewUnavailable(primitive.ConsistencyLevelOne, 1, 1)
+	assert.EqualError(t, err, "cannot create UNAVAILABLE error: invalid required/alive replicas: 1/1")
+}
+
+func TestNewReadTimeout(t *testing.T) {
+	actual, err := NewReadTimeout(primitive.ConsistencyLevelQuorum, 1, 2, true)
+	assert.NoError(t, err)
+	assert.Equal(t, &ReadTimeout{
+		ErrorMessage: "Operation timed out - received only 1 responses.",
+		Consistency:  primitive.ConsistencyLevelQuorum,
+		Received:     1,
+		BlockFor:     2,
+		DataPresent:  true,
+	}, actual)
+	_, err = NewReadTimeout(primitive.ConsistencyLevelQuorum, -1, 2, true)
+	assert.EqualError(t, err, "cannot create READ TIMEOUT error: invalid received/blockfor responses: -1/2")
+}
+
+func TestNewWriteTimeout(t *testing.T) {
+	actual, err := NewWriteTimeout(primitive.ConsistencyLevelQuorum, 1, 2, primitive.WriteTypeCas)
+	assert.NoError(t, err)
+	assert.Equal(t, &WriteTimeout{
+		ErrorMessage: "Operation timed out - received only 1 responses.",
+		Consistency:  primitive.ConsistencyLevelQuorum,
+		Received:     1,
+		BlockFor:     2,
+		WriteType:    primitive.WriteTypeCas,
+	}, actual)
+	_, err = NewWriteTimeout(primitive.ConsistencyLevelQuorum, 1, 2, "NOT A WRITE TYPE")
+	assert.EqualError(t, err, "cannot create WRITE TIMEOUT error: invalid write type: NOT A WRITE TYPE")
+}
+
+func TestNewCasWriteUnknown(t *testing.T) {
+	actual, err := NewCasWriteUnknown(primitive.ConsistencyLevelSerial, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, &CasWriteUnknown{
+		ErrorMessage: "CAS operation result is unknown - proposal accepted by 1 but not a quorum.",
+		Consistency:  primitive.ConsistencyLevelSerial,
+		Received:     1,
+		BlockFor:     2,
+	}, actual)
+	_, err = NewCasWriteUnknown(primitive.ConsistencyLevelSerial, 1, -1)
+	assert.EqualError(t, err, "cannot create CAS WRITE UNKNOWN error: invalid received/blockfor responses: 1/-1")
+}
+
+func TestNewReadAndWriteFailure(t *testing.T) {
+	reasons := []*primitive.FailureReason{{Endpoint: net.IPv4(192, 168, 1, 1), Code: primitive.FailureCodeTooManyTombstonesRead}}
+	readFailure, err := NewReadFailure(primitive.ConsistencyLevelAll, 2, 3, reasons, false)
+	assert.NoError(t, err)
+	assert.Equal(t, &ReadFailure{
+		ErrorMessage:   "Operation failed - received 2 responses and 1 failures",
+		Consistency:    primitive.ConsistencyLevelAll,
+		Received:       2,
+		BlockFor:       3,
+		NumFailures:    1,
+		FailureReasons: reasons,
+	}, readFailure)
+	writeFailure, err := NewWriteFailure(primitive.ConsistencyLevelAll, 2, 3, reasons, primitive.WriteTypeSimple)
+	assert.NoError(t, err)
+	assert.Equal(t, &WriteFailure{
+		ErrorMessage:   "Operation failed - received 2 responses and 1 failures",
+		Consistency:    primitive.ConsistencyLevelAll,
+		Received:       2,
+		BlockFor:       3,
+		NumFailures:    1,
+		FailureReasons: reasons,
+		WriteType:      primitive.WriteTypeSimple,
+	}, writeFailure)
+	_, err = NewReadFailure(primitive.ConsistencyLevel(42), 2, 3, nil, false)
+	assert.Error(t, err)
+	_, err = NewWriteFailure(primitive.ConsistencyLevelAll, 2, 3, nil, "")
+	assert.EqualError(t, err, "cannot create WRITE FAILURE error: invalid write type: ")
+	// errors created with constructors must be encodable with all versions
+	codec := &errorCodec{}
+	for _, version := range primitive.SupportedProtocolVersions() {
+		assert.NoError(t, codec.Encode(readFailure, &bytes.Buffer{}, version))
+		assert.NoError(t, codec.Encode(writeFailure, &bytes.Buffer{}, version))
+	}
+}
+
+func TestNewFunctionFailure(t *testing.T) {
+	actual, err := NewFunctionFailure("ks1", "fn1", []string{"int", "text"}, "BOOM")
+	assert.NoError(t, err)
+	assert.Equal(t, &FunctionFailure{
+		ErrorMessage: "execution of 'ks1.fn1[int, text]' failed: BOOM",
+		Keyspace:     "ks1",
+		Function:     "fn1",
+		Arguments:    []string{"int", "text"},
+	}, actual)
+	_, err = NewFunctionFailure("ks1", "", nil, "BOOM")
+	assert.EqualError(t, err, "cannot create FUNCTION FAILURE error: keyspace and function cannot be empty")
+}
+
+func TestNewUnprepared(t *testing.T) {
+	actual, err := NewUnprepared([]byte{0xca, 0xfe})
+	assert.NoError(t, err)
+	assert.Equal(t, PreparedId{0xca, 0xfe}, actual.Id)
+	assert.Contains(t, actual.ErrorMessage, "Prepared query with ID cafe not found")
+	_, err = NewUnprepared(nil)
+	assert.EqualError(t, err, "cannot create UNPREPARED error: prepared id cannot be empty")
+}
+
+func TestNewAlreadyExists(t *testing.T) {
+	actual, err := NewAlreadyExists("ks1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, &AlreadyExists{ErrorMessage: `Cannot add existing keyspace "ks1"`, Keyspace: "ks1"}, actual)
+	actual, err = NewAlreadyExists("ks1", "table1")
+	assert.NoError(t, err)
+	assert.Equal(t, &AlreadyExists{ErrorMessage: `Cannot add already existing table "table1" to keyspace "ks1"`, Keyspace: "ks1", Table: "table1"}, actual)
+	_, err = NewAlreadyExists("", "table1")
+	assert.EqualError(t, err, "cannot create ALREADY EXISTS error: keyspace cannot be empty")
 }
@@ -46,6 +46,9 @@ type SchemaChangeEvent struct {
 	// If the schema object affected by the change is a function or an aggregate, this field contains its arguments.
 	// Otherwise, this field is irrelevant. Valid from protocol version 4 onwards.
 	Arguments []string
+	// If the target is unknown, this field contains the raw bytes following the keyspace name. Unknown targets are only
+	// accepted by lenient codecs, see NewLenientEventCodec; they write these bytes back verbatim.
+	RawTargetOptions []byte
 }
 
 func (m *SchemaChangeEvent) IsResponse() bool {
@@ -77,6 +80,9 @@ func (m *SchemaChangeEvent) String() string {
 type StatusChangeEvent struct {
 	ChangeType primitive.StatusChangeType
 	Address    *primitive.Inet
+	// ExtraBytes contains the raw bytes that some servers append after the address. They are only read by lenient
+	// codecs, see NewLenientEventCodec, and are written back verbatim.
+	ExtraBytes []byte
 }
 
 func (m *StatusChangeEvent) IsResponse() bool {
@@ -105,6 +111,9 @@ type TopologyChangeEvent struct {
 	ChangeType primitive.TopologyChangeType
 	// The address of the node.
 	Address *primitive.Inet
+	// ExtraBytes contains the raw bytes that some servers append after the address. They are only read by lenient
+	// codecs, see NewLenientEventCodec, and are written back verbatim.
+	ExtraBytes []byte
 }
 
 func (m *TopologyChangeEvent) IsResponse() bool {
@@ -123,15 +132,116 @@ func (m *TopologyChangeEvent) String() string {
 	return fmt.Sprintf("EVENT TOPOLOGY CHANGE (type=%v address=%v)", m.ChangeType, m.Address)
 }
 
+// RAW EVENT
+
+// RawEvent is an event of a type that the EVENT codec does not know about, with its contents left undecoded. Raw
+// events are only produced by lenient codecs, see NewLenientEventCodec; they can be encoded by all codecs.
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=github.com/datastax/go-cassandra-native-protocol/message.Message
+type RawEvent struct {
+	// The event type.
+	EventType primitive.EventType
+	// The raw bytes following the event type.
+	Payload []byte
+}
+
+func (m *RawEvent) IsResponse() bool {
+	return true
+}
+
+func (m *RawEvent) GetOpCode() primitive.OpCode {
+	return primitive.OpCodeEvent
+}
+
+func (m *RawEvent) GetEventType() primitive.EventType {
+	return m.EventType
+}
+
+func (m *RawEvent) String() string {
+	return fmt.Sprintf("EVENT %v (payload=%x)", m.EventType, m.Payload)
+}
+
 // EVENT CODEC
 
-type eventCodec struct{}
+// EventTypeCodec encodes and decodes the contents of events of a given type, after the event type [string]. Codecs
+// for vendor-specific event types can be plugged into EVENT codecs with NewEventCodec.
+type EventTypeCodec interface {
+
+	// GetEventType returns the event type handled by this codec.
+	GetEventType() primitive.EventType
+
+	// Encode encodes the contents of the given event, which has the event type of this codec.
+	Encode(event Event, dest io.Writer, version primitive.ProtocolVersion) error
+
+	// EncodedLength returns the length of the encoded contents of the given event.
+	EncodedLength(event Event, version primitive.ProtocolVersion) (int, error)
+
+	// Decode decodes the contents of an event of the event type of this codec.
+	Decode(source io.Reader, version primitive.ProtocolVersion) (Event, error)
+}
+
+// NewLenientEventCodec returns an EVENT codec that tolerates deviations found in events sent by some servers:
+//
+//   - unknown schema change targets: instead of failing, it preserves such targets, along with the raw bytes following
+//     the keyspace name, in SchemaChangeEvent.RawTargetOptions;
+//   - extra bytes after the address of STATUS_CHANGE and TOPOLOGY_CHANGE events, which are preserved in
+//     StatusChangeEvent.ExtraBytes and TopologyChangeEvent.ExtraBytes;
+//   - unknown event types, which are decoded as RawEvent.
+//
+// This is useful e.g. for proxies relaying events from newer server versions. The returned codec can be passed to
+// frame.NewCodec to override the default EVENT codec.
+func NewLenientEventCodec() Codec {
+	return &eventCodec{lenient: true}
+}
+
+// NewEventCodec returns an EVENT codec that delegates the events of the types handled by the given codecs to them,
+// e.g. to parse vendor-specific event types; a codec for a built-in event type replaces the built-in implementation.
+// Other events are handled like the default EVENT codec does, or like NewLenientEventCodec if lenient is true.
+func NewEventCodec(lenient bool, eventTypeCodecs ...EventTypeCodec) Codec {
+	codec := &eventCodec{
+		lenient:         lenient,
+		eventTypeCodecs: make(map[primitive.EventType]EventTypeCodec, len(eventTypeCodecs)),
+	}
+	for _, eventTypeCodec := range eventTypeCodecs {
+		codec.eventTypeCodecs[eventTypeCodec.GetEventType()] = eventTypeCodec
+	}
+	return codec
+}
+
+type eventCodec struct {
+	lenient         bool
+	eventTypeCodecs map[primitive.EventType]EventTypeCodec
+}
+
+// checkSchemaChangeTarget checks that the given target is valid for the given version. In lenient mode, unknown
+// non-empty targets are accepted from protocol version 3 onwards.
+func checkSchemaChangeTarget(target primitive.SchemaChangeTarget, version primitive.ProtocolVersion, lenient bool) error {
+	if lenient && version >= primitive.ProtocolVersion3 && target != "" && !target.IsValid() {
+		return nil
+	}
+	return primitive.CheckValidSchemaChangeTarget(target, version)
+}
 
 func (c *eventCodec) Encode(msg Message, dest io.Writer, version primitive.ProtocolVersion) (err error) {
 	event, ok := msg.(Event)
 	if !ok {
 		return fmt.Errorf("expected message.Event, got %T", msg)
 	}
+	if eventTypeCodec, found := c.eventTypeCodecs[event.GetEventType()]; found {
+		if err = primitive.WriteString(string(event.GetEventType()), dest); err != nil {
+			return fmt.Errorf("cannot write EVENT type: %v", err)
+		}
+		return eventTypeCodec.Encode(event, dest, version)
+	} else if raw, ok := msg.(*RawEvent); ok {
+		if raw.EventType == "" {
+			return errors.New("EVENT: cannot write empty event type")
+		} else if err = primitive.WriteString(string(raw.EventType), dest); err != nil {
+			return fmt.Errorf("cannot write EVENT type: %v", err)
+		} else if _, err = dest.Write(raw.Payload); err != nil {
+			return fmt.Errorf("cannot write RawEvent.Payload: %w", err)
+		}
+		return nil
+	}
 	if err = primitive.CheckValidEventType(event.GetEventType()); err != nil {
 		return err
 	} else if err = primitive.WriteString(string(event.GetEventType()), dest); err != nil {
@@ -149,7 +259,7 @@ func (c *eventCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 			return fmt.Errorf("cannot write SchemaChangeEvent.ChangeType: %w", err)
 		}
 		if version >= primitive.ProtocolVersion3 {
-			if err = primitive.CheckValidSchemaChangeTarget(sce.Target, version); err != nil {
+			if err = checkSchemaChangeTarget(sce.Target, version, c.lenient); err != nil {
 				return err
 			} else if err = primitive.WriteString(string(sce.Target), dest); err != nil {
 				return fmt.Errorf("cannot write SchemaChangeEvent.Target: %w", err)
@@ -172,7 +282,7 @@ func (c *eventCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 			case primitive.SchemaChangeTargetAggregate:
 				fallthrough
 			case primitive.SchemaChangeTargetFunction:
-				if sce.Keyspace == "" {
+				if sce.Object == "" {
 					return errors.New("EVENT SchemaChange: cannot write empty object")
 				} else if err = primitive.WriteString(sce.Object, dest); err != nil {
 					return fmt.Errorf("cannot write SchemaChangeEvent.Object: %w", err)
@@ -180,9 +290,13 @@ func (c *eventCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 				if err = primitive.WriteStringList(sce.Arguments, dest); err != nil {
 					return fmt.Errorf("cannot write SchemaChangeEvent.Arguments: %w", err)
 				}
+			default:
+				if _, err = dest.Write(sce.RawTargetOptions); err != nil {
+					return fmt.Errorf("cannot write SchemaChangeEvent.RawTargetOptions: %w", err)
+				}
 			}
 		} else {
-			if err = primitive.CheckValidSchemaChangeTarget(sce.Target, version); err != nil {
+			if err = checkSchemaChangeTarget(sce.Target, version, c.lenient); err != nil {
 				return err
 			}
 			if sce.Keyspace == "" {
@@ -218,6 +332,8 @@ func (c *eventCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 		}
 		if err = primitive.WriteInet(sce.Address, dest); err != nil {
 			return fmt.Errorf("cannot write StatusChangeEvent.Address: %w", err)
+		} else if _, err = dest.Write(sce.ExtraBytes); err != nil {
+			return fmt.Errorf("cannot write StatusChangeEvent.ExtraBytes: %w", err)
 		}
 		return nil
 	case primitive.EventTypeTopologyChange:
@@ -232,6 +348,8 @@ func (c *eventCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 		}
 		if err = primitive.WriteInet(tce.Address, dest); err != nil {
 			return fmt.Errorf("cannot write TopologyChangeEvent.Address: %w", err)
+		} else if _, err = dest.Write(tce.ExtraBytes); err != nil {
+			return fmt.Errorf("cannot write TopologyChangeEvent.ExtraBytes: %w", err)
 		}
 		return nil
 	}
@@ -244,6 +362,15 @@ func (c *eventCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 		return -1, fmt.Errorf("expected message.Event, got %T", msg)
 	}
 	length = primitive.LengthOfString(string(event.GetEventType()))
+	if eventTypeCodec, found := c.eventTypeCodecs[event.GetEventType()]; found {
+		contentsLength, err := eventTypeCodec.EncodedLength(event, version)
+		if err != nil {
+			return -1, err
+		}
+		return length + contentsLength, nil
+	} else if raw, ok := msg.(*RawEvent); ok {
+		return length + len(raw.Payload), nil
+	}
 	switch event.GetEventType() {
 	case primitive.EventTypeSchemaChange:
 		sce, ok := msg.(*SchemaChangeEvent)
@@ -251,7 +378,7 @@ func (c *eventCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 			return -1, fmt.Errorf("expected *message.SchemaChangeEvent, got %T", msg)
 		}
 		length += primitive.LengthOfString(string(sce.ChangeType))
-		if err = primitive.CheckValidSchemaChangeTarget(sce.Target, version); err != nil {
+		if err = checkSchemaChangeTarget(sce.Target, version, c.lenient); err != nil {
 			return -1, err
 		}
 		if version >= primitive.ProtocolVersion3 {
@@ -268,6 +395,8 @@ func (c *eventCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 			case primitive.SchemaChangeTargetFunction:
 				length += primitive.LengthOfString(sce.Object)
 				length += primitive.LengthOfStringList(sce.Arguments)
+			default:
+				length += len(sce.RawTargetOptions)
 			}
 		} else {
 			length += primitive.LengthOfString(sce.Keyspace)
@@ -284,7 +413,7 @@ func (c *eventCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 		if err != nil {
 			return -1, fmt.Errorf("cannot compute length of StatusChangeEvent.Address: %w", err)
 		}
-		length += inetLength
+		length += inetLength + len(sce.ExtraBytes)
 		return length, nil
 	case primitive.EventTypeTopologyChange:
 		tce, ok := msg.(*TopologyChangeEvent)
@@ -296,7 +425,7 @@ func (c *eventCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 		if err != nil {
 			return -1, fmt.Errorf("cannot compute length of TopologyChangeEvent.Address: %w", err)
 		}
-		length += inetLength
+		length += inetLength + len(tce.ExtraBytes)
 		return length, nil
 	}
 	return -1, fmt.Errorf("unknown EVENT type: %v", event.GetEventType())
@@ -307,6 +436,9 @@ func (c *eventCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 	if err != nil {
 		return nil, err
 	}
+	if eventTypeCodec, found := c.eventTypeCodecs[primitive.EventType(eventType)]; found {
+		return eventTypeCodec.Decode(source, version)
+	}
 	switch primitive.EventType(eventType) {
 	case primitive.EventTypeSchemaChange:
 		sce := &SchemaChangeEvent{}
@@ -321,7 +453,7 @@ func (c *eventCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 				return nil, fmt.Errorf("cannot read SchemaChangeEvent.Target: %w", err)
 			}
 			sce.Target = primitive.SchemaChangeTarget(target)
-			if err = primitive.CheckValidSchemaChangeTarget(sce.Target, version); err != nil {
+			if err = checkSchemaChangeTarget(sce.Target, version, c.lenient); err != nil {
 				return nil, err
 			}
 			if sce.Keyspace, err = primitive.ReadString(source); err != nil {
@@ -345,7 +477,13 @@ func (c *eventCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 					return nil, fmt.Errorf("cannot read SchemaChangeEvent.Arguments: %w", err)
 				}
 			default:
-				return nil, fmt.Errorf("unknown schema change target: %v", sce.Target)
+				if !c.lenient {
+					return nil, fmt.Errorf("unknown schema change target: %v", sce.Target)
+				} else if sce.RawTargetOptions, err = io.ReadAll(source); err != nil {
+					return nil, fmt.Errorf("cannot read SchemaChangeEvent.RawTargetOptions: %w", err)
+				} else if len(sce.RawTargetOptions) == 0 {
+					sce.RawTargetOptions = nil
+				}
 			}
 		} else {
 			if sce.Keyspace, err = primitive.ReadString(source); err != nil {
@@ -370,6 +508,8 @@ func (c *eventCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 		sce.ChangeType = primitive.StatusChangeType(changeType)
 		if sce.Address, err = primitive.ReadInet(source); err != nil {
 			return nil, fmt.Errorf("cannot read StatusChangeEvent.Address: %w", err)
+		} else if sce.ExtraBytes, err = c.readExtraBytes(source); err != nil {
+			return nil, fmt.Errorf("cannot read StatusChangeEvent.ExtraBytes: %w", err)
 		}
 		return sce, nil
 	case primitive.EventTypeTopologyChange:
@@ -381,12 +521,33 @@ func (c *eventCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 		tce.ChangeType = primitive.TopologyChangeType(changeType)
 		if tce.Address, err = primitive.ReadInet(source); err != nil {
 			return nil, fmt.Errorf("cannot read TopologyChangeEvent.Address: %w", err)
+		} else if tce.ExtraBytes, err = c.readExtraBytes(source); err != nil {
+			return nil, fmt.Errorf("cannot read TopologyChangeEvent.ExtraBytes: %w", err)
 		}
 		return tce, nil
 	}
+	if c.lenient && eventType != "" {
+		raw := &RawEvent{EventType: primitive.EventType(eventType)}
+		if raw.Payload, err = c.readExtraBytes(source); err != nil {
+			return nil, fmt.Errorf("cannot read RawEvent.Payload: %w", err)
+		}
+		return raw, nil
+	}
 	return nil, errors.New("unknown EVENT type: " + eventType)
 }
 
+// readExtraBytes reads the remaining bytes of the source in lenient mode, returning nil if there are none; in strict
+// mode, it does not read anything.
+func (c *eventCodec) readExtraBytes(source io.Reader) ([]byte, error) {
+	if !c.lenient {
+		return nil, nil
+	} else if extra, err := io.ReadAll(source); err != nil || len(extra) == 0 {
+		return nil, err
+	} else {
+		return extra, nil
+	}
+}
+
 func (c *eventCodec) GetOpCode() primitive.OpCode {
 	return primitive.OpCodeEvent
 }
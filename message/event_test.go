@@ -16,7 +16,9 @@ package message
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"testing"
 
@@ -455,6 +457,22 @@ func TestEventCodec_Encode(test *testing.T) {
 					},
 					nil,
 				},
+				{
+					"schema change event function empty object",
+					&SchemaChangeEvent{
+						ChangeType: primitive.SchemaChangeTypeCreated,
+						Target:     primitive.SchemaChangeTargetFunction,
+						Keyspace:   "ks1",
+						Arguments:  []string{"int"},
+					},
+					[]byte{
+						0, 13, S, C, H, E, M, A, __, C, H, A, N, G, E,
+						0, 7, C, R, E, A, T, E, D,
+						0, 8, F, U, N, C, T, I, O, N,
+						0, 3, k, s, _1,
+					},
+					errors.New("EVENT SchemaChange: cannot write empty object"),
+				},
 				{
 					"status change event",
 					&StatusChangeEvent{
@@ -1144,3 +1162,170 @@ func TestEventCodec_Decode(test *testing.T) {
 		})
 	}
 }
+
+func TestEventCodec_Lenient(t *testing.T) {
+	encoded := []byte{
+		0, 13, S, C, H, E, M, A, __, C, H, A, N, G, E,
+		0, 7, C, R, E, A, T, E, D,
+		0, 4, V, I, E, W,
+		0, 3, k, s, _1,
+		0, 3, v, w, _1, // unknown options
+	}
+	expected := &SchemaChangeEvent{
+		ChangeType:       primitive.SchemaChangeTypeCreated,
+		Target:           primitive.SchemaChangeTarget("VIEW"),
+		Keyspace:         "ks1",
+		RawTargetOptions: []byte{0, 3, v, w, _1},
+	}
+	t.Run("strict", func(t *testing.T) {
+		_, err := (&eventCodec{}).Decode(bytes.NewBuffer(encoded), primitive.ProtocolVersion5)
+		assert.EqualError(t, err, "invalid schema change target for ProtocolVersion OSS 5: VIEW")
+		err = (&eventCodec{}).Encode(expected, &bytes.Buffer{}, primitive.ProtocolVersion5)
+		assert.EqualError(t, err, "invalid schema change target for ProtocolVersion OSS 5: VIEW")
+	})
+	t.Run("lenient", func(t *testing.T) {
+		codec := NewLenientEventCodec()
+		decoded, err := codec.Decode(bytes.NewBuffer(encoded), primitive.ProtocolVersion5)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, decoded)
+		length, err := codec.EncodedLength(decoded, primitive.ProtocolVersion5)
+		assert.NoError(t, err)
+		assert.Equal(t, len(encoded), length)
+		dest := &bytes.Buffer{}
+		assert.NoError(t, codec.Encode(decoded, dest, primitive.ProtocolVersion5))
+		assert.Equal(t, encoded, dest.Bytes())
+	})
+	t.Run("lenient known target", func(t *testing.T) {
+		_, err := NewLenientEventCodec().Decode(bytes.NewBuffer([]byte{
+			0, 13, S, C, H, E, M, A, __, C, H, A, N, G, E,
+			0, 7, C, R, E, A, T, E, D,
+			0, 8, F, U, N, C, T, I, O, N,
+			0, 3, k, s, _1,
+		}), primitive.ProtocolVersion3)
+		assert.EqualError(t, err, "invalid schema change target for ProtocolVersion OSS 3: FUNCTION")
+	})
+}
+
+func TestEventCodec_ExtraBytes(t *testing.T) {
+	encoded := []byte{
+		0, 13, S, T, A, T, U, S, __, C, H, A, N, G, E,
+		0, 2, U, P,
+		4, 192, 168, 1, 1, 0, 0, 0x23, 0x52,
+		0xca, 0xfe, // extra bytes
+	}
+	expected := &StatusChangeEvent{
+		ChangeType: primitive.StatusChangeTypeUp,
+		Address:    &primitive.Inet{Addr: net.IPv4(192, 168, 1, 1), Port: 9042},
+		ExtraBytes: []byte{0xca, 0xfe},
+	}
+	t.Run("strict", func(t *testing.T) {
+		source := bytes.NewBuffer(encoded)
+		decoded, err := (&eventCodec{}).Decode(source, primitive.ProtocolVersion4)
+		assert.NoError(t, err)
+		assert.Nil(t, decoded.(*StatusChangeEvent).ExtraBytes)
+		assert.Equal(t, 2, source.Len())
+	})
+	t.Run("lenient", func(t *testing.T) {
+		codec := NewLenientEventCodec()
+		decoded, err := codec.Decode(bytes.NewBuffer(encoded), primitive.ProtocolVersion4)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, decoded)
+		length, err := codec.EncodedLength(decoded, primitive.ProtocolVersion4)
+		assert.NoError(t, err)
+		assert.Equal(t, len(encoded), length)
+		dest := &bytes.Buffer{}
+		assert.NoError(t, codec.Encode(decoded, dest, primitive.ProtocolVersion4))
+		assert.Equal(t, encoded, dest.Bytes())
+	})
+}
+
+func TestEventCodec_RawEvent(t *testing.T) {
+	encoded := []byte{
+		0, 9, C, U, S, T, O, M, __, E, V,
+		0, 2, U, P,
+	}
+	expected := &RawEvent{EventType: "CUSTOM_EV", Payload: []byte{0, 2, U, P}}
+	t.Run("strict", func(t *testing.T) {
+		_, err := (&eventCodec{}).Decode(bytes.NewBuffer(encoded), primitive.ProtocolVersion4)
+		assert.EqualError(t, err, "unknown EVENT type: CUSTOM_EV")
+	})
+	t.Run("lenient", func(t *testing.T) {
+		codec := NewLenientEventCodec()
+		decoded, err := codec.Decode(bytes.NewBuffer(encoded), primitive.ProtocolVersion4)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, decoded)
+		length, err := codec.EncodedLength(decoded, primitive.ProtocolVersion4)
+		assert.NoError(t, err)
+		assert.Equal(t, len(encoded), length)
+		dest := &bytes.Buffer{}
+		assert.NoError(t, codec.Encode(decoded, dest, primitive.ProtocolVersion4))
+		assert.Equal(t, encoded, dest.Bytes())
+	})
+}
+
+// customEvent is a vendor-specific event with a single [string] field.
+type customEvent struct {
+	Data string
+}
+
+func (m *customEvent) IsResponse() bool {
+	return true
+}
+
+func (m *customEvent) GetOpCode() primitive.OpCode {
+	return primitive.OpCodeEvent
+}
+
+func (m *customEvent) GetEventType() primitive.EventType {
+	return "CUSTOM_EV"
+}
+
+func (m *customEvent) DeepCopyMessage() Message {
+	return &customEvent{Data: m.Data}
+}
+
+type customEventCodec struct{}
+
+func (c *customEventCodec) GetEventType() primitive.EventType {
+	return "CUSTOM_EV"
+}
+
+func (c *customEventCodec) Encode(event Event, dest io.Writer, _ primitive.ProtocolVersion) error {
+	return primitive.WriteString(event.(*customEvent).Data, dest)
+}
+
+func (c *customEventCodec) EncodedLength(event Event, _ primitive.ProtocolVersion) (int, error) {
+	return primitive.LengthOfString(event.(*customEvent).Data), nil
+}
+
+func (c *customEventCodec) Decode(source io.Reader, _ primitive.ProtocolVersion) (Event, error) {
+	data, err := primitive.ReadString(source)
+	return &customEvent{Data: data}, err
+}
+
+func TestNewEventCodec(t *testing.T) {
+	codec := NewEventCodec(false, &customEventCodec{})
+	encoded := []byte{
+		0, 9, C, U, S, T, O, M, __, E, V,
+		0, 2, U, P,
+	}
+	decoded, err := codec.Decode(bytes.NewBuffer(encoded), primitive.ProtocolVersion4)
+	assert.NoError(t, err)
+	assert.Equal(t, &customEvent{Data: "UP"}, decoded)
+	length, err := codec.EncodedLength(decoded, primitive.ProtocolVersion4)
+	assert.NoError(t, err)
+	assert.Equal(t, len(encoded), length)
+	dest := &bytes.Buffer{}
+	assert.NoError(t, codec.Encode(decoded, dest, primitive.ProtocolVersion4))
+	assert.Equal(t, encoded, dest.Bytes())
+	// built-in event types are still handled
+	dest.Reset()
+	topologyChange := &TopologyChangeEvent{
+		ChangeType: primitive.TopologyChangeTypeNewNode,
+		Address:    &primitive.Inet{Addr: net.IPv4(192, 168, 1, 1), Port: 9042},
+	}
+	assert.NoError(t, codec.Encode(topologyChange, dest, primitive.ProtocolVersion4))
+	decoded, err = codec.Decode(dest, primitive.ProtocolVersion4)
+	assert.NoError(t, err)
+	assert.Equal(t, topologyChange, decoded)
+}
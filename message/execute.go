@@ -28,11 +28,14 @@ import (
 // +k8s:deepcopy-gen:interfaces=github.com/datastax/go-cassandra-native-protocol/message.Message
 type Execute struct {
 	// QueryId is the prepared query id to execute.
-	QueryId []byte
+	QueryId PreparedId
 	// the ID of the result set metadata that was sent along with response to PREPARE message.
 	// Valid in protocol version 5 and DSE protocol version 2. See PreparedResult.
-	ResultMetadataId []byte
+	ResultMetadataId ResultMetadataId
 	Options          *QueryOptions
+	// Idempotent tells whether the query is idempotent, or is nil if unspecified. It is not part of the EXECUTE
+	// message itself, but is carried in the custom payload of the enclosing frame; see IdempotencePayloadKey.
+	Idempotent *bool
 }
 
 func (m *Execute) IsResponse() bool {
@@ -89,6 +92,14 @@ func (c *executeCodec) EncodedLength(msg Message, version primitive.ProtocolVers
 }
 
 func (c *executeCodec) Decode(source io.Reader, version primitive.ProtocolVersion) (msg Message, err error) {
+	return c.DecodeWithAnomalies(source, version, nil)
+}
+
+func (c *executeCodec) DecodeWithAnomalies(
+	source io.Reader,
+	version primitive.ProtocolVersion,
+	onAnomaly AnomalyHandler,
+) (msg Message, err error) {
 	var execute = &Execute{
 		Options: nil,
 	}
@@ -104,7 +115,7 @@ func (c *executeCodec) Decode(source io.Reader, version primitive.ProtocolVersio
 			return nil, errors.New("EXECUTE missing result metadata id")
 		}
 	}
-	if execute.Options, err = DecodeQueryOptions(source, version); err != nil {
+	if execute.Options, err = decodeQueryOptions(source, version, onAnomaly); err != nil {
 		return nil, fmt.Errorf("cannot read EXECUTE query options: %w", err)
 	}
 	return execute, nil
@@ -92,8 +92,8 @@ func TestExecute_DeepCopy(t *testing.T) {
 		},
 	}
 
-	assert.Equal(t, []byte{0x01}, msg.QueryId)
-	assert.Equal(t, []byte{0x02}, msg.ResultMetadataId)
+	assert.Equal(t, PreparedId{0x01}, msg.QueryId)
+	assert.Equal(t, ResultMetadataId{0x02}, msg.ResultMetadataId)
 	assert.Equal(t, primitive.ConsistencyLevelAll, msg.Options.Consistency)
 	assert.Equal(t, primitive.ValueTypeRegular, msg.Options.PositionalValues[0].Type)
 	assert.Equal(t, []byte{0x11}, msg.Options.PositionalValues[0].Contents)
@@ -113,8 +113,8 @@ func TestExecute_DeepCopy(t *testing.T) {
 
 	assert.NotEqual(t, msg, cloned)
 
-	assert.Equal(t, []byte{0x41}, cloned.QueryId)
-	assert.Equal(t, []byte{0x52}, cloned.ResultMetadataId)
+	assert.Equal(t, PreparedId{0x41}, cloned.QueryId)
+	assert.Equal(t, ResultMetadataId{0x52}, cloned.ResultMetadataId)
 	assert.Equal(t, primitive.ConsistencyLevelLocalOne, cloned.Options.Consistency)
 	assert.Equal(t, primitive.ValueTypeUnset, cloned.Options.PositionalValues[0].Type)
 	assert.Equal(t, []byte{0x21}, cloned.Options.PositionalValues[0].Contents)
@@ -0,0 +1,81 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"fmt"
+)
+
+// IdempotencePayloadKey is the custom payload key carrying the idempotence of QUERY, EXECUTE and BATCH requests. The
+// protocol has no notion of idempotence, which is a client-side concept; by convention, a request's idempotence can
+// however be carried in the custom payload of its frame, under this key, with a single byte value: 1 if the request
+// is idempotent, 0 otherwise. This allows test servers to observe the idempotence that drivers assign to requests,
+// e.g. to test retry and speculative execution policies.
+//
+// Frame codecs built with frame.CodecBuilder.WithIdempotencePayload handle this convention: when encoding a frame, the
+// Idempotent field of its message, if set, is added to the custom payload; when decoding a frame, the Idempotent field
+// of its message is set from the custom payload, if the key is present. Custom payloads require protocol version 4 or
+// higher: with lower versions, encoding a message whose Idempotent field is set fails.
+const IdempotencePayloadKey = "idempotent"
+
+// IdempotentRequest is implemented by the request messages that can carry idempotence metadata: Query, Execute and
+// Batch. See IdempotencePayloadKey.
+type IdempotentRequest interface {
+	Message
+	// GetIdempotent returns whether the request is idempotent, or nil if unspecified.
+	GetIdempotent() *bool
+	// SetIdempotent sets whether the request is idempotent; nil means unspecified.
+	SetIdempotent(idempotent *bool)
+}
+
+func (q *Query) GetIdempotent() *bool {
+	return q.Idempotent
+}
+
+func (q *Query) SetIdempotent(idempotent *bool) {
+	q.Idempotent = idempotent
+}
+
+func (m *Execute) GetIdempotent() *bool {
+	return m.Idempotent
+}
+
+func (m *Execute) SetIdempotent(idempotent *bool) {
+	m.Idempotent = idempotent
+}
+
+func (m *Batch) GetIdempotent() *bool {
+	return m.Idempotent
+}
+
+func (m *Batch) SetIdempotent(idempotent *bool) {
+	m.Idempotent = idempotent
+}
+
+// EncodeIdempotence returns the custom payload value for the given idempotence. See IdempotencePayloadKey.
+func EncodeIdempotence(idempotent bool) []byte {
+	if idempotent {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// DecodeIdempotence decodes the given custom payload value. See IdempotencePayloadKey.
+func DecodeIdempotence(value []byte) (bool, error) {
+	if len(value) != 1 || value[0] > 1 {
+		return false, fmt.Errorf("invalid idempotence payload value: expected 0 or 1, got: %v", value)
+	}
+	return value[0] == 1, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotence(test *testing.T) {
+	tests := []struct {
+		name     string
+		value    []byte
+		expected bool
+		err      string
+	}{
+		{"idempotent", []byte{1}, true, ""},
+		{"not idempotent", []byte{0}, false, ""},
+		{"empty", []byte{}, false, "invalid idempotence payload value: expected 0 or 1, got: []"},
+		{"too long", []byte{1, 0}, false, "invalid idempotence payload value: expected 0 or 1, got: [1 0]"},
+		{"invalid", []byte{2}, false, "invalid idempotence payload value: expected 0 or 1, got: [2]"},
+	}
+	for _, tt := range tests {
+		test.Run(tt.name, func(t *testing.T) {
+			actual, err := DecodeIdempotence(tt.value)
+			assert.Equal(t, tt.expected, actual)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.value, EncodeIdempotence(actual))
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestIdempotentRequest(test *testing.T) {
+	idempotent := true
+	for _, request := range []IdempotentRequest{&Query{}, &Execute{}, &Batch{}} {
+		assert.Nil(test, request.GetIdempotent())
+		request.SetIdempotent(&idempotent)
+		assert.Equal(test, &idempotent, request.GetIdempotent())
+		cloned := request.DeepCopyMessage().(IdempotentRequest)
+		assert.Equal(test, &idempotent, cloned.GetIdempotent())
+		assert.NotSame(test, request.GetIdempotent(), cloned.GetIdempotent())
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PreparedId is the id of a prepared statement, as returned in PreparedResult.PreparedQueryId and sent back in
+// Execute.QueryId, BatchChild.Id and Unprepared.Id. Its contents are opaque and determined by the server.
+//
+// PreparedId and ResultMetadataId are distinct types, so that they cannot be swapped by mistake; both are still byte
+// slices, and can be created from and converted to []byte without any conversion. Both types print and marshal to JSON
+// as hex strings.
+type PreparedId []byte
+
+// ResultMetadataId is the id of the result set metadata of a prepared statement, as returned in
+// PreparedResult.ResultMetadataId and RowsMetadata.NewResultMetadataId, and sent back in Execute.ResultMetadataId.
+// Result metadata ids exist in protocol version 5 and DSE protocol version 2. See PreparedId.
+type ResultMetadataId []byte
+
+var (
+	errEmptyPreparedId       = errors.New("prepared id cannot be empty")
+	errEmptyResultMetadataId = errors.New("result metadata id cannot be empty")
+)
+
+// Validate returns an error if this id is empty.
+func (id PreparedId) Validate() error {
+	if len(id) == 0 {
+		return errEmptyPreparedId
+	}
+	return nil
+}
+
+func (id PreparedId) String() string {
+	return hex.EncodeToString(id)
+}
+
+// MarshalJSON marshals this id as a hex string; nil ids are marshaled as null.
+func (id PreparedId) MarshalJSON() ([]byte, error) {
+	return marshalIdJSON(id)
+}
+
+// UnmarshalJSON unmarshals this id from a hex string or null.
+func (id *PreparedId) UnmarshalJSON(data []byte) error {
+	decoded, err := unmarshalIdJSON(data)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal prepared id: %w", err)
+	}
+	*id = decoded
+	return nil
+}
+
+// Validate returns an error if this id is empty.
+func (id ResultMetadataId) Validate() error {
+	if len(id) == 0 {
+		return errEmptyResultMetadataId
+	}
+	return nil
+}
+
+func (id ResultMetadataId) String() string {
+	return hex.EncodeToString(id)
+}
+
+// MarshalJSON marshals this id as a hex string; nil ids are marshaled as null.
+func (id ResultMetadataId) MarshalJSON() ([]byte, error) {
+	return marshalIdJSON(id)
+}
+
+// UnmarshalJSON unmarshals this id from a hex string or null.
+func (id *ResultMetadataId) UnmarshalJSON(data []byte) error {
+	decoded, err := unmarshalIdJSON(data)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal result metadata id: %w", err)
+	}
+	*id = decoded
+	return nil
+}
+
+func marshalIdJSON(id []byte) ([]byte, error) {
+	if id == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(hex.EncodeToString(id))
+}
+
+func unmarshalIdJSON(data []byte) ([]byte, error) {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	} else if s == nil {
+		return nil, nil
+	}
+	return hex.DecodeString(*s)
+}
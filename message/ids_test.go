@@ -0,0 +1,72 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreparedId(t *testing.T) {
+	id := PreparedId{0xca, 0xfe}
+	assert.NoError(t, id.Validate())
+	assert.EqualError(t, PreparedId{}.Validate(), "prepared id cannot be empty")
+	assert.EqualError(t, PreparedId(nil).Validate(), "prepared id cannot be empty")
+	assert.Equal(t, "cafe", id.String())
+	assert.Equal(t, "EXECUTE cafe", (&Execute{QueryId: id}).String())
+}
+
+func TestResultMetadataId(t *testing.T) {
+	id := ResultMetadataId{0xba, 0xbe}
+	assert.NoError(t, id.Validate())
+	assert.EqualError(t, ResultMetadataId{}.Validate(), "result metadata id cannot be empty")
+	assert.Equal(t, "babe", id.String())
+}
+
+func TestIds_JSON(t *testing.T) {
+	type ids struct {
+		QueryId          PreparedId
+		ResultMetadataId ResultMetadataId
+	}
+	tests := []struct {
+		name     string
+		value    ids
+		expected string
+	}{
+		{"non-empty", ids{PreparedId{0xca, 0xfe}, ResultMetadataId{0xba, 0xbe}}, `{"QueryId":"cafe","ResultMetadataId":"babe"}`},
+		{"empty", ids{PreparedId{}, ResultMetadataId{}}, `{"QueryId":"","ResultMetadataId":""}`},
+		{"nil", ids{}, `{"QueryId":null,"ResultMetadataId":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := json.Marshal(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(actual))
+			var decoded ids
+			require.NoError(t, json.Unmarshal(actual, &decoded))
+			assert.Equal(t, tt.value, decoded)
+		})
+	}
+	var decoded ids
+	err := json.Unmarshal([]byte(`{"QueryId":"xyz"}`), &decoded)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot unmarshal prepared id: encoding/hex: invalid byte")
+	err = json.Unmarshal([]byte(`{"ResultMetadataId":1}`), &decoded)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot unmarshal result metadata id")
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+)
+
+// JSON representation of messages: a message is represented by an object with a "messageType" key holding the name of
+// its Go type, e.g. "Query" or "RowsResult", and the exported fields of the corresponding Go struct, e.g.
+// {"messageType":"Query","Query":"SELECT ...","Options":{...}}. Data types are represented as described in the
+// datatype package. Custom message types can be registered with RegisterMessageJSONType.
+
+var (
+	messageJSONTypes     = map[string]reflect.Type{}
+	messageJSONTypesLock sync.RWMutex
+)
+
+func init() {
+	for _, msg := range []Message{
+		// requests
+		&Startup{}, &Options{}, &Query{}, &Prepare{}, &Execute{}, &Register{}, &Batch{}, &AuthResponse{}, &Revise{},
+		// responses
+		&Ready{}, &Authenticate{}, &Supported{}, &AuthChallenge{}, &AuthSuccess{},
+		&VoidResult{}, &SetKeyspaceResult{}, &SchemaChangeResult{}, &PreparedResult{}, &RowsResult{},
+		&SchemaChangeEvent{}, &StatusChangeEvent{}, &TopologyChangeEvent{}, &RawEvent{},
+		// errors
+		&ServerError{}, &ProtocolError{}, &AuthenticationError{}, &Overloaded{}, &IsBootstrapping{}, &TruncateError{},
+		&SyntaxError{}, &Unauthorized{}, &Invalid{}, &ConfigError{}, &Unavailable{}, &ReadTimeout{}, &WriteTimeout{},
+		&ReadFailure{}, &WriteFailure{}, &CdcWriteFailure{}, &CasWriteUnknown{}, &FunctionFailure{}, &Unprepared{},
+		&AlreadyExists{},
+		// other
+		&RawMessage{},
+	} {
+		RegisterMessageJSONType(msg)
+	}
+}
+
+// RegisterMessageJSONType registers the type of the given message, which must be a pointer to a struct, so that
+// UnmarshalMessageJSON can decode messages of that type; it is named after the struct type. Use it for custom
+// messages, e.g. vendor-specific events; all the messages of this package are registered by default.
+func RegisterMessageJSONType(msg Message) {
+	t := reflect.TypeOf(msg)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("cannot register message JSON type: expecting pointer to struct, got: %v", t))
+	}
+	messageJSONTypesLock.Lock()
+	defer messageJSONTypesLock.Unlock()
+	messageJSONTypes[t.Elem().Name()] = t.Elem()
+}
+
+// MarshalMessageJSON encodes the given message to its JSON representation, including its type discriminator. A nil
+// message encodes to a JSON null.
+func MarshalMessageJSON(msg Message) ([]byte, error) {
+	if msg == nil || reflect.ValueOf(msg).IsNil() {
+		return []byte("null"), nil
+	}
+	t := reflect.TypeOf(msg)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot encode message %v: expecting pointer to struct", t)
+	}
+	fields, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode message %v: %w", t, err)
+	}
+	typeName, _ := json.Marshal(t.Elem().Name())
+	var buf bytes.Buffer
+	buf.WriteString(`{"messageType":`)
+	buf.Write(typeName)
+	if fields = bytes.TrimSpace(fields); len(fields) > 2 {
+		buf.WriteByte(',')
+	}
+	buf.Write(fields[1:])
+	return buf.Bytes(), nil
+}
+
+// UnmarshalMessageJSON decodes a message from its JSON representation, as produced by MarshalMessageJSON. Since
+// Message is an interface, the concrete type to decode is determined by the type discriminator, which must designate
+// a registered type, see RegisterMessageJSONType. A JSON null decodes to a nil Message.
+func UnmarshalMessageJSON(data []byte) (Message, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+	var discriminator struct {
+		Type string `json:"messageType"`
+	}
+	if err := json.Unmarshal(data, &discriminator); err != nil {
+		return nil, fmt.Errorf("cannot decode message: %w", err)
+	} else if discriminator.Type == "" {
+		return nil, errors.New("cannot decode message: missing messageType")
+	}
+	messageJSONTypesLock.RLock()
+	t, found := messageJSONTypes[discriminator.Type]
+	messageJSONTypesLock.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("cannot decode message: unknown message type: %q", discriminator.Type)
+	}
+	msg := reflect.New(t).Interface().(Message)
+	// the messageType key is ignored, since no message has a field of that name
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("cannot decode %v message: %w", discriminator.Type, err)
+	}
+	return msg, nil
+}
+
+// UnmarshalJSON decodes this column from its JSON representation; it is needed to decode the column type, since
+// datatype.DataType is an interface.
+func (c *ColumnMetadata) UnmarshalJSON(data []byte) error {
+	type columnMetadata ColumnMetadata // prevents recursion
+	var decoded struct {
+		*columnMetadata
+		Type json.RawMessage
+	}
+	decoded.columnMetadata = (*columnMetadata)(c)
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	dt, err := datatype.UnmarshalDataTypeJSON(decoded.Type)
+	if err != nil {
+		return fmt.Errorf("cannot decode column %v: %w", c.Name, err)
+	}
+	c.Type = dt
+	return nil
+}
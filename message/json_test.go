@@ -0,0 +1,77 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestMessageJSON_RoundTrip(t *testing.T) {
+	for _, version := range primitive.SupportedProtocolVersions() {
+		for _, sample := range ConformanceSamples(version) {
+			t.Run(version.String()+"/"+sample.Name, func(t *testing.T) {
+				data, err := MarshalMessageJSON(sample.Message)
+				require.NoError(t, err)
+				decoded, err := UnmarshalMessageJSON(data)
+				require.NoError(t, err)
+				assert.IsType(t, sample.Message, decoded)
+				// compare the representations, since e.g. IPv4 addresses can have different lengths
+				again, err := MarshalMessageJSON(decoded)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(data), string(again))
+			})
+		}
+	}
+}
+
+func TestMarshalMessageJSON(t *testing.T) {
+	data, err := MarshalMessageJSON(&Options{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"messageType":"Options"}`, string(data))
+	data, err = MarshalMessageJSON(&Overloaded{ErrorMessage: "too busy"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"messageType":"Overloaded","ErrorMessage":"too busy"}`, string(data))
+	data, err = MarshalMessageJSON(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(data))
+}
+
+func TestUnmarshalMessageJSON(t *testing.T) {
+	msg, err := UnmarshalMessageJSON([]byte(`{"messageType":"Batch","Type":"UNLOGGED","Children":[{"Query":"INSERT"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Batch{Type: primitive.BatchTypeUnlogged, Children: []*BatchChild{{Query: "INSERT"}}}, msg)
+	msg, err = UnmarshalMessageJSON([]byte(`{"messageType":"PreparedResult","PreparedQueryId":"cafe",` +
+		`"VariablesMetadata":{"Columns":[{"Name":"c1","Type":{"type":"list","elementType":"int"}}]}}`))
+	require.NoError(t, err)
+	assert.Equal(t, &PreparedResult{
+		PreparedQueryId:   []byte{0xca, 0xfe},
+		VariablesMetadata: &VariablesMetadata{Columns: []*ColumnMetadata{{Name: "c1", Type: datatype.NewList(datatype.Int)}}},
+	}, msg)
+	msg, err = UnmarshalMessageJSON([]byte(`null`))
+	require.NoError(t, err)
+	assert.Nil(t, msg)
+	_, err = UnmarshalMessageJSON([]byte(`{"Query":"SELECT"}`))
+	assert.EqualError(t, err, "cannot decode message: missing messageType")
+	_, err = UnmarshalMessageJSON([]byte(`{"messageType":"Foo"}`))
+	assert.EqualError(t, err, `cannot decode message: unknown message type: "Foo"`)
+	_, err = UnmarshalMessageJSON([]byte(`{"messageType":"PreparedResult","VariablesMetadata":{"Columns":[{"Name":"c1","Type":"foo"}]}}`))
+	assert.EqualError(t, err, "cannot decode PreparedResult message: cannot decode column c1: cannot decode data type: unknown primitive type: foo")
+}
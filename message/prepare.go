@@ -31,6 +31,12 @@ type Prepare struct {
 	// The keyspace that the query should be executed in.
 	// Introduced in Protocol Version 5, also present in DSE protocol v2.
 	Keyspace string
+	// UnknownFlags holds the flag bits that this library does not know about. When decoding, unknown bits are
+	// preserved here; when encoding, they are emitted along with the flags derived from the other fields. This allows
+	// proxies to relay PREPARE requests from newer clients without silently dropping their flags. Note that only
+	// flags that do not carry additional data can be relayed faithfully.
+	// Only valid for protocol versions supporting PREPARE flags, ignored otherwise.
+	UnknownFlags primitive.PrepareFlag
 }
 
 func (m *Prepare) IsResponse() bool {
@@ -45,14 +51,18 @@ func (m *Prepare) String() string {
 	return fmt.Sprintf("PREPARE (%v, %v)", m.Query, m.Keyspace)
 }
 
+// Flags returns the flags to encode for this message: the flags derived from its fields, plus its unknown flags.
 func (m *Prepare) Flags() primitive.PrepareFlag {
-	var flags primitive.PrepareFlag
+	flags := m.UnknownFlags.Remove(knownPrepareFlags)
 	if m.Keyspace != "" {
 		flags = flags.Add(primitive.PrepareFlagWithKeyspace)
 	}
 	return flags
 }
 
+// knownPrepareFlags are the flags handled by this library, see Prepare.UnknownFlags.
+const knownPrepareFlags = primitive.PrepareFlagWithKeyspace
+
 type prepareCodec struct{}
 
 func (c *prepareCodec) Encode(msg Message, dest io.Writer, version primitive.ProtocolVersion) (err error) {
@@ -108,6 +118,7 @@ func (c *prepareCodec) Decode(source io.Reader, version primitive.ProtocolVersio
 			return nil, fmt.Errorf("cannot read PREPARE flags: %w", err)
 		}
 		flags = primitive.PrepareFlag(f)
+		prepare.UnknownFlags = flags.Remove(knownPrepareFlags)
 		if flags.Contains(primitive.PrepareFlagWithKeyspace) {
 			if prepare.Keyspace, err = primitive.ReadString(source); err != nil {
 				return nil, fmt.Errorf("cannot read PREPARE keyspace: %w", err)
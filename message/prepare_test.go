@@ -26,8 +26,9 @@ import (
 
 func TestPrepare_DeepCopy(t *testing.T) {
 	msg := &Prepare{
-		Query:    "query",
-		Keyspace: "ks1",
+		Query:        "query",
+		Keyspace:     "ks1",
+		UnknownFlags: 0x00000100,
 	}
 
 	cloned := msg.DeepCopy()
@@ -35,11 +36,13 @@ func TestPrepare_DeepCopy(t *testing.T) {
 
 	cloned.Query = "query2"
 	cloned.Keyspace = "ks2"
+	cloned.UnknownFlags = 0
 
 	assert.NotEqual(t, msg, cloned)
 
 	assert.Equal(t, "query", msg.Query)
 	assert.Equal(t, "ks1", msg.Keyspace)
+	assert.Equal(t, primitive.PrepareFlag(0x00000100), msg.UnknownFlags)
 
 	assert.Equal(t, "query2", cloned.Query)
 	assert.Equal(t, "ks2", cloned.Keyspace)
@@ -53,7 +56,7 @@ func TestPrepareCodec_Encode(t *testing.T) {
 			tests := []encodeTestCase{
 				{
 					"prepare simple",
-					&Prepare{"SELECT", ""},
+					&Prepare{Query: "SELECT"},
 					[]byte{
 						0, 0, 0, 6, S, E, L, E, C, T,
 					},
@@ -82,7 +85,7 @@ func TestPrepareCodec_Encode(t *testing.T) {
 			tests := []encodeTestCase{
 				{
 					"prepare simple",
-					&Prepare{"SELECT", ""},
+					&Prepare{Query: "SELECT"},
 					[]byte{
 						0, 0, 0, 6, S, E, L, E, C, T,
 						0, 0, 0, 0, // flags
@@ -91,7 +94,7 @@ func TestPrepareCodec_Encode(t *testing.T) {
 				},
 				{
 					"prepare with keyspace",
-					&Prepare{"SELECT", "ks"},
+					&Prepare{Query: "SELECT", Keyspace: "ks"},
 					[]byte{
 						0, 0, 0, 6, S, E, L, E, C, T,
 						0, 0, 0, 1, // flags
@@ -99,6 +102,25 @@ func TestPrepareCodec_Encode(t *testing.T) {
 					},
 					nil,
 				},
+				{
+					"prepare with unknown flags",
+					&Prepare{Query: "SELECT", Keyspace: "ks", UnknownFlags: 0x00010100},
+					[]byte{
+						0, 0, 0, 6, S, E, L, E, C, T,
+						0, 1, 1, 1, // flags
+						0, 2, k, s, // keyspace
+					},
+					nil,
+				},
+				{
+					"prepare with known flags in unknown flags",
+					&Prepare{Query: "SELECT", UnknownFlags: 0x00000101},
+					[]byte{
+						0, 0, 0, 6, S, E, L, E, C, T,
+						0, 0, 1, 0, // flags
+					},
+					nil,
+				},
 				{
 					"not a prepare",
 					&Ready{},
@@ -126,7 +148,7 @@ func TestPrepareCodec_EncodedLength(t *testing.T) {
 			tests := []encodedLengthTestCase{
 				{
 					"prepare simple",
-					&Prepare{"SELECT", ""},
+					&Prepare{Query: "SELECT"},
 					primitive.LengthOfLongString("SELECT"),
 					nil,
 				},
@@ -152,19 +174,26 @@ func TestPrepareCodec_EncodedLength(t *testing.T) {
 			tests := []encodedLengthTestCase{
 				{
 					"prepare simple",
-					&Prepare{"SELECT", ""},
+					&Prepare{Query: "SELECT"},
 					primitive.LengthOfLongString("SELECT") +
 						primitive.LengthOfInt, // flags
 					nil,
 				},
 				{
 					"prepare with keyspace",
-					&Prepare{"SELECT", "ks"},
+					&Prepare{Query: "SELECT", Keyspace: "ks"},
 					primitive.LengthOfLongString("SELECT") +
 						primitive.LengthOfInt + // flags
 						primitive.LengthOfString("ks"), // keyspace
 					nil,
 				},
+				{
+					"prepare with unknown flags",
+					&Prepare{Query: "SELECT", UnknownFlags: 0x00010000},
+					primitive.LengthOfLongString("SELECT") +
+						primitive.LengthOfInt, // flags
+					nil,
+				},
 				{
 					"not a prepare",
 					&Ready{},
@@ -194,7 +223,7 @@ func TestPrepareCodec_Decode(t *testing.T) {
 					[]byte{
 						0, 0, 0, 6, S, E, L, E, C, T,
 					},
-					&Prepare{"SELECT", ""},
+					&Prepare{Query: "SELECT"},
 					nil,
 				},
 			}
@@ -218,7 +247,7 @@ func TestPrepareCodec_Decode(t *testing.T) {
 						0, 0, 0, 6, S, E, L, E, C, T,
 						0, 0, 0, 0, // flags
 					},
-					&Prepare{"SELECT", ""},
+					&Prepare{Query: "SELECT"},
 					nil,
 				},
 				{
@@ -228,7 +257,17 @@ func TestPrepareCodec_Decode(t *testing.T) {
 						0, 0, 0, 1, // flags
 						0, 2, k, s, // keyspace
 					},
-					&Prepare{"SELECT", "ks"},
+					&Prepare{Query: "SELECT", Keyspace: "ks"},
+					nil,
+				},
+				{
+					"prepare with unknown flags",
+					[]byte{
+						0, 0, 0, 6, S, E, L, E, C, T,
+						0x80, 0, 1, 1, // flags
+						0, 2, k, s, // keyspace
+					},
+					&Prepare{Query: "SELECT", Keyspace: "ks", UnknownFlags: 0x80000100},
 					nil,
 				},
 			}
@@ -28,6 +28,9 @@ import (
 type Query struct {
 	Query   string
 	Options *QueryOptions
+	// Idempotent tells whether the query is idempotent, or is nil if unspecified. It is not part of the QUERY message
+	// itself, but is carried in the custom payload of the enclosing frame; see IdempotencePayloadKey.
+	Idempotent *bool
 }
 
 func (q *Query) String() string {
@@ -72,9 +75,13 @@ func (c *queryCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 }
 
 func (c *queryCodec) Decode(source io.Reader, version primitive.ProtocolVersion) (Message, error) {
+	return c.DecodeWithAnomalies(source, version, nil)
+}
+
+func (c *queryCodec) DecodeWithAnomalies(source io.Reader, version primitive.ProtocolVersion, onAnomaly AnomalyHandler) (Message, error) {
 	if query, err := primitive.ReadLongString(source); err != nil {
 		return nil, err
-	} else if options, err := DecodeQueryOptions(source, version); err != nil {
+	} else if options, err := decodeQueryOptions(source, version, onAnomaly); err != nil {
 		return nil, err
 	} else {
 		return &Query{Query: query, Options: options}, nil
@@ -18,6 +18,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"sort"
 
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
@@ -78,8 +80,29 @@ type QueryOptions struct {
 
 	// Valid only for DSE protocol versions.
 	ContinuousPagingOptions *ContinuousPagingOptions
+
+	// RawFlags are the flag bits unknown to this library found when decoding, such as vendor-specific extensions. When
+	// encoding, they are added to the flags computed from the other fields, so that such extensions survive a
+	// decode/encode round trip; bits known to this library are ignored. Note that any extension data associated with
+	// these bits is not preserved. Unknown bits are also reported as anomalies when decoding with an anomaly handler,
+	// see AnomalyDecoder: to reject them, use a handler that does not tolerate AnomalyUnexpectedFlags, e.g. with
+	// frame.DecodingModeStrict.
+	RawFlags primitive.QueryFlag
 }
 
+// knownQueryFlags are the query flags this library knows how to encode and decode.
+const knownQueryFlags = primitive.QueryFlagValues |
+	primitive.QueryFlagSkipMetadata |
+	primitive.QueryFlagPageSize |
+	primitive.QueryFlagPagingState |
+	primitive.QueryFlagSerialConsistency |
+	primitive.QueryFlagDefaultTimestamp |
+	primitive.QueryFlagValueNames |
+	primitive.QueryFlagWithKeyspace |
+	primitive.QueryFlagNowInSeconds |
+	primitive.QueryFlagDsePageSizeBytes |
+	primitive.QueryFlagDseWithContinuousPagingOptions
+
 func (o *QueryOptions) String() string {
 	return fmt.Sprintf(
 		"[cl=%v, positionalVals=%v, namedVals=%v, skip=%v, psize=%v, state=%v, serialCl=%v]",
@@ -128,7 +151,98 @@ func (o *QueryOptions) Flags() primitive.QueryFlag {
 	if o.ContinuousPagingOptions != nil {
 		flags = flags.Add(primitive.QueryFlagDseWithContinuousPagingOptions)
 	}
-	return flags
+	return flags.Add(o.RawFlags.Remove(knownQueryFlags))
+}
+
+// Validate checks these options against the given protocol version before they are encoded, and returns a
+// ValidationErrors reporting all the incompatibilities found, e.g. a keyspace set with protocol version 4, or both
+// positional and named values set; it returns nil if the options are valid. Note that encoding is more lenient: it
+// silently ignores the named values when positional values are also set, as well as the fields not supported by the
+// protocol version, except for the flags themselves.
+func (o *QueryOptions) Validate(version primitive.ProtocolVersion) error {
+	var errs ValidationErrors
+	unsupported := func(field string, flag primitive.QueryFlag) {
+		if !version.SupportsQueryFlag(flag) {
+			errs = append(errs, fmt.Errorf("%s is not supported in %v", field, version))
+		}
+	}
+	if err := primitive.CheckValidConsistencyLevel(o.Consistency); err != nil {
+		errs = append(errs, err)
+	}
+	if o.PositionalValues != nil && o.NamedValues != nil {
+		errs = append(errs, errors.New("positional and named values cannot be both set"))
+	}
+	if o.PositionalValues != nil {
+		unsupported("positional values", primitive.QueryFlagValues)
+		for i, value := range o.PositionalValues {
+			if err := validateValue(fmt.Sprintf("positional value %d", i), value, version); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if o.NamedValues != nil {
+		unsupported("named values", primitive.QueryFlagValueNames)
+		names := make([]string, 0, len(o.NamedValues))
+		for name := range o.NamedValues {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := validateValue("named value "+name, o.NamedValues[name], version); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if o.SkipMetadata {
+		unsupported("skip metadata", primitive.QueryFlagSkipMetadata)
+	}
+	if o.PageSize > 0 {
+		unsupported("page size", primitive.QueryFlagPageSize)
+	}
+	if o.PageSizeInBytes {
+		unsupported("page size in bytes", primitive.QueryFlagDsePageSizeBytes)
+	}
+	if o.PagingState != nil {
+		unsupported("paging state", primitive.QueryFlagPagingState)
+	}
+	if o.SerialConsistency != nil {
+		unsupported("serial consistency", primitive.QueryFlagSerialConsistency)
+		if err := primitive.CheckSerialConsistencyLevel(*o.SerialConsistency); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if o.DefaultTimestamp != nil {
+		unsupported("default timestamp", primitive.QueryFlagDefaultTimestamp)
+		if *o.DefaultTimestamp == math.MinInt64 {
+			errs = append(errs, fmt.Errorf("default timestamp cannot be %d", int64(math.MinInt64)))
+		}
+	}
+	if o.Keyspace != "" {
+		unsupported("keyspace", primitive.QueryFlagWithKeyspace)
+	}
+	if o.NowInSeconds != nil {
+		unsupported("now-in-seconds", primitive.QueryFlagNowInSeconds)
+	}
+	if o.ContinuousPagingOptions != nil {
+		unsupported("continuous paging options", primitive.QueryFlagDseWithContinuousPagingOptions)
+	}
+	return errs.orNil()
+}
+
+func validateValue(name string, value *primitive.Value, version primitive.ProtocolVersion) error {
+	if value == nil {
+		return fmt.Errorf("%s is nil", name)
+	}
+	switch value.Type {
+	case primitive.ValueTypeRegular, primitive.ValueTypeNull:
+	case primitive.ValueTypeUnset:
+		if !version.SupportsUnsetValues() {
+			return fmt.Errorf("%s is unset, but unset values are not supported in %v", name, version)
+		}
+	default:
+		return fmt.Errorf("%s has unknown type: %v", name, value.Type)
+	}
+	return nil
 }
 
 func EncodeQueryOptions(options *QueryOptions, dest io.Writer, version primitive.ProtocolVersion) (err error) {
@@ -255,13 +369,19 @@ func LengthOfQueryOptions(options *QueryOptions, version primitive.ProtocolVersi
 }
 
 func DecodeQueryOptions(source io.Reader, version primitive.ProtocolVersion) (options *QueryOptions, err error) {
+	return decodeQueryOptions(source, version, nil)
+}
+
+// decodeQueryOptions decodes query options; unknown consistency levels and flags are reported to the given handler, or
+// make decoding fail if the handler is nil.
+func decodeQueryOptions(source io.Reader, version primitive.ProtocolVersion, onAnomaly AnomalyHandler) (options *QueryOptions, err error) {
 	options = &QueryOptions{}
 	var consistency uint16
 	if consistency, err = primitive.ReadShort(source); err != nil {
 		return nil, fmt.Errorf("cannot read consistency: %w", err)
 	}
 	options.Consistency = primitive.ConsistencyLevel(consistency)
-	if err = primitive.CheckValidConsistencyLevel(options.Consistency); err != nil {
+	if err = checkDecodedConsistencyLevel("consistency", options.Consistency, onAnomaly); err != nil {
 		return nil, err
 	}
 	var flags primitive.QueryFlag
@@ -277,6 +397,18 @@ func DecodeQueryOptions(source io.Reader, version primitive.ProtocolVersion) (op
 	if err != nil {
 		return nil, fmt.Errorf("cannot read flags: %w", err)
 	}
+	if unknown := flags.Remove(knownQueryFlags); unknown != 0 {
+		// without an anomaly handler, unknown bits are passed through, as they have always been tolerated
+		if onAnomaly != nil {
+			if err = onAnomaly(Anomaly{
+				Kind:    AnomalyUnexpectedFlags,
+				Message: fmt.Sprintf("unknown query flags: %#.8x", uint32(unknown)),
+			}); err != nil {
+				return nil, err
+			}
+		}
+		options.RawFlags = unknown
+	}
 	if flags.Contains(primitive.QueryFlagValues) {
 		if flags.Contains(primitive.QueryFlagValueNames) {
 			options.NamedValues, err = primitive.ReadNamedValues(source, version)
@@ -307,7 +439,7 @@ func DecodeQueryOptions(source io.Reader, version primitive.ProtocolVersion) (op
 			return nil, fmt.Errorf("cannot read serial consistency: %w", err)
 		}
 		optionsSerialConsistency := primitive.ConsistencyLevel(optionsSerialConsistencyUint)
-		if err = primitive.CheckValidConsistencyLevel(optionsSerialConsistency); err != nil {
+		if err = checkDecodedConsistencyLevel("serial consistency", optionsSerialConsistency, onAnomaly); err != nil {
 			return nil, err
 		}
 		options.SerialConsistency = &optionsSerialConsistency
@@ -338,3 +470,12 @@ func DecodeQueryOptions(source io.Reader, version primitive.ProtocolVersion) (op
 	}
 	return options, nil
 }
+
+func checkDecodedConsistencyLevel(name string, consistency primitive.ConsistencyLevel, onAnomaly AnomalyHandler) error {
+	if onAnomaly == nil {
+		return primitive.CheckValidConsistencyLevel(consistency)
+	} else if !consistency.IsValid() {
+		return onAnomaly(unknownConsistencyAnomaly(name, consistency))
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ package message
 import (
 	"bytes"
 	"errors"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -1956,3 +1957,86 @@ func TestQueryCodec_Decode(t *testing.T) {
 		}
 	})
 }
+
+func TestQueryOptions_Validate(t *testing.T) {
+	serial := primitive.ConsistencyLevelSerial
+	quorum := primitive.ConsistencyLevelQuorum
+	timestamp := int64(123)
+	minTimestamp := int64(math.MinInt64)
+	now := int32(123)
+	tests := []struct {
+		name     string
+		options  *QueryOptions
+		version  primitive.ProtocolVersion
+		expected string
+	}{
+		{"empty", &QueryOptions{}, primitive.ProtocolVersion4, ""},
+		{
+			"all valid",
+			&QueryOptions{
+				Consistency:       primitive.ConsistencyLevelOne,
+				PositionalValues:  []*primitive.Value{primitive.NewValue([]byte{1}), primitive.NewUnsetValue()},
+				SkipMetadata:      true,
+				PageSize:          100,
+				PagingState:       []byte{1},
+				SerialConsistency: &serial,
+				DefaultTimestamp:  &timestamp,
+				Keyspace:          "ks1",
+				NowInSeconds:      &now,
+			},
+			primitive.ProtocolVersion5,
+			"",
+		},
+		{
+			"keyspace and now-in-seconds",
+			&QueryOptions{Keyspace: "ks1", NowInSeconds: &now},
+			primitive.ProtocolVersion4,
+			"keyspace is not supported in ProtocolVersion OSS 4; now-in-seconds is not supported in ProtocolVersion OSS 4",
+		},
+		{
+			"now-in-seconds DSE",
+			&QueryOptions{NowInSeconds: &now},
+			primitive.ProtocolVersionDse1,
+			"now-in-seconds is not supported in ProtocolVersion DSE 1",
+		},
+		{
+			"positional and named values",
+			&QueryOptions{
+				PositionalValues: []*primitive.Value{primitive.NewUnsetValue(), nil},
+				NamedValues:      map[string]*primitive.Value{"b": primitive.NewUnsetValue(), "a": {Type: 42}},
+			},
+			primitive.ProtocolVersion3,
+			"positional and named values cannot be both set; " +
+				"positional value 0 is unset, but unset values are not supported in ProtocolVersion OSS 3; " +
+				"positional value 1 is nil; " +
+				"named value a has unknown type: 42; " +
+				"named value b is unset, but unset values are not supported in ProtocolVersion OSS 3",
+		},
+		{
+			"invalid levels and timestamp",
+			&QueryOptions{Consistency: 42, SerialConsistency: &quorum, DefaultTimestamp: &minTimestamp},
+			primitive.ProtocolVersion4,
+			"invalid consistency level: ConsistencyLevel ? [0X002A]; " +
+				"invalid serial consistency level: ConsistencyLevel QUORUM [0x0004]; " +
+				"default timestamp cannot be -9223372036854775808",
+		},
+		{
+			"DSE options",
+			&QueryOptions{PageSize: 100, PageSizeInBytes: true, ContinuousPagingOptions: &ContinuousPagingOptions{}},
+			primitive.ProtocolVersion4,
+			"page size in bytes is not supported in ProtocolVersion OSS 4; continuous paging options is not supported in ProtocolVersion OSS 4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate(tt.version)
+			if tt.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expected)
+				var validationErrors ValidationErrors
+				assert.True(t, errors.As(err, &validationErrors))
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// RawMessage is a message with an opcode that the frame codec does not know about, with its contents left undecoded.
+// Raw messages are produced by lenient frame codecs when decoding frames with unknown opcodes, e.g. vendor-specific
+// ones, so that proxies can forward them; they are encoded verbatim by all frame codecs. See also
+// NewRawMessageCodec.
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=github.com/datastax/go-cassandra-native-protocol/message.Message
+type RawMessage struct {
+	// The message opcode.
+	OpCode primitive.OpCode
+	// Whether the message is a response, as told by the frame header.
+	Response bool
+	// The raw bytes of the message, that is, the frame body after the tracing id, custom payload and warnings, if any.
+	Body []byte
+}
+
+func (m *RawMessage) IsResponse() bool {
+	return m.Response
+}
+
+func (m *RawMessage) GetOpCode() primitive.OpCode {
+	return m.OpCode
+}
+
+func (m *RawMessage) String() string {
+	return fmt.Sprintf("RAW %v (body=%x)", m.OpCode, m.Body)
+}
+
+// NewRawMessageCodec returns a codec for the given opcode that decodes messages as RawMessage, and encodes RawMessage
+// verbatim. It can be registered in frame codecs to forward messages of a vendor-specific opcode without decoding
+// them, even in strict decoding mode.
+func NewRawMessageCodec(opCode primitive.OpCode, isResponse bool) Codec {
+	return &rawMessageCodec{opCode: opCode, isResponse: isResponse}
+}
+
+type rawMessageCodec struct {
+	opCode     primitive.OpCode
+	isResponse bool
+}
+
+func (c *rawMessageCodec) Encode(msg Message, dest io.Writer, _ primitive.ProtocolVersion) error {
+	raw, ok := msg.(*RawMessage)
+	if !ok {
+		return fmt.Errorf("expected *message.RawMessage, got %T", msg)
+	}
+	if _, err := dest.Write(raw.Body); err != nil {
+		return fmt.Errorf("cannot write RawMessage.Body: %w", err)
+	}
+	return nil
+}
+
+func (c *rawMessageCodec) EncodedLength(msg Message, _ primitive.ProtocolVersion) (int, error) {
+	raw, ok := msg.(*RawMessage)
+	if !ok {
+		return -1, fmt.Errorf("expected *message.RawMessage, got %T", msg)
+	}
+	return len(raw.Body), nil
+}
+
+func (c *rawMessageCodec) Decode(source io.Reader, _ primitive.ProtocolVersion) (Message, error) {
+	raw := &RawMessage{OpCode: c.opCode, Response: c.isResponse}
+	if body, err := io.ReadAll(source); err != nil {
+		return nil, fmt.Errorf("cannot read RawMessage.Body: %w", err)
+	} else if len(body) > 0 {
+		raw.Body = body
+	}
+	return raw, nil
+}
+
+func (c *rawMessageCodec) GetOpCode() primitive.OpCode {
+	return c.opCode
+}
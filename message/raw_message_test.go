@@ -0,0 +1,60 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestRawMessageCodec(t *testing.T) {
+	codec := NewRawMessageCodec(0x42, true)
+	assert.Equal(t, primitive.OpCode(0x42), codec.GetOpCode())
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			tests := []struct {
+				name    string
+				msg     *RawMessage
+				encoded []byte
+			}{
+				{"empty", &RawMessage{OpCode: 0x42, Response: true}, nil},
+				{"non empty", &RawMessage{OpCode: 0x42, Response: true, Body: []byte{1, 2, 3}}, []byte{1, 2, 3}},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					length, err := codec.EncodedLength(tt.msg, version)
+					require.NoError(t, err)
+					assert.Equal(t, len(tt.encoded), length)
+					dest := &bytes.Buffer{}
+					require.NoError(t, codec.Encode(tt.msg, dest, version))
+					assert.Equal(t, tt.encoded, dest.Bytes())
+					decoded, err := codec.Decode(bytes.NewReader(tt.encoded), version)
+					require.NoError(t, err)
+					assert.Equal(t, tt.msg, decoded)
+				})
+			}
+		})
+	}
+	_, err := codec.EncodedLength(&Options{}, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "expected *message.RawMessage, got *message.Options")
+	assert.EqualError(t, codec.Encode(&Options{}, &bytes.Buffer{}, primitive.ProtocolVersion4),
+		"expected *message.RawMessage, got *message.Options")
+	assert.Equal(t, "RAW OpCode ? [0X42] (body=010203)", (&RawMessage{OpCode: 0x42, Body: []byte{1, 2, 3}}).String())
+}
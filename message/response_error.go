@@ -0,0 +1,103 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Sentinel errors classifying the errors returned by ToGoError; use errors.Is to match them.
+var (
+	// ErrFatal matches fatal errors (ServerError, ProtocolError and AuthenticationError), after which the connection
+	// should be closed.
+	ErrFatal = errors.New("fatal error")
+	// ErrRequestExecution matches errors that occurred while executing the request, such as timeouts and failures.
+	ErrRequestExecution = errors.New("request execution error")
+	// ErrQueryValidation matches errors denoting an invalid request, such as syntax errors and unprepared queries.
+	ErrQueryValidation = errors.New("query validation error")
+	// ErrTimeout matches ReadTimeout and WriteTimeout errors.
+	ErrTimeout = errors.New("timeout")
+	// ErrUnavailable matches Unavailable errors.
+	ErrUnavailable = errors.New("unavailable")
+	// ErrRetryable matches errors denoting a transient condition; see primitive.ErrorCode.IsRetryable.
+	ErrRetryable = errors.New("retryable error")
+)
+
+// ResponseError is a Go error wrapping an Error message. Use errors.As to retrieve the original message, and
+// errors.Is with ErrFatal, ErrRequestExecution, ErrQueryValidation, ErrTimeout, ErrUnavailable or ErrRetryable to
+// classify it.
+type ResponseError struct {
+	Response Error
+}
+
+// ToGoError wraps the given Error message into a *ResponseError. It returns nil if the message is nil.
+func ToGoError(msg Error) error {
+	if msg == nil {
+		return nil
+	}
+	return &ResponseError{Response: msg}
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprint(e.Response)
+}
+
+// Is reports whether this error matches the given sentinel error.
+func (e *ResponseError) Is(target error) bool {
+	code := e.Response.GetErrorCode()
+	switch target {
+	case ErrFatal:
+		return code.IsFatalError()
+	case ErrRequestExecution:
+		return code.IsRequestExecutionError()
+	case ErrQueryValidation:
+		return code.IsQueryValidationError()
+	case ErrTimeout:
+		return code.IsTimeout()
+	case ErrUnavailable:
+		return code.IsUnavailable()
+	case ErrRetryable:
+		return code.IsRetryable()
+	}
+	return false
+}
+
+// ErrorCodeOf returns the error code of the Error message wrapped in the given error chain, if any.
+func ErrorCodeOf(err error) (primitive.ErrorCode, bool) {
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.Response.GetErrorCode(), true
+	}
+	return 0, false
+}
+
+// IsTimeout returns true if the given error chain contains a ReadTimeout or WriteTimeout error.
+func IsTimeout(err error) bool {
+	return errors.Is(err, ErrTimeout)
+}
+
+// IsUnavailable returns true if the given error chain contains an Unavailable error.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrUnavailable)
+}
+
+// IsRetryable returns true if the given error chain contains an error denoting a transient condition, in which the
+// same request may succeed when retried. See primitive.ErrorCode.IsRetryable.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRetryable)
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestToGoError(t *testing.T) {
+	assert.Nil(t, ToGoError(nil))
+	msg := &ReadTimeout{"BOOM", primitive.ConsistencyLevelQuorum, 1, 2, false}
+	err := fmt.Errorf("query failed: %w", ToGoError(msg))
+	assert.EqualError(t, err, "query failed: "+msg.String())
+	var responseErr *ResponseError
+	require.True(t, errors.As(err, &responseErr))
+	assert.Same(t, msg, responseErr.Response)
+	code, ok := ErrorCodeOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, primitive.ErrorCodeReadTimeout, code)
+	_, ok = ErrorCodeOf(errors.New("not a response error"))
+	assert.False(t, ok)
+}
+
+func TestResponseError_Is(t *testing.T) {
+	sentinels := []error{ErrFatal, ErrRequestExecution, ErrQueryValidation, ErrTimeout, ErrUnavailable, ErrRetryable}
+	tests := []struct {
+		name     string
+		input    Error
+		expected []error
+	}{
+		{"server error", &ServerError{"BOOM"}, []error{ErrFatal}},
+		{"authentication error", &AuthenticationError{"BOOM"}, []error{ErrFatal}},
+		{"unavailable", &Unavailable{"BOOM", primitive.ConsistencyLevelQuorum, 2, 1}, []error{ErrRequestExecution, ErrUnavailable, ErrRetryable}},
+		{"overloaded", &Overloaded{"BOOM"}, []error{ErrRequestExecution, ErrRetryable}},
+		{"read timeout", &ReadTimeout{ErrorMessage: "BOOM"}, []error{ErrRequestExecution, ErrTimeout, ErrRetryable}},
+		{"write timeout", &WriteTimeout{ErrorMessage: "BOOM"}, []error{ErrRequestExecution, ErrTimeout, ErrRetryable}},
+		{"write failure", &WriteFailure{ErrorMessage: "BOOM"}, []error{ErrRequestExecution}},
+		{"cdc write failure", &CdcWriteFailure{"BOOM"}, []error{ErrRequestExecution}},
+		{"cas write unknown", &CasWriteUnknown{ErrorMessage: "BOOM"}, []error{ErrRequestExecution}},
+		{"syntax error", &SyntaxError{"BOOM"}, []error{ErrQueryValidation}},
+		{"unprepared", &Unprepared{"BOOM", []byte{1}}, []error{ErrQueryValidation}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ToGoError(tt.input)
+			for _, sentinel := range sentinels {
+				assert.Equal(t, containsError(tt.expected, sentinel), errors.Is(err, sentinel), sentinel.Error())
+			}
+			assert.Equal(t, containsError(tt.expected, ErrTimeout), IsTimeout(err))
+			assert.Equal(t, containsError(tt.expected, ErrUnavailable), IsUnavailable(err))
+			assert.Equal(t, containsError(tt.expected, ErrRetryable), IsRetryable(err))
+		})
+	}
+	assert.False(t, IsRetryable(nil))
+	assert.False(t, IsTimeout(errors.New("timeout")))
+}
+
+func containsError(errs []error, target error) bool {
+	for _, err := range errs {
+		if err == target {
+			return true
+		}
+	}
+	return false
+}
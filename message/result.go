@@ -96,6 +96,9 @@ type SchemaChangeResult struct {
 	// If the schema object affected by the change is a function or an aggregate, this field contains its arguments.
 	// Otherwise, this field is irrelevant. Valid from protocol version 4 onwards.
 	Arguments []string
+	// If the target is unknown, this field contains the raw bytes following the keyspace name. Unknown targets are only
+	// accepted by lenient codecs, see NewLenientResultCodec; they write these bytes back verbatim.
+	RawTargetOptions []byte
 }
 
 func (m *SchemaChangeResult) IsResponse() bool {
@@ -125,9 +128,9 @@ func (m *SchemaChangeResult) String() string {
 // +k8s:deepcopy-gen=true
 // +k8s:deepcopy-gen:interfaces=github.com/datastax/go-cassandra-native-protocol/message.Message
 type PreparedResult struct {
-	PreparedQueryId []byte
+	PreparedQueryId PreparedId
 	// The result set metadata id; valid for protocol version 5, if the prepared statement is a SELECT. Also valid in DSE v2. See Execute.
-	ResultMetadataId []byte
+	ResultMetadataId ResultMetadataId
 	// Reflects the prepared statement's bound variables, if any, or empty (but not nil) if there are no bound variables.
 	VariablesMetadata *VariablesMetadata
 	// When the prepared statement is a SELECT, reflects the result set columns; empty (but not nil) otherwise.
@@ -184,7 +187,17 @@ func (m *RowsResult) String() string {
 
 // CODEC
 
-type resultCodec struct{}
+// NewLenientResultCodec returns a RESULT codec that tolerates unknown schema change targets: instead of failing, it
+// preserves such targets, along with the raw bytes following the keyspace name, in
+// SchemaChangeResult.RawTargetOptions. The returned codec can be passed to frame.NewCodec to override the default
+// RESULT codec.
+func NewLenientResultCodec() Codec {
+	return &resultCodec{lenient: true}
+}
+
+type resultCodec struct {
+	lenient bool
+}
 
 func (c *resultCodec) Encode(msg Message, dest io.Writer, version primitive.ProtocolVersion) (err error) {
 	result, ok := msg.(Result)
@@ -220,7 +233,7 @@ func (c *resultCodec) Encode(msg Message, dest io.Writer, version primitive.Prot
 			return fmt.Errorf("cannot write SchemaChangeResult.ChangeType: %w", err)
 		}
 		if version >= primitive.ProtocolVersion3 {
-			if err = primitive.CheckValidSchemaChangeTarget(sce.Target, version); err != nil {
+			if err = checkSchemaChangeTarget(sce.Target, version, c.lenient); err != nil {
 				return err
 			} else if err = primitive.WriteString(string(sce.Target), dest); err != nil {
 				return fmt.Errorf("cannot write SchemaChangeResult.Target: %w", err)
@@ -251,28 +264,32 @@ func (c *resultCodec) Encode(msg Message, dest io.Writer, version primitive.Prot
 				if err = primitive.WriteStringList(sce.Arguments, dest); err != nil {
 					return fmt.Errorf("cannot write SchemaChangeResult.Arguments: %w", err)
 				}
+			default:
+				if _, err = dest.Write(sce.RawTargetOptions); err != nil {
+					return fmt.Errorf("cannot write SchemaChangeResult.RawTargetOptions: %w", err)
+				}
 			}
 		} else {
-			if err = primitive.CheckValidSchemaChangeTarget(sce.Target, version); err != nil {
+			if err = checkSchemaChangeTarget(sce.Target, version, c.lenient); err != nil {
 				return err
 			}
 			if sce.Keyspace == "" {
 				return errors.New("RESULT SchemaChange: cannot write empty keyspace")
 			} else if err = primitive.WriteString(sce.Keyspace, dest); err != nil {
-				return fmt.Errorf("cannot write SchemaChangeEvent.Keyspace: %w", err)
+				return fmt.Errorf("cannot write SchemaChangeResult.Keyspace: %w", err)
 			}
 			switch sce.Target {
 			case primitive.SchemaChangeTargetKeyspace:
 				if sce.Object != "" {
 					return errors.New("RESULT SchemaChange: table must be empty for keyspace targets")
 				} else if err = primitive.WriteString("", dest); err != nil {
-					return fmt.Errorf("cannot write SchemaChangeEvent.Object: %w", err)
+					return fmt.Errorf("cannot write SchemaChangeResult.Object: %w", err)
 				}
 			case primitive.SchemaChangeTargetTable:
 				if sce.Object == "" {
 					return errors.New("RESULT SchemaChange: cannot write empty table")
 				} else if err = primitive.WriteString(sce.Object, dest); err != nil {
-					return fmt.Errorf("cannot write SchemaChangeEvent.Object: %w", err)
+					return fmt.Errorf("cannot write SchemaChangeResult.Object: %w", err)
 				}
 			}
 		}
@@ -344,7 +361,7 @@ func (c *resultCodec) EncodedLength(msg Message, version primitive.ProtocolVersi
 			return -1, fmt.Errorf("expected *message.SchemaChangeResult, got %T", msg)
 		}
 		length += primitive.LengthOfString(string(sc.ChangeType))
-		if err = primitive.CheckValidSchemaChangeTarget(sc.Target, version); err != nil {
+		if err = checkSchemaChangeTarget(sc.Target, version, c.lenient); err != nil {
 			return -1, err
 		}
 		if version >= primitive.ProtocolVersion3 {
@@ -361,6 +378,8 @@ func (c *resultCodec) EncodedLength(msg Message, version primitive.ProtocolVersi
 			case primitive.SchemaChangeTargetFunction:
 				length += primitive.LengthOfString(sc.Object)
 				length += primitive.LengthOfStringList(sc.Arguments)
+			default:
+				length += len(sc.RawTargetOptions)
 			}
 		} else {
 			length += primitive.LengthOfString(sc.Keyspace)
@@ -438,7 +457,7 @@ func (c *resultCodec) Decode(source io.Reader, version primitive.ProtocolVersion
 				return nil, fmt.Errorf("cannot read SchemaChangeResult.Target: %w", err)
 			}
 			sc.Target = primitive.SchemaChangeTarget(target)
-			if err = primitive.CheckValidSchemaChangeTarget(sc.Target, version); err != nil {
+			if err = checkSchemaChangeTarget(sc.Target, version, c.lenient); err != nil {
 				return nil, err
 			}
 			if sc.Keyspace, err = primitive.ReadString(source); err != nil {
@@ -462,14 +481,20 @@ func (c *resultCodec) Decode(source io.Reader, version primitive.ProtocolVersion
 					return nil, fmt.Errorf("cannot read SchemaChangeResult.Arguments: %w", err)
 				}
 			default:
-				return nil, fmt.Errorf("unknown schema change target: %v", sc.Target)
+				if !c.lenient {
+					return nil, fmt.Errorf("unknown schema change target: %v", sc.Target)
+				} else if sc.RawTargetOptions, err = io.ReadAll(source); err != nil {
+					return nil, fmt.Errorf("cannot read SchemaChangeResult.RawTargetOptions: %w", err)
+				} else if len(sc.RawTargetOptions) == 0 {
+					sc.RawTargetOptions = nil
+				}
 			}
 		} else {
 			if sc.Keyspace, err = primitive.ReadString(source); err != nil {
-				return nil, fmt.Errorf("cannot read SchemaChangeEvent.Keyspace: %w", err)
+				return nil, fmt.Errorf("cannot read SchemaChangeResult.Keyspace: %w", err)
 			}
 			if sc.Object, err = primitive.ReadString(source); err != nil {
-				return nil, fmt.Errorf("cannot read SchemaChangeEvent.Object: %w", err)
+				return nil, fmt.Errorf("cannot read SchemaChangeResult.Object: %w", err)
 			}
 			if sc.Object == "" {
 				sc.Target = primitive.SchemaChangeTargetKeyspace
@@ -504,14 +529,25 @@ func (c *resultCodec) Decode(source io.Reader, version primitive.ProtocolVersion
 		if rowsCount, err = primitive.ReadInt(source); err != nil {
 			return nil, fmt.Errorf("cannot read RESULT Rows data length: %w", err)
 		}
-		rows.Data = make(RowSet, rowsCount)
+		if rowsCount < 0 {
+			return nil, fmt.Errorf("invalid RESULT Rows data length: %d", rowsCount)
+		} else if err = primitive.CheckCollectionLength(source, "RESULT Rows data", int(rowsCount)); err != nil {
+			return nil, err
+		} else if rowsCount > 0 && rows.Metadata.ColumnCount == 0 {
+			// rows without columns would not consume any input
+			return nil, fmt.Errorf("invalid RESULT Rows data: %d rows without columns", rowsCount)
+		}
+		rows.Data = make(RowSet, 0, primitive.PreallocatedLength(int(rowsCount)))
 		for i := 0; i < int(rowsCount); i++ {
-			rows.Data[i] = make(Row, rows.Metadata.ColumnCount)
+			row := make(Row, 0, primitive.PreallocatedLength(int(rows.Metadata.ColumnCount)))
 			for j := 0; j < int(rows.Metadata.ColumnCount); j++ {
-				if rows.Data[i][j], err = primitive.ReadBytes(source); err != nil {
+				if column, err := primitive.ReadBytes(source); err != nil {
 					return nil, fmt.Errorf("cannot read RESULT Rows data row %d col %d: %w", i, j, err)
+				} else {
+					row = append(row, column)
 				}
 			}
+			rows.Data = append(rows.Data, row)
 		}
 		return rows, nil
 	default:
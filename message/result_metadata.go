@@ -59,7 +59,7 @@ type RowsMetadata struct {
 	// PagingState is a [bytes] value. If provided, this means that this page of results is not the last page..
 	PagingState []byte
 	// Valid for protocol version 5 and DSE protocol version 2 only.
-	NewResultMetadataId []byte
+	NewResultMetadataId ResultMetadataId
 	// Valid for DSE protocol versions only.
 	ContinuousPageNumber int32
 	// Valid for DSE protocol versions only.
@@ -130,7 +130,7 @@ func lengthOfVariablesMetadata(metadata *VariablesMetadata, version primitive.Pr
 		length += primitive.LengthOfShort * len(metadata.PkIndices)
 	}
 	if len(metadata.Columns) > 0 {
-		globalTableSpec := metadata.Flags()&primitive.VariablesFlagGlobalTablesSpec > 0
+		globalTableSpec := metadata.Flags().Contains(primitive.VariablesFlagGlobalTablesSpec)
 		var lcs int
 		if lcs, err = lengthOfColumnsMetadata(globalTableSpec, metadata.Columns, version); err != nil {
 			return -1, fmt.Errorf("cannot compute length of RESULT Prepared variables metadata column cols: %w", err)
@@ -150,6 +150,8 @@ func decodeVariablesMetadata(source io.Reader, version primitive.ProtocolVersion
 	var columnCount int32
 	if columnCount, err = primitive.ReadInt(source); err != nil {
 		return nil, fmt.Errorf("cannot read RESULT Prepared variables metadata column count: %w", err)
+	} else if err = primitive.CheckCollectionLength(source, "RESULT Prepared variables metadata columns", int(columnCount)); err != nil {
+		return nil, err
 	}
 	if version >= primitive.ProtocolVersion4 {
 		var pkCount int32
@@ -157,10 +159,12 @@ func decodeVariablesMetadata(source io.Reader, version primitive.ProtocolVersion
 			return nil, fmt.Errorf("cannot read RESULT Prepared variables metadata pk indices length: %w", err)
 		}
 		if pkCount > 0 {
-			metadata.PkIndices = make([]uint16, pkCount)
+			metadata.PkIndices = make([]uint16, 0, primitive.PreallocatedLength(int(pkCount)))
 			for i := 0; i < int(pkCount); i++ {
-				if metadata.PkIndices[i], err = primitive.ReadShort(source); err != nil {
+				if pkIndex, err := primitive.ReadShort(source); err != nil {
 					return nil, fmt.Errorf("cannot read RESULT Prepared variables metadata pk index element %d: %w", i, err)
+				} else {
+					metadata.PkIndices = append(metadata.PkIndices, pkIndex)
 				}
 			}
 		}
@@ -179,7 +183,9 @@ func encodeRowsMetadata(metadata *RowsMetadata, dest io.Writer, version primitiv
 		metadata = &RowsMetadata{}
 	}
 	flags := metadata.Flags()
-	if err = primitive.WriteInt(int32(flags), dest); err != nil {
+	if err = checkRowsFlags(flags, version); err != nil {
+		return err
+	} else if err = primitive.WriteInt(int32(flags), dest); err != nil {
 		return fmt.Errorf("cannot write RESULT Rows metadata flags: %w", err)
 	}
 	columnSpecsLength := len(metadata.Columns)
@@ -208,7 +214,7 @@ func encodeRowsMetadata(metadata *RowsMetadata, dest io.Writer, version primitiv
 			return fmt.Errorf("cannot write RESULT Rows metadata continuous page number: %w", err)
 		}
 	}
-	if flags&primitive.RowsFlagNoMetadata == 0 && columnSpecsLength > 0 {
+	if !flags.Contains(primitive.RowsFlagNoMetadata) && columnSpecsLength > 0 {
 		globalTableSpec := flags.Contains(primitive.RowsFlagGlobalTablesSpec)
 		if err = encodeColumnsMetadata(globalTableSpec, metadata.Columns, dest, version); err != nil {
 			return fmt.Errorf("cannot write RESULT Rows metadata column specs: %w", err)
@@ -224,6 +230,9 @@ func lengthOfRowsMetadata(metadata *RowsMetadata, version primitive.ProtocolVers
 	length += primitive.LengthOfInt // flags
 	length += primitive.LengthOfInt // column count
 	flags := metadata.Flags()
+	if err = checkRowsFlags(flags, version); err != nil {
+		return -1, err
+	}
 	if flags.Contains(primitive.RowsFlagHasMorePages) {
 		length += primitive.LengthOfBytes(metadata.PagingState)
 	}
@@ -233,7 +242,7 @@ func lengthOfRowsMetadata(metadata *RowsMetadata, version primitive.ProtocolVers
 	if flags.Contains(primitive.RowsFlagDseContinuousPaging) {
 		length += primitive.LengthOfInt // continuous page number
 	}
-	if flags&primitive.RowsFlagNoMetadata == 0 && len(metadata.Columns) > 0 {
+	if !flags.Contains(primitive.RowsFlagNoMetadata) && len(metadata.Columns) > 0 {
 		globalTableSpec := flags.Contains(primitive.RowsFlagGlobalTablesSpec)
 		var lengthOfCols int
 		if lengthOfCols, err = lengthOfColumnsMetadata(globalTableSpec, metadata.Columns, version); err != nil {
@@ -251,8 +260,15 @@ func decodeRowsMetadata(source io.Reader, version primitive.ProtocolVersion) (me
 		return nil, fmt.Errorf("cannot read RESULT Rows metadata flags: %w", err)
 	}
 	var flags = primitive.RowsFlag(f)
+	if err = checkRowsFlags(flags, version); err != nil {
+		return nil, err
+	}
 	if metadata.ColumnCount, err = primitive.ReadInt(source); err != nil {
 		return nil, fmt.Errorf("cannot read RESULT Rows metadata column count: %w", err)
+	} else if metadata.ColumnCount < 0 {
+		return nil, fmt.Errorf("invalid RESULT Rows metadata column count: %d", metadata.ColumnCount)
+	} else if err = primitive.CheckCollectionLength(source, "RESULT Rows metadata columns", int(metadata.ColumnCount)); err != nil {
+		return nil, err
 	}
 	if flags.Contains(primitive.RowsFlagHasMorePages) {
 		if metadata.PagingState, err = primitive.ReadBytes(source); err != nil {
@@ -270,7 +286,7 @@ func decodeRowsMetadata(source io.Reader, version primitive.ProtocolVersion) (me
 		}
 		metadata.LastContinuousPage = flags.Contains(primitive.RowsFlagDseLastContinuousPage)
 	}
-	if flags&primitive.RowsFlagNoMetadata == 0 {
+	if !flags.Contains(primitive.RowsFlagNoMetadata) {
 		globalTableSpec := flags.Contains(primitive.RowsFlagGlobalTablesSpec)
 		if metadata.Columns, err = decodeColumnsMetadata(globalTableSpec, metadata.ColumnCount, source, version); err != nil {
 			return nil, fmt.Errorf("cannot read RESULT Rows metadata column cols: %w", err)
@@ -279,6 +295,27 @@ func decodeRowsMetadata(source io.Reader, version primitive.ProtocolVersion) (me
 	return metadata, nil
 }
 
+// rowsFlags lists all the known rows flags; flags that are not supported by a given protocol version, e.g.
+// METADATA_CHANGED in protocol versions lesser than 5, are rejected by checkRowsFlags, since the fields they announce
+// would otherwise be written to, or read from, the wrong positions.
+var rowsFlags = []primitive.RowsFlag{
+	primitive.RowsFlagGlobalTablesSpec,
+	primitive.RowsFlagHasMorePages,
+	primitive.RowsFlagNoMetadata,
+	primitive.RowsFlagMetadataChanged,
+	primitive.RowsFlagDseContinuousPaging,
+	primitive.RowsFlagDseLastContinuousPage,
+}
+
+func checkRowsFlags(flags primitive.RowsFlag, version primitive.ProtocolVersion) error {
+	for _, flag := range rowsFlags {
+		if flags.Contains(flag) && !version.SupportsRowsFlag(flag) {
+			return fmt.Errorf("invalid RESULT Rows metadata flag for %v: %v", version, flag)
+		}
+	}
+	return nil
+}
+
 func encodeColumnsMetadata(globalTableSpec bool, cols []*ColumnMetadata, dest io.Writer, version primitive.ProtocolVersion) (err error) {
 	if globalTableSpec {
 		firstCol := cols[0]
@@ -340,29 +377,30 @@ func decodeColumnsMetadata(globalTableSpec bool, columnCount int32, source io.Re
 			return nil, fmt.Errorf("cannot read column col global table: %w", err)
 		}
 	}
-	cols = make([]*ColumnMetadata, columnCount)
+	cols = make([]*ColumnMetadata, 0, primitive.PreallocatedLength(int(columnCount)))
 	for i := 0; i < int(columnCount); i++ {
-		cols[i] = &ColumnMetadata{}
+		col := &ColumnMetadata{}
 		if globalTableSpec {
-			cols[i].Keyspace = globalKsName
+			col.Keyspace = globalKsName
 		} else {
-			if cols[i].Keyspace, err = primitive.ReadString(source); err != nil {
+			if col.Keyspace, err = primitive.ReadString(source); err != nil {
 				return nil, fmt.Errorf("cannot read column col %d keyspace: %w", i, err)
 			}
 		}
 		if globalTableSpec {
-			cols[i].Table = globalTableName
+			col.Table = globalTableName
 		} else {
-			if cols[i].Table, err = primitive.ReadString(source); err != nil {
+			if col.Table, err = primitive.ReadString(source); err != nil {
 				return nil, fmt.Errorf("cannot read column col %d table: %w", i, err)
 			}
 		}
-		if cols[i].Name, err = primitive.ReadString(source); err != nil {
+		if col.Name, err = primitive.ReadString(source); err != nil {
 			return nil, fmt.Errorf("cannot read column col %d name: %w", i, err)
 		}
-		if cols[i].Type, err = datatype.ReadDataType(source, version); err != nil {
+		if col.Type, err = datatype.ReadDataType(source, version); err != nil {
 			return nil, fmt.Errorf("cannot read column col %d type: %w", i, err)
 		}
+		cols = append(cols, col)
 	}
 	return cols, nil
 }
@@ -0,0 +1,137 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ResultMetadataCache caches the result set metadata of prepared statements, keyed by prepared id, so that EXECUTE
+// requests can ask the server to skip metadata in Rows RESULT responses (see QueryOptions.SkipMetadata).
+//
+// The cache is populated from PreparedResult responses with Put. When a Rows RESULT response is received for an
+// EXECUTE request, EffectiveMetadata returns the columns to use to decode its rows: the columns included in the
+// response if any, or the cached ones otherwise. When the response has the METADATA_CHANGED flag set (that is,
+// RowsMetadata.NewResultMetadataId is non-nil), the cache is updated with the new columns and result metadata id; use
+// ResultMetadataId to obtain the id to send in subsequent Execute requests.
+//
+// A ResultMetadataCache is safe for concurrent use.
+type ResultMetadataCache struct {
+	entries map[string]*resultMetadataEntry
+	lock    sync.RWMutex
+}
+
+type resultMetadataEntry struct {
+	resultMetadataId ResultMetadataId
+	columns          []*ColumnMetadata
+}
+
+// NewResultMetadataCache creates a new, empty ResultMetadataCache.
+func NewResultMetadataCache() *ResultMetadataCache {
+	return &ResultMetadataCache{entries: make(map[string]*resultMetadataEntry)}
+}
+
+// Put caches the result set metadata of the given prepared statement, replacing any metadata previously cached for
+// the same prepared id. Statements that do not return rows have no result set metadata and are not cached.
+func (c *ResultMetadataCache) Put(prepared *PreparedResult) {
+	if prepared == nil || prepared.ResultMetadata == nil || len(prepared.ResultMetadata.Columns) == 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries[string(prepared.PreparedQueryId)] = &resultMetadataEntry{
+		resultMetadataId: prepared.ResultMetadataId,
+		columns:          prepared.ResultMetadata.Columns,
+	}
+}
+
+// Evict removes the metadata cached for the given prepared id, e.g. after the server replied with an Unprepared
+// error.
+func (c *ResultMetadataCache) Evict(id PreparedId) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, string(id))
+}
+
+// Contains returns true if metadata is cached for the given prepared id.
+func (c *ResultMetadataCache) Contains(id PreparedId) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	_, found := c.entries[string(id)]
+	return found
+}
+
+// Columns returns the result set columns cached for the given prepared id, or nil if none is cached.
+func (c *ResultMetadataCache) Columns(id PreparedId) []*ColumnMetadata {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if entry, found := c.entries[string(id)]; found {
+		return entry.columns
+	}
+	return nil
+}
+
+// ResultMetadataId returns the result metadata id cached for the given prepared id, or nil if none is cached. This is
+// the id to send in Execute.ResultMetadataId with protocol versions that support result metadata ids.
+func (c *ResultMetadataCache) ResultMetadataId(id PreparedId) ResultMetadataId {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if entry, found := c.entries[string(id)]; found {
+		return entry.resultMetadataId
+	}
+	return nil
+}
+
+// EffectiveMetadata returns the columns describing the rows of the given result, obtained by executing the prepared
+// statement with the given id.
+//
+// If the result metadata has the METADATA_CHANGED flag set, the cache is updated with the new columns and result
+// metadata id, and the new columns are returned. Otherwise, if the result includes its own columns, they are returned
+// as is. Otherwise, the server skipped the metadata, and the cached columns are returned; an error is returned if no
+// columns are cached for the prepared id, or if their count does not match the result's column count.
+func (c *ResultMetadataCache) EffectiveMetadata(id PreparedId, result *RowsResult) ([]*ColumnMetadata, error) {
+	if result == nil || result.Metadata == nil {
+		return nil, fmt.Errorf("cannot determine result metadata of prepared statement %v: result has no metadata", id)
+	}
+	metadata := result.Metadata
+	if metadata.NewResultMetadataId != nil {
+		if len(metadata.Columns) == 0 {
+			return nil, fmt.Errorf(
+				"cannot determine result metadata of prepared statement %v: metadata changed but result has no columns", id)
+		}
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		c.entries[string(id)] = &resultMetadataEntry{
+			resultMetadataId: metadata.NewResultMetadataId,
+			columns:          metadata.Columns,
+		}
+		return metadata.Columns, nil
+	} else if len(metadata.Columns) > 0 {
+		return metadata.Columns, nil
+	}
+	columns := c.Columns(id)
+	if columns == nil {
+		return nil, fmt.Errorf("cannot determine result metadata of prepared statement %v: no cached metadata", id)
+	} else if int(metadata.ColumnCount) != len(columns) {
+		return nil, fmt.Errorf(
+			"cannot determine result metadata of prepared statement %v: expecting %d columns, got %d cached columns",
+			id,
+			metadata.ColumnCount,
+			len(columns),
+		)
+	}
+	return columns, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+)
+
+func TestResultMetadataCache(t *testing.T) {
+	id := PreparedId{0xca, 0xfe}
+	col1 := &ColumnMetadata{Keyspace: "ks1", Table: "t1", Name: "c1", Type: datatype.Int}
+	col2 := &ColumnMetadata{Keyspace: "ks1", Table: "t1", Name: "c2", Index: 1, Type: datatype.Varchar}
+	cache := NewResultMetadataCache()
+
+	// nothing cached yet
+	assert.False(t, cache.Contains(id))
+	assert.Nil(t, cache.ResultMetadataId(id))
+	_, err := cache.EffectiveMetadata(id, &RowsResult{Metadata: &RowsMetadata{ColumnCount: 1}})
+	assert.EqualError(t, err, "cannot determine result metadata of prepared statement cafe: no cached metadata")
+
+	// statements that do not return rows are not cached
+	cache.Put(&PreparedResult{PreparedQueryId: id, ResultMetadata: &RowsMetadata{}})
+	assert.False(t, cache.Contains(id))
+
+	cache.Put(&PreparedResult{
+		PreparedQueryId:  id,
+		ResultMetadataId: ResultMetadataId{1},
+		ResultMetadata:   &RowsMetadata{ColumnCount: 1, Columns: []*ColumnMetadata{col1}},
+	})
+	assert.True(t, cache.Contains(id))
+	assert.Equal(t, ResultMetadataId{1}, cache.ResultMetadataId(id))
+
+	// metadata skipped: cached columns are used
+	columns, err := cache.EffectiveMetadata(id, &RowsResult{Metadata: &RowsMetadata{ColumnCount: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, []*ColumnMetadata{col1}, columns)
+
+	// metadata skipped but column count mismatch
+	_, err = cache.EffectiveMetadata(id, &RowsResult{Metadata: &RowsMetadata{ColumnCount: 2}})
+	assert.EqualError(t, err,
+		"cannot determine result metadata of prepared statement cafe: expecting 2 columns, got 1 cached columns")
+
+	// metadata included: used as is, cache unchanged
+	columns, err = cache.EffectiveMetadata(id, &RowsResult{Metadata: &RowsMetadata{ColumnCount: 1, Columns: []*ColumnMetadata{col2}}})
+	require.NoError(t, err)
+	assert.Equal(t, []*ColumnMetadata{col2}, columns)
+	assert.Equal(t, []*ColumnMetadata{col1}, cache.Columns(id))
+
+	// metadata changed: cache updated
+	columns, err = cache.EffectiveMetadata(id, &RowsResult{Metadata: &RowsMetadata{
+		ColumnCount:         2,
+		NewResultMetadataId: ResultMetadataId{2},
+		Columns:             []*ColumnMetadata{col1, col2},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []*ColumnMetadata{col1, col2}, columns)
+	assert.Equal(t, ResultMetadataId{2}, cache.ResultMetadataId(id))
+	columns, err = cache.EffectiveMetadata(id, &RowsResult{Metadata: &RowsMetadata{ColumnCount: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, []*ColumnMetadata{col1, col2}, columns)
+
+	// metadata changed without columns
+	_, err = cache.EffectiveMetadata(id, &RowsResult{Metadata: &RowsMetadata{ColumnCount: 2, NewResultMetadataId: ResultMetadataId{3}}})
+	assert.EqualError(t, err,
+		"cannot determine result metadata of prepared statement cafe: metadata changed but result has no columns")
+
+	_, err = cache.EffectiveMetadata(id, &RowsResult{})
+	assert.EqualError(t, err, "cannot determine result metadata of prepared statement cafe: result has no metadata")
+
+	cache.Evict(id)
+	assert.False(t, cache.Contains(id))
+	assert.Nil(t, cache.Columns(id))
+}
@@ -107,8 +107,8 @@ func TestPreparedResult_DeepCopy(t *testing.T) {
 	}
 
 	assert.NotEqual(t, msg, cloned)
-	assert.Equal(t, []byte{0x12}, msg.PreparedQueryId)
-	assert.Equal(t, []byte{0x23}, msg.ResultMetadataId)
+	assert.Equal(t, PreparedId{0x12}, msg.PreparedQueryId)
+	assert.Equal(t, ResultMetadataId{0x23}, msg.ResultMetadataId)
 	assert.EqualValues(t, 0, msg.VariablesMetadata.PkIndices[0])
 	assert.Equal(t, "ks1", msg.VariablesMetadata.Columns[0].Keyspace)
 	assert.Equal(t, "tb1", msg.VariablesMetadata.Columns[0].Table)
@@ -127,8 +127,8 @@ func TestPreparedResult_DeepCopy(t *testing.T) {
 	assert.EqualValues(t, 0, msg.ResultMetadata.Columns[0].Index)
 	assert.Equal(t, datatype.Ascii, msg.ResultMetadata.Columns[0].Type)
 
-	assert.Equal(t, []byte{0x42}, cloned.PreparedQueryId)
-	assert.Equal(t, []byte{0x51}, cloned.ResultMetadataId)
+	assert.Equal(t, PreparedId{0x42}, cloned.PreparedQueryId)
+	assert.Equal(t, ResultMetadataId{0x51}, cloned.ResultMetadataId)
 	assert.EqualValues(t, 1, cloned.VariablesMetadata.PkIndices[0])
 	assert.Equal(t, "ks2", cloned.VariablesMetadata.Columns[0].Keyspace)
 	assert.Equal(t, "tb2", cloned.VariablesMetadata.Columns[0].Table)
@@ -143,7 +143,7 @@ func TestPreparedResult_DeepCopy(t *testing.T) {
 
 	assert.EqualValues(t, 1, cloned.ResultMetadata.ColumnCount)
 	assert.Equal(t, []byte{0x22}, cloned.ResultMetadata.PagingState)
-	assert.Equal(t, []byte{0x33}, cloned.ResultMetadata.NewResultMetadataId)
+	assert.Equal(t, ResultMetadataId{0x33}, cloned.ResultMetadata.NewResultMetadataId)
 	assert.EqualValues(t, 3, cloned.ResultMetadata.ContinuousPageNumber)
 	assert.True(t, cloned.ResultMetadata.LastContinuousPage)
 	assert.Equal(t, "ks2", cloned.ResultMetadata.Columns[0].Keyspace)
@@ -100,7 +100,7 @@ func TestRowsResult_DeepCopy(t *testing.T) {
 
 	assert.EqualValues(t, 1, cloned.Metadata.ColumnCount)
 	assert.Equal(t, []byte{0x22}, cloned.Metadata.PagingState)
-	assert.Equal(t, []byte{0x33}, cloned.Metadata.NewResultMetadataId)
+	assert.Equal(t, ResultMetadataId{0x33}, cloned.Metadata.NewResultMetadataId)
 	assert.EqualValues(t, 3, cloned.Metadata.ContinuousPageNumber)
 	assert.True(t, cloned.Metadata.LastContinuousPage)
 	assert.Equal(t, "ks2", cloned.Metadata.Columns[0].Keyspace)
@@ -830,3 +830,40 @@ func TestResultCodec_Decode_SchemaChange(test *testing.T) {
 		})
 	}
 }
+
+func TestResultCodec_Lenient_SchemaChange(t *testing.T) {
+	encoded := []byte{
+		0, 0, 0, 5, // result type
+		0, 7, C, R, E, A, T, E, D,
+		0, 4, V, I, E, W,
+		0, 3, k, s, _1,
+		0, 3, v, w, _1, // unknown options
+	}
+	expected := &SchemaChangeResult{
+		ChangeType:       primitive.SchemaChangeTypeCreated,
+		Target:           primitive.SchemaChangeTarget("VIEW"),
+		Keyspace:         "ks1",
+		RawTargetOptions: []byte{0, 3, v, w, _1},
+	}
+	t.Run("strict", func(t *testing.T) {
+		_, err := (&resultCodec{}).Decode(bytes.NewBuffer(encoded), primitive.ProtocolVersion4)
+		assert.EqualError(t, err, "invalid schema change target for ProtocolVersion OSS 4: VIEW")
+	})
+	t.Run("lenient", func(t *testing.T) {
+		codec := NewLenientResultCodec()
+		decoded, err := codec.Decode(bytes.NewBuffer(encoded), primitive.ProtocolVersion4)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, decoded)
+		length, err := codec.EncodedLength(decoded, primitive.ProtocolVersion4)
+		assert.NoError(t, err)
+		assert.Equal(t, len(encoded), length)
+		dest := &bytes.Buffer{}
+		assert.NoError(t, codec.Encode(decoded, dest, primitive.ProtocolVersion4))
+		assert.Equal(t, encoded, dest.Bytes())
+	})
+	t.Run("lenient without options", func(t *testing.T) {
+		decoded, err := NewLenientResultCodec().Decode(bytes.NewBuffer(encoded[:len(encoded)-5]), primitive.ProtocolVersion4)
+		assert.NoError(t, err)
+		assert.Nil(t, decoded.(*SchemaChangeResult).RawTargetOptions)
+	})
+}
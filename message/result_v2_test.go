@@ -0,0 +1,183 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// The tests in this file cover the differences between protocol version 2 and later versions in RESULT messages, which
+// are still relevant when talking to Cassandra 2.0 and 2.1 clusters.
+
+func TestResultCodec_V2_RowsFlags(t *testing.T) {
+	codec := &resultCodec{}
+	tests := []struct {
+		name string
+		// a version that supports the flag, used to produce a body to decode
+		supported primitive.ProtocolVersion
+		metadata  *RowsMetadata
+		flag      primitive.RowsFlag
+	}{
+		{
+			"new result metadata id",
+			primitive.ProtocolVersion5,
+			&RowsMetadata{ColumnCount: 1, NewResultMetadataId: []byte{1, 2, 3, 4}},
+			primitive.RowsFlagMetadataChanged,
+		},
+		{
+			"continuous paging",
+			primitive.ProtocolVersionDse2,
+			&RowsMetadata{ColumnCount: 1, ContinuousPageNumber: 1, LastContinuousPage: true},
+			primitive.RowsFlagDseContinuousPaging,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := &RowsResult{Metadata: tt.metadata}
+			expected := fmt.Sprintf("invalid RESULT Rows metadata flag for %v: %v", primitive.ProtocolVersion2, tt.flag)
+			err := codec.Encode(rows, &bytes.Buffer{}, primitive.ProtocolVersion2)
+			assert.EqualError(t, err, "cannot write RESULT Rows metadata: "+expected)
+			_, err = codec.EncodedLength(rows, primitive.ProtocolVersion2)
+			assert.EqualError(t, err, "cannot compute length of RESULT Rows metadata: "+expected)
+			// a v2 server never sets these flags, but if it did, the fields they announce would be misread
+			encoded := &bytes.Buffer{}
+			require.NoError(t, codec.Encode(rows, encoded, tt.supported))
+			_, err = codec.Decode(encoded, primitive.ProtocolVersion2)
+			assert.EqualError(t, err, "cannot read RESULT Rows metadata: "+expected)
+		})
+	}
+}
+
+func TestResultCodec_V2_RowsPaging(t *testing.T) {
+	codec := &resultCodec{}
+	// paging was introduced in protocol version 2: HAS_MORE_PAGES and NO_METADATA are both valid
+	rows := &RowsResult{
+		Metadata: &RowsMetadata{ColumnCount: 1, PagingState: []byte{0xca, 0xfe}},
+		Data:     RowSet{{{0, 0, 0, 1}}},
+	}
+	expected := []byte{
+		0, 0, 0, 2, // result type
+		0, 0, 0, 6, // flags (HAS_MORE_PAGES | NO_METADATA)
+		0, 0, 0, 1, // column count
+		0, 0, 0, 2, 0xca, 0xfe, // paging state
+		0, 0, 0, 1, // rows count
+		0, 0, 0, 4, 0, 0, 0, 1, // row1, col1
+	}
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.Encode(rows, encoded, primitive.ProtocolVersion2))
+	assert.Equal(t, expected, encoded.Bytes())
+	length, err := codec.EncodedLength(rows, primitive.ProtocolVersion2)
+	require.NoError(t, err)
+	assert.Equal(t, len(expected), length)
+	decoded, err := codec.Decode(bytes.NewBuffer(expected), primitive.ProtocolVersion2)
+	require.NoError(t, err)
+	assert.Equal(t, rows, decoded)
+}
+
+func TestResultCodec_V2_ColumnTypes(t *testing.T) {
+	codec := &resultCodec{}
+	// user-defined types and tuples were introduced in protocol version 3
+	tupleType := datatype.NewTuple(datatype.Int)
+	rows := &RowsResult{
+		Metadata: &RowsMetadata{
+			ColumnCount: 1,
+			Columns:     []*ColumnMetadata{{Keyspace: "ks1", Table: "table1", Name: "col1", Type: tupleType}},
+		},
+	}
+	err := codec.Encode(rows, &bytes.Buffer{}, primitive.ProtocolVersion2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid data type code for ProtocolVersion OSS 2: DataTypeCode Tuple")
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.Encode(rows, encoded, primitive.ProtocolVersion3))
+	_, err = codec.Decode(encoded, primitive.ProtocolVersion2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid data type code for ProtocolVersion OSS 2: DataTypeCode Tuple")
+}
+
+func TestResultCodec_V2_Prepared(test *testing.T) {
+	codec := &resultCodec{}
+	// partition key indices were introduced in protocol version 4: they are silently dropped in protocol version 2
+	prepared := &PreparedResult{
+		PreparedQueryId: []byte{1, 2, 3, 4},
+		VariablesMetadata: &VariablesMetadata{
+			PkIndices: []uint16{0},
+			Columns:   []*ColumnMetadata{{Keyspace: "ks1", Table: "table1", Name: "col1", Type: datatype.Int}},
+		},
+	}
+	expected := []byte{
+		0, 0, 0, 4, // result type
+		0, 4, 1, 2, 3, 4, // prepared id
+		// variables metadata
+		0, 0, 0, 1, // flags (GLOBAL_TABLES_SPEC)
+		0, 0, 0, 1, // column count
+		0, 3, k, s, _1, // global ks
+		0, 6, t, a, b, l, e, _1, // global table
+		0, 4, c, o, l, _1, // col1 name
+		0, 9, // col1 type
+		// result metadata
+		0, 0, 0, 4, // flags (NO_METADATA)
+		0, 0, 0, 0, // column count
+	}
+	encoded := &bytes.Buffer{}
+	require.NoError(test, codec.Encode(prepared, encoded, primitive.ProtocolVersion2))
+	assert.Equal(test, expected, encoded.Bytes())
+	length, err := codec.EncodedLength(prepared, primitive.ProtocolVersion2)
+	require.NoError(test, err)
+	assert.Equal(test, len(expected), length)
+	decoded, err := codec.Decode(bytes.NewBuffer(expected), primitive.ProtocolVersion2)
+	require.NoError(test, err)
+	assert.Nil(test, decoded.(*PreparedResult).VariablesMetadata.PkIndices)
+	assert.Equal(test, prepared.VariablesMetadata.Columns, decoded.(*PreparedResult).VariablesMetadata.Columns)
+	assert.Equal(test, &RowsMetadata{}, decoded.(*PreparedResult).ResultMetadata)
+}
+
+func TestResultCodec_V2_SchemaChange(t *testing.T) {
+	codec := &resultCodec{}
+	// protocol version 2 has no target: keyspace changes are recognized by their empty table name
+	keyspaceChange := &SchemaChangeResult{
+		ChangeType: primitive.SchemaChangeTypeDropped,
+		Target:     primitive.SchemaChangeTargetKeyspace,
+		Keyspace:   "ks1",
+	}
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.Encode(keyspaceChange, encoded, primitive.ProtocolVersion2))
+	decoded, err := codec.Decode(encoded, primitive.ProtocolVersion2)
+	require.NoError(t, err)
+	assert.Equal(t, keyspaceChange, decoded)
+	// a keyspace change cannot carry a table name, since it would be decoded as a table change
+	err = codec.Encode(&SchemaChangeResult{
+		ChangeType: primitive.SchemaChangeTypeDropped,
+		Target:     primitive.SchemaChangeTargetKeyspace,
+		Keyspace:   "ks1",
+		Object:     "table1",
+	}, &bytes.Buffer{}, primitive.ProtocolVersion2)
+	assert.EqualError(t, err, "RESULT SchemaChange: table must be empty for keyspace targets")
+	// lenient codecs do not accept unknown targets in protocol version 2, since they cannot be encoded
+	_, err = NewLenientResultCodec().EncodedLength(&SchemaChangeResult{
+		ChangeType: primitive.SchemaChangeTypeCreated,
+		Target:     "VIEW",
+		Keyspace:   "ks1",
+		Object:     "view1",
+	}, primitive.ProtocolVersion2)
+	assert.EqualError(t, err, "invalid schema change target for ProtocolVersion OSS 2: VIEW")
+}
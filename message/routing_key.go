@@ -0,0 +1,92 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Routing keys are the serialized partition keys that token-aware load balancing policies hash to find the replicas
+// of a request. A prepared statement's partition key columns are given by the PkIndices of its variables metadata;
+// they are only available from protocol version 4 onwards.
+
+// PartitionKeyColumns returns the bound variables of this prepared statement that make up the partition key of its
+// table, in partition key order. It returns an error if the partition key indices are unknown, e.g. because the
+// statement was prepared with protocol version 3 or lower, or because it does not restrict the whole partition key.
+func (m *PreparedResult) PartitionKeyColumns() ([]*ColumnMetadata, error) {
+	if m.VariablesMetadata == nil || len(m.VariablesMetadata.PkIndices) == 0 {
+		return nil, errors.New("prepared statement has no partition key indices")
+	}
+	columns := make([]*ColumnMetadata, len(m.VariablesMetadata.PkIndices))
+	for i, index := range m.VariablesMetadata.PkIndices {
+		if int(index) >= len(m.VariablesMetadata.Columns) {
+			return nil, fmt.Errorf(
+				"partition key index %d out of range: prepared statement has %d bound variables",
+				index,
+				len(m.VariablesMetadata.Columns),
+			)
+		}
+		columns[i] = m.VariablesMetadata.Columns[index]
+	}
+	return columns, nil
+}
+
+// RoutingKey returns the routing key of this prepared statement for the given positional values, as found in the
+// QueryOptions of an Execute request; to compute it from Go values, see datacodec.EncodeRoutingKey. See
+// ComposeRoutingKey for the routing key format. It returns an error if the partition key indices are unknown, or if
+// any partition key value is missing, null or unset.
+func (m *PreparedResult) RoutingKey(positionalValues []*primitive.Value) ([]byte, error) {
+	if m.VariablesMetadata == nil || len(m.VariablesMetadata.PkIndices) == 0 {
+		return nil, errors.New("cannot compute routing key: prepared statement has no partition key indices")
+	}
+	components := make([][]byte, len(m.VariablesMetadata.PkIndices))
+	for i, index := range m.VariablesMetadata.PkIndices {
+		if int(index) >= len(positionalValues) {
+			return nil, fmt.Errorf("cannot compute routing key: missing value for partition key component %d", i)
+		}
+		value := positionalValues[index]
+		if value == nil || value.Type != primitive.ValueTypeRegular || value.Contents == nil {
+			return nil, fmt.Errorf("cannot compute routing key: partition key component %d is null or unset", i)
+		}
+		components[i] = value.Contents
+	}
+	return ComposeRoutingKey(components...)
+}
+
+// ComposeRoutingKey returns the routing key made of the given serialized partition key components. A single
+// component is used as is; composite partition keys are serialized as Cassandra's CompositeType does: for each
+// component, its length as a 2-byte big-endian integer, followed by its bytes, followed by a zero byte.
+func ComposeRoutingKey(components ...[]byte) ([]byte, error) {
+	if len(components) == 0 {
+		return nil, errors.New("cannot compose routing key: no components")
+	} else if len(components) == 1 {
+		return components[0], nil
+	}
+	buf := &bytes.Buffer{}
+	for i, component := range components {
+		if len(component) > math.MaxUint16 {
+			return nil, fmt.Errorf("cannot compose routing key: component %d too long: %d bytes", i, len(component))
+		}
+		_ = primitive.WriteShort(uint16(len(component)), buf)
+		buf.Write(component)
+		buf.WriteByte(0)
+	}
+	return buf.Bytes(), nil
+}
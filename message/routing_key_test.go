@@ -0,0 +1,83 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestPreparedResult_RoutingKey(test *testing.T) {
+	// INSERT INTO ks1.table1 (c, pk2, pk1) VALUES (?, ?, ?) with PRIMARY KEY ((pk1, pk2), c)
+	composite := &PreparedResult{
+		VariablesMetadata: &VariablesMetadata{
+			PkIndices: []uint16{2, 1},
+			Columns: []*ColumnMetadata{
+				{Keyspace: "ks1", Table: "table1", Name: "c", Index: 0, Type: datatype.Int},
+				{Keyspace: "ks1", Table: "table1", Name: "pk2", Index: 1, Type: datatype.Varchar},
+				{Keyspace: "ks1", Table: "table1", Name: "pk1", Index: 2, Type: datatype.Int},
+			},
+		},
+	}
+	// SELECT * FROM ks1.table2 WHERE pk = ?
+	single := &PreparedResult{
+		VariablesMetadata: &VariablesMetadata{
+			PkIndices: []uint16{0},
+			Columns:   []*ColumnMetadata{{Keyspace: "ks1", Table: "table2", Name: "pk", Index: 0, Type: datatype.Bigint}},
+		},
+	}
+	compositeKey := []byte{0, 4, 0, 0, 0, 1, 0, 0, 3, 'a', 'b', 'c', 0}
+	singleKey := []byte{0, 0, 0, 0, 0, 0, 0, 42}
+
+	test.Run("partition key columns", func(t *testing.T) {
+		columns, err := composite.PartitionKeyColumns()
+		require.NoError(t, err)
+		assert.Equal(t, []*ColumnMetadata{composite.VariablesMetadata.Columns[2], composite.VariablesMetadata.Columns[1]}, columns)
+		_, err = (&PreparedResult{VariablesMetadata: &VariablesMetadata{}}).PartitionKeyColumns()
+		assert.EqualError(t, err, "prepared statement has no partition key indices")
+		_, err = (&PreparedResult{VariablesMetadata: &VariablesMetadata{PkIndices: []uint16{1}}}).PartitionKeyColumns()
+		assert.EqualError(t, err, "partition key index 1 out of range: prepared statement has 0 bound variables")
+	})
+
+	test.Run("from positional values", func(t *testing.T) {
+		values := []*primitive.Value{
+			primitive.NewValue([]byte{0, 0, 0, 2}),
+			primitive.NewValue([]byte("abc")),
+			primitive.NewValue([]byte{0, 0, 0, 1}),
+		}
+		key, err := composite.RoutingKey(values)
+		require.NoError(t, err)
+		assert.Equal(t, compositeKey, key)
+		key, err = single.RoutingKey([]*primitive.Value{primitive.NewValue(singleKey)})
+		require.NoError(t, err)
+		assert.Equal(t, singleKey, key)
+		_, err = composite.RoutingKey(values[:2])
+		assert.EqualError(t, err, "cannot compute routing key: missing value for partition key component 0")
+		_, err = composite.RoutingKey([]*primitive.Value{values[0], primitive.NewUnsetValue(), values[2]})
+		assert.EqualError(t, err, "cannot compute routing key: partition key component 1 is null or unset")
+	})
+
+	test.Run("compose", func(t *testing.T) {
+		_, err := ComposeRoutingKey()
+		assert.EqualError(t, err, "cannot compose routing key: no components")
+		_, err = ComposeRoutingKey([]byte{1}, make([]byte, 65536))
+		assert.EqualError(t, err, "cannot compose routing key: component 1 too long: 65536 bytes")
+	})
+}
@@ -18,11 +18,22 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
 
 const (
+	// SupportedCqlVersions is a Supported.Options multimap key holding the list of CQL versions supported by the
+	// server, e.g. 3.4.5.
+	SupportedCqlVersions = "CQL_VERSION"
+
+	// SupportedCompression is a Supported.Options multimap key holding the list of compression algorithms supported by
+	// the server, e.g. lz4 and snappy.
+	SupportedCompression = "COMPRESSION"
+
 	// SupportedProtocolVersions is a Supported.Options multimap key returned by Cassandra from protocol v5 onwards.
 	// It holds the list of native protocol versions that are supported, encoded as the version number followed by a
 	// slash and the version description. For example: 3/v3, 4/v4, 5/v5-beta. If a version is in beta, it will have the
@@ -37,6 +48,214 @@ type Supported struct {
 	// This multimap gives for each of the supported Startup options, the list of supported values.
 	// See Startup.Options for details about supported option keys.
 	Options map[string][]string
+
+	// OptionKeys holds the keys of Options in the order in which they were decoded, including keys unknown to this
+	// library, so that a decoded message can be re-encoded without loss, e.g. by a proxy forwarding server
+	// capabilities. When encoding, keys listed here are written first, in this order; keys of Options not listed here
+	// are written afterwards, in lexical order. It can be left empty when creating a new message. Since options could
+	// not be forwarded without loss otherwise, decoding fails if an option key appears more than once.
+	OptionKeys []string
+}
+
+// CqlVersions returns the values of the SupportedCqlVersions option, or nil if the option is absent.
+func (m *Supported) CqlVersions() []string {
+	return m.Options[SupportedCqlVersions]
+}
+
+// Compression returns the values of the SupportedCompression option, normalized like in
+// SupportedOptions.CompressionAlgorithms, or nil if the option is absent.
+func (m *Supported) Compression() []primitive.Compression {
+	return parseCompressionAlgorithms(m.Options[SupportedCompression])
+}
+
+// ProtocolVersions returns the parsed values of the SupportedProtocolVersions option, or nil if the option is absent.
+// An error is returned if a protocol version cannot be parsed.
+func (m *Supported) ProtocolVersions() ([]SupportedProtocolVersion, error) {
+	return parseProtocolVersions(m.Options[SupportedProtocolVersions])
+}
+
+// ParseOptions returns a typed view over Options. Compression algorithms are normalized to upper case, so that they
+// can be compared to the primitive.Compression constants; algorithms unknown to this library are preserved. Options not
+// covered by the typed fields, e.g. vendor-specific ones, are preserved in SupportedOptions.Other, and the order of
+// all options in SupportedOptions.OptionKeys. An error is returned if a protocol version cannot be parsed.
+func (m *Supported) ParseOptions() (*SupportedOptions, error) {
+	options := &SupportedOptions{OptionKeys: m.OptionKeys}
+	for key, values := range m.Options {
+		switch key {
+		case SupportedCqlVersions:
+			options.CqlVersions = values
+		case SupportedCompression:
+			options.CompressionAlgorithms = parseCompressionAlgorithms(values)
+		case SupportedProtocolVersions:
+			versions, err := parseProtocolVersions(values)
+			if err != nil {
+				return nil, err
+			}
+			options.ProtocolVersions = versions
+		default:
+			if options.Other == nil {
+				options.Other = map[string][]string{}
+			}
+			options.Other[key] = values
+		}
+	}
+	return options, nil
+}
+
+func parseCompressionAlgorithms(values []string) []primitive.Compression {
+	var algorithms []primitive.Compression
+	for _, value := range values {
+		algorithms = append(algorithms, primitive.Compression(strings.ToUpper(value)))
+	}
+	return algorithms
+}
+
+func parseProtocolVersions(values []string) ([]SupportedProtocolVersion, error) {
+	var versions []SupportedProtocolVersion
+	for _, value := range values {
+		version, err := ParseSupportedProtocolVersion(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %v option: %w", SupportedProtocolVersions, err)
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// NewSupported creates a Supported message advertising the given options; empty options are omitted. The options are
+// encoded in the order given by SupportedOptions.OptionKeys, if any.
+func NewSupported(options *SupportedOptions) *Supported {
+	msg := &Supported{Options: map[string][]string{}, OptionKeys: options.OptionKeys}
+	for key, values := range options.Other {
+		msg.Options[key] = values
+	}
+	if len(options.CqlVersions) > 0 {
+		msg.Options[SupportedCqlVersions] = options.CqlVersions
+	}
+	if len(options.CompressionAlgorithms) > 0 {
+		values := make([]string, len(options.CompressionAlgorithms))
+		for i, compression := range options.CompressionAlgorithms {
+			values[i] = string(compression)
+		}
+		msg.Options[SupportedCompression] = values
+	}
+	if len(options.ProtocolVersions) > 0 {
+		values := make([]string, len(options.ProtocolVersions))
+		for i, version := range options.ProtocolVersions {
+			values[i] = version.String()
+		}
+		msg.Options[SupportedProtocolVersions] = values
+	}
+	return msg
+}
+
+// SupportedOptions is a typed view over the options of a Supported message, see Supported.ParseOptions and
+// NewSupported.
+type SupportedOptions struct {
+	// CqlVersions holds the values of the SupportedCqlVersions option, e.g. 3.4.5.
+	CqlVersions []string
+
+	// CompressionAlgorithms holds the values of the SupportedCompression option.
+	CompressionAlgorithms []primitive.Compression
+
+	// ProtocolVersions holds the values of the SupportedProtocolVersions option.
+	ProtocolVersions []SupportedProtocolVersion
+
+	// Other holds the options that are not covered by the fields above, keyed by option name.
+	Other map[string][]string
+
+	// OptionKeys holds the order of the options, see Supported.OptionKeys. It can be left empty.
+	OptionKeys []string
+}
+
+// SupportsCompression returns true if the given compression algorithm is advertised. CompressionNone is always
+// supported.
+func (o *SupportedOptions) SupportsCompression(compression primitive.Compression) bool {
+	if compression == primitive.CompressionNone {
+		return true
+	}
+	for _, supported := range o.CompressionAlgorithms {
+		if strings.EqualFold(string(supported), string(compression)) {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateCompression returns the first of the given locally available compression algorithms, in order of
+// preference, that is advertised, see SupportsCompression, and that the given protocol version supports; e.g. Snappy
+// is never returned for protocol v5 and higher. It returns primitive.CompressionNone if no algorithm qualifies, in
+// which case compression should not be used.
+func (o *SupportedOptions) NegotiateCompression(
+	version primitive.ProtocolVersion,
+	available ...primitive.Compression,
+) primitive.Compression {
+	for _, compression := range available {
+		if compression != primitive.CompressionNone &&
+			version.SupportsCompression(compression) &&
+			o.SupportsCompression(compression) {
+			return compression
+		}
+	}
+	return primitive.CompressionNone
+}
+
+// SupportsProtocolVersion returns true if the given protocol version is advertised, be it in beta or not.
+func (o *SupportedOptions) SupportsProtocolVersion(version primitive.ProtocolVersion) bool {
+	for _, supported := range o.ProtocolVersions {
+		if supported.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// HighestProtocolVersion returns the highest advertised protocol version; beta versions are only considered if
+// allowBeta is true. It returns false if no such version is advertised.
+func (o *SupportedOptions) HighestProtocolVersion(allowBeta bool) (primitive.ProtocolVersion, bool) {
+	var versions []primitive.ProtocolVersion
+	for _, supported := range o.ProtocolVersions {
+		if allowBeta || !supported.IsBeta() {
+			versions = append(versions, supported.Version)
+		}
+	}
+	if len(versions) == 0 {
+		return 0, false
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+	return versions[0], true
+}
+
+// SupportedProtocolVersion is a value of the SupportedProtocolVersions option, e.g. 5/v5-beta.
+type SupportedProtocolVersion struct {
+	Version primitive.ProtocolVersion
+
+	// Description is the version description sent by the server, e.g. v5-beta.
+	Description string
+}
+
+// ParseSupportedProtocolVersion parses a value of the SupportedProtocolVersions option, encoded as the version number
+// followed by a slash and the version description, e.g. 5/v5-beta.
+func ParseSupportedProtocolVersion(s string) (SupportedProtocolVersion, error) {
+	slash := strings.IndexByte(s, '/')
+	if slash < 0 {
+		return SupportedProtocolVersion{}, fmt.Errorf("invalid protocol version %q: expecting number/description", s)
+	}
+	version, err := strconv.ParseUint(s[:slash], 10, 8)
+	if err != nil {
+		return SupportedProtocolVersion{}, fmt.Errorf("invalid protocol version %q: %w", s, err)
+	}
+	return SupportedProtocolVersion{Version: primitive.ProtocolVersion(version), Description: s[slash+1:]}, nil
+}
+
+// IsBeta returns true if the description contains the word "beta".
+func (v SupportedProtocolVersion) IsBeta() bool {
+	return strings.Contains(strings.ToLower(v.Description), "beta")
+}
+
+// String returns the encoded form of this version, e.g. 5/v5-beta.
+func (v SupportedProtocolVersion) String() string {
+	return fmt.Sprintf("%d/%s", uint8(v.Version), v.Description)
 }
 
 func (m *Supported) IsResponse() bool {
@@ -58,7 +277,7 @@ func (c *supportedCodec) Encode(msg Message, dest io.Writer, _ primitive.Protoco
 	if !ok {
 		return errors.New(fmt.Sprintf("expected *message.Supported, got %T", msg))
 	}
-	if err := primitive.WriteStringMultiMap(supported.Options, dest); err != nil {
+	if err := primitive.WriteStringMultiMapOrdered(supported.Options, supported.OptionKeys, dest); err != nil {
 		return err
 	}
 	return nil
@@ -73,10 +292,10 @@ func (c *supportedCodec) EncodedLength(msg Message, _ primitive.ProtocolVersion)
 }
 
 func (c *supportedCodec) Decode(source io.Reader, _ primitive.ProtocolVersion) (Message, error) {
-	if options, err := primitive.ReadStringMultiMap(source); err != nil {
+	if options, keys, err := primitive.ReadStringMultiMapOrdered(source); err != nil {
 		return nil, err
 	} else {
-		return &Supported{Options: options}, nil
+		return &Supported{Options: options, OptionKeys: keys}, nil
 	}
 }
 
@@ -30,6 +30,7 @@ func TestSupported_DeepCopy(t *testing.T) {
 			"opt1": {"val1"},
 			"opt2": {"val2"},
 		},
+		OptionKeys: []string{"opt2", "opt1"},
 	}
 
 	cloned := msg.DeepCopy()
@@ -37,6 +38,7 @@ func TestSupported_DeepCopy(t *testing.T) {
 
 	cloned.Options["opt1"] = []string{"val5"}
 	cloned.Options["opt3"] = []string{"val6"}
+	cloned.OptionKeys[0] = "opt3"
 
 	assert.NotEqual(t, msg, cloned)
 
@@ -46,6 +48,9 @@ func TestSupported_DeepCopy(t *testing.T) {
 	assert.Equal(t, "val5", cloned.Options["opt1"][0])
 	assert.Equal(t, "val2", cloned.Options["opt2"][0])
 	assert.Equal(t, "val6", cloned.Options["opt3"][0])
+
+	assert.Equal(t, []string{"opt2", "opt1"}, msg.OptionKeys)
+	assert.Equal(t, []string{"opt3", "opt1"}, cloned.OptionKeys)
 }
 
 func TestSupportedCodec_Encode(test *testing.T) {
@@ -89,7 +94,7 @@ func TestSupportedCodec_Encode(test *testing.T) {
 				{
 					"supported with 2 options",
 					&Supported{Options: map[string][]string{"option1": {"value1a", "value1b"}, "option2": {"value2a", "value2b"}}},
-					// we have two possible encodings because maps do not have deterministic iteration order
+					// without OptionKeys, keys are encoded in lexical order
 					[][]byte{
 						{
 							0, 2, // map length
@@ -110,25 +115,6 @@ func TestSupportedCodec_Encode(test *testing.T) {
 							// value1b
 							0, 7, v, a, l, u, e, _2, b,
 						},
-						{
-							0, 2, // map length
-							// key "option2"
-							0, 7, o, p, t, i, o, n, _2,
-							// list length
-							0, 2,
-							// value1a
-							0, 7, v, a, l, u, e, _2, a,
-							// value1b
-							0, 7, v, a, l, u, e, _2, b,
-							// key "option1"
-							0, 7, o, p, t, i, o, n, _1,
-							// list length
-							0, 2,
-							// value1a
-							0, 7, v, a, l, u, e, _1, a,
-							// value1b
-							0, 7, v, a, l, u, e, _1, b,
-						},
 					},
 					nil,
 				},
@@ -238,12 +224,11 @@ func TestSupportedCodec_Decode(test *testing.T) {
 						// value1b
 						0, 7, v, a, l, u, e, _1, b,
 					},
-					&Supported{Options: map[string][]string{"option1": {"value1a", "value1b"}}},
+					&Supported{Options: map[string][]string{"option1": {"value1a", "value1b"}}, OptionKeys: []string{"option1"}},
 					nil,
 				},
 				{
 					"supported with 2 options",
-					// we have two possible encodings because maps do not have deterministic iteration order
 					[]byte{
 						0, 2, // map length
 						// key "option1"
@@ -263,7 +248,10 @@ func TestSupportedCodec_Decode(test *testing.T) {
 						// value1b
 						0, 7, v, a, l, u, e, _2, b,
 					},
-					&Supported{Options: map[string][]string{"option1": {"value1a", "value1b"}, "option2": {"value2a", "value2b"}}},
+					&Supported{
+						Options:    map[string][]string{"option1": {"value1a", "value1b"}, "option2": {"value2a", "value2b"}},
+						OptionKeys: []string{"option1", "option2"},
+					},
 					nil,
 				},
 			}
@@ -278,3 +266,159 @@ func TestSupportedCodec_Decode(test *testing.T) {
 		})
 	}
 }
+
+func TestSupportedCodec_DecodeDuplicateKeys(test *testing.T) {
+	codec := &supportedCodec{}
+	source := []byte{
+		0, 2, // map length
+		// key "option1"
+		0, 7, o, p, t, i, o, n, _1,
+		// list length
+		0, 1,
+		// value1a
+		0, 7, v, a, l, u, e, _1, a,
+		// key "option1" again
+		0, 7, o, p, t, i, o, n, _1,
+		// list length
+		0, 1,
+		// value1b
+		0, 7, v, a, l, u, e, _1, b,
+	}
+	_, err := codec.Decode(bytes.NewBuffer(source), primitive.ProtocolVersion4)
+	assert.EqualError(test, err, "cannot read [string multimap] entry 1: duplicate key 'option1'")
+}
+
+func TestSupportedCodec_RoundTrip(t *testing.T) {
+	codec := &supportedCodec{}
+	// unknown keys and values must be forwarded as is, in their original order
+	input := []byte{
+		0, 3, // map length
+		0, 4, z, z, z, z, // unknown key
+		0, 1,
+		0, 1, a,
+		0, 11, C, O, M, P, R, E, S, S, I, O, N,
+		0, 2,
+		0, 3, l, z, _4,
+		0, 6, s, n, a, p, p, y,
+		0, 3, a, a, a, // unknown key
+		0, 0,
+	}
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			decoded, err := codec.Decode(bytes.NewReader(input), version)
+			assert.NoError(t, err)
+			supported := decoded.(*Supported)
+			assert.Equal(t, []string{"zzzz", "COMPRESSION", "aaa"}, supported.OptionKeys)
+			assert.Equal(t, []primitive.Compression{primitive.CompressionLz4, primitive.CompressionSnappy}, supported.Compression())
+			assert.Nil(t, supported.CqlVersions())
+			versions, err := supported.ProtocolVersions()
+			assert.NoError(t, err)
+			assert.Nil(t, versions)
+			// the typed view preserves the options order too
+			options, err := supported.ParseOptions()
+			assert.NoError(t, err)
+			dest := &bytes.Buffer{}
+			assert.NoError(t, codec.Encode(NewSupported(options), dest, version))
+			reencoded, err := codec.Decode(dest, version)
+			assert.NoError(t, err)
+			assert.Equal(t, supported.OptionKeys, reencoded.(*Supported).OptionKeys)
+			dest.Reset()
+			assert.NoError(t, codec.Encode(supported.DeepCopy(), dest, version))
+			assert.Equal(t, input, dest.Bytes())
+		})
+	}
+}
+
+func TestSupported_ParseOptions(t *testing.T) {
+	msg := &Supported{Options: map[string][]string{
+		SupportedCqlVersions:      {"3.4.5"},
+		SupportedCompression:      {"lz4", "snappy", "zstd"},
+		SupportedProtocolVersions: {"3/v3", "4/v4", "5/v5", "6/v6-beta"},
+		"CUSTOM":                  {"foo"},
+	}}
+	options, err := msg.ParseOptions()
+	assert.NoError(t, err)
+	assert.Equal(t, &SupportedOptions{
+		CqlVersions:           []string{"3.4.5"},
+		CompressionAlgorithms: []primitive.Compression{primitive.CompressionLz4, primitive.CompressionSnappy, "ZSTD"},
+		ProtocolVersions: []SupportedProtocolVersion{
+			{primitive.ProtocolVersion3, "v3"},
+			{primitive.ProtocolVersion4, "v4"},
+			{primitive.ProtocolVersion5, "v5"},
+			{primitive.ProtocolVersion6, "v6-beta"},
+		},
+		Other: map[string][]string{"CUSTOM": {"foo"}},
+	}, options)
+	assert.True(t, options.SupportsCompression(primitive.CompressionNone))
+	assert.True(t, options.SupportsCompression(primitive.CompressionSnappy))
+	assert.True(t, options.SupportsProtocolVersion(primitive.ProtocolVersion6))
+	assert.False(t, options.SupportsProtocolVersion(primitive.ProtocolVersionDse1))
+	highest, found := options.HighestProtocolVersion(false)
+	assert.True(t, found)
+	assert.Equal(t, primitive.ProtocolVersion5, highest)
+	highest, found = options.HighestProtocolVersion(true)
+	assert.True(t, found)
+	assert.Equal(t, primitive.ProtocolVersion6, highest)
+
+	options, err = (&Supported{}).ParseOptions()
+	assert.NoError(t, err)
+	assert.Equal(t, &SupportedOptions{}, options)
+	assert.False(t, options.SupportsCompression(primitive.CompressionLz4))
+	_, found = options.HighestProtocolVersion(true)
+	assert.False(t, found)
+
+	_, err = (&Supported{Options: map[string][]string{SupportedProtocolVersions: {"v4"}}}).ParseOptions()
+	assert.EqualError(t, err, `cannot parse PROTOCOL_VERSIONS option: invalid protocol version "v4": expecting number/description`)
+	_, err = (&Supported{Options: map[string][]string{SupportedProtocolVersions: {"256/v256"}}}).ParseOptions()
+	assert.Error(t, err)
+}
+
+func TestNewSupported(t *testing.T) {
+	options := &SupportedOptions{
+		CqlVersions:           []string{"3.4.5"},
+		CompressionAlgorithms: []primitive.Compression{primitive.CompressionLz4},
+		ProtocolVersions:      []SupportedProtocolVersion{{primitive.ProtocolVersionDse2, "dse-v2"}},
+		Other:                 map[string][]string{"CUSTOM": {"foo"}},
+		OptionKeys:            []string{"CUSTOM", SupportedCompression},
+	}
+	msg := NewSupported(options)
+	assert.Equal(t, &Supported{
+		Options: map[string][]string{
+			SupportedCqlVersions:      {"3.4.5"},
+			SupportedCompression:      {"LZ4"},
+			SupportedProtocolVersions: {"66/dse-v2"},
+			"CUSTOM":                  {"foo"},
+		},
+		OptionKeys: []string{"CUSTOM", SupportedCompression},
+	}, msg)
+	parsed, err := msg.ParseOptions()
+	assert.NoError(t, err)
+	assert.Equal(t, options, parsed)
+	assert.Equal(t, &Supported{Options: map[string][]string{}}, NewSupported(&SupportedOptions{}))
+}
+
+func TestSupportedOptions_NegotiateCompression(t *testing.T) {
+	lz4, snappy, none := primitive.CompressionLz4, primitive.CompressionSnappy, primitive.CompressionNone
+	// advertised algorithms are compared case-insensitively
+	msg := &SupportedOptions{CompressionAlgorithms: []primitive.Compression{"snappy", "lz4"}}
+	snappyOnly := &SupportedOptions{CompressionAlgorithms: []primitive.Compression{snappy}}
+	tests := []struct {
+		name      string
+		msg       *SupportedOptions
+		version   primitive.ProtocolVersion
+		available []primitive.Compression
+		expected  primitive.Compression
+	}{
+		{"local preference", msg, primitive.ProtocolVersion4, []primitive.Compression{lz4, snappy}, lz4},
+		{"local preference snappy", msg, primitive.ProtocolVersion4, []primitive.Compression{snappy, lz4}, snappy},
+		{"snappy unsupported by version", msg, primitive.ProtocolVersion5, []primitive.Compression{snappy, lz4}, lz4},
+		{"not advertised", snappyOnly, primitive.ProtocolVersion5, []primitive.Compression{snappy, lz4}, none},
+		{"nothing advertised", &SupportedOptions{}, primitive.ProtocolVersion4, []primitive.Compression{lz4}, none},
+		{"nothing available", msg, primitive.ProtocolVersion4, nil, none},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.msg.NegotiateCompression(tt.version, tt.available...))
+		})
+	}
+}
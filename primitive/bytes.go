@@ -23,19 +23,9 @@ import (
 // [bytes]
 
 func ReadBytes(source io.Reader) ([]byte, error) {
-	if length, err := ReadInt(source); err != nil {
-		return nil, fmt.Errorf("cannot read [bytes] length: %w", err)
-	} else if length < 0 {
-		return nil, nil
-	} else if length == 0 {
-		return []byte{}, nil
-	} else {
-		decoded := make([]byte, length)
-		if _, err := io.ReadFull(source, decoded); err != nil {
-			return nil, fmt.Errorf("cannot read [bytes] content: %w", err)
-		}
-		return decoded, nil
-	}
+	buf := acquireReadBuffer()
+	defer releaseReadBuffer(buf)
+	return buf.readBytes(source)
 }
 
 func WriteBytes(b []byte, dest io.Writer) error {
@@ -22,14 +22,18 @@ import (
 // [bytes map]
 
 func ReadBytesMap(source io.Reader) (map[string][]byte, error) {
-	if length, err := ReadShort(source); err != nil {
+	buf := acquireReadBuffer()
+	defer releaseReadBuffer(buf)
+	if length, err := buf.readShort(source); err != nil {
 		return nil, fmt.Errorf("cannot read [bytes map] length: %w", err)
+	} else if err := CheckCollectionLength(source, "[bytes map]", int(length)); err != nil {
+		return nil, err
 	} else {
 		decoded := make(map[string][]byte, length)
 		for i := uint16(0); i < length; i++ {
-			if key, err := ReadString(source); err != nil {
+			if key, err := buf.readString(source); err != nil {
 				return nil, fmt.Errorf("cannot read [bytes map] entry %d key: %w", i, err)
-			} else if value, err := ReadBytes(source); err != nil {
+			} else if value, err := buf.readBytes(source); err != nil {
 				return nil, fmt.Errorf("cannot read [bytes map] entry %d value: %w", i, err)
 			} else {
 				decoded[key] = value
@@ -167,3 +167,41 @@ func TestWriteBytesMap(t *testing.T) {
 		})
 	}
 }
+
+// benchmarkBytesMap resembles a typical custom payload.
+var benchmarkBytesMap = map[string][]byte{
+	"trace-id":  bytes.Repeat([]byte{0xca}, 16),
+	"tenant":    []byte("acme"),
+	"proxy-hop": {1},
+	"empty":     {},
+}
+
+func BenchmarkReadBytesMap(b *testing.B) {
+	buf := &bytes.Buffer{}
+	_ = WriteBytesMap(benchmarkBytesMap, buf)
+	encoded := buf.Bytes()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadBytesMap(bytes.NewReader(encoded)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteBytesMap(b *testing.B) {
+	buf := NewWriteBuffer(LengthOfBytesMap(benchmarkBytesMap))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := WriteBytesMap(benchmarkBytesMap, buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLengthOfBytesMap(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = LengthOfBytesMap(benchmarkBytesMap)
+	}
+}
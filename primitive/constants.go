@@ -26,6 +26,14 @@ const (
 	ProtocolVersion5 = ProtocolVersion(0x5)
 )
 
+// Beta OSS versions
+// Beta versions are drafts subject to change: they are not included in SupportedProtocolVersions, and can only be
+// encoded and decoded by frame codecs that explicitly allow them. Frames using a beta version must have the USE_BETA
+// flag set.
+const (
+	ProtocolVersion6 = ProtocolVersion(0x6)
+)
+
 // Supported DSE versions
 // Note: all DSE versions have the 7th bit set to 1
 const (
@@ -48,6 +56,7 @@ func (v ProtocolVersion) IsOss() bool {
 	case ProtocolVersion3:
 	case ProtocolVersion4:
 	case ProtocolVersion5:
+	case ProtocolVersion6:
 	default:
 		return false
 	}
@@ -65,7 +74,7 @@ func (v ProtocolVersion) IsDse() bool {
 }
 
 func (v ProtocolVersion) IsBeta() bool {
-	return false // no beta version supported currently
+	return v == ProtocolVersion6
 }
 
 func (v ProtocolVersion) String() string {
@@ -78,6 +87,8 @@ func (v ProtocolVersion) String() string {
 		return "ProtocolVersion OSS 4"
 	case ProtocolVersion5:
 		return "ProtocolVersion OSS 5"
+	case ProtocolVersion6:
+		return "ProtocolVersion OSS 6 (beta)"
 	case ProtocolVersionDse1:
 		return "ProtocolVersion DSE 1"
 	case ProtocolVersionDse2:
@@ -101,7 +112,8 @@ func (v ProtocolVersion) SupportsCompression(compression Compression) bool {
 	case CompressionLz4:
 		return true
 	case CompressionSnappy:
-		return v != ProtocolVersion5
+		// removed with the modern framing layout
+		return !v.SupportsModernFramingLayout()
 	}
 	return false // unknown compression
 }
@@ -144,6 +156,38 @@ func (v ProtocolVersion) SupportsQueryFlag(flag QueryFlag) bool {
 	return false
 }
 
+func (v ProtocolVersion) SupportsRowsFlag(flag RowsFlag) bool {
+	switch flag {
+	case RowsFlagGlobalTablesSpec:
+		return true
+	case RowsFlagHasMorePages:
+		return true
+	case RowsFlagNoMetadata:
+		return true
+	case RowsFlagMetadataChanged:
+		return v.SupportsResultMetadataId()
+	// DSE-specific flags
+	case RowsFlagDseContinuousPaging:
+		return v.IsDse()
+	case RowsFlagDseLastContinuousPage:
+		return v.IsDse()
+	}
+	// Unknown flag
+	return false
+}
+
+func (v ProtocolVersion) SupportsDataType(code DataTypeCode) bool {
+	switch code {
+	case DataTypeCodeUdt, DataTypeCodeTuple:
+		return v >= ProtocolVersion3
+	case DataTypeCodeDate, DataTypeCodeTime, DataTypeCodeSmallint, DataTypeCodeTinyint:
+		return v >= ProtocolVersion4
+	case DataTypeCodeDuration:
+		return v >= ProtocolVersion5
+	}
+	return code.IsValid()
+}
+
 func (v ProtocolVersion) SupportsResultMetadataId() bool {
 	return v >= ProtocolVersion5 && v != ProtocolVersionDse1
 }
@@ -156,6 +200,16 @@ func (v ProtocolVersion) SupportsWriteTimeoutContentions() bool {
 	return v >= ProtocolVersion5 && v != ProtocolVersionDse1 && v != ProtocolVersionDse2
 }
 
+func (v ProtocolVersion) SupportsErrorCode(code ErrorCode) bool {
+	switch code {
+	case ErrorCodeCdcWriteFailure:
+		return v >= ProtocolVersion5
+	case ErrorCodeCasWriteUnknown:
+		return v >= ProtocolVersion5
+	}
+	return code.IsValid()
+}
+
 func (v ProtocolVersion) SupportsSchemaChangeTarget(target SchemaChangeTarget) bool {
 	switch target {
 	case SchemaChangeTargetKeyspace:
@@ -412,6 +466,9 @@ const (
 	ErrorCodeReadFailure     = ErrorCode(0x00001300)
 	ErrorCodeFunctionFailure = ErrorCode(0x00001400)
 	ErrorCodeWriteFailure    = ErrorCode(0x00001500)
+	// ErrorCodeCdcWriteFailure and ErrorCodeCasWriteUnknown were introduced in protocol version 5.
+	ErrorCodeCdcWriteFailure = ErrorCode(0x00001600)
+	ErrorCodeCasWriteUnknown = ErrorCode(0x00001700)
 )
 
 // 2xx: query validation
@@ -438,6 +495,8 @@ func (c ErrorCode) IsValid() bool {
 	case ErrorCodeReadFailure:
 	case ErrorCodeFunctionFailure:
 	case ErrorCodeWriteFailure:
+	case ErrorCodeCdcWriteFailure:
+	case ErrorCodeCasWriteUnknown:
 	case ErrorCodeSyntaxError:
 	case ErrorCodeUnauthorized:
 	case ErrorCodeInvalid:
@@ -472,6 +531,8 @@ func (c ErrorCode) IsRequestExecutionError() bool {
 	case ErrorCodeReadFailure:
 	case ErrorCodeFunctionFailure:
 	case ErrorCodeWriteFailure:
+	case ErrorCodeCdcWriteFailure:
+	case ErrorCodeCasWriteUnknown:
 	default:
 		return false
 	}
@@ -492,6 +553,36 @@ func (c ErrorCode) IsQueryValidationError() bool {
 	return true
 }
 
+// IsTimeout returns true if the error code denotes a read or write timeout, that is, the coordinator did not receive
+// enough responses from replicas in time.
+func (c ErrorCode) IsTimeout() bool {
+	return c == ErrorCodeReadTimeout || c == ErrorCodeWriteTimeout
+}
+
+// IsUnavailable returns true if the error code denotes that the coordinator knew, before executing the request, that
+// not enough replicas were alive to achieve the requested consistency level.
+func (c ErrorCode) IsUnavailable() bool {
+	return c == ErrorCodeUnavailable
+}
+
+// IsRetryable returns true if the error code denotes a transient condition, in which the same request may succeed
+// when retried, possibly on another coordinator: Unavailable, Overloaded, IsBootstrapping, TruncateError, ReadTimeout
+// and WriteTimeout. Note that retrying a write after a WriteTimeout is only safe if the write is idempotent; whether
+// it is cannot be determined from the error code alone.
+func (c ErrorCode) IsRetryable() bool {
+	switch c {
+	case ErrorCodeUnavailable:
+	case ErrorCodeOverloaded:
+	case ErrorCodeIsBootstrapping:
+	case ErrorCodeTruncateError:
+	case ErrorCodeReadTimeout:
+	case ErrorCodeWriteTimeout:
+	default:
+		return false
+	}
+	return true
+}
+
 func (c ErrorCode) String() string {
 	switch c {
 	case ErrorCodeServerError:
@@ -518,6 +609,10 @@ func (c ErrorCode) String() string {
 		return "ErrorCode FunctionFailure [0x00001400]"
 	case ErrorCodeWriteFailure:
 		return "ErrorCode WriteFailure [0x00001500]"
+	case ErrorCodeCdcWriteFailure:
+		return "ErrorCode CdcWriteFailure [0x00001600]"
+	case ErrorCodeCasWriteUnknown:
+		return "ErrorCode CasWriteUnknown [0x00001700]"
 	case ErrorCodeSyntaxError:
 		return "ErrorCode SyntaxError [0x00002000]"
 	case ErrorCodeUnauthorized:
@@ -624,6 +719,32 @@ func (c ConsistencyLevel) IsNonLocal() bool {
 	return true
 }
 
+// RequiredReplicas returns the number of replicas that must acknowledge a request at this consistency level, given the
+// replication factor rf; it makes consistency levels comparable for a given replication factor. For local consistency
+// levels, rf is the replication factor of the local datacenter; for EACH_QUORUM, it is the replication factor of each
+// datacenter, and the result applies to each datacenter. The result may be greater than rf, in which case the
+// consistency level cannot be achieved. ANY requires one replica or hint. Zero is returned for invalid consistency
+// levels.
+func (c ConsistencyLevel) RequiredReplicas(rf int) int {
+	switch c {
+	case ConsistencyLevelAny, ConsistencyLevelOne, ConsistencyLevelLocalOne:
+		return 1
+	case ConsistencyLevelTwo:
+		return 2
+	case ConsistencyLevelThree:
+		return 3
+	case ConsistencyLevelQuorum,
+		ConsistencyLevelLocalQuorum,
+		ConsistencyLevelEachQuorum,
+		ConsistencyLevelSerial,
+		ConsistencyLevelLocalSerial:
+		return rf/2 + 1
+	case ConsistencyLevelAll:
+		return rf
+	}
+	return 0
+}
+
 func (c ConsistencyLevel) String() string {
 	switch c {
 	case ConsistencyLevelAny:
@@ -672,6 +793,7 @@ func (t WriteType) IsValid() bool {
 	case WriteTypeUnloggedBatch:
 	case WriteTypeCounter:
 	case WriteTypeBatchLog:
+	case WriteTypeCas:
 	case WriteTypeView:
 	case WriteTypeCdc:
 	default:
@@ -1010,24 +1132,41 @@ func (f HeaderFlag) Remove(other HeaderFlag) HeaderFlag {
 	return f &^ other
 }
 
+// Contains returns true if this mask contains any of the flags in other.
 func (f HeaderFlag) Contains(other HeaderFlag) bool {
 	return f&other != 0
 }
 
+// Has returns true if this mask contains all the flags in other.
+func (f HeaderFlag) Has(other HeaderFlag) bool {
+	return f&other == other
+}
+
+func (f HeaderFlag) Bits() uint32 {
+	return uint32(f)
+}
+
+func (f HeaderFlag) Names() []string {
+	return headerFlagDefs.names(uint32(f))
+}
+
 func (f HeaderFlag) String() string {
-	switch f {
-	case HeaderFlagCompressed:
-		return fmt.Sprintf("HeaderFlag Compressed [0x01 %#.8b]", f)
-	case HeaderFlagTracing:
-		return fmt.Sprintf("HeaderFlag Tracing [0x02 %#.8b]", f)
-	case HeaderFlagCustomPayload:
-		return fmt.Sprintf("HeaderFlag CustomPayload [0x04 %#.8b]", f)
-	case HeaderFlagWarning:
-		return fmt.Sprintf("HeaderFlag Warning [0x08 %#.8b]", f)
-	case HeaderFlagUseBeta:
-		return fmt.Sprintf("HeaderFlag UseBeta [0x10 %#.8b]", f)
+	return headerFlagDefs.format(uint32(f))
+}
+
+// MarshalJSON marshals this mask as an array of flag names, see FlagSet.
+func (f HeaderFlag) MarshalJSON() ([]byte, error) {
+	return headerFlagDefs.marshalJSON(uint32(f))
+}
+
+// UnmarshalJSON unmarshals this mask from an array of flag names or a number, see FlagSet.
+func (f *HeaderFlag) UnmarshalJSON(data []byte) error {
+	bits, err := headerFlagDefs.unmarshalJSON(data)
+	if err != nil {
+		return err
 	}
-	return fmt.Sprintf("HeaderFlag ? [%#.2X %#.8b]", uint8(f), uint8(f))
+	*f = HeaderFlag(bits)
+	return nil
 }
 
 // QueryFlag was encoded as [byte] in v3 and v4, but changed to [int] in v5.
@@ -1059,36 +1198,41 @@ func (f QueryFlag) Remove(other QueryFlag) QueryFlag {
 	return f &^ other
 }
 
+// Contains returns true if this mask contains any of the flags in other.
 func (f QueryFlag) Contains(other QueryFlag) bool {
 	return f&other != 0
 }
 
+// Has returns true if this mask contains all the flags in other.
+func (f QueryFlag) Has(other QueryFlag) bool {
+	return f&other == other
+}
+
+func (f QueryFlag) Bits() uint32 {
+	return uint32(f)
+}
+
+func (f QueryFlag) Names() []string {
+	return queryFlagDefs.names(uint32(f))
+}
+
 func (f QueryFlag) String() string {
-	switch f {
-	case QueryFlagValues:
-		return fmt.Sprintf("QueryFlag Values [0x00000001 %#.32b]", f)
-	case QueryFlagSkipMetadata:
-		return fmt.Sprintf("QueryFlag SkipMetadata [0x00000002 %#.32b]", f)
-	case QueryFlagPageSize:
-		return fmt.Sprintf("QueryFlag PageSize [0x00000004 %#.32b]", f)
-	case QueryFlagPagingState:
-		return fmt.Sprintf("QueryFlag PagingState [0x00000008 %#.32b]", f)
-	case QueryFlagSerialConsistency:
-		return fmt.Sprintf("QueryFlag SerialConsistency [0x00000010 %#.32b]", f)
-	case QueryFlagDefaultTimestamp:
-		return fmt.Sprintf("QueryFlag DefaultTimestamp [0x00000020 %#.32b]", f)
-	case QueryFlagValueNames:
-		return fmt.Sprintf("QueryFlag ValueNames [0x00000040 %#.32b]", f)
-	case QueryFlagWithKeyspace:
-		return fmt.Sprintf("QueryFlag WithKeyspace [0x00000080 %#.32b]", f)
-	case QueryFlagNowInSeconds:
-		return fmt.Sprintf("QueryFlag NowInSeconds [0x00000100 %#.32b]", f)
-	case QueryFlagDsePageSizeBytes:
-		return fmt.Sprintf("QueryFlag DsePageSizeBytes [0x40000000 %#.32b]", f)
-	case QueryFlagDseWithContinuousPagingOptions:
-		return fmt.Sprintf("QueryFlag DseWithContinuousPagingOptions [0x80000000 %#.32b]", f)
+	return queryFlagDefs.format(uint32(f))
+}
+
+// MarshalJSON marshals this mask as an array of flag names, see FlagSet.
+func (f QueryFlag) MarshalJSON() ([]byte, error) {
+	return queryFlagDefs.marshalJSON(uint32(f))
+}
+
+// UnmarshalJSON unmarshals this mask from an array of flag names or a number, see FlagSet.
+func (f *QueryFlag) UnmarshalJSON(data []byte) error {
+	bits, err := queryFlagDefs.unmarshalJSON(data)
+	if err != nil {
+		return err
 	}
-	return fmt.Sprintf("QueryFlag ? [%#.8X %#.32b]", uint32(f), uint32(f))
+	*f = QueryFlag(bits)
+	return nil
 }
 
 type RowsFlag uint32
@@ -1114,27 +1258,41 @@ func (f RowsFlag) Remove(other RowsFlag) RowsFlag {
 	return f &^ other
 }
 
+// Contains returns true if this mask contains any of the flags in other.
 func (f RowsFlag) Contains(other RowsFlag) bool {
 	return f&other != 0
 }
 
+// Has returns true if this mask contains all the flags in other.
+func (f RowsFlag) Has(other RowsFlag) bool {
+	return f&other == other
+}
+
+func (f RowsFlag) Bits() uint32 {
+	return uint32(f)
+}
+
+func (f RowsFlag) Names() []string {
+	return rowsFlagDefs.names(uint32(f))
+}
+
 func (f RowsFlag) String() string {
-	switch f {
-	case RowsFlagGlobalTablesSpec:
-		return fmt.Sprintf("RowsFlag GlobalTablesSpec [0x00000001 %#.32b]", f)
-	case RowsFlagHasMorePages:
-		return fmt.Sprintf("RowsFlag HasMorePages [0x00000002 %#.32b]", f)
-	case RowsFlagNoMetadata:
-		return fmt.Sprintf("RowsFlag NoMetadata [0x00000004 %#.32b]", f)
-	case RowsFlagMetadataChanged:
-		return fmt.Sprintf("RowsFlag MetadataChanged [0x00000008 %#.32b]", f)
-	// DSE-specific flags
-	case RowsFlagDseContinuousPaging:
-		return fmt.Sprintf("RowsFlag ContinuousPaging [0x40000000 %#.32b]", f)
-	case RowsFlagDseLastContinuousPage:
-		return fmt.Sprintf("RowsFlag LastContinuousPage [0x80000000 %#.32b]", f)
+	return rowsFlagDefs.format(uint32(f))
+}
+
+// MarshalJSON marshals this mask as an array of flag names, see FlagSet.
+func (f RowsFlag) MarshalJSON() ([]byte, error) {
+	return rowsFlagDefs.marshalJSON(uint32(f))
+}
+
+// UnmarshalJSON unmarshals this mask from an array of flag names or a number, see FlagSet.
+func (f *RowsFlag) UnmarshalJSON(data []byte) error {
+	bits, err := rowsFlagDefs.unmarshalJSON(data)
+	if err != nil {
+		return err
 	}
-	return fmt.Sprintf("RowsFlag ? [%#.8X %#.32b]", uint32(f), uint32(f))
+	*f = RowsFlag(bits)
+	return nil
 }
 
 type VariablesFlag uint32
@@ -1151,16 +1309,41 @@ func (f VariablesFlag) Remove(other VariablesFlag) VariablesFlag {
 	return f &^ other
 }
 
+// Contains returns true if this mask contains any of the flags in other.
 func (f VariablesFlag) Contains(other VariablesFlag) bool {
 	return f&other != 0
 }
 
+// Has returns true if this mask contains all the flags in other.
+func (f VariablesFlag) Has(other VariablesFlag) bool {
+	return f&other == other
+}
+
+func (f VariablesFlag) Bits() uint32 {
+	return uint32(f)
+}
+
+func (f VariablesFlag) Names() []string {
+	return variablesFlagDefs.names(uint32(f))
+}
+
 func (f VariablesFlag) String() string {
-	switch f {
-	case VariablesFlagGlobalTablesSpec:
-		return fmt.Sprintf("VariablesFlag GlobalTablesSpec [0x00000001 %#.32b]", f)
+	return variablesFlagDefs.format(uint32(f))
+}
+
+// MarshalJSON marshals this mask as an array of flag names, see FlagSet.
+func (f VariablesFlag) MarshalJSON() ([]byte, error) {
+	return variablesFlagDefs.marshalJSON(uint32(f))
+}
+
+// UnmarshalJSON unmarshals this mask from an array of flag names or a number, see FlagSet.
+func (f *VariablesFlag) UnmarshalJSON(data []byte) error {
+	bits, err := variablesFlagDefs.unmarshalJSON(data)
+	if err != nil {
+		return err
 	}
-	return fmt.Sprintf("VariablesFlag ? [%#.8X %#.32b]", uint32(f), uint32(f))
+	*f = VariablesFlag(bits)
+	return nil
 }
 
 type PrepareFlag uint32
@@ -1177,16 +1360,41 @@ func (f PrepareFlag) Remove(other PrepareFlag) PrepareFlag {
 	return f &^ other
 }
 
+// Contains returns true if this mask contains any of the flags in other.
 func (f PrepareFlag) Contains(other PrepareFlag) bool {
 	return f&other != 0
 }
 
+// Has returns true if this mask contains all the flags in other.
+func (f PrepareFlag) Has(other PrepareFlag) bool {
+	return f&other == other
+}
+
+func (f PrepareFlag) Bits() uint32 {
+	return uint32(f)
+}
+
+func (f PrepareFlag) Names() []string {
+	return prepareFlagDefs.names(uint32(f))
+}
+
 func (f PrepareFlag) String() string {
-	switch f {
-	case PrepareFlagWithKeyspace:
-		return fmt.Sprintf("PrepareFlag WithKeyspace [0x00000001 %#.32b]", f)
+	return prepareFlagDefs.format(uint32(f))
+}
+
+// MarshalJSON marshals this mask as an array of flag names, see FlagSet.
+func (f PrepareFlag) MarshalJSON() ([]byte, error) {
+	return prepareFlagDefs.marshalJSON(uint32(f))
+}
+
+// UnmarshalJSON unmarshals this mask from an array of flag names or a number, see FlagSet.
+func (f *PrepareFlag) UnmarshalJSON(data []byte) error {
+	bits, err := prepareFlagDefs.unmarshalJSON(data)
+	if err != nil {
+		return err
 	}
-	return fmt.Sprintf("PrepareFlag ? [%#.8X %#.32b]", uint32(f), uint32(f))
+	*f = PrepareFlag(bits)
+	return nil
 }
 
 type DseRevisionType uint32
@@ -14,7 +14,12 @@
 
 package primitive
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestProtocolVersion_String(t *testing.T) {
 	tests := []struct {
@@ -28,7 +33,8 @@ func TestProtocolVersion_String(t *testing.T) {
 		{"v5", ProtocolVersion5, "ProtocolVersion OSS 5"},
 		{"DSE v1", ProtocolVersionDse1, "ProtocolVersion DSE 1"},
 		{"DSE v2", ProtocolVersionDse2, "ProtocolVersion DSE 2"},
-		{"unknown", ProtocolVersion(6), "ProtocolVersion ? [0X06]"},
+		{"v6 beta", ProtocolVersion6, "ProtocolVersion OSS 6 (beta)"},
+		{"unknown", ProtocolVersion(7), "ProtocolVersion ? [0X07]"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -39,6 +45,125 @@ func TestProtocolVersion_String(t *testing.T) {
 	}
 }
 
+func TestProtocolVersion_Beta(t *testing.T) {
+	assert.True(t, ProtocolVersion6.IsBeta())
+	assert.True(t, ProtocolVersion6.IsOss())
+	assert.False(t, ProtocolVersion6.IsSupported())
+	assert.NotContains(t, SupportedProtocolVersions(), ProtocolVersion6)
+	assert.Equal(t, []ProtocolVersion{ProtocolVersion6}, SupportedBetaProtocolVersions())
+	for _, v := range SupportedProtocolVersions() {
+		assert.False(t, v.IsBeta(), v.String())
+	}
+	// v6 is based on v5
+	assert.True(t, ProtocolVersion6.SupportsModernFramingLayout())
+	assert.True(t, ProtocolVersion6.SupportsResultMetadataId())
+	assert.False(t, ProtocolVersion6.SupportsCompression(CompressionSnappy))
+	assert.True(t, ProtocolVersion4.SupportsCompression(CompressionSnappy))
+	assert.True(t, ProtocolVersionDse2.SupportsCompression(CompressionSnappy))
+}
+
+func TestProtocolVersion_SupportsRowsFlag(t *testing.T) {
+	for _, v := range SupportedProtocolVersions() {
+		assert.True(t, v.SupportsRowsFlag(RowsFlagGlobalTablesSpec), v.String())
+		assert.True(t, v.SupportsRowsFlag(RowsFlagHasMorePages), v.String())
+		assert.True(t, v.SupportsRowsFlag(RowsFlagNoMetadata), v.String())
+		assert.Equal(t, v.SupportsResultMetadataId(), v.SupportsRowsFlag(RowsFlagMetadataChanged), v.String())
+		assert.Equal(t, v.IsDse(), v.SupportsRowsFlag(RowsFlagDseContinuousPaging), v.String())
+		assert.Equal(t, v.IsDse(), v.SupportsRowsFlag(RowsFlagDseLastContinuousPage), v.String())
+	}
+	assert.False(t, ProtocolVersion4.SupportsRowsFlag(RowsFlag(0x10)))
+}
+
+func TestProtocolVersion_SupportsDataType(t *testing.T) {
+	assert.False(t, ProtocolVersion2.SupportsDataType(DataTypeCodeUdt))
+	assert.False(t, ProtocolVersion2.SupportsDataType(DataTypeCodeTuple))
+	assert.True(t, ProtocolVersion3.SupportsDataType(DataTypeCodeTuple))
+	assert.False(t, ProtocolVersion3.SupportsDataType(DataTypeCodeDate))
+	assert.True(t, ProtocolVersion4.SupportsDataType(DataTypeCodeTinyint))
+	assert.False(t, ProtocolVersion4.SupportsDataType(DataTypeCodeDuration))
+	assert.True(t, ProtocolVersion5.SupportsDataType(DataTypeCodeDuration))
+	assert.True(t, ProtocolVersionDse1.SupportsDataType(DataTypeCodeDuration))
+	assert.True(t, ProtocolVersion2.SupportsDataType(DataTypeCodeVarchar))
+	assert.False(t, ProtocolVersion5.SupportsDataType(DataTypeCode(0x0016)))
+	assert.EqualError(t, CheckValidDataTypeCode(DataTypeCodeDate, ProtocolVersion3),
+		"invalid data type code for ProtocolVersion OSS 3: DataTypeCode Date [0x0011]")
+}
+
+func TestProtocolVersion_SupportsErrorCode(t *testing.T) {
+	assert.False(t, ProtocolVersion4.SupportsErrorCode(ErrorCodeCdcWriteFailure))
+	assert.False(t, ProtocolVersion4.SupportsErrorCode(ErrorCodeCasWriteUnknown))
+	assert.True(t, ProtocolVersion5.SupportsErrorCode(ErrorCodeCdcWriteFailure))
+	assert.True(t, ProtocolVersion5.SupportsErrorCode(ErrorCodeCasWriteUnknown))
+	assert.True(t, ProtocolVersion2.SupportsErrorCode(ErrorCodeWriteFailure))
+	assert.False(t, ProtocolVersion5.SupportsErrorCode(ErrorCode(0x1800)))
+}
+
+func TestWriteType_IsValid(t *testing.T) {
+	assert.True(t, WriteTypeCas.IsValid())
+	assert.True(t, WriteTypeCdc.IsValid())
+	assert.False(t, WriteType("UNKNOWN").IsValid())
+}
+
+func TestErrorCode_Classification(t *testing.T) {
+	tests := []struct {
+		code        ErrorCode
+		timeout     bool
+		unavailable bool
+		retryable   bool
+	}{
+		{ErrorCodeServerError, false, false, false},
+		{ErrorCodeUnavailable, false, true, true},
+		{ErrorCodeOverloaded, false, false, true},
+		{ErrorCodeIsBootstrapping, false, false, true},
+		{ErrorCodeReadTimeout, true, false, true},
+		{ErrorCodeWriteTimeout, true, false, true},
+		{ErrorCodeReadFailure, false, false, false},
+		{ErrorCodeCdcWriteFailure, false, false, false},
+		{ErrorCodeCasWriteUnknown, false, false, false},
+		{ErrorCodeUnprepared, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			assert.True(t, tt.code.IsValid())
+			assert.Equal(t, tt.timeout, tt.code.IsTimeout())
+			assert.Equal(t, tt.unavailable, tt.code.IsUnavailable())
+			assert.Equal(t, tt.retryable, tt.code.IsRetryable())
+		})
+	}
+	assert.True(t, ErrorCodeCasWriteUnknown.IsRequestExecutionError())
+	assert.Equal(t, "ErrorCode CdcWriteFailure [0x00001600]", ErrorCodeCdcWriteFailure.String())
+}
+
+func TestConsistencyLevel_RequiredReplicas(t *testing.T) {
+	tests := []struct {
+		level    ConsistencyLevel
+		rf       int
+		expected int
+	}{
+		{ConsistencyLevelAny, 3, 1},
+		{ConsistencyLevelOne, 3, 1},
+		{ConsistencyLevelLocalOne, 3, 1},
+		{ConsistencyLevelTwo, 3, 2},
+		{ConsistencyLevelThree, 3, 3},
+		{ConsistencyLevelThree, 1, 3},
+		{ConsistencyLevelQuorum, 1, 1},
+		{ConsistencyLevelQuorum, 3, 2},
+		{ConsistencyLevelQuorum, 4, 3},
+		{ConsistencyLevelLocalQuorum, 5, 3},
+		{ConsistencyLevelEachQuorum, 3, 2},
+		{ConsistencyLevelSerial, 3, 2},
+		{ConsistencyLevelLocalSerial, 3, 2},
+		{ConsistencyLevelAll, 3, 3},
+		{ConsistencyLevelAll, 6, 6},
+		{ConsistencyLevel(0x00FF), 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v rf=%d", tt.level, tt.rf), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.level.RequiredReplicas(tt.rf))
+		})
+	}
+}
+
 func TestDataTypeCode_IsValid(t *testing.T) {
 	tests := []struct {
 		name          string
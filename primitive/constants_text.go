@@ -0,0 +1,120 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Canonical names of consistency levels, as used by cqlsh, the Java driver and Cassandra itself.
+var consistencyLevelNames = map[ConsistencyLevel]string{
+	ConsistencyLevelAny:         "ANY",
+	ConsistencyLevelOne:         "ONE",
+	ConsistencyLevelTwo:         "TWO",
+	ConsistencyLevelThree:       "THREE",
+	ConsistencyLevelQuorum:      "QUORUM",
+	ConsistencyLevelAll:         "ALL",
+	ConsistencyLevelLocalQuorum: "LOCAL_QUORUM",
+	ConsistencyLevelEachQuorum:  "EACH_QUORUM",
+	ConsistencyLevelSerial:      "SERIAL",
+	ConsistencyLevelLocalSerial: "LOCAL_SERIAL",
+	ConsistencyLevelLocalOne:    "LOCAL_ONE",
+}
+
+// Canonical names of batch types, as used in CQL statements and by the Java driver.
+var batchTypeNames = map[BatchType]string{
+	BatchTypeLogged:   "LOGGED",
+	BatchTypeUnlogged: "UNLOGGED",
+	BatchTypeCounter:  "COUNTER",
+}
+
+// MarshalText implements encoding.TextMarshaler. Consistency levels are marshaled using their canonical names, e.g.
+// "LOCAL_QUORUM"; unknown consistency levels are marshaled in hexadecimal, as in String, e.g. "0X00FF", so that
+// messages carrying them, e.g. decoded by lenient codecs, can still be marshaled. This also makes ConsistencyLevel
+// marshal to a JSON string.
+func (c ConsistencyLevel) MarshalText() ([]byte, error) {
+	if name, found := consistencyLevelNames[c]; found {
+		return []byte(name), nil
+	}
+	return []byte(fmt.Sprintf("%#.4X", uint16(c))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Canonical names are matched case-insensitively, so both
+// "LOCAL_QUORUM" and "local_quorum" are accepted; numeric values, e.g. "0X00FF" as produced by MarshalText for unknown
+// consistency levels, are accepted as well.
+func (c *ConsistencyLevel) UnmarshalText(text []byte) error {
+	if level, err := ParseConsistencyLevel(string(text)); err == nil {
+		*c = level
+	} else if value, err := parseNumericText(text, 16); err == nil {
+		*c = ConsistencyLevel(value)
+	} else {
+		return fmt.Errorf("cannot unmarshal unknown consistency level: %q", text)
+	}
+	return nil
+}
+
+// ParseConsistencyLevel returns the consistency level with the given canonical name, e.g. "LOCAL_QUORUM", as returned
+// by ConsistencyLevel.Name. Names are matched case-insensitively, and surrounding spaces are ignored, which makes this
+// function suitable to parse user-supplied configuration values.
+func ParseConsistencyLevel(name string) (ConsistencyLevel, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(name))
+	for level, levelName := range consistencyLevelNames {
+		if levelName == normalized {
+			return level, nil
+		}
+	}
+	return ConsistencyLevelAny, fmt.Errorf("unknown consistency level: %q", name)
+}
+
+// Name returns the canonical name of this consistency level, as used by cqlsh, e.g. "LOCAL_QUORUM", or an empty string
+// if this consistency level is invalid. The returned name can be parsed back with ParseConsistencyLevel.
+func (c ConsistencyLevel) Name() string {
+	return consistencyLevelNames[c]
+}
+
+// MarshalText implements encoding.TextMarshaler. Batch types are marshaled using their canonical names, e.g.
+// "UNLOGGED"; unknown batch types are marshaled in hexadecimal, as in String, e.g. "0X0F". This also makes BatchType
+// marshal to a JSON string.
+func (t BatchType) MarshalText() ([]byte, error) {
+	if name, found := batchTypeNames[t]; found {
+		return []byte(name), nil
+	}
+	return []byte(fmt.Sprintf("%#.2X", uint8(t))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Canonical names are matched case-insensitively; numeric values,
+// e.g. "0X0F" as produced by MarshalText for unknown batch types, are accepted as well.
+func (t *BatchType) UnmarshalText(text []byte) error {
+	name := strings.ToUpper(strings.TrimSpace(string(text)))
+	for batchType, batchTypeName := range batchTypeNames {
+		if batchTypeName == name {
+			*t = batchType
+			return nil
+		}
+	}
+	if value, err := parseNumericText(text, 8); err == nil {
+		*t = BatchType(value)
+		return nil
+	}
+	return fmt.Errorf("cannot unmarshal unknown batch type: %q", text)
+}
+
+// parseNumericText parses the given decimal or hexadecimal text, e.g. "0X00FF", into an unsigned integer of the given
+// bit size.
+func parseNumericText(text []byte, bitSize int) (uint64, error) {
+	return strconv.ParseUint(strings.TrimSpace(string(text)), 0, bitSize)
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsistencyLevel_MarshalText(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    ConsistencyLevel
+		expected string
+		err      string
+	}{
+		{"ANY", ConsistencyLevelAny, "ANY", ""},
+		{"ONE", ConsistencyLevelOne, "ONE", ""},
+		{"TWO", ConsistencyLevelTwo, "TWO", ""},
+		{"THREE", ConsistencyLevelThree, "THREE", ""},
+		{"QUORUM", ConsistencyLevelQuorum, "QUORUM", ""},
+		{"ALL", ConsistencyLevelAll, "ALL", ""},
+		{"LOCAL_QUORUM", ConsistencyLevelLocalQuorum, "LOCAL_QUORUM", ""},
+		{"EACH_QUORUM", ConsistencyLevelEachQuorum, "EACH_QUORUM", ""},
+		{"SERIAL", ConsistencyLevelSerial, "SERIAL", ""},
+		{"LOCAL_SERIAL", ConsistencyLevelLocalSerial, "LOCAL_SERIAL", ""},
+		{"LOCAL_ONE", ConsistencyLevelLocalOne, "LOCAL_ONE", ""},
+		{"unknown", ConsistencyLevel(0x00FF), "0X00FF", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.level.MarshalText()
+			if tt.err == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, string(actual))
+				var decoded ConsistencyLevel
+				require.NoError(t, decoded.UnmarshalText(actual))
+				assert.Equal(t, tt.level, decoded)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestConsistencyLevel_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected ConsistencyLevel
+		err      string
+	}{
+		{"canonical", "LOCAL_QUORUM", ConsistencyLevelLocalQuorum, ""},
+		{"lower case", "local_quorum", ConsistencyLevelLocalQuorum, ""},
+		{"mixed case with spaces", " Local_One ", ConsistencyLevelLocalOne, ""},
+		{"unknown", "LOCAL_TWO", ConsistencyLevelAny, `cannot unmarshal unknown consistency level: "LOCAL_TWO"`},
+		{"empty", "", ConsistencyLevelAny, `cannot unmarshal unknown consistency level: ""`},
+		{"hexadecimal", "0X00FF", ConsistencyLevel(0x00FF), ""},
+		{"lower case hexadecimal", "0x000a", ConsistencyLevelLocalOne, ""},
+		{"decimal", "6", ConsistencyLevelLocalQuorum, ""},
+		{"out of range", "0x10000", ConsistencyLevelAny, `cannot unmarshal unknown consistency level: "0x10000"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual ConsistencyLevel
+			err := actual.UnmarshalText([]byte(tt.text))
+			assert.Equal(t, tt.expected, actual)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseConsistencyLevel(t *testing.T) {
+	for level := range consistencyLevelNames {
+		t.Run(level.Name(), func(t *testing.T) {
+			actual, err := ParseConsistencyLevel(level.Name())
+			require.NoError(t, err)
+			assert.Equal(t, level, actual)
+			actual, err = ParseConsistencyLevel(strings.ToLower(level.Name()))
+			require.NoError(t, err)
+			assert.Equal(t, level, actual)
+		})
+	}
+	_, err := ParseConsistencyLevel("LOCAL_TWO")
+	assert.EqualError(t, err, `unknown consistency level: "LOCAL_TWO"`)
+	_, err = ParseConsistencyLevel("")
+	assert.EqualError(t, err, `unknown consistency level: ""`)
+	assert.Equal(t, "LOCAL_QUORUM", ConsistencyLevelLocalQuorum.Name())
+	assert.Equal(t, "", ConsistencyLevel(0x00FF).Name())
+}
+
+func TestConsistencyLevel_JSON(t *testing.T) {
+	type config struct {
+		Consistency       ConsistencyLevel `json:"consistency"`
+		SerialConsistency ConsistencyLevel `json:"serial_consistency"`
+		BatchType         BatchType        `json:"batch_type"`
+	}
+	expected := config{ConsistencyLevelLocalQuorum, ConsistencyLevelLocalSerial, BatchTypeUnlogged}
+	encoded, err := json.Marshal(expected)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"consistency":"LOCAL_QUORUM","serial_consistency":"LOCAL_SERIAL","batch_type":"UNLOGGED"}`, string(encoded))
+	var actual config
+	require.NoError(t, json.Unmarshal([]byte(`{"consistency":"local_quorum","serial_consistency":"LOCAL_SERIAL","batch_type":"unlogged"}`), &actual))
+	assert.Equal(t, expected, actual)
+	err = json.Unmarshal([]byte(`{"consistency":"WHATEVER"}`), &actual)
+	assert.Error(t, err)
+}
+
+func TestBatchType_MarshalText(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchType BatchType
+		expected  string
+		err       string
+	}{
+		{"LOGGED", BatchTypeLogged, "LOGGED", ""},
+		{"UNLOGGED", BatchTypeUnlogged, "UNLOGGED", ""},
+		{"COUNTER", BatchTypeCounter, "COUNTER", ""},
+		{"unknown", BatchType(0x0F), "0X0F", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.batchType.MarshalText()
+			if tt.err == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, string(actual))
+				var decoded BatchType
+				require.NoError(t, decoded.UnmarshalText(actual))
+				assert.Equal(t, tt.batchType, decoded)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+	var actual BatchType
+	assert.EqualError(t, actual.UnmarshalText([]byte("BATCH")), `cannot unmarshal unknown batch type: "BATCH"`)
+	assert.EqualError(t, actual.UnmarshalText([]byte("0x100")), `cannot unmarshal unknown batch type: "0x100"`)
+}
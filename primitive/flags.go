@@ -0,0 +1,201 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FlagSet is implemented by all the bit mask types of this package: HeaderFlag, QueryFlag (also used for BATCH
+// flags), RowsFlag, VariablesFlag and PrepareFlag. It gives uniform, read-only access to the flags set in a mask,
+// regardless of its type and width. Each mask type also has the typed methods Has, Contains, Add and Remove.
+//
+// All mask types print the names of all the flags they contain, e.g.:
+//
+//  RowsFlag HasMorePages|NoMetadata [0x00000006 0b00000000000000000000000000000110]
+//
+// And marshal to JSON as an array of flag names, e.g. ["HasMorePages","NoMetadata"]; unknown bits are represented
+// by a hex string, e.g. "0x00000040". Both forms, as well as plain numbers, are accepted when unmarshalling.
+type FlagSet interface {
+	fmt.Stringer
+	json.Marshaler
+
+	// Bits returns the raw bit mask.
+	Bits() uint32
+
+	// Names returns the names of the known flags set in the mask, in ascending bit order, followed by the hex value
+	// of the remaining unknown bits, if any.
+	Names() []string
+}
+
+// flagDef describes a single flag of a mask type.
+type flagDef struct {
+	mask uint32
+	name string
+}
+
+// flagDefs describes all the known flags of a mask type.
+type flagDefs struct {
+	typeName string
+	// bitWidth is the width of the mask type, in bits: 8 or 32.
+	bitWidth int
+	flags    []flagDef
+}
+
+func (d *flagDefs) names(bits uint32) []string {
+	var names []string
+	for _, flag := range d.flags {
+		if bits&flag.mask != 0 {
+			names = append(names, flag.name)
+			bits &^= flag.mask
+		}
+	}
+	if bits != 0 {
+		names = append(names, d.hex(bits))
+	}
+	return names
+}
+
+func (d *flagDefs) hex(bits uint32) string {
+	return fmt.Sprintf("0x%0*X", d.bitWidth/4, bits)
+}
+
+func (d *flagDefs) format(bits uint32) string {
+	var desc string
+	if bits == 0 {
+		desc = "None"
+	} else {
+		names := d.names(bits)
+		if unknown := strings.HasPrefix(names[len(names)-1], "0x"); unknown {
+			names[len(names)-1] = "?"
+		}
+		desc = strings.Join(names, "|")
+	}
+	return fmt.Sprintf("%s %s [%s %#.*b]", d.typeName, desc, d.hex(bits), d.bitWidth, bits)
+}
+
+func (d *flagDefs) marshalJSON(bits uint32) ([]byte, error) {
+	names := d.names(bits)
+	if names == nil {
+		names = []string{}
+	}
+	return json.Marshal(names)
+}
+
+func (d *flagDefs) unmarshalJSON(data []byte) (uint32, error) {
+	var number *uint64
+	if err := json.Unmarshal(data, &number); err == nil {
+		if number == nil {
+			return 0, nil
+		}
+		return d.checkWidth(*number)
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return 0, fmt.Errorf("cannot unmarshal %s: expected array of flag names or number, got: %s", d.typeName, data)
+	}
+	var bits uint64
+	for _, name := range names {
+		mask, err := d.parse(name)
+		if err != nil {
+			return 0, err
+		}
+		bits |= mask
+	}
+	return d.checkWidth(bits)
+}
+
+func (d *flagDefs) parse(name string) (uint64, error) {
+	for _, flag := range d.flags {
+		if flag.name == name {
+			return uint64(flag.mask), nil
+		}
+	}
+	if strings.HasPrefix(name, "0x") || strings.HasPrefix(name, "0X") {
+		if bits, err := strconv.ParseUint(name[2:], 16, 32); err == nil {
+			return bits, nil
+		}
+	}
+	return 0, fmt.Errorf("cannot unmarshal %s: unknown flag: %s", d.typeName, name)
+}
+
+func (d *flagDefs) checkWidth(bits uint64) (uint32, error) {
+	if bits>>d.bitWidth != 0 {
+		return 0, fmt.Errorf("cannot unmarshal %s: value out of range: %#x", d.typeName, bits)
+	}
+	return uint32(bits), nil
+}
+
+var headerFlagDefs = &flagDefs{
+	typeName: "HeaderFlag",
+	bitWidth: 8,
+	flags: []flagDef{
+		{uint32(HeaderFlagCompressed), "Compressed"},
+		{uint32(HeaderFlagTracing), "Tracing"},
+		{uint32(HeaderFlagCustomPayload), "CustomPayload"},
+		{uint32(HeaderFlagWarning), "Warning"},
+		{uint32(HeaderFlagUseBeta), "UseBeta"},
+	},
+}
+
+var queryFlagDefs = &flagDefs{
+	typeName: "QueryFlag",
+	bitWidth: 32,
+	flags: []flagDef{
+		{uint32(QueryFlagValues), "Values"},
+		{uint32(QueryFlagSkipMetadata), "SkipMetadata"},
+		{uint32(QueryFlagPageSize), "PageSize"},
+		{uint32(QueryFlagPagingState), "PagingState"},
+		{uint32(QueryFlagSerialConsistency), "SerialConsistency"},
+		{uint32(QueryFlagDefaultTimestamp), "DefaultTimestamp"},
+		{uint32(QueryFlagValueNames), "ValueNames"},
+		{uint32(QueryFlagWithKeyspace), "WithKeyspace"},
+		{uint32(QueryFlagNowInSeconds), "NowInSeconds"},
+		{uint32(QueryFlagDsePageSizeBytes), "DsePageSizeBytes"},
+		{uint32(QueryFlagDseWithContinuousPagingOptions), "DseWithContinuousPagingOptions"},
+	},
+}
+
+var rowsFlagDefs = &flagDefs{
+	typeName: "RowsFlag",
+	bitWidth: 32,
+	flags: []flagDef{
+		{uint32(RowsFlagGlobalTablesSpec), "GlobalTablesSpec"},
+		{uint32(RowsFlagHasMorePages), "HasMorePages"},
+		{uint32(RowsFlagNoMetadata), "NoMetadata"},
+		{uint32(RowsFlagMetadataChanged), "MetadataChanged"},
+		{uint32(RowsFlagDseContinuousPaging), "ContinuousPaging"},
+		{uint32(RowsFlagDseLastContinuousPage), "LastContinuousPage"},
+	},
+}
+
+var variablesFlagDefs = &flagDefs{
+	typeName: "VariablesFlag",
+	bitWidth: 32,
+	flags: []flagDef{
+		{uint32(VariablesFlagGlobalTablesSpec), "GlobalTablesSpec"},
+	},
+}
+
+var prepareFlagDefs = &flagDefs{
+	typeName: "PrepareFlag",
+	bitWidth: 32,
+	flags: []flagDef{
+		{uint32(PrepareFlagWithKeyspace), "WithKeyspace"},
+	},
+}
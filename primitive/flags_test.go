@@ -0,0 +1,113 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ FlagSet = HeaderFlag(0)
+	_ FlagSet = QueryFlag(0)
+	_ FlagSet = RowsFlag(0)
+	_ FlagSet = VariablesFlag(0)
+	_ FlagSet = PrepareFlag(0)
+)
+
+func TestFlagSet_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    FlagSet
+		expected string
+	}{
+		{"header single", HeaderFlagTracing, "HeaderFlag Tracing [0x02 0b00000010]"},
+		{"header multiple", HeaderFlagCompressed | HeaderFlagWarning, "HeaderFlag Compressed|Warning [0x09 0b00001001]"},
+		{"header none", HeaderFlag(0), "HeaderFlag None [0x00 0b00000000]"},
+		{"header unknown", HeaderFlag(0x80), "HeaderFlag ? [0x80 0b10000000]"},
+		{"query single", QueryFlagValues, "QueryFlag Values [0x00000001 0b00000000000000000000000000000001]"},
+		{"query multiple", QueryFlagValues | QueryFlagDseWithContinuousPagingOptions, "QueryFlag Values|DseWithContinuousPagingOptions [0x80000001 0b10000000000000000000000000000001]"},
+		{"rows partially unknown", RowsFlagNoMetadata | RowsFlag(0x10), "RowsFlag NoMetadata|? [0x00000014 0b00000000000000000000000000010100]"},
+		{"variables single", VariablesFlagGlobalTablesSpec, "VariablesFlag GlobalTablesSpec [0x00000001 0b00000000000000000000000000000001]"},
+		{"prepare none", PrepareFlag(0), "PrepareFlag None [0x00000000 0b00000000000000000000000000000000]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.input.String())
+		})
+	}
+}
+
+func TestFlagSet_Names(t *testing.T) {
+	assert.Nil(t, QueryFlag(0).Names())
+	assert.Equal(t, []string{"Values", "PageSize"}, (QueryFlagPageSize | QueryFlagValues).Names())
+	assert.Equal(t, []string{"HasMorePages", "0x00000030"}, (RowsFlagHasMorePages | RowsFlag(0x30)).Names())
+	assert.Equal(t, []string{"0x20"}, HeaderFlag(0x20).Names())
+	assert.Equal(t, uint32(0x0A), (HeaderFlagTracing | HeaderFlagWarning).Bits())
+}
+
+func TestFlagSet_HasAndContains(t *testing.T) {
+	flags := QueryFlagValues.Add(QueryFlagPageSize)
+	assert.True(t, flags.Has(QueryFlagValues))
+	assert.True(t, flags.Has(QueryFlagValues|QueryFlagPageSize))
+	assert.False(t, flags.Has(QueryFlagValues|QueryFlagPagingState))
+	assert.True(t, flags.Contains(QueryFlagValues|QueryFlagPagingState))
+	assert.False(t, flags.Remove(QueryFlagValues).Contains(QueryFlagValues))
+}
+
+func TestFlagSet_JSON(t *testing.T) {
+	type dump struct {
+		Header HeaderFlag `json:"header"`
+		Query  QueryFlag  `json:"query"`
+		Rows   RowsFlag   `json:"rows"`
+	}
+	input := dump{
+		Header: HeaderFlagTracing | HeaderFlagUseBeta,
+		Query:  QueryFlag(0),
+		Rows:   RowsFlagNoMetadata | RowsFlag(0x100),
+	}
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	assert.Equal(t, `{"header":["Tracing","UseBeta"],"query":[],"rows":["NoMetadata","0x00000100"]}`, string(data))
+	var decoded dump
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, input, decoded)
+	// numbers are accepted too
+	require.NoError(t, json.Unmarshal([]byte(`{"header":3,"query":null,"rows":6}`), &decoded))
+	assert.Equal(t, dump{Header: HeaderFlagCompressed | HeaderFlagTracing, Rows: RowsFlagHasMorePages | RowsFlagNoMetadata}, decoded)
+}
+
+func TestFlagSet_UnmarshalJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		dest     json.Unmarshaler
+		expected string
+	}{
+		{"unknown name", `["Foo"]`, new(QueryFlag), "cannot unmarshal QueryFlag: unknown flag: Foo"},
+		{"wrong type", `"Values"`, new(QueryFlag), `cannot unmarshal QueryFlag: expected array of flag names or number, got: "Values"`},
+		{"number out of range", `256`, new(HeaderFlag), "cannot unmarshal HeaderFlag: value out of range: 0x100"},
+		{"hex out of range", `["0x100"]`, new(HeaderFlag), "cannot unmarshal HeaderFlag: value out of range: 0x100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dest.UnmarshalJSON([]byte(tt.input))
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
@@ -15,10 +15,12 @@
 package primitive
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 )
 
 // [inet] (net.IP + port)
@@ -30,8 +32,64 @@ type Inet struct {
 	Port int32
 }
 
+// String returns the "host:port" representation of this inet; IPv6 addresses are enclosed in square brackets, as in
+// "[::1]:9042". The result can be parsed back with ParseInet.
 func (i Inet) String() string {
-	return fmt.Sprintf("%v:%v", i.Addr, i.Port)
+	return net.JoinHostPort(i.Addr.String(), strconv.Itoa(int(i.Port)))
+}
+
+// Equal returns true if both inets have the same port and equal addresses, as per net.IP.Equal: an IPv4 address and
+// its IPv4-mapped IPv6 form are considered equal.
+func (i Inet) Equal(other Inet) bool {
+	return i.Port == other.Port && i.Addr.Equal(other.Addr)
+}
+
+// Normalize returns a copy of this inet with its address normalized, see NormalizeInetAddr.
+func (i Inet) Normalize() Inet {
+	return Inet{Addr: NormalizeInetAddr(i.Addr), Port: i.Port}
+}
+
+// MarshalJSON marshals this inet as a "host:port" string, see String.
+func (i Inet) MarshalJSON() ([]byte, error) {
+	if i.Addr == nil {
+		return nil, errors.New("cannot marshal [inet] with nil address")
+	}
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON unmarshals this inet from a "host:port" string, see ParseInet.
+func (i *Inet) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("cannot unmarshal [inet]: %w", err)
+	} else if s == nil {
+		return nil
+	}
+	inet, err := ParseInet(*s)
+	if err != nil {
+		return err
+	}
+	*i = *inet
+	return nil
+}
+
+// ParseInet parses the given "host:port" string, where host is an IPv4 or IPv6 address literal; IPv6 addresses must
+// be enclosed in square brackets, as in "[::1]:9042". Host names are not resolved. The returned address is
+// normalized, see NormalizeInetAddr.
+func ParseInet(s string) (*Inet, error) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse [inet] %q: %w", s, err)
+	}
+	addr := net.ParseIP(host)
+	if addr == nil {
+		return nil, fmt.Errorf("cannot parse [inet] %q: invalid IP address: %v", s, host)
+	}
+	portNumber, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse [inet] %q: invalid port number: %v", s, port)
+	}
+	return &Inet{Addr: NormalizeInetAddr(addr), Port: int32(portNumber)}, nil
 }
 
 func ReadInet(source io.Reader) (*Inet, error) {
@@ -44,6 +102,17 @@ func ReadInet(source io.Reader) (*Inet, error) {
 	}
 }
 
+// ReadInetLenient is like ReadInet, but reads the address with ReadInetAddrLenient.
+func ReadInetLenient(source io.Reader) (*Inet, error) {
+	if addr, err := ReadInetAddrLenient(source); err != nil {
+		return nil, fmt.Errorf("cannot read [inet] address: %w", err)
+	} else if port, err := ReadInt(source); err != nil {
+		return nil, fmt.Errorf("cannot read [inet] port number: %w", err)
+	} else {
+		return &Inet{Addr: addr, Port: port}, nil
+	}
+}
+
 func WriteInet(inet *Inet, dest io.Writer) error {
 	if inet == nil {
 		return errors.New("cannot write nil [inet]")
@@ -45,6 +45,47 @@ func ReadInetAddr(source io.Reader) (net.IP, error) {
 	}
 }
 
+// ReadInetAddrLenient is like ReadInetAddr, but additionally recognizes the nonstandard 16-byte payloads that some
+// servers emit for IPv4 addresses: both IPv4-mapped (::ffff:a.b.c.d) and deprecated IPv4-compatible (::a.b.c.d)
+// addresses are decoded as IPv4 addresses. The unspecified (::) and loopback (::1) IPv6 addresses are left untouched.
+// The returned address is normalized, see NormalizeInetAddr.
+func ReadInetAddrLenient(source io.Reader) (net.IP, error) {
+	addr, err := ReadInetAddr(source)
+	if err != nil {
+		return nil, err
+	}
+	if isIPv4Compatible(addr) {
+		return net.IPv4(addr[12], addr[13], addr[14], addr[15]).To4(), nil
+	}
+	return NormalizeInetAddr(addr), nil
+}
+
+// NormalizeInetAddr returns the canonical form of the given address: a 4-byte slice for IPv4 addresses, including
+// IPv4-mapped IPv6 addresses, and a 16-byte slice for all other IPv6 addresses. Normalized addresses can be compared
+// with bytes.Equal or used as map keys. Nil and malformed addresses are returned unchanged.
+func NormalizeInetAddr(addr net.IP) net.IP {
+	if addr4 := addr.To4(); addr4 != nil {
+		return addr4
+	} else if addr16 := addr.To16(); addr16 != nil {
+		return addr16
+	}
+	return addr
+}
+
+// isIPv4Compatible returns true if the given address is a 16-byte address in the deprecated ::a.b.c.d form, excluding
+// addresses in ::/104 such as :: and ::1.
+func isIPv4Compatible(addr net.IP) bool {
+	if len(addr) != net.IPv6len {
+		return false
+	}
+	for _, b := range addr[:12] {
+		if b != 0 {
+			return false
+		}
+	}
+	return addr[12] != 0
+}
+
 func WriteInetAddr(inetAddr net.IP, dest io.Writer) error {
 	if inetAddr == nil {
 		return errors.New("cannot write nil [inetaddr]")
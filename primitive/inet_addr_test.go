@@ -153,3 +153,48 @@ func TestLengthOfInetAddr(t *testing.T) {
 		})
 	}
 }
+
+func TestReadInetAddrLenient(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   []byte
+		expected net.IP
+	}{
+		{"IPv4", inetAddr4Bytes, net.IP{192, 168, 1, 1}},
+		{"IPv6", inetAddr6Bytes, inetAddr6},
+		{"IPv4-mapped", []byte{16, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 192, 168, 1, 1}, net.IP{192, 168, 1, 1}},
+		{"IPv4-compatible", []byte{16, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 1, 1}, net.IP{192, 168, 1, 1}},
+		{"unspecified", []byte{16, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, net.IPv6unspecified},
+		{"loopback", []byte{16, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}, net.IPv6loopback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ReadInetAddrLenient(bytes.NewBuffer(tt.source))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+	t.Run("error", func(t *testing.T) {
+		_, err := ReadInetAddrLenient(bytes.NewBuffer([]byte{4, 192, 168}))
+		assert.Equal(t, fmt.Errorf("cannot read [inetaddr] IPv4 content: %w", errors.New("unexpected EOF")), err)
+	})
+}
+
+func TestNormalizeInetAddr(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    net.IP
+		expected net.IP
+	}{
+		{"nil", nil, nil},
+		{"IPv4 compact", net.IP{192, 168, 1, 1}, net.IP{192, 168, 1, 1}},
+		{"IPv4 mapped", net.IPv4(192, 168, 1, 1), net.IP{192, 168, 1, 1}},
+		{"IPv6", inetAddr6, inetAddr6},
+		{"malformed", net.IP{1, 2, 3}, net.IP{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeInetAddr(tt.input))
+		})
+	}
+}
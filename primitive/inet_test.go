@@ -16,6 +16,7 @@ package primitive
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
@@ -175,3 +176,75 @@ func TestLengthOfInet(t *testing.T) {
 		})
 	}
 }
+
+func TestReadInetLenient(t *testing.T) {
+	source := []byte{16, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 192, 168, 1, 1, 0, 0, 0x23, 0x52}
+	actual, err := ReadInetLenient(bytes.NewBuffer(source))
+	assert.NoError(t, err)
+	assert.Equal(t, &Inet{Addr: net.IP{192, 168, 1, 1}, Port: 9042}, actual)
+}
+
+func TestInet_String(t *testing.T) {
+	assert.Equal(t, "192.168.1.1:9042", inet4.String())
+	assert.Equal(t, "[2001:db8:85a3::8a2e:370:7334]:9042", inet6.String())
+}
+
+func TestInet_Equal(t *testing.T) {
+	assert.True(t, inet4.Equal(Inet{Addr: net.IP{192, 168, 1, 1}, Port: 9042}))
+	assert.True(t, inet6.Equal(inet6))
+	assert.False(t, inet4.Equal(Inet{Addr: net.IP{192, 168, 1, 1}, Port: 9043}))
+	assert.False(t, inet4.Equal(inet6))
+	assert.True(t, Inet{}.Equal(Inet{}))
+}
+
+func TestInet_Normalize(t *testing.T) {
+	assert.Equal(t, Inet{Addr: net.IP{192, 168, 1, 1}, Port: 9042}, inet4.Normalize())
+	assert.Equal(t, inet6, inet6.Normalize())
+}
+
+func TestParseInet(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *Inet
+		err      string
+	}{
+		{"IPv4", "192.168.1.1:9042", &Inet{Addr: net.IP{192, 168, 1, 1}, Port: 9042}, ""},
+		{"IPv6", "[2001:db8:85a3::8a2e:370:7334]:9042", &inet6, ""},
+		{"IPv4-mapped", "[::ffff:192.168.1.1]:9042", &Inet{Addr: net.IP{192, 168, 1, 1}, Port: 9042}, ""},
+		{"missing port", "192.168.1.1", nil, `cannot parse [inet] "192.168.1.1": address 192.168.1.1: missing port in address`},
+		{"unbracketed IPv6", "::1:9042", nil, `cannot parse [inet] "::1:9042": address ::1:9042: too many colons in address`},
+		{"host name", "localhost:9042", nil, `cannot parse [inet] "localhost:9042": invalid IP address: localhost`},
+		{"port out of range", "192.168.1.1:65536", nil, `cannot parse [inet] "192.168.1.1:65536": invalid port number: 65536`},
+		{"negative port", "192.168.1.1:-1", nil, `cannot parse [inet] "192.168.1.1:-1": invalid port number: -1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ParseInet(tt.input)
+			assert.Equal(t, tt.expected, actual)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestInet_JSON(t *testing.T) {
+	type wrapper struct {
+		Address  *Inet
+		Optional *Inet
+	}
+	source := wrapper{Address: &inet6}
+	marshaled, err := json.Marshal(source)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Address":"[2001:db8:85a3::8a2e:370:7334]:9042","Optional":null}`, string(marshaled))
+	var unmarshaled wrapper
+	assert.NoError(t, json.Unmarshal(marshaled, &unmarshaled))
+	assert.Equal(t, source, unmarshaled)
+	_, err = json.Marshal(Inet{})
+	assert.Error(t, err)
+	assert.Error(t, json.Unmarshal([]byte(`{"Address":"192.168.1.1"}`), &unmarshaled))
+	assert.Error(t, json.Unmarshal([]byte(`{"Address":9042}`), &unmarshaled))
+}
@@ -37,6 +37,9 @@ func ReadByte(source io.Reader) (decoded uint8, err error) {
 }
 
 func WriteByte(b uint8, dest io.Writer) error {
+	if buf, ok := dest.(*WriteBuffer); ok {
+		return buf.WriteByte(b)
+	}
 	if err := binary.Write(dest, binary.BigEndian, b); err != nil {
 		return fmt.Errorf("cannot write [byte]: %w", err)
 	}
@@ -53,6 +56,10 @@ func ReadShort(source io.Reader) (decoded uint16, err error) {
 }
 
 func WriteShort(i uint16, dest io.Writer) error {
+	if buf, ok := dest.(*WriteBuffer); ok {
+		buf.appendShort(i)
+		return nil
+	}
 	if err := binary.Write(dest, binary.BigEndian, i); err != nil {
 		return fmt.Errorf("cannot write [short]: %w", err)
 	}
@@ -69,6 +76,10 @@ func ReadInt(source io.Reader) (decoded int32, err error) {
 }
 
 func WriteInt(i int32, dest io.Writer) error {
+	if buf, ok := dest.(*WriteBuffer); ok {
+		buf.appendInt(i)
+		return nil
+	}
 	if err := binary.Write(dest, binary.BigEndian, i); err != nil {
 		return fmt.Errorf("cannot write [int]: %w", err)
 	}
@@ -85,6 +96,10 @@ func ReadLong(source io.Reader) (decoded int64, err error) {
 }
 
 func WriteLong(l int64, dest io.Writer) error {
+	if buf, ok := dest.(*WriteBuffer); ok {
+		buf.appendLong(l)
+		return nil
+	}
 	if err := binary.Write(dest, binary.BigEndian, l); err != nil {
 		return fmt.Errorf("cannot write [long]: %w", err)
 	}
@@ -0,0 +1,107 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"fmt"
+	"io"
+)
+
+// DecodeLimits are upper bounds on the lengths read from untrusted sources. When a length exceeds its limit, decoding
+// fails with a *LimitExceededError before anything is allocated for the contents. Zero means no limit.
+//
+// Limits are enforced by the read functions of this package, and by message decoders, when their source was wrapped
+// with WithDecodeLimits.
+type DecodeLimits struct {
+	// MaxStringLength is the maximum length, in bytes, of [string] and [long string] contents.
+	MaxStringLength int
+	// MaxBytesLength is the maximum length of [bytes], [short bytes] and [value] contents.
+	MaxBytesLength int
+	// MaxCollectionLength is the maximum number of elements of [string list], [string map], [string multimap],
+	// [bytes map], positional and named [value]s and reason maps, of BATCH children, and of rows and columns in
+	// results.
+	MaxCollectionLength int
+}
+
+// LimitExceededError is returned when a length read from the input exceeds the corresponding limit.
+type LimitExceededError struct {
+	// Kind describes what exceeded its limit, e.g. "[string]" or "frame body".
+	Kind string
+	// Length is the length that was read.
+	Length int64
+	// Limit is the maximum length allowed.
+	Limit int64
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%v length %d exceeds limit of %d", e.Kind, e.Length, e.Limit)
+}
+
+// limitedSource is a source carrying DecodeLimits, see WithDecodeLimits.
+type limitedSource struct {
+	io.Reader
+	limits DecodeLimits
+}
+
+// WithDecodeLimits returns a source reading from the given source, and enforcing the given limits when passed to the
+// read functions of this package and to message decoders. The source is returned unchanged if all limits are zero.
+func WithDecodeLimits(source io.Reader, limits DecodeLimits) io.Reader {
+	if limits == (DecodeLimits{}) {
+		return source
+	} else if s, ok := source.(*limitedSource); ok {
+		return &limitedSource{Reader: s.Reader, limits: limits}
+	}
+	return &limitedSource{Reader: source, limits: limits}
+}
+
+// DecodeLimitsOf returns the limits enforced by the given source, see WithDecodeLimits; all limits are zero if the
+// source does not enforce any. Decoders that wrap their source into another reader should propagate the limits with
+// WithDecodeLimits(wrapped, DecodeLimitsOf(source)).
+func DecodeLimitsOf(source io.Reader) DecodeLimits {
+	if s, ok := source.(*limitedSource); ok {
+		return s.limits
+	}
+	return DecodeLimits{}
+}
+
+// CheckCollectionLength returns a *LimitExceededError if the given number of elements exceeds the MaxCollectionLength
+// limit of the given source; kind describes the collection.
+func CheckCollectionLength(source io.Reader, kind string, length int) error {
+	if s, ok := source.(*limitedSource); ok {
+		return checkLength(kind, length, s.limits.MaxCollectionLength)
+	}
+	return nil
+}
+
+func checkStringLength(source io.Reader, kind string, length int) error {
+	if s, ok := source.(*limitedSource); ok {
+		return checkLength(kind, length, s.limits.MaxStringLength)
+	}
+	return nil
+}
+
+func checkBytesLength(source io.Reader, kind string, length int) error {
+	if s, ok := source.(*limitedSource); ok {
+		return checkLength(kind, length, s.limits.MaxBytesLength)
+	}
+	return nil
+}
+
+func checkLength(kind string, length int, limit int) error {
+	if limit > 0 && length > limit {
+		return &LimitExceededError{Kind: kind, Length: int64(length), Limit: int64(limit)}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDecodeLimits(t *testing.T) {
+	source := bytes.NewReader(nil)
+	assert.Same(t, source, WithDecodeLimits(source, DecodeLimits{}))
+	limits := DecodeLimits{MaxStringLength: 1, MaxBytesLength: 2, MaxCollectionLength: 3}
+	limited := WithDecodeLimits(source, limits)
+	assert.Equal(t, limits, DecodeLimitsOf(limited))
+	assert.Equal(t, DecodeLimits{}, DecodeLimitsOf(source))
+	// wrapping again replaces the limits
+	assert.Equal(t, DecodeLimits{MaxStringLength: 10}, DecodeLimitsOf(WithDecodeLimits(limited, DecodeLimits{MaxStringLength: 10})))
+}
+
+func TestDecodeLimits(t *testing.T) {
+	limits := DecodeLimits{MaxStringLength: 3, MaxBytesLength: 3, MaxCollectionLength: 1}
+	tests := []struct {
+		name     string
+		input    []byte
+		read     func(io.Reader) error
+		expected *LimitExceededError
+	}{
+		{
+			"[string] within limit",
+			[]byte{0, 3, 'a', 'b', 'c'},
+			func(source io.Reader) error { _, err := ReadString(source); return err },
+			nil,
+		},
+		{
+			"[string]",
+			[]byte{0, 4, 'a', 'b', 'c', 'd'},
+			func(source io.Reader) error { _, err := ReadString(source); return err },
+			&LimitExceededError{Kind: "[string]", Length: 4, Limit: 3},
+		},
+		{
+			"[long string]",
+			[]byte{0x7f, 0xff, 0xff, 0xff},
+			func(source io.Reader) error { _, err := ReadLongString(source); return err },
+			&LimitExceededError{Kind: "[long string]", Length: 1<<31 - 1, Limit: 3},
+		},
+		{
+			"[bytes]",
+			[]byte{0x7f, 0xff, 0xff, 0xff},
+			func(source io.Reader) error { _, err := ReadBytes(source); return err },
+			&LimitExceededError{Kind: "[bytes]", Length: 1<<31 - 1, Limit: 3},
+		},
+		{
+			"[short bytes]",
+			[]byte{0xff, 0xff},
+			func(source io.Reader) error { _, err := ReadShortBytes(source); return err },
+			&LimitExceededError{Kind: "[short bytes]", Length: 65535, Limit: 3},
+		},
+		{
+			"[value]",
+			[]byte{0, 0, 0, 4},
+			func(source io.Reader) error { _, err := ReadValue(source, ProtocolVersion4); return err },
+			&LimitExceededError{Kind: "[value]", Length: 4, Limit: 3},
+		},
+		{
+			"[string list]",
+			[]byte{0, 2},
+			func(source io.Reader) error { _, err := ReadStringList(source); return err },
+			&LimitExceededError{Kind: "[string list]", Length: 2, Limit: 1},
+		},
+		{
+			"[string map]",
+			[]byte{0, 2},
+			func(source io.Reader) error { _, err := ReadStringMap(source); return err },
+			&LimitExceededError{Kind: "[string map]", Length: 2, Limit: 1},
+		},
+		{
+			"[string multimap] element",
+			[]byte{0, 1, 0, 1, 'k', 0, 2},
+			func(source io.Reader) error { _, err := ReadStringMultiMap(source); return err },
+			&LimitExceededError{Kind: "[string list]", Length: 2, Limit: 1},
+		},
+		{
+			"[bytes map]",
+			[]byte{0, 2},
+			func(source io.Reader) error { _, err := ReadBytesMap(source); return err },
+			&LimitExceededError{Kind: "[bytes map]", Length: 2, Limit: 1},
+		},
+		{
+			"positional [value]s",
+			[]byte{0, 2},
+			func(source io.Reader) error { _, err := ReadPositionalValues(source, ProtocolVersion4); return err },
+			&LimitExceededError{Kind: "positional [value]s", Length: 2, Limit: 1},
+		},
+		{
+			"named [value]s",
+			[]byte{0, 2},
+			func(source io.Reader) error { _, err := ReadNamedValues(source, ProtocolVersion4); return err },
+			&LimitExceededError{Kind: "named [value]s", Length: 2, Limit: 1},
+		},
+		{
+			"reason map",
+			[]byte{0, 0, 0, 2},
+			func(source io.Reader) error { _, err := ReadReasonMap(source); return err },
+			&LimitExceededError{Kind: "reason map", Length: 2, Limit: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.read(WithDecodeLimits(bytes.NewReader(tt.input), limits))
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				var limitErr *LimitExceededError
+				require.True(t, errors.As(err, &limitErr), err)
+				assert.Equal(t, tt.expected, limitErr)
+				// without limits, decoding proceeds and fails on the truncated input instead
+				err = tt.read(bytes.NewReader(tt.input))
+				assert.False(t, errors.As(err, &limitErr))
+			}
+		})
+	}
+}
@@ -27,9 +27,11 @@ func ReadLongString(source io.Reader) (string, error) {
 		return "", fmt.Errorf("cannot read [long string] length: %w", err)
 	} else if length <= 0 {
 		return "", nil
+	} else if err := checkStringLength(source, "[long string]", int(length)); err != nil {
+		return "", err
 	} else {
-		decoded := make([]byte, length)
-		if _, err := io.ReadFull(source, decoded); err != nil {
+		decoded, err := readFull(source, int(length))
+		if err != nil {
 			return "", fmt.Errorf("cannot read [long string] content: %w", err)
 		}
 		return string(decoded), nil
@@ -40,7 +42,7 @@ func WriteLongString(s string, dest io.Writer) error {
 	length := len(s)
 	if err := WriteInt(int32(length), dest); err != nil {
 		return fmt.Errorf("cannot write [long string] length: %w", err)
-	} else if n, err := dest.Write([]byte(s)); err != nil {
+	} else if n, err := io.WriteString(dest, s); err != nil {
 		return fmt.Errorf("cannot write [long string] length: %w", err)
 	} else if n < length {
 		return errors.New("not enough capacity to write [long string] content")
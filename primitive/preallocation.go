@@ -0,0 +1,58 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"bytes"
+	"io"
+)
+
+// MaxPreallocatedLength is the maximum number of bytes, or of elements, that decoders preallocate based on a length
+// read from their input. Length fields come from untrusted sources: a hostile or corrupted input could otherwise
+// trigger huge allocations with a few bytes. Contents longer than this are still decoded, but their storage grows as
+// they are actually read, so that the memory allocated is bounded by the size of the input.
+const MaxPreallocatedLength = 64 * 1024
+
+// PreallocatedLength returns the capacity to preallocate for the given number of bytes or elements, as read from an
+// untrusted length field: the length itself if it does not exceed MaxPreallocatedLength, MaxPreallocatedLength if it
+// does, and zero if it is negative.
+func PreallocatedLength(length int) int {
+	if length < 0 {
+		return 0
+	} else if length > MaxPreallocatedLength {
+		return MaxPreallocatedLength
+	}
+	return length
+}
+
+// readFull reads exactly length bytes from source, like io.ReadFull does, but without preallocating more than
+// MaxPreallocatedLength bytes upfront.
+func readFull(source io.Reader, length int) ([]byte, error) {
+	if length <= MaxPreallocatedLength {
+		decoded := make([]byte, length)
+		if _, err := io.ReadFull(source, decoded); err != nil {
+			return nil, err
+		}
+		return decoded, nil
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, MaxPreallocatedLength))
+	if n, err := io.CopyN(buf, source, int64(length)); err != nil {
+		if err == io.EOF && n > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
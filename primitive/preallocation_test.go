@@ -0,0 +1,56 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreallocatedLength(t *testing.T) {
+	assert.Equal(t, 0, PreallocatedLength(-1))
+	assert.Equal(t, 0, PreallocatedLength(0))
+	assert.Equal(t, 123, PreallocatedLength(123))
+	assert.Equal(t, MaxPreallocatedLength, PreallocatedLength(MaxPreallocatedLength))
+	assert.Equal(t, MaxPreallocatedLength, PreallocatedLength(1<<31-1))
+}
+
+func TestReadFull(t *testing.T) {
+	large := bytes.Repeat([]byte{0xca}, MaxPreallocatedLength*2+1)
+	tests := []struct {
+		name     string
+		source   []byte
+		length   int
+		expected []byte
+		err      error
+	}{
+		{"small", []byte{1, 2, 3}, 3, []byte{1, 2, 3}, nil},
+		{"small truncated", []byte{1, 2}, 3, nil, io.ErrUnexpectedEOF},
+		{"small empty", []byte{}, 3, nil, io.EOF},
+		{"large", large, len(large), large, nil},
+		{"large truncated", large, 1<<31 - 1, nil, io.ErrUnexpectedEOF},
+		{"large empty", []byte{}, 1<<31 - 1, nil, io.EOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := readFull(bytes.NewReader(tt.source), tt.length)
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// readBuffer is a scratch buffer for reading fixed-size integers and [string] contents without going through
+// encoding/binary, and without allocating an intermediate byte slice for each string: string contents are read into
+// the scratch buffer, then copied once into the resulting string. Composite readers such as ReadStringMultiMap and
+// ReadBytesMap acquire a single readBuffer for all their entries. A readBuffer is not safe for concurrent use.
+type readBuffer struct {
+	buf []byte
+}
+
+// [string] contents are at most 65535 bytes long, so pooled buffers never grow beyond this capacity.
+const maxReadBufferCapacity = 64 * 1024
+
+var readBufferPool = sync.Pool{
+	New: func() interface{} {
+		return &readBuffer{buf: make([]byte, 0, 256)}
+	},
+}
+
+func acquireReadBuffer() *readBuffer {
+	return readBufferPool.Get().(*readBuffer)
+}
+
+func releaseReadBuffer(b *readBuffer) {
+	if cap(b.buf) <= maxReadBufferCapacity {
+		readBufferPool.Put(b)
+	}
+}
+
+// next returns a slice of the scratch buffer of the given length, growing it if necessary. The returned slice is only
+// valid until the next call to next.
+func (b *readBuffer) next(length int) []byte {
+	if cap(b.buf) < length {
+		b.buf = make([]byte, length)
+	}
+	return b.buf[:length]
+}
+
+func (b *readBuffer) readShort(source io.Reader) (uint16, error) {
+	buf := b.next(LengthOfShort)
+	if _, err := io.ReadFull(source, buf); err != nil {
+		return 0, fmt.Errorf("cannot read [short]: %w", err)
+	}
+	return binary.BigEndian.Uint16(buf), nil
+}
+
+func (b *readBuffer) readInt(source io.Reader) (int32, error) {
+	buf := b.next(LengthOfInt)
+	if _, err := io.ReadFull(source, buf); err != nil {
+		return 0, fmt.Errorf("cannot read [int]: %w", err)
+	}
+	return int32(binary.BigEndian.Uint32(buf)), nil
+}
+
+func (b *readBuffer) readString(source io.Reader) (string, error) {
+	if length, err := b.readShort(source); err != nil {
+		return "", fmt.Errorf("cannot read [string] length: %w", err)
+	} else if length == 0 {
+		return "", nil
+	} else if err := checkStringLength(source, "[string]", int(length)); err != nil {
+		return "", err
+	} else {
+		buf := b.next(int(length))
+		if _, err := io.ReadFull(source, buf); err != nil {
+			return "", fmt.Errorf("cannot read [string] content: %w", err)
+		}
+		return string(buf), nil
+	}
+}
+
+func (b *readBuffer) readStringList(source io.Reader) ([]string, error) {
+	length, err := b.readShort(source)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read [string list] length: %w", err)
+	} else if length == 0 {
+		return []string{}, nil
+	} else if err := CheckCollectionLength(source, "[string list]", int(length)); err != nil {
+		return nil, err
+	}
+	decoded := make([]string, length)
+	for i := uint16(0); i < length; i++ {
+		if decoded[i], err = b.readString(source); err != nil {
+			return nil, fmt.Errorf("cannot read [string list] element %d: %w", i, err)
+		}
+	}
+	return decoded, nil
+}
+
+func (b *readBuffer) readBytes(source io.Reader) ([]byte, error) {
+	if length, err := b.readInt(source); err != nil {
+		return nil, fmt.Errorf("cannot read [bytes] length: %w", err)
+	} else if length < 0 {
+		return nil, nil
+	} else if length == 0 {
+		return []byte{}, nil
+	} else if err := checkBytesLength(source, "[bytes]", int(length)); err != nil {
+		return nil, err
+	} else {
+		// the decoded bytes are returned to the caller, so they cannot be read into the scratch buffer
+		decoded, err := readFull(source, int(length))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read [bytes] content: %w", err)
+		}
+		return decoded, nil
+	}
+}
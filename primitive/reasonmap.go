@@ -37,8 +37,12 @@ type FailureReason struct {
 func ReadReasonMap(source io.Reader) ([]*FailureReason, error) {
 	if length, err := ReadInt(source); err != nil {
 		return nil, fmt.Errorf("cannot read reason map length: %w", err)
+	} else if length < 0 {
+		return nil, fmt.Errorf("invalid reason map length: %d", length)
+	} else if err := CheckCollectionLength(source, "reason map", int(length)); err != nil {
+		return nil, err
 	} else {
-		reasonMap := make([]*FailureReason, length)
+		reasonMap := make([]*FailureReason, 0, PreallocatedLength(int(length)))
 		for i := 0; i < int(length); i++ {
 			if addr, err := ReadInetAddr(source); err != nil {
 				return nil, fmt.Errorf("cannot read reason map key for element %d: %w", i, err)
@@ -47,7 +51,7 @@ func ReadReasonMap(source io.Reader) ([]*FailureReason, error) {
 			} else if err := CheckValidFailureCode(FailureCode(code)); err != nil {
 				return nil, err
 			} else {
-				reasonMap[i] = &FailureReason{addr, FailureCode(code)}
+				reasonMap = append(reasonMap, &FailureReason{addr, FailureCode(code)})
 			}
 		}
 		return reasonMap, err
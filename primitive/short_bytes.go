@@ -29,6 +29,8 @@ func ReadShortBytes(source io.Reader) ([]byte, error) {
 		return nil, nil
 	} else if length == 0 {
 		return []byte{}, nil
+	} else if err := checkBytesLength(source, "[short bytes]", int(length)); err != nil {
+		return nil, err
 	} else {
 		decoded := make([]byte, length)
 		if _, err := io.ReadFull(source, decoded); err != nil {
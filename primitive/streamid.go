@@ -37,9 +37,65 @@ func ReadStreamId(source io.Reader, version ProtocolVersion) (int16, error) {
 func WriteStreamId(streamId int16, dest io.Writer, version ProtocolVersion) error {
 	if version >= ProtocolVersion3 {
 		return WriteShort(uint16(streamId), dest)
-	} else if streamId > math.MaxInt8 || streamId < math.MinInt8 {
-		return fmt.Errorf("stream id out of range for %v: %v", version, streamId)
+	} else if err := StreamId(streamId).Validate(version); err != nil {
+		return err
 	} else {
 		return WriteByte(uint8(streamId), dest)
 	}
 }
+
+// StreamId is the stream id of a frame. Clients send requests with non-negative stream ids, and servers reply on the
+// stream id of each request; negative stream ids are reserved for frames initiated by servers, i.e. EVENT responses,
+// which are sent with EventStreamId. Frame headers hold stream ids as plain int16 values; this type provides
+// validation helpers for them.
+type StreamId int16
+
+// EventStreamId is the stream id of the frames initiated by servers, i.e. EVENT responses.
+const EventStreamId StreamId = -1
+
+// MinStreamId returns the lowest stream id that can be encoded in the given protocol version: -128 in protocol
+// versions 1 and 2, where stream ids are 1-byte signed integers, and -32768 in protocol version 3 and higher.
+func MinStreamId(version ProtocolVersion) StreamId {
+	if version < ProtocolVersion3 {
+		return math.MinInt8
+	}
+	return math.MinInt16
+}
+
+// MaxStreamId returns the highest stream id that can be encoded in the given protocol version: 127 in protocol
+// versions 1 and 2, where stream ids are 1-byte signed integers, and 32767 in protocol version 3 and higher.
+func MaxStreamId(version ProtocolVersion) StreamId {
+	if version < ProtocolVersion3 {
+		return math.MaxInt8
+	}
+	return math.MaxInt16
+}
+
+// IsEvent returns true if this is EventStreamId.
+func (s StreamId) IsEvent() bool {
+	return s == EventStreamId
+}
+
+// IsReserved returns true if this stream id is reserved for server-initiated frames, i.e. if it is negative.
+func (s StreamId) IsReserved() bool {
+	return s < 0
+}
+
+// Validate returns an error if this stream id cannot be encoded in the given protocol version.
+func (s StreamId) Validate(version ProtocolVersion) error {
+	if s < MinStreamId(version) || s > MaxStreamId(version) {
+		return fmt.Errorf("stream id out of range for %v: %v", version, int16(s))
+	}
+	return nil
+}
+
+// ValidateRequest returns an error if this stream id cannot be used by a client request in the given protocol version,
+// i.e. if it cannot be encoded, or if it is reserved for server-initiated frames.
+func (s StreamId) ValidateRequest(version ProtocolVersion) error {
+	if err := s.Validate(version); err != nil {
+		return err
+	} else if s.IsReserved() {
+		return fmt.Errorf("stream id reserved for server-initiated frames: %v", int16(s))
+	}
+	return nil
+}
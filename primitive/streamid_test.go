@@ -188,3 +188,52 @@ func TestWriteStreamId(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamId_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		streamId   StreamId
+		version    ProtocolVersion
+		err        string
+		requestErr string
+	}{
+		{"v2 zero", 0, ProtocolVersion2, "", ""},
+		{"v2 max", 127, ProtocolVersion2, "", ""},
+		{"v2 event", EventStreamId, ProtocolVersion2, "", "stream id reserved for server-initiated frames: -1"},
+		{"v2 min", -128, ProtocolVersion2, "", "stream id reserved for server-initiated frames: -128"},
+		{"v2 too high", 128, ProtocolVersion2, "stream id out of range for ProtocolVersion OSS 2: 128",
+			"stream id out of range for ProtocolVersion OSS 2: 128"},
+		{"v2 too low", -129, ProtocolVersion2, "stream id out of range for ProtocolVersion OSS 2: -129",
+			"stream id out of range for ProtocolVersion OSS 2: -129"},
+		{"v4 max", math.MaxInt16, ProtocolVersion4, "", ""},
+		{"v4 event", EventStreamId, ProtocolVersion4, "", "stream id reserved for server-initiated frames: -1"},
+		{"v4 min", math.MinInt16, ProtocolVersion4, "", "stream id reserved for server-initiated frames: -32768"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.streamId.Validate(tt.version); tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+			if err := tt.streamId.ValidateRequest(tt.version); tt.requestErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.requestErr)
+			}
+		})
+	}
+}
+
+func TestStreamId_Reserved(t *testing.T) {
+	assert.True(t, EventStreamId.IsEvent())
+	assert.True(t, EventStreamId.IsReserved())
+	assert.False(t, StreamId(-2).IsEvent())
+	assert.True(t, StreamId(-2).IsReserved())
+	assert.False(t, StreamId(0).IsEvent())
+	assert.False(t, StreamId(0).IsReserved())
+	assert.Equal(t, StreamId(math.MinInt8), MinStreamId(ProtocolVersion2))
+	assert.Equal(t, StreamId(math.MaxInt8), MaxStreamId(ProtocolVersion2))
+	assert.Equal(t, StreamId(math.MinInt16), MinStreamId(ProtocolVersion3))
+	assert.Equal(t, StreamId(math.MaxInt16), MaxStreamId(ProtocolVersion5))
+}
@@ -23,24 +23,16 @@ import (
 // [string]
 
 func ReadString(source io.Reader) (string, error) {
-	if length, err := ReadShort(source); err != nil {
-		return "", fmt.Errorf("cannot read [string] length: %w", err)
-	} else if length <= 0 {
-		return "", nil
-	} else {
-		decoded := make([]byte, length)
-		if _, err := io.ReadFull(source, decoded); err != nil {
-			return "", fmt.Errorf("cannot read [string] content: %w", err)
-		}
-		return string(decoded), nil
-	}
+	buf := acquireReadBuffer()
+	defer releaseReadBuffer(buf)
+	return buf.readString(source)
 }
 
 func WriteString(s string, dest io.Writer) error {
 	length := len(s)
 	if err := WriteShort(uint16(length), dest); err != nil {
 		return fmt.Errorf("cannot write [string] length: %w", err)
-	} else if n, err := dest.Write([]byte(s)); err != nil {
+	} else if n, err := io.WriteString(dest, s); err != nil {
 		return fmt.Errorf("cannot write [string] length: %w", err)
 	} else if n < length {
 		return errors.New("not enough capacity to write [string] content")
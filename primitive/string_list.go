@@ -22,28 +22,9 @@ import (
 // [string list]
 
 func ReadStringList(source io.Reader) (decoded []string, err error) {
-	var length uint16
-	length, err = ReadShort(source)
-	if err != nil {
-		return nil, fmt.Errorf("cannot read [string list] length: %w", err)
-	}
-
-	if length < 0 {
-		return nil, nil
-	} else if length == 0 {
-		return []string{}, nil
-	}
-
-	decoded = make([]string, length)
-	for i := uint16(0); i < length; i++ {
-		var str string
-		str, err = ReadString(source)
-		if err != nil {
-			return nil, fmt.Errorf("cannot read [string list] element %d: %w", i, err)
-		}
-		decoded[i] = str
-	}
-	return decoded, nil
+	buf := acquireReadBuffer()
+	defer releaseReadBuffer(buf)
+	return buf.readStringList(source)
 }
 
 func WriteStringList(list []string, dest io.Writer) error {
@@ -22,14 +22,18 @@ import (
 // [string map]
 
 func ReadStringMap(source io.Reader) (map[string]string, error) {
-	if length, err := ReadShort(source); err != nil {
+	buf := acquireReadBuffer()
+	defer releaseReadBuffer(buf)
+	if length, err := buf.readShort(source); err != nil {
 		return nil, fmt.Errorf("cannot read [string map] length: %w", err)
+	} else if err := CheckCollectionLength(source, "[string map]", int(length)); err != nil {
+		return nil, err
 	} else {
 		decoded := make(map[string]string, length)
 		for i := uint16(0); i < length; i++ {
-			if key, err := ReadString(source); err != nil {
+			if key, err := buf.readString(source); err != nil {
 				return nil, fmt.Errorf("cannot read [string map] entry %d key: %w", i, err)
-			} else if value, err := ReadString(source); err != nil {
+			} else if value, err := buf.readString(source); err != nil {
 				return nil, fmt.Errorf("cannot read [string map] entry %d value: %w", i, err)
 			} else {
 				decoded[key] = value
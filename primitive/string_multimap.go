@@ -17,19 +17,24 @@ package primitive
 import (
 	"fmt"
 	"io"
+	"sort"
 )
 
 // [string multimap]
 
 func ReadStringMultiMap(source io.Reader) (decoded map[string][]string, err error) {
-	if length, err := ReadShort(source); err != nil {
+	buf := acquireReadBuffer()
+	defer releaseReadBuffer(buf)
+	if length, err := buf.readShort(source); err != nil {
 		return nil, fmt.Errorf("cannot read [string multimap] length: %w", err)
+	} else if err := CheckCollectionLength(source, "[string multimap]", int(length)); err != nil {
+		return nil, err
 	} else {
 		decoded := make(map[string][]string, length)
 		for i := uint16(0); i < length; i++ {
-			if key, err := ReadString(source); err != nil {
+			if key, err := buf.readString(source); err != nil {
 				return nil, fmt.Errorf("cannot read [string multimap] entry %d key: %w", i, err)
-			} else if value, err := ReadStringList(source); err != nil {
+			} else if value, err := buf.readStringList(source); err != nil {
 				return nil, fmt.Errorf("cannot read [string multimap] entry %d value: %w", i, err)
 			} else {
 				decoded[key] = value
@@ -61,3 +66,72 @@ func LengthOfStringMultiMap(m map[string][]string) int {
 	}
 	return length
 }
+
+// ReadStringMultiMapOrdered reads a [string multimap] like ReadStringMultiMap, and also returns its keys in the order
+// in which they were read. Since a map cannot hold the values of a key that appears more than once, and since such
+// values would otherwise be silently lost, duplicate keys are rejected with an error.
+func ReadStringMultiMapOrdered(source io.Reader) (decoded map[string][]string, keys []string, err error) {
+	buf := acquireReadBuffer()
+	defer releaseReadBuffer(buf)
+	if length, err := buf.readShort(source); err != nil {
+		return nil, nil, fmt.Errorf("cannot read [string multimap] length: %w", err)
+	} else if err := CheckCollectionLength(source, "[string multimap]", int(length)); err != nil {
+		return nil, nil, err
+	} else {
+		decoded := make(map[string][]string, length)
+		var keys []string
+		if length > 0 {
+			keys = make([]string, 0, length)
+		}
+		for i := uint16(0); i < length; i++ {
+			if key, err := buf.readString(source); err != nil {
+				return nil, nil, fmt.Errorf("cannot read [string multimap] entry %d key: %w", i, err)
+			} else if value, err := buf.readStringList(source); err != nil {
+				return nil, nil, fmt.Errorf("cannot read [string multimap] entry %d value: %w", i, err)
+			} else if _, found := decoded[key]; found {
+				return nil, nil, fmt.Errorf("cannot read [string multimap] entry %d: duplicate key '%v'", i, key)
+			} else {
+				keys = append(keys, key)
+				decoded[key] = value
+			}
+		}
+		return decoded, keys, nil
+	}
+}
+
+// WriteStringMultiMapOrdered writes a [string multimap] with a deterministic entry order: the given keys are written
+// first, in the given order, skipping keys absent from the map; the remaining keys of the map are then written in
+// lexical order.
+func WriteStringMultiMapOrdered(m map[string][]string, keys []string, dest io.Writer) error {
+	if err := WriteShort(uint16(len(m)), dest); err != nil {
+		return fmt.Errorf("cannot write [string multimap] length: %w", err)
+	}
+	for _, key := range orderedKeys(m, keys) {
+		if err := WriteString(key, dest); err != nil {
+			return fmt.Errorf("cannot write [string multimap] entry '%v' key: %w", key, err)
+		}
+		if err := WriteStringList(m[key], dest); err != nil {
+			return fmt.Errorf("cannot write [string multimap] entry '%v' value: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func orderedKeys(m map[string][]string, keys []string) []string {
+	ordered := make([]string, 0, len(m))
+	seen := make(map[string]bool, len(m))
+	for _, key := range keys {
+		if _, found := m[key]; found && !seen[key] {
+			ordered = append(ordered, key)
+			seen[key] = true
+		}
+	}
+	remaining := len(ordered)
+	for key := range m {
+		if !seen[key] {
+			ordered = append(ordered, key)
+		}
+	}
+	sort.Strings(ordered[remaining:])
+	return ordered
+}
@@ -198,3 +198,104 @@ func TestWriteStringMultiMap(t *testing.T) {
 		})
 	}
 }
+
+func TestReadStringMultiMapOrdered(t *testing.T) {
+	source := []byte{
+		0, 3, // map length
+		0, 5, w, o, r, l, d, // key1: world
+		0, 1, // list length
+		0, 5, h, e, l, l, o, // value: hello
+		0, 5, h, e, l, l, o, // key2: hello
+		0, 0, // list length
+		0, 5, m, u, n, d, o, // key3: mundo
+		0, 1, // list length
+		0, 5, w, o, r, l, d, // value: world
+	}
+	decoded, keys, err := ReadStringMultiMapOrdered(bytes.NewReader(source))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"world": {"hello"}, "hello": {}, "mundo": {"world"}}, decoded)
+	assert.Equal(t, []string{"world", "hello", "mundo"}, keys)
+	// round trip preserves the original order
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteStringMultiMapOrdered(decoded, keys, buf))
+	assert.Equal(t, source, buf.Bytes())
+	// duplicate keys are rejected
+	_, _, err = ReadStringMultiMapOrdered(bytes.NewReader([]byte{
+		0, 2, // map length
+		0, 5, h, e, l, l, o, // key: hello
+		0, 1, // list length
+		0, 5, w, o, r, l, d, // value: world
+		0, 5, h, e, l, l, o, // key: hello
+		0, 1, // list length
+		0, 5, m, u, n, d, o, // value: mundo
+	}))
+	assert.EqualError(t, err, "cannot read [string multimap] entry 1: duplicate key 'hello'")
+	_, _, err = ReadStringMultiMapOrdered(bytes.NewReader([]byte{0, 1, 0, 5, h, e, l, l}))
+	assert.EqualError(t, err, "cannot read [string multimap] entry 0 key: cannot read [string] content: unexpected EOF")
+}
+
+func TestWriteStringMultiMapOrdered(t *testing.T) {
+	input := map[string][]string{"world": {}, "hello": {}, "mundo": {}}
+	tests := []struct {
+		name     string
+		keys     []string
+		expected []byte
+	}{
+		{"no keys: lexical order", nil, []byte{
+			0, 3, // map length
+			0, 5, h, e, l, l, o, 0, 0,
+			0, 5, m, u, n, d, o, 0, 0,
+			0, 5, w, o, r, l, d, 0, 0,
+		}},
+		{"partial keys", []string{"world", "unknown", "world"}, []byte{
+			0, 3, // map length
+			0, 5, w, o, r, l, d, 0, 0,
+			0, 5, h, e, l, l, o, 0, 0,
+			0, 5, m, u, n, d, o, 0, 0,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			assert.NoError(t, WriteStringMultiMapOrdered(input, tt.keys, buf))
+			assert.Equal(t, tt.expected, buf.Bytes())
+		})
+	}
+}
+
+// benchmarkStringMultiMap resembles the options of a typical SUPPORTED response.
+var benchmarkStringMultiMap = map[string][]string{
+	"CQL_VERSION":       {"3.4.5"},
+	"COMPRESSION":       {"snappy", "lz4"},
+	"PROTOCOL_VERSIONS": {"3/v3", "4/v4", "5/v5", "6/v6-beta"},
+}
+
+func BenchmarkReadStringMultiMap(b *testing.B) {
+	buf := &bytes.Buffer{}
+	_ = WriteStringMultiMap(benchmarkStringMultiMap, buf)
+	encoded := buf.Bytes()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadStringMultiMap(bytes.NewReader(encoded)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteStringMultiMap(b *testing.B) {
+	buf := NewWriteBuffer(LengthOfStringMultiMap(benchmarkStringMultiMap))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := WriteStringMultiMap(benchmarkStringMultiMap, buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLengthOfStringMultiMap(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = LengthOfStringMultiMap(benchmarkStringMultiMap)
+	}
+}
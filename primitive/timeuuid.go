@@ -0,0 +1,109 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Time-based (version 1) UUIDs, as used by CQL timeuuid columns, see RFC 4122 section 4.2.
+
+// uuidEpochOffset is the number of 100-nanosecond intervals between the UUID epoch, 1582-10-15 00:00:00 UTC, and the
+// Unix epoch.
+const uuidEpochOffset = 0x01b21dd213814000
+
+const (
+	// minClockSeqAndNode and maxClockSeqAndNode are the lowest and highest clock sequence and node bytes in Cassandra's
+	// timeuuid ordering, which compares them as signed bytes.
+	minClockSeqAndNode = 0x8080808080808080
+	maxClockSeqAndNode = 0x7f7f7f7f7f7f7f7f
+)
+
+var timeUuidGenerator struct {
+	lock          sync.Mutex
+	lastTimestamp uint64
+	clockSeqNode  uint64
+}
+
+// NewTimeUuid returns a new time-based (version 1) UUID for the current time, suitable for timeuuid columns.
+//
+// The clock sequence is random, and the node is a random multicast address, as recommended by RFC 4122 when no IEEE
+// 802 address is used; both are chosen once per process. Timestamps have a resolution of 100 nanoseconds; they are
+// strictly increasing within the process, even when generating UUIDs faster than that, or if the system clock goes
+// backwards, so that the returned UUIDs are unique and ordered.
+func NewTimeUuid() *UUID {
+	g := &timeUuidGenerator
+	g.lock.Lock()
+	if g.clockSeqNode == 0 {
+		var random [8]byte
+		_, _ = rand.Read(random[:])
+		g.clockSeqNode = binary.BigEndian.Uint64(random[:])&0x3fffffffffffffff | // 14-bit clock sequence and node
+			0x8000000000000000 | // variant
+			0x0000010000000000 // multicast bit of the node
+	}
+	timestamp := toUuidTimestamp(time.Now())
+	if timestamp <= g.lastTimestamp {
+		timestamp = g.lastTimestamp + 1
+	}
+	g.lastTimestamp = timestamp
+	clockSeqNode := g.clockSeqNode
+	g.lock.Unlock()
+	return newTimeUuid(timestamp, clockSeqNode)
+}
+
+// MinTimeUuid returns the lowest timeuuid for the millisecond of the given time, like the CQL minTimeuuid function.
+// It is meant for range queries, e.g. to select the timeuuids generated at or after t; it should not be inserted.
+func MinTimeUuid(t time.Time) *UUID {
+	return newTimeUuid(toUuidTimestamp(t.Truncate(time.Millisecond)), minClockSeqAndNode)
+}
+
+// MaxTimeUuid returns the highest timeuuid for the millisecond of the given time, like the CQL maxTimeuuid function.
+// It is meant for range queries, e.g. to select the timeuuids generated at or before t; it should not be inserted.
+func MaxTimeUuid(t time.Time) *UUID {
+	return newTimeUuid(toUuidTimestamp(t.Truncate(time.Millisecond).Add(time.Millisecond))-1, maxClockSeqAndNode)
+}
+
+// TimestampOf returns the time of the given time-based (version 1) UUID, in UTC, with a resolution of 100
+// nanoseconds. It returns an error if the UUID is nil or not time-based.
+func TimestampOf(u *UUID) (time.Time, error) {
+	if u == nil {
+		return time.Time{}, fmt.Errorf("cannot extract timestamp of nil UUID")
+	} else if version := u[6] >> 4; version != 1 {
+		return time.Time{}, fmt.Errorf("cannot extract timestamp of UUID %v: expected version 1, got: %d", u, version)
+	}
+	timestamp := uint64(binary.BigEndian.Uint16(u[6:])&0x0fff)<<48 |
+		uint64(binary.BigEndian.Uint16(u[4:]))<<32 |
+		uint64(binary.BigEndian.Uint32(u[0:]))
+	ticks := int64(timestamp) - uuidEpochOffset
+	return time.Unix(ticks/1e7, ticks%1e7*100).UTC(), nil
+}
+
+// toUuidTimestamp returns the number of 100-nanosecond intervals between the UUID epoch and the given time.
+func toUuidTimestamp(t time.Time) uint64 {
+	return uint64(t.Unix()*1e7 + int64(t.Nanosecond()/100) + uuidEpochOffset)
+}
+
+func newTimeUuid(timestamp uint64, clockSeqNode uint64) *UUID {
+	u := &UUID{}
+	binary.BigEndian.PutUint32(u[0:], uint32(timestamp))
+	binary.BigEndian.PutUint16(u[4:], uint16(timestamp>>32))
+	binary.BigEndian.PutUint16(u[6:], uint16(timestamp>>48)&0x0fff|0x1000)
+	binary.BigEndian.PutUint64(u[8:], clockSeqNode)
+	return u
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTimeUuid(t *testing.T) {
+	start := time.Now().Add(-time.Millisecond)
+	var previous time.Time
+	first := NewTimeUuid()
+	for i := 0; i < 1000; i++ {
+		u := NewTimeUuid()
+		assert.Equal(t, byte(1), u[6]>>4, "version")
+		assert.Equal(t, byte(0x80), u[8]&0xc0, "variant")
+		assert.Equal(t, byte(0x01), u[10]&0x01, "multicast bit")
+		assert.Equal(t, first[8:], u[8:], "clock sequence and node")
+		timestamp, err := TimestampOf(u)
+		require.NoError(t, err)
+		assert.True(t, timestamp.After(previous), "timestamps are strictly increasing")
+		assert.True(t, timestamp.After(start))
+		previous = timestamp
+	}
+	assert.WithinDuration(t, time.Now(), previous, time.Second)
+}
+
+func TestMinMaxTimeUuid(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    time.Time
+		min      string
+		max      string
+		minStart time.Time
+		maxEnd   time.Time
+	}{
+		{
+			"UUID epoch",
+			time.Date(1582, 10, 15, 0, 0, 0, 0, time.UTC),
+			"00000000-0000-1000-8080-808080808080",
+			"0000270f-0000-1000-7f7f-7f7f7f7f7f7f",
+			time.Date(1582, 10, 15, 0, 0, 0, 0, time.UTC),
+			time.Date(1582, 10, 15, 0, 0, 0, 999_900, time.UTC),
+		},
+		{
+			"Unix epoch",
+			time.Unix(0, 123_456).UTC(),
+			"13814000-1dd2-11b2-8080-808080808080",
+			"1381670f-1dd2-11b2-7f7f-7f7f7f7f7f7f",
+			time.Unix(0, 0).UTC(),
+			time.Unix(0, 999_900).UTC(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minUuid := MinTimeUuid(tt.input)
+			maxUuid := MaxTimeUuid(tt.input)
+			assert.Equal(t, tt.min, minUuid.String())
+			assert.Equal(t, tt.max, maxUuid.String())
+			minTimestamp, err := TimestampOf(minUuid)
+			require.NoError(t, err)
+			assert.Equal(t, tt.minStart, minTimestamp)
+			maxTimestamp, err := TimestampOf(maxUuid)
+			require.NoError(t, err)
+			assert.Equal(t, tt.maxEnd, maxTimestamp)
+		})
+	}
+}
+
+func TestTimestampOf(t *testing.T) {
+	// 2021-03-04T05:06:07.1234567Z
+	expected := time.Date(2021, 3, 4, 5, 6, 7, 123_456_700, time.UTC)
+	u := newTimeUuid(toUuidTimestamp(expected), minClockSeqAndNode)
+	actual, err := TimestampOf(u)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.True(t, bytes.Compare(MinTimeUuid(expected)[:8], u[:8]) <= 0)
+
+	_, err = TimestampOf(&uuid)
+	assert.EqualError(t, err, "cannot extract timestamp of UUID c0d1d21e-bb01-4196-86db-bc317bc1796a: expected version 1, got: 4")
+	_, err = TimestampOf(nil)
+	assert.EqualError(t, err, "cannot extract timestamp of nil UUID")
+}
@@ -38,8 +38,12 @@ func SupportedDseProtocolVersions() []ProtocolVersion {
 	return matchingProtocolVersions(func(v ProtocolVersion) bool { return v.IsDse() })
 }
 
+// SupportedBetaProtocolVersions returns a slice containing all the beta protocol versions known to this library. Beta
+// versions are not included in SupportedProtocolVersions, since frame codecs must explicitly allow them.
 func SupportedBetaProtocolVersions() []ProtocolVersion {
-	return matchingProtocolVersions(func(v ProtocolVersion) bool { return v.IsBeta() })
+	return []ProtocolVersion{
+		ProtocolVersion6,
+	}
 }
 
 func SupportedNonBetaProtocolVersions() []ProtocolVersion {
@@ -150,7 +154,7 @@ func CheckValidBatchType(batchType BatchType) error {
 }
 
 func CheckValidDataTypeCode(code DataTypeCode, version ProtocolVersion) error {
-	if !code.IsValid() {
+	if !code.IsValid() || !version.SupportsDataType(code) {
 		return fmt.Errorf("invalid data type code for %v: %v", version, code)
 	}
 	return nil
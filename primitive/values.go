@@ -15,6 +15,8 @@
 package primitive
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -51,6 +53,79 @@ func NewUnsetValue() *Value {
 	return &Value{Type: ValueTypeUnset}
 }
 
+// NewEmptyValue returns a regular value with zero-length contents, that is, a CQL empty value. Empty values are
+// distinct from NULLs: for example, an empty varchar is the empty string, not a NULL.
+func NewEmptyValue() *Value {
+	return &Value{Type: ValueTypeRegular, Contents: []byte{}}
+}
+
+// MarshalJSON marshals this value as a JSON object with the following fields:
+//
+//   - "type": the value type, one of "regular", "null" or "unset";
+//   - "contents": for regular values only, the value contents encoded in standard base64; an empty string denotes
+//     empty contents, while a missing field denotes nil contents, which are written as null.
+//
+// For example: {"type":"regular","contents":"AAAAAQ=="}, {"type":"null"} or {"type":"unset"}. Values can be
+// unmarshaled back with UnmarshalJSON, including their type, so that bound values can be faithfully represented in
+// fixtures and capture files.
+func (v Value) MarshalJSON() ([]byte, error) {
+	var typeName string
+	switch v.Type {
+	case ValueTypeRegular:
+		typeName = "regular"
+	case ValueTypeNull:
+		typeName = "null"
+	case ValueTypeUnset:
+		typeName = "unset"
+	default:
+		return nil, fmt.Errorf("cannot marshal [value]: unknown type: %v", v.Type)
+	}
+	obj := valueJSON{Type: typeName}
+	if v.Type == ValueTypeRegular && v.Contents != nil {
+		contents := base64.StdEncoding.EncodeToString(v.Contents)
+		obj.Contents = &contents
+	}
+	return json.Marshal(obj)
+}
+
+// UnmarshalJSON unmarshals this value from a JSON object, see MarshalJSON. Null values are ignored.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var obj *valueJSON
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("cannot unmarshal [value]: %w", err)
+	} else if obj == nil {
+		return nil
+	}
+	var value Value
+	switch obj.Type {
+	case "regular":
+		value.Type = ValueTypeRegular
+		if obj.Contents != nil {
+			contents, err := base64.StdEncoding.DecodeString(*obj.Contents)
+			if err != nil {
+				return fmt.Errorf("cannot unmarshal [value] contents: %w", err)
+			}
+			value.Contents = contents
+		}
+	case "null":
+		value.Type = ValueTypeNull
+	case "unset":
+		value.Type = ValueTypeUnset
+	default:
+		return fmt.Errorf("cannot unmarshal [value]: unknown type: %q", obj.Type)
+	}
+	if value.Type != ValueTypeRegular && obj.Contents != nil {
+		return fmt.Errorf("cannot unmarshal [value]: unexpected contents for %v value", obj.Type)
+	}
+	*v = value
+	return nil
+}
+
+type valueJSON struct {
+	Type     string  `json:"type"`
+	Contents *string `json:"contents,omitempty"`
+}
+
 // [value]
 
 func ReadValue(source io.Reader, version ProtocolVersion) (*Value, error) {
@@ -67,9 +142,11 @@ func ReadValue(source io.Reader, version ProtocolVersion) (*Value, error) {
 		return nil, fmt.Errorf("invalid [value] length: %v", length)
 	} else if length == 0 {
 		return NewValue([]byte{}), nil
+	} else if err := checkBytesLength(source, "[value]", int(length)); err != nil {
+		return nil, err
 	} else {
-		decoded := make([]byte, length)
-		if _, err := io.ReadFull(source, decoded); err != nil {
+		decoded, err := readFull(source, int(length))
+		if err != nil {
 			return nil, fmt.Errorf("cannot read [value] content: %w", err)
 		}
 		return NewValue(decoded), nil
@@ -128,6 +205,8 @@ func LengthOfValue(value *Value) (int, error) {
 func ReadPositionalValues(source io.Reader, version ProtocolVersion) ([]*Value, error) {
 	if length, err := ReadShort(source); err != nil {
 		return nil, fmt.Errorf("cannot read positional [value]s length: %w", err)
+	} else if err := CheckCollectionLength(source, "positional [value]s", int(length)); err != nil {
+		return nil, err
 	} else {
 		decoded := make([]*Value, length)
 		for i := uint16(0); i < length; i++ {
@@ -172,6 +251,8 @@ func LengthOfPositionalValues(values []*Value) (length int, err error) {
 func ReadNamedValues(source io.Reader, version ProtocolVersion) (map[string]*Value, error) {
 	if length, err := ReadShort(source); err != nil {
 		return nil, fmt.Errorf("cannot read named [value]s length: %w", err)
+	} else if err := CheckCollectionLength(source, "named [value]s", int(length)); err != nil {
+		return nil, err
 	} else {
 		decoded := make(map[string]*Value, length)
 		for i := uint16(0); i < length; i++ {
@@ -16,6 +16,7 @@ package primitive
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
@@ -221,6 +222,12 @@ func TestWriteValue(t *testing.T) {
 					[]byte{0, 0, 0, 4, 1, 2, 3, 4},
 					nil,
 				},
+				{
+					"empty value (NewEmptyValue)",
+					NewEmptyValue(),
+					[]byte{0, 0, 0, 0}, // length 0
+					nil,
+				},
 				{
 					"empty value with type null",
 					&Value{Type: ValueTypeNull},
@@ -298,6 +305,12 @@ func TestWriteValue(t *testing.T) {
 					[]byte{0, 0, 0, 4, 1, 2, 3, 4},
 					nil,
 				},
+				{
+					"empty value (NewEmptyValue)",
+					NewEmptyValue(),
+					[]byte{0, 0, 0, 0}, // length 0
+					nil,
+				},
 				{
 					"empty value with type null",
 					&Value{Type: ValueTypeNull},
@@ -1087,3 +1100,35 @@ func TestLengthOfNamedValues(t *testing.T) {
 		})
 	}
 }
+
+func TestValue_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    *Value
+		expected string
+	}{
+		{"regular", NewValue([]byte{0, 0, 0, 1}), `{"type":"regular","contents":"AAAAAQ=="}`},
+		{"empty", NewValue([]byte{}), `{"type":"regular","contents":""}`},
+		{"regular nil", &Value{Type: ValueTypeRegular}, `{"type":"regular"}`},
+		{"null", NewNullValue(), `{"type":"null"}`},
+		{"unset", NewUnsetValue(), `{"type":"unset"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marshaled, err := json.Marshal(tt.value)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(marshaled))
+			var unmarshaled *Value
+			assert.NoError(t, json.Unmarshal(marshaled, &unmarshaled))
+			assert.Equal(t, tt.value, unmarshaled)
+		})
+	}
+	_, err := json.Marshal(Value{Type: 42})
+	assert.Error(t, err)
+	var unmarshaled Value
+	assert.Error(t, json.Unmarshal([]byte(`{"type":"foo"}`), &unmarshaled))
+	assert.Error(t, json.Unmarshal([]byte(`{"type":"null","contents":"AA=="}`), &unmarshaled))
+	assert.Error(t, json.Unmarshal([]byte(`{"type":"regular","contents":"!"}`), &unmarshaled))
+	assert.NoError(t, json.Unmarshal([]byte(`null`), &unmarshaled))
+	assert.Equal(t, Value{}, unmarshaled)
+}
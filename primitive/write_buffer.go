@@ -0,0 +1,155 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// WriteBuffer is a growable byte buffer optimized for the write patterns of protocol codecs: fixed-size big-endian
+// integers and length-prefixed chunks. Unlike bytes.Buffer, it never reads, and its integer writes do not go through
+// encoding/binary nor allocate. The Write* functions in this package detect when their destination is a WriteBuffer
+// and take a fast path accordingly, so a WriteBuffer can be passed wherever an io.Writer is expected.
+//
+// WriteBuffers can be pooled with AcquireWriteBuffer and ReleaseWriteBuffer; this is mostly useful when the encoded
+// bytes are consumed before the buffer is released, or copied out with CopyBytes. The zero value is an empty buffer
+// ready to use. A WriteBuffer is not safe for concurrent use.
+type WriteBuffer struct {
+	buf []byte
+}
+
+// NewWriteBuffer creates a new, empty WriteBuffer with the given initial capacity.
+func NewWriteBuffer(capacity int) *WriteBuffer {
+	return &WriteBuffer{buf: make([]byte, 0, capacity)}
+}
+
+// Buffers larger than this are not returned to the pool, to avoid retaining large amounts of memory after encoding a
+// few big frames.
+const maxPooledWriteBufferCapacity = 64 * 1024
+
+var writeBufferPool = sync.Pool{
+	New: func() interface{} {
+		return NewWriteBuffer(512)
+	},
+}
+
+// AcquireWriteBuffer returns an empty WriteBuffer from a shared pool. Callers should return it with ReleaseWriteBuffer
+// once done.
+func AcquireWriteBuffer() *WriteBuffer {
+	return writeBufferPool.Get().(*WriteBuffer)
+}
+
+// ReleaseWriteBuffer resets the given buffer and returns it to the shared pool. The buffer, and any slice previously
+// obtained from Bytes, must not be used afterwards.
+func ReleaseWriteBuffer(b *WriteBuffer) {
+	if b == nil || cap(b.buf) > maxPooledWriteBufferCapacity {
+		return
+	}
+	b.Reset()
+	writeBufferPool.Put(b)
+}
+
+// Bytes returns the contents of the buffer. The returned slice aliases the buffer's storage and is only valid until
+// the next modification of the buffer.
+func (b *WriteBuffer) Bytes() []byte {
+	return b.buf
+}
+
+// CopyBytes returns a copy of the contents of the buffer, safe to use after the buffer is modified or released. It
+// returns nil if the buffer is empty.
+func (b *WriteBuffer) CopyBytes() []byte {
+	if len(b.buf) == 0 {
+		return nil
+	}
+	result := make([]byte, len(b.buf))
+	copy(result, b.buf)
+	return result
+}
+
+// Len returns the number of bytes written so far.
+func (b *WriteBuffer) Len() int {
+	return len(b.buf)
+}
+
+// Reset empties the buffer, retaining its storage for future writes.
+func (b *WriteBuffer) Reset() {
+	b.buf = b.buf[:0]
+}
+
+// Grow grows the buffer capacity, if necessary, to guarantee space for another n bytes.
+func (b *WriteBuffer) Grow(n int) {
+	if cap(b.buf)-len(b.buf) < n {
+		grown := make([]byte, len(b.buf), 2*cap(b.buf)+n)
+		copy(grown, b.buf)
+		b.buf = grown
+	}
+}
+
+// Write appends the given bytes to the buffer; it never fails.
+func (b *WriteBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+// WriteString appends the given string to the buffer, without any length prefix; it never fails.
+func (b *WriteBuffer) WriteString(s string) (int, error) {
+	b.buf = append(b.buf, s...)
+	return len(s), nil
+}
+
+// WriteByte appends the given byte to the buffer; it never fails.
+func (b *WriteBuffer) WriteByte(c byte) error {
+	b.buf = append(b.buf, c)
+	return nil
+}
+
+// WriteTo writes the contents of the buffer to the given writer, and drains the buffer, like bytes.Buffer.WriteTo.
+// If the writer does not write all the bytes, the buffer only retains the bytes that were not written, and
+// io.ErrShortWrite is returned unless the writer returned an error.
+func (b *WriteBuffer) WriteTo(dest io.Writer) (int64, error) {
+	n, err := dest.Write(b.buf)
+	if n < len(b.buf) {
+		b.buf = b.buf[:copy(b.buf, b.buf[n:])]
+		if err == nil {
+			err = io.ErrShortWrite
+		}
+	} else {
+		b.buf = b.buf[:0]
+	}
+	return int64(n), err
+}
+
+func (b *WriteBuffer) appendShort(i uint16) {
+	b.Grow(LengthOfShort)
+	n := len(b.buf)
+	b.buf = b.buf[:n+LengthOfShort]
+	binary.BigEndian.PutUint16(b.buf[n:], i)
+}
+
+func (b *WriteBuffer) appendInt(i int32) {
+	b.Grow(LengthOfInt)
+	n := len(b.buf)
+	b.buf = b.buf[:n+LengthOfInt]
+	binary.BigEndian.PutUint32(b.buf[n:], uint32(i))
+}
+
+func (b *WriteBuffer) appendLong(l int64) {
+	b.Grow(LengthOfLong)
+	n := len(b.buf)
+	b.buf = b.buf[:n+LengthOfLong]
+	binary.BigEndian.PutUint64(b.buf[n:], uint64(l))
+}
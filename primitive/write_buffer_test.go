@@ -0,0 +1,155 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeChunks exercises the typical write pattern of message codecs.
+func writeChunks(dest io.Writer) error {
+	if err := WriteByte(0x85, dest); err != nil {
+		return err
+	} else if err = WriteShort(0xcafe, dest); err != nil {
+		return err
+	} else if err = WriteInt(-2, dest); err != nil {
+		return err
+	} else if err = WriteLong(1<<40, dest); err != nil {
+		return err
+	} else if err = WriteString("hello", dest); err != nil {
+		return err
+	} else if err = WriteLongString("world", dest); err != nil {
+		return err
+	} else if err = WriteBytes(nil, dest); err != nil {
+		return err
+	} else if err = WriteShortBytes([]byte{1, 2}, dest); err != nil {
+		return err
+	}
+	return WriteStringMultiMap(map[string][]string{"CQL_VERSION": {"3.0.0"}}, dest)
+}
+
+func TestWriteBuffer(t *testing.T) {
+	expected := &bytes.Buffer{}
+	require.NoError(t, writeChunks(expected))
+	t.Run("zero value", func(t *testing.T) {
+		buf := &WriteBuffer{}
+		require.NoError(t, writeChunks(buf))
+		assert.Equal(t, expected.Bytes(), buf.Bytes())
+		assert.Equal(t, expected.Len(), buf.Len())
+	})
+	t.Run("small capacity", func(t *testing.T) {
+		buf := NewWriteBuffer(1)
+		require.NoError(t, writeChunks(buf))
+		assert.Equal(t, expected.Bytes(), buf.Bytes())
+	})
+	t.Run("pooled", func(t *testing.T) {
+		buf := AcquireWriteBuffer()
+		defer ReleaseWriteBuffer(buf)
+		assert.Equal(t, 0, buf.Len())
+		require.NoError(t, writeChunks(buf))
+		assert.Equal(t, expected.Bytes(), buf.Bytes())
+	})
+	t.Run("copy and reset", func(t *testing.T) {
+		buf := NewWriteBuffer(64)
+		assert.Nil(t, buf.CopyBytes())
+		require.NoError(t, writeChunks(buf))
+		copied := buf.CopyBytes()
+		buf.Reset()
+		assert.Equal(t, 0, buf.Len())
+		require.NoError(t, WriteInt(0, buf))
+		assert.Equal(t, expected.Bytes(), copied)
+	})
+	t.Run("write to", func(t *testing.T) {
+		buf := NewWriteBuffer(64)
+		require.NoError(t, writeChunks(buf))
+		dest := &bytes.Buffer{}
+		n, err := buf.WriteTo(dest)
+		require.NoError(t, err)
+		assert.EqualValues(t, expected.Len(), n)
+		assert.Equal(t, expected.Bytes(), dest.Bytes())
+		// the buffer is drained
+		assert.Equal(t, 0, buf.Len())
+	})
+	t.Run("write to short write", func(t *testing.T) {
+		buf := NewWriteBuffer(64)
+		require.NoError(t, writeChunks(buf))
+		dest := &shortWriter{max: 3}
+		n, err := buf.WriteTo(dest)
+		assert.Equal(t, io.ErrShortWrite, err)
+		assert.EqualValues(t, 3, n)
+		assert.Equal(t, expected.Bytes()[:3], dest.written)
+		// the unwritten bytes are retained
+		assert.Equal(t, expected.Bytes()[3:], buf.Bytes())
+	})
+	t.Run("grow", func(t *testing.T) {
+		buf := NewWriteBuffer(0)
+		buf.Grow(100)
+		assert.GreaterOrEqual(t, cap(buf.Bytes()), 100)
+		assert.Equal(t, 0, buf.Len())
+	})
+}
+
+func TestReleaseWriteBuffer_Oversized(t *testing.T) {
+	buf := NewWriteBuffer(maxPooledWriteBufferCapacity + 1)
+	_, _ = buf.Write([]byte{1, 2, 3})
+	ReleaseWriteBuffer(buf)
+	// oversized buffers are not reset, since they are not returned to the pool
+	assert.Equal(t, 3, buf.Len())
+	ReleaseWriteBuffer(nil)
+}
+
+func BenchmarkWriteChunks(b *testing.B) {
+	b.Run("bytes.Buffer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := &bytes.Buffer{}
+			_ = writeChunks(buf)
+		}
+	})
+	b.Run("WriteBuffer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := &WriteBuffer{}
+			_ = writeChunks(buf)
+		}
+	})
+	b.Run("pooled WriteBuffer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := AcquireWriteBuffer()
+			_ = writeChunks(buf)
+			ReleaseWriteBuffer(buf)
+		}
+	})
+}
+
+// shortWriter writes at most max bytes at each call, without returning an error.
+type shortWriter struct {
+	max     int
+	written []byte
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.max {
+		p = p[:w.max]
+	}
+	w.written = append(w.written, p...)
+	return len(p), nil
+}
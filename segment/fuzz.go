@@ -0,0 +1,34 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package segment
+
+import (
+	"bytes"
+)
+
+var fuzzCodec = NewCodec()
+
+// FuzzDecode is an entry point for fuzzers such as go-fuzz; with Go 1.18 and higher, it is also wired to Go native
+// fuzzing, see FuzzDecode in this package's tests. It decodes the given data as an uncompressed segment, then
+// re-encodes the decoded segment. Decoding errors are expected, but the decoder must never panic, whatever the input.
+// FuzzDecode returns 1 if the data was decoded successfully, and 0 otherwise, as go-fuzz expects.
+func FuzzDecode(data []byte) int {
+	decoded, err := fuzzCodec.DecodeSegment(bytes.NewReader(data))
+	if err != nil {
+		return 0
+	}
+	_ = fuzzCodec.EncodeSegment(decoded, &bytes.Buffer{})
+	return 1
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package segment_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/datastax/go-cassandra-native-protocol/segment"
+)
+
+// FuzzDecode runs segment.FuzzDecode with Go native fuzzing, e.g.: go test -fuzz=FuzzDecode ./segment.
+func FuzzDecode(f *testing.F) {
+	codec := segment.NewCodec()
+	for _, payload := range [][]byte{{}, {1, 2, 3}, bytes.Repeat([]byte{0xca}, 1024)} {
+		buf := &bytes.Buffer{}
+		s := &segment.Segment{
+			Header:  &segment.Header{IsSelfContained: true, UncompressedPayloadLength: int32(len(payload))},
+			Payload: &segment.Payload{UncompressedData: payload},
+		}
+		if err := codec.EncodeSegment(s, buf); err == nil {
+			f.Add(buf.Bytes())
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		segment.FuzzDecode(data)
+	})
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package segment
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// SegmentWriter is an io.Writer that coalesces the encoded frames written to it into segments, using a Multiplexer;
+// it can thus be used as the destination of frame.Encoder.EncodeFrame, or of any component writing a stream of v5
+// frames, to switch it to the modern framing layout.
+//
+// Frames do not need to be written in one call: bytes are accumulated until a whole frame is available, which is
+// then handed to the Multiplexer. Pending frames are written as one self-contained segment when the payload size
+// threshold is reached, when the flush delay expires, or when Flush is called.
+//
+// A SegmentWriter is safe for concurrent use, but concurrent writes must write whole frames to avoid interleaving
+// them.
+type SegmentWriter struct {
+	multiplexer *Multiplexer
+	incomplete  []byte
+	lock        sync.Mutex
+}
+
+// NewSegmentWriter creates a new SegmentWriter writing segments to dest. The parameters maxPayloadLength and
+// flushDelay have the same meaning as in NewMultiplexer.
+func NewSegmentWriter(codec Codec, dest io.Writer, maxPayloadLength int, flushDelay time.Duration) *SegmentWriter {
+	return &SegmentWriter{multiplexer: NewMultiplexer(codec, dest, maxPayloadLength, flushDelay)}
+}
+
+// Write adds the whole frames contained in p, including the frame started by previous writes, if any, to the current
+// segment; the bytes of a trailing incomplete frame are retained until the next write completes it. Since frames are
+// only written once whole, n is len(p) unless an error occurs.
+func (w *SegmentWriter) Write(p []byte) (n int, err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	data := p
+	if len(w.incomplete) > 0 {
+		w.incomplete = append(w.incomplete, p...)
+		data = w.incomplete
+	}
+	offset := 0
+	for len(data)-offset >= primitive.FrameHeaderLengthV3AndHigher {
+		length, err := encodedFrameLength(data[offset:])
+		if err != nil {
+			return 0, fmt.Errorf("cannot read frame header: %w", err)
+		} else if offset+length > len(data) {
+			break
+		} else if err = w.multiplexer.WriteFrame(data[offset : offset+length]); err != nil {
+			return 0, err
+		}
+		offset += length
+	}
+	w.incomplete = append(w.incomplete[:0], data[offset:]...)
+	return len(p), nil
+}
+
+// Flush writes all pending whole frames as one self-contained segment; the bytes of an incomplete frame, if any, are
+// retained.
+func (w *SegmentWriter) Flush() error {
+	return w.multiplexer.Flush()
+}
+
+// Close flushes all pending frames and releases resources held by this SegmentWriter. It does not close the
+// underlying writer. It fails if an incomplete frame is pending, since its bytes cannot be written.
+func (w *SegmentWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if err := w.multiplexer.Close(); err != nil {
+		return err
+	} else if len(w.incomplete) > 0 {
+		return fmt.Errorf("cannot close segment writer: %d bytes of incomplete frame pending", len(w.incomplete))
+	}
+	return nil
+}
+
+// SegmentReader is an io.Reader returning the encoded frames contained in the segments read from a source, using a
+// Demultiplexer; it can thus be used as the source of frame.Decoder.DecodeFrame, or of any component reading a stream
+// of v5 frames. It is the counterpart of SegmentWriter.
+//
+// A SegmentReader is not safe for concurrent use.
+type SegmentReader struct {
+	demultiplexer *Demultiplexer
+	current       []byte
+}
+
+// NewSegmentReader creates a new SegmentReader reading segments from source.
+func NewSegmentReader(codec Codec, source io.Reader) *SegmentReader {
+	return &SegmentReader{demultiplexer: NewDemultiplexer(codec, source)}
+}
+
+// Read reads the bytes of the current frame into p, reading the next frame when the current one is exhausted. It
+// never returns bytes of two different frames, so that reading the exact length of a frame does not read ahead; it
+// returns io.EOF when the source is exhausted between two segments.
+func (r *SegmentReader) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(r.current) == 0 {
+		if r.current, err = r.demultiplexer.ReadFrame(); err != nil {
+			return 0, err
+		}
+	}
+	n = copy(p, r.current)
+	r.current = r.current[n:]
+	return n, nil
+}
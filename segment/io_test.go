@@ -0,0 +1,99 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package segment
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+)
+
+func TestSegmentWriter_RoundTrip(t *testing.T) {
+	codecs := map[string]Codec{
+		"uncompressed": NewCodec(),
+		"lz4":          NewCodecWithCompression(&lz4.Compressor{}),
+	}
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			frames := [][]byte{
+				encodedFrame(1, 10),
+				encodedFrame(2, 0),
+				encodedFrame(3, 500),
+				encodedFrame(4, MaxPayloadLength*2+100), // multi-segment
+				encodedFrame(5, 20),
+			}
+			stream := bytes.Join(frames, nil)
+			dest := &bytes.Buffer{}
+			writer := NewSegmentWriter(codec, dest, 0, 0)
+			// frames split across writes, and many frames in one write
+			for _, chunk := range [][]byte{stream[:5], stream[5:12], stream[12:600], stream[600:]} {
+				n, err := writer.Write(chunk)
+				require.NoError(t, err)
+				assert.Equal(t, len(chunk), n)
+			}
+			require.NoError(t, writer.Close())
+
+			reader := NewSegmentReader(codec, dest)
+			for _, expected := range frames {
+				// reads never cross frame boundaries
+				actual := make([]byte, len(stream))
+				n, err := io.ReadAtLeast(reader, actual, len(expected))
+				require.NoError(t, err)
+				assert.Equal(t, expected, actual[:n])
+			}
+			_, err := reader.Read(make([]byte, 1))
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestSegmentWriter_Coalescing(t *testing.T) {
+	dest := &syncBuffer{}
+	writer := NewSegmentWriter(NewCodec(), dest, 100, 20*time.Millisecond)
+	_, err := writer.Write(encodedFrame(1, 10))
+	require.NoError(t, err)
+	_, err = writer.Write(encodedFrame(2, 10))
+	require.NoError(t, err)
+	assert.Zero(t, dest.Len())
+	// time-based flush
+	assert.Eventually(t, func() bool { return dest.Len() > 0 }, time.Second, time.Millisecond)
+	seg, err := NewCodec().DecodeSegment(bytes.NewReader(dest.Bytes()))
+	require.NoError(t, err)
+	assert.True(t, seg.Header.IsSelfContained)
+	assert.Equal(t, append(encodedFrame(1, 10), encodedFrame(2, 10)...), seg.Payload.UncompressedData)
+	// size-based flush
+	length := dest.Len()
+	_, err = writer.Write(encodedFrame(3, 91))
+	require.NoError(t, err)
+	assert.Greater(t, dest.Len(), length)
+	require.NoError(t, writer.Close())
+}
+
+func TestSegmentWriter_Errors(t *testing.T) {
+	writer := NewSegmentWriter(NewCodec(), &bytes.Buffer{}, 0, 0)
+	_, err := writer.Write(encodedFrame(1, 10)[:12])
+	require.NoError(t, err)
+	assert.EqualError(t, writer.Close(), "cannot close segment writer: 12 bytes of incomplete frame pending")
+
+	writer = NewSegmentWriter(NewCodec(), &bytes.Buffer{}, 0, 0)
+	_, err = writer.Write([]byte{5, 0, 0, 1, 7, 0xff, 0xff, 0xff, 0xff})
+	assert.EqualError(t, err, "cannot read frame header: negative frame body length: -1")
+}
@@ -0,0 +1,270 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package segment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Multiplexer coalesces outbound encoded frames into segments, as allowed by the modern framing layout introduced in
+// protocol v5.
+//
+// Frames are accumulated in a self-contained segment until either the payload size threshold is reached, the flush
+// delay expires, or Flush is explicitly called. Frames that are too large to fit in a single segment are split across
+// many non-self-contained segments.
+//
+// Note that the frames passed to a Multiplexer must be fully encoded frames, and must not be individually compressed;
+// compression, if any, is performed at segment level by the underlying Codec.
+//
+// A Multiplexer is safe for concurrent use.
+type Multiplexer struct {
+	codec            Codec
+	dest             io.Writer
+	maxPayloadLength int
+	flushDelay       time.Duration
+	buffer           *bytes.Buffer
+	timer            *time.Timer
+	err              error
+	closed           bool
+	lock             sync.Mutex
+}
+
+// NewMultiplexer creates a new Multiplexer writing segments to dest.
+// The parameter maxPayloadLength is the payload size threshold that triggers a flush, and also the size of each part
+// when a large frame is split across many segments; if it is lesser than or equal to zero, or greater than
+// MaxPayloadLength, MaxPayloadLength is used.
+// The parameter flushDelay is the maximum amount of time a frame can wait before being flushed; if it is lesser than
+// or equal to zero, frames are only flushed when the size threshold is reached, or when Flush is called.
+func NewMultiplexer(codec Codec, dest io.Writer, maxPayloadLength int, flushDelay time.Duration) *Multiplexer {
+	if maxPayloadLength <= 0 || maxPayloadLength > MaxPayloadLength {
+		maxPayloadLength = MaxPayloadLength
+	}
+	return &Multiplexer{
+		codec:            codec,
+		dest:             dest,
+		maxPayloadLength: maxPayloadLength,
+		flushDelay:       flushDelay,
+		buffer:           &bytes.Buffer{},
+	}
+}
+
+// WriteFrame adds the given encoded frame to the current segment, flushing it if necessary. If the frame is too large
+// to fit in one segment, the current segment is flushed and the frame is written immediately as a sequence of
+// non-self-contained segments. If a previous asynchronous flush failed, its error is returned and the frame is not
+// written.
+func (m *Multiplexer) WriteFrame(encodedFrame []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.closed {
+		return errors.New("multiplexer closed")
+	} else if m.err != nil {
+		return m.err
+	}
+	if len(encodedFrame) > m.maxPayloadLength {
+		if err := m.flush(); err != nil {
+			return err
+		}
+		return m.writeMultiSegment(encodedFrame)
+	}
+	if m.buffer.Len()+len(encodedFrame) > m.maxPayloadLength {
+		if err := m.flush(); err != nil {
+			return err
+		}
+	}
+	m.buffer.Write(encodedFrame)
+	if m.buffer.Len() == m.maxPayloadLength {
+		return m.flush()
+	} else if m.flushDelay > 0 && m.timer == nil {
+		m.timer = time.AfterFunc(m.flushDelay, m.flushAsync)
+	}
+	return nil
+}
+
+// Flush writes all pending frames as one self-contained segment. It is a no-op if there are no pending frames.
+func (m *Multiplexer) Flush() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	return m.flush()
+}
+
+// Close flushes all pending frames and releases resources held by this Multiplexer. It does not close the underlying
+// writer. Subsequent calls to WriteFrame will fail.
+func (m *Multiplexer) Close() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	if m.err != nil {
+		return m.err
+	}
+	return m.flush()
+}
+
+func (m *Multiplexer) flushAsync() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.timer = nil
+	if m.err == nil {
+		// errors are stored in m.err and reported on next write
+		_ = m.flush()
+	}
+}
+
+func (m *Multiplexer) flush() error {
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+	if m.buffer.Len() == 0 {
+		return nil
+	}
+	payload := make([]byte, m.buffer.Len())
+	copy(payload, m.buffer.Bytes())
+	m.buffer.Reset()
+	return m.writeSegment(payload, true)
+}
+
+func (m *Multiplexer) writeMultiSegment(encodedFrame []byte) error {
+	for offset := 0; offset < len(encodedFrame); offset += m.maxPayloadLength {
+		end := offset + m.maxPayloadLength
+		if end > len(encodedFrame) {
+			end = len(encodedFrame)
+		}
+		if err := m.writeSegment(encodedFrame[offset:end], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Multiplexer) writeSegment(payload []byte, selfContained bool) error {
+	seg := &Segment{
+		Header:  &Header{IsSelfContained: selfContained},
+		Payload: &Payload{UncompressedData: payload},
+	}
+	if err := m.codec.EncodeSegment(seg, m.dest); err != nil {
+		m.err = fmt.Errorf("cannot write segment: %w", err)
+		return m.err
+	}
+	return nil
+}
+
+// Demultiplexer reads segments from a source and splits them back into encoded frames. It handles both
+// self-contained segments containing one or more frames, and sequences of non-self-contained segments containing
+// one large frame.
+//
+// A Demultiplexer is not safe for concurrent use.
+type Demultiplexer struct {
+	codec       Codec
+	source      io.Reader
+	pending     [][]byte
+	accumulated []byte
+	target      int
+}
+
+// NewDemultiplexer creates a new Demultiplexer reading segments from source.
+func NewDemultiplexer(codec Codec, source io.Reader) *Demultiplexer {
+	return &Demultiplexer{codec: codec, source: source}
+}
+
+// ReadFrame returns the next encoded frame, reading as many segments from the source as necessary. When the source is
+// exhausted, io.EOF is returned.
+func (d *Demultiplexer) ReadFrame() ([]byte, error) {
+	for len(d.pending) == 0 {
+		seg, err := d.codec.DecodeSegment(d.source)
+		if err != nil {
+			if errors.Is(err, io.EOF) && d.target == 0 {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("cannot read segment: %w", err)
+		}
+		if seg.Header.IsSelfContained {
+			if d.target != 0 {
+				return nil, fmt.Errorf("unexpected self-contained segment: expecting remaining %d bytes of multi-segment frame",
+					d.target-len(d.accumulated))
+			} else if d.pending, err = SplitFrames(seg.Payload.UncompressedData); err != nil {
+				return nil, err
+			}
+		} else if err = d.accumulate(seg.Payload.UncompressedData); err != nil {
+			return nil, err
+		}
+	}
+	frame := d.pending[0]
+	d.pending = d.pending[1:]
+	return frame, nil
+}
+
+func (d *Demultiplexer) accumulate(payload []byte) error {
+	if d.target == 0 {
+		if length, err := encodedFrameLength(payload); err != nil {
+			return fmt.Errorf("cannot read first frame header in multi-segment payload: %w", err)
+		} else {
+			d.target = length
+		}
+	}
+	d.accumulated = append(d.accumulated, payload...)
+	if len(d.accumulated) > d.target {
+		return fmt.Errorf("multi-segment payload exceeds frame length: %d > %d", len(d.accumulated), d.target)
+	} else if len(d.accumulated) == d.target {
+		d.pending = append(d.pending, d.accumulated)
+		d.accumulated = nil
+		d.target = 0
+	}
+	return nil
+}
+
+// SplitFrames splits the payload of a self-contained segment into individual encoded frames. The returned slices
+// share the payload's underlying array.
+func SplitFrames(payload []byte) ([][]byte, error) {
+	var frames [][]byte
+	for offset := 0; offset < len(payload); {
+		length, err := encodedFrameLength(payload[offset:])
+		if err != nil {
+			return nil, fmt.Errorf("cannot read frame header at offset %d: %w", offset, err)
+		} else if offset+length > len(payload) {
+			return nil, fmt.Errorf("frame at offset %d exceeds segment payload: %d > %d", offset, length, len(payload)-offset)
+		}
+		frames = append(frames, payload[offset:offset+length:offset+length])
+		offset += length
+	}
+	return frames, nil
+}
+
+// encodedFrameLength returns the total length of the frame whose header is at the beginning of the given slice.
+// Segments are only used with protocol v5 and higher, so the header is always 9 bytes long, with the body length in
+// the last 4 bytes.
+func encodedFrameLength(data []byte) (int, error) {
+	if len(data) < primitive.FrameHeaderLengthV3AndHigher {
+		return 0, fmt.Errorf("not enough bytes to read frame header: %d < %d", len(data), primitive.FrameHeaderLengthV3AndHigher)
+	}
+	bodyLength := int32(binary.BigEndian.Uint32(data[primitive.FrameHeaderLengthV3AndHigher-primitive.LengthOfInt:]))
+	if bodyLength < 0 {
+		return 0, fmt.Errorf("negative frame body length: %d", bodyLength)
+	}
+	return primitive.FrameHeaderLengthV3AndHigher + int(bodyLength), nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package segment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+)
+
+// encodedFrame creates a fake v5 frame with the given stream id and body length. The body is filled with
+// pseudo-random bytes, to avoid compression ratios that the lz4 compressor cannot handle.
+func encodedFrame(streamId int16, bodyLength int) []byte {
+	random := rand.New(rand.NewSource(int64(streamId)))
+	f := make([]byte, 9+bodyLength)
+	f[0] = 5
+	binary.BigEndian.PutUint16(f[2:], uint16(streamId))
+	f[4] = 0x07
+	binary.BigEndian.PutUint32(f[5:], uint32(bodyLength))
+	random.Read(f[9:])
+	return f
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	buf  bytes.Buffer
+	lock sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Len() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Len()
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Bytes()
+}
+
+func TestMultiplexer_RoundTrip(t *testing.T) {
+	codecs := map[string]Codec{
+		"uncompressed": NewCodec(),
+		"lz4":          NewCodecWithCompression(&lz4.Compressor{}),
+	}
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			frames := [][]byte{
+				encodedFrame(1, 10),
+				encodedFrame(2, 0),
+				encodedFrame(3, 500),
+				encodedFrame(4, MaxPayloadLength*2+100), // multi-segment
+				encodedFrame(5, 20),
+			}
+			dest := &bytes.Buffer{}
+			mux := NewMultiplexer(codec, dest, 1000, 0)
+			for _, f := range frames {
+				require.NoError(t, mux.WriteFrame(f))
+			}
+			require.NoError(t, mux.Close())
+			assert.EqualError(t, mux.WriteFrame(frames[0]), "multiplexer closed")
+			demux := NewDemultiplexer(codec, dest)
+			for _, expected := range frames {
+				actual, err := demux.ReadFrame()
+				require.NoError(t, err)
+				assert.Equal(t, expected, actual)
+			}
+			_, err := demux.ReadFrame()
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestMultiplexer_Coalescing(t *testing.T) {
+	t.Run("size threshold", func(t *testing.T) {
+		dest := &bytes.Buffer{}
+		mux := NewMultiplexer(NewCodec(), dest, 100, 0)
+		require.NoError(t, mux.WriteFrame(encodedFrame(1, 41)))
+		assert.Equal(t, 0, dest.Len())
+		require.NoError(t, mux.WriteFrame(encodedFrame(2, 41)))
+		// 2 frames of 50 bytes reach the threshold: segment flushed
+		assert.Equal(t, UncompressedHeaderLength+Crc24Length+100+Crc32Length, dest.Len())
+		require.NoError(t, mux.WriteFrame(encodedFrame(3, 41)))
+		assert.Equal(t, UncompressedHeaderLength+Crc24Length+100+Crc32Length, dest.Len())
+		require.NoError(t, mux.Flush())
+		assert.Equal(t, 2*(UncompressedHeaderLength+Crc24Length+Crc32Length)+150, dest.Len())
+		segment, err := NewCodec().DecodeSegment(dest)
+		require.NoError(t, err)
+		assert.True(t, segment.Header.IsSelfContained)
+		frames, err := SplitFrames(segment.Payload.UncompressedData)
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{encodedFrame(1, 41), encodedFrame(2, 41)}, frames)
+	})
+	t.Run("flush delay", func(t *testing.T) {
+		dest := &syncBuffer{}
+		mux := NewMultiplexer(NewCodec(), dest, 0, 10*time.Millisecond)
+		require.NoError(t, mux.WriteFrame(encodedFrame(1, 10)))
+		require.NoError(t, mux.WriteFrame(encodedFrame(2, 10)))
+		assert.Eventually(t, func() bool { return dest.Len() > 0 }, time.Second, time.Millisecond)
+		demux := NewDemultiplexer(NewCodec(), bytes.NewReader(dest.Bytes()))
+		actual, err := demux.ReadFrame()
+		require.NoError(t, err)
+		assert.Equal(t, encodedFrame(1, 10), actual)
+		actual, err = demux.ReadFrame()
+		require.NoError(t, err)
+		assert.Equal(t, encodedFrame(2, 10), actual)
+		require.NoError(t, mux.Close())
+	})
+}
+
+func TestSplitFrames(t *testing.T) {
+	frames, err := SplitFrames(append(encodedFrame(1, 3), encodedFrame(2, 5)...))
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{encodedFrame(1, 3), encodedFrame(2, 5)}, frames)
+	_, err = SplitFrames(encodedFrame(1, 3)[:10])
+	assert.EqualError(t, err, "frame at offset 0 exceeds segment payload: 12 > 10")
+	_, err = SplitFrames(append(encodedFrame(1, 3), 1, 2))
+	assert.EqualError(t, err, "cannot read frame header at offset 12: not enough bytes to read frame header: 2 < 9")
+}
+
+func TestDemultiplexer_Errors(t *testing.T) {
+	codec := NewCodec()
+	t.Run("self-contained segment while accumulating", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		f := encodedFrame(1, 100)
+		require.NoError(t, codec.EncodeSegment(&Segment{Header: &Header{}, Payload: &Payload{UncompressedData: f[:50]}}, buf))
+		require.NoError(t, codec.EncodeSegment(&Segment{Header: &Header{IsSelfContained: true}, Payload: &Payload{UncompressedData: f}}, buf))
+		_, err := NewDemultiplexer(codec, buf).ReadFrame()
+		assert.EqualError(t, err, "unexpected self-contained segment: expecting remaining 59 bytes of multi-segment frame")
+	})
+	t.Run("truncated multi-segment frame", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		f := encodedFrame(1, 100)
+		require.NoError(t, codec.EncodeSegment(&Segment{Header: &Header{}, Payload: &Payload{UncompressedData: f[:50]}}, buf))
+		_, err := NewDemultiplexer(codec, buf).ReadFrame()
+		assert.Error(t, err)
+		assert.NotEqual(t, io.EOF, err)
+	})
+}
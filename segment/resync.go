@@ -0,0 +1,109 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package segment
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/datastax/go-cassandra-native-protocol/crc"
+)
+
+// FindNextSegment scans the given bytes, typically a corrupted or truncated capture of protocol v5 traffic, for the
+// first offset at which a plausible segment starts, and returns that offset, i.e. the number of bytes to skip before
+// the data can be decoded again with a Decoder. It returns -1 if no plausible segment was found.
+//
+// A segment is plausible if its header has a valid CRC-24 and zero padding bits; moreover, if the whole segment is
+// available, its payload must have a valid CRC-32. Set compressed to true if the segments were encoded with
+// compression, since compressed segments have a different header layout.
+func FindNextSegment(data []byte, compressed bool) int {
+	headerLength := headerLength(compressed)
+	for offset := 0; offset+headerLength+Crc24Length <= len(data); offset++ {
+		payloadLength, ok := checkHeader(data[offset:], compressed)
+		if !ok {
+			continue
+		}
+		payloadStart := offset + headerLength + Crc24Length
+		payloadEnd := payloadStart + payloadLength
+		if payloadEnd+Crc32Length <= len(data) {
+			expected := binary.LittleEndian.Uint32(data[payloadEnd:])
+			if crc.ChecksumIEEE(data[payloadStart:payloadEnd]) != expected {
+				continue
+			}
+		}
+		return offset
+	}
+	return -1
+}
+
+// SkipToNextSegment discards bytes from the given reader until the next bytes form a plausible segment header, i.e. a
+// header with a valid CRC-24 and zero padding bits. The header itself is not consumed, so that the segment can then be
+// decoded with a Decoder. It returns the number of bytes skipped; if the end of the stream is reached before a
+// plausible header is found, io.EOF is returned, along with the number of bytes skipped.
+func SkipToNextSegment(source *bufio.Reader, compressed bool) (skipped int, err error) {
+	length := headerLength(compressed) + Crc24Length
+	for {
+		header, err := source.Peek(length)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				n, _ := source.Discard(len(header))
+				return skipped + n, io.EOF
+			}
+			return skipped, err
+		}
+		if _, ok := checkHeader(header, compressed); ok {
+			return skipped, nil
+		}
+		if _, err := source.Discard(1); err != nil {
+			return skipped, err
+		}
+		skipped++
+	}
+}
+
+// checkHeader checks whether the given bytes start with a plausible segment header followed by its CRC-24, and
+// returns the length of the encoded payload that follows it.
+func checkHeader(data []byte, compressed bool) (payloadLength int, ok bool) {
+	headerLength := headerLength(compressed)
+	var headerData uint64
+	for i := 0; i < headerLength; i++ {
+		headerData |= uint64(data[i]) << (8 * i)
+	}
+	var expectedCrc uint32
+	for i := 0; i < Crc24Length; i++ {
+		expectedCrc |= uint32(data[headerLength+i]) << (8 * i)
+	}
+	if crc.ChecksumKoopman(headerData, headerLength) != expectedCrc {
+		return 0, false
+	}
+	// payload lengths (17 bits each) and self-contained flag (1 bit); the remaining bits are padding
+	usedBits := 18
+	if compressed {
+		usedBits = 35
+	}
+	if headerData>>usedBits != 0 {
+		return 0, false
+	}
+	return int(headerData & MaxPayloadLength), true
+}
+
+func headerLength(compressed bool) int {
+	if compressed {
+		return CompressedHeaderLength
+	}
+	return UncompressedHeaderLength
+}
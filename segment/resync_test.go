@@ -0,0 +1,103 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package segment
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+)
+
+func encodeTestSegments(t *testing.T, codec Codec, payloads ...[]byte) []byte {
+	buf := &bytes.Buffer{}
+	for _, payload := range payloads {
+		segment := &Segment{Header: &Header{IsSelfContained: true}, Payload: &Payload{UncompressedData: payload}}
+		require.NoError(t, codec.EncodeSegment(segment, buf))
+	}
+	return buf.Bytes()
+}
+
+func TestFindNextSegment(t *testing.T) {
+	for _, compressed := range []bool{false, true} {
+		codec := NewCodec()
+		if compressed {
+			codec = NewCodecWithCompression(&lz4.Compressor{})
+		}
+		valid := encodeTestSegments(t, codec, bytes.Repeat([]byte("payload "), 10), []byte("second"))
+		first := len(encodeTestSegments(t, codec, bytes.Repeat([]byte("payload "), 10)))
+		garbage := []byte{0xde, 0xad, 0xbe, 0xef, 0x01}
+		tests := []struct {
+			name     string
+			data     []byte
+			expected int
+		}{
+			{"valid stream", valid, 0},
+			{"leading garbage", append(append([]byte{}, garbage...), valid...), len(garbage)},
+			{"truncated first segment", valid[3:], first - 3},
+			{"corrupted first payload", corruptAt(valid, first-Crc32Length-1), first},
+			{"truncated last segment", valid[first+2 : len(valid)-1], -1},
+			{"only garbage", garbage, -1},
+			{"empty", nil, -1},
+		}
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%v compressed %v", tt.name, compressed), func(t *testing.T) {
+				skipped := FindNextSegment(tt.data, compressed)
+				assert.Equal(t, tt.expected, skipped)
+				if skipped >= 0 && skipped+headerLength(compressed)+Crc24Length < len(tt.data) {
+					_, err := codec.DecodeSegment(bytes.NewReader(tt.data[skipped:]))
+					assert.NoError(t, err)
+				}
+			})
+		}
+	}
+}
+
+func TestSkipToNextSegment(t *testing.T) {
+	codec := NewCodec()
+	valid := encodeTestSegments(t, codec, []byte("first"), []byte("second"))
+	garbage := []byte{0xde, 0xad, 0xbe, 0xef, 0x01}
+	source := bufio.NewReader(bytes.NewReader(append(append([]byte{}, garbage...), valid...)))
+	skipped, err := SkipToNextSegment(source, false)
+	require.NoError(t, err)
+	assert.Equal(t, len(garbage), skipped)
+	segment, err := codec.DecodeSegment(source)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("first"), segment.Payload.UncompressedData)
+	// already synchronized: nothing to skip
+	skipped, err = SkipToNextSegment(source, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, skipped)
+	segment, err = codec.DecodeSegment(source)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), segment.Payload.UncompressedData)
+	// end of stream
+	source = bufio.NewReader(bytes.NewReader(garbage))
+	skipped, err = SkipToNextSegment(source, false)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, len(garbage), skipped)
+}
+
+func corruptAt(data []byte, index int) []byte {
+	corrupted := append([]byte{}, data...)
+	corrupted[index] ^= 0xff
+	return corrupted
+}